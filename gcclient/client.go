@@ -0,0 +1,506 @@
+// Package gcclient is a typed Go client for the HTTP server
+// that a gcosmos node runs when started with --g-http-addr.
+//
+// The client has no dependency on the Cosmos SDK;
+// transactions are submitted in their JSON encoding,
+// and SDK-specific responses are returned as raw JSON.
+package gcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client makes requests against a single gcosmos node.
+// Its methods are safe for concurrent use.
+type Client struct {
+	baseURL string
+	hc      *http.Client
+
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// Config is the configuration for [New].
+type Config struct {
+	// Address of the node's HTTP server,
+	// either host:port or a full http:// or https:// URL.
+	Addr string
+
+	// HTTP client to use.
+	// If nil, [http.DefaultClient] is used.
+	HTTPClient *http.Client
+
+	// How many times to retry a failed read request.
+	// A request fails if it cannot reach the node
+	// or if the node responds with a 5xx status.
+	// Zero disables retries.
+	MaxRetries int
+
+	// Delay before the first retry, doubling on each subsequent retry.
+	// If zero, 100ms is used.
+	InitialBackoff time.Duration
+
+	// Upper bound on the delay between retries.
+	// If zero, 5s is used.
+	MaxBackoff time.Duration
+}
+
+// New returns a new Client.
+func New(cfg Config) *Client {
+	baseURL := strings.TrimSuffix(cfg.Addr, "/")
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "http://" + baseURL
+	}
+
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	initialBackoff := cfg.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = 100 * time.Millisecond
+	}
+	maxBackoff := cfg.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 5 * time.Second
+	}
+
+	return &Client{
+		baseURL: baseURL,
+		hc:      hc,
+
+		maxRetries:     cfg.MaxRetries,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+	}
+}
+
+// StatusError is returned when the node responds with an unexpected HTTP status.
+type StatusError struct {
+	StatusCode int
+
+	// The response body.
+	Body string
+
+	// The fields of the node's JSON error body, if the body is one.
+	// Codespace and Code identify the failure, in the SDK's ABCI convention:
+	// errors raised by the node's HTTP server have the codespace "gserver",
+	// and errors returned by the app keep the app's codespace and code.
+	Codespace string
+	Code      uint32
+	Log       string
+}
+
+func (e StatusError) Error() string {
+	if e.Codespace != "" {
+		return fmt.Sprintf("unexpected status %d: %s (codespace %s, code %d)", e.StatusCode, e.Log, e.Codespace, e.Code)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// newStatusError returns a StatusError for a response with the given status and body.
+func newStatusError(statusCode int, body []byte) StatusError {
+	se := StatusError{
+		StatusCode: statusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+
+	var env struct {
+		Codespace string `json:"codespace"`
+		Code      uint32 `json:"code"`
+		Log       string `json:"log"`
+	}
+	if json.Unmarshal(body, &env) == nil {
+		se.Codespace, se.Code, se.Log = env.Codespace, env.Code, env.Log
+	}
+	return se
+}
+
+// Watermark is the node's current voting and committing height and round.
+type Watermark struct {
+	VotingHeight uint64
+	VotingRound  uint32
+
+	CommittingHeight uint64
+	CommittingRound  uint32
+}
+
+// Watermark returns the node's current heights and rounds.
+func (c *Client) Watermark(ctx context.Context) (Watermark, error) {
+	var wm Watermark
+	err := c.getJSON(ctx, "/blocks/watermark", &wm)
+	return wm, err
+}
+
+// Validator is a single consensus validator.
+type Validator struct {
+	// The public key in the node's crypto registry encoding.
+	PubKey []byte
+	Power  uint64
+}
+
+// ValidatorSet is the consensus validator set at a finalized height.
+type ValidatorSet struct {
+	FinalizationHeight uint64
+	Validators         []Validator
+}
+
+// Validators returns the validator set at the node's committing height.
+// The node may limit its page size,
+// so Validators requests pages until an empty page is returned.
+func (c *Client) Validators(ctx context.Context) (ValidatorSet, error) {
+	var vs ValidatorSet
+	for {
+		var page ValidatorSet
+		if err := c.getJSON(ctx, "/validators?offset="+strconv.Itoa(len(vs.Validators)), &page); err != nil {
+			return ValidatorSet{}, err
+		}
+
+		if len(vs.Validators) == 0 {
+			vs.FinalizationHeight = page.FinalizationHeight
+		} else if page.FinalizationHeight != vs.FinalizationHeight {
+			return ValidatorSet{}, fmt.Errorf(
+				"committing height changed from %d to %d while paging validators",
+				vs.FinalizationHeight, page.FinalizationHeight,
+			)
+		}
+
+		if len(page.Validators) == 0 {
+			return vs, nil
+		}
+		vs.Validators = append(vs.Validators, page.Validators...)
+	}
+}
+
+// Balance returns the balance of the account with the given bech32 address
+// in the given denomination, as a decimal string.
+// If denom is empty, the node's default denomination is used.
+func (c *Client) Balance(ctx context.Context, addr, denom string) (string, error) {
+	path := "/debug/accounts/" + url.PathEscape(addr) + "/balance"
+	if denom != "" {
+		path += "?denom=" + url.QueryEscape(denom)
+	}
+
+	var resp struct {
+		Balance struct {
+			Amount string
+		}
+	}
+	if err := c.getJSON(ctx, path, &resp); err != nil {
+		return "", err
+	}
+	if resp.Balance.Amount == "" {
+		// The bank module omits the amount of an empty balance.
+		return "0", nil
+	}
+	return resp.Balance.Amount, nil
+}
+
+// SubmitTx submits a signed transaction, in its SDK JSON encoding,
+// to the node's transaction buffer.
+// On success, it returns the node's JSON-encoded simulation result.
+//
+// SubmitTx is never retried,
+// because a retry of a submission that did reach the node
+// would be rejected as a duplicate.
+func (c *Client) SubmitTx(ctx context.Context, txJSON []byte) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.baseURL+"/debug/submit_tx", bytes.NewReader(txJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+// GasEstimate is the result of [Client.SimulateTx].
+type GasEstimate struct {
+	GasWanted uint64
+	GasUsed   uint64
+
+	// The events the transaction would emit, as JSON.
+	Events json.RawMessage
+}
+
+// SimulateTx simulates a transaction, in its SDK JSON encoding,
+// without submitting it, and returns its estimated gas.
+// If the transaction has no signer infos,
+// the node simulates it as signed by its messages' senders,
+// so an unsigned transaction from --generate-only can be estimated before signing.
+func (c *Client) SimulateTx(ctx context.Context, txJSON []byte) (GasEstimate, error) {
+	in, err := json.Marshal(struct {
+		Tx json.RawMessage `json:"tx"`
+	}{Tx: txJSON})
+	if err != nil {
+		return GasEstimate{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.baseURL+"/simulate_tx", bytes.NewReader(in),
+	)
+	if err != nil {
+		return GasEstimate{}, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, err := c.do(req)
+	if err != nil {
+		return GasEstimate{}, err
+	}
+
+	var resp struct {
+		GasInfo struct {
+			GasWanted uint64 `json:"gas_wanted,string"`
+			GasUsed   uint64 `json:"gas_used,string"`
+		} `json:"gas_info"`
+		Result struct {
+			Events json.RawMessage `json:"events"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return GasEstimate{}, fmt.Errorf("failed to decode response from /simulate_tx: %w", err)
+	}
+	return GasEstimate{
+		GasWanted: resp.GasInfo.GasWanted,
+		GasUsed:   resp.GasInfo.GasUsed,
+		Events:    resp.Result.Events,
+	}, nil
+}
+
+// TxPending reports whether the transaction with the given hash
+// is still in the node's transaction buffer.
+//
+// The node does not index committed transactions,
+// so a transaction that is no longer pending
+// was either included in a block or dropped as invalid.
+func (c *Client) TxPending(ctx context.Context, hash []byte) (bool, error) {
+	var resp struct {
+		Pending bool
+	}
+	err := c.getJSON(ctx, "/debug/pending_txs/"+hex.EncodeToString(hash), &resp)
+	return resp.Pending, err
+}
+
+// PendingTxCount returns the number of transactions in the node's transaction buffer.
+func (c *Client) PendingTxCount(ctx context.Context) (int, error) {
+	// Only the total count header is needed, so request the smallest page.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/debug/pending_txs?limit=1", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request to %s failed: %w", req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, newStatusError(resp.StatusCode, body)
+	}
+
+	n, err := strconv.Atoi(resp.Header.Get("X-Total-Count"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid X-Total-Count header: %w", err)
+	}
+	return n, nil
+}
+
+// SubscribeBlocks polls the node's watermark every interval,
+// and sends the watermark on the returned channel
+// each time the committing height increases.
+//
+// The channel is closed when ctx is canceled,
+// or when polling fails after exhausting the client's retries.
+func (c *Client) SubscribeBlocks(ctx context.Context, interval time.Duration) <-chan Watermark {
+	out := make(chan Watermark)
+
+	go func() {
+		defer close(out)
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		var lastHeight uint64
+		for {
+			wm, err := c.Watermark(ctx)
+			if err != nil {
+				return
+			}
+
+			if wm.CommittingHeight > lastHeight {
+				lastHeight = wm.CommittingHeight
+				select {
+				case <-ctx.Done():
+					return
+				case out <- wm:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+
+	return out
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, dst any) error {
+	backoff := c.initialBackoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+		if err != nil {
+			return fmt.Errorf("failed to build request: %w", err)
+		}
+
+		body, err := c.do(req)
+		if err == nil {
+			if err := json.Unmarshal(body, dst); err != nil {
+				return fmt.Errorf("failed to decode response from %s: %w", path, err)
+			}
+			return nil
+		}
+
+		if attempt >= c.maxRetries || !retryable(err) {
+			return err
+		}
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+
+		backoff = min(2*backoff, c.maxBackoff)
+	}
+}
+
+// do sends req and returns the response body,
+// or a [StatusError] if the response status is not 200.
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", req.URL.Path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+// retryable reports whether err is worth retrying:
+// server errors and failures to reach the server,
+// but not client errors or context cancellation.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var se StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500
+	}
+
+	return true
+}
+
+// AddressChange is a change to a watched address's balance or delegations,
+// in a block the node has finalized.
+type AddressChange struct {
+	Height  uint64
+	Address string
+
+	// Either "balance" or "delegation".
+	Kind string
+
+	// The app event reporting the change.
+	Event struct {
+		Type       string
+		Attributes []struct {
+			Key, Value string
+		}
+	}
+}
+
+// Watch subscribes to changes to the balances and delegations of addrs,
+// in blocks the node finalizes after Watch returns.
+//
+// The channel is closed when ctx is canceled,
+// when the connection to the node fails,
+// or when the node ends the subscription because the client fell behind.
+// Changes are not replayed on resubscription,
+// so a caller should read the current balances after each call to Watch.
+func (c *Client) Watch(ctx context.Context, addrs ...string) (<-chan AddressChange, error) {
+	q := make(url.Values, 1)
+	q["address"] = addrs
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/watch?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newStatusError(resp.StatusCode, body)
+	}
+
+	out := make(chan AddressChange)
+	go func() {
+		defer close(out)
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var ac AddressChange
+			if err := dec.Decode(&ac); err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- ac:
+			}
+		}
+	}()
+
+	return out, nil
+}
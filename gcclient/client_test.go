@@ -0,0 +1,293 @@
+package gcclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcclient"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Watermark_retries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/blocks/watermark", r.URL.Path)
+		if calls.Add(1) < 3 {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(gcclient.Watermark{VotingHeight: 3, CommittingHeight: 2})
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{
+		Addr:           srv.URL,
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+	})
+
+	wm, err := c.Watermark(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), wm.VotingHeight)
+	require.Equal(t, uint64(2), wm.CommittingHeight)
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_clientErrorNotRetried(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad hash", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{
+		Addr:           srv.URL,
+		MaxRetries:     5,
+		InitialBackoff: time.Millisecond,
+	})
+
+	_, err := c.TxPending(context.Background(), []byte{1, 2, 3})
+	var se gcclient.StatusError
+	require.ErrorAs(t, err, &se)
+	require.Equal(t, http.StatusBadRequest, se.StatusCode)
+	require.Equal(t, "bad hash", se.Body)
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_errorEnvelope(t *testing.T) {
+	t.Parallel()
+
+	body := `{"codespace":"sdk","code":5,"log":"transaction validation failed: insufficient funds"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(body + "\n"))
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	_, err := c.SubmitTx(context.Background(), []byte(`{"body":{}}`))
+	var se gcclient.StatusError
+	require.ErrorAs(t, err, &se)
+	require.Equal(t, http.StatusBadRequest, se.StatusCode)
+	require.Equal(t, body, se.Body)
+	require.Equal(t, "sdk", se.Codespace)
+	require.Equal(t, uint32(5), se.Code)
+	require.Equal(t, "transaction validation failed: insufficient funds", se.Log)
+	require.ErrorContains(t, err, "codespace sdk, code 5")
+}
+
+func TestClient_SubmitTx(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/debug/submit_tx", r.URL.Path)
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"body":{}}`, string(b))
+
+		_, _ = w.Write([]byte(`{"GasUsed":"100"}`))
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	res, err := c.SubmitTx(context.Background(), []byte(`{"body":{}}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"GasUsed":"100"}`, string(res))
+}
+
+func TestClient_SimulateTx(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/simulate_tx", r.URL.Path)
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"tx":{"body":{}}}`, string(b))
+
+		_, _ = w.Write([]byte(`{"gas_info":{"gas_wanted":"200","gas_used":"150"},"result":{"events":[{"type":"transfer"}]}}`))
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	est, err := c.SimulateTx(context.Background(), []byte(`{"body":{}}`))
+	require.NoError(t, err)
+	require.Equal(t, uint64(200), est.GasWanted)
+	require.Equal(t, uint64(150), est.GasUsed)
+	require.JSONEq(t, `[{"type":"transfer"}]`, string(est.Events))
+}
+
+func TestClient_Balance(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/debug/accounts/cosmos1abc/balance", r.URL.Path)
+		if r.URL.Query().Get("denom") == "empty" {
+			_, _ = w.Write([]byte(`{"balance":{"denom":"empty"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"balance":{"denom":"stake","amount":"10000"}}`))
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	amt, err := c.Balance(context.Background(), "cosmos1abc", "")
+	require.NoError(t, err)
+	require.Equal(t, "10000", amt)
+
+	amt, err = c.Balance(context.Background(), "cosmos1abc", "empty")
+	require.NoError(t, err)
+	require.Equal(t, "0", amt)
+}
+
+func TestClient_PendingTxCount(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/debug/pending_txs", r.URL.Path)
+		w.Header().Set("X-Total-Count", "42")
+		_, _ = w.Write([]byte(`[{}]`))
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	n, err := c.PendingTxCount(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 42, n)
+}
+
+func TestClient_SubscribeBlocks(t *testing.T) {
+	t.Parallel()
+
+	var height atomic.Uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(gcclient.Watermark{CommittingHeight: height.Load()})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	height.Store(1)
+	ch := c.SubscribeBlocks(ctx, time.Millisecond)
+
+	wm := <-ch
+	require.Equal(t, uint64(1), wm.CommittingHeight)
+
+	height.Store(3)
+	wm = <-ch
+	require.Equal(t, uint64(3), wm.CommittingHeight)
+
+	cancel()
+	for range ch {
+		// Drain until closed.
+	}
+}
+
+func TestClient_Validators_paged(t *testing.T) {
+	t.Parallel()
+
+	all := []gcclient.Validator{{Power: 1}, {Power: 2}, {Power: 3}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/validators", r.URL.Path)
+
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		require.NoError(t, err)
+
+		// Serve at most two validators per page.
+		vals := all[min(offset, len(all)):]
+		vals = vals[:min(2, len(vals))]
+		_ = json.NewEncoder(w).Encode(gcclient.ValidatorSet{
+			FinalizationHeight: 4,
+			Validators:         vals,
+		})
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	vs, err := c.Validators(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), vs.FinalizationHeight)
+	require.Equal(t, all, vs.Validators)
+}
+
+func TestClient_Watch(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/watch", r.URL.Path)
+		require.Equal(t, []string{"alice", "bob"}, r.URL.Query()["address"])
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(
+			`{"Height":3,"Address":"alice","Kind":"balance","Event":{"Type":"transfer","Attributes":[{"Key":"sender","Value":"alice"}]}}` + "\n" +
+				`{"Height":4,"Address":"bob","Kind":"delegation","Event":{"Type":"delegate"}}` + "\n",
+		))
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.Watch(ctx, "alice", "bob")
+	require.NoError(t, err)
+
+	ac := gtest.ReceiveSoon(t, ch)
+	require.Equal(t, uint64(3), ac.Height)
+	require.Equal(t, "alice", ac.Address)
+	require.Equal(t, "balance", ac.Kind)
+	require.Equal(t, "transfer", ac.Event.Type)
+	require.Equal(t, "sender", ac.Event.Attributes[0].Key)
+
+	ac = gtest.ReceiveSoon(t, ch)
+	require.Equal(t, "delegation", ac.Kind)
+
+	// The stream ended, so the channel is closed.
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func TestClient_Watch_error(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"codespace":"gserver","code":5,"log":"address watching not enabled"}`))
+	}))
+	defer srv.Close()
+
+	c := gcclient.New(gcclient.Config{Addr: srv.URL})
+
+	_, err := c.Watch(context.Background(), "alice")
+	var se gcclient.StatusError
+	require.ErrorAs(t, err, &se)
+	require.Equal(t, uint32(5), se.Code)
+}
@@ -0,0 +1,303 @@
+// Package gcconformance is an end-to-end conformance suite
+// for running networks of a gordian-based chain.
+//
+// The suite only talks to nodes over their HTTP API, through [gcclient],
+// so it can run against any chain binary that serves that API,
+// however the network was started.
+// Transactions are supplied by the caller already signed,
+// because building and signing them is specific to each chain.
+package gcconformance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcclient"
+)
+
+// DefaultTimeout is the time allowed for each check when [Config.Timeout] is zero.
+const DefaultTimeout = 30 * time.Second
+
+// How often to poll nodes while waiting for a condition.
+const pollInterval = 100 * time.Millisecond
+
+// Config is the configuration for [Run].
+type Config struct {
+	// The nodes under test. Transactions are submitted to the first node,
+	// and every node must observe their effects.
+	Nodes []*gcclient.Client
+
+	// How long each check may take.
+	// Defaults to DefaultTimeout if zero.
+	Timeout time.Duration
+
+	// How many heights every node must commit during the height check.
+	// Defaults to 2 if zero.
+	MinAdvance uint64
+
+	// Optional check that a bank send changes balances.
+	Send *SendCheck
+
+	// Optional check that a staking transaction changes the validator set.
+	Staking *StakingCheck
+}
+
+// SendCheck describes a transaction sending Amount of Denom from From to To.
+// The sender is assumed to pay no fees in Denom.
+type SendCheck struct {
+	From, To string
+
+	// If empty, the node's default denomination.
+	Denom string
+
+	Amount uint64
+
+	// Tx returns the signed transaction in its JSON encoding.
+	// It is called once the chain is past its initial heights.
+	Tx func(t *testing.T) []byte
+}
+
+// StakingCheck describes a staking transaction that changes the validator set,
+// such as a delegation, a new validator, or an unbonding.
+type StakingCheck struct {
+	// Tx returns the signed transaction in its JSON encoding.
+	Tx func(t *testing.T) []byte
+
+	// Reports whether after reflects the transaction, given the set before it.
+	// If nil, any change in membership or power is accepted.
+	Changed func(before, after gcclient.ValidatorSet) bool
+}
+
+// Run runs every configured check as a subtest of t, in order:
+// heights advance on every node, then the send check, then the staking check.
+func Run(t *testing.T, cfg Config) {
+	t.Helper()
+
+	if len(cfg.Nodes) == 0 {
+		t.Fatal("gcconformance: no nodes configured")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MinAdvance == 0 {
+		cfg.MinAdvance = 2
+	}
+
+	t.Run("heights advance", func(t *testing.T) {
+		RequireHeightsAdvance(t, cfg.Nodes, cfg.MinAdvance, cfg.Timeout)
+	})
+
+	if cfg.Send != nil {
+		t.Run("balances change after send", func(t *testing.T) {
+			RequireSend(t, cfg.Nodes, *cfg.Send, cfg.Timeout)
+		})
+	}
+
+	if cfg.Staking != nil {
+		t.Run("validator set updates after staking tx", func(t *testing.T) {
+			RequireStakingChange(t, cfg.Nodes, *cfg.Staking, cfg.Timeout)
+		})
+	}
+}
+
+// RequireHeightsAdvance fails the test unless every node's committing height
+// advances by at least n within the timeout.
+func RequireHeightsAdvance(t *testing.T, nodes []*gcclient.Client, n uint64, timeout time.Duration) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := make([]uint64, len(nodes))
+	for i, c := range nodes {
+		err := waitFor(ctx, func() bool {
+			wm, err := c.Watermark(ctx)
+			start[i] = wm.CommittingHeight
+			return err == nil
+		})
+		if err != nil {
+			t.Fatalf("node %d did not report a watermark: %v", i, err)
+		}
+	}
+
+	for i, c := range nodes {
+		var last gcclient.Watermark
+		err := waitFor(ctx, func() bool {
+			wm, err := c.Watermark(ctx)
+			if err != nil {
+				return false
+			}
+			last = wm
+			return wm.CommittingHeight >= start[i]+n
+		})
+		if err != nil {
+			t.Fatalf(
+				"node %d committing height did not advance from %d to %d (last %d): %v",
+				i, start[i], start[i]+n, last.CommittingHeight, err,
+			)
+		}
+	}
+}
+
+// RequireSend submits the send transaction to the first node,
+// and fails the test unless every node reports the sender's balance
+// decreased by the amount and the recipient's increased by the amount,
+// within the timeout.
+func RequireSend(t *testing.T, nodes []*gcclient.Client, sc SendCheck, timeout time.Duration) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	fromBefore := requireBalance(t, ctx, nodes[0], sc.From, sc.Denom)
+	toBefore := requireBalance(t, ctx, nodes[0], sc.To, sc.Denom)
+
+	amt := new(big.Int).SetUint64(sc.Amount)
+	wantFrom := new(big.Int).Sub(fromBefore, amt)
+	wantTo := new(big.Int).Add(toBefore, amt)
+
+	if _, err := nodes[0].SubmitTx(ctx, sc.Tx(t)); err != nil {
+		t.Fatalf("failed to submit send transaction: %v", err)
+	}
+
+	for i, c := range nodes {
+		var from, to *big.Int
+		err := waitFor(ctx, func() bool {
+			var err error
+			if from, err = balance(ctx, c, sc.From, sc.Denom); err != nil {
+				return false
+			}
+			if to, err = balance(ctx, c, sc.To, sc.Denom); err != nil {
+				return false
+			}
+			return from.Cmp(wantFrom) == 0 && to.Cmp(wantTo) == 0
+		})
+		if err != nil {
+			t.Fatalf(
+				"node %d balances did not reflect send: sender %v (want %v), recipient %v (want %v): %v",
+				i, from, wantFrom, to, wantTo, err,
+			)
+		}
+	}
+}
+
+// RequireStakingChange submits the staking transaction to the first node,
+// and fails the test unless every node reports a validator set
+// that sc.Changed accepts, within the timeout.
+func RequireStakingChange(t *testing.T, nodes []*gcclient.Client, sc StakingCheck, timeout time.Duration) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	before, err := nodes[0].Validators(ctx)
+	if err != nil {
+		t.Fatalf("failed to get validators before staking transaction: %v", err)
+	}
+
+	changed := sc.Changed
+	if changed == nil {
+		changed = validatorsDiffer
+	}
+
+	if _, err := nodes[0].SubmitTx(ctx, sc.Tx(t)); err != nil {
+		t.Fatalf("failed to submit staking transaction: %v", err)
+	}
+
+	for i, c := range nodes {
+		var after gcclient.ValidatorSet
+		err := waitFor(ctx, func() bool {
+			vs, err := c.Validators(ctx)
+			if err != nil {
+				return false
+			}
+			after = vs
+			return changed(before, vs)
+		})
+		if err != nil {
+			t.Fatalf(
+				"node %d validator set did not reflect staking transaction (last at height %d with %d validators): %v",
+				i, after.FinalizationHeight, len(after.Validators), err,
+			)
+		}
+	}
+}
+
+// TotalPowerIncreased is a [StakingCheck.Changed] function
+// accepting a validator set whose total power is greater than before,
+// as after a delegation.
+func TotalPowerIncreased(before, after gcclient.ValidatorSet) bool {
+	return totalPower(after) > totalPower(before)
+}
+
+func totalPower(vs gcclient.ValidatorSet) uint64 {
+	var sum uint64
+	for _, v := range vs.Validators {
+		sum += v.Power
+	}
+	return sum
+}
+
+// validatorsDiffer reports whether the validators or their powers differ,
+// regardless of order.
+func validatorsDiffer(before, after gcclient.ValidatorSet) bool {
+	if len(before.Validators) != len(after.Validators) {
+		return true
+	}
+
+	powers := make(map[string]uint64, len(before.Validators))
+	for _, v := range before.Validators {
+		powers[string(v.PubKey)] = v.Power
+	}
+	for _, v := range after.Validators {
+		if p, ok := powers[string(v.PubKey)]; !ok || p != v.Power {
+			return true
+		}
+	}
+	return false
+}
+
+func requireBalance(t *testing.T, ctx context.Context, c *gcclient.Client, addr, denom string) *big.Int {
+	t.Helper()
+
+	b, err := balance(ctx, c, addr, denom)
+	if err != nil {
+		t.Fatalf("failed to get balance of %s: %v", addr, err)
+	}
+	return b
+}
+
+func balance(ctx context.Context, c *gcclient.Client, addr, denom string) (*big.Int, error) {
+	s, err := c.Balance(ctx, addr, denom)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance amount %q", s)
+	}
+	return b, nil
+}
+
+// waitFor calls check every pollInterval until it returns true,
+// returning the cause of ctx if ctx is done first.
+// Errors within check are treated as transient, as a node may be restarting.
+func waitFor(ctx context.Context, check func() bool) error {
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+
+	for {
+		if check() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gcconformance_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcclient"
+	"github.com/gordian-engine/gcosmos/gcconformance"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode serves just enough of the node HTTP API for the suite:
+// its height advances on every watermark request,
+// and every submitted transaction is immediately applied
+// as a send of 100 from alice to bob and 10 more power for the only validator.
+type fakeNode struct {
+	mu     sync.Mutex
+	height uint64
+	alice  uint64
+	bob    uint64
+	power  uint64
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/blocks/watermark":
+		n.height++
+		_ = json.NewEncoder(w).Encode(gcclient.Watermark{CommittingHeight: n.height})
+	case "/debug/submit_tx":
+		n.alice -= 100
+		n.bob += 100
+		n.power += 10
+		_, _ = w.Write([]byte(`{}`))
+	case "/debug/accounts/alice/balance":
+		fmt.Fprintf(w, `{"balance":{"amount":"%d"}}`, n.alice)
+	case "/debug/accounts/bob/balance":
+		fmt.Fprintf(w, `{"balance":{"amount":"%d"}}`, n.bob)
+	case "/validators":
+		vs := gcclient.ValidatorSet{FinalizationHeight: n.height}
+		if r.URL.Query().Get("offset") == "0" {
+			vs.Validators = []gcclient.Validator{{PubKey: []byte{1}, Power: n.power}}
+		}
+		_ = json.NewEncoder(w).Encode(vs)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	node := &fakeNode{alice: 10_000, power: 1_000}
+	srv := httptest.NewServer(node)
+	defer srv.Close()
+
+	tx := func(*testing.T) []byte { return []byte(`{}`) }
+	gcconformance.Run(t, gcconformance.Config{
+		Nodes:   []*gcclient.Client{gcclient.New(gcclient.Config{Addr: srv.URL})},
+		Timeout: 5 * time.Second,
+
+		Send: &gcconformance.SendCheck{
+			From: "alice", To: "bob",
+			Amount: 100,
+			Tx:     tx,
+		},
+		Staking: &gcconformance.StakingCheck{
+			Tx:      tx,
+			Changed: gcconformance.TotalPowerIncreased,
+		},
+	})
+
+	require.Equal(t, uint64(9_800), node.alice)
+	require.Equal(t, uint64(200), node.bob)
+}
@@ -0,0 +1,115 @@
+// Package gcconsensus contains helpers for consensus strategies
+// built on the tmconsensus types.
+package gcconsensus
+
+import (
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// VoteSummary wraps a [tmconsensus.VoteSummary] with the threshold helpers
+// that consensus strategies otherwise compute by hand
+// with [tmconsensus.ByzantineMajority] and [tmconsensus.ByzantineMinority].
+//
+// The comparisons match those in the engine:
+// a quorum is at least a Byzantine majority of the available power,
+// and a round may be skipped on at least a Byzantine minority.
+//
+// A summary with zero available power never has a quorum,
+// never allows a round skip,
+// and reports the remaining power to a quorum as unknown.
+type VoteSummary struct {
+	tmconsensus.VoteSummary
+}
+
+// Majority returns the power required for a quorum,
+// or zero if there is no available power.
+func (vs VoteSummary) Majority() uint64 {
+	if vs.AvailablePower == 0 {
+		return 0
+	}
+	return tmconsensus.ByzantineMajority(vs.AvailablePower)
+}
+
+// Minority returns the power required to skip to a round,
+// or zero if there is no available power.
+func (vs VoteSummary) Minority() uint64 {
+	if vs.AvailablePower == 0 {
+		return 0
+	}
+	return tmconsensus.ByzantineMinority(vs.AvailablePower)
+}
+
+// HasPrevoteQuorum reports whether hash has a majority of prevote power.
+// The empty string means nil.
+func (vs VoteSummary) HasPrevoteQuorum(hash string) bool {
+	return vs.AvailablePower > 0 && vs.PrevoteBlockPower[hash] >= vs.Majority()
+}
+
+// HasPrecommitQuorum reports whether hash has a majority of precommit power.
+// The empty string means nil.
+func (vs VoteSummary) HasPrecommitQuorum(hash string) bool {
+	return vs.AvailablePower > 0 && vs.PrecommitBlockPower[hash] >= vs.Majority()
+}
+
+// PrevoteQuorum returns the hash holding a majority of prevote power, if any.
+// As a majority is more than two thirds, at most one hash can hold one.
+func (vs VoteSummary) PrevoteQuorum() (hash string, ok bool) {
+	return vs.MostVotedPrevoteHash, vs.HasPrevoteQuorum(vs.MostVotedPrevoteHash)
+}
+
+// PrecommitQuorum returns the hash holding a majority of precommit power, if any.
+func (vs VoteSummary) PrecommitQuorum() (hash string, ok bool) {
+	return vs.MostVotedPrecommitHash, vs.HasPrecommitQuorum(vs.MostVotedPrecommitHash)
+}
+
+// HasPrevoteMajorityPresent reports whether a majority of power has prevoted,
+// regardless of the targets.
+func (vs VoteSummary) HasPrevoteMajorityPresent() bool {
+	return vs.AvailablePower > 0 && vs.TotalPrevotePower >= vs.Majority()
+}
+
+// HasPrecommitMajorityPresent reports whether a majority of power has precommitted,
+// regardless of the targets.
+func (vs VoteSummary) HasPrecommitMajorityPresent() bool {
+	return vs.AvailablePower > 0 && vs.TotalPrecommitPower >= vs.Majority()
+}
+
+// CanSkipRound reports whether, for a summary of a later round,
+// enough power has voted in that round to justify skipping ahead to it:
+// a minority of prevote power or a minority of precommit power.
+func (vs VoteSummary) CanSkipRound() bool {
+	if vs.AvailablePower == 0 {
+		return false
+	}
+	min := vs.Minority()
+	return vs.TotalPrevotePower >= min || vs.TotalPrecommitPower >= min
+}
+
+// RemainingPrevotePowerToQuorum returns the additional prevote power
+// hash needs for a quorum, or zero if it already has one.
+// As with [VoteSummary.HasPrevoteQuorum], a summary without available power
+// has no quorum to reach, so ok is false.
+func (vs VoteSummary) RemainingPrevotePowerToQuorum(hash string) (power uint64, ok bool) {
+	if vs.AvailablePower == 0 {
+		return 0, false
+	}
+	return remaining(vs.Majority(), vs.PrevoteBlockPower[hash]), true
+}
+
+// RemainingPrecommitPowerToQuorum returns the additional precommit power
+// hash needs for a quorum, or zero if it already has one.
+// As with [VoteSummary.HasPrecommitQuorum], a summary without available power
+// has no quorum to reach, so ok is false.
+func (vs VoteSummary) RemainingPrecommitPowerToQuorum(hash string) (power uint64, ok bool) {
+	if vs.AvailablePower == 0 {
+		return 0, false
+	}
+	return remaining(vs.Majority(), vs.PrecommitBlockPower[hash]), true
+}
+
+func remaining(need, have uint64) uint64 {
+	if have >= need {
+		return 0
+	}
+	return need - have
+}
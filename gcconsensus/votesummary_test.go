@@ -0,0 +1,101 @@
+package gcconsensus_test
+
+import (
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteSummary_quorum(t *testing.T) {
+	t.Parallel()
+
+	// 10 available: majority is 7, minority is 4.
+	vs := gcconsensus.VoteSummary{VoteSummary: tmconsensus.VoteSummary{
+		AvailablePower: 10,
+
+		TotalPrevotePower: 9,
+		PrevoteBlockPower: map[string]uint64{"a": 7, "": 2},
+
+		TotalPrecommitPower: 6,
+		PrecommitBlockPower: map[string]uint64{"a": 6},
+
+		MostVotedPrevoteHash:   "a",
+		MostVotedPrecommitHash: "a",
+	}}
+
+	require.Equal(t, uint64(7), vs.Majority())
+	require.Equal(t, uint64(4), vs.Minority())
+
+	require.True(t, vs.HasPrevoteQuorum("a"))
+	require.False(t, vs.HasPrevoteQuorum(""))
+	require.False(t, vs.HasPrecommitQuorum("a"))
+
+	h, ok := vs.PrevoteQuorum()
+	require.True(t, ok)
+	require.Equal(t, "a", h)
+	_, ok = vs.PrecommitQuorum()
+	require.False(t, ok)
+
+	require.True(t, vs.HasPrevoteMajorityPresent())
+	require.False(t, vs.HasPrecommitMajorityPresent())
+
+	for _, tc := range []struct {
+		name string
+		fn   func(string) (uint64, bool)
+		hash string
+		want uint64
+	}{
+		{name: "prevote quorum", fn: vs.RemainingPrevotePowerToQuorum, hash: "a", want: 0},
+		{name: "prevote nil", fn: vs.RemainingPrevotePowerToQuorum, hash: "", want: 5},
+		{name: "precommit short", fn: vs.RemainingPrecommitPowerToQuorum, hash: "a", want: 1},
+		{name: "precommit unvoted", fn: vs.RemainingPrecommitPowerToQuorum, hash: "b", want: 7},
+	} {
+		got, ok := tc.fn(tc.hash)
+		require.True(t, ok, tc.name)
+		require.Equal(t, tc.want, got, tc.name)
+	}
+}
+
+func TestVoteSummary_CanSkipRound(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name               string
+		prevote, precommit uint64
+		want               bool
+	}{
+		{name: "no votes"},
+		{name: "below minority", prevote: 3, precommit: 3},
+		{name: "prevote minority", prevote: 4, want: true},
+		{name: "precommit minority", precommit: 4, want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := gcconsensus.VoteSummary{VoteSummary: tmconsensus.VoteSummary{
+				AvailablePower:      10,
+				TotalPrevotePower:   tc.prevote,
+				TotalPrecommitPower: tc.precommit,
+			}}
+			require.Equal(t, tc.want, vs.CanSkipRound())
+		})
+	}
+}
+
+func TestVoteSummary_noAvailablePower(t *testing.T) {
+	t.Parallel()
+
+	var vs gcconsensus.VoteSummary
+
+	require.Zero(t, vs.Majority())
+	require.False(t, vs.HasPrevoteQuorum(""))
+	require.False(t, vs.HasPrecommitQuorum(""))
+	require.False(t, vs.CanSkipRound())
+
+	// Without available power there is no quorum to reach,
+	// so the remaining power is unknown rather than zero.
+	_, ok := vs.RemainingPrevotePowerToQuorum("a")
+	require.False(t, ok)
+	_, ok = vs.RemainingPrecommitPowerToQuorum("a")
+	require.False(t, ok)
+}
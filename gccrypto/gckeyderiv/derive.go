@@ -0,0 +1,139 @@
+// Package gckeyderiv deterministically derives ed25519 consensus keys
+// from BIP39 mnemonics, so that a validator's consensus key
+// can be restored from the same backup as its account keys.
+//
+// Two derivation schemes are supported.
+//
+// [SchemeCosmos] is the scheme used by "init --recover" in the Cosmos SDK:
+// the ed25519 seed is the SHA-256 hash of the mnemonic text,
+// after trimming surrounding whitespace.
+// This is the scheme to use for keys originally created with "init --recover".
+//
+// [SchemeSLIP10] follows BIP39 and SLIP-0010:
+// the mnemonic and an optional passphrase produce a 64-byte BIP39 seed,
+// from which the key is derived along a hardened path,
+// [DefaultPath] unless otherwise specified.
+// Because the path differs from the account key path,
+// the same mnemonic can safely back both the account key and the consensus key.
+//
+// Neither scheme validates the mnemonic's word list or checksum;
+// callers should do so before deriving a key.
+package gckeyderiv
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Scheme identifies a derivation scheme.
+type Scheme string
+
+const (
+	SchemeCosmos Scheme = "cosmos"
+	SchemeSLIP10 Scheme = "slip10"
+)
+
+// DefaultPath is the SLIP-0010 derivation path for consensus keys:
+// purpose 44', Cosmos coin type 118', account 0', and then 1'/0',
+// keeping consensus keys apart from the non-hardened account key path m/44'/118'/0'/0/0.
+const DefaultPath = "m/44'/118'/0'/1'/0'"
+
+// ParseScheme parses the string form of a [Scheme].
+func ParseScheme(s string) (Scheme, error) {
+	switch Scheme(s) {
+	case SchemeCosmos, SchemeSLIP10:
+		return Scheme(s), nil
+	default:
+		return "", fmt.Errorf("unknown derivation scheme %q (expected %q or %q)", s, SchemeCosmos, SchemeSLIP10)
+	}
+}
+
+// ConsensusKey derives the consensus key for mnemonic using the given scheme.
+// The passphrase and path are only used by [SchemeSLIP10];
+// an empty path means [DefaultPath].
+func ConsensusKey(scheme Scheme, mnemonic, passphrase, path string) (ed25519.PrivateKey, error) {
+	switch scheme {
+	case SchemeCosmos:
+		if passphrase != "" {
+			return nil, fmt.Errorf("scheme %q does not support a passphrase", scheme)
+		}
+		seed := sha256.Sum256([]byte(strings.TrimSpace(mnemonic)))
+		return ed25519.NewKeyFromSeed(seed[:]), nil
+	case SchemeSLIP10:
+		if path == "" {
+			path = DefaultPath
+		}
+		return KeyFromSeed(SeedFromMnemonic(mnemonic, passphrase), path)
+	default:
+		return nil, fmt.Errorf("unknown derivation scheme %q", scheme)
+	}
+}
+
+// SeedFromMnemonic returns the 64-byte BIP39 seed for mnemonic and passphrase.
+// Words in the mnemonic are normalized to be separated by single spaces.
+func SeedFromMnemonic(mnemonic, passphrase string) []byte {
+	m := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(m), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
+
+// KeyFromSeed derives the ed25519 key at path from a BIP39 seed, per SLIP-0010.
+// Every path element must be hardened, as SLIP-0010 requires for ed25519.
+func KeyFromSeed(seed []byte, path string) (ed25519.PrivateKey, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	k, c := hmacSplit([]byte("ed25519 seed"), seed)
+	for _, idx := range indices {
+		data := make([]byte, 0, 1+32+4)
+		data = append(data, 0)
+		data = append(data, k...)
+		data = binary.BigEndian.AppendUint32(data, idx)
+		k, c = hmacSplit(c, data)
+	}
+
+	return ed25519.NewKeyFromSeed(k), nil
+}
+
+// ParsePath parses a derivation path such as [DefaultPath]
+// into its child indices, with the hardened bit set.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path %q must start with m", path)
+	}
+
+	indices := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		n, ok := strings.CutSuffix(p, "'")
+		if !ok {
+			n, ok = strings.CutSuffix(p, "h")
+		}
+		if !ok {
+			return nil, fmt.Errorf("derivation path %q: element %q is not hardened", path, p)
+		}
+
+		i, err := strconv.ParseUint(n, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("derivation path %q: invalid element %q", path, p)
+		}
+		indices = append(indices, uint32(i)|1<<31)
+	}
+	return indices, nil
+}
+
+func hmacSplit(key, data []byte) (left, right []byte) {
+	h := hmac.New(sha512.New, key)
+	h.Write(data)
+	sum := h.Sum(nil)
+	return sum[:32], sum[32:]
+}
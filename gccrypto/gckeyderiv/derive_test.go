@@ -0,0 +1,102 @@
+package gckeyderiv_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gccrypto/gckeyderiv"
+	"github.com/stretchr/testify/require"
+)
+
+const abandonMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestSeedFromMnemonic(t *testing.T) {
+	t.Parallel()
+
+	// Test vector from the reference BIP39 implementation.
+	seed := gckeyderiv.SeedFromMnemonic(abandonMnemonic, "TREZOR")
+	require.Equal(
+		t,
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		hex.EncodeToString(seed),
+	)
+}
+
+func TestKeyFromSeed(t *testing.T) {
+	t.Parallel()
+
+	// Test vector 1 for ed25519 from SLIP-0010.
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		path, priv, pub string
+	}{
+		{
+			path: "m",
+			priv: "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			pub:  "a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+		},
+		{
+			path: "m/0'",
+			priv: "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			pub:  "8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+		},
+		{
+			path: "m/0h/1h",
+			priv: "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			pub:  "1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+		},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			k, err := gckeyderiv.KeyFromSeed(seed, tc.path)
+			require.NoError(t, err)
+			require.Equal(t, tc.priv, hex.EncodeToString(k.Seed()))
+			require.Equal(t, tc.pub, hex.EncodeToString(k.Public().(ed25519.PublicKey)))
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	t.Parallel()
+
+	idx, err := gckeyderiv.ParsePath(gckeyderiv.DefaultPath)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0x8000002c, 0x80000076, 0x80000000, 0x80000001, 0x80000000}, idx)
+
+	for _, bad := range []string{"", "44'/0'", "m/44'/0", "m/x'", "m/2147483648'"} {
+		_, err := gckeyderiv.ParsePath(bad)
+		require.Error(t, err, "path %q", bad)
+	}
+}
+
+func TestConsensusKey_cosmos(t *testing.T) {
+	t.Parallel()
+
+	// Must match the key written by "init --recover".
+	want := sha256.Sum256([]byte(abandonMnemonic))
+	k, err := gckeyderiv.ConsensusKey(gckeyderiv.SchemeCosmos, "  "+abandonMnemonic+"\n", "", "")
+	require.NoError(t, err)
+	require.Equal(t, want[:], k.Seed())
+
+	_, err = gckeyderiv.ConsensusKey(gckeyderiv.SchemeCosmos, abandonMnemonic, "pass", "")
+	require.Error(t, err)
+}
+
+func TestConsensusKey_slip10(t *testing.T) {
+	t.Parallel()
+
+	k1, err := gckeyderiv.ConsensusKey(gckeyderiv.SchemeSLIP10, abandonMnemonic, "", "")
+	require.NoError(t, err)
+
+	k2, err := gckeyderiv.KeyFromSeed(gckeyderiv.SeedFromMnemonic(abandonMnemonic, ""), gckeyderiv.DefaultPath)
+	require.NoError(t, err)
+	require.Equal(t, k2, k1)
+
+	// A passphrase yields a different key.
+	k3, err := gckeyderiv.ConsensusKey(gckeyderiv.SchemeSLIP10, abandonMnemonic, "pass", "")
+	require.NoError(t, err)
+	require.NotEqual(t, k1, k3)
+}
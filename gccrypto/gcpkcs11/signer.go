@@ -0,0 +1,217 @@
+// Package gcpkcs11 contains a [gcrypto.Signer] whose ed25519 key
+// lives in a hardware security module, accessed through PKCS#11.
+//
+// The PKCS#11 binding uses github.com/miekg/pkcs11,
+// so it requires cgo and the "pkcs11" build tag,
+// i.e. "go build -tags pkcs11";
+// otherwise [OpenToken] always returns an error.
+// The [Signer] itself works with any [Token] implementation.
+package gcpkcs11
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+)
+
+// Token is a signing key held by a hardware token.
+//
+// Implementations need not be safe for concurrent use;
+// the [Signer] serializes calls,
+// as a single PKCS#11 session may only run one operation at a time.
+type Token interface {
+	// PublicKey returns the raw 32-byte ed25519 public key.
+	PublicKey() ([]byte, error)
+
+	// Sign returns the ed25519 signature of msg.
+	Sign(msg []byte) ([]byte, error)
+
+	// Ping reports whether the token is still reachable.
+	Ping() error
+}
+
+// TokenConfig identifies the signing key for [OpenToken].
+type TokenConfig struct {
+	// Path to the vendor's PKCS#11 shared library.
+	ModulePath string
+
+	// Label of the token holding the key.
+	TokenLabel string
+
+	// Label shared by the private and public key objects.
+	KeyLabel string
+
+	// User PIN for the token.
+	PIN string
+}
+
+// SignerConfig is the configuration for [NewSigner].
+type SignerConfig struct {
+	Token Token
+
+	// Signatures taking longer than this are logged as a warning.
+	// Zero disables the warning.
+	SlowSignThreshold time.Duration
+}
+
+// SignerStats is a snapshot of a [Signer]'s counters.
+type SignerStats struct {
+	// Number of Sign calls that reached the token, and how many of those failed.
+	Signs, SignErrors uint64
+
+	// Latency of token signing operations, including failures.
+	LastLatency, MaxLatency, TotalLatency time.Duration
+
+	// Result of the most recent health check; nil if healthy or never checked.
+	LastHealthErr error
+}
+
+// Signer is a [gcrypto.Signer] delegating to a [Token].
+//
+// Every signature returned by the token is verified against the public key
+// before it is returned, so a faulty token cannot cause the validator
+// to broadcast invalid signatures.
+type Signer struct {
+	log *slog.Logger
+
+	slowThreshold time.Duration
+
+	pub gcrypto.Ed25519PubKey
+
+	// Serializes access to the token.
+	tokenMu sync.Mutex
+	token   Token
+
+	statsMu sync.Mutex
+	stats   SignerStats
+}
+
+var _ gcrypto.Signer = (*Signer)(nil)
+
+// NewSigner returns a new Signer based on cfg,
+// reading the public key from the token.
+func NewSigner(log *slog.Logger, cfg SignerConfig) (*Signer, error) {
+	pub, err := cfg.Token.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key from token: %w", err)
+	}
+	if len(pub) != 32 {
+		return nil, fmt.Errorf("token returned %d-byte public key; expected 32-byte ed25519 key", len(pub))
+	}
+
+	return &Signer{
+		log: log,
+
+		slowThreshold: cfg.SlowSignThreshold,
+
+		pub: gcrypto.Ed25519PubKey(pub),
+
+		token: cfg.Token,
+	}, nil
+}
+
+func (s *Signer) PubKey() gcrypto.PubKey {
+	return s.pub
+}
+
+func (s *Signer) Sign(ctx context.Context, input []byte) ([]byte, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	// Token calls cannot be interrupted,
+	// so the best we can do is avoid starting one after cancellation,
+	// including cancellation while waiting for the lock.
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	sig, err := s.token.Sign(input)
+	latency := time.Since(start)
+
+	if err == nil && !s.pub.Verify(input, sig) {
+		err = errors.New("token returned a signature that does not verify")
+	}
+
+	s.statsMu.Lock()
+	s.stats.Signs++
+	if err != nil {
+		s.stats.SignErrors++
+	}
+	s.stats.LastLatency = latency
+	s.stats.MaxLatency = max(s.stats.MaxLatency, latency)
+	s.stats.TotalLatency += latency
+	s.statsMu.Unlock()
+
+	if s.slowThreshold > 0 && latency > s.slowThreshold {
+		s.log.Warn("Slow HSM signature", "latency", latency, "threshold", s.slowThreshold)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("HSM signing failed: %w", err)
+	}
+	return sig, nil
+}
+
+// Stats returns a snapshot of s's counters.
+func (s *Signer) Stats() SignerStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
+
+// CheckHealth pings the token, records the result in s's stats, and returns it.
+func (s *Signer) CheckHealth() error {
+	s.tokenMu.Lock()
+	err := s.token.Ping()
+	s.tokenMu.Unlock()
+
+	s.statsMu.Lock()
+	s.stats.LastHealthErr = err
+	s.statsMu.Unlock()
+
+	return err
+}
+
+// RunHealthChecks calls [*Signer.CheckHealth] every interval until ctx is cancelled,
+// logging when the token becomes unhealthy or recovers,
+// and logging the signing latency at debug level.
+func (s *Signer) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	healthy := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		err := s.CheckHealth()
+		if err != nil && healthy {
+			s.log.Warn("HSM health check failed", "err", err)
+		} else if err == nil && !healthy {
+			s.log.Info("HSM health check recovered")
+		}
+		healthy = err == nil
+
+		st := s.Stats()
+		var mean time.Duration
+		if st.Signs > 0 {
+			mean = st.TotalLatency / time.Duration(st.Signs)
+		}
+		s.log.Debug(
+			"HSM signer stats",
+			"signs", st.Signs,
+			"sign_errors", st.SignErrors,
+			"mean_latency", mean,
+			"max_latency", st.MaxLatency,
+		)
+	}
+}
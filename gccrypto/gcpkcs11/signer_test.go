@@ -0,0 +1,121 @@
+package gcpkcs11_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gccrypto/gcpkcs11"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeToken is a software [gcpkcs11.Token].
+type fakeToken struct {
+	priv ed25519.PrivateKey
+
+	corrupt bool
+	signErr error
+	pingErr error
+}
+
+func newFakeToken() *fakeToken {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		panic(err)
+	}
+	return &fakeToken{priv: priv}
+}
+
+func (t *fakeToken) PublicKey() ([]byte, error) {
+	return t.priv.Public().(ed25519.PublicKey), nil
+}
+
+func (t *fakeToken) Sign(msg []byte) ([]byte, error) {
+	if t.signErr != nil {
+		return nil, t.signErr
+	}
+	sig := ed25519.Sign(t.priv, msg)
+	if t.corrupt {
+		sig[0] ^= 0xff
+	}
+	return sig, nil
+}
+
+func (t *fakeToken) Ping() error { return t.pingErr }
+
+func TestSigner_Sign(t *testing.T) {
+	t.Parallel()
+
+	tok := newFakeToken()
+	s, err := gcpkcs11.NewSigner(gtest.NewLogger(t), gcpkcs11.SignerConfig{Token: tok})
+	require.NoError(t, err)
+
+	pub, err := tok.PublicKey()
+	require.NoError(t, err)
+	require.True(t, s.PubKey().Equal(gcrypto.Ed25519PubKey(pub)))
+
+	msg := []byte("hello")
+	sig, err := s.Sign(context.Background(), msg)
+	require.NoError(t, err)
+	require.True(t, s.PubKey().Verify(msg, sig))
+
+	st := s.Stats()
+	require.Equal(t, uint64(1), st.Signs)
+	require.Zero(t, st.SignErrors)
+	require.Equal(t, st.LastLatency, st.TotalLatency)
+}
+
+func TestSigner_Sign_errors(t *testing.T) {
+	t.Parallel()
+
+	tok := newFakeToken()
+	s, err := gcpkcs11.NewSigner(gtest.NewLogger(t), gcpkcs11.SignerConfig{Token: tok})
+	require.NoError(t, err)
+
+	t.Run("invalid signature", func(t *testing.T) {
+		tok.corrupt = true
+		defer func() { tok.corrupt = false }()
+
+		_, err := s.Sign(context.Background(), []byte("hello"))
+		require.Error(t, err)
+	})
+
+	t.Run("token error", func(t *testing.T) {
+		tok.signErr = errors.New("device removed")
+		defer func() { tok.signErr = nil }()
+
+		_, err := s.Sign(context.Background(), []byte("hello"))
+		require.ErrorIs(t, err, tok.signErr)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := s.Sign(ctx, []byte("hello"))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	// The cancelled sign never reached the token.
+	st := s.Stats()
+	require.Equal(t, uint64(2), st.Signs)
+	require.Equal(t, uint64(2), st.SignErrors)
+}
+
+func TestSigner_CheckHealth(t *testing.T) {
+	t.Parallel()
+
+	tok := newFakeToken()
+	s, err := gcpkcs11.NewSigner(gtest.NewLogger(t), gcpkcs11.SignerConfig{Token: tok})
+	require.NoError(t, err)
+
+	require.NoError(t, s.CheckHealth())
+	require.NoError(t, s.Stats().LastHealthErr)
+
+	tok.pingErr = errors.New("session closed")
+	require.ErrorIs(t, s.CheckHealth(), tok.pingErr)
+	require.ErrorIs(t, s.Stats().LastHealthErr, tok.pingErr)
+}
@@ -0,0 +1,22 @@
+//go:build !cgo || !pkcs11
+
+package gcpkcs11
+
+import (
+	"errors"
+)
+
+// OpenToken is unavailable in this build;
+// rebuild with cgo enabled and the "pkcs11" build tag.
+func OpenToken(cfg TokenConfig) (*ModuleToken, error) {
+	return nil, errors.New("PKCS#11 support not compiled in; rebuild with -tags pkcs11 and cgo enabled")
+}
+
+// ModuleToken is a [Token] backed by a PKCS#11 module.
+// It is only functional in builds with the "pkcs11" build tag.
+type ModuleToken struct{}
+
+func (*ModuleToken) PublicKey() ([]byte, error)  { return nil, errors.ErrUnsupported }
+func (*ModuleToken) Sign([]byte) ([]byte, error) { return nil, errors.ErrUnsupported }
+func (*ModuleToken) Ping() error                 { return errors.ErrUnsupported }
+func (*ModuleToken) Close() error                { return nil }
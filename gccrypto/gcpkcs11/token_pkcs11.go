@@ -0,0 +1,220 @@
+//go:build cgo && pkcs11
+
+package gcpkcs11
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/miekg/pkcs11"
+)
+
+// The ed25519 mechanism from PKCS#11 v3.0,
+// which github.com/miekg/pkcs11 does not define.
+const ckmEDDSA = 0x1057
+
+// RVError is a PKCS#11 return value other than CKR_OK.
+type RVError struct {
+	Func string
+	RV   uint
+}
+
+func (e RVError) Error() string {
+	return fmt.Sprintf("%s returned CKR 0x%X", e.Func, e.RV)
+}
+
+// rvError wraps an error returned from calling fn,
+// so that PKCS#11 return values are reported as an [RVError].
+func rvError(fn string, err error) error {
+	var rv pkcs11.Error
+	if errors.As(err, &rv) {
+		return RVError{Func: fn, RV: uint(rv)}
+	}
+	return fmt.Errorf("%s failed: %w", fn, err)
+}
+
+// isRV reports whether err is the PKCS#11 return value rv.
+func isRV(err error, rv uint) bool {
+	var e pkcs11.Error
+	return errors.As(err, &e) && uint(e) == rv
+}
+
+// ModuleToken is a [Token] backed by a PKCS#11 module.
+type ModuleToken struct {
+	p *pkcs11.Ctx
+
+	initialized bool
+	session     pkcs11.SessionHandle
+	privKey     pkcs11.ObjectHandle
+	pubKey      []byte
+}
+
+var _ Token = (*ModuleToken)(nil)
+
+// OpenToken loads the PKCS#11 module, logs in to the token,
+// and finds the key pair identified by cfg.
+// The caller must call Close when finished with the token.
+func OpenToken(cfg TokenConfig) (_ *ModuleToken, err error) {
+	p := pkcs11.New(cfg.ModulePath)
+	if p == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %q", cfg.ModulePath)
+	}
+
+	t := &ModuleToken{p: p}
+	defer func() {
+		if err != nil {
+			_ = t.Close()
+		}
+	}()
+
+	// The default initialization lets the module use OS locking,
+	// as Go calls it from many threads.
+	if err := p.Initialize(); err != nil && !isRV(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		return nil, rvError("C_Initialize", err)
+	}
+	t.initialized = true
+
+	slot, err := t.findSlot(cfg.TokenLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	t.session, err = p.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, rvError("C_OpenSession", err)
+	}
+
+	if err := p.Login(t.session, pkcs11.CKU_USER, cfg.PIN); err != nil && !isRV(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return nil, rvError("C_Login", err)
+	}
+
+	t.privKey, err = t.findObject(pkcs11.CKO_PRIVATE_KEY, cfg.KeyLabel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find private key %q: %w", cfg.KeyLabel, err)
+	}
+
+	pubObj, err := t.findObject(pkcs11.CKO_PUBLIC_KEY, cfg.KeyLabel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find public key %q: %w", cfg.KeyLabel, err)
+	}
+	t.pubKey, err = t.ecPoint(pubObj)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *ModuleToken) findSlot(label string) (uint, error) {
+	slots, err := t.p.GetSlotList(true)
+	if err != nil {
+		return 0, rvError("C_GetSlotList", err)
+	}
+	if len(slots) == 0 {
+		return 0, errors.New("no PKCS#11 slots with a token present")
+	}
+
+	for _, slot := range slots {
+		info, err := t.p.GetTokenInfo(slot)
+		if err != nil {
+			return 0, rvError("C_GetTokenInfo", err)
+		}
+
+		// The label is already trimmed of its space padding.
+		if info.Label == label {
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no PKCS#11 token with label %q", label)
+}
+
+// findObject returns the single object of the given class with the given label.
+func (t *ModuleToken) findObject(class uint, label string) (pkcs11.ObjectHandle, error) {
+	if err := t.p.FindObjectsInit(t.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}); err != nil {
+		return 0, rvError("C_FindObjectsInit", err)
+	}
+	defer t.p.FindObjectsFinal(t.session)
+
+	objs, _, err := t.p.FindObjects(t.session, 2)
+	if err != nil {
+		return 0, rvError("C_FindObjects", err)
+	}
+
+	switch len(objs) {
+	case 0:
+		return 0, errors.New("no matching object")
+	case 1:
+		return objs[0], nil
+	default:
+		return 0, errors.New("multiple matching objects")
+	}
+}
+
+// ecPoint reads the raw ed25519 public key from the CKA_EC_POINT attribute of obj.
+func (t *ModuleToken) ecPoint(obj pkcs11.ObjectHandle) ([]byte, error) {
+	attrs, err := t.p.GetAttributeValue(t.session, obj, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, rvError("C_GetAttributeValue", err)
+	}
+
+	point := attrs[0].Value
+	switch {
+	case len(point) == 32:
+		return point, nil
+	case len(point) == 34 && point[0] == 0x04 && point[1] == 32:
+		// DER OCTET STRING wrapping, as most modules encode it.
+		return point[2:], nil
+	default:
+		return nil, fmt.Errorf("unrecognized ed25519 public key encoding (%d bytes)", len(point))
+	}
+}
+
+func (t *ModuleToken) PublicKey() ([]byte, error) {
+	return bytes.Clone(t.pubKey), nil
+}
+
+func (t *ModuleToken) Sign(msg []byte) ([]byte, error) {
+	if err := t.p.SignInit(
+		t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(ckmEDDSA, nil)}, t.privKey,
+	); err != nil {
+		return nil, rvError("C_SignInit", err)
+	}
+
+	sig, err := t.p.Sign(t.session, msg)
+	if err != nil {
+		return nil, rvError("C_Sign", err)
+	}
+	return sig, nil
+}
+
+func (t *ModuleToken) Ping() error {
+	if _, err := t.p.GetSessionInfo(t.session); err != nil {
+		return rvError("C_GetSessionInfo", err)
+	}
+	return nil
+}
+
+// Close logs out, closes the session, and unloads the module.
+func (t *ModuleToken) Close() error {
+	if t.p == nil {
+		return nil
+	}
+
+	if t.session != 0 {
+		_ = t.p.Logout(t.session)
+		_ = t.p.CloseSession(t.session)
+	}
+	if t.initialized {
+		_ = t.p.Finalize()
+	}
+	t.p.Destroy()
+	t.p = nil
+	return nil
+}
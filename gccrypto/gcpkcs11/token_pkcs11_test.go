@@ -0,0 +1,167 @@
+//go:build cgo && pkcs11
+
+package gcpkcs11_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gccrypto/gcpkcs11"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests run against a real PKCS#11 module, such as SoftHSM2,
+// selected by the GCOSMOS_TEST_PKCS11_MODULE, GCOSMOS_TEST_PKCS11_TOKEN,
+// and GCOSMOS_TEST_PKCS11_PIN environment variables.
+// The token must already be initialized;
+// each test generates its own key pair on it.
+
+func TestOpenToken(t *testing.T) {
+	cfg := testTokenConfig(t)
+	cfg.KeyLabel = generateTestKey(t, cfg)
+
+	tok, err := gcpkcs11.OpenToken(cfg)
+	require.NoError(t, err)
+	defer tok.Close()
+
+	require.NoError(t, tok.Ping())
+
+	pub, err := tok.PublicKey()
+	require.NoError(t, err)
+	require.Len(t, pub, ed25519.PublicKeySize)
+
+	msg := []byte("hello")
+	sig, err := tok.Sign(msg)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(pub, msg, sig))
+
+	// The signer verifies every signature from the token,
+	// so a successful sign confirms the token's key encoding too.
+	s, err := gcpkcs11.NewSigner(gtest.NewLogger(t), gcpkcs11.SignerConfig{Token: tok})
+	require.NoError(t, err)
+	_, err = s.Sign(context.Background(), msg)
+	require.NoError(t, err)
+}
+
+func TestOpenToken_errors(t *testing.T) {
+	cfg := testTokenConfig(t)
+	cfg.KeyLabel = generateTestKey(t, cfg)
+
+	t.Run("unknown key", func(t *testing.T) {
+		c := cfg
+		c.KeyLabel = "no such key"
+		_, err := gcpkcs11.OpenToken(c)
+		require.ErrorContains(t, err, "no matching object")
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		c := cfg
+		c.TokenLabel = "no such token"
+		_, err := gcpkcs11.OpenToken(c)
+		require.ErrorContains(t, err, "no PKCS#11 token")
+	})
+
+	t.Run("wrong PIN", func(t *testing.T) {
+		c := cfg
+		c.PIN = c.PIN + "0"
+		_, err := gcpkcs11.OpenToken(c)
+		require.ErrorAs(t, err, new(gcpkcs11.RVError))
+	})
+
+	t.Run("missing module", func(t *testing.T) {
+		c := cfg
+		c.ModulePath = "/nonexistent/libpkcs11.so"
+		_, err := gcpkcs11.OpenToken(c)
+		require.Error(t, err)
+	})
+}
+
+func testTokenConfig(t *testing.T) gcpkcs11.TokenConfig {
+	t.Helper()
+
+	cfg := gcpkcs11.TokenConfig{
+		ModulePath: os.Getenv("GCOSMOS_TEST_PKCS11_MODULE"),
+		TokenLabel: os.Getenv("GCOSMOS_TEST_PKCS11_TOKEN"),
+		PIN:        os.Getenv("GCOSMOS_TEST_PKCS11_PIN"),
+	}
+	if cfg.ModulePath == "" || cfg.TokenLabel == "" || cfg.PIN == "" {
+		t.Skip("GCOSMOS_TEST_PKCS11_MODULE, GCOSMOS_TEST_PKCS11_TOKEN, and GCOSMOS_TEST_PKCS11_PIN not set")
+	}
+	return cfg
+}
+
+// generateTestKey generates a new ed25519 key pair on the token in cfg,
+// and returns the label shared by its public and private key objects.
+func generateTestKey(t *testing.T, cfg gcpkcs11.TokenConfig) string {
+	t.Helper()
+
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	label := "gcosmos-test-" + hex.EncodeToString(id[:])
+
+	p := pkcs11.New(cfg.ModulePath)
+	require.NotNil(t, p)
+	defer p.Destroy()
+
+	// The module is shared with the token under test,
+	// so finalize before it is opened there.
+	require.NoError(t, p.Initialize())
+	defer p.Finalize()
+
+	slots, err := p.GetSlotList(true)
+	require.NoError(t, err)
+	var slot uint
+	found := false
+	for _, s := range slots {
+		info, err := p.GetTokenInfo(s)
+		require.NoError(t, err)
+		if info.Label == cfg.TokenLabel {
+			slot, found = s, true
+			break
+		}
+	}
+	require.True(t, found, "no token with label %q", cfg.TokenLabel)
+
+	session, err := p.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	require.NoError(t, err)
+	defer p.CloseSession(session)
+
+	require.NoError(t, p.Login(session, pkcs11.CKU_USER, cfg.PIN))
+	defer p.Logout(session)
+
+	const (
+		ckmECEdwardsKeyPairGen = 0x1055
+		ckkECEdwards           = 0x40
+	)
+	// DER encoding of the ed25519 object identifier, 1.3.101.112.
+	ed25519Params := []byte{0x06, 0x03, 0x2b, 0x65, 0x70}
+
+	_, _, err = p.GenerateKeyPair(
+		session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(ckmECEdwardsKeyPairGen, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, ckkECEdwards),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ed25519Params),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, ckkECEdwards),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		},
+	)
+	require.NoError(t, err)
+
+	return label
+}
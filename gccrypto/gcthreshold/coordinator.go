@@ -0,0 +1,282 @@
+package gcthreshold
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+)
+
+// Participant is one holder of a [KeyShare], as seen by the [Coordinator].
+// A participant is usually on another machine;
+// [*LocalParticipant] holds the share in process.
+type Participant interface {
+	// ID returns the participant's identifier, matching its KeyShare.
+	ID() uint16
+
+	// Commit runs the first signing round,
+	// retaining the nonces until the matching call to Sign.
+	Commit(ctx context.Context) (Commitment, error)
+
+	// Sign runs the second signing round,
+	// using the nonces for the participant's commitment in the commitments list.
+	Sign(ctx context.Context, msg []byte, commitments []Commitment) (SignatureShare, error)
+}
+
+// maxPendingNonces bounds the nonces a LocalParticipant retains
+// for commitments whose signing round never arrived.
+const maxPendingNonces = 64
+
+// LocalParticipant is a [Participant] holding its [KeyShare] in memory.
+// It is safe for concurrent use.
+type LocalParticipant struct {
+	share KeyShare
+
+	mu sync.Mutex
+
+	// Nonces awaiting their signing round, oldest first.
+	pending []*Nonces
+}
+
+// NewLocalParticipant returns a new LocalParticipant for share.
+func NewLocalParticipant(share KeyShare) *LocalParticipant {
+	return &LocalParticipant{share: share}
+}
+
+func (p *LocalParticipant) ID() uint16 {
+	return p.share.ID
+}
+
+func (p *LocalParticipant) Commit(context.Context) (Commitment, error) {
+	n, c, err := p.share.Commit(rand.Reader)
+	if err != nil {
+		return Commitment{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) >= maxPendingNonces {
+		// Drop the oldest; its signing round is not coming.
+		p.pending = slices.Delete(p.pending, 0, 1)
+	}
+	p.pending = append(p.pending, n)
+
+	return c, nil
+}
+
+func (p *LocalParticipant) Sign(_ context.Context, msg []byte, commitments []Commitment) (SignatureShare, error) {
+	idx := slices.IndexFunc(commitments, func(c Commitment) bool { return c.ID == p.share.ID })
+	if idx < 0 {
+		return SignatureShare{}, fmt.Errorf("commitment list does not include participant %d", p.share.ID)
+	}
+
+	// Remove the nonces before signing, so they can only ever be used once.
+	p.mu.Lock()
+	ni := slices.IndexFunc(p.pending, func(n *Nonces) bool {
+		return commitmentsEqual(n.commitment, commitments[idx])
+	})
+	var n *Nonces
+	if ni >= 0 {
+		n = p.pending[ni]
+		p.pending = slices.Delete(p.pending, ni, ni+1)
+	}
+	p.mu.Unlock()
+
+	if n == nil {
+		return SignatureShare{}, errors.New("no pending nonces for commitment")
+	}
+
+	return p.share.Sign(n, msg, commitments)
+}
+
+// CoordinatorConfig is the configuration for [NewCoordinator].
+type CoordinatorConfig struct {
+	Group GroupKey
+
+	// At least Group.Threshold participants.
+	Participants []Participant
+
+	// Maximum duration of each signing round.
+	// Zero means rounds are only bounded by the context passed to Sign.
+	RoundTimeout time.Duration
+}
+
+// Coordinator is a [gcrypto.Signer] producing threshold signatures
+// by running the two FROST rounds against its participants.
+// The coordinator holds no secret key material.
+//
+// Each signature uses the first threshold participants
+// to return a commitment, so slow or offline participants
+// do not hold up signing as long as enough others are available.
+type Coordinator struct {
+	log *slog.Logger
+
+	group        GroupKey
+	participants []Participant
+	roundTimeout time.Duration
+}
+
+var _ gcrypto.Signer = (*Coordinator)(nil)
+
+// NewCoordinator returns a new Coordinator based on cfg.
+func NewCoordinator(log *slog.Logger, cfg CoordinatorConfig) (*Coordinator, error) {
+	if cfg.Group.Threshold < 1 {
+		return nil, fmt.Errorf("invalid group threshold %d", cfg.Group.Threshold)
+	}
+	if len(cfg.Participants) < cfg.Group.Threshold {
+		return nil, fmt.Errorf(
+			"need at least %d participants for threshold; got %d",
+			cfg.Group.Threshold, len(cfg.Participants),
+		)
+	}
+
+	seen := make(map[uint16]bool, len(cfg.Participants))
+	for _, p := range cfg.Participants {
+		id := p.ID()
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate participant %d", id)
+		}
+		seen[id] = true
+
+		if _, ok := cfg.Group.VerifyingShares[id]; !ok {
+			return nil, fmt.Errorf("no verifying share for participant %d", id)
+		}
+	}
+
+	return &Coordinator{
+		log: log,
+
+		group:        cfg.Group,
+		participants: slices.Clone(cfg.Participants),
+		roundTimeout: cfg.RoundTimeout,
+	}, nil
+}
+
+func (c *Coordinator) PubKey() gcrypto.PubKey {
+	return c.group.PubKey
+}
+
+func (c *Coordinator) Sign(ctx context.Context, input []byte) ([]byte, error) {
+	signers, commitments, err := c.commitRound(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := c.signRound(ctx, signers, input, commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := c.group.Aggregate(input, commitments, shares)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate threshold signature: %w", err)
+	}
+	return sig, nil
+}
+
+// commitRound collects commitments from the first threshold participants to respond,
+// returning those participants and their commitments, both sorted by identifier.
+func (c *Coordinator) commitRound(ctx context.Context) ([]Participant, []Commitment, error) {
+	ctx, cancel := c.roundContext(ctx)
+	defer cancel()
+
+	type result struct {
+		p   Participant
+		c   Commitment
+		err error
+	}
+	results := make(chan result, len(c.participants))
+	for _, p := range c.participants {
+		go func() {
+			cm, err := p.Commit(ctx)
+			if err == nil && cm.ID != p.ID() {
+				err = fmt.Errorf("commitment has identifier %d", cm.ID)
+			}
+			results <- result{p: p, c: cm, err: err}
+		}()
+	}
+
+	signers := make([]Participant, 0, c.group.Threshold)
+	commitments := make([]Commitment, 0, c.group.Threshold)
+	var errs []error
+	for range c.participants {
+		var r result
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf(
+				"only %d of %d required commitments before round ended: %w",
+				len(commitments), c.group.Threshold, errors.Join(append(errs, context.Cause(ctx))...),
+			)
+		case r = <-results:
+		}
+
+		if r.err != nil {
+			c.log.Warn("Threshold participant failed to commit", "participant", r.p.ID(), "err", r.err)
+			errs = append(errs, fmt.Errorf("participant %d: %w", r.p.ID(), r.err))
+			continue
+		}
+
+		signers = append(signers, r.p)
+		commitments = append(commitments, r.c)
+		if len(commitments) == c.group.Threshold {
+			break
+		}
+	}
+
+	if len(commitments) < c.group.Threshold {
+		return nil, nil, fmt.Errorf(
+			"only %d of %d required commitments: %w",
+			len(commitments), c.group.Threshold, errors.Join(errs...),
+		)
+	}
+
+	slices.SortFunc(signers, func(a, b Participant) int { return int(a.ID()) - int(b.ID()) })
+	slices.SortFunc(commitments, func(a, b Commitment) int { return int(a.ID) - int(b.ID) })
+	return signers, commitments, nil
+}
+
+// signRound collects a signature share from every participant in signers.
+func (c *Coordinator) signRound(
+	ctx context.Context, signers []Participant, msg []byte, commitments []Commitment,
+) ([]SignatureShare, error) {
+	ctx, cancel := c.roundContext(ctx)
+	defer cancel()
+
+	shares := make([]SignatureShare, len(signers))
+	errs := make([]error, len(signers))
+	var wg sync.WaitGroup
+	for i, p := range signers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sh, err := p.Sign(ctx, msg, commitments)
+			if err == nil && sh.ID != p.ID() {
+				err = fmt.Errorf("signature share has identifier %d", sh.ID)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("participant %d: %w", p.ID(), err)
+				return
+			}
+			shares[i] = sh
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("failed to collect signature shares: %w", err)
+	}
+	return shares, nil
+}
+
+func (c *Coordinator) roundContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.roundTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.roundTimeout)
+}
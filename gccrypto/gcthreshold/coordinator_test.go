@@ -0,0 +1,143 @@
+package gcthreshold_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gccrypto/gcthreshold"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+// offlineParticipant is a [gcthreshold.Participant] that always fails.
+type offlineParticipant struct {
+	id uint16
+}
+
+func (p offlineParticipant) ID() uint16 { return p.id }
+
+func (offlineParticipant) Commit(context.Context) (gcthreshold.Commitment, error) {
+	return gcthreshold.Commitment{}, errors.New("offline")
+}
+
+func (offlineParticipant) Sign(context.Context, []byte, []gcthreshold.Commitment) (gcthreshold.SignatureShare, error) {
+	return gcthreshold.SignatureShare{}, errors.New("offline")
+}
+
+// fixedRandParticipant is a [gcthreshold.Participant]
+// drawing its nonce randomness from a fixed source,
+// supporting one signature.
+type fixedRandParticipant struct {
+	share gcthreshold.KeyShare
+	rand  io.Reader
+
+	nonces *gcthreshold.Nonces
+}
+
+func (p *fixedRandParticipant) ID() uint16 { return p.share.ID }
+
+func (p *fixedRandParticipant) Commit(context.Context) (gcthreshold.Commitment, error) {
+	n, c, err := p.share.Commit(p.rand)
+	p.nonces = n
+	return c, err
+}
+
+func (p *fixedRandParticipant) Sign(
+	_ context.Context, msg []byte, commitments []gcthreshold.Commitment,
+) (gcthreshold.SignatureShare, error) {
+	return p.share.Sign(p.nonces, msg, commitments)
+}
+
+func TestCoordinator_Sign(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, g, err := gcthreshold.SplitKey(priv, 2, 3, rand.Reader)
+	require.NoError(t, err)
+
+	c, err := gcthreshold.NewCoordinator(gtest.NewLogger(t), gcthreshold.CoordinatorConfig{
+		Group: g,
+		Participants: []gcthreshold.Participant{
+			gcthreshold.NewLocalParticipant(shares[0]),
+			offlineParticipant{id: shares[1].ID},
+			gcthreshold.NewLocalParticipant(shares[2]),
+		},
+	})
+	require.NoError(t, err)
+
+	for _, msg := range []string{"one", "two", "three"} {
+		sig, err := c.Sign(context.Background(), []byte(msg))
+		require.NoError(t, err)
+		require.True(t, c.PubKey().Verify([]byte(msg), sig))
+	}
+}
+
+func TestCoordinator_Sign_rfc9591(t *testing.T) {
+	t.Parallel()
+
+	// Participant 2 is offline, so the coordinator signs with 1 and 3,
+	// exactly as in the RFC 9591 vectors.
+	participants := []gcthreshold.Participant{offlineParticipant{id: 2}}
+	for _, p := range rfc9591Signers() {
+		participants = append(participants, &fixedRandParticipant{
+			share: p.KeyShare(t),
+			rand:  p.Randomness(t),
+		})
+	}
+
+	c, err := gcthreshold.NewCoordinator(gtest.NewLogger(t), gcthreshold.CoordinatorConfig{
+		Group:        rfc9591GroupKey(t),
+		Participants: participants,
+	})
+	require.NoError(t, err)
+
+	sig, err := c.Sign(context.Background(), mustHex(t, rfc9591.Message))
+	require.NoError(t, err)
+	require.Equal(t, rfc9591.Sig, hex.EncodeToString(sig))
+}
+
+func TestCoordinator_Sign_tooFewOnline(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, g, err := gcthreshold.SplitKey(priv, 2, 3, rand.Reader)
+	require.NoError(t, err)
+
+	c, err := gcthreshold.NewCoordinator(gtest.NewLogger(t), gcthreshold.CoordinatorConfig{
+		Group: g,
+		Participants: []gcthreshold.Participant{
+			gcthreshold.NewLocalParticipant(shares[0]),
+			offlineParticipant{id: shares[1].ID},
+			offlineParticipant{id: shares[2].ID},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = c.Sign(context.Background(), []byte("msg"))
+	require.Error(t, err)
+}
+
+func TestNewCoordinator_tooFewParticipants(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, g, err := gcthreshold.SplitKey(priv, 2, 3, rand.Reader)
+	require.NoError(t, err)
+
+	_, err = gcthreshold.NewCoordinator(gtest.NewLogger(t), gcthreshold.CoordinatorConfig{
+		Group:        g,
+		Participants: []gcthreshold.Participant{gcthreshold.NewLocalParticipant(shares[0])},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,505 @@
+// Package gcthreshold implements t-of-n threshold ed25519 signing
+// using FROST (RFC 9591) with the FROST(Ed25519, SHA-512) ciphersuite,
+// so that one validator identity can be operated by several machines
+// (distributed validator technology) without any one of them holding the full key.
+//
+// An aggregated FROST signature is an ordinary ed25519 signature
+// over the group's public key.
+// The group key is therefore a plain [gcrypto.Ed25519PubKey],
+// and threshold signatures are accepted unchanged by
+// gcrypto.SimpleCommonMessageSignatureProof and every other consumer of ed25519 keys.
+//
+// Signing takes two rounds.
+// In the first, each participating [KeyShare] produces single-use [Nonces]
+// and publishes the matching [Commitment].
+// In the second, each participant signs over the message and the full commitment list,
+// producing a [SignatureShare].
+// [GroupKey.Aggregate] combines the shares into the final signature.
+// The [Coordinator] drives both rounds as a [gcrypto.Signer].
+package gcthreshold
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"filippo.io/edwards25519"
+	"github.com/gordian-engine/gordian/gcrypto"
+)
+
+// contextString is the FROST(Ed25519, SHA-512) ciphersuite context string.
+const contextString = "FROST-ED25519-SHA512-v1"
+
+// KeyShare is one participant's share of a group signing key.
+// The Secret field must be kept as private as a full signing key.
+type KeyShare struct {
+	// Participant identifier, starting at 1.
+	ID uint16
+
+	// Canonical little-endian encoding of the secret share scalar.
+	Secret []byte
+
+	// Group public key, for which aggregated signatures are valid.
+	GroupPubKey gcrypto.Ed25519PubKey
+}
+
+// GroupKey is the public information about a split key,
+// needed to verify signature shares and aggregate them.
+type GroupKey struct {
+	PubKey gcrypto.Ed25519PubKey
+
+	// Minimum number of participants required to sign.
+	Threshold int
+
+	// Each participant's public verifying share, keyed by participant identifier.
+	VerifyingShares map[uint16][]byte
+}
+
+// SplitKey splits priv into n shares, any threshold of which can sign for priv's public key.
+// This is a trusted dealer key generation:
+// priv should be split on a secure machine and then destroyed,
+// and each share should be delivered only to its participant.
+//
+// Splitting an existing key allows an existing validator to move to threshold signing
+// without changing its identity.
+func SplitKey(priv ed25519.PrivateKey, threshold, n int, rand io.Reader) ([]KeyShare, GroupKey, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, GroupKey{}, fmt.Errorf("invalid ed25519 private key length %d", len(priv))
+	}
+	if threshold < 1 || threshold > n {
+		return nil, GroupKey{}, fmt.Errorf("threshold must be in [1, %d]; got %d", n, threshold)
+	}
+	if n > 0xffff {
+		return nil, GroupKey{}, fmt.Errorf("at most %d shares are supported; got %d", 0xffff, n)
+	}
+
+	// Derive the signing scalar exactly as crypto/ed25519 does,
+	// so that the group public key equals priv's public key.
+	h := sha512.Sum512(priv.Seed())
+	s, err := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		panic(fmt.Errorf("BUG: clamping failed: %w", err))
+	}
+
+	// Random polynomial of degree threshold-1 with constant term s.
+	coeffs := make([]*edwards25519.Scalar, threshold)
+	coeffs[0] = s
+	for i := 1; i < threshold; i++ {
+		coeffs[i], err = randomScalar(rand)
+		if err != nil {
+			return nil, GroupKey{}, err
+		}
+	}
+
+	pub := gcrypto.Ed25519PubKey(edwards25519.NewIdentityPoint().ScalarBaseMult(s).Bytes())
+
+	shares := make([]KeyShare, n)
+	g := GroupKey{
+		PubKey:          pub,
+		Threshold:       threshold,
+		VerifyingShares: make(map[uint16][]byte, n),
+	}
+	for i := range shares {
+		id := uint16(i + 1)
+
+		// Evaluate the polynomial at id with Horner's method.
+		x := scalarFromID(id)
+		y := edwards25519.NewScalar()
+		for j := len(coeffs) - 1; j >= 0; j-- {
+			y.MultiplyAdd(y, x, coeffs[j])
+		}
+
+		shares[i] = KeyShare{
+			ID:          id,
+			Secret:      y.Bytes(),
+			GroupPubKey: pub,
+		}
+		g.VerifyingShares[id] = edwards25519.NewIdentityPoint().ScalarBaseMult(y).Bytes()
+	}
+
+	return shares, g, nil
+}
+
+// Nonces are a participant's secret nonces for a single signing operation.
+// They must never be used for more than one signature;
+// [KeyShare.Sign] clears them after use.
+type Nonces struct {
+	hiding, binding *edwards25519.Scalar
+
+	commitment Commitment
+}
+
+// Commitment is a participant's public commitment to its [Nonces].
+type Commitment struct {
+	ID uint16
+
+	// Encoded hiding and binding nonce commitment points.
+	Hiding, Binding []byte
+}
+
+// SignatureShare is a participant's contribution to a threshold signature.
+type SignatureShare struct {
+	ID uint16
+
+	// Canonical little-endian encoding of the share scalar.
+	Z []byte
+}
+
+// Commit runs the first signing round for ks,
+// returning fresh nonces to keep secret and the commitment to publish.
+func (ks KeyShare) Commit(rand io.Reader) (*Nonces, Commitment, error) {
+	secret, err := edwards25519.NewScalar().SetCanonicalBytes(ks.Secret)
+	if err != nil {
+		return nil, Commitment{}, fmt.Errorf("invalid secret share: %w", err)
+	}
+
+	hiding, err := generateNonce(secret, rand)
+	if err != nil {
+		return nil, Commitment{}, err
+	}
+	binding, err := generateNonce(secret, rand)
+	if err != nil {
+		return nil, Commitment{}, err
+	}
+
+	c := Commitment{
+		ID:      ks.ID,
+		Hiding:  edwards25519.NewIdentityPoint().ScalarBaseMult(hiding).Bytes(),
+		Binding: edwards25519.NewIdentityPoint().ScalarBaseMult(binding).Bytes(),
+	}
+	return &Nonces{hiding: hiding, binding: binding, commitment: c}, c, nil
+}
+
+// Commitment returns the commitment matching n.
+func (n *Nonces) Commitment() Commitment {
+	return n.commitment
+}
+
+// Sign runs the second signing round for ks,
+// producing a signature share over msg.
+// The commitments must be those of every participant in this signature,
+// including the commitment for nonces.
+//
+// The nonces are cleared before Sign returns, whether or not it succeeds,
+// so that they can never be reused.
+func (ks KeyShare) Sign(nonces *Nonces, msg []byte, commitments []Commitment) (SignatureShare, error) {
+	if nonces.hiding == nil {
+		return SignatureShare{}, errors.New("nonces already used")
+	}
+	hiding, binding := nonces.hiding, nonces.binding
+	nonces.hiding, nonces.binding = nil, nil
+
+	if nonces.commitment.ID != ks.ID {
+		return SignatureShare{}, fmt.Errorf("nonces belong to participant %d, not %d", nonces.commitment.ID, ks.ID)
+	}
+
+	secret, err := edwards25519.NewScalar().SetCanonicalBytes(ks.Secret)
+	if err != nil {
+		return SignatureShare{}, fmt.Errorf("invalid secret share: %w", err)
+	}
+
+	st, err := newSigningState(ks.GroupPubKey, msg, commitments)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+
+	idx, ok := st.index(ks.ID)
+	if !ok {
+		return SignatureShare{}, fmt.Errorf("commitment list does not include participant %d", ks.ID)
+	}
+	if !commitmentsEqual(commitments[idx], nonces.commitment) {
+		return SignatureShare{}, fmt.Errorf("commitment list has a different commitment for participant %d", ks.ID)
+	}
+
+	// z = hiding + binding*rho + lambda*secret*challenge.
+	z := edwards25519.NewScalar().Multiply(st.lambda(idx), secret)
+	z.Multiply(z, st.challenge)
+	z.MultiplyAdd(binding, st.rhos[idx], z)
+	z.Add(z, hiding)
+
+	return SignatureShare{ID: ks.ID, Z: z.Bytes()}, nil
+}
+
+// InvalidShareError is returned by [GroupKey.Aggregate]
+// when a participant's signature share does not verify,
+// identifying the misbehaving participant.
+type InvalidShareError struct {
+	ID uint16
+}
+
+func (e InvalidShareError) Error() string {
+	return fmt.Sprintf("invalid signature share from participant %d", e.ID)
+}
+
+// Aggregate verifies the signature shares and combines them
+// into an ed25519 signature of msg for g.PubKey.
+// There must be exactly one share for each commitment.
+func (g GroupKey) Aggregate(msg []byte, commitments []Commitment, shares []SignatureShare) ([]byte, error) {
+	if len(commitments) < g.Threshold {
+		return nil, fmt.Errorf("need at least %d commitments; got %d", g.Threshold, len(commitments))
+	}
+	if len(shares) != len(commitments) {
+		return nil, fmt.Errorf("got %d shares for %d commitments", len(shares), len(commitments))
+	}
+
+	st, err := newSigningState(g.PubKey, msg, commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	z := edwards25519.NewScalar()
+	seen := make([]bool, len(commitments))
+	for _, sh := range shares {
+		idx, ok := st.index(sh.ID)
+		if !ok {
+			return nil, fmt.Errorf("share from participant %d without a commitment", sh.ID)
+		}
+		if seen[idx] {
+			return nil, fmt.Errorf("duplicate share from participant %d", sh.ID)
+		}
+		seen[idx] = true
+
+		zi, err := edwards25519.NewScalar().SetCanonicalBytes(sh.Z)
+		if err != nil {
+			return nil, InvalidShareError{ID: sh.ID}
+		}
+		if err := g.verifyShare(st, idx, zi); err != nil {
+			return nil, err
+		}
+
+		z.Add(z, zi)
+	}
+
+	sig := append(st.groupCommitment.Bytes(), z.Bytes()...)
+
+	// Every share verified, so this cannot fail unless there is a bug;
+	// but it is cheap insurance against broadcasting an invalid signature.
+	if !ed25519.Verify(ed25519.PublicKey(g.PubKey), msg, sig) {
+		return nil, errors.New("aggregated signature failed verification")
+	}
+
+	return sig, nil
+}
+
+// verifyShare checks z*B == D + rho*E + (lambda*challenge)*Y
+// for the participant at index idx in st.
+func (g GroupKey) verifyShare(st *signingState, idx int, z *edwards25519.Scalar) error {
+	id := st.ids[idx]
+
+	vs, ok := g.VerifyingShares[id]
+	if !ok {
+		return fmt.Errorf("no verifying share for participant %d", id)
+	}
+	y, err := edwards25519.NewIdentityPoint().SetBytes(vs)
+	if err != nil {
+		return fmt.Errorf("invalid verifying share for participant %d: %w", id, err)
+	}
+
+	l := edwards25519.NewScalar().Multiply(st.lambda(idx), st.challenge)
+	want := edwards25519.NewIdentityPoint().ScalarMult(l, y)
+	want.Add(want, st.commitmentShares[idx])
+
+	got := edwards25519.NewIdentityPoint().ScalarBaseMult(z)
+	if got.Equal(want) != 1 {
+		return InvalidShareError{ID: id}
+	}
+	return nil
+}
+
+// signingState holds the values derived from a message and commitment list
+// that are shared between signing and aggregation.
+type signingState struct {
+	// Participant identifiers and their scalars, sorted ascending.
+	ids    []uint16
+	idScas []*edwards25519.Scalar
+
+	// Binding factor and commitment share for each participant.
+	rhos             []*edwards25519.Scalar
+	commitmentShares []*edwards25519.Point
+
+	groupCommitment *edwards25519.Point
+	challenge       *edwards25519.Scalar
+}
+
+func newSigningState(groupPub gcrypto.Ed25519PubKey, msg []byte, commitments []Commitment) (*signingState, error) {
+	if len(commitments) == 0 {
+		return nil, errors.New("empty commitment list")
+	}
+	if !slices.IsSortedFunc(commitments, func(a, b Commitment) int { return int(a.ID) - int(b.ID) }) {
+		return nil, errors.New("commitment list must be sorted by participant identifier")
+	}
+
+	st := &signingState{
+		ids:              make([]uint16, len(commitments)),
+		idScas:           make([]*edwards25519.Scalar, len(commitments)),
+		rhos:             make([]*edwards25519.Scalar, len(commitments)),
+		commitmentShares: make([]*edwards25519.Point, len(commitments)),
+	}
+
+	hidings := make([]*edwards25519.Point, len(commitments))
+	bindings := make([]*edwards25519.Point, len(commitments))
+
+	// encode_group_commitment_list.
+	var encoded []byte
+	for i, c := range commitments {
+		if c.ID == 0 {
+			return nil, errors.New("participant identifier 0 is invalid")
+		}
+		if i > 0 && c.ID == commitments[i-1].ID {
+			return nil, fmt.Errorf("duplicate commitment for participant %d", c.ID)
+		}
+
+		var err error
+		hidings[i], err = decodeCommitmentPoint(c.Hiding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hiding commitment from participant %d: %w", c.ID, err)
+		}
+		bindings[i], err = decodeCommitmentPoint(c.Binding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid binding commitment from participant %d: %w", c.ID, err)
+		}
+
+		st.ids[i] = c.ID
+		st.idScas[i] = scalarFromID(c.ID)
+
+		encoded = append(encoded, st.idScas[i].Bytes()...)
+		encoded = append(encoded, c.Hiding...)
+		encoded = append(encoded, c.Binding...)
+	}
+
+	// compute_binding_factors.
+	msgHash := h4(msg)
+	comHash := h5(encoded)
+	prefix := make([]byte, 0, len(groupPub)+len(msgHash)+len(comHash)+32)
+	prefix = append(prefix, groupPub...)
+	prefix = append(prefix, msgHash...)
+	prefix = append(prefix, comHash...)
+
+	// compute_group_commitment.
+	st.groupCommitment = edwards25519.NewIdentityPoint()
+	for i := range commitments {
+		st.rhos[i] = h1(append(prefix, st.idScas[i].Bytes()...))
+
+		cs := edwards25519.NewIdentityPoint().ScalarMult(st.rhos[i], bindings[i])
+		cs.Add(cs, hidings[i])
+		st.commitmentShares[i] = cs
+
+		st.groupCommitment.Add(st.groupCommitment, cs)
+	}
+
+	// compute_challenge, which is the standard ed25519 challenge.
+	st.challenge = h2(st.groupCommitment.Bytes(), groupPub, msg)
+
+	return st, nil
+}
+
+// index returns the position of participant id in st.
+func (st *signingState) index(id uint16) (int, bool) {
+	return slices.BinarySearch(st.ids, id)
+}
+
+// lambda returns the Lagrange coefficient at zero
+// for the participant at index idx, over all participants in st.
+func (st *signingState) lambda(idx int) *edwards25519.Scalar {
+	num := scalarFromID(1)
+	den := scalarFromID(1)
+	diff := edwards25519.NewScalar()
+	for j, x := range st.idScas {
+		if j == idx {
+			continue
+		}
+		num.Multiply(num, x)
+		den.Multiply(den, diff.Subtract(x, st.idScas[idx]))
+	}
+	return num.Multiply(num, edwards25519.NewScalar().Invert(den))
+}
+
+func commitmentsEqual(a, b Commitment) bool {
+	return a.ID == b.ID && string(a.Hiding) == string(b.Hiding) && string(a.Binding) == string(b.Binding)
+}
+
+var identityBytes = edwards25519.NewIdentityPoint().Bytes()
+
+func decodeCommitmentPoint(b []byte) (*edwards25519.Point, error) {
+	if string(b) == string(identityBytes) {
+		return nil, errors.New("identity element")
+	}
+	return edwards25519.NewIdentityPoint().SetBytes(b)
+}
+
+func scalarFromID(id uint16) *edwards25519.Scalar {
+	var b [32]byte
+	b[0], b[1] = byte(id), byte(id>>8)
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(b[:])
+	if err != nil {
+		panic(fmt.Errorf("BUG: identifier scalar not canonical: %w", err))
+	}
+	return s
+}
+
+func randomScalar(rand io.Reader) (*edwards25519.Scalar, error) {
+	var b [64]byte
+	if _, err := io.ReadFull(rand, b[:]); err != nil {
+		return nil, fmt.Errorf("failed to read randomness: %w", err)
+	}
+	s, err := edwards25519.NewScalar().SetUniformBytes(b[:])
+	if err != nil {
+		panic(fmt.Errorf("BUG: uniform bytes rejected: %w", err))
+	}
+	return s, nil
+}
+
+// generateNonce is nonce_generate from RFC 9591,
+// mixing the secret share into the randomness
+// so that a weak random source alone does not expose the key.
+func generateNonce(secret *edwards25519.Scalar, rand io.Reader) (*edwards25519.Scalar, error) {
+	var b [32]byte
+	if _, err := io.ReadFull(rand, b[:]); err != nil {
+		return nil, fmt.Errorf("failed to read randomness: %w", err)
+	}
+	return h3(b[:], secret.Bytes()), nil
+}
+
+// The ciphersuite hash functions H1 through H5.
+
+func h1(m []byte) *edwards25519.Scalar {
+	return hashToScalar([]byte(contextString+"rho"), m)
+}
+
+func h2(ms ...[]byte) *edwards25519.Scalar {
+	// No context string, for compatibility with ed25519 verification.
+	return hashToScalar(ms...)
+}
+
+func h3(ms ...[]byte) *edwards25519.Scalar {
+	return hashToScalar(append([][]byte{[]byte(contextString + "nonce")}, ms...)...)
+}
+
+func h4(m []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(contextString + "msg"))
+	h.Write(m)
+	return h.Sum(nil)
+}
+
+func h5(m []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(contextString + "com"))
+	h.Write(m)
+	return h.Sum(nil)
+}
+
+func hashToScalar(ms ...[]byte) *edwards25519.Scalar {
+	h := sha512.New()
+	for _, m := range ms {
+		h.Write(m)
+	}
+	s, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		panic(fmt.Errorf("BUG: uniform bytes rejected: %w", err))
+	}
+	return s
+}
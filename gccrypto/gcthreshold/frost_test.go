@@ -0,0 +1,145 @@
+package gcthreshold_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gccrypto/gcthreshold"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/stretchr/testify/require"
+)
+
+// thresholdSign runs both rounds with the given shares, in order, and aggregates.
+func thresholdSign(
+	t *testing.T, g gcthreshold.GroupKey, shares []gcthreshold.KeyShare, msg []byte,
+) ([]byte, error) {
+	t.Helper()
+
+	nonces := make([]*gcthreshold.Nonces, len(shares))
+	commitments := make([]gcthreshold.Commitment, len(shares))
+	for i, ks := range shares {
+		var err error
+		nonces[i], commitments[i], err = ks.Commit(rand.Reader)
+		require.NoError(t, err)
+	}
+
+	sigShares := make([]gcthreshold.SignatureShare, len(shares))
+	for i, ks := range shares {
+		var err error
+		sigShares[i], err = ks.Sign(nonces[i], msg, commitments)
+		require.NoError(t, err)
+	}
+
+	return g.Aggregate(msg, commitments, sigShares)
+}
+
+func TestSplitKey_groupKeyMatches(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, g, err := gcthreshold.SplitKey(priv, 2, 3, rand.Reader)
+	require.NoError(t, err)
+	require.Len(t, shares, 3)
+
+	require.True(t, g.PubKey.Equal(gcrypto.Ed25519PubKey(pub)))
+	for _, ks := range shares {
+		require.True(t, ks.GroupPubKey.Equal(g.PubKey))
+	}
+}
+
+func TestThresholdSign_everySubset(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, g, err := gcthreshold.SplitKey(priv, 3, 5, rand.Reader)
+	require.NoError(t, err)
+
+	msg := []byte("threshold")
+	n := len(shares)
+	for mask := 0; mask < 1<<n; mask++ {
+		var subset []gcthreshold.KeyShare
+		for i := range n {
+			if mask&(1<<i) != 0 {
+				subset = append(subset, shares[i])
+			}
+		}
+		if len(subset) < g.Threshold {
+			continue
+		}
+
+		sig, err := thresholdSign(t, g, subset, msg)
+		require.NoError(t, err, "subset mask %b", mask)
+		require.True(t, g.PubKey.Verify(msg, sig), "subset mask %b", mask)
+	}
+}
+
+func TestThresholdSign_belowThreshold(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, g, err := gcthreshold.SplitKey(priv, 3, 5, rand.Reader)
+	require.NoError(t, err)
+
+	_, err = thresholdSign(t, g, shares[:2], []byte("threshold"))
+	require.Error(t, err)
+}
+
+func TestKeyShare_Sign_noncesSingleUse(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, _, err := gcthreshold.SplitKey(priv, 1, 1, rand.Reader)
+	require.NoError(t, err)
+
+	ks := shares[0]
+	n, c, err := ks.Commit(rand.Reader)
+	require.NoError(t, err)
+
+	commitments := []gcthreshold.Commitment{c}
+	_, err = ks.Sign(n, []byte("one"), commitments)
+	require.NoError(t, err)
+
+	_, err = ks.Sign(n, []byte("two"), commitments)
+	require.Error(t, err)
+}
+
+func TestGroupKey_Aggregate_invalidShare(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	shares, g, err := gcthreshold.SplitKey(priv, 2, 3, rand.Reader)
+	require.NoError(t, err)
+
+	msg := []byte("threshold")
+	signers := shares[1:]
+
+	nonces := make([]*gcthreshold.Nonces, len(signers))
+	commitments := make([]gcthreshold.Commitment, len(signers))
+	for i, ks := range signers {
+		nonces[i], commitments[i], err = ks.Commit(rand.Reader)
+		require.NoError(t, err)
+	}
+
+	sigShares := make([]gcthreshold.SignatureShare, len(signers))
+	for i, ks := range signers {
+		sigShares[i], err = ks.Sign(nonces[i], msg, commitments)
+		require.NoError(t, err)
+	}
+
+	// Participant 3 signs a different message.
+	sigShares[1].Z = append([]byte(nil), sigShares[0].Z...)
+
+	_, err = g.Aggregate(msg, commitments, sigShares)
+	require.ErrorIs(t, err, gcthreshold.InvalidShareError{ID: 3})
+}
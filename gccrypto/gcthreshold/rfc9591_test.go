@@ -0,0 +1,192 @@
+package gcthreshold_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"slices"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/gordian-engine/gcosmos/gccrypto/gcthreshold"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/stretchr/testify/require"
+)
+
+// The FROST(Ed25519, SHA-512) test vectors from RFC 9591, Appendix E.1:
+// a 2-of-3 key signed by participants 1 and 3.
+//
+// The vectors start from a raw group secret scalar rather than an ed25519 seed,
+// so the shares are built directly instead of through [gcthreshold.SplitKey].
+var rfc9591 = struct {
+	GroupSecretKey, GroupPubKey string
+	Coefficient                 string // share_polynomial_coefficients[1].
+	Message                     string
+
+	Participants []rfc9591Participant
+
+	Sig string
+}{
+	GroupSecretKey: "7b1c33d3f5291d85de664833beb1ad469f7fb6025a0ec78b3a790c6e13a98304",
+	GroupPubKey:    "15d21ccd7ee42959562fc8aa63224c8851fb3ec85a3faf66040d380fb9738673",
+	Coefficient:    "178199860edd8c62f5212ee91eff1295d0d670ab4ed4506866bae57e7030b204",
+	Message:        "74657374",
+
+	Participants: []rfc9591Participant{
+		{
+			ID:    1,
+			Share: "929dcc590407aae7d388761cddb0c0db6f5627aea8e217f4a033f2ec83d93509",
+
+			HidingRandomness:  "0fd2e39e111cdc266f6c0f4d0fd45c947761f1f5d3cb583dfcb9bbaf8d4c9fec",
+			BindingRandomness: "69cd85f631d5f7f2721ed5e40519b1366f340a87c2f6856363dbdcda348a7501",
+
+			HidingCommitment:  "b5aa8ab305882a6fc69cbee9327e5a45e54c08af61ae77cb8207be3d2ce13de3",
+			BindingCommitment: "67e98ab55aa310c3120418e5050c9cf76cf387cb20ac9e4b6fdb6f82a469f932",
+
+			SigShare: "001719ab5a53ee1a12095cd088fd149702c0720ce5fd2f29dbecf24b7281b603",
+		},
+		{
+			ID:    2,
+			Share: "a91e66e012e4364ac9aaa405fcafd370402d9859f7b6685c07eed76bf409e80d",
+		},
+		{
+			ID:    3,
+			Share: "d3cb090a075eb154e82fdb4b3cb507f110040905468bb9c46da8bdea643a9a02",
+
+			HidingRandomness:  "86d64a260059e495d0fb4fcc17ea3da7452391baa494d4b00321098ed2a0062f",
+			BindingRandomness: "13e6b25afb2eba51716a9a7d44130c0dbae0004a9ef8d7b5550c8a0e07c61775",
+
+			HidingCommitment:  "cfbdb165bd8aad6eb79deb8d287bcc0ab6658ae57fdcc98ed12c0669e90aec91",
+			BindingCommitment: "7487bc41a6e712eea2f2af24681b58b1cf1da278ea11fe4e8b78398965f13552",
+
+			SigShare: "bd86125de990acc5e1f13781d8e32c03a9bbd4c53539bbc106058bfd14326007",
+		},
+	},
+
+	Sig: "36282629c383bb820a88b71cae937d41f2f2adfcc3d02e55507e2fb9e2dd3cbe" +
+		"bd9d2b0844e49ae0f3fa935161e1419aab7b47d21a37ebeae1f17d4987b3160b",
+}
+
+// rfc9591Participant is one participant in the [rfc9591] vectors.
+// Participant 2 does not sign, so it only has a share.
+type rfc9591Participant struct {
+	ID    uint16
+	Share string
+
+	HidingRandomness, BindingRandomness string
+	HidingCommitment, BindingCommitment string
+
+	SigShare string
+}
+
+// rfc9591Signers returns the participants that sign in the [rfc9591] vectors.
+func rfc9591Signers() []rfc9591Participant {
+	return slices.DeleteFunc(slices.Clone(rfc9591.Participants), func(p rfc9591Participant) bool {
+		return p.SigShare == ""
+	})
+}
+
+// KeyShare returns p's key share.
+func (p rfc9591Participant) KeyShare(t *testing.T) gcthreshold.KeyShare {
+	t.Helper()
+
+	return gcthreshold.KeyShare{
+		ID:          p.ID,
+		Secret:      mustHex(t, p.Share),
+		GroupPubKey: gcrypto.Ed25519PubKey(mustHex(t, rfc9591.GroupPubKey)),
+	}
+}
+
+// Randomness returns a reader producing the nonce randomness
+// in the order that [gcthreshold.KeyShare.Commit] consumes it.
+func (p rfc9591Participant) Randomness(t *testing.T) *bytes.Reader {
+	t.Helper()
+
+	return bytes.NewReader(append(mustHex(t, p.HidingRandomness), mustHex(t, p.BindingRandomness)...))
+}
+
+// rfc9591GroupKey returns the group key for the [rfc9591] vectors,
+// with a verifying share for every participant.
+func rfc9591GroupKey(t *testing.T) gcthreshold.GroupKey {
+	t.Helper()
+
+	g := gcthreshold.GroupKey{
+		PubKey:          gcrypto.Ed25519PubKey(mustHex(t, rfc9591.GroupPubKey)),
+		Threshold:       2,
+		VerifyingShares: make(map[uint16][]byte, len(rfc9591.Participants)),
+	}
+	for _, p := range rfc9591.Participants {
+		s, err := edwards25519.NewScalar().SetCanonicalBytes(mustHex(t, p.Share))
+		require.NoError(t, err)
+		g.VerifyingShares[p.ID] = edwards25519.NewIdentityPoint().ScalarBaseMult(s).Bytes()
+	}
+	return g
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestRFC9591_keyShares(t *testing.T) {
+	t.Parallel()
+
+	secret, err := edwards25519.NewScalar().SetCanonicalBytes(mustHex(t, rfc9591.GroupSecretKey))
+	require.NoError(t, err)
+	coeff, err := edwards25519.NewScalar().SetCanonicalBytes(mustHex(t, rfc9591.Coefficient))
+	require.NoError(t, err)
+
+	require.Equal(t, rfc9591.GroupPubKey, hex.EncodeToString(
+		edwards25519.NewIdentityPoint().ScalarBaseMult(secret).Bytes(),
+	))
+
+	// Each share is the polynomial secret + coeff*x evaluated at the participant's identifier.
+	for _, p := range rfc9591.Participants {
+		var xb [32]byte
+		xb[0] = byte(p.ID)
+		x, err := edwards25519.NewScalar().SetCanonicalBytes(xb[:])
+		require.NoError(t, err)
+
+		share := edwards25519.NewScalar().MultiplyAdd(coeff, x, secret)
+		require.Equal(t, p.Share, hex.EncodeToString(share.Bytes()), "participant %d", p.ID)
+	}
+}
+
+func TestRFC9591_sign(t *testing.T) {
+	t.Parallel()
+
+	msg := mustHex(t, rfc9591.Message)
+	signers := rfc9591Signers()
+
+	keyShares := make([]gcthreshold.KeyShare, len(signers))
+	nonces := make([]*gcthreshold.Nonces, len(signers))
+	commitments := make([]gcthreshold.Commitment, len(signers))
+	for i, p := range signers {
+		keyShares[i] = p.KeyShare(t)
+
+		var err error
+		nonces[i], commitments[i], err = keyShares[i].Commit(p.Randomness(t))
+		require.NoError(t, err)
+
+		require.Equal(t, p.HidingCommitment, hex.EncodeToString(commitments[i].Hiding), "participant %d", p.ID)
+		require.Equal(t, p.BindingCommitment, hex.EncodeToString(commitments[i].Binding), "participant %d", p.ID)
+	}
+
+	sigShares := make([]gcthreshold.SignatureShare, len(signers))
+	for i, p := range signers {
+		var err error
+		sigShares[i], err = keyShares[i].Sign(nonces[i], msg, commitments)
+		require.NoError(t, err)
+
+		require.Equal(t, p.ID, sigShares[i].ID)
+		require.Equal(t, p.SigShare, hex.EncodeToString(sigShares[i].Z), "participant %d", p.ID)
+	}
+
+	sig, err := rfc9591GroupKey(t).Aggregate(msg, commitments, sigShares)
+	require.NoError(t, err)
+	require.Equal(t, rfc9591.Sig, hex.EncodeToString(sig))
+	require.True(t, ed25519.Verify(mustHex(t, rfc9591.GroupPubKey), msg, sig))
+}
@@ -0,0 +1,194 @@
+// Package gcerr classifies errors from the stores and consensus types
+// into a small set of codes,
+// so that callers such as the HTTP layer can choose a response status
+// without matching on error strings.
+//
+// Errors defined in gcosmos carry their code directly,
+// by implementing [Coder] or by being created with [New] or [WithCode].
+// Errors defined in gordian's tmconsensus and tmstore packages
+// are classified by type in [CodeOf].
+package gcerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+)
+
+// Code is the broad category of an error.
+type Code uint8
+
+const (
+	// The error is nil or does not belong to any other category.
+	CodeUnknown Code = iota
+
+	// The requested height, round, hash, or record does not exist.
+	CodeNotFound
+
+	// The value being saved already exists, possibly with different content.
+	CodeAlreadyExists
+
+	// The input failed validation, such as a conflicting or empty vote.
+	CodeInvalid
+
+	// The input is inconsistent with the current view,
+	// such as a header whose previous hash or height does not match.
+	CodeMismatch
+
+	// The resource is not ready, such as an uninitialized store.
+	CodeUnavailable
+)
+
+func (c Code) String() string {
+	switch c {
+	case CodeUnknown:
+		return "unknown"
+	case CodeNotFound:
+		return "not_found"
+	case CodeAlreadyExists:
+		return "already_exists"
+	case CodeInvalid:
+		return "invalid"
+	case CodeMismatch:
+		return "mismatch"
+	case CodeUnavailable:
+		return "unavailable"
+	default:
+		return fmt.Sprintf("Code(%d)", uint8(c))
+	}
+}
+
+// HTTPStatus returns the HTTP response status appropriate for c.
+// [CodeUnknown] maps to 500 Internal Server Error.
+func (c Code) HTTPStatus() int {
+	switch c {
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeAlreadyExists:
+		return http.StatusConflict
+	case CodeInvalid:
+		return http.StatusBadRequest
+	case CodeMismatch:
+		return http.StatusUnprocessableEntity
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Coder is implemented by errors that report their own [Code].
+type Coder interface {
+	error
+	ErrorCode() Code
+}
+
+// New returns a sentinel error with the given code and message.
+// Like errors created with [errors.New], each call returns a distinct error
+// that can be matched with [errors.Is].
+func New(code Code, msg string) error {
+	return &codedError{code: code, msg: msg}
+}
+
+type codedError struct {
+	code Code
+	msg  string
+}
+
+func (e *codedError) Error() string   { return e.msg }
+func (e *codedError) ErrorCode() Code { return e.code }
+
+// WithCode wraps err so that [CodeOf] reports code,
+// overriding any code err would otherwise have.
+// The wrapped error remains available to [errors.Is] and [errors.As].
+// WithCode returns nil if err is nil.
+func WithCode(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return wrappedError{code: code, err: err}
+}
+
+type wrappedError struct {
+	code Code
+	err  error
+}
+
+func (e wrappedError) Error() string   { return e.err.Error() }
+func (e wrappedError) Unwrap() error   { return e.err }
+func (e wrappedError) ErrorCode() Code { return e.code }
+
+// CodeOf returns the code for err,
+// considering every error in err's tree.
+// It returns [CodeUnknown] for nil or unclassified errors.
+func CodeOf(err error) Code {
+	if err == nil {
+		return CodeUnknown
+	}
+
+	var c Coder
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+
+	for _, k := range upstreamKinds {
+		if k.match(err) {
+			return k.code
+		}
+	}
+
+	return CodeUnknown
+}
+
+// HTTPStatus is shorthand for CodeOf(err).HTTPStatus().
+func HTTPStatus(err error) int {
+	return CodeOf(err).HTTPStatus()
+}
+
+type upstreamKind struct {
+	code  Code
+	match func(error) bool
+}
+
+func is(target error) func(error) bool {
+	return func(err error) bool { return errors.Is(err, target) }
+}
+
+func as[T error]() func(error) bool {
+	return func(err error) bool { return errors.As(err, new(T)) }
+}
+
+// upstreamKinds classifies the error types declared in gordian.
+var upstreamKinds = []upstreamKind{
+	{CodeNotFound, as[tmconsensus.HeightUnknownError]()},
+	{CodeNotFound, as[tmconsensus.RoundUnknownError]()},
+	{CodeNotFound, as[tmconsensus.HashUnknownError]()},
+	{CodeNotFound, as[tmstore.NoPubKeyHashError]()},
+	{CodeNotFound, as[tmstore.NoVotePowerHashError]()},
+
+	{CodeAlreadyExists, as[tmconsensus.HashAlreadyExistsError]()},
+	{CodeAlreadyExists, as[tmstore.PubKeysAlreadyExistError]()},
+	{CodeAlreadyExists, as[tmstore.VotePowersAlreadyExistError]()},
+	{CodeAlreadyExists, as[tmstore.OverwriteError]()},
+	{CodeAlreadyExists, as[tmstore.FinalizationOverwriteError]()},
+
+	{CodeInvalid, as[tmconsensus.DoublePrevoteError]()},
+	{CodeInvalid, as[tmconsensus.DoublePrecommitError]()},
+	{CodeInvalid, as[tmconsensus.DoubleVoteByIndexError]()},
+	{CodeInvalid, as[tmconsensus.EmptyVoteError]()},
+	{CodeInvalid, as[tmstore.DoubleActionError]()},
+
+	{CodeMismatch, as[tmconsensus.PreviousHashMismatchError]()},
+	{CodeMismatch, as[tmconsensus.AppDataHashMismatchError]()},
+	{CodeMismatch, as[tmconsensus.AppStateHashMismatchError]()},
+	{CodeMismatch, as[tmconsensus.HeightMismatchError]()},
+	{CodeMismatch, as[tmconsensus.VoteTargetMismatchError]()},
+	{CodeMismatch, as[tmstore.PubKeyPowerCountMismatchError]()},
+	{CodeMismatch, as[tmstore.PubKeyChangedError]()},
+
+	{CodeUnavailable, is(tmstore.ErrStoreUninitialized)},
+	{CodeUnavailable, is(tmconsensus.ErrProposedBlockChoiceNotReady)},
+}
@@ -0,0 +1,80 @@
+package gcerr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOf(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		want gcerr.Code
+	}{
+		{name: "nil", err: nil, want: gcerr.CodeUnknown},
+		{name: "plain", err: errors.New("boom"), want: gcerr.CodeUnknown},
+
+		{name: "height unknown", err: tmconsensus.HeightUnknownError{Want: 3}, want: gcerr.CodeNotFound},
+		{
+			name: "wrapped height unknown",
+			err:  fmt.Errorf("failed to load: %w", tmconsensus.HeightUnknownError{Want: 3}),
+			want: gcerr.CodeNotFound,
+		},
+		{name: "store sentinel", err: gcstore.ErrBlockDataNotFound, want: gcerr.CodeNotFound},
+
+		{name: "overwrite", err: tmstore.OverwriteError{Field: "height", Value: "1"}, want: gcerr.CodeAlreadyExists},
+		{
+			name: "store error type",
+			err:  gcstore.AlreadyHaveBlockDataForHeightError{Height: 1},
+			want: gcerr.CodeAlreadyExists,
+		},
+
+		{name: "empty vote", err: tmconsensus.EmptyVoteError{}, want: gcerr.CodeInvalid},
+		{name: "height mismatch", err: tmconsensus.HeightMismatchError{Want: 1, Got: 2}, want: gcerr.CodeMismatch},
+		{name: "uninitialized", err: tmstore.ErrStoreUninitialized, want: gcerr.CodeUnavailable},
+
+		{
+			name: "override",
+			err:  gcerr.WithCode(gcerr.CodeInvalid, tmconsensus.HeightUnknownError{Want: 3}),
+			want: gcerr.CodeInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, gcerr.CodeOf(tc.err))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	err := gcerr.New(gcerr.CodeNotFound, "widget not found")
+	require.Equal(t, "widget not found", err.Error())
+
+	wrapped := fmt.Errorf("loading widget: %w", err)
+	require.ErrorIs(t, wrapped, err)
+	require.NotErrorIs(t, wrapped, gcerr.New(gcerr.CodeNotFound, "widget not found"))
+	require.Equal(t, http.StatusNotFound, gcerr.HTTPStatus(wrapped))
+}
+
+func TestWithCode(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, gcerr.WithCode(gcerr.CodeInvalid, nil))
+
+	inner := tmconsensus.HeightUnknownError{Want: 3}
+	err := gcerr.WithCode(gcerr.CodeUnavailable, inner)
+	require.Equal(t, inner.Error(), err.Error())
+	require.ErrorAs(t, err, new(tmconsensus.HeightUnknownError))
+	require.Equal(t, http.StatusServiceUnavailable, gcerr.HTTPStatus(err))
+}
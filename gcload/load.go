@@ -0,0 +1,416 @@
+// Package gcload generates transaction load against running nodes of a gordian-based chain,
+// and measures how the network keeps up.
+//
+// Like [github.com/gordian-engine/gcosmos/gcconformance],
+// the package only talks to nodes over their HTTP API through [gcclient],
+// and the caller supplies signed transactions,
+// because building and signing them is specific to each chain.
+package gcload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcclient"
+)
+
+// Defaults for the corresponding zero fields of [Config].
+const (
+	DefaultInclusionTimeout = 30 * time.Second
+	DefaultPollInterval     = 100 * time.Millisecond
+)
+
+// Tx is a signed transaction to submit.
+type Tx struct {
+	// The transaction in its JSON encoding, as accepted by [gcclient.Client.SubmitTx].
+	JSON []byte
+
+	// The transaction hash, as accepted by [gcclient.Client.TxPending].
+	Hash []byte
+}
+
+// Config is the configuration for [Run].
+type Config struct {
+	// The nodes to submit transactions to.
+	Nodes []*gcclient.Client
+
+	// How many funded accounts sign transactions.
+	// Account i only submits to node i%len(Nodes),
+	// so that each of its sequences follows the previous one
+	// in the same node's transaction buffer.
+	Accounts int
+
+	// NewTx returns the signed transaction for the given account,
+	// where n is how many of the account's transactions have been accepted so far.
+	// It is called concurrently for different accounts,
+	// but never concurrently for the same account.
+	NewTx func(account int, n uint64) (Tx, error)
+
+	// Transactions per second to submit across all accounts.
+	Rate float64
+
+	// How long to submit transactions for.
+	Duration time.Duration
+
+	// How long to keep waiting for submitted transactions
+	// to leave the transaction buffer, after the last submission.
+	// Defaults to DefaultInclusionTimeout if zero.
+	InclusionTimeout time.Duration
+
+	// How often to check pending transactions and buffer sizes.
+	// This bounds the resolution of the inclusion latencies.
+	// Defaults to DefaultPollInterval if zero.
+	PollInterval time.Duration
+}
+
+// Report is the outcome of [Run].
+type Report struct {
+	// Transactions accepted by a node.
+	Submitted int
+
+	// Transactions a node refused, such as for a sequence mismatch or insufficient funds.
+	Rejected int
+
+	// Submissions that were due while every account was still busy
+	// with its previous submission.
+	Skipped int
+
+	// Submitted transactions that left the transaction buffer,
+	// and those still pending when the inclusion timeout elapsed.
+	// The node does not index committed transactions,
+	// so a transaction that was dropped as invalid also counts as included.
+	Included, TimedOut int
+
+	// Time from the first submission until the last observed inclusion.
+	Elapsed time.Duration
+
+	// Included transactions per second over Elapsed.
+	Throughput float64
+
+	// Inclusion latency percentiles,
+	// measured from acceptance until the transaction was no longer pending.
+	LatencyP50, LatencyP90, LatencyP99, LatencyMax time.Duration
+
+	// Largest and mean transaction buffer size sampled on any node.
+	MaxPending  int
+	MeanPending float64
+
+	// The first submission error, if any submission was rejected.
+	FirstRejection error
+}
+
+// WriteTo writes a human-readable summary of r to w.
+func (r Report) WriteTo(w io.Writer) (int64, error) {
+	s := fmt.Sprintf(`Submitted:    %d (rejected %d, skipped %d)
+Included:     %d (timed out %d)
+Elapsed:      %s
+Throughput:   %.2f tx/s
+Latency:      p50 %s, p90 %s, p99 %s, max %s
+Buffer size:  max %d, mean %.1f
+`,
+		r.Submitted, r.Rejected, r.Skipped,
+		r.Included, r.TimedOut,
+		r.Elapsed.Round(time.Millisecond),
+		r.Throughput,
+		r.LatencyP50.Round(time.Millisecond), r.LatencyP90.Round(time.Millisecond),
+		r.LatencyP99.Round(time.Millisecond), r.LatencyMax.Round(time.Millisecond),
+		r.MaxPending, r.MeanPending,
+	)
+	if r.FirstRejection != nil {
+		s += fmt.Sprintf("First rejection: %v\n", r.FirstRejection)
+	}
+
+	n, err := io.WriteString(w, s)
+	return int64(n), err
+}
+
+// pendingTx is a transaction accepted by a node
+// that has not yet been observed leaving its buffer.
+type pendingTx struct {
+	hash     []byte
+	accepted time.Time
+}
+
+// run is the state of a single call to Run.
+type run struct {
+	cfg Config
+
+	start time.Time
+
+	mu             sync.Mutex
+	submitted      int
+	rejected       int
+	firstRejection error
+	latencies      []time.Duration
+	lastInclusion  time.Time
+	timedOut       int
+	pendingSamples int
+	pendingSum     int
+	maxPending     int
+}
+
+// Run submits transactions per cfg until cfg.Duration elapses,
+// then waits for the submitted transactions to be included,
+// and reports the results.
+// An error is only returned for an invalid configuration,
+// or if ctx is canceled before the run completes.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	if len(cfg.Nodes) == 0 {
+		return Report{}, errors.New("gcload: no nodes configured")
+	}
+	if cfg.Accounts <= 0 {
+		return Report{}, errors.New("gcload: no accounts configured")
+	}
+	if cfg.Rate <= 0 {
+		return Report{}, fmt.Errorf("gcload: rate must be positive (got %v)", cfg.Rate)
+	}
+	if cfg.InclusionTimeout == 0 {
+		cfg.InclusionTimeout = DefaultInclusionTimeout
+	}
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+
+	r := &run{cfg: cfg, start: time.Now()}
+
+	// One tracker per node, fed by the workers of the accounts pinned to it.
+	accepted := make([]chan pendingTx, len(cfg.Nodes))
+	var trackersWG sync.WaitGroup
+	for i := range cfg.Nodes {
+		accepted[i] = make(chan pendingTx, 64)
+		trackersWG.Add(1)
+		go func() {
+			defer trackersWG.Done()
+			r.track(ctx, cfg.Nodes[i], accepted[i])
+		}()
+	}
+
+	samplerCtx, stopSampler := context.WithCancel(ctx)
+	samplerDone := make(chan struct{})
+	go func() {
+		defer close(samplerDone)
+		r.sample(samplerCtx)
+	}()
+
+	tokens := make(chan struct{})
+	var workersWG sync.WaitGroup
+	for a := 0; a < cfg.Accounts; a++ {
+		node := a % len(cfg.Nodes)
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+			r.work(ctx, a, cfg.Nodes[node], tokens, accepted[node])
+		}()
+	}
+
+	skipped := r.dispatch(ctx, tokens)
+
+	close(tokens)
+	workersWG.Wait()
+	for _, ch := range accepted {
+		close(ch)
+	}
+	trackersWG.Wait()
+
+	stopSampler()
+	<-samplerDone
+
+	if err := context.Cause(ctx); err != nil {
+		return Report{}, err
+	}
+
+	return r.report(skipped), nil
+}
+
+// dispatch hands a token to an idle worker at the configured rate,
+// and returns how many tokens no worker was available for.
+func (r *run) dispatch(ctx context.Context, tokens chan<- struct{}) int {
+	interval := time.Duration(float64(time.Second) / r.cfg.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	deadline := time.NewTimer(r.cfg.Duration)
+	defer deadline.Stop()
+
+	skipped := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return skipped
+		case <-deadline.C:
+			return skipped
+		case <-ticker.C:
+			select {
+			case tokens <- struct{}{}:
+			default:
+				skipped++
+			}
+		}
+	}
+}
+
+// work submits one transaction for the given account per token received.
+func (r *run) work(
+	ctx context.Context,
+	account int,
+	node *gcclient.Client,
+	tokens <-chan struct{},
+	accepted chan<- pendingTx,
+) {
+	var n uint64
+	for range tokens {
+		tx, err := r.cfg.NewTx(account, n)
+		if err == nil {
+			_, err = node.SubmitTx(ctx, tx.JSON)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			r.mu.Lock()
+			r.rejected++
+			if r.firstRejection == nil {
+				r.firstRejection = fmt.Errorf("account %d: %w", account, err)
+			}
+			r.mu.Unlock()
+			continue
+		}
+
+		n++
+		r.mu.Lock()
+		r.submitted++
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case accepted <- pendingTx{hash: tx.Hash, accepted: time.Now()}:
+		}
+	}
+}
+
+// track polls node for each accepted transaction until it is no longer pending.
+// Once accepted is closed, tracking continues
+// until nothing is pending or the inclusion timeout elapses.
+func (r *run) track(ctx context.Context, node *gcclient.Client, accepted <-chan pendingTx) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var outstanding []pendingTx
+	var drainDeadline <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case ptx, ok := <-accepted:
+			if !ok {
+				if len(outstanding) == 0 {
+					return
+				}
+				accepted = nil
+				t := time.NewTimer(r.cfg.InclusionTimeout)
+				defer t.Stop()
+				drainDeadline = t.C
+				continue
+			}
+			outstanding = append(outstanding, ptx)
+
+		case <-drainDeadline:
+			r.mu.Lock()
+			r.timedOut += len(outstanding)
+			r.mu.Unlock()
+			return
+
+		case <-ticker.C:
+			outstanding = slices.DeleteFunc(outstanding, func(ptx pendingTx) bool {
+				pending, err := node.TxPending(ctx, ptx.hash)
+				if err != nil || pending {
+					// Treat errors as transient; the deadline bounds retries.
+					return false
+				}
+
+				now := time.Now()
+				r.mu.Lock()
+				r.latencies = append(r.latencies, now.Sub(ptx.accepted))
+				r.lastInclusion = now
+				r.mu.Unlock()
+				return true
+			})
+			if accepted == nil && len(outstanding) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// sample records every node's transaction buffer size on each poll.
+func (r *run) sample(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, node := range r.cfg.Nodes {
+			n, err := node.PendingTxCount(ctx)
+			if err != nil {
+				continue
+			}
+			r.mu.Lock()
+			r.pendingSamples++
+			r.pendingSum += n
+			r.maxPending = max(r.maxPending, n)
+			r.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *run) report(skipped int) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := Report{
+		Submitted: r.submitted,
+		Rejected:  r.rejected,
+		Skipped:   skipped,
+		Included:  len(r.latencies),
+		TimedOut:  r.timedOut,
+
+		MaxPending: r.maxPending,
+
+		FirstRejection: r.firstRejection,
+	}
+
+	if r.pendingSamples > 0 {
+		rep.MeanPending = float64(r.pendingSum) / float64(r.pendingSamples)
+	}
+
+	if len(r.latencies) > 0 {
+		rep.Elapsed = r.lastInclusion.Sub(r.start)
+		rep.Throughput = float64(rep.Included) / rep.Elapsed.Seconds()
+
+		slices.Sort(r.latencies)
+		rep.LatencyP50 = percentile(r.latencies, 50)
+		rep.LatencyP90 = percentile(r.latencies, 90)
+		rep.LatencyP99 = percentile(r.latencies, 99)
+		rep.LatencyMax = r.latencies[len(r.latencies)-1]
+	}
+
+	return rep
+}
+
+// percentile returns the nearest-rank p-th percentile of the sorted, non-empty ds.
+func percentile(ds []time.Duration, p int) time.Duration {
+	i := (len(ds)*p + 99) / 100
+	return ds[max(i-1, 0)]
+}
@@ -0,0 +1,147 @@
+package gcload_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcclient"
+	"github.com/gordian-engine/gcosmos/gcload"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode accepts any transaction whose body is its hash,
+// except those starting with "bad",
+// and keeps each accepted transaction pending for a fixed delay.
+type fakeNode struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	for h, until := range n.pending {
+		if now.After(until) {
+			delete(n.pending, h)
+		}
+	}
+
+	switch {
+	case r.URL.Path == "/debug/submit_tx":
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r.Body)
+		if bytes.HasPrefix(buf.Bytes(), []byte("bad")) {
+			http.Error(w, "transaction validation failed: bad", http.StatusBadRequest)
+			return
+		}
+		n.pending[buf.String()] = now.Add(n.delay)
+		_, _ = w.Write([]byte(`{}`))
+	case r.URL.Path == "/debug/pending_txs":
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(n.pending)))
+		_, _ = w.Write([]byte(`[]`))
+	case strings.HasPrefix(r.URL.Path, "/debug/pending_txs/"):
+		h, _ := hex.DecodeString(strings.TrimPrefix(r.URL.Path, "/debug/pending_txs/"))
+		_, ok := n.pending[string(h)]
+		fmt.Fprintf(w, `{"Pending":%t}`, ok)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var nodes []*gcclient.Client
+	for range 2 {
+		srv := httptest.NewServer(&fakeNode{delay: 50 * time.Millisecond, pending: map[string]time.Time{}})
+		defer srv.Close()
+		nodes = append(nodes, gcclient.New(gcclient.Config{Addr: srv.URL}))
+	}
+
+	var mu sync.Mutex
+	seen := map[int]uint64{}
+	rep, err := gcload.Run(context.Background(), gcload.Config{
+		Nodes:    nodes,
+		Accounts: 4,
+		NewTx: func(account int, n uint64) (gcload.Tx, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if n != seen[account] {
+				return gcload.Tx{}, fmt.Errorf("account %d: got sequence %d, want %d", account, n, seen[account])
+			}
+
+			// Every third transaction of account 0 is rejected,
+			// and must not advance its sequence.
+			if account == 0 && n%3 == 2 {
+				if _, ok := seen[-1]; !ok {
+					seen[-1] = 0
+					return gcload.Tx{JSON: []byte("bad"), Hash: []byte("bad")}, nil
+				}
+				delete(seen, -1)
+			}
+
+			seen[account]++
+			b := []byte(fmt.Sprintf("tx-%d-%d", account, n))
+			return gcload.Tx{JSON: b, Hash: b}, nil
+		},
+		Rate:         200,
+		Duration:     300 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.Positive(t, rep.Submitted)
+	require.Equal(t, rep.Submitted, rep.Included)
+	require.Zero(t, rep.TimedOut)
+	require.Positive(t, rep.Rejected)
+	require.ErrorContains(t, rep.FirstRejection, "transaction validation failed")
+
+	require.GreaterOrEqual(t, rep.LatencyP50, 50*time.Millisecond)
+	require.LessOrEqual(t, rep.LatencyP50, rep.LatencyP90)
+	require.LessOrEqual(t, rep.LatencyP99, rep.LatencyMax)
+	require.Positive(t, rep.Throughput)
+	require.Positive(t, rep.MaxPending)
+
+	var out strings.Builder
+	_, err = rep.WriteTo(&out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "Throughput:")
+}
+
+func TestRun_timeout(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(&fakeNode{delay: time.Hour, pending: map[string]time.Time{}})
+	defer srv.Close()
+
+	rep, err := gcload.Run(context.Background(), gcload.Config{
+		Nodes:    []*gcclient.Client{gcclient.New(gcclient.Config{Addr: srv.URL})},
+		Accounts: 1,
+		NewTx: func(_ int, n uint64) (gcload.Tx, error) {
+			b := []byte(fmt.Sprintf("tx-%d", n))
+			return gcload.Tx{JSON: b, Hash: b}, nil
+		},
+		Rate:             50,
+		Duration:         100 * time.Millisecond,
+		InclusionTimeout: 50 * time.Millisecond,
+		PollInterval:     10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.Positive(t, rep.Submitted)
+	require.Zero(t, rep.Included)
+	require.Equal(t, rep.Submitted, rep.TimedOut)
+}
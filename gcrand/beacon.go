@@ -0,0 +1,108 @@
+// Package gcrand derives deterministic per-height randomness
+// from the commit proofs embedded in Gordian block headers.
+//
+// Every header after the initial height carries the precommit proof
+// for its parent block, and that proof is part of the header hash.
+// Therefore every validator finalizing the same block
+// derives an identical [Beacon] value,
+// without any additional communication.
+//
+// The beacon is unpredictable until the parent block's precommits are collected,
+// but note that the proposer of a block chooses which subset of precommit signatures
+// to include in its header, so a proposer has a limited ability to bias the value.
+// Applications requiring unbiasable randomness need a dedicated VRF scheme instead.
+//
+// The beacon reaches SDK modules through the context passed to block delivery,
+// read with [BeaconFromContext].
+// This is deliberate: the SDK's block request and header info types
+// have no field for values outside the SDK's own model,
+// and the app manager already hands the delivery context to every module,
+// which is also how the comet info reaches them.
+// Code outside block delivery, such as queries or transaction checks,
+// never sees a beacon.
+package gcrand
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"slices"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Beacon is a 32-byte random value associated with a single block height.
+type Beacon [32]byte
+
+// beaconDomain separates beacon hashes from any other use of sha256
+// over the same signature bytes.
+const beaconDomain = "gcosmos/beacon/v1"
+
+// BeaconFromHeader derives the beacon for the block with header h.
+//
+// The beacon is derived from the signatures in h.PrevCommitProof
+// that precommitted h.PrevBlockHash.
+// If there are no such signatures, as is the case at the initial height,
+// BeaconFromHeader returns the zero Beacon and false.
+func BeaconFromHeader(h tmconsensus.Header) (Beacon, bool) {
+	sigs := h.PrevCommitProof.Proofs[string(h.PrevBlockHash)]
+	if len(sigs) == 0 {
+		return Beacon{}, false
+	}
+
+	// The proofs should already be in a consistent order,
+	// but sort a copy by key ID anyway so that encoding details
+	// can never produce a different beacon on different validators.
+	sorted := slices.Clone(sigs)
+	slices.SortFunc(sorted, func(a, b gcrypto.SparseSignature) int {
+		return bytes.Compare(a.KeyID, b.KeyID)
+	})
+
+	hasher := sha256.New()
+	_, _ = hasher.Write([]byte(beaconDomain))
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], h.Height)
+	_, _ = hasher.Write(buf[:])
+
+	writeLenPrefixed(hasher, h.PrevBlockHash)
+	for _, s := range sorted {
+		writeLenPrefixed(hasher, s.KeyID)
+		writeLenPrefixed(hasher, s.Sig)
+	}
+
+	var b Beacon
+	hasher.Sum(b[:0])
+	return b, true
+}
+
+func writeLenPrefixed(w io.Writer, b []byte) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(len(b)))
+	_, _ = w.Write(buf[:])
+	_, _ = w.Write(b)
+}
+
+type beaconContextKey struct{}
+
+// ContextWithBeacon returns a child of ctx carrying the beacon b.
+//
+// The driver sets the beacon on the context used to deliver a block to the SDK app,
+// so that modules may retrieve it through [BeaconFromContext].
+// The context is the only way to pass it, as the SDK's block request has no field for it;
+// see the package documentation.
+func ContextWithBeacon(ctx context.Context, b Beacon) context.Context {
+	return context.WithValue(ctx, beaconContextKey{}, b)
+}
+
+// BeaconFromContext returns the beacon set on ctx by [ContextWithBeacon].
+// The ok return value is false if no beacon was set,
+// which is expected at the initial height
+// and on any context not derived from block delivery.
+func BeaconFromContext(ctx context.Context) (b Beacon, ok bool) {
+	b, ok = ctx.Value(beaconContextKey{}).(Beacon)
+	return b, ok
+}
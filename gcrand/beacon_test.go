@@ -0,0 +1,81 @@
+package gcrand_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcrand"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeaconFromHeader(t *testing.T) {
+	t.Parallel()
+
+	sigs := []gcrypto.SparseSignature{
+		{KeyID: []byte{0}, Sig: []byte("sig0")},
+		{KeyID: []byte{1}, Sig: []byte("sig1")},
+		{KeyID: []byte{2}, Sig: []byte("sig2")},
+	}
+
+	newHeader := func(height uint64, sigs []gcrypto.SparseSignature) tmconsensus.Header {
+		return tmconsensus.Header{
+			Height:        height,
+			PrevBlockHash: []byte("prev"),
+			PrevCommitProof: tmconsensus.CommitProof{
+				Proofs: map[string][]gcrypto.SparseSignature{
+					"prev": sigs,
+					"":     {{KeyID: []byte{3}, Sig: []byte("nil_sig")}},
+				},
+			},
+		}
+	}
+
+	t.Run("no proof for previous block", func(t *testing.T) {
+		t.Parallel()
+
+		b, ok := gcrand.BeaconFromHeader(tmconsensus.Header{Height: 1})
+		require.False(t, ok)
+		require.Zero(t, b)
+	})
+
+	t.Run("deterministic regardless of signature order", func(t *testing.T) {
+		t.Parallel()
+
+		b1, ok := gcrand.BeaconFromHeader(newHeader(2, sigs))
+		require.True(t, ok)
+
+		reversed := []gcrypto.SparseSignature{sigs[2], sigs[1], sigs[0]}
+		b2, ok := gcrand.BeaconFromHeader(newHeader(2, reversed))
+		require.True(t, ok)
+
+		require.Equal(t, b1, b2)
+
+		// Sorting must not have modified the header's proof.
+		require.Equal(t, []byte{2}, reversed[0].KeyID)
+	})
+
+	t.Run("height and signatures affect the beacon", func(t *testing.T) {
+		t.Parallel()
+
+		b1, _ := gcrand.BeaconFromHeader(newHeader(2, sigs))
+		b2, _ := gcrand.BeaconFromHeader(newHeader(3, sigs))
+		require.NotEqual(t, b1, b2)
+
+		b3, _ := gcrand.BeaconFromHeader(newHeader(2, sigs[:2]))
+		require.NotEqual(t, b1, b3)
+	})
+}
+
+func TestBeaconContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := gcrand.BeaconFromContext(context.Background())
+	require.False(t, ok)
+
+	want := gcrand.Beacon{1, 2, 3}
+	got, ok := gcrand.BeaconFromContext(gcrand.ContextWithBeacon(context.Background(), want))
+	require.True(t, ok)
+	require.Equal(t, want, got)
+}
@@ -0,0 +1,152 @@
+package gcstore
+
+import (
+	"context"
+	"encoding/binary"
+	"slices"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+)
+
+// CompactingCommittedHeaderStore is a [tmstore.CommittedHeaderStore]
+// that trims each commit proof before saving it to an underlying store.
+//
+// A commit proof usually contains more signatures than necessary
+// to prove the commit, and the extra signatures are never needed
+// once the header is committed.
+// Only the signatures for the committed block hash are kept,
+// and of those, only the highest-powered signatures
+// needed to exceed two thirds of the voting power.
+//
+// The ed25519 signatures in use today cannot be aggregated,
+// so dropping redundant signatures is the only compaction available.
+type CompactingCommittedHeaderStore struct {
+	tmstore.CommittedHeaderStore
+
+	keyIndex func(keyID []byte) (int, bool)
+}
+
+// NewCompactingCommittedHeaderStore returns a CompactingCommittedHeaderStore
+// wrapping inner.
+//
+// The keyIndex function maps a sparse signature's key ID
+// to the index of the validator in the header's validator set.
+// If keyIndex is nil, [SimpleKeyIndex] is used,
+// matching [gcrypto.SimpleCommonMessageSignatureProofScheme].
+func NewCompactingCommittedHeaderStore(
+	inner tmstore.CommittedHeaderStore,
+	keyIndex func(keyID []byte) (int, bool),
+) *CompactingCommittedHeaderStore {
+	if keyIndex == nil {
+		keyIndex = SimpleKeyIndex
+	}
+
+	return &CompactingCommittedHeaderStore{
+		CommittedHeaderStore: inner,
+		keyIndex:             keyIndex,
+	}
+}
+
+// SimpleKeyIndex interprets keyID as the big endian uint16
+// used by [gcrypto.SimpleCommonMessageSignatureProofScheme].
+func SimpleKeyIndex(keyID []byte) (int, bool) {
+	if len(keyID) != 2 {
+		return 0, false
+	}
+	return int(binary.BigEndian.Uint16(keyID)), true
+}
+
+func (s *CompactingCommittedHeaderStore) SaveCommittedHeader(
+	ctx context.Context, ch tmconsensus.CommittedHeader,
+) error {
+	ch.Proof = CompactCommitProof(ch.Header, ch.Proof, s.keyIndex)
+	return s.CommittedHeaderStore.SaveCommittedHeader(ctx, ch)
+}
+
+// CompactCommitProof returns a copy of p, the commit proof for h,
+// containing only the signatures for h's hash
+// that are needed to exceed two thirds of the voting power of h's validator set,
+// preferring the validators with the most power.
+//
+// If p does not contain a recognizable majority for h,
+// p is returned unmodified, as there is nothing safe to drop.
+func CompactCommitProof(
+	h tmconsensus.Header,
+	p tmconsensus.CommitProof,
+	keyIndex func(keyID []byte) (int, bool),
+) tmconsensus.CommitProof {
+	vals := h.ValidatorSet.Validators
+	if len(vals) == 0 {
+		return p
+	}
+
+	sigs := p.Proofs[string(h.Hash)]
+
+	var totalPow uint64
+	for _, v := range vals {
+		totalPow += v.Power
+	}
+	if totalPow == 0 {
+		return p
+	}
+	maj := tmconsensus.ByzantineMajority(totalPow)
+
+	type indexedSig struct {
+		pow uint64
+		sig gcrypto.SparseSignature
+	}
+	byPow := make([]indexedSig, 0, len(sigs))
+	for _, sig := range sigs {
+		idx, ok := keyIndex(sig.KeyID)
+		if !ok || idx < 0 || idx >= len(vals) {
+			// We can't attribute this signature, so we can't reason about what to drop.
+			return p
+		}
+		byPow = append(byPow, indexedSig{pow: vals[idx].Power, sig: sig})
+	}
+
+	// Stable so that equally powered validators keep their relative order.
+	slices.SortStableFunc(byPow, func(a, b indexedSig) int {
+		switch {
+		case a.pow > b.pow:
+			return -1
+		case a.pow < b.pow:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	var pow uint64
+	n := 0
+	for n < len(byPow) && pow < maj {
+		pow += byPow[n].pow
+		n++
+	}
+	if pow < maj {
+		return p
+	}
+
+	kept := make([]gcrypto.SparseSignature, n)
+	for i := range n {
+		kept[i] = byPow[i].sig
+	}
+
+	// Restore the original key order of the kept signatures,
+	// so that the proof looks like any other sparse proof.
+	slices.SortFunc(kept, func(a, b gcrypto.SparseSignature) int {
+		ai, _ := keyIndex(a.KeyID)
+		bi, _ := keyIndex(b.KeyID)
+		return ai - bi
+	})
+
+	return tmconsensus.CommitProof{
+		Round:      p.Round,
+		PubKeyHash: p.PubKeyHash,
+		Proofs: map[string][]gcrypto.SparseSignature{
+			string(h.Hash): kept,
+		},
+	}
+}
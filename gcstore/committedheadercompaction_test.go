@@ -0,0 +1,118 @@
+package gcstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactCommitProof(t *testing.T) {
+	t.Parallel()
+
+	h := tmconsensus.Header{
+		Hash:   []byte("block"),
+		Height: 3,
+		ValidatorSet: tmconsensus.ValidatorSet{
+			Validators: []tmconsensus.Validator{
+				{Power: 10}, {Power: 20}, {Power: 30}, {Power: 40},
+			},
+		},
+	}
+
+	sig := func(idx byte) gcrypto.SparseSignature {
+		return gcrypto.SparseSignature{
+			KeyID: []byte{0, idx},
+			Sig:   []byte{'s', idx},
+		}
+	}
+
+	p := tmconsensus.CommitProof{
+		Round:      1,
+		PubKeyHash: "pkh",
+		Proofs: map[string][]gcrypto.SparseSignature{
+			"block": {sig(0), sig(1), sig(2), sig(3)},
+			"":      {sig(0)},
+		},
+	}
+
+	t.Run("keeps highest power signatures for the block", func(t *testing.T) {
+		t.Parallel()
+
+		got := gcstore.CompactCommitProof(h, p, gcstore.SimpleKeyIndex)
+
+		// 40 and 30 make 70, which exceeds two thirds of 100.
+		require.Equal(t, tmconsensus.CommitProof{
+			Round:      1,
+			PubKeyHash: "pkh",
+			Proofs: map[string][]gcrypto.SparseSignature{
+				"block": {sig(2), sig(3)},
+			},
+		}, got)
+
+		// Original is unmodified.
+		require.Len(t, p.Proofs["block"], 4)
+		require.Len(t, p.Proofs[""], 1)
+	})
+
+	t.Run("unchanged without majority", func(t *testing.T) {
+		t.Parallel()
+
+		weak := tmconsensus.CommitProof{
+			Proofs: map[string][]gcrypto.SparseSignature{
+				"block": {sig(0), sig(1), sig(2)},
+			},
+		}
+		require.Equal(t, weak, gcstore.CompactCommitProof(h, weak, gcstore.SimpleKeyIndex))
+	})
+
+	t.Run("unchanged with unrecognized key ID", func(t *testing.T) {
+		t.Parallel()
+
+		odd := tmconsensus.CommitProof{
+			Proofs: map[string][]gcrypto.SparseSignature{
+				"block": {sig(2), sig(3), {KeyID: []byte{0, 9}}},
+			},
+		}
+		require.Equal(t, odd, gcstore.CompactCommitProof(h, odd, gcstore.SimpleKeyIndex))
+	})
+}
+
+func TestCompactingCommittedHeaderStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	inner := tmmemstore.NewCommittedHeaderStore()
+	s := gcstore.NewCompactingCommittedHeaderStore(inner, nil)
+
+	h := tmconsensus.Header{
+		Hash:   []byte("block"),
+		Height: 1,
+		ValidatorSet: tmconsensus.ValidatorSet{
+			Validators: []tmconsensus.Validator{{Power: 1}, {Power: 1}, {Power: 1}, {Power: 1}},
+		},
+	}
+
+	sigs := make([]gcrypto.SparseSignature, 4)
+	for i := range sigs {
+		sigs[i] = gcrypto.SparseSignature{KeyID: []byte{0, byte(i)}, Sig: []byte{byte(i)}}
+	}
+
+	require.NoError(t, s.SaveCommittedHeader(ctx, tmconsensus.CommittedHeader{
+		Header: h,
+		Proof: tmconsensus.CommitProof{
+			Proofs: map[string][]gcrypto.SparseSignature{"block": sigs},
+		},
+	}))
+
+	ch, err := s.LoadCommittedHeader(ctx, 1)
+	require.NoError(t, err)
+
+	// Three of four equal validators are required.
+	require.Equal(t, sigs[:3], ch.Proof.Proofs["block"])
+}
@@ -0,0 +1,167 @@
+package gcstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySource provides the symmetric key used to encrypt data at rest.
+//
+// Implementations may read the key from local disk, as [FileKeySource] does,
+// or retrieve it from an external key management service.
+type KeySource interface {
+	// Key returns a 16-, 24-, or 32-byte AES key.
+	Key(ctx context.Context) ([]byte, error)
+}
+
+// FileKeySource is a [KeySource] reading a hex-encoded key
+// from the file at the given path.
+// Surrounding whitespace in the file is ignored.
+type FileKeySource string
+
+func (p FileKeySource) Key(context.Context) ([]byte, error) {
+	b, err := os.ReadFile(string(p))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode hex key from %s: %w", string(p), err)
+	}
+	return key, nil
+}
+
+// encryptedFormatV1 is the leading byte of every value
+// written by an [EncryptingBlockDataStore],
+// followed by the AES-GCM nonce and the sealed data.
+const encryptedFormatV1 byte = 1
+
+// ErrBlockDataDecrypt is returned by an [EncryptingBlockDataStore]
+// when stored data cannot be decrypted,
+// whether due to a wrong key or to tampering.
+var ErrBlockDataDecrypt = errors.New("failed to decrypt block data")
+
+// EncryptingBlockDataStore is a [BlockDataStore]
+// that encrypts block data with AES-GCM before saving it to an underlying store,
+// and decrypts it when loading.
+//
+// The height and data ID are authenticated along with the data,
+// so the underlying store cannot return data saved under a different key
+// without the load failing.
+// Heights and data IDs themselves are stored in the clear,
+// as the underlying store must be able to look them up.
+type EncryptingBlockDataStore struct {
+	inner BlockDataStore
+
+	aead cipher.AEAD
+}
+
+// NewEncryptingBlockDataStore returns an EncryptingBlockDataStore wrapping inner,
+// using the key retrieved from ks.
+func NewEncryptingBlockDataStore(
+	ctx context.Context, inner BlockDataStore, ks KeySource,
+) (*EncryptingBlockDataStore, error) {
+	key, err := ks.Key(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES-GCM cipher: %w", err)
+	}
+
+	return &EncryptingBlockDataStore{
+		inner: inner,
+		aead:  aead,
+	}, nil
+}
+
+func (s *EncryptingBlockDataStore) SaveBlockData(
+	ctx context.Context,
+	height uint64,
+	dataID string,
+	data []byte,
+) error {
+	ns := s.aead.NonceSize()
+	buf := make([]byte, 1+ns, 1+ns+len(data)+s.aead.Overhead())
+	buf[0] = encryptedFormatV1
+	if _, err := rand.Read(buf[1:]); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	buf = s.aead.Seal(buf, buf[1:], data, blockDataAD(height, dataID))
+	return s.inner.SaveBlockData(ctx, height, dataID, buf)
+}
+
+func (s *EncryptingBlockDataStore) LoadBlockDataByHeight(
+	ctx context.Context,
+	height uint64,
+	dst []byte,
+) (
+	dataID string, data []byte, err error,
+) {
+	dataID, enc, err := s.inner.LoadBlockDataByHeight(ctx, height, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err = s.open(dst, height, dataID, enc)
+	if err != nil {
+		return "", nil, err
+	}
+	return dataID, data, nil
+}
+
+func (s *EncryptingBlockDataStore) LoadBlockDataByID(
+	ctx context.Context,
+	dataID string,
+	dst []byte,
+) (
+	height uint64, data []byte, err error,
+) {
+	height, enc, err := s.inner.LoadBlockDataByID(ctx, dataID, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	data, err = s.open(dst, height, dataID, enc)
+	if err != nil {
+		return 0, nil, err
+	}
+	return height, data, nil
+}
+
+// open decrypts enc, appending the plaintext to dst.
+func (s *EncryptingBlockDataStore) open(dst []byte, height uint64, dataID string, enc []byte) ([]byte, error) {
+	ns := s.aead.NonceSize()
+	if len(enc) < 1+ns || enc[0] != encryptedFormatV1 {
+		return nil, fmt.Errorf("%w at height %d: unrecognized format", ErrBlockDataDecrypt, height)
+	}
+
+	data, err := s.aead.Open(dst, enc[1:1+ns], enc[1+ns:], blockDataAD(height, dataID))
+	if err != nil {
+		return nil, fmt.Errorf("%w at height %d: %w", ErrBlockDataDecrypt, height, err)
+	}
+	return data, nil
+}
+
+// blockDataAD returns the additional authenticated data
+// binding encrypted block data to its height and data ID.
+func blockDataAD(height uint64, dataID string) []byte {
+	return append(binary.BigEndian.AppendUint64(nil, height), dataID...)
+}
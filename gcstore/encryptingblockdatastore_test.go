@@ -0,0 +1,93 @@
+package gcstore_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcmemstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcstoretest"
+	"github.com/stretchr/testify/require"
+)
+
+type staticKeySource []byte
+
+func (k staticKeySource) Key(context.Context) ([]byte, error) {
+	return k, nil
+}
+
+func TestEncryptingBlockDataStoreCompliance(t *testing.T) {
+	t.Parallel()
+
+	key := staticKeySource(bytes.Repeat([]byte{1}, 32))
+	gcstoretest.TestBlockDataStoreCompliance(t, func() gcstore.BlockDataStore {
+		s, err := gcstore.NewEncryptingBlockDataStore(
+			context.Background(), gcmemstore.NewBlockDataStore(), key,
+		)
+		require.NoError(t, err)
+		return s
+	})
+}
+
+func TestEncryptingBlockDataStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	inner := gcmemstore.NewBlockDataStore()
+	s, err := gcstore.NewEncryptingBlockDataStore(
+		ctx, inner, staticKeySource(bytes.Repeat([]byte{1}, 32)),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, s.SaveBlockData(ctx, 1, "one", []byte("first block")))
+	require.NoError(t, s.SaveBlockData(ctx, 2, "two", []byte("second block")))
+
+	t.Run("underlying data is not plaintext", func(t *testing.T) {
+		_, enc, err := inner.LoadBlockDataByHeight(ctx, 1, nil)
+		require.NoError(t, err)
+		require.NotContains(t, string(enc), "first block")
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := gcstore.NewEncryptingBlockDataStore(
+			ctx, inner, staticKeySource(bytes.Repeat([]byte{2}, 32)),
+		)
+		require.NoError(t, err)
+
+		_, _, err = other.LoadBlockDataByHeight(ctx, 1, nil)
+		require.ErrorIs(t, err, gcstore.ErrBlockDataDecrypt)
+	})
+
+	t.Run("data moved to another height", func(t *testing.T) {
+		_, enc, err := inner.LoadBlockDataByHeight(ctx, 2, nil)
+		require.NoError(t, err)
+
+		// Save the second block's ciphertext directly under a new height and ID.
+		require.NoError(t, inner.SaveBlockData(ctx, 3, "three", enc))
+
+		_, _, err = s.LoadBlockDataByID(ctx, "three", nil)
+		require.ErrorIs(t, err, gcstore.ErrBlockDataDecrypt)
+	})
+
+	t.Run("invalid key length", func(t *testing.T) {
+		_, err := gcstore.NewEncryptingBlockDataStore(
+			ctx, inner, staticKeySource([]byte("short")),
+		)
+		require.Error(t, err)
+	})
+}
+
+func TestFileKeySource(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(p, []byte("  000102030405060708090a0b0c0d0e0f\n"), 0600))
+
+	key, err := gcstore.FileKeySource(p).Key(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, key)
+}
@@ -3,6 +3,8 @@ package gcstore
 import (
 	"errors"
 	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gcerr"
 )
 
 type AlreadyHaveBlockDataForHeightError struct {
@@ -13,6 +15,8 @@ func (e AlreadyHaveBlockDataForHeightError) Error() string {
 	return fmt.Sprintf("already have block data for height %d", e.Height)
 }
 
+func (AlreadyHaveBlockDataForHeightError) ErrorCode() gcerr.Code { return gcerr.CodeAlreadyExists }
+
 type AlreadyHaveBlockDataForIDError struct {
 	ID string
 }
@@ -21,7 +25,21 @@ func (e AlreadyHaveBlockDataForIDError) Error() string {
 	return fmt.Sprintf("already have block data for id %q", e.ID)
 }
 
-var ErrBlockDataNotFound = errors.New("block data not found")
+func (AlreadyHaveBlockDataForIDError) ErrorCode() gcerr.Code { return gcerr.CodeAlreadyExists }
+
+var ErrBlockDataNotFound = gcerr.New(gcerr.CodeNotFound, "block data not found")
+
+type AlreadyHaveTxsForHeightError struct {
+	Height uint64
+}
+
+func (e AlreadyHaveTxsForHeightError) Error() string {
+	return fmt.Sprintf("already have transactions for height %d", e.Height)
+}
+
+func (AlreadyHaveTxsForHeightError) ErrorCode() gcerr.Code { return gcerr.CodeAlreadyExists }
+
+var ErrTxNotFound = gcerr.New(gcerr.CodeNotFound, "transaction not found")
 
 func IsAlreadyHaveBlockDataError(e error) bool {
 	return errors.As(e, new(AlreadyHaveBlockDataForHeightError)) ||
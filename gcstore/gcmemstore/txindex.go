@@ -0,0 +1,57 @@
+package gcmemstore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+)
+
+type TxIndex struct {
+	mu sync.Mutex
+
+	heights map[uint64]struct{}
+	locs    map[string]txLocation
+}
+
+type txLocation struct {
+	h   uint64
+	idx int
+}
+
+func NewTxIndex() *TxIndex {
+	return &TxIndex{
+		heights: make(map[uint64]struct{}),
+		locs:    make(map[string]txLocation),
+	}
+}
+
+func (s *TxIndex) SaveBlockTxs(ctx context.Context, height uint64, txHashes [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.heights[height]; ok {
+		return gcstore.AlreadyHaveTxsForHeightError{Height: height}
+	}
+	s.heights[height] = struct{}{}
+
+	for i, h := range txHashes {
+		// Converting to a string key copies the hash.
+		if _, ok := s.locs[string(h)]; ok {
+			continue
+		}
+		s.locs[string(h)] = txLocation{h: height, idx: i}
+	}
+	return nil
+}
+
+func (s *TxIndex) LoadTxLocation(ctx context.Context, txHash []byte) (height uint64, index int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loc, ok := s.locs[string(txHash)]
+	if !ok {
+		return 0, 0, gcstore.ErrTxNotFound
+	}
+	return loc.h, loc.idx, nil
+}
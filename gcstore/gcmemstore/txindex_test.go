@@ -0,0 +1,17 @@
+package gcmemstore_test
+
+import (
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcmemstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcstoretest"
+)
+
+func TestTxIndexCompliance(t *testing.T) {
+	t.Parallel()
+
+	gcstoretest.TestTxIndexCompliance(t, func() gcstore.TxIndex {
+		return gcmemstore.NewTxIndex()
+	})
+}
@@ -0,0 +1,79 @@
+package gcpgstore
+
+// Internal test, because the durability is only observable
+// inside the transactions the store begins for its own writes.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_durabilityReachesSession(t *testing.T) {
+	t.Parallel()
+
+	dsn := os.Getenv("GCOSMOS_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("GCOSMOS_TEST_POSTGRES_DSN not set")
+	}
+
+	for _, tc := range []struct {
+		durability Durability
+		want       string // Empty to expect the server default.
+	}{
+		{durability: DurabilitySync},
+		{durability: DurabilityPeriodic, want: "off"},
+	} {
+		t.Run(fmt.Sprintf("durability %d", tc.durability), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			db, err := sql.Open("pgx", dsn)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = db.Close() })
+
+			// A single connection, so that every statement below
+			// runs in the same session as the store's transaction.
+			db.SetMaxOpenConns(1)
+
+			reg := new(gcrypto.Registry)
+			gcrypto.RegisterEd25519(reg)
+
+			s, err := NewStore(ctx, StoreConfig{
+				DB:       db,
+				Registry: reg,
+
+				TablePrefix: fmt.Sprintf("test_%d_durability_%d_", os.Getpid(), tc.durability),
+				Durability:  tc.durability,
+			})
+			require.NoError(t, err)
+
+			var serverDefault string
+			require.NoError(t, db.QueryRowContext(ctx, `SHOW synchronous_commit`).Scan(&serverDefault))
+			want := tc.want
+			if want == "" {
+				want = serverDefault
+			}
+
+			tx, err := s.beginRecoverable(ctx)
+			require.NoError(t, err)
+
+			var got string
+			require.NoError(t, tx.QueryRowContext(ctx, `SHOW synchronous_commit`).Scan(&got))
+			require.Equal(t, want, got)
+			require.NoError(t, tx.Commit())
+
+			// The setting does not outlive the transaction,
+			// so other writes on the pooled connection keep the server default.
+			require.NoError(t, db.QueryRowContext(ctx, `SHOW synchronous_commit`).Scan(&got))
+			require.Equal(t, serverDefault, got)
+		})
+	}
+}
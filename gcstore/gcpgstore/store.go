@@ -0,0 +1,432 @@
+// Package gcpgstore contains PostgreSQL-backed store implementations,
+// for deployments such as hosted RPC providers
+// that share a database across replicas or run SQL analytics over chain data.
+//
+// The package only depends on database/sql.
+// Callers open the *sql.DB with the PostgreSQL driver of their choice.
+package gcpgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+)
+
+// StoreConfig is the configuration for [NewStore].
+type StoreConfig struct {
+	DB *sql.DB
+
+	// Used to encode and decode validator public keys.
+	Registry *gcrypto.Registry
+
+	// Prefix for every table name, so that multiple chains
+	// can share one database schema.
+	// May only contain lowercase letters, digits, and underscores.
+	// Defaults to "gcosmos_".
+	TablePrefix string
+
+	// How durably recoverable writes are committed.
+	// Defaults to DurabilitySync.
+	Durability Durability
+}
+
+// Durability controls when a commit of a recoverable write
+// returns relative to the write reaching stable storage.
+//
+// Writes are classified by whether losing them in a crash is recoverable.
+// Finalizations are commit-critical: the engine relies on them
+// to resume consensus after a restart,
+// so they are always committed with DurabilitySync regardless of configuration.
+// Block data and transaction index entries can be fetched from peers
+// or rebuilt by replaying blocks, so they honor the configured durability.
+//
+// Group commit across concurrent writers is a server-wide setting in PostgreSQL,
+// controlled by commit_delay and commit_siblings, and is not configured here.
+type Durability uint8
+
+const (
+	// Every commit waits for its write-ahead log records to be flushed to disk.
+	// A write that returned successfully survives a crash.
+	DurabilitySync Durability = iota
+
+	// Commits return before the write-ahead log is flushed,
+	// and the server flushes it periodically (every wal_writer_delay).
+	// A crash may lose the most recent writes, up to about three times wal_writer_delay,
+	// but the database always recovers to a consistent state
+	// in which lost writes are entirely absent, never partially applied.
+	// The store then reports the lost heights as missing,
+	// and saving them again succeeds.
+	DurabilityPeriodic
+)
+
+// ParseDurability parses the string form of a Durability:
+// "sync" (or the empty string) or "periodic".
+func ParseDurability(s string) (Durability, error) {
+	switch s {
+	case "", "sync":
+		return DurabilitySync, nil
+	case "periodic":
+		return DurabilityPeriodic, nil
+	default:
+		return 0, fmt.Errorf("unknown durability %q (must be sync or periodic)", s)
+	}
+}
+
+// Store is a PostgreSQL implementation of
+// [gcstore.BlockDataStore], [gcstore.TxIndex], and [tmstore.FinalizationStore].
+type Store struct {
+	db  *sql.DB
+	reg *gcrypto.Registry
+
+	durability Durability
+
+	// Table names, including the configured prefix.
+	blockData, finalizations, finValidators, txHeights, txs string
+}
+
+var validTablePrefix = regexp.MustCompile(`^[a-z0-9_]*$`)
+
+// NewStore returns a new Store based on cfg,
+// creating its tables if they do not yet exist.
+func NewStore(ctx context.Context, cfg StoreConfig) (*Store, error) {
+	prefix := cfg.TablePrefix
+	if prefix == "" {
+		prefix = "gcosmos_"
+	}
+	if !validTablePrefix.MatchString(prefix) {
+		return nil, fmt.Errorf("invalid table prefix %q", prefix)
+	}
+
+	s := &Store{
+		db:  cfg.DB,
+		reg: cfg.Registry,
+
+		durability: cfg.Durability,
+
+		blockData:     prefix + "block_data",
+		finalizations: prefix + "finalizations",
+		finValidators: prefix + "finalization_validators",
+		txHeights:     prefix + "tx_heights",
+		txs:           prefix + "txs",
+	}
+
+	if err := s.migrate(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create tables: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS ` + s.blockData + ` (
+			height BIGINT PRIMARY KEY,
+			data_id BYTEA NOT NULL UNIQUE,
+			data BYTEA NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS ` + s.finalizations + ` (
+			height BIGINT PRIMARY KEY,
+			round BIGINT NOT NULL,
+			block_hash BYTEA NOT NULL,
+			pub_key_hash BYTEA NOT NULL,
+			vote_power_hash BYTEA NOT NULL,
+			app_state_hash BYTEA NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS ` + s.finValidators + ` (
+			height BIGINT NOT NULL REFERENCES ` + s.finalizations + ` (height),
+			idx INTEGER NOT NULL,
+			pub_key BYTEA NOT NULL,
+			power BIGINT NOT NULL,
+			PRIMARY KEY (height, idx)
+		)`,
+
+		// Heights are tracked separately from transactions
+		// so that empty blocks are still recorded as indexed.
+		`CREATE TABLE IF NOT EXISTS ` + s.txHeights + ` (
+			height BIGINT PRIMARY KEY
+		)`,
+		`CREATE TABLE IF NOT EXISTS ` + s.txs + ` (
+			hash BYTEA PRIMARY KEY,
+			height BIGINT NOT NULL REFERENCES ` + s.txHeights + ` (height),
+			idx INTEGER NOT NULL
+		)`,
+	}
+
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// beginRecoverable begins a transaction for writes that can be recovered after a crash,
+// applying the configured durability.
+func (s *Store) beginRecoverable(ctx context.Context) (*sql.Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if s.durability == DurabilityPeriodic {
+		// SET LOCAL only applies until the end of this transaction,
+		// so pooled connections keep the server default for other writes.
+		if _, err := tx.ExecContext(ctx, `SET LOCAL synchronous_commit = off`); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("failed to set commit durability: %w", err)
+		}
+	}
+
+	return tx, nil
+}
+
+func (s *Store) SaveBlockData(ctx context.Context, height uint64, dataID string, data []byte) (err error) {
+	tx, err := s.beginRecoverable(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO `+s.blockData+` (height, data_id, data) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`,
+		height, []byte(dataID), data,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert block data: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to check inserted block data: %w", err)
+	} else if n > 0 {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit block data: %w", err)
+		}
+		return nil
+	}
+
+	// Nothing was inserted, so either the height or the ID conflicted.
+	// Returning a non-nil error rolls back the empty transaction.
+	var heightExists bool
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+s.blockData+` WHERE height = $1)`,
+		height,
+	).Scan(&heightExists); err != nil {
+		return fmt.Errorf("failed to check block data conflict: %w", err)
+	}
+	if heightExists {
+		return gcstore.AlreadyHaveBlockDataForHeightError{Height: height}
+	}
+	return gcstore.AlreadyHaveBlockDataForIDError{ID: dataID}
+}
+
+func (s *Store) LoadBlockDataByHeight(ctx context.Context, height uint64, dst []byte) (
+	dataID string, data []byte, err error,
+) {
+	var id []byte
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT data_id, data FROM `+s.blockData+` WHERE height = $1`,
+		height,
+	).Scan(&id, &data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, gcstore.ErrBlockDataNotFound
+		}
+		return "", nil, fmt.Errorf("failed to load block data: %w", err)
+	}
+	return string(id), append(dst, data...), nil
+}
+
+func (s *Store) LoadBlockDataByID(ctx context.Context, dataID string, dst []byte) (
+	height uint64, data []byte, err error,
+) {
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT height, data FROM `+s.blockData+` WHERE data_id = $1`,
+		[]byte(dataID),
+	).Scan(&height, &data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil, gcstore.ErrBlockDataNotFound
+		}
+		return 0, nil, fmt.Errorf("failed to load block data: %w", err)
+	}
+	return height, append(dst, data...), nil
+}
+
+// SaveFinalization always commits synchronously, regardless of the configured [Durability].
+func (s *Store) SaveFinalization(
+	ctx context.Context,
+	height uint64, round uint32,
+	blockHash string,
+	valSet tmconsensus.ValidatorSet,
+	appStateHash string,
+) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO `+s.finalizations+`
+		(height, round, block_hash, pub_key_hash, vote_power_hash, app_state_hash)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING`,
+		height, round, []byte(blockHash),
+		valSet.PubKeyHash, valSet.VotePowerHash,
+		[]byte(appStateHash),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert finalization: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to check inserted finalization: %w", err)
+	} else if n == 0 {
+		return tmstore.FinalizationOverwriteError{Height: height}
+	}
+
+	for i, v := range valSet.Validators {
+		if v.Power > math.MaxInt64 {
+			return fmt.Errorf("validator %d power %d exceeds database limit", i, v.Power)
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO `+s.finValidators+` (height, idx, pub_key, power) VALUES ($1, $2, $3, $4)`,
+			height, i, s.reg.Marshal(v.PubKey), int64(v.Power),
+		); err != nil {
+			return fmt.Errorf("failed to insert finalization validator %d: %w", i, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit finalization: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) LoadFinalizationByHeight(ctx context.Context, height uint64) (
+	round uint32,
+	blockHash string,
+	valSet tmconsensus.ValidatorSet,
+	appStateHash string,
+	err error,
+) {
+	var bh, ash []byte
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT round, block_hash, pub_key_hash, vote_power_hash, app_state_hash
+		FROM `+s.finalizations+` WHERE height = $1`,
+		height,
+	).Scan(&round, &bh, &valSet.PubKeyHash, &valSet.VotePowerHash, &ash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", tmconsensus.ValidatorSet{}, "", tmconsensus.HeightUnknownError{Want: height}
+		}
+		return 0, "", tmconsensus.ValidatorSet{}, "", fmt.Errorf("failed to load finalization: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT pub_key, power FROM `+s.finValidators+` WHERE height = $1 ORDER BY idx`,
+		height,
+	)
+	if err != nil {
+		return 0, "", tmconsensus.ValidatorSet{}, "", fmt.Errorf("failed to load finalization validators: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pkb []byte
+		var power int64
+		if err := rows.Scan(&pkb, &power); err != nil {
+			return 0, "", tmconsensus.ValidatorSet{}, "", fmt.Errorf("failed to scan finalization validator: %w", err)
+		}
+		pk, err := s.reg.Unmarshal(pkb)
+		if err != nil {
+			return 0, "", tmconsensus.ValidatorSet{}, "", fmt.Errorf("failed to decode validator public key: %w", err)
+		}
+		valSet.Validators = append(valSet.Validators, tmconsensus.Validator{
+			PubKey: pk,
+			Power:  uint64(power),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return 0, "", tmconsensus.ValidatorSet{}, "", fmt.Errorf("failed to iterate finalization validators: %w", err)
+	}
+
+	return round, string(bh), valSet, string(ash), nil
+}
+
+func (s *Store) SaveBlockTxs(ctx context.Context, height uint64, txHashes [][]byte) (err error) {
+	tx, err := s.beginRecoverable(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO `+s.txHeights+` (height) VALUES ($1) ON CONFLICT DO NOTHING`,
+		height,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction height: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to check inserted transaction height: %w", err)
+	} else if n == 0 {
+		return gcstore.AlreadyHaveTxsForHeightError{Height: height}
+	}
+
+	for i, h := range txHashes {
+		// A repeated hash keeps its first location.
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO `+s.txs+` (hash, height, idx) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+			h, height, i,
+		); err != nil {
+			return fmt.Errorf("failed to insert transaction %d: %w", i, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) LoadTxLocation(ctx context.Context, txHash []byte) (height uint64, index int, err error) {
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT height, idx FROM `+s.txs+` WHERE hash = $1`,
+		txHash,
+	).Scan(&height, &index); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, gcstore.ErrTxNotFound
+		}
+		return 0, 0, fmt.Errorf("failed to load transaction location: %w", err)
+	}
+	return height, index, nil
+}
@@ -0,0 +1,98 @@
+package gcpgstore_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcpgstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcstoretest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmstoretest"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests run against a real database,
+// selected by the GCOSMOS_TEST_POSTGRES_DSN environment variable,
+// through the same pgx driver that gserver links.
+// Each store uses its own table prefix, so the database may be reused between runs.
+
+var tablePrefixCounter atomic.Uint32
+
+func newTestStore(t *testing.T) *gcpgstore.Store {
+	t.Helper()
+
+	dsn := os.Getenv("GCOSMOS_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("GCOSMOS_TEST_POSTGRES_DSN not set")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	s, err := gcpgstore.NewStore(context.Background(), gcpgstore.StoreConfig{
+		DB:       db,
+		Registry: reg,
+
+		TablePrefix: fmt.Sprintf("test_%d_%d_", os.Getpid(), tablePrefixCounter.Add(1)),
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func TestStore_BlockDataStoreCompliance(t *testing.T) {
+	t.Parallel()
+
+	newTestStore(t) // Skip early if not configured.
+
+	gcstoretest.TestBlockDataStoreCompliance(t, func() gcstore.BlockDataStore {
+		return newTestStore(t)
+	})
+}
+
+func TestStore_TxIndexCompliance(t *testing.T) {
+	t.Parallel()
+
+	newTestStore(t) // Skip early if not configured.
+
+	gcstoretest.TestTxIndexCompliance(t, func() gcstore.TxIndex {
+		return newTestStore(t)
+	})
+}
+
+func TestStore_FinalizationStoreCompliance(t *testing.T) {
+	t.Parallel()
+
+	newTestStore(t) // Skip early if not configured.
+
+	tmstoretest.TestFinalizationStoreCompliance(t, func(func(func())) (tmstore.FinalizationStore, error) {
+		return newTestStore(t), nil
+	})
+}
+
+func TestParseDurability(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]gcpgstore.Durability{
+		"":         gcpgstore.DurabilitySync,
+		"sync":     gcpgstore.DurabilitySync,
+		"periodic": gcpgstore.DurabilityPeriodic,
+	} {
+		got, err := gcpgstore.ParseDurability(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err := gcpgstore.ParseDurability("never")
+	require.Error(t, err)
+}
@@ -0,0 +1,234 @@
+// Package gcs3store contains a [gcstore.BlockDataStore]
+// backed by an S3-compatible object store,
+// so that archive nodes need not keep historical block data on local disk.
+package gcs3store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+)
+
+// BlockDataStoreConfig is the configuration for [NewBlockDataStore].
+type BlockDataStoreConfig struct {
+	Objects ObjectStore
+
+	// Prefix applied to every object key, e.g. "chain-1/".
+	Prefix string
+
+	// Number of most recently saved or loaded heights
+	// to keep in the in-memory cache.
+	// Zero disables the cache.
+	CacheSize int
+}
+
+// BlockDataStore is a [gcstore.BlockDataStore] persisting block data in an [ObjectStore].
+// Saved block data is written through to a bounded in-memory cache,
+// so that recent heights, which peers request most often,
+// are served without a round trip to the object store.
+//
+// Each height is stored as one object holding the data ID and the data,
+// under a key derived from the height,
+// alongside a small index object mapping the data ID to its height.
+//
+// BlockDataStore enforces uniqueness of heights and data IDs
+// by checking for existing objects before writing,
+// so only a single process may write to a given bucket and prefix.
+type BlockDataStore struct {
+	objs   ObjectStore
+	prefix string
+
+	// Serializes saves, so the existence checks are not raced within this process.
+	saveMu sync.Mutex
+
+	cacheMu    sync.Mutex
+	cacheSize  int
+	cache      map[uint64]cachedBlockData
+	cacheIDs   map[string]uint64
+	cacheOrder []uint64
+}
+
+type cachedBlockData struct {
+	id   string
+	data []byte
+}
+
+// NewBlockDataStore returns a new BlockDataStore based on cfg.
+func NewBlockDataStore(cfg BlockDataStoreConfig) *BlockDataStore {
+	return &BlockDataStore{
+		objs:   cfg.Objects,
+		prefix: cfg.Prefix,
+
+		cacheSize: cfg.CacheSize,
+		cache:     make(map[uint64]cachedBlockData, cfg.CacheSize),
+		cacheIDs:  make(map[string]uint64, cfg.CacheSize),
+	}
+}
+
+func (s *BlockDataStore) SaveBlockData(
+	ctx context.Context,
+	height uint64,
+	dataID string,
+	data []byte,
+) error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	if _, err := s.objs.GetObject(ctx, s.heightKey(height)); err == nil {
+		return gcstore.AlreadyHaveBlockDataForHeightError{Height: height}
+	} else if !errors.Is(err, ErrObjectNotFound) {
+		return fmt.Errorf("failed to check for existing height %d: %w", height, err)
+	}
+
+	if _, err := s.objs.GetObject(ctx, s.idKey(dataID)); err == nil {
+		return gcstore.AlreadyHaveBlockDataForIDError{ID: dataID}
+	} else if !errors.Is(err, ErrObjectNotFound) {
+		return fmt.Errorf("failed to check for existing data ID %q: %w", dataID, err)
+	}
+
+	obj := binary.AppendUvarint(nil, uint64(len(dataID)))
+	obj = append(obj, dataID...)
+	obj = append(obj, data...)
+	if err := s.objs.PutObject(ctx, s.heightKey(height), obj); err != nil {
+		return fmt.Errorf("failed to save block data for height %d: %w", height, err)
+	}
+
+	if err := s.objs.PutObject(
+		ctx, s.idKey(dataID), binary.BigEndian.AppendUint64(nil, height),
+	); err != nil {
+		return fmt.Errorf("failed to save data ID index for height %d: %w", height, err)
+	}
+
+	s.addToCache(height, dataID, bytes.Clone(data))
+	return nil
+}
+
+func (s *BlockDataStore) LoadBlockDataByHeight(
+	ctx context.Context,
+	height uint64,
+	dst []byte,
+) (
+	dataID string, data []byte, err error,
+) {
+	s.cacheMu.Lock()
+	c, ok := s.cache[height]
+	s.cacheMu.Unlock()
+	if ok {
+		return c.id, append(dst, c.data...), nil
+	}
+
+	dataID, data, err = s.loadHeight(ctx, height)
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.addToCache(height, dataID, data)
+	return dataID, append(dst, data...), nil
+}
+
+func (s *BlockDataStore) LoadBlockDataByID(
+	ctx context.Context,
+	dataID string,
+	dst []byte,
+) (
+	height uint64, data []byte, err error,
+) {
+	s.cacheMu.Lock()
+	height, ok := s.cacheIDs[dataID]
+	var c cachedBlockData
+	if ok {
+		c = s.cache[height]
+	}
+	s.cacheMu.Unlock()
+	if ok {
+		return height, append(dst, c.data...), nil
+	}
+
+	idx, err := s.objs.GetObject(ctx, s.idKey(dataID))
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return 0, nil, gcstore.ErrBlockDataNotFound
+		}
+		return 0, nil, fmt.Errorf("failed to load data ID index: %w", err)
+	}
+	if len(idx) != 8 {
+		return 0, nil, fmt.Errorf("malformed data ID index object for %q", dataID)
+	}
+	height = binary.BigEndian.Uint64(idx)
+
+	gotID, data, err := s.loadHeight(ctx, height)
+	if err != nil {
+		return 0, nil, err
+	}
+	if gotID != dataID {
+		return 0, nil, fmt.Errorf(
+			"data ID index for %q points at height %d, which has data ID %q",
+			dataID, height, gotID,
+		)
+	}
+
+	s.addToCache(height, dataID, data)
+	return height, append(dst, data...), nil
+}
+
+// loadHeight loads and decodes the block data object for height.
+// The returned data is owned by the caller.
+func (s *BlockDataStore) loadHeight(ctx context.Context, height uint64) (string, []byte, error) {
+	obj, err := s.objs.GetObject(ctx, s.heightKey(height))
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return "", nil, gcstore.ErrBlockDataNotFound
+		}
+		return "", nil, fmt.Errorf("failed to load block data for height %d: %w", height, err)
+	}
+
+	idLen, n := binary.Uvarint(obj)
+	if n <= 0 || uint64(len(obj)-n) < idLen {
+		return "", nil, fmt.Errorf("malformed block data object for height %d", height)
+	}
+	obj = obj[n:]
+	return string(obj[:idLen]), obj[idLen:], nil
+}
+
+// addToCache adds the given entry to the cache,
+// evicting the oldest entry if the cache is full.
+// The cache takes ownership of data.
+func (s *BlockDataStore) addToCache(height uint64, dataID string, data []byte) {
+	if s.cacheSize <= 0 {
+		return
+	}
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if _, ok := s.cache[height]; ok {
+		return
+	}
+
+	if len(s.cacheOrder) >= s.cacheSize {
+		oldest := s.cacheOrder[0]
+		s.cacheOrder = s.cacheOrder[1:]
+		delete(s.cacheIDs, s.cache[oldest].id)
+		delete(s.cache, oldest)
+	}
+
+	s.cache[height] = cachedBlockData{id: dataID, data: data}
+	s.cacheIDs[dataID] = height
+	s.cacheOrder = append(s.cacheOrder, height)
+}
+
+func (s *BlockDataStore) heightKey(height uint64) string {
+	// Zero-padded so that listing the bucket returns heights in order.
+	return fmt.Sprintf("%sheights/%020d", s.prefix, height)
+}
+
+func (s *BlockDataStore) idKey(dataID string) string {
+	// Data IDs are arbitrary strings, so hex-encode them for a safe key.
+	return s.prefix + "ids/" + hex.EncodeToString([]byte(dataID))
+}
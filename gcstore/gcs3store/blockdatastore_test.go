@@ -0,0 +1,103 @@
+package gcs3store_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcs3store"
+	"github.com/gordian-engine/gcosmos/gcstore/gcstoretest"
+	"github.com/stretchr/testify/require"
+)
+
+// memObjectStore is an in-memory [gcs3store.ObjectStore] that counts reads.
+type memObjectStore struct {
+	mu    sync.Mutex
+	objs  map[string][]byte
+	reads int
+}
+
+func newMemObjectStore() *memObjectStore {
+	return &memObjectStore{objs: make(map[string][]byte)}
+}
+
+func (s *memObjectStore) GetObject(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	b, ok := s.objs[key]
+	if !ok {
+		return nil, gcs3store.ErrObjectNotFound
+	}
+	return append([]byte(nil), b...), nil
+}
+
+func (s *memObjectStore) PutObject(_ context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objs[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func TestBlockDataStoreCompliance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without cache", func(t *testing.T) {
+		t.Parallel()
+
+		gcstoretest.TestBlockDataStoreCompliance(t, func() gcstore.BlockDataStore {
+			return gcs3store.NewBlockDataStore(gcs3store.BlockDataStoreConfig{
+				Objects: newMemObjectStore(),
+			})
+		})
+	})
+
+	t.Run("with cache", func(t *testing.T) {
+		t.Parallel()
+
+		gcstoretest.TestBlockDataStoreCompliance(t, func() gcstore.BlockDataStore {
+			return gcs3store.NewBlockDataStore(gcs3store.BlockDataStoreConfig{
+				Objects:   newMemObjectStore(),
+				CacheSize: 2,
+			})
+		})
+	})
+}
+
+func TestBlockDataStore_cache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	objs := newMemObjectStore()
+	s := gcs3store.NewBlockDataStore(gcs3store.BlockDataStoreConfig{
+		Objects:   objs,
+		Prefix:    "chain/",
+		CacheSize: 2,
+	})
+
+	for h, id := range []string{"zero", "one", "two"} {
+		require.NoError(t, s.SaveBlockData(ctx, uint64(h), id, []byte(id)))
+	}
+	require.Contains(t, objs.objs, "chain/heights/00000000000000000001")
+
+	objs.reads = 0
+
+	// Heights 1 and 2 were saved most recently, so they are served from the cache.
+	_, data, err := s.LoadBlockDataByHeight(ctx, 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, "two", string(data))
+	h, data, err := s.LoadBlockDataByID(ctx, "one", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), h)
+	require.Equal(t, "one", string(data))
+	require.Zero(t, objs.reads)
+
+	// Height 0 was evicted, so it is read from the object store.
+	id, data, err := s.LoadBlockDataByHeight(ctx, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, "zero", id)
+	require.Equal(t, "zero", string(data))
+	require.Equal(t, 1, objs.reads)
+}
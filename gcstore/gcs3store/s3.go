@@ -0,0 +1,261 @@
+package gcs3store
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ObjectStore is the minimal set of object storage operations
+// required by the [BlockDataStore].
+type ObjectStore interface {
+	// GetObject returns the content of the object with the given key.
+	// If no such object exists, GetObject returns [ErrObjectNotFound].
+	GetObject(ctx context.Context, key string) ([]byte, error)
+
+	// PutObject creates or overwrites the object with the given key.
+	PutObject(ctx context.Context, key string, data []byte) error
+}
+
+// ErrObjectNotFound is returned from [ObjectStore.GetObject]
+// when the requested key does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// S3Config is the configuration for [NewS3Client].
+type S3Config struct {
+	// Base URL of the S3-compatible service, e.g. "https://s3.us-east-1.amazonaws.com".
+	// Objects are addressed path-style, as Endpoint/Bucket/Key,
+	// which is supported by AWS and by self-hosted S3-compatible services alike.
+	Endpoint string
+
+	Bucket string
+
+	// Region used for request signing.
+	// Defaults to "us-east-1", which most S3-compatible services accept.
+	Region string
+
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Optional HTTP client; http.DefaultClient if nil.
+	HTTPClient *http.Client
+
+	// Optional clock for request signing; time.Now if nil.
+	Now func() time.Time
+}
+
+// S3Client is an [ObjectStore] using the S3 REST API,
+// authenticating requests with AWS Signature Version 4.
+type S3Client struct {
+	base   *url.URL
+	bucket string
+	region string
+
+	accessKeyID     string
+	secretAccessKey string
+
+	hc  *http.Client
+	now func() time.Time
+}
+
+// NewS3Client returns a new S3Client based on cfg.
+func NewS3Client(cfg S3Config) (*S3Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", cfg.Endpoint, err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q: scheme and host required", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket required")
+	}
+
+	c := &S3Client{
+		base:   base,
+		bucket: cfg.Bucket,
+		region: cfg.Region,
+
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+
+		hc:  cfg.HTTPClient,
+		now: cfg.Now,
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	if c.hc == nil {
+		c.hc = http.DefaultClient
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+	return c, nil
+}
+
+func (c *S3Client) GetObject(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %q: %w", key, err)
+	}
+	return b, nil
+}
+
+func (c *S3Client) PutObject(ctx context.Context, key string, data []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	return nil
+}
+
+func (c *S3Client) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	u := *c.base
+	u.Path = c.base.Path + "/" + c.bucket + "/" + key
+	u.RawPath = awsURIEncode(u.Path, false)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	signV4(req, body, c.accessKeyID, c.secretAccessKey, c.region, c.now())
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+func statusError(resp *http.Response) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf(
+		"unexpected status %d from object store: %s",
+		resp.StatusCode, strings.TrimSpace(string(b)),
+	)
+}
+
+// signV4 signs req in place with AWS Signature Version 4 for the S3 service,
+// signing the host, x-amz-content-sha256, and x-amz-date headers.
+func signV4(req *http.Request, body []byte, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256.Sum256(body)
+	payloadHex := hex.EncodeToString(payloadHash[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHex)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHex + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHex,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set(
+		"Authorization",
+		"AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+			", SignedHeaders="+signedHeaders+
+			", Signature="+sig,
+	)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return awsURIEncode(path, false)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), q[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEncode percent-encodes every byte of s outside the unreserved set,
+// as required for the canonical request.
+// Slashes are only encoded if encodeSlash is set,
+// which is the case for query parameters but not for paths.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9',
+			ch == '-', ch == '.', ch == '_', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package gcs3store_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcs3store"
+	"github.com/gordian-engine/gcosmos/gcstore/gcstoretest"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeS3 returns a server storing objects in memory,
+// requiring a signed request for every operation.
+func newFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	objs := map[string][]byte{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240102/us-east-1/s3/aws4_request, ") ||
+			r.Header.Get("X-Amz-Date") != "20240102T030405Z" ||
+			r.Header.Get("X-Amz-Content-Sha256") == "" {
+			http.Error(w, "unsigned request", http.StatusForbidden)
+			return
+		}
+
+		key, ok := strings.CutPrefix(r.URL.Path, "/bucket/")
+		if !ok {
+			http.Error(w, "no such bucket", http.StatusNotFound)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			b, ok := objs[key]
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(b)
+		case http.MethodPut:
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			objs[key] = b
+		default:
+			http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestS3Client(t *testing.T, endpoint string) *gcs3store.S3Client {
+	t.Helper()
+
+	c, err := gcs3store.NewS3Client(gcs3store.S3Config{
+		Endpoint: endpoint,
+		Bucket:   "bucket",
+
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+
+		Now: func() time.Time {
+			return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		},
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestS3Client(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := newTestS3Client(t, newFakeS3(t).URL)
+
+	_, err := c.GetObject(ctx, "missing")
+	require.ErrorIs(t, err, gcs3store.ErrObjectNotFound)
+
+	require.NoError(t, c.PutObject(ctx, "a/b", []byte("hello")))
+	got, err := c.GetObject(ctx, "a/b")
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(got))
+}
+
+func TestS3Client_BlockDataStoreCompliance(t *testing.T) {
+	t.Parallel()
+
+	gcstoretest.TestBlockDataStoreCompliance(t, func() gcstore.BlockDataStore {
+		return gcs3store.NewBlockDataStore(gcs3store.BlockDataStoreConfig{
+			Objects: newTestS3Client(t, newFakeS3(t).URL),
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package gcstoretest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/stretchr/testify/require"
+)
+
+type TxIndexFactory func() gcstore.TxIndex
+
+func TestTxIndexCompliance(t *testing.T, tif TxIndexFactory) {
+	ctx := context.Background()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		s := tif()
+
+		hashes := [][]byte{[]byte("tx_a"), []byte("tx_b")}
+		require.NoError(t, s.SaveBlockTxs(ctx, 3, hashes))
+
+		// Modifying the input must not affect the index.
+		hashes[0] = []byte("tx_c")
+
+		h, idx, err := s.LoadTxLocation(ctx, []byte("tx_a"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), h)
+		require.Zero(t, idx)
+
+		h, idx, err = s.LoadTxLocation(ctx, []byte("tx_b"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), h)
+		require.Equal(t, 1, idx)
+
+		_, _, err = s.LoadTxLocation(ctx, []byte("tx_c"))
+		require.ErrorIs(t, err, gcstore.ErrTxNotFound)
+	})
+
+	t.Run("empty blocks are recorded", func(t *testing.T) {
+		t.Parallel()
+
+		s := tif()
+
+		require.NoError(t, s.SaveBlockTxs(ctx, 1, nil))
+		err := s.SaveBlockTxs(ctx, 1, [][]byte{[]byte("tx")})
+		require.ErrorIs(t, err, gcstore.AlreadyHaveTxsForHeightError{Height: 1})
+	})
+
+	t.Run("duplicate height", func(t *testing.T) {
+		t.Parallel()
+
+		s := tif()
+
+		require.NoError(t, s.SaveBlockTxs(ctx, 1, [][]byte{[]byte("tx_a")}))
+		err := s.SaveBlockTxs(ctx, 1, [][]byte{[]byte("tx_b")})
+		require.ErrorIs(t, err, gcstore.AlreadyHaveTxsForHeightError{Height: 1})
+
+		_, _, err = s.LoadTxLocation(ctx, []byte("tx_b"))
+		require.ErrorIs(t, err, gcstore.ErrTxNotFound)
+	})
+
+	t.Run("repeated hash keeps first location", func(t *testing.T) {
+		t.Parallel()
+
+		s := tif()
+
+		require.NoError(t, s.SaveBlockTxs(ctx, 1, [][]byte{[]byte("tx")}))
+		require.NoError(t, s.SaveBlockTxs(ctx, 2, [][]byte{[]byte("other"), []byte("tx")}))
+
+		h, idx, err := s.LoadTxLocation(ctx, []byte("tx"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), h)
+		require.Zero(t, idx)
+	})
+}
@@ -0,0 +1,24 @@
+package gcstore
+
+import (
+	"context"
+)
+
+// TxIndex records where finalized transactions were included,
+// so that they can be looked up by hash.
+type TxIndex interface {
+	// SaveBlockTxs records the hashes of the transactions finalized at height,
+	// in block order.
+	// If transactions for the height were already saved,
+	// an [AlreadyHaveTxsForHeightError] is returned.
+	//
+	// Callers may assume that the index does not retain a reference to txHashes.
+	SaveBlockTxs(ctx context.Context, height uint64, txHashes [][]byte) error
+
+	// LoadTxLocation returns the height and the index within the block
+	// of the transaction with the given hash.
+	//
+	// If the transaction was never saved,
+	// [ErrTxNotFound] is returned.
+	LoadTxLocation(ctx context.Context, txHash []byte) (height uint64, index int, err error)
+}
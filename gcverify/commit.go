@@ -0,0 +1,166 @@
+// Package gcverify checks commit proofs outside of the consensus engine,
+// for use by RPC consumers, bridges, and nodes following headers
+// without running consensus.
+//
+// It also defines [SignatureVerifier], through which a node
+// may offload the signature checks in every vote and commit proof it handles.
+package gcverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+)
+
+var (
+	// ErrPubKeyHashMismatch indicates a commit proof
+	// made for a different validator set.
+	ErrPubKeyHashMismatch = gcerr.New(gcerr.CodeMismatch, "commit proof public key hash does not match validator set")
+
+	// ErrInvalidSignatures indicates a commit proof
+	// containing a signature that does not verify.
+	ErrInvalidSignatures = gcerr.New(gcerr.CodeInvalid, "commit proof contains invalid signatures")
+
+	// ErrNoVotingPower indicates a validator set with zero total power.
+	ErrNoVotingPower = gcerr.New(gcerr.CodeInvalid, "validator set has no voting power")
+)
+
+// InsufficientPowerError indicates a commit proof whose valid signatures
+// do not reach a majority of the validator set's power.
+type InsufficientPowerError struct {
+	Have, Need uint64
+}
+
+func (e InsufficientPowerError) Error() string {
+	return fmt.Sprintf("commit proof has power %d, need at least %d", e.Have, e.Need)
+}
+
+func (InsufficientPowerError) ErrorCode() gcerr.Code { return gcerr.CodeInvalid }
+
+// CommitProof reports an error unless proof holds valid precommit signatures
+// for blockHash at height from a majority of the power in vals.
+//
+// The proof is the one published for the block,
+// in the committed header or the next header's PrevCommitProof.
+// The caller is responsible for vals being the validator set for height;
+// [*Verifier.VerifyCommit] looks it up from a store.
+func CommitProof(
+	vals tmconsensus.ValidatorSet,
+	height uint64, blockHash []byte,
+	proof tmconsensus.CommitProof,
+	ss tmconsensus.SignatureScheme,
+	cmsp gcrypto.CommonMessageSignatureProofScheme,
+) error {
+	if proof.PubKeyHash != string(vals.PubKeyHash) {
+		return ErrPubKeyHashMismatch
+	}
+
+	msg, err := tmconsensus.PrecommitSignBytes(tmconsensus.VoteTarget{
+		Height:    height,
+		Round:     proof.Round,
+		BlockHash: string(blockHash),
+	}, ss)
+	if err != nil {
+		return fmt.Errorf("failed to build precommit sign bytes: %w", err)
+	}
+
+	sp, err := cmsp.New(msg, tmconsensus.ValidatorsToPubKeys(vals.Validators), proof.PubKeyHash)
+	if err != nil {
+		return fmt.Errorf("failed to build signature proof: %w", err)
+	}
+
+	res := sp.MergeSparse(gcrypto.SparseSignatureProof{
+		PubKeyHash: proof.PubKeyHash,
+		Signatures: proof.Proofs[string(blockHash)],
+	})
+	if !res.AllValidSignatures {
+		return ErrInvalidSignatures
+	}
+
+	var totalPow, signedPow uint64
+	bs := sp.SignatureBitSet()
+	for i, v := range vals.Validators {
+		totalPow += v.Power
+		if bs.Test(uint(i)) {
+			signedPow += v.Power
+		}
+	}
+	if totalPow == 0 {
+		return ErrNoVotingPower
+	}
+	if maj := tmconsensus.ByzantineMajority(totalPow); signedPow < maj {
+		return InsufficientPowerError{Have: signedPow, Need: maj}
+	}
+
+	return nil
+}
+
+// VerifierConfig is the configuration for [NewVerifier].
+type VerifierConfig struct {
+	// Source of the validator sets for each height.
+	Store tmstore.CommittedHeaderStore
+
+	SignatureScheme                   tmconsensus.SignatureScheme
+	CommonMessageSignatureProofScheme gcrypto.CommonMessageSignatureProofScheme
+}
+
+// Verifier checks commit proofs against the validator sets
+// in a committed header store.
+type Verifier struct {
+	s    tmstore.CommittedHeaderStore
+	ss   tmconsensus.SignatureScheme
+	cmsp gcrypto.CommonMessageSignatureProofScheme
+}
+
+// NewVerifier returns a new Verifier based on cfg.
+func NewVerifier(cfg VerifierConfig) *Verifier {
+	return &Verifier{
+		s:    cfg.Store,
+		ss:   cfg.SignatureScheme,
+		cmsp: cfg.CommonMessageSignatureProofScheme,
+	}
+}
+
+// VerifyCommit reports an error unless proof commits blockHash at height,
+// using the validator set stored for that height.
+//
+// The validator set is taken from the committed header at height if one is stored,
+// or else from the next validator set of the committed header at height-1,
+// so that the proof for the block after the latest committed header can also be checked.
+// If neither header is stored, the error is a [tmconsensus.HeightUnknownError].
+func (v *Verifier) VerifyCommit(
+	ctx context.Context, height uint64, blockHash []byte, proof tmconsensus.CommitProof,
+) error {
+	vals, err := v.validatorSet(ctx, height)
+	if err != nil {
+		return err
+	}
+	return CommitProof(vals, height, blockHash, proof, v.ss, v.cmsp)
+}
+
+func (v *Verifier) validatorSet(ctx context.Context, height uint64) (tmconsensus.ValidatorSet, error) {
+	ch, err := v.s.LoadCommittedHeader(ctx, height)
+	if err == nil {
+		return ch.Header.ValidatorSet, nil
+	}
+	if !errors.As(err, new(tmconsensus.HeightUnknownError)) || height == 0 {
+		return tmconsensus.ValidatorSet{}, fmt.Errorf("failed to load committed header at height %d: %w", height, err)
+	}
+
+	prev, prevErr := v.s.LoadCommittedHeader(ctx, height-1)
+	if prevErr != nil {
+		if errors.As(prevErr, new(tmconsensus.HeightUnknownError)) {
+			// Report the originally requested height.
+			return tmconsensus.ValidatorSet{}, err
+		}
+		return tmconsensus.ValidatorSet{}, fmt.Errorf(
+			"failed to load committed header at height %d: %w", height-1, prevErr,
+		)
+	}
+	return prev.Header.NextValidatorSet, nil
+}
@@ -0,0 +1,102 @@
+package gcverify_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_VerifyCommit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	s := tmmemstore.NewCommittedHeaderStore()
+
+	// Commit heights 1 and 2, but only store height 1,
+	// so height 2 uses the next validator set from height 1.
+	var headers []tmconsensus.Header
+	var proofs []tmconsensus.CommitProof
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	for range 2 {
+		fx.CommitBlock(ph.Header, []byte("app_state"), 0, fx.PrecommitProofMap(ctx, ph.Header.Height, 0, map[string][]int{
+			string(ph.Header.Hash): {0, 1, 2},
+			"":                     {3},
+		}))
+		next := fx.NextProposedHeader([]byte("data"), 0)
+
+		headers = append(headers, ph.Header)
+		proofs = append(proofs, next.Header.PrevCommitProof)
+		ph = next
+	}
+	require.NoError(t, s.SaveCommittedHeader(ctx, tmconsensus.CommittedHeader{
+		Header: headers[0],
+		Proof:  proofs[0],
+	}))
+
+	v := gcverify.NewVerifier(gcverify.VerifierConfig{
+		Store:                             s,
+		SignatureScheme:                   fx.SignatureScheme,
+		CommonMessageSignatureProofScheme: fx.CommonMessageSignatureProofScheme,
+	})
+
+	t.Run("stored height", func(t *testing.T) {
+		require.NoError(t, v.VerifyCommit(ctx, 1, headers[0].Hash, proofs[0]))
+	})
+
+	t.Run("height after stored", func(t *testing.T) {
+		require.NoError(t, v.VerifyCommit(ctx, 2, headers[1].Hash, proofs[1]))
+	})
+
+	t.Run("unknown height", func(t *testing.T) {
+		err := v.VerifyCommit(ctx, 3, []byte("whatever"), proofs[1])
+		require.ErrorIs(t, err, tmconsensus.HeightUnknownError{Want: 3})
+		require.Equal(t, gcerr.CodeNotFound, gcerr.CodeOf(err))
+	})
+
+	t.Run("wrong block hash", func(t *testing.T) {
+		err := v.VerifyCommit(ctx, 1, headers[1].Hash, proofs[0])
+		var ipe gcverify.InsufficientPowerError
+		require.ErrorAs(t, err, &ipe)
+		require.Zero(t, ipe.Have)
+	})
+
+	t.Run("wrong height", func(t *testing.T) {
+		// The signatures were made for height 1, so they are invalid for height 2.
+		err := v.VerifyCommit(ctx, 2, headers[0].Hash, tmconsensus.CommitProof{
+			Round:      proofs[0].Round,
+			PubKeyHash: proofs[0].PubKeyHash,
+			Proofs: map[string][]gcrypto.SparseSignature{
+				string(headers[0].Hash): proofs[0].Proofs[string(headers[0].Hash)],
+			},
+		})
+		require.ErrorIs(t, err, gcverify.ErrInvalidSignatures)
+	})
+
+	t.Run("insufficient power", func(t *testing.T) {
+		weak := proofs[0].Clone()
+		sigs := weak.Proofs[string(headers[0].Hash)]
+		weak.Proofs[string(headers[0].Hash)] = sigs[:2]
+
+		err := v.VerifyCommit(ctx, 1, headers[0].Hash, weak)
+		var ipe gcverify.InsufficientPowerError
+		require.ErrorAs(t, err, &ipe)
+		require.Equal(t, gcerr.CodeInvalid, gcerr.CodeOf(err))
+	})
+
+	t.Run("pub key hash mismatch", func(t *testing.T) {
+		other := proofs[0].Clone()
+		other.PubKeyHash = "not the right hash"
+
+		err := v.VerifyCommit(ctx, 1, headers[0].Hash, other)
+		require.ErrorIs(t, err, gcverify.ErrPubKeyHashMismatch)
+	})
+}
@@ -0,0 +1,171 @@
+package gcverify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+var (
+	// ErrUntrustedValidatorSet indicates a finality proof
+	// whose block was not committed by the trusted validator set.
+	ErrUntrustedValidatorSet = gcerr.New(gcerr.CodeMismatch, "header validator set does not match trusted validator set")
+
+	// ErrValidatorSetHashMismatch indicates a header
+	// whose validator set hashes do not match its validators.
+	ErrValidatorSetHashMismatch = gcerr.New(gcerr.CodeMismatch, "header validator set hashes do not match validators")
+
+	// ErrHeaderHashMismatch indicates a header whose hash does not match its contents.
+	ErrHeaderHashMismatch = gcerr.New(gcerr.CodeMismatch, "header hash does not match header contents")
+
+	// ErrNotConsecutive indicates a finality proof
+	// whose next header does not directly follow its block.
+	ErrNotConsecutive = gcerr.New(gcerr.CodeMismatch, "next header does not follow block")
+)
+
+// FinalityProof is a self-contained proof that a block is final,
+// and of the app state hash resulting from that block,
+// which can be checked offline with [VerifyFinalityProof].
+//
+// A header only commits to the app state hash of the previous height,
+// so the proof includes the committed header of the following height.
+type FinalityProof struct {
+	// The block at the proven height, with its commit proof.
+	Block tmconsensus.CommittedHeader
+
+	// The block at the following height, with its commit proof.
+	Next tmconsensus.CommittedHeader
+}
+
+// Height returns the height of the proven block.
+func (p FinalityProof) Height() uint64 {
+	return p.Block.Header.Height
+}
+
+// AppStateHash returns the app state hash resulting from the proven block.
+// It is only trustworthy once p has been verified.
+func (p FinalityProof) AppStateHash() []byte {
+	return p.Next.Header.PrevAppStateHash
+}
+
+// Schemes are the schemes the chain uses for hashing and signing,
+// needed to verify a [FinalityProof].
+type Schemes struct {
+	HashScheme                        tmconsensus.HashScheme
+	SignatureScheme                   tmconsensus.SignatureScheme
+	CommonMessageSignatureProofScheme gcrypto.CommonMessageSignatureProofScheme
+}
+
+// VerifyFinalityProof reports an error unless both headers in p hash correctly,
+// p.Block is committed by trusted,
+// and p.Next directly follows p.Block and is committed by p.Block's next validator set.
+//
+// Only the hashes of trusted are compared,
+// so a caller may retain just the hashes of the validator set it trusts.
+// Having verified p, the caller may trust p.Next.Header.NextValidatorSet
+// to verify a proof for the height after p.Next.
+func VerifyFinalityProof(p FinalityProof, trusted tmconsensus.ValidatorSet, s Schemes) error {
+	b, n := p.Block.Header, p.Next.Header
+
+	if !bytes.Equal(b.ValidatorSet.PubKeyHash, trusted.PubKeyHash) ||
+		!bytes.Equal(b.ValidatorSet.VotePowerHash, trusted.VotePowerHash) {
+		return ErrUntrustedValidatorSet
+	}
+
+	if n.Height != b.Height+1 || !bytes.Equal(n.PrevBlockHash, b.Hash) ||
+		!bytes.Equal(n.ValidatorSet.PubKeyHash, b.NextValidatorSet.PubKeyHash) ||
+		!bytes.Equal(n.ValidatorSet.VotePowerHash, b.NextValidatorSet.VotePowerHash) {
+		return ErrNotConsecutive
+	}
+
+	for _, ch := range []tmconsensus.CommittedHeader{p.Block, p.Next} {
+		if err := verifyHeader(ch.Header, s.HashScheme); err != nil {
+			return fmt.Errorf("header at height %d: %w", ch.Header.Height, err)
+		}
+		if err := CommitProof(
+			ch.Header.ValidatorSet, ch.Header.Height, ch.Header.Hash, ch.Proof,
+			s.SignatureScheme, s.CommonMessageSignatureProofScheme,
+		); err != nil {
+			return fmt.Errorf("commit at height %d: %w", ch.Header.Height, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyHeader reports an error unless h's validator set hashes
+// and block hash match its contents.
+func verifyHeader(h tmconsensus.Header, hs tmconsensus.HashScheme) error {
+	for _, vs := range []tmconsensus.ValidatorSet{h.ValidatorSet, h.NextValidatorSet} {
+		if len(vs.Validators) == 0 {
+			return ErrValidatorSetHashMismatch
+		}
+		want, err := tmconsensus.NewValidatorSet(vs.Validators, hs)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(vs.PubKeyHash, want.PubKeyHash) || !bytes.Equal(vs.VotePowerHash, want.VotePowerHash) {
+			return ErrValidatorSetHashMismatch
+		}
+	}
+
+	hash, err := hs.Block(h)
+	if err != nil {
+		return fmt.Errorf("failed to calculate block hash: %w", err)
+	}
+	if !bytes.Equal(hash, h.Hash) {
+		return ErrHeaderHashMismatch
+	}
+	return nil
+}
+
+// finalityProofJSON is the encoding of a [FinalityProof].
+// The committed headers are encoded with the chain's consensus codec;
+// the height and app state hash are informational and not verified on decode.
+type finalityProofJSON struct {
+	Height       uint64
+	AppStateHash []byte
+	Block, Next  []byte
+}
+
+// MarshalFinalityProof encodes p as JSON,
+// with its committed headers encoded by c.
+func MarshalFinalityProof(p FinalityProof, c tmcodec.Marshaler) ([]byte, error) {
+	block, err := c.MarshalCommittedHeader(p.Block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal block: %w", err)
+	}
+	next, err := c.MarshalCommittedHeader(p.Next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal next block: %w", err)
+	}
+	return json.Marshal(finalityProofJSON{
+		Height:       p.Height(),
+		AppStateHash: p.AppStateHash(),
+		Block:        block,
+		Next:         next,
+	})
+}
+
+// UnmarshalFinalityProof decodes a proof encoded by [MarshalFinalityProof].
+// The decoded proof must still be checked with [VerifyFinalityProof].
+func UnmarshalFinalityProof(b []byte, c tmcodec.Unmarshaler) (FinalityProof, error) {
+	var j finalityProofJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return FinalityProof{}, fmt.Errorf("failed to decode finality proof: %w", err)
+	}
+
+	var p FinalityProof
+	if err := c.UnmarshalCommittedHeader(j.Block, &p.Block); err != nil {
+		return FinalityProof{}, fmt.Errorf("failed to unmarshal block: %w", err)
+	}
+	if err := c.UnmarshalCommittedHeader(j.Next, &p.Next); err != nil {
+		return FinalityProof{}, fmt.Errorf("failed to unmarshal next block: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,101 @@
+package gcverify_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyFinalityProof(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+
+	// Commit heights 1 and 2, so that height 1 has a finality proof.
+	var chs []tmconsensus.CommittedHeader
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	for h := range 2 {
+		fx.CommitBlock(ph.Header, []byte{'s', byte(h + 1)}, 0, fx.PrecommitProofMap(ctx, ph.Header.Height, 0, map[string][]int{
+			string(ph.Header.Hash): {0, 1, 2},
+			"":                     {3},
+		}))
+		next := fx.NextProposedHeader([]byte("data"), 0)
+		chs = append(chs, tmconsensus.CommittedHeader{
+			Header: ph.Header,
+			Proof:  next.Header.PrevCommitProof,
+		})
+		ph = next
+	}
+
+	p := gcverify.FinalityProof{Block: chs[0], Next: chs[1]}
+	s := gcverify.Schemes{
+		HashScheme:                        fx.HashScheme,
+		SignatureScheme:                   fx.SignatureScheme,
+		CommonMessageSignatureProofScheme: fx.CommonMessageSignatureProofScheme,
+	}
+	trusted := fx.ValSet()
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, gcverify.VerifyFinalityProof(p, trusted, s))
+		require.Equal(t, uint64(1), p.Height())
+		require.Equal(t, []byte{'s', 1}, p.AppStateHash())
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		var reg gcrypto.Registry
+		gcrypto.RegisterEd25519(&reg)
+		codec := tmjson.MarshalCodec{CryptoRegistry: &reg}
+
+		b, err := gcverify.MarshalFinalityProof(p, codec)
+		require.NoError(t, err)
+		got, err := gcverify.UnmarshalFinalityProof(b, codec)
+		require.NoError(t, err)
+		require.NoError(t, gcverify.VerifyFinalityProof(got, trusted, s))
+		require.Equal(t, p.AppStateHash(), got.AppStateHash())
+	})
+
+	t.Run("untrusted validator set", func(t *testing.T) {
+		other := trusted
+		other.VotePowerHash = []byte("other")
+		err := gcverify.VerifyFinalityProof(p, other, s)
+		require.ErrorIs(t, err, gcverify.ErrUntrustedValidatorSet)
+		require.Equal(t, gcerr.CodeMismatch, gcerr.CodeOf(err))
+	})
+
+	t.Run("not consecutive", func(t *testing.T) {
+		err := gcverify.VerifyFinalityProof(gcverify.FinalityProof{Block: chs[0], Next: chs[0]}, trusted, s)
+		require.ErrorIs(t, err, gcverify.ErrNotConsecutive)
+	})
+
+	t.Run("forged app state hash", func(t *testing.T) {
+		forged := p
+		forged.Next.Header.PrevAppStateHash = []byte("forged")
+		err := gcverify.VerifyFinalityProof(forged, trusted, s)
+		require.ErrorIs(t, err, gcverify.ErrHeaderHashMismatch)
+
+		// Rehashing the forged header invalidates its commit instead.
+		fx.RecalculateHash(&forged.Next.Header)
+		err = gcverify.VerifyFinalityProof(forged, trusted, s)
+		require.Error(t, err)
+	})
+
+	t.Run("insufficient power", func(t *testing.T) {
+		weak := p
+		weak.Next.Proof = p.Next.Proof.Clone()
+		sigs := weak.Next.Proof.Proofs[string(p.Next.Header.Hash)]
+		weak.Next.Proof.Proofs[string(p.Next.Header.Hash)] = sigs[:2]
+
+		err := gcverify.VerifyFinalityProof(weak, trusted, s)
+		var ipe gcverify.InsufficientPowerError
+		require.ErrorAs(t, err, &ipe)
+	})
+}
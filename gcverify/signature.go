@@ -0,0 +1,134 @@
+package gcverify
+
+import (
+	"github.com/bits-and-blooms/bitset"
+	"github.com/gordian-engine/gordian/gcrypto"
+)
+
+// SignatureVerifier checks individual signatures.
+//
+// Implementations may offload the work,
+// for instance to a hardware accelerator or a remote verification service.
+// Verify may be called concurrently.
+// If an implementation cannot reach its backend,
+// it should fall back to [PureGoSignatureVerifier]
+// rather than report valid signatures as invalid.
+type SignatureVerifier interface {
+	// Verify reports whether sig is a valid signature of msg by key.
+	Verify(key gcrypto.PubKey, msg, sig []byte) bool
+}
+
+// PureGoSignatureVerifier is the default [SignatureVerifier],
+// which calls the key's own Verify method.
+type PureGoSignatureVerifier struct{}
+
+func (PureGoSignatureVerifier) Verify(key gcrypto.PubKey, msg, sig []byte) bool {
+	return key.Verify(msg, sig)
+}
+
+// ProofScheme returns a proof scheme that behaves like
+// [gcrypto.SimpleCommonMessageSignatureProofScheme],
+// but checks every signature added to its proofs through v.
+//
+// Proofs from the returned scheme must only be merged with one another,
+// not with proofs from a different scheme.
+// Their sparse representation is identical to that of the simple scheme,
+// so the two interoperate over the network and in stores.
+func ProofScheme(v SignatureVerifier) gcrypto.CommonMessageSignatureProofScheme {
+	return proofScheme{v: v}
+}
+
+type proofScheme struct {
+	v SignatureVerifier
+}
+
+func (s proofScheme) New(
+	msg []byte, candidateKeys []gcrypto.PubKey, pubKeyHash string,
+) (gcrypto.CommonMessageSignatureProof, error) {
+	keys := make([]gcrypto.PubKey, len(candidateKeys))
+	for i, k := range candidateKeys {
+		keys[i] = s.wrap(k)
+	}
+
+	p, err := gcrypto.NewSimpleCommonMessageSignatureProof(msg, keys, pubKeyHash)
+	if err != nil {
+		return nil, err
+	}
+	return proof{inner: p, s: s}, nil
+}
+
+func (s proofScheme) IsValidKeyID(id []byte, keys []gcrypto.PubKey) bool {
+	return gcrypto.SimpleCommonMessageSignatureProofScheme.IsValidKeyID(id, keys)
+}
+
+func (s proofScheme) wrap(k gcrypto.PubKey) gcrypto.PubKey {
+	if vk, ok := k.(verifyingKey); ok {
+		return vk
+	}
+	return verifyingKey{PubKey: k, v: s.v}
+}
+
+// verifyingKey is a public key whose Verify method defers to a SignatureVerifier.
+type verifyingKey struct {
+	gcrypto.PubKey
+
+	v SignatureVerifier
+}
+
+func (k verifyingKey) Verify(msg, sig []byte) bool {
+	return k.v.Verify(k.PubKey, msg, sig)
+}
+
+func (k verifyingKey) Equal(other gcrypto.PubKey) bool {
+	if o, ok := other.(verifyingKey); ok {
+		other = o.PubKey
+	}
+	return k.PubKey.Equal(other)
+}
+
+// proof wraps a simple proof, so that every key it holds is a verifyingKey,
+// including keys passed directly to AddSignature.
+type proof struct {
+	inner gcrypto.CommonMessageSignatureProof
+
+	s proofScheme
+}
+
+func (p proof) Message() []byte    { return p.inner.Message() }
+func (p proof) PubKeyHash() []byte { return p.inner.PubKeyHash() }
+
+func (p proof) AddSignature(sig []byte, key gcrypto.PubKey) error {
+	return p.inner.AddSignature(sig, p.s.wrap(key))
+}
+
+func (p proof) Matches(other gcrypto.CommonMessageSignatureProof) bool {
+	o, ok := other.(proof)
+	if !ok {
+		return false
+	}
+	return p.inner.Matches(o.inner)
+}
+
+func (p proof) Merge(other gcrypto.CommonMessageSignatureProof) gcrypto.SignatureProofMergeResult {
+	return p.inner.Merge(other.(proof).inner)
+}
+
+func (p proof) MergeSparse(s gcrypto.SparseSignatureProof) gcrypto.SignatureProofMergeResult {
+	return p.inner.MergeSparse(s)
+}
+
+func (p proof) HasSparseKeyID(keyID []byte) (has, valid bool) {
+	return p.inner.HasSparseKeyID(keyID)
+}
+
+func (p proof) Clone() gcrypto.CommonMessageSignatureProof {
+	return proof{inner: p.inner.Clone(), s: p.s}
+}
+
+func (p proof) Derive() gcrypto.CommonMessageSignatureProof {
+	return proof{inner: p.inner.Derive(), s: p.s}
+}
+
+func (p proof) SignatureBitSet() *bitset.BitSet { return p.inner.SignatureBitSet() }
+
+func (p proof) AsSparse() gcrypto.SparseSignatureProof { return p.inner.AsSparse() }
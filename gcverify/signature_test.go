@@ -0,0 +1,102 @@
+package gcverify_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+// countingVerifier counts its calls,
+// and reports every signature as valid only if ok is set.
+type countingVerifier struct {
+	n  atomic.Int32
+	ok bool
+}
+
+func (v *countingVerifier) Verify(key gcrypto.PubKey, msg, sig []byte) bool {
+	v.n.Add(1)
+	return v.ok && key.Verify(msg, sig)
+}
+
+func TestProofScheme(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	fx.CommitBlock(ph.Header, []byte("app_state"), 0, fx.PrecommitProofMap(ctx, 1, 0, map[string][]int{
+		string(ph.Header.Hash): {0, 1, 2},
+	}))
+	proof := fx.NextProposedHeader([]byte("data"), 0).Header.PrevCommitProof
+	vals := ph.Header.ValidatorSet
+
+	t.Run("valid signatures", func(t *testing.T) {
+		v := &countingVerifier{ok: true}
+		require.NoError(t, gcverify.CommitProof(
+			vals, 1, ph.Header.Hash, proof, fx.SignatureScheme, gcverify.ProofScheme(v),
+		))
+		require.Equal(t, int32(3), v.n.Load())
+	})
+
+	t.Run("verifier rejects", func(t *testing.T) {
+		v := &countingVerifier{ok: false}
+		err := gcverify.CommitProof(
+			vals, 1, ph.Header.Hash, proof, fx.SignatureScheme, gcverify.ProofScheme(v),
+		)
+		require.ErrorIs(t, err, gcverify.ErrInvalidSignatures)
+		require.Positive(t, v.n.Load())
+	})
+
+	t.Run("merge and add", func(t *testing.T) {
+		v := &countingVerifier{ok: true}
+		s := gcverify.ProofScheme(v)
+
+		msg, err := tmconsensus.PrecommitSignBytes(tmconsensus.VoteTarget{
+			Height: 1, BlockHash: string(ph.Header.Hash),
+		}, fx.SignatureScheme)
+		require.NoError(t, err)
+
+		keys := tmconsensus.ValidatorsToPubKeys(vals.Validators)
+		a, err := s.New(msg, keys, string(vals.PubKeyHash))
+		require.NoError(t, err)
+		b := a.Derive()
+
+		// Keys passed directly, as the engine does for its own signature,
+		// are interchangeable with the candidate keys.
+		sig, err := fx.PrivVals[0].Signer.Sign(ctx, msg)
+		require.NoError(t, err)
+		require.NoError(t, a.AddSignature(sig, fx.PrivVals[0].CVal.PubKey))
+
+		res := b.MergeSparse(gcrypto.SparseSignatureProof{
+			PubKeyHash: proof.PubKeyHash,
+			Signatures: proof.Proofs[string(ph.Header.Hash)],
+		})
+		require.True(t, res.AllValidSignatures)
+
+		require.True(t, a.Matches(b))
+		res = b.Merge(a)
+		require.True(t, res.AllValidSignatures)
+		require.Equal(t, uint(3), b.SignatureBitSet().Count())
+
+		res = a.Merge(b.Clone())
+		require.True(t, res.AllValidSignatures)
+		require.True(t, res.IncreasedSignatures)
+
+		// The sparse form is the same as the simple scheme's.
+		simple, err := gcrypto.SimpleCommonMessageSignatureProofScheme.New(msg, keys, string(vals.PubKeyHash))
+		require.NoError(t, err)
+		simple.MergeSparse(a.AsSparse())
+		require.Equal(t, a.AsSparse(), simple.AsSparse())
+
+		// One signature was added directly, three merged sparsely,
+		// and two were new to a in the final merge.
+		require.Equal(t, int32(6), v.n.Load())
+	})
+}
@@ -0,0 +1,102 @@
+// Package gcwasm runs a gordian application as a WebAssembly module,
+// in place of an app compiled into the node.
+//
+// A [Runner] answers the engine's init chain and finalize block requests
+// by calling exports of the module, so that the application
+// can be sandboxed, and upgraded by scheduling a new module at a height
+// rather than by recompiling and restarting every node.
+//
+// The package does not embed a WebAssembly runtime.
+// The caller supplies one through the [Runtime] interface,
+// configured for deterministic execution:
+// every validator must compute the same results from the same inputs,
+// so the runtime must not expose clocks, randomness, or other host state to the module,
+// and must bound execution identically on every node.
+//
+// # Module interface
+//
+// Every export takes and returns a single byte slice;
+// how the bytes cross into the module's memory is up to the runtime.
+// The module must export:
+//
+//   - [ExportInitChain], taking an [InitChainInput] and returning a [ResultOutput].
+//   - [ExportFinalizeBlock], taking a [FinalizeBlockInput] and returning a [ResultOutput].
+//
+// A module replacing another at an upgrade height must also export [ExportRestore],
+// taking the output of the previous module's [ExportSnapshot].
+// Inputs and outputs are JSON.
+package gcwasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// Names of the exports a module provides, per the package documentation.
+const (
+	ExportInitChain     = "gordian_init_chain"
+	ExportFinalizeBlock = "gordian_finalize_block"
+	ExportSnapshot      = "gordian_snapshot"
+	ExportRestore       = "gordian_restore"
+)
+
+// Runtime instantiates WebAssembly modules.
+type Runtime interface {
+	// Instantiate compiles and instantiates the module.
+	Instantiate(ctx context.Context, m Module) (Instance, error)
+}
+
+// Instance is an instantiated module.
+// A [Runner] calls an Instance from a single goroutine.
+type Instance interface {
+	// Call calls the named export with input and returns its output.
+	// An error from the module itself, such as a trap, is returned as an error.
+	Call(ctx context.Context, export string, input []byte) ([]byte, error)
+
+	// Close releases the instance.
+	Close(ctx context.Context) error
+}
+
+// Module is the compiled form of a WebAssembly module.
+type Module struct {
+	// The module binary.
+	Code []byte
+
+	// SHA-256 hash of Code.
+	Hash []byte
+}
+
+// NewModule returns a Module for code.
+func NewModule(code []byte) Module {
+	h := sha256.Sum256(code)
+	return Module{Code: code, Hash: h[:]}
+}
+
+// LoadModule reads the module binary at path.
+// If wantHash is not empty, it must be the hex-encoded SHA-256 hash of the file,
+// so that every validator is sure to run the same module.
+func LoadModule(path, wantHash string) (Module, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return Module{}, fmt.Errorf("failed to read module: %w", err)
+	}
+
+	m := NewModule(code)
+	if wantHash != "" {
+		want, err := hex.DecodeString(wantHash)
+		if err != nil {
+			return Module{}, fmt.Errorf("invalid module hash %q: %w", wantHash, err)
+		}
+		if !bytes.Equal(want, m.Hash) {
+			return Module{}, fmt.Errorf(
+				"module %s has hash %x; expected %x", path, m.Hash, want,
+			)
+		}
+	}
+
+	return m, nil
+}
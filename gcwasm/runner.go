@@ -0,0 +1,407 @@
+package gcwasm
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/glog"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmdriver"
+)
+
+// Validator is a validator as passed to and from a module.
+type Validator struct {
+	// Encoded through the runner's registry.
+	PubKey []byte
+
+	Power uint64
+}
+
+// InitChainInput is the input to [ExportInitChain].
+type InitChainInput struct {
+	ChainID       string
+	InitialHeight uint64
+
+	// The app state from the genesis file, in the application's own format.
+	AppState []byte
+
+	Validators []Validator
+}
+
+// FinalizeBlockInput is the input to [ExportFinalizeBlock].
+type FinalizeBlockInput struct {
+	Height    uint64
+	Round     uint32
+	BlockHash []byte
+
+	PrevAppStateHash []byte
+
+	// The block's data ID, and its data if the runner has a BlockData source.
+	DataID []byte
+	Data   []byte `json:",omitempty"`
+
+	// The validators of the block, and those already set for the next block.
+	Validators     []Validator
+	NextValidators []Validator
+}
+
+// ResultOutput is the output of [ExportInitChain] and [ExportFinalizeBlock].
+type ResultOutput struct {
+	AppStateHash []byte
+
+	// The validators resulting from the call.
+	// If omitted, the validators are unchanged:
+	// the genesis validators for init chain,
+	// or the block's next validators for finalize block.
+	Validators []Validator `json:",omitempty"`
+}
+
+// Upgrade replaces the running module from Height onward.
+//
+// Before finalizing Height, the runner passes the snapshot of the previous module
+// to the new module's restore export.
+// Module state outside the snapshot, such as state the runtime persists on the module's behalf,
+// is the runtime's concern; after a restart past Height,
+// the caller may configure the new module directly and drop the upgrade.
+type Upgrade struct {
+	// The first height finalized by Module.
+	Height uint64
+
+	Module Module
+}
+
+// RunnerConfig is the configuration for [NewRunner].
+type RunnerConfig struct {
+	Runtime Runtime
+
+	// The module to run before the first upgrade.
+	Module Module
+
+	// Scheduled module upgrades.
+	// They must be identical on every validator.
+	Upgrades []Upgrade
+
+	// Used to encode and decode validator public keys.
+	Registry *gcrypto.Registry
+
+	InitChainRequests     <-chan tmdriver.InitChainRequest
+	FinalizeBlockRequests <-chan tmdriver.FinalizeBlockRequest
+
+	// Optional source of a block's data, passed to the module on finalization.
+	BlockData func(context.Context, tmconsensus.Header) ([]byte, error)
+}
+
+// Runner runs an application module per the package documentation.
+type Runner struct {
+	log *slog.Logger
+
+	rt        Runtime
+	reg       *gcrypto.Registry
+	blockData func(context.Context, tmconsensus.Header) ([]byte, error)
+
+	// Pending upgrades, in increasing height order.
+	upgrades []Upgrade
+
+	mu         sync.Mutex
+	moduleHash []byte
+	err        error
+
+	done chan struct{}
+}
+
+// NewRunner instantiates cfg.Module and returns a Runner
+// handling requests until ctx is canceled or the module fails.
+func NewRunner(ctx context.Context, log *slog.Logger, cfg RunnerConfig) (*Runner, error) {
+	upgrades := slices.Clone(cfg.Upgrades)
+	slices.SortFunc(upgrades, func(a, b Upgrade) int { return cmp.Compare(a.Height, b.Height) })
+	for i := 1; i < len(upgrades); i++ {
+		if upgrades[i].Height == upgrades[i-1].Height {
+			return nil, fmt.Errorf("multiple module upgrades at height %d", upgrades[i].Height)
+		}
+	}
+
+	inst, err := cfg.Runtime.Instantiate(ctx, cfg.Module)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate module %x: %w", cfg.Module.Hash, err)
+	}
+
+	r := &Runner{
+		log: log,
+
+		rt:        cfg.Runtime,
+		reg:       cfg.Registry,
+		blockData: cfg.BlockData,
+
+		upgrades: upgrades,
+
+		moduleHash: cfg.Module.Hash,
+
+		done: make(chan struct{}),
+	}
+
+	go r.kernel(ctx, inst, cfg.InitChainRequests, cfg.FinalizeBlockRequests)
+
+	return r, nil
+}
+
+// Wait blocks until r has stopped.
+func (r *Runner) Wait() {
+	<-r.done
+}
+
+// Done returns a channel that is closed once r has stopped.
+func (r *Runner) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err returns the error the runner stopped with, if any.
+func (r *Runner) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ModuleHash returns the hash of the module currently running.
+func (r *Runner) ModuleHash() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.moduleHash
+}
+
+func (r *Runner) kernel(
+	ctx context.Context,
+	inst Instance,
+	initChainRequests <-chan tmdriver.InitChainRequest,
+	finalizeBlockRequests <-chan tmdriver.FinalizeBlockRequest,
+) {
+	defer close(r.done)
+	defer func() {
+		// The context may be canceled already.
+		if err := inst.Close(context.WithoutCancel(ctx)); err != nil {
+			r.log.Warn("Failed to close module instance", "err", err)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case req := <-initChainRequests:
+			resp, err := r.initChain(ctx, inst, req)
+			if err != nil {
+				r.fail(err)
+				return
+			}
+			select {
+			case req.Resp <- resp:
+				// Okay.
+			case <-ctx.Done():
+				return
+			}
+
+		case req := <-finalizeBlockRequests:
+			var err error
+			inst, err = r.maybeUpgrade(ctx, inst, req.Header.Height)
+			if err != nil {
+				r.fail(err)
+				return
+			}
+
+			resp, err := r.finalizeBlock(ctx, inst, req)
+			if err != nil {
+				r.fail(err)
+				return
+			}
+
+			// The response channel is guaranteed to be 1-buffered.
+			req.Resp <- resp
+		}
+	}
+}
+
+// fail records err as the reason the runner stopped.
+// The request being handled is left unanswered,
+// as the engine cannot continue without a result from the app.
+func (r *Runner) fail(err error) {
+	r.log.Error("Stopping after module failure", "err", err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func (r *Runner) initChain(
+	ctx context.Context, inst Instance, req tmdriver.InitChainRequest,
+) (tmdriver.InitChainResponse, error) {
+	g := req.Genesis
+	in := InitChainInput{
+		ChainID:       g.ChainID,
+		InitialHeight: g.InitialHeight,
+		Validators:    r.encodeValidators(g.GenesisValidatorSet.Validators),
+	}
+	if g.InitialAppState != nil {
+		b, err := io.ReadAll(g.InitialAppState)
+		if err != nil {
+			return tmdriver.InitChainResponse{}, fmt.Errorf("failed to read initial app state: %w", err)
+		}
+		in.AppState = b
+	}
+
+	out, err := r.call(ctx, inst, ExportInitChain, in)
+	if err != nil {
+		return tmdriver.InitChainResponse{}, err
+	}
+
+	// Nil validators tell the engine to use the genesis validators.
+	vals, err := r.decodeValidators(out.Validators)
+	if err != nil {
+		return tmdriver.InitChainResponse{}, err
+	}
+	return tmdriver.InitChainResponse{
+		AppStateHash: out.AppStateHash,
+		Validators:   vals,
+	}, nil
+}
+
+func (r *Runner) finalizeBlock(
+	ctx context.Context, inst Instance, req tmdriver.FinalizeBlockRequest,
+) (tmdriver.FinalizeBlockResponse, error) {
+	h := req.Header
+	in := FinalizeBlockInput{
+		Height:    h.Height,
+		Round:     req.Round,
+		BlockHash: h.Hash,
+
+		PrevAppStateHash: h.PrevAppStateHash,
+
+		DataID: h.DataID,
+
+		Validators:     r.encodeValidators(h.ValidatorSet.Validators),
+		NextValidators: r.encodeValidators(h.NextValidatorSet.Validators),
+	}
+	if r.blockData != nil {
+		data, err := r.blockData(ctx, h)
+		if err != nil {
+			return tmdriver.FinalizeBlockResponse{}, fmt.Errorf(
+				"failed to load block data at height %d: %w", h.Height, err,
+			)
+		}
+		in.Data = data
+	}
+
+	out, err := r.call(ctx, inst, ExportFinalizeBlock, in)
+	if err != nil {
+		return tmdriver.FinalizeBlockResponse{}, fmt.Errorf("failed to finalize height %d: %w", h.Height, err)
+	}
+
+	vals := h.NextValidatorSet.Validators
+	if out.Validators != nil {
+		vals, err = r.decodeValidators(out.Validators)
+		if err != nil {
+			return tmdriver.FinalizeBlockResponse{}, err
+		}
+	}
+
+	return tmdriver.FinalizeBlockResponse{
+		Height:    h.Height,
+		Round:     req.Round,
+		BlockHash: h.Hash,
+
+		Validators: slices.Clone(vals),
+
+		AppStateHash: out.AppStateHash,
+	}, nil
+}
+
+// maybeUpgrade returns the instance to finalize height with,
+// replacing inst if an upgrade applies at or before height.
+func (r *Runner) maybeUpgrade(ctx context.Context, inst Instance, height uint64) (Instance, error) {
+	for len(r.upgrades) > 0 && r.upgrades[0].Height <= height {
+		u := r.upgrades[0]
+
+		snapshot, err := inst.Call(ctx, ExportSnapshot, nil)
+		if err != nil {
+			return inst, fmt.Errorf("failed to snapshot module state before upgrade at height %d: %w", u.Height, err)
+		}
+
+		next, err := r.rt.Instantiate(ctx, u.Module)
+		if err != nil {
+			return inst, fmt.Errorf("failed to instantiate module %x for upgrade at height %d: %w", u.Module.Hash, u.Height, err)
+		}
+		if _, err := next.Call(ctx, ExportRestore, snapshot); err != nil {
+			_ = next.Close(ctx)
+			return inst, fmt.Errorf("failed to restore module state for upgrade at height %d: %w", u.Height, err)
+		}
+
+		if err := inst.Close(ctx); err != nil {
+			r.log.Warn("Failed to close replaced module instance", "err", err)
+		}
+		inst = next
+		r.upgrades = r.upgrades[1:]
+
+		r.mu.Lock()
+		r.moduleHash = u.Module.Hash
+		r.mu.Unlock()
+
+		r.log.Info(
+			"Upgraded app module",
+			"height", height, "scheduled_height", u.Height, "module_hash", glog.Hex(u.Module.Hash),
+		)
+	}
+	return inst, nil
+}
+
+func (r *Runner) call(ctx context.Context, inst Instance, export string, in any) (ResultOutput, error) {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return ResultOutput{}, fmt.Errorf("failed to encode input for %s: %w", export, err)
+	}
+
+	outb, err := inst.Call(ctx, export, b)
+	if err != nil {
+		return ResultOutput{}, fmt.Errorf("module call %s failed: %w", export, err)
+	}
+
+	var out ResultOutput
+	if err := json.Unmarshal(outb, &out); err != nil {
+		return ResultOutput{}, fmt.Errorf("failed to decode output of %s: %w", export, err)
+	}
+	if len(out.AppStateHash) == 0 {
+		return ResultOutput{}, errors.New("module returned an empty app state hash from " + export)
+	}
+	return out, nil
+}
+
+func (r *Runner) encodeValidators(vals []tmconsensus.Validator) []Validator {
+	out := make([]Validator, len(vals))
+	for i, v := range vals {
+		out[i] = Validator{PubKey: r.reg.Marshal(v.PubKey), Power: v.Power}
+	}
+	return out
+}
+
+func (r *Runner) decodeValidators(vals []Validator) ([]tmconsensus.Validator, error) {
+	if vals == nil {
+		return nil, nil
+	}
+
+	out := make([]tmconsensus.Validator, len(vals))
+	for i, v := range vals {
+		pk, err := r.reg.Unmarshal(v.PubKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode public key of validator %d from module: %w", i, err)
+		}
+		out[i] = tmconsensus.Validator{PubKey: pk, Power: v.Power}
+	}
+	return out, nil
+}
@@ -0,0 +1,198 @@
+package gcwasm_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcwasm"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmdriver"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRuntime runs "modules" whose code is a version string.
+// Each instance hashes its version and every block it finalizes into its state,
+// and version "bad" fails every call.
+type fakeRuntime struct{}
+
+func (fakeRuntime) Instantiate(_ context.Context, m gcwasm.Module) (gcwasm.Instance, error) {
+	return &fakeInstance{version: string(m.Code)}, nil
+}
+
+type fakeInstance struct {
+	version string
+	state   []byte
+	closed  bool
+}
+
+func (i *fakeInstance) Call(_ context.Context, export string, input []byte) ([]byte, error) {
+	if i.closed {
+		return nil, errors.New("instance closed")
+	}
+	if i.version == "bad" {
+		return nil, errors.New("trap")
+	}
+
+	switch export {
+	case gcwasm.ExportSnapshot:
+		return i.state, nil
+	case gcwasm.ExportRestore:
+		i.state = input
+		return nil, nil
+	case gcwasm.ExportInitChain:
+		var in gcwasm.InitChainInput
+		if err := json.Unmarshal(input, &in); err != nil {
+			return nil, err
+		}
+		i.state = in.AppState
+		return json.Marshal(gcwasm.ResultOutput{AppStateHash: i.hash()})
+	case gcwasm.ExportFinalizeBlock:
+		var in gcwasm.FinalizeBlockInput
+		if err := json.Unmarshal(input, &in); err != nil {
+			return nil, err
+		}
+		i.state = append(append(i.state, i.version...), in.BlockHash...)
+		// Drop the last validator, to show that returned validators apply.
+		return json.Marshal(gcwasm.ResultOutput{
+			AppStateHash: i.hash(),
+			Validators:   in.NextValidators[:len(in.NextValidators)-1],
+		})
+	}
+	return nil, errors.New("unknown export " + export)
+}
+
+func (i *fakeInstance) hash() []byte {
+	h := sha256.Sum256(i.state)
+	return h[:]
+}
+
+func (i *fakeInstance) Close(context.Context) error {
+	i.closed = true
+	return nil
+}
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+
+	initCh := make(chan tmdriver.InitChainRequest)
+	finCh := make(chan tmdriver.FinalizeBlockRequest)
+
+	v2 := gcwasm.NewModule([]byte("v2"))
+	r, err := gcwasm.NewRunner(ctx, gtest.NewLogger(t), gcwasm.RunnerConfig{
+		Runtime:  fakeRuntime{},
+		Module:   gcwasm.NewModule([]byte("v1")),
+		Upgrades: []gcwasm.Upgrade{{Height: 2, Module: v2}},
+		Registry: &reg,
+
+		InitChainRequests:     initCh,
+		FinalizeBlockRequests: finCh,
+	})
+	require.NoError(t, err)
+	defer r.Wait()
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(3)
+
+	initResp := make(chan tmdriver.InitChainResponse, 1)
+	initCh <- tmdriver.InitChainRequest{
+		Genesis: tmconsensus.ExternalGenesis{
+			ChainID:             "test",
+			InitialHeight:       1,
+			InitialAppState:     strings.NewReader("genesis"),
+			GenesisValidatorSet: fx.ValSet(),
+		},
+		Resp: initResp,
+	}
+	ir := gtest.ReceiveSoon(t, initResp)
+	h := sha256.Sum256([]byte("genesis"))
+	require.Equal(t, h[:], ir.AppStateHash)
+	require.Nil(t, ir.Validators)
+
+	state := []byte("genesis")
+	for height, version := range []string{"v1", "v2"} {
+		ph := fx.NextProposedHeader([]byte("data"), 0)
+		finResp := make(chan tmdriver.FinalizeBlockResponse, 1)
+		finCh <- tmdriver.FinalizeBlockRequest{Header: ph.Header, Round: 0, Resp: finResp}
+		fr := gtest.ReceiveSoon(t, finResp)
+
+		// The upgraded module continues from the previous module's state.
+		state = append(append(state, version...), ph.Header.Hash...)
+		h := sha256.Sum256(state)
+		require.Equal(t, uint64(height+1), fr.Height)
+		require.Equal(t, h[:], fr.AppStateHash)
+		require.Len(t, fr.Validators, len(ph.Header.NextValidatorSet.Validators)-1)
+		require.True(t, fr.Validators[0].PubKey.Equal(ph.Header.NextValidatorSet.Validators[0].PubKey))
+
+		fx.CommitBlock(ph.Header, fr.AppStateHash, 0, fx.PrecommitProofMap(ctx, ph.Header.Height, 0, map[string][]int{
+			string(ph.Header.Hash): {0, 1, 2},
+		}))
+	}
+	require.Equal(t, v2.Hash, r.ModuleHash())
+	require.NoError(t, r.Err())
+}
+
+func TestRunner_moduleFailure(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+
+	finCh := make(chan tmdriver.FinalizeBlockRequest)
+	r, err := gcwasm.NewRunner(ctx, gtest.NewLogger(t), gcwasm.RunnerConfig{
+		Runtime:  fakeRuntime{},
+		Module:   gcwasm.NewModule([]byte("bad")),
+		Registry: &reg,
+
+		FinalizeBlockRequests: finCh,
+	})
+	require.NoError(t, err)
+
+	fx := tmconsensustest.NewStandardFixture(2)
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	finCh <- tmdriver.FinalizeBlockRequest{
+		Header: ph.Header,
+		Resp:   make(chan tmdriver.FinalizeBlockResponse, 1),
+	}
+
+	// The runner stops without responding.
+	gtest.ReceiveSoon(t, r.Done())
+	require.ErrorContains(t, r.Err(), "trap")
+}
+
+func TestLoadModule(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "app.wasm")
+	require.NoError(t, os.WriteFile(path, []byte("code"), 0o600))
+	h := sha256.Sum256([]byte("code"))
+
+	m, err := gcwasm.LoadModule(path, hex.EncodeToString(h[:]))
+	require.NoError(t, err)
+	require.Equal(t, []byte("code"), m.Code)
+	require.Equal(t, h[:], m.Hash)
+
+	_, err = gcwasm.LoadModule(path, hex.EncodeToString(make([]byte, 32)))
+	require.ErrorContains(t, err, "expected")
+
+	_, err = gcwasm.LoadModule(path, "not hex")
+	require.Error(t, err)
+}
@@ -3,9 +3,13 @@ module github.com/gordian-engine/gcosmos
 go 1.23.2
 
 require (
+	filippo.io/edwards25519 v1.1.0
 	github.com/cosmos/gogoproto v1.7.0
+	github.com/jackc/pgx/v5 v5.7.1
 	github.com/jhump/protoreflect v1.16.0
 	github.com/libp2p/go-libp2p v0.35.0
+	github.com/miekg/pkcs11 v1.1.2
+	golang.org/x/crypto v0.27.0
 	google.golang.org/protobuf v1.34.2
 )
 
@@ -36,6 +40,9 @@ require (
 	github.com/ipfs/go-log v1.0.5 // indirect
 	github.com/ipfs/go-log/v2 v2.5.1 // indirect
 	github.com/ipld/go-ipld-prime v0.21.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/jbenet/goprocess v0.1.4 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.7 // indirect
 	github.com/libp2p/go-buffer-pool v0.1.0 // indirect
@@ -84,10 +91,11 @@ require (
 	go.opentelemetry.io/otel/trace v1.28.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
-	golang.org/x/crypto v0.27.0 // indirect
 	golang.org/x/exp v0.0.0-20240531132922-fd00a4e0eefc // indirect
 	golang.org/x/net v0.29.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/text v0.18.0 // indirect
 	gonum.org/v1/gonum v0.15.1 // indirect
 	lukechampine.com/blake3 v1.2.2 // indirect
 	modernc.org/gc/v3 v3.0.0-20240107210532-573471604cb6 // indirect
@@ -1,14 +1,40 @@
 package gserver
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	banktypes "cosmossdk.io/x/bank/types"
+	cmted25519 "github.com/cometbft/cometbft/crypto/ed25519"
 	"github.com/cometbft/cometbft/privval"
 	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	clienttx "github.com/cosmos/cosmos-sdk/client/tx"
 	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authclient "github.com/cosmos/cosmos-sdk/x/auth/client"
+	"github.com/cosmos/go-bip39"
+	"github.com/gordian-engine/gcosmos/gcclient"
+	"github.com/gordian-engine/gcosmos/gccodec"
+	"github.com/gordian-engine/gcosmos/gccrypto/gckeyderiv"
+	"github.com/gordian-engine/gcosmos/gcload"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaction"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
 	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p"
 	"github.com/libp2p/go-libp2p"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
@@ -103,3 +129,715 @@ func newPrintValPubKeyCommand() *cobra.Command {
 		},
 	}
 }
+
+const (
+	// Address of the node's HTTP server, for commands that talk to a running node.
+	gordianAddrFlag = "gordian-addr"
+
+	broadcastWaitFlag        = "wait"
+	broadcastWaitTimeoutFlag = "wait-timeout"
+)
+
+func newBroadcastTxCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast-tx [unsigned-tx-file]",
+		Short: "Sign a transaction created with --generate-only and submit it to a Gordian node's HTTP server",
+		Long: `Sign a transaction created with --generate-only and submit it to a Gordian node's HTTP server.
+
+Gordian does not serve the account queries used for online signing,
+so the --account-number and --sequence flags must be set.
+
+With --wait, the command blocks until the node no longer reports the transaction as pending,
+which normally means it was included in a committed block.`,
+		Args: cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to get client context: %w", err)
+			}
+
+			txf, err := clienttx.NewFactoryCLI(clientCtx, cmd.Flags())
+			if err != nil {
+				return fmt.Errorf("failed to build transaction factory: %w", err)
+			}
+
+			stdTx, err := authclient.ReadTxFromFile(clientCtx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read transaction from %q: %w", args[0], err)
+			}
+			txBuilder, err := clientCtx.TxConfig.WrapTxBuilder(stdTx)
+			if err != nil {
+				return fmt.Errorf("failed to wrap transaction: %w", err)
+			}
+
+			// Always sign offline, as there is nowhere to look up the account.
+			if err := authclient.SignTx(txf, clientCtx, clientCtx.FromName, txBuilder, true, true); err != nil {
+				return fmt.Errorf("failed to sign transaction: %w", err)
+			}
+
+			txJSON, err := clientCtx.TxConfig.TxJSONEncoder()(txBuilder.GetTx())
+			if err != nil {
+				return fmt.Errorf("failed to encode signed transaction: %w", err)
+			}
+
+			baseURL, err := gordianBaseURL(cmd)
+			if err != nil {
+				return err
+			}
+
+			resp, err := http.Post(baseURL+"/debug/submit_tx", "application/json", bytes.NewReader(txJSON))
+			if err != nil {
+				return fmt.Errorf("failed to submit transaction: %w", err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return fmt.Errorf("failed to read submit response: %w", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf(
+					"node rejected transaction (status %d): %s",
+					resp.StatusCode, strings.TrimSpace(string(body)),
+				)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(string(body)))
+
+			wait, err := cmd.Flags().GetBool(broadcastWaitFlag)
+			if err != nil {
+				return err
+			}
+			if !wait {
+				return nil
+			}
+
+			waitTimeout, err := cmd.Flags().GetDuration(broadcastWaitTimeoutFlag)
+			if err != nil {
+				return err
+			}
+
+			// Decode the transaction the same way the node does,
+			// so that we look for the same hash.
+			tx, err := gccodec.NewTxDecoder(clientCtx.TxConfig).DecodeJSON(txJSON)
+			if err != nil {
+				return fmt.Errorf("failed to decode signed transaction: %w", err)
+			}
+			hash := tx.Hash()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), waitTimeout)
+			defer cancel()
+			if err := waitForTxNotPending(ctx, baseURL, hash[:]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Transaction %X is no longer pending\n", hash)
+			return nil
+		},
+	}
+
+	flags.AddTxFlagsToCmd(cmd)
+
+	cmd.Flags().String(gordianAddrFlag, "", "TCP address of the Gordian node's HTTP server (the node's --g-http-addr)")
+	cmd.Flags().Bool(broadcastWaitFlag, false, "Wait until the transaction is no longer pending on the node")
+	cmd.Flags().Duration(broadcastWaitTimeoutFlag, time.Minute, "How long to wait when --wait is set")
+
+	return cmd
+}
+
+// waitForTxNotPending polls the node at baseURL
+// until it stops reporting the transaction with the given hash as pending.
+func waitForTxNotPending(ctx context.Context, baseURL string, hash []byte) error {
+	u := baseURL + "/debug/pending_txs/" + hex.EncodeToString(hash)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return fmt.Errorf("failed to build pending transaction request: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to check pending transaction: %w", err)
+		}
+
+		var pending struct {
+			Pending bool
+		}
+		err = json.NewDecoder(resp.Body).Decode(&pending)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode pending transaction response: %w", err)
+		}
+		if !pending.Pending {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction still pending: %w", context.Cause(ctx))
+		case <-ticker.C:
+		}
+	}
+}
+
+func newGordianCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gordian",
+		Short: "Gordian-specific commands, including queries against a running Gordian node over its HTTP server",
+	}
+
+	q := &cobra.Command{
+		Use:     "q",
+		Aliases: []string{"query"},
+		Short:   "Query a running Gordian node",
+	}
+	q.PersistentFlags().String(gordianAddrFlag, "", "TCP address of the Gordian node's HTTP server (the node's --g-http-addr)")
+
+	bank := &cobra.Command{
+		Use:   "bank",
+		Short: "Bank module queries",
+	}
+	bank.AddCommand(newGordianBalanceCommand())
+
+	staking := &cobra.Command{
+		Use:   "staking",
+		Short: "Staking module queries",
+	}
+	staking.AddCommand(newGordianValidatorsCommand())
+
+	q.AddCommand(bank, staking)
+
+	keys := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage local Gordian keys",
+	}
+	consensus := &cobra.Command{
+		Use:   "consensus",
+		Short: "Manage the consensus (priv_validator) key",
+	}
+	consensus.AddCommand(newConsensusKeyRecoverCommand())
+	keys.AddCommand(consensus)
+
+	actions := &cobra.Command{
+		Use:   "actions",
+		Short: "Inspect and compact the signed actions recorded with --" + actionRetainHeightsFlag,
+	}
+	actions.AddCommand(newActionsInspectCommand(), newActionsCompactCommand())
+
+	cmd.AddCommand(q, keys, actions, newReplayCommand(), newLoadTestCommand())
+
+	return cmd
+}
+
+func newGordianBalanceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "balance [address]",
+		Short: "Print the balance of an account in a single denomination",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			denom, err := cmd.Flags().GetString("denom")
+			if err != nil {
+				return err
+			}
+			return printGordianQuery(
+				cmd,
+				"/debug/accounts/"+url.PathEscape(args[0])+"/balance?denom="+url.QueryEscape(denom),
+			)
+		},
+	}
+
+	cmd.Flags().String("denom", "stake", "Denomination of the balance to query")
+
+	return cmd
+}
+
+func newGordianValidatorsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validators",
+		Short: "Print the staking module's validators",
+		Args:  cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printGordianQuery(cmd, "/debug/staking/validators")
+		},
+	}
+}
+
+const (
+	recoverSourceFlag     = "source"
+	recoverDerivationFlag = "derivation"
+	recoverHDPathFlag     = "hd-path"
+	recoverPassphraseFlag = "passphrase-file"
+	recoverOverwriteFlag  = "overwrite"
+)
+
+func newConsensusKeyRecoverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recover",
+		Short: "Restore the consensus key from a BIP39 mnemonic",
+		Long: `Restore the consensus key from a BIP39 mnemonic, writing it to the priv_validator_key.json file.
+
+The mnemonic is read from the --source file, or else from the first line of standard input.
+
+With the default cosmos derivation, the key is the one written by "init --recover" with the same mnemonic.
+With the slip10 derivation, the key is derived from the BIP39 seed per SLIP-0010 along --hd-path,
+optionally with a BIP39 passphrase read from --passphrase-file.
+
+An existing priv_validator_state.json file is left untouched,
+so the double-signing protection it records is kept.`,
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mnemonic, err := readSecretInput(cmd, recoverSourceFlag)
+			if err != nil {
+				return fmt.Errorf("failed to read mnemonic: %w", err)
+			}
+			if !bip39.IsMnemonicValid(mnemonic) {
+				return errors.New("invalid mnemonic")
+			}
+
+			var passphrase string
+			if p, _ := cmd.Flags().GetString(recoverPassphraseFlag); p != "" {
+				b, err := os.ReadFile(p)
+				if err != nil {
+					return fmt.Errorf("failed to read passphrase: %w", err)
+				}
+				passphrase = strings.TrimRight(string(b), "\r\n")
+			}
+
+			d, err := cmd.Flags().GetString(recoverDerivationFlag)
+			if err != nil {
+				return err
+			}
+			scheme, err := gckeyderiv.ParseScheme(d)
+			if err != nil {
+				return err
+			}
+			hdPath, err := cmd.Flags().GetString(recoverHDPathFlag)
+			if err != nil {
+				return err
+			}
+
+			priv, err := gckeyderiv.ConsensusKey(scheme, mnemonic, passphrase, hdPath)
+			if err != nil {
+				return fmt.Errorf("failed to derive consensus key: %w", err)
+			}
+
+			cometConfig := client.GetConfigFromCmd(cmd)
+			keyFile := cometConfig.PrivValidatorKeyFile()
+			stateFile := cometConfig.PrivValidatorStateFile()
+
+			overwrite, err := cmd.Flags().GetBool(recoverOverwriteFlag)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(keyFile); err == nil && !overwrite {
+				return fmt.Errorf("%s already exists; use --%s to replace it", keyFile, recoverOverwriteFlag)
+			}
+
+			if err := os.MkdirAll(filepath.Dir(keyFile), 0o700); err != nil {
+				return fmt.Errorf("failed to create key directory: %w", err)
+			}
+
+			fpv := privval.NewFilePV(cmted25519.PrivKey(priv), keyFile, stateFile)
+			fpv.Key.Save()
+
+			if _, err := os.Stat(stateFile); errors.Is(err, os.ErrNotExist) {
+				if err := os.MkdirAll(filepath.Dir(stateFile), 0o700); err != nil {
+					return fmt.Errorf("failed to create state directory: %w", err)
+				}
+				fpv.LastSignState.Save()
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Recovered consensus key %X to %s\n", fpv.Key.PubKey.Bytes(), keyFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(recoverSourceFlag, "", "Path to a file containing the mnemonic; if blank, the mnemonic is read from standard input")
+	cmd.Flags().String(recoverDerivationFlag, string(gckeyderiv.SchemeCosmos), "Key derivation scheme; either cosmos (matching init --recover) or slip10")
+	cmd.Flags().String(recoverHDPathFlag, gckeyderiv.DefaultPath, "Hardened derivation path for the slip10 scheme")
+	cmd.Flags().String(recoverPassphraseFlag, "", "Path to a file containing the BIP39 passphrase for the slip10 scheme")
+	cmd.Flags().Bool(recoverOverwriteFlag, false, "Replace an existing priv_validator_key.json file")
+
+	return cmd
+}
+
+const replaySpeedFlag = "speed"
+
+func newReplayCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay [replay-file] [-- start flags...]",
+		Short: "Start a node that replays recorded consensus messages",
+		Long: `Start a node that feeds the consensus messages in a replay file to its engine,
+instead of connecting to the network.
+
+Replay files are written by a node started with --` + consensusRecordFileFlag + `.
+To reproduce the recorded behavior, use a fresh home directory
+with the same genesis file and consensus key as the recording node.
+
+Arguments after the replay file are passed to the start command.`,
+		Args: cobra.MinimumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := os.Stat(args[0]); err != nil {
+				return fmt.Errorf("failed to read replay file: %w", err)
+			}
+
+			speed, err := cmd.Flags().GetFloat64(replaySpeedFlag)
+			if err != nil {
+				return err
+			}
+
+			root := cmd.Root()
+			if start, _, err := root.Find([]string{"start"}); err != nil || start == root {
+				return errors.New("no start command available to run the replay")
+			}
+
+			startArgs := []string{
+				"start",
+				"--" + consensusReplayFileFlag + "=" + args[0],
+				"--" + consensusReplaySpeedFlag + "=" + strconv.FormatFloat(speed, 'g', -1, 64),
+			}
+			root.SetArgs(append(startArgs, args[1:]...))
+			return root.ExecuteContext(cmd.Context())
+		},
+	}
+
+	cmd.Flags().Float64(replaySpeedFlag, 1, "Replay speed relative to the recorded timing; 0 replays messages without delay")
+
+	return cmd
+}
+
+// openActionStore opens the action store in the home directory of cmd,
+// without a fallback store, for offline inspection.
+func openActionStore(cmd *cobra.Command) (*gaction.Store, error) {
+	dir := filepath.Join(client.GetConfigFromCmd(cmd).RootDir, "data", "actions")
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("no action store found: %w", err)
+	}
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+	return gaction.NewStore(slog.New(slog.NewTextHandler(cmd.ErrOrStderr(), nil)), gaction.StoreConfig{
+		Dir:      dir,
+		Codec:    tmjson.MarshalCodec{CryptoRegistry: &reg},
+		Registry: &reg,
+	})
+}
+
+func newActionsInspectCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect",
+		Short: "Print the range of heights and total size of the recorded actions",
+		Args:  cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := openActionStore(cmd)
+			if err != nil {
+				return err
+			}
+			st, err := s.Stats()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Directory: %s\n", s.Dir())
+			fmt.Fprintf(out, "Heights:   %d\n", st.Heights)
+			if st.Heights > 0 {
+				fmt.Fprintf(out, "Range:     %d-%d\n", st.Lowest, st.Highest)
+			}
+			fmt.Fprintf(out, "Size:      %d bytes\n", st.Bytes)
+			return nil
+		},
+	}
+}
+
+const actionsRetainFlag = "retain"
+
+func newActionsCompactCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Remove the recorded actions of old heights",
+		Long: `Remove the recorded actions of every height more than --retain heights
+below the highest recorded height.
+
+The engine only consults the actions of the round it resumes in,
+so old heights no longer protect against double signing.
+At least ` + strconv.Itoa(gaction.MinRetainHeights) + ` heights are always kept,
+and the command is safe to run while the node is running.`,
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			retain, err := cmd.Flags().GetUint64(actionsRetainFlag)
+			if err != nil {
+				return err
+			}
+
+			s, err := openActionStore(cmd)
+			if err != nil {
+				return err
+			}
+			res, err := s.Compact(retain)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d heights below height %d\n", res.Removed, res.Below)
+			return nil
+		},
+	}
+
+	cmd.Flags().Uint64(actionsRetainFlag, 100, "Number of recent heights to keep")
+
+	return cmd
+}
+
+const (
+	loadtestNodesFlag            = "nodes"
+	loadtestAccountNumbersFlag   = "account-numbers"
+	loadtestSequencesFlag        = "sequences"
+	loadtestAmountFlag           = "amount"
+	loadtestRateFlag             = "rate"
+	loadtestDurationFlag         = "duration"
+	loadtestInclusionTimeoutFlag = "inclusion-timeout"
+)
+
+func newLoadTestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "loadtest [key-name...]",
+		Short: "Submit signed bank sends to running Gordian nodes at a fixed rate and report throughput",
+		Long: `Submit signed bank sends to running Gordian nodes at a fixed rate and report throughput.
+
+Each named key must be a funded account in the keyring.
+Every transaction sends --amount from one account to the next account in the argument list,
+so funds keep circulating among the accounts.
+Gordian does not serve the account queries used for online signing,
+so --account-numbers must be set, in the same order as the keys;
+--sequences defaults to zero for every account.
+
+Accounts are spread across the --nodes addresses,
+each account always submitting to the same node.
+Once --duration elapses, the command waits up to --inclusion-timeout
+for the submitted transactions to leave the nodes' transaction buffers,
+then reports throughput, inclusion latency percentiles, and buffer sizes.`,
+		Args: cobra.MinimumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to get client context: %w", err)
+			}
+
+			txf, err := clienttx.NewFactoryCLI(clientCtx, cmd.Flags())
+			if err != nil {
+				return fmt.Errorf("failed to build transaction factory: %w", err)
+			}
+
+			addrs, err := cmd.Flags().GetStringSlice(loadtestNodesFlag)
+			if err != nil {
+				return err
+			}
+			if len(addrs) == 0 {
+				return fmt.Errorf("--%s is required", loadtestNodesFlag)
+			}
+			nodes := make([]*gcclient.Client, len(addrs))
+			for i, a := range addrs {
+				nodes[i] = gcclient.New(gcclient.Config{Addr: a})
+			}
+
+			accountNumbers, err := cmd.Flags().GetUintSlice(loadtestAccountNumbersFlag)
+			if err != nil {
+				return err
+			}
+			if len(accountNumbers) != len(args) {
+				return fmt.Errorf(
+					"--%s must have one entry per key (got %d for %d keys)",
+					loadtestAccountNumbersFlag, len(accountNumbers), len(args),
+				)
+			}
+			sequences, err := cmd.Flags().GetUintSlice(loadtestSequencesFlag)
+			if err != nil {
+				return err
+			}
+			if len(sequences) == 0 {
+				sequences = make([]uint, len(args))
+			} else if len(sequences) != len(args) {
+				return fmt.Errorf(
+					"--%s must have one entry per key (got %d for %d keys)",
+					loadtestSequencesFlag, len(sequences), len(args),
+				)
+			}
+
+			amountStr, err := cmd.Flags().GetString(loadtestAmountFlag)
+			if err != nil {
+				return err
+			}
+			amount, err := sdk.ParseCoinsNormalized(amountStr)
+			if err != nil {
+				return fmt.Errorf("invalid value for --%s: %w", loadtestAmountFlag, err)
+			}
+
+			bech32Addrs := make([]string, len(args))
+			for i, name := range args {
+				rec, err := clientCtx.Keyring.Key(name)
+				if err != nil {
+					return fmt.Errorf("failed to find key %q: %w", name, err)
+				}
+				addr, err := rec.GetAddress()
+				if err != nil {
+					return fmt.Errorf("failed to get address of key %q: %w", name, err)
+				}
+				bech32Addrs[i], err = clientCtx.AddressCodec.BytesToString(addr)
+				if err != nil {
+					return fmt.Errorf("failed to encode address of key %q: %w", name, err)
+				}
+			}
+
+			rate, err := cmd.Flags().GetFloat64(loadtestRateFlag)
+			if err != nil {
+				return err
+			}
+			duration, err := cmd.Flags().GetDuration(loadtestDurationFlag)
+			if err != nil {
+				return err
+			}
+			inclusionTimeout, err := cmd.Flags().GetDuration(loadtestInclusionTimeoutFlag)
+			if err != nil {
+				return err
+			}
+
+			txDecoder := gccodec.NewTxDecoder(clientCtx.TxConfig)
+			newTx := func(account int, n uint64) (gcload.Tx, error) {
+				msg := banktypes.NewMsgSend(
+					bech32Addrs[account], bech32Addrs[(account+1)%len(args)], amount,
+				)
+
+				f := txf.
+					WithAccountNumber(uint64(accountNumbers[account])).
+					WithSequence(uint64(sequences[account]) + n)
+				txBuilder, err := f.BuildUnsignedTx(msg)
+				if err != nil {
+					return gcload.Tx{}, fmt.Errorf("failed to build transaction: %w", err)
+				}
+
+				// Always sign offline, as there is nowhere to look up the account.
+				if err := authclient.SignTx(f, clientCtx, args[account], txBuilder, true, true); err != nil {
+					return gcload.Tx{}, fmt.Errorf("failed to sign transaction: %w", err)
+				}
+
+				txJSON, err := clientCtx.TxConfig.TxJSONEncoder()(txBuilder.GetTx())
+				if err != nil {
+					return gcload.Tx{}, fmt.Errorf("failed to encode signed transaction: %w", err)
+				}
+
+				// Decode the transaction the same way the node does,
+				// so that we look for the same hash.
+				tx, err := txDecoder.DecodeJSON(txJSON)
+				if err != nil {
+					return gcload.Tx{}, fmt.Errorf("failed to decode signed transaction: %w", err)
+				}
+				hash := tx.Hash()
+
+				return gcload.Tx{JSON: txJSON, Hash: hash[:]}, nil
+			}
+
+			rep, err := gcload.Run(cmd.Context(), gcload.Config{
+				Nodes:    nodes,
+				Accounts: len(args),
+				NewTx:    newTx,
+
+				Rate:             rate,
+				Duration:         duration,
+				InclusionTimeout: inclusionTimeout,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = rep.WriteTo(cmd.OutOrStdout())
+			return err
+		},
+	}
+
+	flags.AddTxFlagsToCmd(cmd)
+
+	cmd.Flags().StringSlice(loadtestNodesFlag, nil, "Comma-separated TCP addresses of the Gordian nodes' HTTP servers")
+	cmd.Flags().UintSlice(loadtestAccountNumbersFlag, nil, "Comma-separated account numbers, one per key")
+	cmd.Flags().UintSlice(loadtestSequencesFlag, nil, "Comma-separated starting sequences, one per key; defaults to zero")
+	cmd.Flags().String(loadtestAmountFlag, "1stake", "Amount sent in each transaction")
+	cmd.Flags().Float64(loadtestRateFlag, 10, "Transactions per second to submit across all accounts")
+	cmd.Flags().Duration(loadtestDurationFlag, 30*time.Second, "How long to submit transactions for")
+	cmd.Flags().Duration(loadtestInclusionTimeoutFlag, gcload.DefaultInclusionTimeout, "How long to wait for submitted transactions after the last submission")
+
+	return cmd
+}
+
+// readSecretInput reads a single line of secret input
+// from the file named by the given flag, or from standard input if the flag is blank.
+func readSecretInput(cmd *cobra.Command, fileFlag string) (string, error) {
+	var r io.Reader = cmd.InOrStdin()
+	if p, _ := cmd.Flags().GetString(fileFlag); p != "" {
+		f, err := os.Open(p)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// gordianBaseURL returns the base URL of the node set through the --gordian-addr flag.
+func gordianBaseURL(cmd *cobra.Command) (string, error) {
+	httpAddr, err := cmd.Flags().GetString(gordianAddrFlag)
+	if err != nil {
+		return "", err
+	}
+	if httpAddr == "" {
+		return "", fmt.Errorf("--%s is required", gordianAddrFlag)
+	}
+	return "http://" + httpAddr, nil
+}
+
+// printGordianQuery issues a GET request for path against the node
+// and prints the response body, which is already SDK-compatible JSON.
+func printGordianQuery(cmd *cobra.Command, path string) error {
+	baseURL, err := gordianBaseURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build query request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query node: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read query response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(
+			"query failed (status %d): %s",
+			resp.StatusCode, strings.TrimSpace(string(body)),
+		)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(string(body)))
+	return nil
+}
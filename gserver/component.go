@@ -3,6 +3,8 @@ package gserver
 import (
 	"context"
 	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,7 +12,10 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"cosmossdk.io/core/transaction"
 	cosmoslog "cosmossdk.io/log"
@@ -18,29 +23,56 @@ import (
 	cometconfig "github.com/cometbft/cometbft/config"
 	"github.com/cometbft/cometbft/privval"
 	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/gordian-engine/gcosmos/gccrypto/gcpkcs11"
 	"github.com/gordian-engine/gcosmos/gcstore"
 	"github.com/gordian-engine/gcosmos/gcstore/gcmemstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcpgstore"
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaction"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gchancfg"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gchaos"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gclock"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gda"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gevidence"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gfork"
+	"github.com/gordian-engine/gcosmos/gserver/internal/ggossip"
 	"github.com/gordian-engine/gcosmos/gserver/internal/ggrpc"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gingress"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gliveness"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gmigrate"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gp2papi"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpanic"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpersist"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gprofile"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gready"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gscheme"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gstall"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gstop"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtelemetry"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtenant"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gvalbook"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gversion"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatch"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatermark"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwire"
+	"github.com/gordian-engine/gordian/gassert"
 	"github.com/gordian-engine/gordian/gcrypto"
 	"github.com/gordian-engine/gordian/gdriver/gtxbuf"
 	"github.com/gordian-engine/gordian/gwatchdog"
 	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
 	"github.com/gordian-engine/gordian/tm/tmconsensus"
-	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
 	"github.com/gordian-engine/gordian/tm/tmdriver"
 	"github.com/gordian-engine/gordian/tm/tmengine"
 	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
-	"github.com/gordian-engine/gordian/tm/tmgossip"
 	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p"
 	"github.com/gordian-engine/gordian/tm/tmstore"
-	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
 	"github.com/gordian-engine/tmsqlite"
 	"github.com/libp2p/go-libp2p"
-	libp2pevent "github.com/libp2p/go-libp2p/core/event"
-	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -61,8 +93,19 @@ type Component struct {
 
 	log *slog.Logger
 
+	// Records when and why each subsystem started during Start stops.
+	stops *gstop.Report
+
+	// Recovers panics in gcosmos goroutines by failing the root context.
+	shield *gpanic.Shield
+
+	slowFinalizeCapture *gprofile.SlowCapture
+
 	chainID string
 
+	// SHA-256 hash of the genesis file.
+	genesisHash []byte
+
 	app   serverv2.AppI[transaction.Tx]
 	txc   transaction.Codec[transaction.Tx]
 	codec codec.Codec
@@ -80,16 +123,193 @@ type Component struct {
 	driver *gsi.Driver
 	cStrat *gsi.ConsensusStrategy
 	dh     *gp2papi.DataHost
+	gossip *ggossip.SwappableStrategy
+
+	// Optional detector wrapping the gossip strategy,
+	// reporting when the voting round stops advancing for stallThreshold.
+	stall          *gstall.Detector
+	stallThreshold time.Duration
+
+	// Measurements of hand-offs between subsystems and the engine.
+	backpressure *gbackpressure.Registry
+
+	// Optional file-backed action store discarding old heights,
+	// used in place of the SQLite action store.
+	actions *gaction.Store
+
+	// Wraps the SQLite round store, retrying failed writes
+	// rather than stopping the engine. Nil with in-memory stores.
+	roundStore *gpersist.RoundStore
+
+	chanSizes gchancfg.Sizes
+
+	// Latest heights, fed by the gossip strategy and the driver.
+	watermarks *gwatermark.Writer
+
+	// Watched addresses, fed the events of finalized blocks by the driver.
+	watch *gwatch.Index
+
+	// Records an app hash mismatch, persisted in the data directory.
+	forks *gfork.Recorder
+
+	// Records protocol violations by peers and validators,
+	// persisted in the data directory.
+	audit *gaudit.Log
+
+	// Consensus parameter changes requested by the app,
+	// persisted in the data directory.
+	params *gparams.Schedule
+
+	// Validator monikers, from genesis and the staking module.
+	valBook *gvalbook.Book
+
+	// Verifies and shares evidence of conflicting commits with peers.
+	forkEvidence *gevidence.Exchange
+
+	// Optional heartbeats measuring peers' clock skew,
+	// enabled when clockSkewThreshold is positive.
+	clockSkew          *gclock.Monitor
+	clockSkewThreshold time.Duration
+
+	// Negotiates protocol versions with each peer at connect time,
+	// disconnecting incompatible peers unless keepIncompatiblePeers is set.
+	versions              *gversion.Negotiator
+	keepIncompatiblePeers bool
+
+	// Heartbeats to and from peers, sent every heartbeatInterval;
+	// nil if the interval is zero.
+	heartbeats        *gliveness.Heartbeater
+	heartbeatInterval time.Duration
+
+	// Upgrade features this node's validator advertises in heartbeats,
+	// the latest signed signal for them,
+	// and every validator's latest signal received from peers.
+	// The tracker is nil if heartbeats are disabled.
+	readyFeatures []string
+	readySigner   gcrypto.Signer
+	readySignal   atomic.Pointer[gready.Signal]
+	readiness     *gready.Tracker
+
+	// Optional reporter of anonymized statistics to telemetryURL,
+	// enabled when the URL is set.
+	telemetry         *gtelemetry.Reporter
+	telemetryURL      string
+	telemetryInterval time.Duration
+
+	// The driver, once created, for the heartbeater goroutine
+	// to report whether the app is finalizing a block.
+	liveDriver atomic.Pointer[gsi.Driver]
+
+	// How long Stop waits for the driver to finish its in-flight work
+	// before canceling every subsystem.
+	shutdownTimeout time.Duration
+
+	// Whether Init only checks the loaded state and exits, instead of returning to start.
+	validateOnly bool
 
 	seedAddrs string
 
+	// Optional paths for recording inbound consensus messages,
+	// or replaying previously recorded messages instead of using the network.
+	recordPath  string
+	replayPath  string
+	replaySpeed float64
+	recordFile  *os.File
+	replayDone  chan struct{}
+
+	// Whether to sign outgoing consensus messages with the libp2p identity key,
+	// and whether to reject incoming consensus messages without a signature.
+	gossipSign        bool
+	gossipRequireSigs bool
+
+	timeoutStrategy     gsi.TimeoutStrategy
+	precommits          *gsi.PrecommitTracker // Nil unless commit wait is skipped on full precommits.
+	roundHistory        *gsi.RoundHistory     // Nil if disabled.
+	roundHistoryHeights int
+	emptyBlocks         gsi.EmptyBlockPolicy
+
+	guardCfg    gingress.GuardConfig
+	dedupeCfg   gingress.DedupeConfig
+	bdrCacheCfg gsbd.RequestCacheConfig
+
+	// When set, Start only follows committed headers
+	// instead of running the engine.
+	headerOnly         bool
+	trustedInitialHash []byte
+	follower           *gp2papi.HeaderFollower
+
+	// Rollup mode settings.
+	// The queue is only created during Start when daPublisher is set.
+	daPublisher gda.Publisher
+	daGating    gda.Gating
+	daQueue     *gda.Queue
+
+	// When minStartPeers is positive, the initial height waits
+	// for that many connected peers, for at most startPeerTimeout.
+	minStartPeers    int
+	startPeerTimeout time.Duration
+	peerBarrier      *gp2papi.PeerBarrier
+
+	// How client transactions reusing a pending signer sequence are handled.
+	seqPolicy gsi.SequencePolicy
+
+	// Optional; nil unless a builder URL was configured.
+	blockBuilder        gsi.BlockBuilder
+	blockBuilderTimeout time.Duration
+
+	// Reserved from the proposal timeout for our proposal to reach the network;
+	// zero uses the consensus strategy's default.
+	proposalBroadcastMargin time.Duration
+
+	// An empty struct in non-debug builds.
+	assertEnv gassert.Env
+
+	// Always disabled in non-debug builds.
+	chaosCfg gchaos.Config
+	chaos    *gchaos.Handler
+
 	httpLn net.Listener
 	grpcLn net.Listener
 
+	// Set instead of httpLn when the HTTP server is shared
+	// with the other chains hosted in this process.
+	sharedHTTP        *gtenant.Host
+	releaseSharedHTTP func()
+
+	// Bearer tokens required by the HTTP and gRPC servers, if set.
+	rpcTokens *gtenant.Tokens
+
+	httpMaxPageSize int
+
 	reg *gcrypto.Registry
 
+	// Dispatch to the schemes in effect at each height,
+	// per the upgrades declared in the genesis file.
+	schedule   *gscheme.Schedule
+	sigScheme  tmconsensus.SignatureScheme
+	hashScheme tmconsensus.HashScheme
+
+	// The encoding of gossiped consensus messages at each height,
+	// per the wire upgrades declared in the genesis file.
+	wireSchedule *gwire.Schedule
+
+	compactCommitProofs bool
+
 	tmsql *tmsqlite.Store // Conditionally set.
 
+	// Set when a PKCS#11 module is configured.
+	// hsmDone is closed once the health check goroutine returns.
+	hsmToken  *gcpkcs11.ModuleToken
+	hsmSigner *gcpkcs11.Signer
+	hsmDone   chan struct{}
+
+	// Nil unless set through SetSignatureVerifier.
+	sigVerifier gcverify.SignatureVerifier
+
+	// Set when a PostgreSQL DSN is configured.
+	pgDB    *sql.DB
+	pgStore *gcpgstore.Store
+
 	// These stores are unconditionally set,
 	// and they may be either explicit in-memory stores,
 	// or they may all be pointing at tmsql.
@@ -99,6 +319,7 @@ type Component struct {
 	chs tmstore.CommittedHeaderStore
 	fs  tmstore.FinalizationStore
 	ms  tmstore.MirrorStore
+	vs  tmstore.ValidatorStore
 
 	httpServer *gsi.HTTPServer
 	grpcServer *ggrpc.GordianGRPC
@@ -117,6 +338,8 @@ func NewComponent(
 	var c Component
 	c.rootCtx, c.cancel = context.WithCancelCause(rootCtx)
 	c.log = log.With("root", "gcosmos")
+	c.stops = gstop.NewReport(c.log.With("sys", "stop_report"))
+	c.watermarks = gwatermark.NewWriter()
 	c.txc = txc
 	c.codec = codec
 
@@ -135,7 +358,7 @@ func (c *Component) Name() string {
 var memDBNameCounter uint32
 
 // Init is called early in the SDK server component lifecycle, before Start.
-func (c *Component) Init(app serverv2.AppI[transaction.Tx], cfg map[string]any, log cosmoslog.Logger) error {
+func (c *Component) Init(app serverv2.AppI[transaction.Tx], cfg map[string]any, log cosmoslog.Logger) (err error) {
 	if c.log == nil {
 		l, ok := log.Impl().(*slog.Logger)
 		if !ok {
@@ -146,37 +369,31 @@ func (c *Component) Init(app serverv2.AppI[transaction.Tx], cfg map[string]any,
 
 	// It's somewhat likely that a user could misconfigure the assertion rules in a debug build,
 	// so check those before doing any other heavy lifting.
-	assertOpt, err := getAssertEngineOpt(cfg)
+	assertOpt, assertEnv, err := getAssertEngineOpt(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to build assertion environment: %w", err)
 	}
+	c.assertEnv = assertEnv
 
-	// Maybe set up the HTTP server.
-	if httpAddr, ok := cfg[httpAddrFlag].(string); ok && httpAddr != "" {
-		ln, err := net.Listen("tcp", httpAddr)
-		if err != nil {
-			return fmt.Errorf("failed to listen for HTTP on %q: %w", httpAddr, err)
-		}
-
-		if f, ok := cfg[httpAddrFileFlag].(string); ok && f != "" {
-			// TODO: we should probably track this file and delete it on shutdown.
-			addr := ln.Addr().String() + "\n"
-			if err := os.WriteFile(f, []byte(addr), 0600); err != nil {
-				return fmt.Errorf("failed to write HTTP address to file %q: %w", f, err)
-			}
-		}
-
-		c.httpLn = ln
+	// Likewise for the chaos settings, which are also only available in debug builds.
+	c.chaosCfg, err = getChaosConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to parse chaos configuration: %w", err)
 	}
 
-	// Maybe set up the GRPC server.
-	if grpcAddrFlag, ok := cfg[grpcAddrFlag].(string); ok && grpcAddrFlag != "" {
-		ln, err := net.Listen("tcp", grpcAddrFlag)
-		if err != nil {
-			return fmt.Errorf("failed to listen for gRPC on %q: %w", grpcAddrFlag, err)
+	// Stop is not called after a failed Init,
+	// and a shared host stays open until every chain sharing it releases it.
+	defer func() {
+		if err != nil && c.releaseSharedHTTP != nil {
+			c.releaseSharedHTTP()
+			c.sharedHTTP = nil
+			c.releaseSharedHTTP = nil
 		}
+	}()
 
-		c.grpcLn = ln
+	// Maybe set up the HTTP and gRPC servers.
+	if err := c.initializeAPIListeners(cfg); err != nil {
+		return err
 	}
 
 	if sa, ok := cfg[seedAddrsFlag].(string); ok {
@@ -186,6 +403,83 @@ func (c *Component) Init(app serverv2.AppI[transaction.Tx], cfg map[string]any,
 		c.log.Warn("No seed addresses provided; relying on incoming connections to discover peers")
 	}
 
+	if err := c.initializeTimeoutStrategy(cfg); err != nil {
+		return fmt.Errorf("failed to configure timeout strategy: %w", err)
+	}
+
+	if err := c.initializeRoundHistory(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeIngress(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeDA(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeHeaderOnly(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeReplay(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeGossip(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeStartPeers(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeHeartbeats(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeTelemetry(cfg); err != nil {
+		return err
+	}
+	if err := c.initializeClockSkew(cfg); err != nil {
+		return err
+	}
+
+	if s := flagString(cfg, blockDataCacheMaxBytesFlag); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid value for %s: %q", blockDataCacheMaxBytesFlag, s)
+		}
+		c.bdrCacheCfg.MaxBytes = n
+	}
+
+	sp, err := gsi.ParseSequencePolicy(flagString(cfg, txSequencePolicyFlag))
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", txSequencePolicyFlag, err)
+	}
+	c.seqPolicy = sp
+
+	if s := flagString(cfg, shutdownTimeoutFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", shutdownTimeoutFlag, s)
+		}
+		c.shutdownTimeout = d
+	}
+
+	c.keepIncompatiblePeers = flagString(cfg, keepIncompatiblePeersFlag) == "true"
+
+	c.validateOnly = flagString(cfg, validateOnlyFlag) == "true"
+
+	var bpWarn time.Duration
+	if s := flagString(cfg, backpressureWarnThresholdFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", backpressureWarnThresholdFlag, s)
+		}
+		bpWarn = d
+	}
+	c.backpressure = gbackpressure.NewRegistry(c.log.With("sys", "backpressure"), bpWarn)
+
+	chanSizes, err := gchancfg.Parse(flagString(cfg, channelSizesFlag))
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", channelSizesFlag, err)
+	}
+	c.chanSizes = chanSizes
+
 	c.app = app
 
 	// Load the comet config, in order to read the privval key from disk.
@@ -194,6 +488,78 @@ func (c *Component) Init(app serverv2.AppI[transaction.Tx], cfg map[string]any,
 	// to get to the FilePVKey,
 	// which gives us the PrivKey.
 	homeDir := cfg["home"].(string)
+
+	dumpDir := flagString(cfg, panicDumpDirFlag)
+	if dumpDir == "" {
+		dumpDir = filepath.Join(homeDir, "data", "panics")
+	}
+	c.shield = gpanic.NewShield(c.log.With("sys", "panic_shield"), gpanic.ShieldConfig{
+		DumpDir: dumpDir,
+		Fail:    c.cancel,
+	})
+
+	if s := flagString(cfg, slowFinalizeThresholdFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", slowFinalizeThresholdFlag, s)
+		}
+		c.slowFinalizeCapture = gprofile.NewSlowCapture(
+			c.log.With("sys", "slow_finalize"),
+			gprofile.SlowCaptureConfig{
+				Dir:       filepath.Join(homeDir, "data", "profiles"),
+				Threshold: d,
+			},
+		)
+	}
+
+	migrateMode := gmigrate.ModeAuto
+	if s := flagString(cfg, dataMigrationsFlag); s != "" {
+		m, err := gmigrate.ParseMode(s)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", dataMigrationsFlag, err)
+		}
+		migrateMode = m
+	}
+	if err := gmigrate.Run(
+		c.rootCtx, c.log.With("sys", "data_migrations"),
+		filepath.Join(homeDir, "data"), migrateMode, dataMigrations,
+	); err != nil {
+		return err
+	}
+
+	if err := c.initializeForkRecorder(homeDir); err != nil {
+		return err
+	}
+
+	audit, err := gaudit.OpenLog(
+		c.log.With("sys", "audit"),
+		gaudit.LogConfig{
+			Path:  filepath.Join(homeDir, "data", "audit.jsonl"),
+			Codec: tmjson.MarshalCodec{CryptoRegistry: c.reg},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	c.audit = audit
+
+	params, err := gparams.NewSchedule(
+		c.log.With("sys", "consensus_params"),
+		filepath.Join(homeDir, "data", "consensus_params.json"),
+	)
+	if err != nil {
+		return err
+	}
+	c.params = params
+
+	// Set here rather than with the other timeout settings,
+	// which are parsed before the schedule is loaded.
+	c.timeoutStrategy.Params = c.params
+
+	if err := c.initializeProposals(cfg); err != nil {
+		return err
+	}
+
 	cometConfig := cometconfig.DefaultConfig().SetRoot(homeDir)
 	if err := serverv2.UnmarshalSubConfig(cfg, "", &cometConfig); err != nil {
 		return fmt.Errorf("failed to unmarshal comet config (to get private key info): %w", err)
@@ -208,64 +574,98 @@ func (c *Component) Init(app serverv2.AppI[transaction.Tx], cfg map[string]any,
 		))
 	}
 
+	// Is it possible for the genesis path to ever be rooted somewhere else?
+	genesisPath := filepath.Join(homeDir, "config", "genesis.json")
+	genesisBytes, err := os.ReadFile(genesisPath)
+	if err != nil {
+		return fmt.Errorf("failed to read genesis file to extract chain ID: %w", err)
+	}
+
+	var cid struct {
+		ChainID string `json:"chain_id"`
+	}
+	if err := json.Unmarshal(genesisBytes, &cid); err != nil {
+		return fmt.Errorf("failed to parse JSON from genesis file at %s: %w", genesisPath, err)
+	}
+
+	// Store the chain ID on the component, because the driver needs it during Start.
+	c.chainID = cid.ChainID
+
+	// Peers exchange the genesis hash at connect time,
+	// to refuse peers on another chain with a reused chain ID.
+	genesisHash := sha256.Sum256(genesisBytes)
+	c.genesisHash = genesisHash[:]
+
+	sched, err := gscheme.LoadGenesisSchedule(genesisPath)
+	if err != nil {
+		return fmt.Errorf("failed to load signature scheme schedule: %w", err)
+	}
+	c.schedule = sched
+	c.sigScheme = sched.SignatureScheme()
+	c.hashScheme = sched.HashScheme()
+
+	c.wireSchedule, err = gwire.LoadGenesisSchedule(genesisPath)
+	if err != nil {
+		return fmt.Errorf("failed to load wire encoding schedule: %w", err)
+	}
+
+	c.valBook = gvalbook.New()
+	if _, err := c.valBook.LoadGenesisFile(genesisPath); err != nil {
+		// Monikers are only informational, so this is not fatal.
+		c.log.Warn("Failed to load validator monikers from genesis", "err", err)
+	}
+
+	if err := c.initializeHSMSigner(cfg); err != nil {
+		return fmt.Errorf("failed to initialize HSM signer: %w", err)
+	}
+
 	// TODO: we should allow a way to explicitly NOT provide a signer.
+	fileSigner := gcrypto.NewEd25519Signer(ed25519.PrivateKey(privKey.Bytes()))
+	var signer gcrypto.Signer = fileSigner
+	if c.hsmSigner != nil {
+		signer = c.hsmSigner
+	}
 	c.signer = tmconsensus.PassthroughSigner{
-		Signer:          gcrypto.NewEd25519Signer(ed25519.PrivateKey(privKey.Bytes())),
-		SignatureScheme: tmconsensustest.SimpleSignatureScheme{},
+		Signer:          signer,
+		SignatureScheme: c.sigScheme,
+	}
+	if len(c.readyFeatures) > 0 {
+		c.readySigner = signer
 	}
 
 	if err := c.initializeSQLite(cfg[sqlitePathFlag].(string)); err != nil {
 		return fmt.Errorf("failed to initialize SQLite database: %w", err)
 	}
 
-	// No SQLite implementation for this yet.
-	c.bds = gcmemstore.NewBlockDataStore()
+	if err := c.initializeBlockDataStore(cfg); err != nil {
+		return fmt.Errorf("failed to initialize block data store: %w", err)
+	}
+
+	if err := c.initializePostgres(cfg); err != nil {
+		return fmt.Errorf("failed to initialize PostgreSQL store: %w", err)
+	}
 
-	var as tmstore.ActionStore
-	var rs tmstore.RoundStore = c.tmsql
-	var sms tmstore.StateMachineStore = c.tmsql
-	var vs tmstore.ValidatorStore = c.tmsql
+	// Checked before encryption wraps the store.
+	_, bdsInMemory := c.bds.(*gcmemstore.BlockDataStore)
 
-	if c.tmsql == nil {
-		if c.signer != nil {
-			as = tmmemstore.NewActionStore()
-		}
-		rs = tmmemstore.NewRoundStore()
-		sms = tmmemstore.NewStateMachineStore()
-		vs = tmmemstore.NewValidatorStore(tmconsensustest.SimpleHashScheme{})
-
-		c.chs = tmmemstore.NewCommittedHeaderStore()
-		c.fs = tmmemstore.NewFinalizationStore()
-		c.ms = tmmemstore.NewMirrorStore()
-	} else {
-		if c.signer != nil {
-			as = c.tmsql
+	if p := flagString(cfg, blockDataKeyFileFlag); p != "" {
+		bds, err := gcstore.NewEncryptingBlockDataStore(c.rootCtx, c.bds, gcstore.FileKeySource(p))
+		if err != nil {
+			return fmt.Errorf("failed to set up block data encryption: %w", err)
 		}
-
-		c.chs = c.tmsql
-		c.fs = c.tmsql
-		c.ms = c.tmsql
+		c.bds = bds
 	}
 
-	// Is it possible for the genesis path to ever be rooted somewhere else?
-	genesisPath := filepath.Join(homeDir, "config", "genesis.json")
-	gf, err := os.Open(genesisPath)
+	storeOpts, err := c.initializeConsensusStores(cfg, homeDir)
 	if err != nil {
-		return fmt.Errorf("failed to open genesis file to extract chain ID: %w", err)
+		return err
 	}
-	defer gf.Close()
 
-	var cid struct {
-		ChainID string `json:"chain_id"`
-	}
-	if err := json.NewDecoder(gf).Decode(&cid); err != nil {
-		return fmt.Errorf("failed to parse JSON from genesis file at %s: %w", genesisPath, err)
+	if bdsInMemory {
+		if err := c.checkReplayBlockData(c.rootCtx); err != nil {
+			return err
+		}
 	}
-	// Even though we have a defer above, close it explicitly now that we are done parsing.
-	_ = gf.Close()
-
-	// Store the chain ID on the component, because the driver needs it during Start.
-	c.chainID = cid.ChainID
 
 	genesis := &tmconsensus.ExternalGenesis{
 		ChainID:         cid.ChainID,
@@ -274,74 +674,49 @@ func (c *Component) Init(app serverv2.AppI[transaction.Tx], cfg map[string]any,
 		// TODO: where will GenesisValidators come from?
 	}
 
-	c.opts = []tmengine.Opt{
-		tmengine.WithSigner(c.signer),
+	c.opts = append([]tmengine.Opt{tmengine.WithSigner(c.signer)}, storeOpts...)
+	c.opts = append(
+		c.opts,
 
-		tmengine.WithActionStore(as),
-		tmengine.WithCommittedHeaderStore(c.chs),
-		tmengine.WithFinalizationStore(c.fs),
-		tmengine.WithMirrorStore(c.ms),
-		tmengine.WithRoundStore(rs),
-		tmengine.WithStateMachineStore(sms),
-		tmengine.WithValidatorStore(vs),
-
-		tmengine.WithHashScheme(tmconsensustest.SimpleHashScheme{}),
-		tmengine.WithSignatureScheme(tmconsensustest.SimpleSignatureScheme{}),
-		tmengine.WithCommonMessageSignatureProofScheme(gcrypto.SimpleCommonMessageSignatureProofScheme),
+		tmengine.WithHashScheme(c.hashScheme),
+		tmengine.WithSignatureScheme(c.sigScheme),
+		tmengine.WithCommonMessageSignatureProofScheme(c.proofScheme()),
 
 		tmengine.WithGenesis(genesis),
 
 		// NOTE: there are remaining required options that we shouldn't initialize here,
 		// but instead they will be added during the Start call.
-	}
+	)
 	if assertOpt != nil {
 		// Will always be nil in non-debug builds.
 		c.opts = append(c.opts, assertOpt)
 	}
 
-	return nil
-}
-
-func (c *Component) initializeSQLite(sqlitePath string) error {
-	// First special case: empty means don't set c.tmsql at all,
-	// and the rest of the Init method will use tmmemstore.
-	if sqlitePath == "" {
-		return nil
+	if c.validateOnly {
+		return c.runValidateOnly(homeDir, fileSigner.PubKey())
 	}
 
-	var err error
-
-	// Other special case: the exact string :memory:,
-	// which has existing special meaning to SQLite.
-	if sqlitePath == ":memory:" {
-		c.tmsql, err = tmsqlite.NewInMemStore(
-			c.rootCtx,
-			tmconsensustest.SimpleHashScheme{},
-			c.reg,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to start tmsqlite store: %w", err)
-		}
-		return nil
-	}
+	return nil
+}
 
-	// Otherwise it looks like a normal path.
-	c.tmsql, err = tmsqlite.NewOnDiskStore(
-		c.rootCtx,
-		sqlitePath,
-		tmconsensustest.SimpleHashScheme{},
-		c.reg,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to start tmsqlite store: %w", err)
+// flagString returns the string form of the named flag's value in cfg,
+// or the empty string if the flag is unset.
+//
+// Numeric flags may arrive as their typed values or as strings,
+// depending on how the config map was populated,
+// so callers parse the returned string themselves.
+func flagString(cfg map[string]any, name string) string {
+	v, ok := cfg[name]
+	if !ok || v == nil {
+		return ""
 	}
-
-	c.log.Info("Using SQLite on-disk file", "path", sqlitePath)
-	return nil
+	return fmt.Sprint(v)
 }
 
 // Start is called when the SDK is starting server components.
 func (c *Component) Start(ctx context.Context) error {
+	c.startHSMHealthChecks()
+
 	h, err := tmlibp2p.NewHost(
 		c.rootCtx,
 		tmlibp2p.HostOptions{
@@ -361,7 +736,26 @@ func (c *Component) Start(ctx context.Context) error {
 
 	c.log.Info("Started libp2p host", "id", h.Libp2pHost().ID().String())
 
+	// Start negotiating versions before connecting to any seeds,
+	// so that no peer connects without a negotiation.
+	c.versions = gversion.NewNegotiator(
+		c.rootCtx,
+		c.log.With("sys", "versions"),
+		gversion.NegotiatorConfig{
+			Host:             h.Libp2pHost(),
+			KeepIncompatible: c.keepIncompatiblePeers,
+
+			ChainID:     c.chainID,
+			GenesisHash: c.genesisHash,
+		},
+	)
+	c.stops.Watch(c.rootCtx, "versions", c.versions.Wait)
+
 	for _, seedAddr := range strings.Split(c.seedAddrs, "\n") {
+		if c.replayPath != "" {
+			c.log.Info("Not connecting to seed addresses while replaying consensus messages")
+			break
+		}
 		if seedAddr == "" {
 			// If c.seedAddrs was empty, skip so we don't log a misleading warning.
 			continue
@@ -393,12 +787,30 @@ func (c *Component) Start(ctx context.Context) error {
 		c.bds,
 		codec,
 	)
+	c.stops.Watch(c.rootCtx, "datahost", c.dh.Wait)
+
+	c.startForkEvidence(codec)
+
+	if err := c.startClockSkewMonitor(); err != nil {
+		return err
+	}
+
+	c.startHeartbeats()
+
+	if c.headerOnly {
+		return c.startHeaderFollower(ctx, codec)
+	}
+
+	connCodec, err := c.gossipCodec(h, codec)
+	if err != nil {
+		return err
+	}
 
 	conn, err := tmlibp2p.NewConnection(
 		c.rootCtx,
 		c.log.With("sys", "libp2pconn"),
 		h,
-		codec,
+		connCodec,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to build libp2p connection: %w", err)
@@ -411,50 +823,30 @@ func (c *Component) Start(ctx context.Context) error {
 		txm.AddTx, txm.TxDeleterFunc,
 	)
 
-	bdrCache := gsbd.NewRequestCache()
+	txPool := gsi.NewTxPool(c.log.With("d_sys", "tx_pool"), txBuf, c.seqPolicy)
 
-	rhCh := make(chan tmelink.ReplayedHeaderRequest)
-	catchupClient := gp2papi.NewCatchupClient(
-		ctx,
-		c.log.With("d_sys", "catchup_client"),
-		gp2papi.CatchupClientConfig{
-			Host:               h.Libp2pHost(),
-			Unmarshaler:        codec,
-			TxDecoder:          c.txc,
-			RequestCache:       bdrCache,
-			ReplayedHeadersOut: rhCh,
-		},
-	)
+	bdrCache := gsbd.NewRequestCache(c.bdrCacheCfg)
 
-	sub, err := h.Libp2pHost().EventBus().Subscribe(new(libp2pevent.EvtPeerConnectednessChanged))
+	rhCh := make(chan tmelink.ReplayedHeaderRequest, c.chanSizes.ReplayedHeaderRequests)
+	catchupClient, err := c.startCatchupClient(ctx, codec, bdrCache, rhCh)
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to libp2p host's peer connectedness events: %w", err)
-	}
-	go func() {
-		defer sub.Close()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case e := <-sub.Out():
-				switch e := e.(type) {
-				case libp2pevent.EvtPeerConnectednessChanged:
-					if e.Connectedness == libp2pnetwork.Connected {
-						catchupClient.AddPeer(ctx, e.Peer)
-					} else if e.Connectedness == libp2pnetwork.NotConnected {
-						catchupClient.RemovePeer(ctx, e.Peer)
-					}
-				default:
-					c.log.Warn("Unknown peer connectedness event type", "type", fmt.Sprintf("%T", e))
-				}
-			}
-		}
-	}()
+		return err
+	}
+
+	c.startDAQueue()
+
+	startBarrier := c.startPeerBarrier()
+
+	// Avoid a typed nil in the interface when PostgreSQL is not configured.
+	var txIndex gcstore.TxIndex
+	if c.pgStore != nil {
+		txIndex = c.pgStore
+	}
 
 	initChainCh := make(chan tmdriver.InitChainRequest)
-	blockFinCh := make(chan tmdriver.FinalizeBlockRequest)
+	blockFinCh := make(chan tmdriver.FinalizeBlockRequest, c.chanSizes.FinalizeBlockRequests)
 	lagStateCh := make(chan tmelink.LagState)
+	c.watch = gwatch.NewIndex(c.log.With("sys", "watch"))
 	d, err := gsi.NewDriver(
 		c.rootCtx,
 		ctx,
@@ -471,17 +863,42 @@ func (c *Component) Start(ctx context.Context) error {
 			LagStateUpdates:       lagStateCh,
 
 			TxBuffer: txBuf,
+			TxPool:   txPool,
 
 			CatchupClient: catchupClient,
 
 			BlockDataRequestCache: bdrCache,
 			BlockDataStore:        c.bds,
+
+			DAQueue: c.daQueue,
+			TxIndex: txIndex,
+
+			StartBarrier: startBarrier,
+
+			AssertEnv:   c.assertEnv,
+			MirrorStore: c.ms,
+
+			PanicShield: c.shield,
+
+			SlowFinalizeCapture: c.slowFinalizeCapture,
+
+			Watermarks: c.watermarks,
+
+			ForkRecorder: c.forks,
+
+			ValidatorBook: c.valBook,
+
+			Params: c.params,
+
+			WatchIndex: c.watch,
 		},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create driver: %w", err)
 	}
 	c.driver = d
+	c.liveDriver.Store(d)
+	c.stops.Watch(c.rootCtx, "driver", d.Wait)
 
 	// We hold onto the options slice so that we can partially initialize it during Init.
 	// But it doesn't need to live beyond the scope of Start,
@@ -497,6 +914,20 @@ func (c *Component) Start(ctx context.Context) error {
 		tmengine.WithReplayedHeaderRequestChannel(rhCh),
 	)
 
+	pbdr := gsi.NewPBDRetriever(
+		ctx,
+		c.log.With("serversys", "pbd_retriever"),
+		gsi.PBDRetrieverConfig{
+			RequestCache: bdrCache,
+			Decoder:      c.txc,
+
+			Host: h.Libp2pHost(),
+
+			NWorkers: 4, // TODO: don't hardcode this.
+		},
+	)
+	c.stops.Watch(ctx, "pbd_retriever", pbdr.Wait)
+
 	// We needed the driver before we could make the consensus strategy.
 	csCfg := gsi.ConsensusStrategyConfig{
 		AppManager: c.app,
@@ -505,20 +936,22 @@ func (c *Component) Start(ctx context.Context) error {
 			c.log.With("s_sys", "block_provider"), h.Libp2pHost(),
 		),
 
-		ProposedBlockDataRetriever: gsi.NewPBDRetriever(
-			ctx,
-			c.log.With("serversys", "pbd_retriever"),
-			gsi.PBDRetrieverConfig{
-				RequestCache: bdrCache,
-				Decoder:      c.txc,
+		ProposedBlockDataRetriever: pbdr,
+
+		BlockDataRequestCache: bdrCache,
 
-				Host: h.Libp2pHost(),
+		TimeoutStrategy:         c.timeoutStrategy,
+		ProposalBroadcastMargin: c.proposalBroadcastMargin,
+		EmptyBlocks:             c.emptyBlocks,
 
-				NWorkers: 4, // TODO: don't hardcode this.
-			},
-		),
+		BlockBuilder:        c.blockBuilder,
+		BlockBuilderTimeout: c.blockBuilderTimeout,
 
-		BlockDataRequestCache: bdrCache,
+		Audit:  c.audit,
+		Params: c.params,
+	}
+	if c.roundHistory != nil {
+		csCfg.StepObservers = append(csCfg.StepObservers, c.roundHistory)
 	}
 	if c.signer != nil {
 		csCfg.SignerPubKey = c.signer.PubKey()
@@ -528,10 +961,19 @@ func (c *Component) Start(ctx context.Context) error {
 		c.log.With("serversys", "cons_strat"),
 		csCfg,
 	)
+	c.stops.Watch(c.rootCtx, "consensus_strategy", c.cStrat.Wait)
 	opts = append(opts, tmengine.WithConsensusStrategy(c.cStrat))
 
+	// Started before the gossip strategy, which feeds it network view updates.
+	if err := c.startTelemetry(); err != nil {
+		return err
+	}
+
 	// Depends on conn.
-	gs := tmgossip.NewChattyStrategy(ctx, c.log.With("sys", "chattygossip"), conn)
+	gs, err := c.startGossip(ctx, bdrCache)
+	if err != nil {
+		return err
+	}
 	opts = append(opts, tmengine.WithGossipStrategy(gs))
 
 	// No point in creating this channel before a call to Start.
@@ -543,7 +985,7 @@ func (c *Component) Start(ctx context.Context) error {
 
 	// The timeout strategy pairs with a context,
 	// so it makes sense to delay this until we have a watchdog context available.
-	opts = append(opts, tmengine.WithTimeoutStrategy(wdCtx, tmengine.LinearTimeoutStrategy{}))
+	opts = append(opts, tmengine.WithTimeoutStrategy(wdCtx, c.timeoutStrategy))
 
 	e, err := tmengine.New(wdCtx, c.log.With("sys", "engine"), opts...)
 	if err != nil {
@@ -551,36 +993,33 @@ func (c *Component) Start(ctx context.Context) error {
 	}
 	c.e = e
 
-	// Plain context here; if canceled, this will fail, which is fine.
-	conn.SetConsensusHandler(ctx, tmconsensus.AcceptAllValidFeedbackMapper{
-		Handler: e,
-	})
+	// The watchdog cancels wdCtx with its termination cause,
+	// which is the usual reason for the engine to stop on its own.
+	c.stops.Watch(wdCtx, "engine", e.Wait)
 
-	if c.grpcLn != nil {
-		// TODO; share this with the http server as a wrapper.
-		// https://github.com/gordian-engine/gordian/pull/14
-		c.grpcServer = ggrpc.NewGordianGRPCServer(ctx, c.log.With("sys", "grpc"), ggrpc.GRPCServerConfig{
-			Listener: c.grpcLn,
-
-			MirrorStore:       c.ms,
-			FinalizationStore: c.fs,
-
-			CryptoRegistry: c.reg,
+	var ch tmconsensus.FineGrainedConsensusHandler = e
+	if c.chaosCfg.Enabled() {
+		c.chaos = gchaos.NewHandler(wdCtx, c.log.With("sys", "chaos"), e, c.chaosCfg)
+		c.stops.Watch(wdCtx, "chaos", c.chaos.Wait)
+		ch = c.chaos
+	}
 
-			AppManager: c.app,
-			TxCodec:    c.txc,
-			Codec:      c.codec,
+	guard, dedupe, err := c.startIngress(ctx, ch, codec)
+	if err != nil {
+		return err
+	}
 
-			TxBuffer: txBuf,
-		})
+	if c.grpcLn != nil {
+		c.startGRPCServer(ctx, txBuf, txPool)
 	}
 
-	if c.httpLn != nil {
-		c.httpServer = gsi.NewHTTPServer(ctx, c.log.With("sys", "http"), gsi.HTTPServerConfig{
-			Listener: c.httpLn,
+	if c.httpLn != nil || c.sharedHTTP != nil {
+		if err := c.startHTTPServer(ctx, gsi.HTTPServerConfig{
+			MaxPageSize: c.httpMaxPageSize,
 
-			MirrorStore:       c.ms,
-			FinalizationStore: c.fs,
+			MirrorStore:          c.ms,
+			FinalizationStore:    c.fs,
+			CommittedHeaderStore: c.chs,
 
 			CryptoRegistry: c.reg,
 
@@ -592,33 +1031,102 @@ func (c *Component) Start(ctx context.Context) error {
 			Codec:      c.codec,
 
 			TxBuffer: txBuf,
-		})
+			TxPool:   txPool,
+
+			TimeoutStrategy: c.timeoutStrategy,
+
+			StallDetector: c.stall,
+			ClockSkew:     c.clockSkew,
+			Heartbeats:    c.heartbeats,
+			Watermarks:    c.watermarks,
+			WatchIndex:    c.watch,
+			RoundHistory:  c.roundHistory,
+			ForkRecorder:  c.forks,
+			ForkEvidence:  c.forkEvidence,
+			AuditLog:      c.audit,
+			Readiness:     c.readiness,
+			Backpressure:  c.backpressure,
+			RoundStore:    c.roundStore,
+			ValidatorBook: c.valBook,
+
+			BlockDataCache: bdrCache,
+			IngressGuard:   guard,
+			IngressDedupe:  dedupe,
+
+			NodeInfo: c.nodeInfo(),
+
+			// Submitted headers skip the ingress guard,
+			// as they come from the operator rather than a peer.
+			ProposedHeaderHandler: e,
+			ConsensusCodec:        codec,
+		}); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// Stop is called when the SDK is shutting down the server components.
+// Stop is called when the SDK is shutting down the server components,
+// such as when the start command receives SIGINT or SIGTERM.
+//
+// Before canceling the other subsystems, Stop lets the driver
+// finish a block finalization in progress, for up to the shutdown timeout,
+// so that the app store is not left partway through a block.
 func (c *Component) Stop(_ context.Context) error {
-	c.cancel(errors.New("stopped via SDK server module"))
+	stopStart := time.Now()
+	stopCause := errors.New("stopped via SDK server module")
+	c.stops.ShuttingDown(stopCause)
+
+	drained := c.drainDriver()
+	c.cancel(stopCause)
 
 	// Stop serving client requests before anything else.
 	if c.dh != nil {
 		c.dh.Wait()
 	}
+	if c.follower != nil {
+		c.follower.Wait()
+	}
 
 	if c.e != nil {
 		c.e.Wait()
 	}
+	if c.roundStore != nil {
+		// Flushes any queued writes before the store is closed.
+		c.roundStore.Wait()
+	}
 	if c.driver != nil {
 		c.driver.Wait()
 	}
+	if c.daQueue != nil {
+		c.daQueue.Wait()
+	}
+	if c.peerBarrier != nil {
+		c.peerBarrier.Wait()
+	}
 	if c.cStrat != nil {
 		c.cStrat.Wait()
 	}
 	if c.conn != nil {
 		c.conn.Disconnect()
 	}
+	if c.chaos != nil {
+		c.chaos.Wait()
+	}
+	if c.replayDone != nil {
+		<-c.replayDone
+	}
+	if c.recordFile != nil {
+		if err := c.recordFile.Close(); err != nil {
+			c.log.Warn("Error closing consensus record file", "err", err)
+		}
+	}
+	if c.audit != nil {
+		if err := c.audit.Close(); err != nil {
+			c.log.Warn("Error closing audit log", "err", err)
+		}
+	}
 	if c.h != nil {
 		if err := c.h.Close(); err != nil {
 			c.log.Warn("Error closing tmp2p host", "err", err)
@@ -638,6 +1146,13 @@ func (c *Component) Stop(_ context.Context) error {
 			c.httpServer.Wait()
 		}
 	}
+	if c.sharedHTTP != nil {
+		// The server detaches from the shared host once the root context is canceled.
+		if c.httpServer != nil {
+			c.httpServer.Wait()
+		}
+		c.releaseSharedHTTP()
+	}
 	if c.grpcLn != nil {
 		if err := c.grpcLn.Close(); err != nil {
 			// If the GRPC server is closed directly,
@@ -657,21 +1172,272 @@ func (c *Component) Stop(_ context.Context) error {
 			c.log.Warn("Error closing tmsqlite store", "err", err)
 		}
 	}
+	if c.hsmToken != nil {
+		if c.hsmDone != nil {
+			<-c.hsmDone
+		}
+		if err := c.hsmToken.Close(); err != nil {
+			c.log.Warn("Error closing PKCS#11 token", "err", err)
+		}
+	}
+	if c.pgDB != nil {
+		if err := c.pgDB.Close(); err != nil {
+			c.log.Warn("Error closing PostgreSQL database", "err", err)
+		}
+	}
 
 	if err := c.app.Store().Close(); err != nil {
 		c.log.Warn("Failed to close root store", "err", err)
 	}
+
+	entries := c.stops.Entries()
+	nUnexpected := 0
+	for _, e := range entries {
+		if e.Unexpected {
+			nUnexpected++
+			c.log.Warn(
+				"Subsystem had stopped before shutdown",
+				"subsystem", e.Subsystem, "stopped_at", e.StoppedAt, "cause", e.Cause,
+			)
+		}
+	}
+
+	wm := c.watermarks.Latest()
+	c.log.Info(
+		"Shutdown complete",
+		"dur", time.Since(stopStart),
+		"driver_drained", drained,
+		"finalized_height", wm.FinalizedHeight,
+		"voting_height", wm.VotingHeight,
+		"voting_round", wm.VotingRound,
+		"subsystems_stopped", len(entries),
+		"subsystems_stopped_early", nUnexpected,
+		"still_running", c.stops.Running(),
+	)
 	return nil
 }
 
+// drainDriver lets the driver finish its in-flight work,
+// reporting whether it stopped within the shutdown timeout.
+func (c *Component) drainDriver() bool {
+	if c.driver == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeoutCause(
+		context.Background(), c.shutdownTimeout,
+		errors.New("shutdown timeout elapsed"),
+	)
+	defer cancel()
+
+	if err := c.driver.Drain(ctx); err != nil {
+		c.log.Warn(
+			"Driver did not finish in-flight work before shutdown timeout; canceling",
+			"timeout", c.shutdownTimeout, "err", err,
+		)
+		return false
+	}
+	return true
+}
+
+// SwapGossipStrategy replaces the engine's gossip strategy with one created by f,
+// without restarting the engine.
+// The old strategy is stopped before the new one starts,
+// and the new strategy first receives the latest network view.
+// It is an error to call SwapGossipStrategy before Start,
+// or on a node in header-only mode.
+func (c *Component) SwapGossipStrategy(ctx context.Context, f ggossip.StrategyFactory) error {
+	if c.gossip == nil {
+		return errors.New("no gossip strategy running")
+	}
+	return c.gossip.Swap(ctx, f)
+}
+
+// SetSignatureVerifier makes the component check the signatures in vote and commit proofs
+// through v instead of in pure Go,
+// so that platforms with hardware acceleration or a remote verification service can use it.
+// SetSignatureVerifier must be called before Start.
+func (c *Component) SetSignatureVerifier(v gcverify.SignatureVerifier) {
+	c.sigVerifier = v
+}
+
+// proofScheme returns the signature proof scheme
+// for the engine and every other consumer of vote and commit proofs.
+func (c *Component) proofScheme() gcrypto.CommonMessageSignatureProofScheme {
+	if c.sigVerifier == nil {
+		return gcrypto.SimpleCommonMessageSignatureProofScheme
+	}
+	return gcverify.ProofScheme(c.sigVerifier)
+}
+
+// VerifyCommit reports an error unless proof commits blockHash at height,
+// using the validator set in the component's committed header store.
+// See [*gcverify.Verifier.VerifyCommit] for the details.
+func (c *Component) VerifyCommit(
+	ctx context.Context, height uint64, blockHash []byte, proof tmconsensus.CommitProof,
+) error {
+	return gcverify.NewVerifier(gcverify.VerifierConfig{
+		Store:                             c.chs,
+		SignatureScheme:                   c.sigScheme,
+		CommonMessageSignatureProofScheme: c.proofScheme(),
+	}).VerifyCommit(ctx, height, blockHash, proof)
+}
+
+// nodeInfo returns the build and configuration details
+// reported by the HTTP server's node info endpoint.
+func (c *Component) nodeInfo() gsi.NodeInfo {
+	return gsi.NodeInfo{
+		Build: gsi.ReadBuildInfo(),
+
+		SchemeVersions: gsi.KnownSchemeVersions(),
+		SchemeUpgrades: c.schedule.Upgrades(),
+		WireUpgrades:   c.wireSchedule.Upgrades(),
+
+		Features: map[string]bool{
+			"header_only":               c.headerOnly,
+			"hsm_signer":                c.hsmSigner != nil,
+			"signature_verifier":        c.sigVerifier != nil,
+			"gossip_sign":               c.gossipSign,
+			"gossip_require_signatures": c.gossipRequireSigs,
+			"compact_commit_proofs":     c.compactCommitProofs,
+			"block_builder":             c.blockBuilder != nil,
+			"da_publisher":              c.daPublisher != nil,
+			"skip_empty_blocks":         c.emptyBlocks.Wait() > 0,
+			"commit_wait_skip_full":     c.precommits != nil,
+			"stall_detection":           c.stallThreshold > 0,
+			"clock_skew_monitor":        c.clockSkewThreshold > 0,
+			"heartbeats":                c.heartbeatInterval > 0,
+			"keep_incompatible_peers":   c.keepIncompatiblePeers,
+			"telemetry":                 c.telemetryURL != "",
+			"chaos":                     c.chaos != nil,
+			"postgres":                  c.pgStore != nil,
+			"bounded_block_data_cache":  c.bdrCacheCfg.MaxBytes > 0,
+			"ingress_dedupe":            c.dedupeCfg.Window > 0,
+			"upgrade_readiness":         len(c.readyFeatures) > 0,
+			"round_store_retry":         c.roundStore != nil,
+			"action_retention":          c.actions != nil,
+			"shared_http":               c.sharedHTTP != nil,
+			"rpc_auth":                  c.rpcTokens.Enabled(),
+			"address_watch":             c.watch != nil,
+			"round_history":             c.roundHistory != nil,
+		},
+	}
+}
+
+// StopReport returns an entry for each subsystem started by Start
+// that has since stopped, in the order they stopped.
+// An entry marked Unexpected indicates a subsystem
+// that stopped before the component was stopped.
+func (c *Component) StopReport() []gstop.Entry {
+	return c.stops.Entries()
+}
+
+// Watermarks returns a channel receiving the node's current heights,
+// and then every change to them, until ctx is canceled.
+// Voting and committing heights are updated as the engine's network view changes,
+// and the finalized height after the driver finalizes each block.
+func (c *Component) Watermarks(ctx context.Context) <-chan gwatermark.Watermark {
+	return c.watermarks.Subscribe(ctx)
+}
+
 const (
 	httpAddrFlag     = "g-http-addr"
 	grpcAddrFlag     = "g-grpc-addr"
 	httpAddrFileFlag = "g-http-addr-file"
 
+	httpMaxPageSizeFlag = "g-http-max-page-size"
+
+	httpSharedFlag   = "g-http-shared"
+	rpcTokenFileFlag = "g-rpc-token-file"
+
 	seedAddrsFlag = "g-seed-addrs"
 
 	sqlitePathFlag = "g-sqlite-path"
+
+	actionRetainHeightsFlag = "g-action-retain-heights"
+
+	blockDataKeyFileFlag = "g-block-data-key-file"
+
+	pkcs11ModuleFlag            = "g-pkcs11-module"
+	pkcs11TokenLabelFlag        = "g-pkcs11-token"
+	pkcs11KeyLabelFlag          = "g-pkcs11-key-label"
+	pkcs11SlowSignThresholdFlag = "g-pkcs11-slow-sign-threshold"
+
+	postgresDSNFlag         = "g-postgres-dsn"
+	postgresTablePrefixFlag = "g-postgres-table-prefix"
+	postgresDurabilityFlag  = "g-postgres-durability"
+
+	blockDataS3EndpointFlag = "g-block-data-s3-endpoint"
+	blockDataS3BucketFlag   = "g-block-data-s3-bucket"
+	blockDataS3RegionFlag   = "g-block-data-s3-region"
+	blockDataS3PrefixFlag   = "g-block-data-s3-prefix"
+	blockDataCacheSizeFlag  = "g-block-data-cache-size"
+
+	timeoutEscalationFlag = "g-timeout-escalation"
+	timeoutFactorFlag     = "g-timeout-factor"
+	timeoutCapFlag        = "g-timeout-cap"
+
+	commitWaitFlag          = "g-commit-wait"
+	commitWaitIncrementFlag = "g-commit-wait-increment"
+	commitWaitSkipFullFlag  = "g-commit-wait-skip-full"
+
+	createEmptyBlocksIntervalFlag = "g-create-empty-blocks-interval"
+
+	maxProposalsPerRoundFlag = "g-max-proposals-per-round"
+	maxFutureVoteHeightsFlag = "g-max-future-vote-heights"
+	ingressDedupeWindowFlag  = "g-ingress-dedupe-window"
+
+	blockDataCacheMaxBytesFlag = "g-block-data-cache-max-bytes"
+
+	blockBuilderURLFlag     = "g-block-builder-url"
+	blockBuilderTimeoutFlag = "g-block-builder-timeout"
+
+	proposalBroadcastMarginFlag = "g-proposal-broadcast-margin"
+
+	compactCommitProofsFlag = "g-compact-commit-proofs"
+
+	consensusRecordFileFlag  = "g-consensus-record-file"
+	consensusReplayFileFlag  = "g-consensus-replay-file"
+	consensusReplaySpeedFlag = "g-consensus-replay-speed"
+
+	gossipSignFlag              = "g-gossip-sign"
+	gossipRequireSignaturesFlag = "g-gossip-require-signatures"
+
+	daPublisherFlag = "g-da-publisher"
+	daGatingFlag    = "g-da-gating"
+
+	headerOnlyFlag         = "g-header-only"
+	trustedInitialHashFlag = "g-trusted-initial-hash"
+
+	minStartPeersFlag    = "g-min-start-peers"
+	startPeerTimeoutFlag = "g-start-peer-timeout"
+
+	stallThresholdFlag     = "g-stall-threshold"
+	clockSkewThresholdFlag = "g-clock-skew-threshold"
+	heartbeatIntervalFlag  = "g-heartbeat-interval"
+	readyFeaturesFlag      = "g-ready-features"
+
+	keepIncompatiblePeersFlag = "g-keep-incompatible-peers"
+
+	telemetryURLFlag      = "g-telemetry-url"
+	telemetryIntervalFlag = "g-telemetry-interval"
+
+	shutdownTimeoutFlag = "g-shutdown-timeout"
+
+	dataMigrationsFlag = "g-data-migrations"
+
+	validateOnlyFlag = "g-validate-only"
+
+	backpressureWarnThresholdFlag = "g-backpressure-warn-threshold"
+	channelSizesFlag              = "g-channel-sizes"
+
+	txSequencePolicyFlag = "g-tx-sequence-policy"
+
+	panicDumpDirFlag = "g-panic-dump-dir"
+
+	slowFinalizeThresholdFlag = "g-slow-finalize-threshold"
+
+	roundHistoryHeightsFlag = "g-round-history-heights"
 )
 
 // StartCmdFlags satisfies the optional [serverv2.HasStartFlags] interface,
@@ -686,14 +1452,88 @@ func (c *Component) StartCmdFlags() *pflag.FlagSet {
 	flags.String(httpAddrFlag, "", "TCP address of Gordian's introspective HTTP server; if blank, server will not be started")
 	flags.String(grpcAddrFlag, "", "TCP address of Gordian's introspective GRPC server; if blank, server will not be started")
 	flags.String(httpAddrFileFlag, "", "Write the actual Gordian HTTP listen address to the given file (useful for tests when configured to listen on :0)")
+	flags.Int(httpMaxPageSizeFlag, 1000, "Maximum and default number of items returned by paginated HTTP list endpoints; 0 means no limit")
+	flags.Bool(httpSharedFlag, false, "Serve the HTTP API under /chains/<chain-id>/ on a listener shared with the other chains, of the same chain type, hosted in this process with the same --"+httpAddrFlag)
+	flags.String(rpcTokenFileFlag, "", "File of bearer tokens, one per line, of which every HTTP and gRPC request must carry one; if blank, requests are not authenticated")
 
 	flags.String(seedAddrsFlag, "", "Newline-separated multiaddrs to connect to; if omitted, relies on incoming connections to discover peers")
 
+	flags.String(pkcs11ModuleFlag, "", "Path to a PKCS#11 module; if set, consensus messages are signed by the HSM key instead of the priv_validator_key.json key, using the PIN from GCOSMOS_PKCS11_PIN (requires a build with -tags pkcs11)")
+	flags.String(pkcs11TokenLabelFlag, "", "Label of the PKCS#11 token holding the consensus key")
+	flags.String(pkcs11KeyLabelFlag, "", "Label of the ed25519 key pair on the PKCS#11 token")
+	flags.Duration(pkcs11SlowSignThresholdFlag, 0, "Log a warning when an HSM signature takes longer than this; 0 disables the warning")
+
+	flags.String(blockDataS3EndpointFlag, "", "Base URL of an S3-compatible object store for block data, using credentials from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY; if blank, block data is kept in memory")
+	flags.String(blockDataS3BucketFlag, "", "Bucket for block data in the S3-compatible object store")
+	flags.String(blockDataS3RegionFlag, "", "Signing region for the S3-compatible object store; defaults to us-east-1")
+	flags.String(blockDataS3PrefixFlag, "", "Prefix for block data object keys in the S3-compatible object store")
+	flags.Int(blockDataCacheSizeFlag, 128, "Number of recent heights of block data to cache in memory when using an object store")
+	flags.String(postgresDSNFlag, "", "PostgreSQL connection string; if set, block data, finalizations, and the transaction index are stored in PostgreSQL")
+	flags.String(postgresTablePrefixFlag, "", "Prefix for PostgreSQL table names, allowing several chains to share a schema; defaults to gcosmos_")
+	flags.String(postgresDurabilityFlag, "sync", "Commit durability for PostgreSQL block data and transaction index writes: sync (flush every commit) or periodic (flush in the background; a crash may lose recent, recoverable writes); finalizations are always synchronous")
+	flags.String(blockDataKeyFileFlag, "", "Path to a file containing a hex-encoded AES key used to encrypt stored block data; if blank, block data is stored unencrypted")
 	flags.String(sqlitePathFlag, "", "Path to Gordian's consensus database; if blank, uses primitive in-memory store; if the exact string :memory:, uses SQLite in-memory database; otherwise path to on-disk SQLite database")
+	flags.Uint64(actionRetainHeightsFlag, 0, fmt.Sprintf("If set, record signed proposals and votes under data/actions instead of the consensus database, keeping only this many recent heights (at least %d); 0 keeps every action in the consensus database", gaction.MinRetainHeights))
+
+	flags.String(timeoutEscalationFlag, string(gsi.TimeoutEscalationLinear), "How consensus timeouts grow as rounds increase; either linear or exponential")
+	flags.Float64(timeoutFactorFlag, 0, "Per-round timeout multiplier when using exponential escalation; must be greater than 1, or 0 to use the default of 1.5")
+	flags.Duration(timeoutCapFlag, 0, "Upper bound on any single consensus timeout; 0 means no upper bound")
+	flags.Duration(commitWaitFlag, 0, "How long to keep collecting precommits after committing a block in round 0, before moving to the next height; 0 uses the engine default of 2s")
+	flags.Duration(commitWaitIncrementFlag, 0, "How much the commit wait grows per round; 0 uses the engine default of 500ms")
+	flags.Bool(commitWaitSkipFullFlag, false, "Skip the commit wait when precommits from every validator have already been seen as the wait begins")
+	flags.Duration(createEmptyBlocksIntervalFlag, 0, "How long the proposer waits for a transaction before proposing an empty block in the first round of a height; every validator must use the same value, as it also extends the first round's proposal timeout; 0 proposes immediately")
+
+	flags.Int(maxProposalsPerRoundFlag, 0, "Maximum distinct proposed blocks to accept from peers in a single round, with at most one per proposer; 0 means no limit")
+	flags.Uint64(maxFutureVoteHeightsFlag, 0, "Reject votes from peers for heights more than this many heights beyond the committing height; 0 means no limit")
+	flags.Duration(ingressDedupeWindowFlag, gingress.DefaultDedupeWindow, "How long to drop identical copies of a proposed block or vote already accepted from another peer; 0 disables deduplication")
+	flags.Int64(blockDataCacheMaxBytesFlag, 0, "Maximum bytes of proposed block data to hold in memory for heights beyond the next one to finalize; 0 means no limit")
+
+	flags.String(blockBuilderURLFlag, "", "URL of an external block builder to request proposed block contents from; if blank, proposals use the local mempool")
+	flags.Duration(blockBuilderTimeoutFlag, 0, "How long to wait for the external block builder before falling back to the local mempool; 0 means half of the block building budget")
+	flags.Duration(proposalBroadcastMarginFlag, 0, "How much of the proposal timeout to reserve for our proposal to propagate through the network, shortening the time available to build it; 0 reserves one fifth of the proposal timeout")
+
+	flags.Bool(compactCommitProofsFlag, false, "Drop commit proof signatures beyond those needed for a two-thirds majority before storing committed headers")
+
+	flags.String(consensusRecordFileFlag, "", "Append every inbound consensus message, with its receipt time, to this replay file")
+	flags.String(consensusReplayFileFlag, "", "Feed the consensus messages in this replay file to the engine instead of connecting to the network; use a fresh home directory")
+	flags.Float64(consensusReplaySpeedFlag, 1, "Replay speed relative to the recorded timing; 0 replays messages without delay")
+
+	flags.Bool(gossipSignFlag, false, "Sign outgoing consensus messages with the node's libp2p identity key, so relayed messages can be attributed to this node")
+	flags.Bool(gossipRequireSignaturesFlag, false, "Ignore incoming consensus messages that are not signed by their originating node")
+
+	flags.String(daPublisherFlag, "", "Data availability layer to publish committed blocks to, for rollups; if blank, blocks are not published; mem uses an in-memory layer for testing")
+	flags.String(daGatingFlag, "optimistic", "Whether finalizing a block waits for DA publication; either optimistic or wait")
+
+	flags.Bool(headerOnlyFlag, false, "Only fetch and verify committed headers from peers, without running consensus or executing blocks")
+	flags.String(trustedInitialHashFlag, "", "Hex-encoded hash of the initial header, used as the root of trust in header-only mode; if blank, the first header received is trusted")
+
+	flags.Int(minStartPeersFlag, 0, "Number of connected peers required before starting consensus at the initial height; 0 means do not wait for peers")
+	flags.Bool(validateOnlyFlag, false, "Load the configuration, genesis, keys and stores, print a report of consistency checks, and exit without joining the network; exits non-zero if any check fails")
+	flags.String(dataMigrationsFlag, string(gmigrate.ModeAuto), "Whether to upgrade an older data directory layout at startup (auto), or fail so that it can be backed up first (refuse)")
+	flags.Duration(shutdownTimeoutFlag, 30*time.Second, "How long shutdown waits for an in-progress block finalization to complete before canceling it")
+	flags.Duration(stallThresholdFlag, 0, "Report a consensus stall when the voting height and round do not advance for this long; 0 disables stall detection")
+	flags.Duration(heartbeatIntervalFlag, gliveness.DefaultInterval, "How often to send connected peers a heartbeat with this node's height, round and step; 0 disables heartbeats")
+	flags.String(readyFeaturesFlag, "", "Comma-separated upgrade features this validator signals readiness for in heartbeats, tallied by voting power on each node's /upgrade_readiness endpoint")
+	flags.Bool(keepIncompatiblePeersFlag, false, "Stay connected to peers that share no version of the block, gossip or sync protocol, without using those protocols with them; by default such peers are disconnected")
+	flags.Duration(clockSkewThresholdFlag, 0, "Exchange signed timestamps with peers and warn about peers whose clocks differ from this node's by more than this; 0 disables the exchange")
+	flags.String(telemetryURLFlag, "", "Opt in to posting anonymized network statistics (heights, round duration percentiles, peer count and version) as JSON to this http or https URL; if blank, nothing is reported")
+	flags.Duration(telemetryIntervalFlag, gtelemetry.DefaultInterval, "How often to post statistics to --g-telemetry-url")
+	flags.Duration(backpressureWarnThresholdFlag, 0, "Log a warning when handing work to the engine blocks for longer than this; 0 disables the warning")
+	flags.String(channelSizesFlag, "", "Advanced: comma-separated name=size channel buffer sizes (finalize_block_requests, replayed_header_requests, catchup_peer_requests); unset channels keep their defaults")
+	flags.Duration(startPeerTimeoutFlag, 0, "How long to wait for the minimum start peers before starting consensus anyway; 0 means wait indefinitely")
+
+	flags.String(txSequencePolicyFlag, "reject", "How to handle a submitted transaction reusing the signer sequence of a pending transaction; either reject or replace-by-fee")
+
+	flags.Duration(slowFinalizeThresholdFlag, 0, "Capture CPU and heap profiles under data/profiles in the home directory when delivering a block to the app takes longer than this; 0 disables capturing")
+	flags.Int(roundHistoryHeightsFlag, gsi.DefaultRoundHistoryHeights, "Number of recent heights whose round summaries are kept in memory for the /round_history HTTP endpoint; 0 disables the history")
+	flags.String(panicDumpDirFlag, "", "Directory for state dumps written when a recovered panic shuts down the node; if blank, defaults to data/panics in the home directory")
 
 	// Adds --g-assert-rules in debug builds, no-op otherwise.
 	addAssertRuleFlag(flags)
 
+	// Adds --g-chaos in debug builds, no-op otherwise.
+	addChaosFlag(flags)
+
 	return flags
 }
 
@@ -713,6 +1553,8 @@ func (c *Component) CLICommands() serverv2.CLIConfig {
 			// These commands are all declared in commands.go.
 			newSeedCommand(),
 			newPrintValPubKeyCommand(),
+			newBroadcastTxCommand(),
+			newGordianCommand(),
 		},
 	}
 }
@@ -0,0 +1,78 @@
+package gserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gp2papi"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	libp2pevent "github.com/libp2p/go-libp2p/core/event"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+)
+
+// startCatchupClient starts the client fetching committed blocks from peers on c.h
+// for the replayed header requests sent to rhCh,
+// and keeps its set of peers current as peers connect and disconnect.
+func (c *Component) startCatchupClient(
+	ctx context.Context,
+	codec tmjson.MarshalCodec,
+	bdrCache *gsbd.RequestCache,
+	rhCh chan tmelink.ReplayedHeaderRequest,
+) (*gp2papi.CatchupClient, error) {
+	var peerHasCommittedHeader func(libp2ppeer.ID, uint64) bool
+	if c.heartbeats != nil {
+		peerHasCommittedHeader = c.heartbeats.HasCommittedHeader
+	}
+
+	catchupClient := gp2papi.NewCatchupClient(
+		ctx,
+		c.log.With("d_sys", "catchup_client"),
+		gp2papi.CatchupClientConfig{
+			Host:               c.h.Libp2pHost(),
+			Unmarshaler:        codec,
+			TxDecoder:          c.txc,
+			RequestCache:       bdrCache,
+			ReplayedHeadersOut: rhCh,
+			ValidatorStore:     c.vs,
+
+			PeerRequestBuffer:   c.chanSizes.CatchupPeerRequests,
+			ReplayedHeadersPath: c.backpressure.Path("replayed_headers"),
+
+			PeerHasCommittedHeader: peerHasCommittedHeader,
+		},
+	)
+	c.stops.Watch(ctx, "catchup_client", catchupClient.Wait)
+
+	sub, err := c.h.Libp2pHost().EventBus().Subscribe(new(libp2pevent.EvtPeerConnectednessChanged))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to libp2p host's peer connectedness events: %w", err)
+	}
+	c.stops.Started("peer_events")
+	go func() {
+		defer sub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				c.stops.Stopped(ctx, "peer_events", nil)
+				return
+			case e := <-sub.Out():
+				switch e := e.(type) {
+				case libp2pevent.EvtPeerConnectednessChanged:
+					if e.Connectedness == libp2pnetwork.Connected {
+						catchupClient.AddPeer(ctx, e.Peer)
+					} else if e.Connectedness == libp2pnetwork.NotConnected {
+						catchupClient.RemovePeer(ctx, e.Peer)
+					}
+				default:
+					c.log.Warn("Unknown peer connectedness event type", "type", fmt.Sprintf("%T", e))
+				}
+			}
+		}
+	}()
+
+	return catchupClient, nil
+}
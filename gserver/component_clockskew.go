@@ -0,0 +1,46 @@
+package gserver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gclock"
+)
+
+// initializeClockSkew parses the clock skew threshold;
+// the monitor is disabled unless it is positive.
+func (c *Component) initializeClockSkew(cfg map[string]any) error {
+	if s := flagString(cfg, clockSkewThresholdFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", clockSkewThresholdFlag, s)
+		}
+		c.clockSkewThreshold = d
+	}
+	return nil
+}
+
+// startClockSkewMonitor starts exchanging signed timestamps with peers on c.h,
+// if the monitor is enabled.
+func (c *Component) startClockSkewMonitor() error {
+	if c.clockSkewThreshold <= 0 {
+		return nil
+	}
+
+	lh := c.h.Libp2pHost()
+	m, err := gclock.NewMonitor(
+		c.rootCtx,
+		c.log.With("sys", "clock_skew"),
+		gclock.MonitorConfig{
+			Host:      lh,
+			Identity:  lh.Peerstore().PrivKey(lh.ID()),
+			Threshold: c.clockSkewThreshold,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start clock skew monitor: %w", err)
+	}
+	c.clockSkew = m
+	c.stops.Watch(c.rootCtx, "clock_skew", c.clockSkew.Wait)
+	return nil
+}
@@ -0,0 +1,43 @@
+package gserver
+
+import (
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gda"
+)
+
+// initializeDA parses the rollup mode settings.
+// A blank publisher means the chain is not a rollup.
+func (c *Component) initializeDA(cfg map[string]any) error {
+	switch s := flagString(cfg, daPublisherFlag); s {
+	case "":
+		// Not a rollup.
+	case "mem":
+		c.daPublisher = gda.NewMemPublisher(0)
+	default:
+		return fmt.Errorf("invalid value for %s: %q (must be blank or mem)", daPublisherFlag, s)
+	}
+
+	g, err := gda.ParseGating(flagString(cfg, daGatingFlag))
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", daGatingFlag, err)
+	}
+	c.daGating = g
+
+	return nil
+}
+
+// startDAQueue starts the queue publishing committed blocks
+// to the data availability layer, if one is configured.
+// c.daQueue remains nil otherwise.
+func (c *Component) startDAQueue() {
+	if c.daPublisher == nil {
+		return
+	}
+
+	c.daQueue = gda.NewQueue(c.rootCtx, c.log.With("sys", "da_queue"), gda.QueueConfig{
+		Publisher: c.daPublisher,
+		Gating:    c.daGating,
+	})
+	c.stops.Watch(c.rootCtx, "da_queue", c.daQueue.Wait)
+}
@@ -3,6 +3,7 @@
 package gserver
 
 import (
+	"github.com/gordian-engine/gcosmos/gserver/internal/gchaos"
 	"github.com/gordian-engine/gordian/gassert"
 	"github.com/gordian-engine/gordian/tm/tmengine"
 	"github.com/spf13/pflag"
@@ -15,11 +16,24 @@ func addAssertRuleFlag(fs *pflag.FlagSet) {
 	fs.String(assertRuleFlag, "*", "Comma-separated assertion rules. Only available in debug builds. See package docs for github.com/gordian-engine/gordian/gassert.")
 }
 
-func getAssertEngineOpt(cfg map[string]any) (tmengine.Opt, error) {
+// getAssertEngineOpt returns the engine option to set the assertion environment,
+// along with the environment itself for use outside the engine.
+func getAssertEngineOpt(cfg map[string]any) (tmengine.Opt, gassert.Env, error) {
 	rules := cfg[assertRuleFlag].(string)
 	env, err := gassert.EnvironmentFromString(rules)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return tmengine.WithAssertEnv(env), nil
+	return tmengine.WithAssertEnv(env), env, nil
+}
+
+const chaosFlag = "g-chaos"
+
+func addChaosFlag(fs *pflag.FlagSet) {
+	fs.String(chaosFlag, "", "Comma-separated fault injection settings for incoming consensus messages, e.g. seed=1,dup=5,delay=10,max-delay=200ms. Only available in debug builds.")
+}
+
+func getChaosConfig(cfg map[string]any) (gchaos.Config, error) {
+	s, _ := cfg[chaosFlag].(string)
+	return gchaos.ParseConfig(s)
 }
@@ -0,0 +1,69 @@
+package gserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gevidence"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gfork"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+)
+
+// initializeForkRecorder opens the fork report in the data directory,
+// refusing to start if a previous run recorded an app hash mismatch.
+func (c *Component) initializeForkRecorder(homeDir string) error {
+	forks, err := gfork.NewRecorder(
+		c.log.With("sys", "fork_detector"),
+		filepath.Join(homeDir, "data", "fork_report.json"),
+	)
+	if err != nil {
+		return err
+	}
+	if rep, ok := forks.Report(); ok {
+		return fmt.Errorf(
+			"refusing to start: app hash mismatch after height %d was recorded at %s; "+
+				"investigate the fork, restore a correct data directory, and then remove the report",
+			rep.Height, forks.Path(),
+		)
+	}
+	c.forks = forks
+	return nil
+}
+
+// startForkEvidence starts exchanging evidence of conflicting commits with peers on c.h.
+func (c *Component) startForkEvidence(codec tmjson.MarshalCodec) {
+	c.forkEvidence = gevidence.NewExchange(
+		c.rootCtx,
+		c.log.With("sys", "fork_evidence"),
+		gevidence.ExchangeConfig{
+			Host:  c.h.Libp2pHost(),
+			Codec: codec,
+
+			Verifier: gcverify.NewVerifier(gcverify.VerifierConfig{
+				Store:                             c.chs,
+				SignatureScheme:                   c.sigScheme,
+				CommonMessageSignatureProofScheme: c.proofScheme(),
+			}),
+			HashScheme: c.hashScheme,
+
+			TooOld: c.evidenceTooOld,
+		},
+	)
+	c.stops.Watch(c.rootCtx, "fork_evidence", c.forkEvidence.Wait)
+}
+
+// evidenceTooOld reports whether fork evidence at height
+// is older than the maximum evidence age at the committing height.
+func (c *Component) evidenceTooOld(ctx context.Context, height uint64) bool {
+	_, _, committingHeight, _, err := c.ms.NetworkHeightRound(ctx)
+	if err != nil {
+		// Evidence is rare and important, so accept it rather than drop it.
+		c.log.Warn("Failed to get committing height for evidence age check", "err", err)
+		return false
+	}
+
+	maxAge := c.params.At(committingHeight).EvidenceMaxAgeBlocks
+	return maxAge > 0 && height+maxAge < committingHeight
+}
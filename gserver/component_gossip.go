@@ -0,0 +1,131 @@
+package gserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/ggossip"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gmsgauth"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gstall"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwire"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/gordian-engine/gordian/tm/tmgossip"
+	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p"
+)
+
+// initializeGossip parses the settings for signing gossiped consensus messages
+// and for detecting a stalled network.
+func (c *Component) initializeGossip(cfg map[string]any) error {
+	c.gossipSign = flagString(cfg, gossipSignFlag) == "true"
+	c.gossipRequireSigs = flagString(cfg, gossipRequireSignaturesFlag) == "true"
+
+	if s := flagString(cfg, stallThresholdFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", stallThresholdFlag, s)
+		}
+		c.stallThreshold = d
+	}
+
+	return nil
+}
+
+// gossipCodec returns the codec for gossiped consensus messages,
+// which encodes messages per the wire schedule,
+// wrapped with message authentication if configured.
+func (c *Component) gossipCodec(h *tmlibp2p.Host, codec tmjson.MarshalCodec) (tmcodec.MarshalCodec, error) {
+	wc, err := gwire.NewCodec(gwire.CodecConfig{
+		Encodings: gwire.Encodings(codec),
+		Schedule:  c.wireSchedule,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wire encoding codec: %w", err)
+	}
+
+	if !c.gossipSign && !c.gossipRequireSigs {
+		return wc, nil
+	}
+
+	cfg := gmsgauth.CodecConfig{
+		Inner:             wc,
+		RequireSignatures: c.gossipRequireSigs,
+		OnInvalid: func(err error, msg []byte) {
+			c.log.Info("Ignoring consensus message that failed authentication", "err", err)
+
+			// An unsigned message is not attributable to anyone,
+			// but a bad signature may indicate a forging or faulty peer.
+			var e gmsgauth.InvalidSignatureError
+			if errors.As(err, &e) {
+				c.audit.InvalidSignature(e.Origin.String(), msg)
+			}
+		},
+	}
+	if c.gossipSign {
+		lh := h.Libp2pHost()
+		cfg.Identity = lh.Peerstore().PrivKey(lh.ID())
+		if cfg.Identity == nil {
+			return nil, errors.New("libp2p host has no identity key for signing consensus messages")
+		}
+	}
+
+	mc, err := gmsgauth.NewCodec(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consensus message authentication codec: %w", err)
+	}
+	return mc, nil
+}
+
+// startGossip returns the engine's gossip strategy over c.conn.
+// The strategy is swappable without restarting the engine,
+// feeds network view updates to the subsystems observing them,
+// and is wrapped in the stall detector if one is configured.
+func (c *Component) startGossip(ctx context.Context, bdrCache *gsbd.RequestCache) (tmgossip.Strategy, error) {
+	conn := c.conn
+	c.gossip = ggossip.NewSwappableStrategy(ctx, c.log.With("sys", "gossip"), func(ctx context.Context) tmgossip.Strategy {
+		return tmgossip.NewChattyStrategy(ctx, c.log.With("sys", "chattygossip"), conn)
+	})
+	c.gossip.SetForwardPath(c.backpressure.Path("gossip_out"))
+
+	viewObservers := []func(tmelink.NetworkViewUpdate){c.watermarks.UpdateView}
+	if c.precommits != nil {
+		viewObservers = append(viewObservers, c.precommits.UpdateView)
+	}
+	if c.telemetry != nil {
+		viewObservers = append(viewObservers, c.telemetry.UpdateView)
+	}
+	if c.roundHistory != nil {
+		viewObservers = append(viewObservers, c.roundHistory.UpdateView)
+	}
+	c.gossip.SetViewObserver(func(u tmelink.NetworkViewUpdate) {
+		for _, o := range viewObservers {
+			o(u)
+		}
+	})
+
+	var gs tmgossip.Strategy = c.gossip
+	if c.stallThreshold > 0 {
+		var err error
+		c.stall, err = gstall.NewDetector(ctx, c.log.With("sys", "stall"), gstall.DetectorConfig{
+			Inner:     c.gossip,
+			Threshold: c.stallThreshold,
+
+			InFlightFetches: bdrCache.InFlight,
+			PeerCount: func() int {
+				return len(c.h.Libp2pHost().Network().Peers())
+			},
+			ValidatorName: c.valBook.Name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stall detector: %w", err)
+		}
+		gs = c.stall
+	}
+	c.stops.Watch(ctx, "gossip", gs.Wait)
+
+	return gs, nil
+}
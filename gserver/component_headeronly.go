@@ -0,0 +1,78 @@
+package gserver
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gp2papi"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+)
+
+// initializeHeaderOnly parses whether the node only follows committed headers,
+// and the hash of the initial header to trust when it does.
+func (c *Component) initializeHeaderOnly(cfg map[string]any) error {
+	c.headerOnly = flagString(cfg, headerOnlyFlag) == "true"
+
+	if s := flagString(cfg, trustedInitialHashFlag); s != "" {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", trustedInitialHashFlag, err)
+		}
+		c.trustedInitialHash = b
+	}
+
+	return nil
+}
+
+// startHeaderFollower finishes Start for a node in header-only mode,
+// which verifies and stores committed headers from peers
+// without running the engine or executing blocks.
+func (c *Component) startHeaderFollower(ctx context.Context, codec tmjson.MarshalCodec) error {
+	c.follower = gp2papi.NewHeaderFollower(
+		c.rootCtx,
+		c.log.With("sys", "header_follower"),
+		gp2papi.HeaderFollowerConfig{
+			Host:        c.h.Libp2pHost(),
+			Unmarshaler: codec,
+
+			HashScheme:                        c.hashScheme,
+			SignatureScheme:                   c.sigScheme,
+			CommonMessageSignatureProofScheme: c.proofScheme(),
+
+			Store: c.chs,
+
+			InitialHeight:      1,
+			TrustedInitialHash: c.trustedInitialHash,
+		},
+	)
+	c.stops.Watch(c.rootCtx, "header_follower", c.follower.Wait)
+
+	if c.httpLn != nil || c.sharedHTTP != nil {
+		if err := c.startHTTPServer(ctx, gsi.HTTPServerConfig{
+			MaxPageSize: c.httpMaxPageSize,
+
+			MirrorStore:          c.ms,
+			FinalizationStore:    c.fs,
+			CommittedHeaderStore: c.chs,
+
+			CryptoRegistry: c.reg,
+
+			Libp2pHost: c.h,
+
+			ForkEvidence:  c.forkEvidence,
+			AuditLog:      c.audit,
+			Readiness:     c.readiness,
+			ValidatorBook: c.valBook,
+
+			NodeInfo: c.nodeInfo(),
+
+			ConsensusCodec: codec,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
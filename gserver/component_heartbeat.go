@@ -0,0 +1,137 @@
+package gserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gliveness"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gready"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+)
+
+// initializeHeartbeats parses the heartbeat interval
+// and the upgrade features advertised in heartbeats.
+func (c *Component) initializeHeartbeats(cfg map[string]any) error {
+	c.heartbeatInterval = gliveness.DefaultInterval
+	if s := flagString(cfg, heartbeatIntervalFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", heartbeatIntervalFlag, s)
+		}
+		c.heartbeatInterval = d
+	}
+
+	if s := flagString(cfg, readyFeaturesFlag); s != "" {
+		for _, f := range strings.Split(s, ",") {
+			c.readyFeatures = append(c.readyFeatures, strings.TrimSpace(f))
+		}
+		if err := gready.ValidateFeatures(c.readyFeatures); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", readyFeaturesFlag, err)
+		}
+	}
+
+	return nil
+}
+
+// startHeartbeats starts exchanging heartbeats with peers on c.h,
+// unless heartbeats are disabled.
+func (c *Component) startHeartbeats() {
+	if c.heartbeatInterval <= 0 {
+		return
+	}
+
+	c.readiness = gready.NewTracker(gready.TrackerConfig{Registry: c.reg})
+	c.heartbeats = gliveness.NewHeartbeater(
+		c.rootCtx,
+		c.log.With("sys", "heartbeat"),
+		gliveness.HeartbeaterConfig{
+			Host:     c.h.Libp2pHost(),
+			Interval: c.heartbeatInterval,
+			Status:   c.heartbeatStatus,
+			OnStatus: c.recordPeerReadiness,
+		},
+	)
+	c.stops.Watch(c.rootCtx, "heartbeat", c.heartbeats.Wait)
+}
+
+// heartbeatStatus returns the status sent in heartbeats to peers.
+func (c *Component) heartbeatStatus() gliveness.Status {
+	w := c.watermarks.Latest()
+	st := gliveness.Status{
+		Height:          w.VotingHeight,
+		Round:           w.VotingRound,
+		Step:            gliveness.StepVoting,
+		FinalizedHeight: w.FinalizedHeight,
+	}
+
+	if c.headerOnly {
+		st.Step = gliveness.StepFollowing
+	} else if d := c.liveDriver.Load(); d != nil && d.FinalizingHeight() != 0 {
+		st.Step = gliveness.StepFinalizing
+	}
+
+	if c.readySigner != nil && !c.headerOnly {
+		st.Readiness = c.currentReadySignal(st.Height)
+	}
+
+	return st
+}
+
+// currentReadySignal returns the readiness signal to send in heartbeats,
+// signing a new one once per voting height
+// so that peers can tell a current signal from a stale one.
+// If signing fails, it returns the previous signal, which may be nil.
+func (c *Component) currentReadySignal(height uint64) *gready.Signal {
+	prev := c.readySignal.Load()
+	if prev != nil && prev.Height == height {
+		return prev
+	}
+
+	ctx, cancel := context.WithTimeout(c.rootCtx, time.Second)
+	defer cancel()
+
+	s, err := gready.NewSignal(ctx, c.reg, c.readySigner, height, c.readyFeatures)
+	if err != nil {
+		c.log.Warn("Failed to sign upgrade readiness signal", "err", err)
+		return prev
+	}
+	c.readySignal.Store(&s)
+
+	// Peers do not send our own signal back, so tally it directly.
+	if err := c.recordReadiness(ctx, s); err != nil {
+		c.log.Debug("Failed to record own upgrade readiness signal", "err", err)
+	}
+
+	return &s
+}
+
+// recordPeerReadiness records the readiness signal in a peer's heartbeat, if any.
+func (c *Component) recordPeerReadiness(p libp2ppeer.ID, st gliveness.Status) {
+	if st.Readiness == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.rootCtx, time.Second)
+	defer cancel()
+
+	if err := c.recordReadiness(ctx, *st.Readiness); err != nil {
+		c.log.Debug("Ignoring upgrade readiness signal", "peer_id", p, "err", err)
+	}
+}
+
+// recordReadiness verifies s against the validators at the committing height
+// and records it in the readiness tracker.
+func (c *Component) recordReadiness(ctx context.Context, s gready.Signal) error {
+	_, _, committingHeight, _, err := c.ms.NetworkHeightRound(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get committing height: %w", err)
+	}
+	_, _, valSet, _, err := c.fs.LoadFinalizationByHeight(ctx, committingHeight)
+	if err != nil {
+		return fmt.Errorf("failed to load validators at committing height: %w", err)
+	}
+
+	return c.readiness.Record(s, valSet.Validators)
+}
@@ -0,0 +1,68 @@
+package gserver
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gccrypto/gcpkcs11"
+)
+
+// How often to ping the HSM while running.
+const hsmHealthCheckInterval = 30 * time.Second
+
+// initializeHSMSigner opens the configured PKCS#11 token, if any,
+// and sets c.hsmSigner to a signer backed by it.
+func (c *Component) initializeHSMSigner(cfg map[string]any) error {
+	modulePath := flagString(cfg, pkcs11ModuleFlag)
+	if modulePath == "" {
+		return nil
+	}
+
+	var slowThreshold time.Duration
+	if s := flagString(cfg, pkcs11SlowSignThresholdFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", pkcs11SlowSignThresholdFlag, s)
+		}
+		slowThreshold = d
+	}
+
+	tok, err := gcpkcs11.OpenToken(gcpkcs11.TokenConfig{
+		ModulePath: modulePath,
+		TokenLabel: flagString(cfg, pkcs11TokenLabelFlag),
+		KeyLabel:   flagString(cfg, pkcs11KeyLabelFlag),
+		PIN:        os.Getenv("GCOSMOS_PKCS11_PIN"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open PKCS#11 token: %w", err)
+	}
+
+	s, err := gcpkcs11.NewSigner(c.log.With("sys", "hsm"), gcpkcs11.SignerConfig{
+		Token:             tok,
+		SlowSignThreshold: slowThreshold,
+	})
+	if err != nil {
+		_ = tok.Close()
+		return err
+	}
+
+	c.hsmToken = tok
+	c.hsmSigner = s
+	return nil
+}
+
+// startHSMHealthChecks pings the HSM in the background, if one is configured,
+// until the root context is canceled.
+func (c *Component) startHSMHealthChecks() {
+	if c.hsmSigner == nil {
+		return
+	}
+
+	c.hsmDone = make(chan struct{})
+	c.stops.Watch(c.rootCtx, "hsm_health", func() { <-c.hsmDone })
+	go func() {
+		defer close(c.hsmDone)
+		c.hsmSigner.RunHealthChecks(c.rootCtx, hsmHealthCheckInterval)
+	}()
+}
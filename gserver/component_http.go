@@ -0,0 +1,123 @@
+package gserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/ggrpc"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtenant"
+)
+
+// initializeAPIListeners listens for the HTTP and gRPC servers, where configured,
+// and loads the bearer tokens that their requests must carry.
+// The HTTP server either gets its own listener
+// or joins the host shared with the other chains in this process.
+func (c *Component) initializeAPIListeners(cfg map[string]any) error {
+	if httpAddr, ok := cfg[httpAddrFlag].(string); ok && httpAddr != "" {
+		var addr net.Addr
+		if flagString(cfg, httpSharedFlag) == "true" {
+			h, release, err := gtenant.Shared(c.log.With("sys", "shared_http"), httpAddr)
+			if err != nil {
+				return err
+			}
+			c.sharedHTTP = h
+			c.releaseSharedHTTP = release
+			addr = h.Addr()
+		} else {
+			ln, err := net.Listen("tcp", httpAddr)
+			if err != nil {
+				return fmt.Errorf("failed to listen for HTTP on %q: %w", httpAddr, err)
+			}
+			c.httpLn = ln
+			addr = ln.Addr()
+		}
+
+		if f, ok := cfg[httpAddrFileFlag].(string); ok && f != "" {
+			// TODO: we should probably track this file and delete it on shutdown.
+			addr := addr.String() + "\n"
+			if err := os.WriteFile(f, []byte(addr), 0600); err != nil {
+				return fmt.Errorf("failed to write HTTP address to file %q: %w", f, err)
+			}
+		}
+
+		if s := flagString(cfg, httpMaxPageSizeFlag); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid value for %s: %q", httpMaxPageSizeFlag, s)
+			}
+			c.httpMaxPageSize = n
+		}
+	}
+
+	if grpcAddrFlag, ok := cfg[grpcAddrFlag].(string); ok && grpcAddrFlag != "" {
+		ln, err := net.Listen("tcp", grpcAddrFlag)
+		if err != nil {
+			return fmt.Errorf("failed to listen for gRPC on %q: %w", grpcAddrFlag, err)
+		}
+
+		c.grpcLn = ln
+	}
+
+	if p := flagString(cfg, rpcTokenFileFlag); p != "" {
+		t, err := gtenant.LoadTokens(p)
+		if err != nil {
+			return err
+		}
+		c.rpcTokens = t
+	}
+
+	return nil
+}
+
+// startHTTPServer starts the HTTP server with cfg,
+// on its own listener or on the shared host.
+func (c *Component) startHTTPServer(ctx context.Context, cfg gsi.HTTPServerConfig) error {
+	cfg.AuthTokens = c.rpcTokens
+
+	log := c.log.With("sys", "http")
+	if c.sharedHTTP != nil {
+		cfg.SharedHost = c.sharedHTTP
+		cfg.ChainID = c.chainID
+
+		s, err := gsi.AttachHTTPServer(ctx, log, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to serve on shared HTTP server: %w", err)
+		}
+		c.httpServer = s
+	} else {
+		cfg.Listener = c.httpLn
+		c.httpServer = gsi.NewHTTPServer(ctx, log, cfg)
+	}
+
+	c.stops.Watch(ctx, "http", c.httpServer.Wait)
+	return nil
+}
+
+// startGRPCServer starts the gRPC server on c.grpcLn.
+func (c *Component) startGRPCServer(ctx context.Context, txBuf *gsi.SDKTxBuf, txPool *gsi.TxPool) {
+	// TODO; share this with the http server as a wrapper.
+	// https://github.com/gordian-engine/gordian/pull/14
+	c.grpcServer = ggrpc.NewGordianGRPCServer(ctx, c.log.With("sys", "grpc"), ggrpc.GRPCServerConfig{
+		Listener: c.grpcLn,
+
+		MirrorStore:       c.ms,
+		FinalizationStore: c.fs,
+
+		CryptoRegistry: c.reg,
+
+		AppManager: c.app,
+		TxCodec:    c.txc,
+		Codec:      c.codec,
+
+		TxBuffer: txBuf,
+		TxPool:   txPool,
+
+		ChainID:    c.chainID,
+		AuthTokens: c.rpcTokens,
+	})
+	c.stops.Watch(ctx, "grpc", c.grpcServer.Wait)
+}
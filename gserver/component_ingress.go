@@ -0,0 +1,91 @@
+package gserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gingress"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// initializeIngress parses the limits on inbound consensus messages.
+func (c *Component) initializeIngress(cfg map[string]any) error {
+	if s := flagString(cfg, maxProposalsPerRoundFlag); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid value for %s: %q", maxProposalsPerRoundFlag, s)
+		}
+		c.guardCfg.MaxProposedHeadersPerRound = n
+	}
+
+	if s := flagString(cfg, maxFutureVoteHeightsFlag); s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %q", maxFutureVoteHeightsFlag, s)
+		}
+		c.guardCfg.MaxFutureVoteHeights = n
+	}
+
+	c.dedupeCfg.Window = gingress.DefaultDedupeWindow
+	if s := flagString(cfg, ingressDedupeWindowFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", ingressDedupeWindowFlag, s)
+		}
+		c.dedupeCfg.Window = d
+	}
+
+	return nil
+}
+
+// startIngress routes inbound consensus messages to ch,
+// through the ingress guard, backpressure measurement, deduplication, and recording,
+// from either the network connection or the replay file.
+// The returned deduper is nil if deduplication is disabled.
+func (c *Component) startIngress(
+	ctx context.Context, ch tmconsensus.FineGrainedConsensusHandler, codec tmjson.MarshalCodec,
+) (*gingress.Guard, *gingress.Deduper, error) {
+	guardCfg := c.guardCfg
+	guardCfg.CommittingHeight = func(ctx context.Context) (uint64, error) {
+		_, _, committingHeight, _, err := c.ms.NetworkHeightRound(ctx)
+		return committingHeight, err
+	}
+	guardCfg.Audit = c.audit
+	guardCfg.SignatureScheme = c.sigScheme
+	guard := gingress.NewGuard(
+		c.log.With("sys", "ingress"),
+		tmconsensus.AcceptAllValidFeedbackMapper{Handler: ch},
+		guardCfg,
+	)
+
+	var inbound tmconsensus.ConsensusHandler = gbackpressure.NewConsensusHandler(c.backpressure, guard)
+	var dedupe *gingress.Deduper
+	if c.dedupeCfg.Window > 0 {
+		// Outside the guard and backpressure measurement,
+		// so that duplicates cost as little as possible.
+		dedupe = gingress.NewDeduper(inbound, c.dedupeCfg)
+		inbound = dedupe
+	}
+
+	inbound, err := c.startRecording(inbound, codec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.replayPath != "" {
+		// Only the recorded messages reach the engine while replaying,
+		// so that the network cannot interfere with the reproduction.
+		if err := c.startReplay(inbound, codec); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		// Plain context here; if canceled, this will fail, which is fine.
+		c.conn.SetConsensusHandler(ctx, inbound)
+	}
+
+	return guard, dedupe, nil
+}
@@ -5,12 +5,20 @@
 package gserver
 
 import (
+	"github.com/gordian-engine/gcosmos/gserver/internal/gchaos"
+	"github.com/gordian-engine/gordian/gassert"
 	"github.com/gordian-engine/gordian/tm/tmengine"
 	"github.com/spf13/pflag"
 )
 
 func addAssertRuleFlag(fs *pflag.FlagSet) {}
 
-func getAssertEngineOpt(cfg map[string]any) (_ tmengine.Opt, _ error) {
+func getAssertEngineOpt(cfg map[string]any) (_ tmengine.Opt, _ gassert.Env, _ error) {
+	return
+}
+
+func addChaosFlag(fs *pflag.FlagSet) {}
+
+func getChaosConfig(cfg map[string]any) (_ gchaos.Config, _ error) {
 	return
 }
@@ -0,0 +1,50 @@
+package gserver
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gcstore/gcpgstore"
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" database/sql driver for gcpgstore.
+)
+
+// initializePostgres opens the PostgreSQL store if a DSN is configured.
+// The store replaces the block data store and is used as the transaction index;
+// the finalization store is replaced after the other consensus stores are chosen.
+func (c *Component) initializePostgres(cfg map[string]any) error {
+	dsn := flagString(cfg, postgresDSNFlag)
+	if dsn == "" {
+		return nil
+	}
+
+	// The pgx driver is linked in through its stdlib package,
+	// which registers itself with database/sql as "pgx".
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	durability, err := gcpgstore.ParseDurability(flagString(cfg, postgresDurabilityFlag))
+	if err != nil {
+		_ = db.Close()
+		return fmt.Errorf("invalid value for %s: %w", postgresDurabilityFlag, err)
+	}
+
+	s, err := gcpgstore.NewStore(c.rootCtx, gcpgstore.StoreConfig{
+		DB:       db,
+		Registry: c.reg,
+
+		TablePrefix: flagString(cfg, postgresTablePrefixFlag),
+		Durability:  durability,
+	})
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	c.pgDB = db
+	c.pgStore = s
+	c.bds = s
+	c.log.Info("Using PostgreSQL for block data, finalizations, and transaction index")
+	return nil
+}
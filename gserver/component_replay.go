@@ -0,0 +1,82 @@
+package gserver
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/greplay"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// initializeReplay parses the paths for recording inbound consensus messages
+// and for replaying previously recorded ones.
+func (c *Component) initializeReplay(cfg map[string]any) error {
+	c.recordPath = flagString(cfg, consensusRecordFileFlag)
+	c.replayPath = flagString(cfg, consensusReplayFileFlag)
+	c.replaySpeed = 1
+	if s := flagString(cfg, consensusReplaySpeedFlag); s != "" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f < 0 {
+			return fmt.Errorf("invalid value for %s: %q", consensusReplaySpeedFlag, s)
+		}
+		c.replaySpeed = f
+	}
+	return nil
+}
+
+// startRecording returns h wrapped to append every message it handles
+// to the configured record file, or h itself if recording is disabled.
+func (c *Component) startRecording(
+	h tmconsensus.ConsensusHandler, codec tmjson.MarshalCodec,
+) (tmconsensus.ConsensusHandler, error) {
+	if c.recordPath == "" {
+		return h, nil
+	}
+
+	f, err := os.OpenFile(c.recordPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open consensus record file: %w", err)
+	}
+	c.recordFile = f
+	return greplay.NewRecorder(c.log.With("sys", "consensus_recorder"), f, codec, h), nil
+}
+
+// startReplay feeds the messages in the configured replay file to h
+// in a background goroutine.
+func (c *Component) startReplay(h tmconsensus.ConsensusHandler, codec tmjson.MarshalCodec) error {
+	f, err := os.Open(c.replayPath)
+	if err != nil {
+		return fmt.Errorf("failed to open consensus replay file: %w", err)
+	}
+
+	log := c.log.With("sys", "consensus_replay")
+	c.replayDone = make(chan struct{})
+	c.stops.Watch(c.rootCtx, "consensus_replay", func() { <-c.replayDone })
+	go func() {
+		defer close(c.replayDone)
+		defer f.Close()
+
+		log.Info("Replaying consensus messages", "file", c.replayPath, "speed", c.replaySpeed)
+		stats, err := greplay.Replay(c.rootCtx, log, greplay.ReplayConfig{
+			Source:  f,
+			Codec:   codec,
+			Handler: h,
+			Speed:   c.replaySpeed,
+		})
+		if err != nil {
+			log.Warn(
+				"Consensus replay stopped early",
+				"messages", stats.Messages, "feedback_changed", stats.FeedbackChanged, "err", err,
+			)
+			return
+		}
+		log.Info(
+			"Finished replaying consensus messages",
+			"messages", stats.Messages, "feedback_changed", stats.FeedbackChanged,
+		)
+	}()
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package gserver
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gp2papi"
+)
+
+// initializeStartPeers parses how many connected peers
+// the initial height waits for, and for how long.
+func (c *Component) initializeStartPeers(cfg map[string]any) error {
+	if s := flagString(cfg, minStartPeersFlag); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid value for %s: %q", minStartPeersFlag, s)
+		}
+		c.minStartPeers = n
+	}
+	if s := flagString(cfg, startPeerTimeoutFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", startPeerTimeoutFlag, s)
+		}
+		c.startPeerTimeout = d
+	}
+	return nil
+}
+
+// startPeerBarrier starts waiting for the minimum start peers on c.h,
+// returning a channel closed once the driver may start the initial height.
+// It returns nil if there is no minimum.
+func (c *Component) startPeerBarrier() <-chan struct{} {
+	if c.minStartPeers <= 0 {
+		return nil
+	}
+
+	c.peerBarrier = gp2papi.NewPeerBarrier(
+		c.rootCtx,
+		c.log.With("sys", "peer_barrier"),
+		gp2papi.PeerBarrierConfig{
+			Host:     c.h.Libp2pHost(),
+			MinPeers: c.minStartPeers,
+			Timeout:  c.startPeerTimeout,
+		},
+	)
+	c.stops.Watch(c.rootCtx, "peer_barrier", c.peerBarrier.Wait)
+	return c.peerBarrier.Ready()
+}
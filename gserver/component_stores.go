@@ -0,0 +1,225 @@
+package gserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcmemstore"
+	"github.com/gordian-engine/gcosmos/gcstore/gcs3store"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaction"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpersist"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmengine"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+	"github.com/gordian-engine/tmsqlite"
+)
+
+func (c *Component) initializeSQLite(sqlitePath string) error {
+	// First special case: empty means don't set c.tmsql at all,
+	// and the rest of the Init method will use tmmemstore.
+	if sqlitePath == "" {
+		return nil
+	}
+
+	var err error
+
+	// Other special case: the exact string :memory:,
+	// which has existing special meaning to SQLite.
+	if sqlitePath == ":memory:" {
+		c.tmsql, err = tmsqlite.NewInMemStore(
+			c.rootCtx,
+			c.hashScheme,
+			c.reg,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to start tmsqlite store: %w", err)
+		}
+		return nil
+	}
+
+	// Otherwise it looks like a normal path.
+	c.tmsql, err = tmsqlite.NewOnDiskStore(
+		c.rootCtx,
+		sqlitePath,
+		c.hashScheme,
+		c.reg,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start tmsqlite store: %w", err)
+	}
+
+	c.log.Info("Using SQLite on-disk file", "path", sqlitePath)
+	return nil
+}
+
+// initializeConsensusStores chooses the engine's stores,
+// either all backed by c.tmsql or all in memory,
+// with the replacements and wrappers configured on top of them,
+// and returns the engine options setting them.
+// The stores that cross into Start are also set on c.
+func (c *Component) initializeConsensusStores(cfg map[string]any, homeDir string) ([]tmengine.Opt, error) {
+	var as tmstore.ActionStore
+	var rs tmstore.RoundStore = c.tmsql
+	var sms tmstore.StateMachineStore = c.tmsql
+	var vs tmstore.ValidatorStore = c.tmsql
+
+	if c.tmsql == nil {
+		if c.signer != nil {
+			as = tmmemstore.NewActionStore()
+		}
+		rs = tmmemstore.NewRoundStore()
+		sms = tmmemstore.NewStateMachineStore()
+		vs = tmmemstore.NewValidatorStore(c.hashScheme)
+
+		c.chs = tmmemstore.NewCommittedHeaderStore()
+		c.fs = tmmemstore.NewFinalizationStore()
+		c.ms = tmmemstore.NewMirrorStore()
+	} else {
+		if c.signer != nil {
+			as = c.tmsql
+		}
+
+		c.chs = c.tmsql
+		c.fs = c.tmsql
+		c.ms = c.tmsql
+
+		// A transient write failure, such as a busy database,
+		// should not halt consensus; the round state remains in memory.
+		c.roundStore = gpersist.NewRoundStore(
+			c.rootCtx, c.log.With("sys", "round_store"), rs, gpersist.RoundStoreConfig{},
+		)
+		rs = c.roundStore
+	}
+
+	if c.pgStore != nil {
+		c.fs = c.pgStore
+	}
+
+	if s := flagString(cfg, actionRetainHeightsFlag); s != "" && s != "0" && as != nil {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %q", actionRetainHeightsFlag, s)
+		}
+		actions, err := gaction.NewStore(
+			c.log.With("sys", "action_store"),
+			gaction.StoreConfig{
+				Dir:           filepath.Join(homeDir, "data", "actions"),
+				Codec:         tmjson.MarshalCodec{CryptoRegistry: c.reg},
+				Registry:      c.reg,
+				RetainHeights: n,
+
+				// Actions recorded before switching stores
+				// still guard against double signing in the round the engine resumes.
+				Fallback: as,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", actionRetainHeightsFlag, err)
+		}
+		c.actions = actions
+		as = actions
+	}
+
+	// The catchup client needs the validator store during Start.
+	c.vs = vs
+
+	c.compactCommitProofs = flagString(cfg, compactCommitProofsFlag) == "true"
+	if c.compactCommitProofs {
+		c.chs = gcstore.NewCompactingCommittedHeaderStore(c.chs, nil)
+	}
+
+	return []tmengine.Opt{
+		tmengine.WithActionStore(as),
+		tmengine.WithCommittedHeaderStore(c.chs),
+		tmengine.WithFinalizationStore(c.fs),
+		tmengine.WithMirrorStore(c.ms),
+		tmengine.WithRoundStore(rs),
+		tmengine.WithStateMachineStore(sms),
+		tmengine.WithValidatorStore(vs),
+	}, nil
+}
+
+func (c *Component) initializeBlockDataStore(cfg map[string]any) error {
+	endpoint := flagString(cfg, blockDataS3EndpointFlag)
+	if endpoint == "" {
+		// No SQLite implementation for this yet.
+		c.bds = gcmemstore.NewBlockDataStore()
+		return nil
+	}
+
+	// Credentials are taken from the conventional environment variables
+	// rather than flags, to keep them out of process listings.
+	objs, err := gcs3store.NewS3Client(gcs3store.S3Config{
+		Endpoint: endpoint,
+		Bucket:   flagString(cfg, blockDataS3BucketFlag),
+		Region:   flagString(cfg, blockDataS3RegionFlag),
+
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	cacheSize := 0
+	if s := flagString(cfg, blockDataCacheSizeFlag); s != "" {
+		cacheSize, err = strconv.Atoi(s)
+		if err != nil || cacheSize < 0 {
+			return fmt.Errorf("invalid value for %s: %q", blockDataCacheSizeFlag, s)
+		}
+	}
+
+	c.bds = gcs3store.NewBlockDataStore(gcs3store.BlockDataStoreConfig{
+		Objects:   objs,
+		Prefix:    flagString(cfg, blockDataS3PrefixFlag),
+		CacheSize: cacheSize,
+	})
+	c.log.Info("Using S3-compatible object store for block data", "endpoint", endpoint)
+	return nil
+}
+
+// checkReplayBlockData reports an error if the app is behind
+// a committed block with transactions,
+// which the driver would have to replay to the app from the block data store,
+// when the block data store did not persist across the restart.
+func (c *Component) checkReplayBlockData(ctx context.Context) error {
+	_, _, committingHeight, _, err := c.ms.NetworkHeightRound(ctx)
+	if errors.Is(err, tmstore.ErrStoreUninitialized) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read network height and round: %w", err)
+	}
+
+	appVersion, _, err := c.app.Store().StateLatest()
+	if err != nil {
+		return fmt.Errorf("failed to read latest app state: %w", err)
+	}
+
+	// The committing height's block data is retrieved from peers like any proposal,
+	// but the driver only finds the data of earlier blocks in the block data store.
+	for h := appVersion + 1; h < committingHeight; h++ {
+		ch, err := c.chs.LoadCommittedHeader(ctx, h)
+		if err != nil {
+			return fmt.Errorf("failed to load committed header at height %d: %w", h, err)
+		}
+		if gsbd.IsZeroTxDataID(string(ch.Header.DataID)) {
+			continue
+		}
+
+		return fmt.Errorf(
+			"app state is at height %d, but the block committed at height %d "+
+				"must be replayed from block data that was only held in memory before restarting; "+
+				"configure a persistent block data store with --%s or --%s",
+			appVersion, h, blockDataS3EndpointFlag, postgresDSNFlag,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package gserver
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+)
+
+func (c *Component) initializeTimeoutStrategy(cfg map[string]any) error {
+	esc, err := gsi.ParseTimeoutEscalation(flagString(cfg, timeoutEscalationFlag))
+	if err != nil {
+		return err
+	}
+	c.timeoutStrategy.Escalation = esc
+
+	if s := flagString(cfg, timeoutFactorFlag); s != "" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", timeoutFactorFlag, err)
+		}
+		if f != 0 && f <= 1 {
+			return fmt.Errorf("%s must be greater than 1 (got %v)", timeoutFactorFlag, f)
+		}
+		c.timeoutStrategy.Factor = f
+	}
+
+	if s := flagString(cfg, timeoutCapFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", timeoutCapFlag, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative (got %s)", timeoutCapFlag, d)
+		}
+		c.timeoutStrategy.Cap = d
+	}
+
+	for _, f := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{commitWaitFlag, &c.timeoutStrategy.Linear.CommitWaitBase},
+		{commitWaitIncrementFlag, &c.timeoutStrategy.Linear.CommitWaitIncrement},
+	} {
+		s := flagString(cfg, f.name)
+		if s == "" {
+			continue
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", f.name, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative (got %s)", f.name, d)
+		}
+		*f.dst = d
+	}
+
+	if flagString(cfg, commitWaitSkipFullFlag) == "true" {
+		c.precommits = new(gsi.PrecommitTracker)
+		c.timeoutStrategy.FullPrecommits = c.precommits.FullPrecommits
+	}
+
+	return nil
+}
+
+// initializeRoundHistory creates the history of recent rounds
+// observed by the consensus strategy, unless it is disabled.
+func (c *Component) initializeRoundHistory(cfg map[string]any) error {
+	n, err := validateRoundHistoryHeights(flagString(cfg, roundHistoryHeightsFlag))
+	if err != nil {
+		return err
+	}
+	c.roundHistoryHeights = n
+	if n > 0 {
+		c.roundHistory = gsi.NewRoundHistory(n)
+	}
+	return nil
+}
+
+// initializeProposals parses how this node builds the blocks it proposes.
+// The block builder is bounded by the parameter schedule,
+// so c.params must already be loaded.
+func (c *Component) initializeProposals(cfg map[string]any) error {
+	if s := flagString(cfg, createEmptyBlocksIntervalFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", createEmptyBlocksIntervalFlag, s)
+		}
+		c.emptyBlocks.Interval = d
+	}
+	if err := c.emptyBlocks.Validate(); err != nil {
+		return fmt.Errorf("invalid empty block configuration: %w", err)
+	}
+	c.timeoutStrategy.EmptyBlockWait = c.emptyBlocks.Wait()
+
+	if u := flagString(cfg, blockBuilderURLFlag); u != "" {
+		b, err := gsi.NewHTTPBlockBuilder(u, c.txc, c.params)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", blockBuilderURLFlag, err)
+		}
+		c.blockBuilder = b
+	}
+
+	if s := flagString(cfg, blockBuilderTimeoutFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", blockBuilderTimeoutFlag, s)
+		}
+		c.blockBuilderTimeout = d
+	}
+
+	if s := flagString(cfg, proposalBroadcastMarginFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", proposalBroadcastMarginFlag, s)
+		}
+		c.proposalBroadcastMargin = d
+	}
+
+	return nil
+}
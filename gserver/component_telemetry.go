@@ -0,0 +1,50 @@
+package gserver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtelemetry"
+)
+
+// initializeTelemetry parses the opt-in telemetry settings;
+// nothing is reported unless the URL is set.
+func (c *Component) initializeTelemetry(cfg map[string]any) error {
+	c.telemetryURL = flagString(cfg, telemetryURLFlag)
+	if s := flagString(cfg, telemetryIntervalFlag); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: %q", telemetryIntervalFlag, s)
+		}
+		c.telemetryInterval = d
+	}
+	return nil
+}
+
+// startTelemetry starts reporting anonymized statistics, if enabled.
+func (c *Component) startTelemetry() error {
+	if c.telemetryURL == "" {
+		return nil
+	}
+
+	t, err := gtelemetry.NewReporter(
+		c.rootCtx,
+		c.log.With("sys", "telemetry"),
+		gtelemetry.ReporterConfig{
+			Endpoint: c.telemetryURL,
+			Interval: c.telemetryInterval,
+			ChainID:  c.chainID,
+
+			Watermarks: c.watermarks,
+			PeerCount: func() int {
+				return len(c.h.Libp2pHost().Network().Peers())
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry reporter: %w", err)
+	}
+	c.telemetry = t
+	c.stops.Watch(c.rootCtx, "telemetry", c.telemetry.Wait)
+	return nil
+}
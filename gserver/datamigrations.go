@@ -0,0 +1,22 @@
+package gserver
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gmigrate"
+)
+
+// dataMigrations upgrade the layout of the data directory in the node home,
+// run by Init before any store is opened.
+// Append a migration whenever a release changes the format of data it persists;
+// never edit or remove an existing entry.
+var dataMigrations = []gmigrate.Migration{
+	{
+		To:          1,
+		Description: "record the data layout version",
+
+		// Homes created before versioning already have the version 1 layout.
+		Apply: func(context.Context, *slog.Logger, string) error { return nil },
+	},
+}
@@ -0,0 +1,520 @@
+// Package gaction is a file-backed [tmstore.ActionStore]
+// that discards the actions of old heights.
+//
+// The engine records every proposed header and vote it signs
+// before publishing it, so that after a restart
+// it does not sign something conflicting in the same round.
+// It only ever loads the actions for the round it resumes in,
+// so the actions of heights well below the latest one
+// no longer protect against double signing.
+// A [Store] keeps one file per height in its directory,
+// which makes discarding old heights a matter of removing files.
+//
+// Heights within [MinRetainHeights] of the highest recorded height
+// are never removed, whichever way the store is compacted.
+package gaction
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+)
+
+// MinRetainHeights is the fewest heights a [Store] keeps,
+// counting down from the highest recorded height.
+//
+// The engine may resume voting at the highest recorded height,
+// and the height before it may still be committing.
+const MinRetainHeights = 2
+
+// StoreConfig is the configuration for [NewStore].
+type StoreConfig struct {
+	// Directory holding one file per height, created if it does not exist.
+	Dir string
+
+	// Codec for the recorded proposed headers.
+	// It must produce JSON, like the tmjson codec.
+	Codec tmcodec.MarshalCodec
+
+	// Used to encode and decode the signing public keys.
+	Registry *gcrypto.Registry
+
+	// If positive, whenever an action is saved at a new highest height,
+	// the store removes the heights more than RetainHeights below it.
+	// Zero keeps every height until [Store.Compact] is called.
+	// It must be zero or at least MinRetainHeights.
+	RetainHeights uint64
+
+	// Optional store consulted for a round with no actions in Dir,
+	// such as the store the node recorded its actions in before using this one.
+	// It is never written to, so that it stops growing.
+	Fallback tmstore.ActionStore
+}
+
+// Stats describe the heights held by a [Store].
+type Stats struct {
+	Heights int
+
+	// Lowest and highest recorded heights; zero if there are none.
+	Lowest, Highest uint64
+
+	// Total size of the height files.
+	Bytes int64
+}
+
+// CompactResult is the outcome of [Store.Compact].
+type CompactResult struct {
+	// Number of heights removed.
+	Removed int
+
+	// Every height below Below was removed.
+	Below uint64
+}
+
+// record is the persisted form of the actions in one round.
+type record struct {
+	Round uint32
+
+	ProposedHeader json.RawMessage `json:",omitempty"`
+
+	// Encoded through the registry.
+	PubKey []byte `json:",omitempty"`
+
+	PrevoteTarget    []byte `json:",omitempty"`
+	PrevoteSignature []byte `json:",omitempty"`
+
+	PrecommitTarget    []byte `json:",omitempty"`
+	PrecommitSignature []byte `json:",omitempty"`
+}
+
+// Store is a file-backed [tmstore.ActionStore].
+// It is safe for concurrent use.
+type Store struct {
+	log *slog.Logger
+
+	dir      string
+	codec    tmcodec.MarshalCodec
+	reg      *gcrypto.Registry
+	retain   uint64
+	fallback tmstore.ActionStore
+
+	mu      sync.Mutex
+	highest uint64
+}
+
+// NewStore returns a Store keeping its files in cfg.Dir.
+func NewStore(log *slog.Logger, cfg StoreConfig) (*Store, error) {
+	if cfg.RetainHeights > 0 && cfg.RetainHeights < MinRetainHeights {
+		return nil, fmt.Errorf(
+			"retained heights must be zero or at least %d; got %d",
+			MinRetainHeights, cfg.RetainHeights,
+		)
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create action store directory: %w", err)
+	}
+
+	s := &Store{
+		log: log,
+
+		dir:      cfg.Dir,
+		codec:    cfg.Codec,
+		reg:      cfg.Registry,
+		retain:   cfg.RetainHeights,
+		fallback: cfg.Fallback,
+	}
+
+	heights, err := s.heights()
+	if err != nil {
+		return nil, err
+	}
+	if len(heights) > 0 {
+		s.highest = heights[len(heights)-1]
+	}
+
+	return s, nil
+}
+
+// Dir returns the directory holding the height files.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) SaveProposedHeaderAction(ctx context.Context, ph tmconsensus.ProposedHeader) error {
+	return s.update(ctx, ph.Header.Height, ph.Round, func(ra *tmstore.RoundActions) error {
+		if ra.ProposedHeader.Header.Height != 0 {
+			return tmstore.DoubleActionError{Type: "proposed block"}
+		}
+		ra.ProposedHeader = ph
+		return nil
+	})
+}
+
+func (s *Store) SavePrevoteAction(
+	ctx context.Context, pubKey gcrypto.PubKey, vt tmconsensus.VoteTarget, sig []byte,
+) error {
+	return s.update(ctx, vt.Height, vt.Round, func(ra *tmstore.RoundActions) error {
+		if ra.PrevoteSignature != "" {
+			return tmstore.DoubleActionError{Type: "prevote"}
+		}
+		if ra.PubKey != nil && !ra.PubKey.Equal(pubKey) {
+			return tmstore.PubKeyChangedError{
+				ActionType: "prevote",
+				Want:       string(ra.PubKey.PubKeyBytes()),
+				Got:        string(pubKey.PubKeyBytes()),
+			}
+		}
+
+		ra.PubKey = pubKey
+		ra.PrevoteTarget = vt.BlockHash
+		ra.PrevoteSignature = string(sig)
+		return nil
+	})
+}
+
+func (s *Store) SavePrecommitAction(
+	ctx context.Context, pubKey gcrypto.PubKey, vt tmconsensus.VoteTarget, sig []byte,
+) error {
+	return s.update(ctx, vt.Height, vt.Round, func(ra *tmstore.RoundActions) error {
+		if ra.PrecommitSignature != "" {
+			return tmstore.DoubleActionError{Type: "precommit"}
+		}
+		if ra.PubKey != nil && !ra.PubKey.Equal(pubKey) {
+			return tmstore.PubKeyChangedError{
+				ActionType: "precommit",
+				Want:       string(ra.PubKey.PubKeyBytes()),
+				Got:        string(pubKey.PubKeyBytes()),
+			}
+		}
+
+		ra.PubKey = pubKey
+		ra.PrecommitTarget = vt.BlockHash
+		ra.PrecommitSignature = string(sig)
+		return nil
+	})
+}
+
+// LoadActions returns all actions recorded for the round,
+// consulting the fallback store if none are recorded in the directory.
+func (s *Store) LoadActions(ctx context.Context, height uint64, round uint32) (tmstore.RoundActions, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ra, ok, err := s.load(ctx, height, round)
+	if err != nil {
+		return tmstore.RoundActions{}, err
+	}
+	if !ok {
+		return tmstore.RoundActions{}, tmconsensus.RoundUnknownError{
+			WantHeight: height,
+			WantRound:  round,
+		}
+	}
+	return ra, nil
+}
+
+// update applies fn to the actions recorded for the round
+// and persists the result if fn succeeds.
+func (s *Store) update(
+	ctx context.Context, height uint64, round uint32, fn func(*tmstore.RoundActions) error,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs, err := s.readHeight(height)
+	if err != nil {
+		return err
+	}
+
+	ra, ok, err := s.load(ctx, height, round)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ra = tmstore.RoundActions{Height: height, Round: round}
+	}
+
+	if err := fn(&ra); err != nil {
+		return err
+	}
+
+	r, err := s.encode(ra)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(rs, func(r record) bool { return r.Round == round })
+	if i < 0 {
+		rs = append(rs, r)
+		slices.SortFunc(rs, func(a, b record) int { return cmp.Compare(a.Round, b.Round) })
+	} else {
+		rs[i] = r
+	}
+
+	if err := s.writeHeight(height, rs); err != nil {
+		return fmt.Errorf("failed to save action at height %d round %d: %w", height, round, err)
+	}
+
+	if height > s.highest {
+		s.highest = height
+		if s.retain > 0 {
+			// A failure to prune is not a failure to save;
+			// the next new height tries again.
+			if _, err := s.compact(s.retain); err != nil {
+				s.log.Warn("Failed to remove old action heights", "height", height, "err", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// load returns the actions for the round from the directory,
+// or else from the fallback store.
+// It reports false, without an error, if neither has any.
+func (s *Store) load(ctx context.Context, height uint64, round uint32) (tmstore.RoundActions, bool, error) {
+	rs, err := s.readHeight(height)
+	if err != nil {
+		return tmstore.RoundActions{}, false, err
+	}
+	for _, r := range rs {
+		if r.Round == round {
+			ra, err := s.decode(height, r)
+			return ra, err == nil, err
+		}
+	}
+
+	if s.fallback == nil {
+		return tmstore.RoundActions{}, false, nil
+	}
+	ra, err := s.fallback.LoadActions(ctx, height, round)
+	if err != nil {
+		if errors.As(err, new(tmconsensus.RoundUnknownError)) {
+			return tmstore.RoundActions{}, false, nil
+		}
+		return tmstore.RoundActions{}, false, fmt.Errorf("failed to load actions from fallback store: %w", err)
+	}
+	return ra, true, nil
+}
+
+// Stats reports the heights currently held by the store.
+func (s *Store) Stats() (Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	heights, err := s.heights()
+	if err != nil {
+		return Stats{}, err
+	}
+
+	st := Stats{Heights: len(heights)}
+	if len(heights) > 0 {
+		st.Lowest = heights[0]
+		st.Highest = heights[len(heights)-1]
+	}
+	for _, h := range heights {
+		fi, err := os.Stat(s.path(h))
+		if err != nil {
+			return Stats{}, fmt.Errorf("failed to stat action height file: %w", err)
+		}
+		st.Bytes += fi.Size()
+	}
+	return st, nil
+}
+
+// Compact removes every height more than retain heights
+// below the highest recorded height.
+// It returns an error if retain is less than [MinRetainHeights].
+func (s *Store) Compact(retain uint64) (CompactResult, error) {
+	if retain < MinRetainHeights {
+		return CompactResult{}, fmt.Errorf(
+			"refusing to retain fewer than %d heights; got %d", MinRetainHeights, retain,
+		)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compact(retain)
+}
+
+func (s *Store) compact(retain uint64) (CompactResult, error) {
+	if s.highest < retain {
+		return CompactResult{}, nil
+	}
+	res := CompactResult{Below: s.highest - retain + 1}
+
+	heights, err := s.heights()
+	if err != nil {
+		return res, err
+	}
+	for _, h := range heights {
+		if h >= res.Below {
+			break
+		}
+		if err := os.Remove(s.path(h)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return res, fmt.Errorf("failed to remove actions at height %d: %w", h, err)
+		}
+		res.Removed++
+	}
+
+	if res.Removed > 0 {
+		s.log.Debug("Removed old action heights", "removed", res.Removed, "below", res.Below)
+	}
+	return res, nil
+}
+
+// heights returns the recorded heights in increasing order.
+func (s *Store) heights() ([]uint64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read action store directory: %w", err)
+	}
+
+	var heights []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			// Including temporary files left by a crash.
+			continue
+		}
+		h, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		heights = append(heights, h)
+	}
+	slices.Sort(heights)
+	return heights, nil
+}
+
+func (s *Store) path(height uint64) string {
+	// Zero padded so that directory listings sort by height.
+	return filepath.Join(s.dir, fmt.Sprintf("%020d.json", height))
+}
+
+func (s *Store) readHeight(height uint64) ([]record, error) {
+	b, err := os.ReadFile(s.path(height))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read actions at height %d: %w", height, err)
+	}
+
+	var rs []record
+	if err := json.Unmarshal(b, &rs); err != nil {
+		return nil, fmt.Errorf("failed to parse actions at height %d: %w", height, err)
+	}
+	return rs, nil
+}
+
+// writeHeight atomically replaces the file for height,
+// syncing it before returning, as the engine publishes
+// the signed action as soon as the save returns.
+func (s *Store) writeHeight(height uint64, rs []record) error {
+	b, err := json.Marshal(rs)
+	if err != nil {
+		return err
+	}
+
+	path := s.path(height)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	d, err := os.Open(s.dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (s *Store) encode(ra tmstore.RoundActions) (record, error) {
+	r := record{
+		Round: ra.Round,
+
+		PrevoteTarget:    []byte(ra.PrevoteTarget),
+		PrevoteSignature: []byte(ra.PrevoteSignature),
+
+		PrecommitTarget:    []byte(ra.PrecommitTarget),
+		PrecommitSignature: []byte(ra.PrecommitSignature),
+	}
+	if ra.PubKey != nil {
+		r.PubKey = s.reg.Marshal(ra.PubKey)
+	}
+	if ra.ProposedHeader.Header.Height != 0 {
+		b, err := s.codec.MarshalProposedHeader(ra.ProposedHeader)
+		if err != nil {
+			return record{}, fmt.Errorf("failed to encode proposed header: %w", err)
+		}
+		r.ProposedHeader = b
+	}
+	return r, nil
+}
+
+func (s *Store) decode(height uint64, r record) (tmstore.RoundActions, error) {
+	ra := tmstore.RoundActions{
+		Height: height,
+		Round:  r.Round,
+
+		PrevoteTarget:    string(r.PrevoteTarget),
+		PrevoteSignature: string(r.PrevoteSignature),
+
+		PrecommitTarget:    string(r.PrecommitTarget),
+		PrecommitSignature: string(r.PrecommitSignature),
+	}
+	if len(r.PubKey) > 0 {
+		pk, err := s.reg.Unmarshal(r.PubKey)
+		if err != nil {
+			return tmstore.RoundActions{}, fmt.Errorf(
+				"failed to decode public key at height %d round %d: %w", height, r.Round, err,
+			)
+		}
+		ra.PubKey = pk
+	}
+	if len(r.ProposedHeader) > 0 {
+		if err := s.codec.UnmarshalProposedHeader(r.ProposedHeader, &ra.ProposedHeader); err != nil {
+			return tmstore.RoundActions{}, fmt.Errorf(
+				"failed to decode proposed header at height %d round %d: %w", height, r.Round, err,
+			)
+		}
+
+		// The codec always allocates the proof map,
+		// but the engine's stores return nil for an empty proof.
+		if len(ra.ProposedHeader.Header.PrevCommitProof.Proofs) == 0 {
+			ra.ProposedHeader.Header.PrevCommitProof.Proofs = nil
+		}
+	}
+	return ra, nil
+}
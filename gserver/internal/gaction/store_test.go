@@ -0,0 +1,120 @@
+package gaction_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaction"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmstoretest"
+	"github.com/stretchr/testify/require"
+)
+
+func newStoreConfig(t *testing.T) gaction.StoreConfig {
+	t.Helper()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+	return gaction.StoreConfig{
+		Dir:      filepath.Join(t.TempDir(), "actions"),
+		Codec:    tmjson.MarshalCodec{CryptoRegistry: &reg},
+		Registry: &reg,
+	}
+}
+
+func TestStore_compliance(t *testing.T) {
+	t.Parallel()
+
+	tmstoretest.TestActionStoreCompliance(t, func(func(func())) (tmstore.ActionStore, error) {
+		return gaction.NewStore(gtest.NewLogger(t), newStoreConfig(t))
+	})
+}
+
+func TestStore_retainHeights(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fx := tmconsensustest.NewStandardFixture(2)
+	pubKey := fx.ValidatorPubKey(0)
+
+	cfg := newStoreConfig(t)
+	cfg.RetainHeights = 3
+	s, err := gaction.NewStore(gtest.NewLogger(t), cfg)
+	require.NoError(t, err)
+
+	for h := uint64(1); h <= 10; h++ {
+		require.NoError(t, s.SavePrevoteAction(
+			ctx, pubKey, tmconsensus.VoteTarget{Height: h, BlockHash: "hash"}, []byte("sig"),
+		))
+	}
+
+	st, err := s.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 3, st.Heights)
+	require.Equal(t, uint64(8), st.Lowest)
+	require.Equal(t, uint64(10), st.Highest)
+	require.Positive(t, st.Bytes)
+
+	_, err = s.LoadActions(ctx, 7, 0)
+	require.ErrorAs(t, err, new(tmconsensus.RoundUnknownError))
+
+	// Compacting never removes the latest heights.
+	_, err = s.Compact(1)
+	require.Error(t, err)
+
+	res, err := s.Compact(gaction.MinRetainHeights)
+	require.NoError(t, err)
+	require.Equal(t, gaction.CompactResult{Removed: 1, Below: 9}, res)
+
+	// The highest height survives a restart.
+	s, err = gaction.NewStore(gtest.NewLogger(t), cfg)
+	require.NoError(t, err)
+	ra, err := s.LoadActions(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, "sig", ra.PrevoteSignature)
+	require.True(t, pubKey.Equal(ra.PubKey))
+
+	_, err = gaction.NewStore(gtest.NewLogger(t), gaction.StoreConfig{Dir: cfg.Dir, RetainHeights: 1})
+	require.Error(t, err)
+}
+
+func TestStore_fallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fx := tmconsensustest.NewStandardFixture(2)
+	pubKey := fx.ValidatorPubKey(0)
+
+	old := tmmemstore.NewActionStore()
+	vt := tmconsensus.VoteTarget{Height: 5, Round: 1, BlockHash: "hash"}
+	require.NoError(t, old.SavePrevoteAction(ctx, pubKey, vt, []byte("sig")))
+
+	cfg := newStoreConfig(t)
+	cfg.Fallback = old
+	s, err := gaction.NewStore(gtest.NewLogger(t), cfg)
+	require.NoError(t, err)
+
+	ra, err := s.LoadActions(ctx, 5, 1)
+	require.NoError(t, err)
+	require.Equal(t, "sig", ra.PrevoteSignature)
+
+	// Actions recorded only in the fallback store still count against double signing.
+	require.ErrorIs(
+		t,
+		s.SavePrevoteAction(ctx, pubKey, vt, []byte("other")),
+		tmstore.DoubleActionError{Type: "prevote"},
+	)
+
+	require.NoError(t, s.SavePrecommitAction(ctx, pubKey, vt, []byte("sig2")))
+	ra, err = s.LoadActions(ctx, 5, 1)
+	require.NoError(t, err)
+	require.Equal(t, "sig", ra.PrevoteSignature)
+	require.Equal(t, "sig2", ra.PrecommitSignature)
+}
@@ -0,0 +1,349 @@
+// Package gaudit keeps an append-only audit log
+// of the protocol violations the node detects,
+// separate from its regular log output.
+//
+// Each [Record] carries the evidence needed to repeat the check that failed,
+// so that an operator can verify a violation independently
+// before acting on it, for instance by reporting a validator.
+// The log file holds one JSON record per line.
+package gaudit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Kind is the kind of violation in a [Record].
+type Kind string
+
+const (
+	// A gossiped consensus message whose authentication signature did not verify.
+	// The evidence is an [InvalidSignatureEvidence].
+	KindInvalidSignature Kind = "invalid_signature"
+
+	// A validator signed two different proposed headers for the same height and round.
+	// The evidence is an [EquivocationEvidence].
+	KindEquivocation Kind = "equivocation"
+
+	// A validator signed a proposed header in a round where it was not the proposer.
+	// The evidence is an [InvalidProposerEvidence].
+	KindInvalidProposer Kind = "invalid_proposer"
+)
+
+// Record is a single entry in the audit log.
+type Record struct {
+	// Position in the log, starting at 1.
+	Seq uint64
+
+	Time time.Time
+	Kind Kind
+
+	Height uint64 `json:",omitempty"`
+	Round  uint32 `json:",omitempty"`
+
+	// The libp2p peer ID the offending message claimed to originate from, if known.
+	Peer string `json:",omitempty"`
+
+	// The public key of the offending validator, if known.
+	PubKey []byte `json:",omitempty"`
+
+	// Kind-specific evidence; see the Kind constants.
+	Evidence json.RawMessage
+
+	// Number of violations not recorded before this one
+	// because the log was over its rate limit.
+	Suppressed uint64 `json:",omitempty"`
+}
+
+// InvalidSignatureEvidence is the evidence for [KindInvalidSignature].
+type InvalidSignatureEvidence struct {
+	// The message as received, including its authentication envelope.
+	Envelope []byte
+}
+
+// EquivocationEvidence is the evidence for [KindEquivocation].
+type EquivocationEvidence struct {
+	// The two proposed headers, in the consensus codec's encoding.
+	A, B json.RawMessage
+}
+
+// InvalidProposerEvidence is the evidence for [KindInvalidProposer].
+type InvalidProposerEvidence struct {
+	// The proposed header, in the consensus codec's encoding.
+	ProposedHeader json.RawMessage
+
+	// The public key of the validator expected to propose in the round.
+	ExpectedProposer []byte
+}
+
+// Defaults for [LogConfig].
+const (
+	DefaultBurst    = 100
+	DefaultInterval = time.Second
+)
+
+// LogConfig is the configuration for [OpenLog].
+type LogConfig struct {
+	// Path of the log file, which is created if it does not exist.
+	Path string
+
+	// Codec for the consensus messages included in evidence.
+	// It must produce JSON, like the tmjson codec.
+	Codec tmcodec.Marshaler
+
+	// The log writes at most Burst records at once,
+	// and then one record per Interval,
+	// so that a flood of violations cannot fill the disk.
+	// Defaults to DefaultBurst and DefaultInterval if zero.
+	Burst    int
+	Interval time.Duration
+
+	// Optional clock; time.Now if nil.
+	Now func() time.Time
+}
+
+// Log is an append-only audit log.
+// It is safe for concurrent use.
+//
+// The methods recording violations may be called on a nil Log,
+// which records nothing.
+// Failures to record are logged rather than returned,
+// as the callers are on message handling paths that cannot act on them.
+type Log struct {
+	log *slog.Logger
+
+	path  string
+	codec tmcodec.Marshaler
+
+	burst    int
+	interval time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	f          *os.File
+	lastSeq    uint64
+	tokens     int
+	refilledAt time.Time
+	suppressed uint64
+}
+
+// OpenLog opens the audit log at cfg.Path for appending,
+// continuing the sequence of any records already present.
+func OpenLog(log *slog.Logger, cfg LogConfig) (*Log, error) {
+	if cfg.Burst == 0 {
+		cfg.Burst = DefaultBurst
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	l := &Log{
+		log: log,
+
+		path:  cfg.Path,
+		codec: cfg.Codec,
+
+		burst:    cfg.Burst,
+		interval: cfg.Interval,
+		now:      cfg.Now,
+
+		tokens:     cfg.Burst,
+		refilledAt: cfg.Now(),
+	}
+
+	rs, err := l.Records()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(rs) > 0 {
+		l.lastSeq = rs[len(rs)-1].Seq
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.f = f
+
+	// Terminate a partial record left by a crash,
+	// so that it does not swallow the next record.
+	if st, err := f.Stat(); err == nil && st.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, st.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("failed to terminate partial audit log record: %w", err)
+			}
+		}
+	}
+
+	return l, nil
+}
+
+// Path returns the path of the log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// Records returns every record in the log, oldest first.
+// A partially written final record, as left by a crash, is skipped.
+func (l *Log) Records() ([]Record, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var rs []Record
+	sc := bufio.NewScanner(f)
+	// Evidence may hold complete proposed headers.
+	sc.Buffer(nil, 16*1024*1024)
+	for sc.Scan() {
+		var r Record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			l.log.Warn("Skipping malformed audit log record", "index", len(rs), "err", err)
+			continue
+		}
+		rs = append(rs, r)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return rs, nil
+}
+
+// InvalidSignature records a gossiped message from peer
+// whose authentication signature did not verify.
+func (l *Log) InvalidSignature(peer string, envelope []byte) {
+	if l == nil {
+		return
+	}
+
+	l.append(Record{
+		Kind: KindInvalidSignature,
+		Peer: peer,
+	}, InvalidSignatureEvidence{Envelope: envelope})
+}
+
+// Equivocation records two different proposed headers
+// signed by the same proposer for the same height and round.
+func (l *Log) Equivocation(a, b tmconsensus.ProposedHeader) {
+	if l == nil {
+		return
+	}
+
+	aj, err := l.codec.MarshalProposedHeader(a)
+	if err != nil {
+		l.log.Warn("Failed to encode proposed header for audit log", "err", err)
+		return
+	}
+	bj, err := l.codec.MarshalProposedHeader(b)
+	if err != nil {
+		l.log.Warn("Failed to encode proposed header for audit log", "err", err)
+		return
+	}
+
+	l.append(Record{
+		Kind:   KindEquivocation,
+		Height: a.Header.Height,
+		Round:  a.Round,
+		PubKey: a.ProposerPubKey.PubKeyBytes(),
+	}, EquivocationEvidence{A: aj, B: bj})
+}
+
+// InvalidProposer records a proposed header signed by a validator
+// other than the expected proposer for its round.
+func (l *Log) InvalidProposer(ph tmconsensus.ProposedHeader, expected gcrypto.PubKey) {
+	if l == nil {
+		return
+	}
+
+	phj, err := l.codec.MarshalProposedHeader(ph)
+	if err != nil {
+		l.log.Warn("Failed to encode proposed header for audit log", "err", err)
+		return
+	}
+
+	l.append(Record{
+		Kind:   KindInvalidProposer,
+		Height: ph.Header.Height,
+		Round:  ph.Round,
+		PubKey: ph.ProposerPubKey.PubKeyBytes(),
+	}, InvalidProposerEvidence{
+		ProposedHeader:   phj,
+		ExpectedProposer: expected.PubKeyBytes(),
+	})
+}
+
+func (l *Log) append(r Record, evidence any) {
+	ev, err := json.Marshal(evidence)
+	if err != nil {
+		l.log.Warn("Failed to encode audit log evidence", "kind", r.Kind, "err", err)
+		return
+	}
+	r.Evidence = ev
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if n := int(now.Sub(l.refilledAt) / l.interval); n > 0 {
+		l.tokens = min(l.burst, l.tokens+n)
+		l.refilledAt = l.refilledAt.Add(time.Duration(n) * l.interval)
+	}
+	if l.tokens == 0 {
+		l.suppressed++
+		return
+	}
+	l.tokens--
+
+	r.Seq = l.lastSeq + 1
+	r.Time = now.UTC()
+	r.Suppressed = l.suppressed
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		l.log.Warn("Failed to encode audit log record", "kind", r.Kind, "err", err)
+		return
+	}
+	b = append(b, '\n')
+
+	// A single write per record, so that a crash leaves at most one partial line.
+	if _, err := l.f.Write(b); err != nil {
+		l.log.Error("Failed to write audit log record", "kind", r.Kind, "err", err)
+		return
+	}
+
+	l.lastSeq = r.Seq
+	l.suppressed = 0
+
+	l.log.Warn(
+		"Recorded protocol violation in audit log",
+		"kind", r.Kind, "seq", r.Seq,
+		"height", r.Height, "round", r.Round, "peer", r.Peer,
+	)
+}
@@ -0,0 +1,133 @@
+package gaudit_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+	codec := tmjson.MarshalCodec{CryptoRegistry: &reg}
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	a := fx.NextProposedHeader([]byte("a"), 0)
+	fx.SignProposal(ctx, &a, 0)
+	b := fx.NextProposedHeader([]byte("b"), 0)
+	fx.SignProposal(ctx, &b, 0)
+
+	path := filepath.Join(t.TempDir(), "data", "audit.jsonl")
+	l, err := gaudit.OpenLog(gtest.NewLogger(t), gaudit.LogConfig{Path: path, Codec: codec})
+	require.NoError(t, err)
+
+	l.InvalidSignature("peer1", []byte("envelope"))
+	l.Equivocation(a, b)
+	l.InvalidProposer(a, fx.ValidatorPubKey(1))
+	require.NoError(t, l.Close())
+
+	// Simulate a crash partway through a write.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`{"Seq":4,"Kind":`))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Reopening continues the sequence past the partial record.
+	l, err = gaudit.OpenLog(gtest.NewLogger(t), gaudit.LogConfig{Path: path, Codec: codec})
+	require.NoError(t, err)
+	defer l.Close()
+	l.InvalidSignature("peer2", []byte("envelope2"))
+
+	rs, err := l.Records()
+	require.NoError(t, err)
+	require.Len(t, rs, 4)
+	for i, r := range rs {
+		require.Equal(t, uint64(i+1), r.Seq)
+	}
+
+	require.Equal(t, gaudit.KindInvalidSignature, rs[0].Kind)
+	require.Equal(t, "peer1", rs[0].Peer)
+	var ise gaudit.InvalidSignatureEvidence
+	require.NoError(t, json.Unmarshal(rs[0].Evidence, &ise))
+	require.Equal(t, []byte("envelope"), ise.Envelope)
+
+	// The evidence holds both headers, whose signatures still verify.
+	require.Equal(t, gaudit.KindEquivocation, rs[1].Kind)
+	require.Equal(t, uint64(1), rs[1].Height)
+	require.Equal(t, fx.ValidatorPubKey(0).PubKeyBytes(), rs[1].PubKey)
+	var ee gaudit.EquivocationEvidence
+	require.NoError(t, json.Unmarshal(rs[1].Evidence, &ee))
+	for _, raw := range []json.RawMessage{ee.A, ee.B} {
+		var ph tmconsensus.ProposedHeader
+		require.NoError(t, codec.UnmarshalProposedHeader(raw, &ph))
+		signBytes, err := tmconsensus.ProposalSignBytes(ph.Header, ph.Round, ph.Annotations, fx.SignatureScheme)
+		require.NoError(t, err)
+		require.True(t, ph.ProposerPubKey.Verify(signBytes, ph.Signature))
+	}
+
+	require.Equal(t, gaudit.KindInvalidProposer, rs[2].Kind)
+	var ipe gaudit.InvalidProposerEvidence
+	require.NoError(t, json.Unmarshal(rs[2].Evidence, &ipe))
+	require.Equal(t, fx.ValidatorPubKey(1).PubKeyBytes(), ipe.ExpectedProposer)
+
+	require.Equal(t, "peer2", rs[3].Peer)
+}
+
+func TestLog_rateLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := gaudit.OpenLog(gtest.NewLogger(t), gaudit.LogConfig{
+		Path:     path,
+		Burst:    2,
+		Interval: time.Second,
+		Now:      func() time.Time { return now },
+	})
+	require.NoError(t, err)
+	defer l.Close()
+
+	for range 5 {
+		l.InvalidSignature("peer", nil)
+	}
+
+	rs, err := l.Records()
+	require.NoError(t, err)
+	require.Len(t, rs, 2)
+
+	// The next record after the interval reports how many were dropped.
+	now = now.Add(time.Second)
+	l.InvalidSignature("peer", nil)
+	l.InvalidSignature("peer", nil)
+
+	rs, err = l.Records()
+	require.NoError(t, err)
+	require.Len(t, rs, 3)
+	require.Zero(t, rs[1].Suppressed)
+	require.Equal(t, uint64(3), rs[2].Suppressed)
+}
+
+func TestLog_nil(t *testing.T) {
+	t.Parallel()
+
+	var l *gaudit.Log
+	l.InvalidSignature("peer", nil)
+	l.Equivocation(tmconsensus.ProposedHeader{}, tmconsensus.ProposedHeader{})
+	l.InvalidProposer(tmconsensus.ProposedHeader{}, nil)
+}
@@ -0,0 +1,60 @@
+package gbackpressure
+
+import (
+	"context"
+
+	"github.com/gordian-engine/gordian/gexchange"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Path names used by [ConsensusHandler].
+const (
+	PathProposedHeaders = "consensus_proposed_headers"
+	PathPrevotes        = "consensus_prevotes"
+	PathPrecommits      = "consensus_precommits"
+)
+
+// ConsensusHandler wraps a [tmconsensus.ConsensusHandler],
+// measuring each call on the path for its message type.
+// Handling a message in the engine's mirror is a synchronous hand-off to its kernel,
+// so these paths show backpressure from the mirror.
+type ConsensusHandler struct {
+	inner tmconsensus.ConsensusHandler
+
+	ph, prevote, precommit *Path
+}
+
+var _ tmconsensus.ConsensusHandler = (*ConsensusHandler)(nil)
+
+// NewConsensusHandler returns a new ConsensusHandler
+// delegating to inner and recording to paths in r.
+func NewConsensusHandler(r *Registry, inner tmconsensus.ConsensusHandler) *ConsensusHandler {
+	return &ConsensusHandler{
+		inner: inner,
+
+		ph:        r.Path(PathProposedHeaders),
+		prevote:   r.Path(PathPrevotes),
+		precommit: r.Path(PathPrecommits),
+	}
+}
+
+func (h *ConsensusHandler) HandleProposedHeader(
+	ctx context.Context, ph tmconsensus.ProposedHeader,
+) gexchange.Feedback {
+	defer h.ph.Begin()()
+	return h.inner.HandleProposedHeader(ctx, ph)
+}
+
+func (h *ConsensusHandler) HandlePrevoteProofs(
+	ctx context.Context, p tmconsensus.PrevoteSparseProof,
+) gexchange.Feedback {
+	defer h.prevote.Begin()()
+	return h.inner.HandlePrevoteProofs(ctx, p)
+}
+
+func (h *ConsensusHandler) HandlePrecommitProofs(
+	ctx context.Context, p tmconsensus.PrecommitSparseProof,
+) gexchange.Feedback {
+	defer h.precommit.Begin()()
+	return h.inner.HandlePrecommitProofs(ctx, p)
+}
@@ -0,0 +1,165 @@
+// Package gbackpressure measures how long the node's subsystems
+// block while handing work to the engine, and to one another.
+//
+// Each hand-off point is a [Path] in a [Registry].
+// A path tracks how many hand-offs are currently blocked,
+// and how long completed hand-offs were blocked,
+// so that saturation of one specific path is visible
+// before it grows into a consensus stall.
+package gbackpressure
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Registry is a set of named paths.
+// It is safe for concurrent use.
+type Registry struct {
+	log       *slog.Logger
+	warnAfter time.Duration
+
+	mu    sync.Mutex
+	paths map[string]*Path
+}
+
+// NewRegistry returns a new, empty Registry.
+// If warnAfter is positive, every path logs a warning
+// when a single hand-off stays blocked for longer than warnAfter.
+func NewRegistry(log *slog.Logger, warnAfter time.Duration) *Registry {
+	return &Registry{
+		log:       log,
+		warnAfter: warnAfter,
+
+		paths: make(map[string]*Path),
+	}
+}
+
+// Path returns the path with the given name, creating it if necessary.
+// Path may be called on a nil Registry, returning a nil Path,
+// which records nothing.
+func (r *Registry) Path(name string) *Path {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.paths[name]
+	if !ok {
+		p = &Path{
+			name:      name,
+			log:       r.log.With("path", name),
+			warnAfter: r.warnAfter,
+		}
+		r.paths[name] = p
+	}
+	return p
+}
+
+// Stats returns the statistics of every path in r, sorted by name.
+func (r *Registry) Stats() []Stats {
+	r.mu.Lock()
+	paths := make([]*Path, 0, len(r.paths))
+	for _, p := range r.paths {
+		paths = append(paths, p)
+	}
+	r.mu.Unlock()
+
+	stats := make([]Stats, len(paths))
+	for i, p := range paths {
+		stats[i] = p.Stats()
+	}
+	slices.SortFunc(stats, func(a, b Stats) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return stats
+}
+
+// Path is a single measured hand-off point.
+type Path struct {
+	name string
+
+	log       *slog.Logger
+	warnAfter time.Duration
+
+	inFlight    atomic.Int64
+	maxInFlight atomic.Int64
+
+	count        atomic.Uint64
+	totalBlocked atomic.Int64
+	maxBlocked   atomic.Int64
+}
+
+// Stats is a snapshot of a [Path]'s measurements.
+type Stats struct {
+	Name string
+
+	// Number of hand-offs currently blocked,
+	// and the most ever blocked at once.
+	InFlight, MaxInFlight int64
+
+	// Number of completed hand-offs.
+	Count uint64
+
+	// Cumulative and maximum time that completed hand-offs were blocked.
+	TotalBlocked, MaxBlocked time.Duration
+}
+
+// Begin marks the start of a hand-off on p.
+// The returned function must be called once the hand-off completes.
+// Begin may be called on a nil Path.
+func (p *Path) Begin() (end func()) {
+	if p == nil {
+		return func() {}
+	}
+
+	n := p.inFlight.Add(1)
+	storeMax(&p.maxInFlight, n)
+
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		p.inFlight.Add(-1)
+
+		p.count.Add(1)
+		p.totalBlocked.Add(int64(d))
+		storeMax(&p.maxBlocked, int64(d))
+
+		if p.warnAfter > 0 && d > p.warnAfter {
+			p.log.Warn(
+				"Hand-off blocked longer than threshold",
+				"dur", d, "threshold", p.warnAfter, "in_flight", n,
+			)
+		}
+	}
+}
+
+// Stats returns a snapshot of p's measurements.
+func (p *Path) Stats() Stats {
+	return Stats{
+		Name: p.name,
+
+		InFlight:    p.inFlight.Load(),
+		MaxInFlight: p.maxInFlight.Load(),
+
+		Count: p.count.Load(),
+
+		TotalBlocked: time.Duration(p.totalBlocked.Load()),
+		MaxBlocked:   time.Duration(p.maxBlocked.Load()),
+	}
+}
+
+func storeMax(v *atomic.Int64, n int64) {
+	for {
+		cur := v.Load()
+		if n <= cur || v.CompareAndSwap(cur, n) {
+			return
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package gbackpressure_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	r := gbackpressure.NewRegistry(gtest.NewLogger(t), time.Millisecond)
+	p := r.Path("b")
+	require.Same(t, p, r.Path("b"))
+
+	end1 := p.Begin()
+	end2 := p.Begin()
+
+	s := p.Stats()
+	require.Equal(t, int64(2), s.InFlight)
+	require.Equal(t, int64(2), s.MaxInFlight)
+	require.Zero(t, s.Count)
+
+	time.Sleep(5 * time.Millisecond)
+	end1()
+	end2()
+
+	s = p.Stats()
+	require.Zero(t, s.InFlight)
+	require.Equal(t, int64(2), s.MaxInFlight)
+	require.Equal(t, uint64(2), s.Count)
+	require.GreaterOrEqual(t, s.MaxBlocked, 5*time.Millisecond)
+	require.GreaterOrEqual(t, s.TotalBlocked, 2*s.MaxBlocked-time.Millisecond)
+
+	_ = r.Path("a")
+	stats := r.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, "a", stats[0].Name)
+	require.Equal(t, "b", stats[1].Name)
+}
+
+func TestPath_nil(t *testing.T) {
+	t.Parallel()
+
+	var r *gbackpressure.Registry
+	p := r.Path("x")
+	require.Nil(t, p)
+	p.Begin()()
+}
@@ -0,0 +1,95 @@
+// Package gchancfg holds the buffer sizes of the channels
+// between the engine and the gcosmos subsystems.
+//
+// The defaults suit small validator sets.
+// With a large validator set, larger buffers trade memory
+// for fewer blocked sends on the busiest paths.
+// The [gbackpressure] measurements show which paths are blocking.
+//
+// [gbackpressure]: github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure
+package gchancfg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxSize is the largest accepted buffer size.
+const MaxSize = 4096
+
+// Sizes are the channel buffer sizes.
+type Sizes struct {
+	// Finalization requests from the engine's state machine to the driver.
+	FinalizeBlockRequests int
+
+	// Committed headers from the catchup client to the engine's mirror.
+	ReplayedHeaderRequests int
+
+	// Peer connection changes delivered to the catchup client.
+	// Must be at least 1, so that peer events do not wait on an active fetch.
+	CatchupPeerRequests int
+}
+
+// Default returns the default sizes.
+func Default() Sizes {
+	return Sizes{
+		CatchupPeerRequests: 8,
+	}
+}
+
+// names maps the names accepted by [Parse] to their fields.
+var names = map[string]func(*Sizes) *int{
+	"finalize_block_requests":  func(s *Sizes) *int { return &s.FinalizeBlockRequests },
+	"replayed_header_requests": func(s *Sizes) *int { return &s.ReplayedHeaderRequests },
+	"catchup_peer_requests":    func(s *Sizes) *int { return &s.CatchupPeerRequests },
+}
+
+// Validate reports an error if any size in s is out of range.
+func (s Sizes) Validate() error {
+	for name, f := range names {
+		n := *f(&s)
+		if n < 0 || n > MaxSize {
+			return fmt.Errorf("channel size %s=%d out of range [0, %d]", name, n, MaxSize)
+		}
+	}
+	if s.CatchupPeerRequests < 1 {
+		return errors.New("channel size catchup_peer_requests must be at least 1")
+	}
+	return nil
+}
+
+// Parse parses a comma-separated list of name=size pairs,
+// such as "finalize_block_requests=2,replayed_header_requests=32",
+// starting from the [Default] sizes.
+// An empty string returns the defaults.
+func Parse(s string) (Sizes, error) {
+	sizes := Default()
+	if strings.TrimSpace(s) == "" {
+		return sizes, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return Sizes{}, fmt.Errorf("channel size %q must be of the form name=size", pair)
+		}
+
+		f, ok := names[name]
+		if !ok {
+			return Sizes{}, fmt.Errorf("unknown channel %q", name)
+		}
+
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return Sizes{}, fmt.Errorf("invalid size for channel %q: %w", name, err)
+		}
+		*f(&sizes) = n
+	}
+
+	if err := sizes.Validate(); err != nil {
+		return Sizes{}, err
+	}
+	return sizes, nil
+}
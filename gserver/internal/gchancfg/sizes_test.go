@@ -0,0 +1,46 @@
+package gchancfg_test
+
+import (
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gchancfg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	s, err := gchancfg.Parse("")
+	require.NoError(t, err)
+	require.Equal(t, gchancfg.Default(), s)
+
+	s, err = gchancfg.Parse("finalize_block_requests=2, replayed_header_requests=32")
+	require.NoError(t, err)
+	require.Equal(t, gchancfg.Sizes{
+		FinalizeBlockRequests:  2,
+		ReplayedHeaderRequests: 32,
+		CatchupPeerRequests:    8,
+	}, s)
+}
+
+func TestParse_invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{
+		"finalize_block_requests",
+		"unknown=1",
+		"replayed_header_requests=x",
+		"replayed_header_requests=-1",
+		"replayed_header_requests=100000",
+		"catchup_peer_requests=0",
+	} {
+		_, err := gchancfg.Parse(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestDefault_valid(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, gchancfg.Default().Validate())
+}
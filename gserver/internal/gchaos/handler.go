@@ -0,0 +1,273 @@
+// Package gchaos injects faults into incoming consensus messages,
+// to exercise the engine's handling of duplicated and reordered network traffic.
+//
+// It is only intended for use in test networks.
+package gchaos
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Config controls which faults a [Handler] injects.
+type Config struct {
+	// Seed for the random decisions of which messages are affected.
+	// Decisions are reproducible for the same seed and message order,
+	// although the delivery timing of delayed messages still depends on the scheduler.
+	Seed uint64
+
+	// Percentage, 0-100, of messages to deliver a second time after a random delay.
+	DuplicatePercent uint8
+
+	// Percentage, 0-100, of messages to deliver only after a random delay,
+	// effectively reordering them with respect to later messages.
+	DelayPercent uint8
+
+	// Upper bound of the random delay.
+	// If zero and delays are enabled, 250ms is used.
+	MaxDelay time.Duration
+}
+
+// Enabled reports whether c injects any faults.
+func (c Config) Enabled() bool {
+	return c.DuplicatePercent > 0 || c.DelayPercent > 0
+}
+
+// ParseConfig parses a comma-separated list of key=value pairs into a Config.
+// The recognized keys are seed, dup, delay, and max-delay,
+// for example "seed=1,dup=5,delay=10,max-delay=200ms".
+// The empty string results in a disabled Config.
+func ParseConfig(s string) (Config, error) {
+	var c Config
+	if s == "" {
+		return c, nil
+	}
+
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			return Config{}, fmt.Errorf("invalid chaos setting %q: must be key=value", kv)
+		}
+
+		switch k {
+		case "seed":
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid seed %q: %w", v, err)
+			}
+			c.Seed = n
+		case "dup", "delay":
+			n, err := strconv.ParseUint(v, 10, 8)
+			if err != nil || n > 100 {
+				return Config{}, fmt.Errorf("invalid %s percentage %q: must be 0-100", k, v)
+			}
+			if k == "dup" {
+				c.DuplicatePercent = uint8(n)
+			} else {
+				c.DelayPercent = uint8(n)
+			}
+		case "max-delay":
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return Config{}, fmt.Errorf("invalid max-delay %q", v)
+			}
+			c.MaxDelay = d
+		default:
+			return Config{}, fmt.Errorf("unknown chaos setting %q", k)
+		}
+	}
+
+	if c.DuplicatePercent+c.DelayPercent > 100 {
+		return Config{}, fmt.Errorf(
+			"dup (%d) and delay (%d) percentages must not sum beyond 100",
+			c.DuplicatePercent, c.DelayPercent,
+		)
+	}
+
+	return c, nil
+}
+
+// Handler is a [tmconsensus.FineGrainedConsensusHandler]
+// that duplicates and delays a portion of messages
+// before passing them to another handler.
+//
+// A delayed message is reported as accepted immediately,
+// and its actual result is only logged when it is eventually delivered.
+type Handler struct {
+	ctx context.Context
+	log *slog.Logger
+
+	inner tmconsensus.FineGrainedConsensusHandler
+
+	dupPct, delayPct uint8
+	maxDelay         time.Duration
+
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	wg sync.WaitGroup
+}
+
+var _ tmconsensus.FineGrainedConsensusHandler = (*Handler)(nil)
+
+// NewHandler returns a new Handler wrapping inner.
+// Pending delayed deliveries are abandoned when ctx is canceled;
+// use [*Handler.Wait] to block until they have all finished.
+func NewHandler(
+	ctx context.Context,
+	log *slog.Logger,
+	inner tmconsensus.FineGrainedConsensusHandler,
+	cfg Config,
+) *Handler {
+	maxDelay := cfg.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 250 * time.Millisecond
+	}
+
+	log.Warn(
+		"Chaos mode enabled for incoming consensus messages",
+		"seed", cfg.Seed,
+		"dup_pct", cfg.DuplicatePercent,
+		"delay_pct", cfg.DelayPercent,
+		"max_delay", maxDelay,
+	)
+
+	return &Handler{
+		ctx: ctx,
+		log: log,
+
+		inner: inner,
+
+		dupPct:   cfg.DuplicatePercent,
+		delayPct: cfg.DelayPercent,
+		maxDelay: maxDelay,
+
+		rng: rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+	}
+}
+
+// Wait blocks until all delayed deliveries have completed or been abandoned.
+func (h *Handler) Wait() {
+	h.wg.Wait()
+}
+
+type fault uint8
+
+const (
+	faultNone fault = iota
+	faultDuplicate
+	faultDelay
+)
+
+// roll decides the fault to apply to the next message,
+// and the delay to use if the fault is not faultNone.
+func (h *Handler) roll() (fault, time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := uint8(h.rng.UintN(100))
+	d := time.Duration(h.rng.Int64N(int64(h.maxDelay)) + 1)
+
+	switch {
+	case n < h.dupPct:
+		return faultDuplicate, d
+	case n < h.dupPct+h.delayPct:
+		return faultDelay, d
+	default:
+		return faultNone, 0
+	}
+}
+
+// later calls fn after d, unless the handler's context is canceled first.
+func (h *Handler) later(d time.Duration, fn func()) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-h.ctx.Done():
+		case <-t.C:
+			fn()
+		}
+	}()
+}
+
+func (h *Handler) HandleProposedHeader(
+	ctx context.Context, ph tmconsensus.ProposedHeader,
+) tmconsensus.HandleProposedHeaderResult {
+	f, d := h.roll()
+	deliver := func() {
+		res := h.inner.HandleProposedHeader(h.ctx, ph)
+		h.log.Debug(
+			"Delivered chaos-affected proposed header",
+			"height", ph.Header.Height, "round", ph.Round, "result", res,
+		)
+	}
+
+	switch f {
+	case faultDelay:
+		h.later(d, deliver)
+		return tmconsensus.HandleProposedHeaderAccepted
+	case faultDuplicate:
+		h.later(d, deliver)
+	}
+
+	return h.inner.HandleProposedHeader(ctx, ph)
+}
+
+func (h *Handler) HandlePrevoteProofs(
+	ctx context.Context, p tmconsensus.PrevoteSparseProof,
+) tmconsensus.HandleVoteProofsResult {
+	f, d := h.roll()
+	deliver := func() {
+		res := h.inner.HandlePrevoteProofs(h.ctx, p)
+		h.log.Debug(
+			"Delivered chaos-affected prevote proofs",
+			"height", p.Height, "round", p.Round, "result", res,
+		)
+	}
+
+	switch f {
+	case faultDelay:
+		h.later(d, deliver)
+		return tmconsensus.HandleVoteProofsAccepted
+	case faultDuplicate:
+		h.later(d, deliver)
+	}
+
+	return h.inner.HandlePrevoteProofs(ctx, p)
+}
+
+func (h *Handler) HandlePrecommitProofs(
+	ctx context.Context, p tmconsensus.PrecommitSparseProof,
+) tmconsensus.HandleVoteProofsResult {
+	f, d := h.roll()
+	deliver := func() {
+		res := h.inner.HandlePrecommitProofs(h.ctx, p)
+		h.log.Debug(
+			"Delivered chaos-affected precommit proofs",
+			"height", p.Height, "round", p.Round, "result", res,
+		)
+	}
+
+	switch f {
+	case faultDelay:
+		h.later(d, deliver)
+		return tmconsensus.HandleVoteProofsAccepted
+	case faultDuplicate:
+		h.later(d, deliver)
+	}
+
+	return h.inner.HandlePrecommitProofs(ctx, p)
+}
@@ -0,0 +1,123 @@
+package gchaos_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gchaos"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	c, err := gchaos.ParseConfig("")
+	require.NoError(t, err)
+	require.False(t, c.Enabled())
+
+	c, err = gchaos.ParseConfig("seed=7, dup=5,delay=10,max-delay=200ms")
+	require.NoError(t, err)
+	require.True(t, c.Enabled())
+	require.Equal(t, gchaos.Config{
+		Seed:             7,
+		DuplicatePercent: 5,
+		DelayPercent:     10,
+		MaxDelay:         200 * time.Millisecond,
+	}, c)
+
+	for _, bad := range []string{
+		"seed",
+		"dup=101",
+		"dup=60,delay=60",
+		"max-delay=soon",
+		"unknown=1",
+	} {
+		_, err := gchaos.ParseConfig(bad)
+		require.Errorf(t, err, "expected error for %q", bad)
+	}
+}
+
+func TestHandler_duplicate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var inner countingHandler
+	h := gchaos.NewHandler(ctx, gtest.NewLogger(t), &inner, gchaos.Config{
+		DuplicatePercent: 100,
+		MaxDelay:         time.Millisecond,
+	})
+
+	const n = 10
+	for range n {
+		require.Equal(
+			t,
+			tmconsensus.HandleVoteProofsAccepted,
+			h.HandlePrevoteProofs(ctx, tmconsensus.PrevoteSparseProof{Height: 1}),
+		)
+	}
+
+	// Every message is delivered once immediately.
+	require.GreaterOrEqual(t, inner.prevotes.Load(), int32(n))
+
+	// And then once more after the delay.
+	h.Wait()
+	require.Equal(t, int32(2*n), inner.prevotes.Load())
+}
+
+func TestHandler_delay(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var inner countingHandler
+	h := gchaos.NewHandler(ctx, gtest.NewLogger(t), &inner, gchaos.Config{
+		DelayPercent: 100,
+		MaxDelay:     time.Hour,
+	})
+
+	require.Equal(
+		t,
+		tmconsensus.HandleVoteProofsAccepted,
+		h.HandlePrecommitProofs(ctx, tmconsensus.PrecommitSparseProof{Height: 1}),
+	)
+
+	// Reported as accepted, but not yet delivered.
+	require.Zero(t, inner.precommits.Load())
+
+	// Canceling the context abandons the delivery.
+	cancel()
+	h.Wait()
+	require.Zero(t, inner.precommits.Load())
+}
+
+type countingHandler struct {
+	phs, prevotes, precommits atomic.Int32
+}
+
+func (h *countingHandler) HandleProposedHeader(
+	context.Context, tmconsensus.ProposedHeader,
+) tmconsensus.HandleProposedHeaderResult {
+	h.phs.Add(1)
+	return tmconsensus.HandleProposedHeaderAccepted
+}
+
+func (h *countingHandler) HandlePrevoteProofs(
+	context.Context, tmconsensus.PrevoteSparseProof,
+) tmconsensus.HandleVoteProofsResult {
+	h.prevotes.Add(1)
+	return tmconsensus.HandleVoteProofsAccepted
+}
+
+func (h *countingHandler) HandlePrecommitProofs(
+	context.Context, tmconsensus.PrecommitSparseProof,
+) tmconsensus.HandleVoteProofsResult {
+	h.precommits.Add(1)
+	return tmconsensus.HandleVoteProofsAccepted
+}
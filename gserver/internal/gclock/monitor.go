@@ -0,0 +1,336 @@
+// Package gclock measures the clock skew between the local node and its peers.
+//
+// Proposer-based timestamps and the engine's round timeouts
+// both assume that validators' clocks roughly agree;
+// a validator with a skewed clock may reject valid proposals
+// or time out of rounds early.
+//
+// A [Monitor] periodically sends a heartbeat to every connected peer,
+// and the peer responds with its current time,
+// signed with its libp2p identity key along with the heartbeat's nonce.
+// The skew is the difference between the peer's time
+// and the local time halfway through the round trip.
+package gclock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime/trace"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+	libp2pprotocol "github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// ProtocolID is the libp2p protocol for heartbeats.
+const ProtocolID = libp2pprotocol.ID("/gcosmos/clock/v1")
+
+// signPrefix provides domain separation for the signed content.
+const signPrefix = "gcosmos/clock/v1\x00"
+
+// DefaultInterval is the heartbeat interval used when [MonitorConfig.Interval] is zero.
+const DefaultInterval = 30 * time.Second
+
+// How long to wait for a single peer's heartbeat response.
+const heartbeatTimeout = 5 * time.Second
+
+const nonceSize = 16
+
+type heartbeatRequest struct {
+	Nonce []byte
+}
+
+type heartbeatResponse struct {
+	// Nanoseconds since the Unix epoch.
+	UnixNano int64
+
+	Sig []byte
+}
+
+// PeerSkew is the most recent measurement of a single peer's clock.
+type PeerSkew struct {
+	Peer string
+
+	// How far the peer's clock is ahead of the local clock;
+	// negative if the peer's clock is behind.
+	Skew time.Duration
+
+	// The round trip time of the heartbeat,
+	// which bounds the error of the skew measurement.
+	RTT time.Duration
+
+	MeasuredAt time.Time
+
+	// Whether the magnitude of Skew exceeds the monitor's threshold.
+	Exceeds bool
+}
+
+// MonitorConfig is the configuration for [NewMonitor].
+type MonitorConfig struct {
+	Host libp2phost.Host
+
+	// The local node's identity key, used to sign heartbeat responses.
+	Identity libp2pcrypto.PrivKey
+
+	// How often to send heartbeats to every connected peer.
+	// Defaults to DefaultInterval if zero.
+	Interval time.Duration
+
+	// The skew magnitude beyond which a peer is reported.
+	Threshold time.Duration
+
+	// Optional clock for the time in heartbeat responses; time.Now if nil.
+	Now func() time.Time
+}
+
+// Monitor responds to heartbeats from peers,
+// and sends heartbeats to peers to measure their clock skew.
+type Monitor struct {
+	log *slog.Logger
+
+	host     libp2phost.Host
+	identity libp2pcrypto.PrivKey
+
+	interval  time.Duration
+	threshold time.Duration
+
+	now func() time.Time
+
+	mu    sync.Mutex
+	skews map[libp2ppeer.ID]PeerSkew
+
+	done chan struct{}
+}
+
+// NewMonitor returns a new Monitor based on cfg.
+// The monitor handles heartbeat streams on cfg.Host until ctx is canceled.
+func NewMonitor(ctx context.Context, log *slog.Logger, cfg MonitorConfig) (*Monitor, error) {
+	if cfg.Identity == nil {
+		return nil, errors.New("identity key is required")
+	}
+	if cfg.Threshold <= 0 {
+		return nil, errors.New("skew threshold must be positive")
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	m := &Monitor{
+		log: log,
+
+		host:     cfg.Host,
+		identity: cfg.Identity,
+
+		interval:  interval,
+		threshold: cfg.Threshold,
+
+		now: cfg.Now,
+
+		skews: make(map[libp2ppeer.ID]PeerSkew),
+
+		done: make(chan struct{}),
+	}
+
+	m.host.SetStreamHandler(ProtocolID, m.handleStream)
+
+	go m.kernel(ctx)
+
+	return m, nil
+}
+
+// Wait blocks until m has stopped.
+func (m *Monitor) Wait() {
+	<-m.done
+}
+
+// Skews returns the latest measurement for each peer, sorted by peer ID.
+func (m *Monitor) Skews() []PeerSkew {
+	m.mu.Lock()
+	out := make([]PeerSkew, 0, len(m.skews))
+	for _, s := range m.skews {
+		out = append(out, s)
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b PeerSkew) int {
+		return strings.Compare(a.Peer, b.Peer)
+	})
+	return out
+}
+
+func (m *Monitor) kernel(ctx context.Context) {
+	defer close(m.done)
+	defer m.host.RemoveStreamHandler(ProtocolID)
+
+	ctx, task := trace.NewTask(ctx, "Monitor.kernel")
+	defer task.End()
+
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case <-t.C:
+			m.measureAll(ctx)
+		}
+	}
+}
+
+// measureAll sends a heartbeat to every connected peer concurrently,
+// returning once every heartbeat has completed.
+func (m *Monitor) measureAll(ctx context.Context) {
+	peers := m.host.Network().Peers()
+
+	var wg sync.WaitGroup
+	wg.Add(len(peers))
+	for _, p := range peers {
+		go func() {
+			defer wg.Done()
+			m.measure(ctx, p)
+		}()
+	}
+	wg.Wait()
+
+	// Forget peers that are no longer connected.
+	m.mu.Lock()
+	for p := range m.skews {
+		if !slices.Contains(peers, p) {
+			delete(m.skews, p)
+		}
+	}
+	m.mu.Unlock()
+}
+
+func (m *Monitor) measure(ctx context.Context, p libp2ppeer.ID) {
+	ctx, cancel := context.WithTimeout(ctx, heartbeatTimeout)
+	defer cancel()
+
+	nonce := make([]byte, nonceSize)
+	_, _ = rand.Read(nonce)
+
+	s, err := m.host.NewStream(ctx, p, ProtocolID)
+	if err != nil {
+		// Most likely a peer without the monitor enabled.
+		m.log.Debug("Failed to open heartbeat stream to peer", "peer_id", p, "err", err)
+		return
+	}
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(heartbeatTimeout))
+
+	sent := time.Now()
+	if err := json.NewEncoder(s).Encode(heartbeatRequest{Nonce: nonce}); err != nil {
+		m.log.Debug("Failed to send heartbeat to peer", "peer_id", p, "err", err)
+		return
+	}
+	_ = s.CloseWrite()
+
+	var res heartbeatResponse
+	err = json.NewDecoder(io.LimitReader(s, 4*1024)).Decode(&res)
+	rtt := time.Since(sent)
+	if err != nil {
+		m.log.Debug("Failed to read heartbeat response from peer", "peer_id", p, "err", err)
+		return
+	}
+
+	pub := s.Conn().RemotePublicKey()
+	if pub == nil {
+		m.log.Info("Ignoring heartbeat response from peer without a public key", "peer_id", p)
+		return
+	}
+	ok, err := pub.Verify(signBytes(nonce, res.UnixNano), res.Sig)
+	if err != nil || !ok {
+		m.log.Info("Ignoring heartbeat response with invalid signature", "peer_id", p, "err", err)
+		return
+	}
+
+	// The peer's time has no monotonic reading,
+	// so the subtraction compares wall clocks, which is what is being measured.
+	mid := sent.Add(rtt / 2)
+	skew := time.Unix(0, res.UnixNano).Sub(mid)
+
+	ps := PeerSkew{
+		Peer: p.String(),
+
+		Skew: skew,
+		RTT:  rtt,
+
+		MeasuredAt: time.Now(),
+
+		Exceeds: skew > m.threshold || skew < -m.threshold,
+	}
+
+	m.mu.Lock()
+	prev, hadPrev := m.skews[p]
+	m.skews[p] = ps
+	m.mu.Unlock()
+
+	if ps.Exceeds && !(hadPrev && prev.Exceeds) {
+		m.log.Warn(
+			"Peer clock skew exceeds threshold",
+			"event", "clock_skew",
+			"peer_id", p,
+			"skew", skew,
+			"rtt", rtt,
+			"threshold", m.threshold,
+		)
+	} else if !ps.Exceeds && hadPrev && prev.Exceeds {
+		m.log.Info("Peer clock skew back within threshold", "peer_id", p, "skew", skew)
+	}
+}
+
+func (m *Monitor) handleStream(s libp2pnetwork.Stream) {
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(heartbeatTimeout))
+
+	var req heartbeatRequest
+	if err := json.NewDecoder(io.LimitReader(s, 1024)).Decode(&req); err != nil {
+		return
+	}
+	_ = s.CloseRead()
+
+	if len(req.Nonce) != nonceSize {
+		return
+	}
+
+	now := m.now().UnixNano()
+	sig, err := m.identity.Sign(signBytes(req.Nonce, now))
+	if err != nil {
+		m.log.Warn("Failed to sign heartbeat response", "err", err)
+		return
+	}
+
+	_ = json.NewEncoder(s).Encode(heartbeatResponse{
+		UnixNano: now,
+		Sig:      sig,
+	})
+}
+
+// signBytes returns the content signed in a heartbeat response:
+// the domain separation prefix, the nonce, and the big-endian time.
+func signBytes(nonce []byte, unixNano int64) []byte {
+	b := make([]byte, 0, len(signPrefix)+len(nonce)+8)
+	b = append(b, signPrefix...)
+	b = append(b, nonce...)
+	b = binary.BigEndian.AppendUint64(b, uint64(unixNano))
+	return b
+}
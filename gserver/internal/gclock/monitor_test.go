@@ -0,0 +1,86 @@
+package gclock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gclock"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p/tmlibp2ptest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitor(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	log := gtest.NewLogger(t)
+	net, err := tmlibp2ptest.NewNetwork(ctx, log.With("sys", "net"), tmjson.MarshalCodec{CryptoRegistry: reg})
+	require.NoError(t, err)
+	defer net.Wait()
+	defer cancel()
+
+	c1, err := net.Connect(ctx)
+	require.NoError(t, err)
+	c2, err := net.Connect(ctx)
+	require.NoError(t, err)
+	require.NoError(t, net.Stabilize(ctx))
+
+	h1 := c1.Host().Libp2pHost()
+	m1, err := gclock.NewMonitor(ctx, log.With("node", 1), gclock.MonitorConfig{
+		Host:      h1,
+		Identity:  h1.Peerstore().PrivKey(h1.ID()),
+		Interval:  10 * time.Millisecond,
+		Threshold: time.Second,
+	})
+	require.NoError(t, err)
+	defer m1.Wait()
+	defer cancel()
+
+	// The second node reports a clock a minute ahead,
+	// but measures using the real clock.
+	h2 := c2.Host().Libp2pHost()
+	m2, err := gclock.NewMonitor(ctx, log.With("node", 2), gclock.MonitorConfig{
+		Host:      h2,
+		Identity:  h2.Peerstore().PrivKey(h2.ID()),
+		Interval:  10 * time.Millisecond,
+		Threshold: time.Second,
+		Now: func() time.Time {
+			return time.Now().Add(time.Minute)
+		},
+	})
+	require.NoError(t, err)
+	defer m2.Wait()
+	defer cancel()
+
+	require.Eventually(t, func() bool {
+		return len(m1.Skews()) == 1 && len(m2.Skews()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	s1 := m1.Skews()[0]
+	require.Equal(t, h2.ID().String(), s1.Peer)
+	require.True(t, s1.Exceeds)
+	require.InDelta(t, time.Minute, s1.Skew, float64(time.Second))
+
+	s2 := m2.Skews()[0]
+	require.Equal(t, h1.ID().String(), s2.Peer)
+	require.False(t, s2.Exceeds)
+	require.InDelta(t, 0, s2.Skew, float64(time.Second))
+}
+
+func TestNewMonitor_validation(t *testing.T) {
+	t.Parallel()
+
+	_, err := gclock.NewMonitor(context.Background(), gtest.NewLogger(t), gclock.MonitorConfig{
+		Threshold: time.Second,
+	})
+	require.Error(t, err)
+}
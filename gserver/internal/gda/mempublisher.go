@@ -0,0 +1,65 @@
+package gda
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemPublisher is an in-memory [Publisher],
+// for tests and for exercising rollup mode without a real DA layer.
+type MemPublisher struct {
+	// Simulated time for the DA layer to include a block.
+	inclusionDelay time.Duration
+
+	mu     sync.Mutex
+	blocks []Block
+}
+
+// NewMemPublisher returns a MemPublisher that waits inclusionDelay
+// before acknowledging each published block.
+func NewMemPublisher(inclusionDelay time.Duration) *MemPublisher {
+	return &MemPublisher{inclusionDelay: inclusionDelay}
+}
+
+func (p *MemPublisher) Publish(ctx context.Context, b Block) (Receipt, error) {
+	if p.inclusionDelay > 0 {
+		t := time.NewTimer(p.inclusionDelay)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return Receipt{}, context.Cause(ctx)
+		case <-t.C:
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if n := len(p.blocks); n > 0 && p.blocks[n-1].Height >= b.Height {
+		return Receipt{}, fmt.Errorf(
+			"blocks must be published in height order: got %d after %d",
+			b.Height, p.blocks[n-1].Height,
+		)
+	}
+
+	b.Hash = bytes.Clone(b.Hash)
+	b.Data = bytes.Clone(b.Data)
+	b.AppStateHash = bytes.Clone(b.AppStateHash)
+	p.blocks = append(p.blocks, b)
+
+	return Receipt{Ref: fmt.Sprintf("mem:%d", len(p.blocks)-1)}, nil
+}
+
+// Blocks returns a copy of the blocks published so far.
+func (p *MemPublisher) Blocks() []Block {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]Block, len(p.blocks))
+	copy(out, p.blocks)
+	return out
+}
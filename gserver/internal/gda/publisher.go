@@ -0,0 +1,67 @@
+// Package gda (short for "Gordian Data Availability")
+// publishes committed block data to an external data availability layer,
+// for chains running as rollups.
+package gda
+
+import (
+	"context"
+	"fmt"
+)
+
+// Block is the committed block data handed to a [Publisher].
+type Block struct {
+	Height uint64
+	Hash   []byte
+
+	// The block's data ID and the encoded transactions it refers to.
+	// Data is nil for blocks without transactions.
+	DataID string
+	Data   []byte
+
+	// The app state hash after applying the block.
+	AppStateHash []byte
+}
+
+// Receipt is the data availability layer's acknowledgement
+// that a block was included.
+type Receipt struct {
+	// Layer-specific reference to the published data,
+	// such as a height and commitment on the DA chain.
+	Ref string
+}
+
+// Publisher publishes committed blocks to a data availability layer.
+type Publisher interface {
+	// Publish blocks until b is included in the data availability layer,
+	// or until ctx is canceled.
+	// Blocks are always published in height order.
+	Publish(ctx context.Context, b Block) (Receipt, error)
+}
+
+// Gating controls whether committing a block
+// waits for the block to be published.
+type Gating uint8
+
+const (
+	// GatingOptimistic publishes blocks in the background,
+	// without delaying the next height.
+	GatingOptimistic Gating = iota
+
+	// GatingWaitForInclusion does not report a block as finalized
+	// until the data availability layer has included it.
+	GatingWaitForInclusion
+)
+
+// ParseGating parses the string form of a Gating value,
+// either "optimistic" or "wait".
+// The empty string is treated as optimistic.
+func ParseGating(s string) (Gating, error) {
+	switch s {
+	case "", "optimistic":
+		return GatingOptimistic, nil
+	case "wait":
+		return GatingWaitForInclusion, nil
+	default:
+		return 0, fmt.Errorf("unknown DA gating %q (must be optimistic or wait)", s)
+	}
+}
@@ -0,0 +1,165 @@
+package gda
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/gchan"
+	"github.com/gordian-engine/gcosmos/internal/copy/glog"
+)
+
+// Queue publishes blocks in order on a background goroutine,
+// retrying failed publications,
+// and applies a [Gating] policy to callers of [*Queue.Submit].
+type Queue struct {
+	log *slog.Logger
+
+	pub    Publisher
+	gating Gating
+
+	retryDelay time.Duration
+
+	reqs chan submitRequest
+
+	done chan struct{}
+}
+
+// QueueConfig is the configuration for [NewQueue].
+type QueueConfig struct {
+	Publisher Publisher
+	Gating    Gating
+
+	// How long to wait before retrying a failed publication.
+	// If zero, one second is used.
+	RetryDelay time.Duration
+
+	// How many blocks may be waiting for publication
+	// before an optimistic Submit call blocks.
+	// If zero, 64 is used.
+	MaxPending int
+}
+
+type submitRequest struct {
+	B Block
+
+	// Only set for GatingWaitForInclusion.
+	Resp chan Receipt
+}
+
+// NewQueue returns a new Queue.
+// The Queue stops publishing when ctx is canceled;
+// use [*Queue.Wait] to block until its goroutine has finished.
+func NewQueue(ctx context.Context, log *slog.Logger, cfg QueueConfig) *Queue {
+	retryDelay := cfg.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = time.Second
+	}
+	maxPending := cfg.MaxPending
+	if maxPending <= 0 {
+		maxPending = 64
+	}
+
+	q := &Queue{
+		log: log,
+
+		pub:    cfg.Publisher,
+		gating: cfg.Gating,
+
+		retryDelay: retryDelay,
+
+		reqs: make(chan submitRequest, maxPending),
+
+		done: make(chan struct{}),
+	}
+
+	go q.run(ctx)
+
+	return q
+}
+
+// Wait blocks until q's background goroutine has finished.
+func (q *Queue) Wait() {
+	<-q.done
+}
+
+// Submit queues b for publication.
+//
+// With [GatingOptimistic], Submit returns as soon as b is queued.
+// With [GatingWaitForInclusion], Submit blocks until b has been published.
+//
+// Submit reports false if ctx is canceled first.
+func (q *Queue) Submit(ctx context.Context, b Block) bool {
+	req := submitRequest{B: b}
+	if q.gating == GatingOptimistic {
+		return gchan.SendC(
+			ctx, q.log,
+			q.reqs, req,
+			"queueing block for DA publication",
+		)
+	}
+
+	req.Resp = make(chan Receipt, 1)
+	_, ok := gchan.ReqResp(
+		ctx, q.log,
+		q.reqs, req,
+		req.Resp,
+		"waiting for DA inclusion",
+	)
+	return ok
+}
+
+func (q *Queue) run(ctx context.Context) {
+	defer close(q.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-q.reqs:
+			r, ok := q.publish(ctx, req.B)
+			if !ok {
+				return
+			}
+			if req.Resp != nil {
+				// Buffered, so this does not block.
+				req.Resp <- r
+			}
+		}
+	}
+}
+
+// publish publishes b, retrying until it succeeds or ctx is canceled.
+func (q *Queue) publish(ctx context.Context, b Block) (Receipt, bool) {
+	for {
+		r, err := q.pub.Publish(ctx, b)
+		if err == nil {
+			q.log.Debug(
+				"Published block to DA layer",
+				"height", b.Height,
+				"hash", glog.Hex(b.Hash),
+				"ref", r.Ref,
+			)
+			return r, true
+		}
+
+		if ctx.Err() != nil {
+			return Receipt{}, false
+		}
+
+		q.log.Warn(
+			"Failed to publish block to DA layer; will retry",
+			"height", b.Height,
+			"retry_delay", q.retryDelay,
+			"err", err,
+		)
+
+		t := time.NewTimer(q.retryDelay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return Receipt{}, false
+		case <-t.C:
+		}
+	}
+}
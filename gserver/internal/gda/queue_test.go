@@ -0,0 +1,98 @@
+package gda_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gda"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGating(t *testing.T) {
+	t.Parallel()
+
+	g, err := gda.ParseGating("")
+	require.NoError(t, err)
+	require.Equal(t, gda.GatingOptimistic, g)
+
+	g, err = gda.ParseGating("wait")
+	require.NoError(t, err)
+	require.Equal(t, gda.GatingWaitForInclusion, g)
+
+	_, err = gda.ParseGating("eventually")
+	require.Error(t, err)
+}
+
+func TestQueue_optimistic(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pub := gda.NewMemPublisher(50 * time.Millisecond)
+	q := gda.NewQueue(ctx, gtest.NewLogger(t), gda.QueueConfig{
+		Publisher: pub,
+		Gating:    gda.GatingOptimistic,
+	})
+	defer q.Wait()
+	defer cancel()
+
+	for h := uint64(1); h <= 3; h++ {
+		require.True(t, q.Submit(ctx, gda.Block{Height: h}))
+	}
+
+	// Submit returned before the slow publisher could have included anything.
+	require.Empty(t, pub.Blocks())
+
+	require.Eventually(t, func() bool {
+		return len(pub.Blocks()) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	for i, b := range pub.Blocks() {
+		require.Equal(t, uint64(i+1), b.Height)
+	}
+}
+
+func TestQueue_waitForInclusion(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pub := &flakyPublisher{inner: gda.NewMemPublisher(0)}
+	pub.failures.Store(2)
+
+	q := gda.NewQueue(ctx, gtest.NewLogger(t), gda.QueueConfig{
+		Publisher:  pub,
+		Gating:     gda.GatingWaitForInclusion,
+		RetryDelay: time.Millisecond,
+	})
+	defer q.Wait()
+	defer cancel()
+
+	// Submit does not return until the block is published,
+	// despite the earlier failures.
+	require.True(t, q.Submit(ctx, gda.Block{Height: 1, Data: []byte("txs")}))
+
+	blocks := pub.inner.Blocks()
+	require.Len(t, blocks, 1)
+	require.Equal(t, []byte("txs"), blocks[0].Data)
+	require.Zero(t, pub.failures.Load())
+}
+
+type flakyPublisher struct {
+	inner    *gda.MemPublisher
+	failures atomic.Int32
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, b gda.Block) (gda.Receipt, error) {
+	if p.failures.Add(-1) >= 0 {
+		return gda.Receipt{}, errors.New("DA layer unavailable")
+	}
+	p.failures.Store(0)
+	return p.inner.Publish(ctx, b)
+}
@@ -0,0 +1,80 @@
+// Package gevidence verifies and exchanges evidence of conflicting commits:
+// two different blocks at the same height,
+// each with a commit proof from a majority of the validator set.
+//
+// Such evidence means the chain has forked,
+// which requires more than a third of the voting power to sign conflicting precommits.
+// An [Exchange] shares verified evidence with every connected peer over libp2p,
+// so that each operator on the network is alerted to the fork,
+// not only the operators whose nodes observed both blocks.
+package gevidence
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Evidence is a pair of conflicting committed headers.
+type Evidence struct {
+	A, B tmconsensus.CommittedHeader
+}
+
+// Height returns the height of the conflicting headers.
+func (ev Evidence) Height() uint64 {
+	return ev.A.Header.Height
+}
+
+// key returns a value identifying ev regardless of the order of its headers.
+func (ev Evidence) key() string {
+	a, b := ev.A.Header.Hash, ev.B.Header.Hash
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d/%x/%x", ev.Height(), a, b)
+}
+
+var (
+	// ErrHeightMismatch indicates evidence whose headers are at different heights.
+	ErrHeightMismatch = errors.New("evidence headers have different heights")
+
+	// ErrSameBlock indicates evidence whose headers are the same block,
+	// which is not a conflict.
+	ErrSameBlock = errors.New("evidence headers have the same hash")
+
+	// ErrTooOld indicates evidence older than the maximum evidence age.
+	ErrTooOld = errors.New("evidence is too old")
+)
+
+// Verify reports an error unless ev is valid evidence of a fork:
+// the headers are at the same height, have different hashes,
+// each hash matches its header under hs,
+// and each commit proof is valid according to v.
+func Verify(ctx context.Context, v *gcverify.Verifier, hs tmconsensus.HashScheme, ev Evidence) error {
+	if ev.A.Header.Height != ev.B.Header.Height {
+		return ErrHeightMismatch
+	}
+	if bytes.Equal(ev.A.Header.Hash, ev.B.Header.Hash) {
+		return ErrSameBlock
+	}
+
+	for _, ch := range []tmconsensus.CommittedHeader{ev.A, ev.B} {
+		want, err := hs.Block(ch.Header)
+		if err != nil {
+			return fmt.Errorf("failed to calculate block hash: %w", err)
+		}
+		if !bytes.Equal(want, ch.Header.Hash) {
+			return fmt.Errorf("header hash %x does not match calculated hash %x", ch.Header.Hash, want)
+		}
+
+		if err := v.VerifyCommit(ctx, ch.Header.Height, ch.Header.Hash, ch.Proof); err != nil {
+			return fmt.Errorf("invalid commit proof for block %x: %w", ch.Header.Hash, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package gevidence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+	libp2pprotocol "github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// ProtocolID is the libp2p protocol for sending evidence to a peer.
+//
+// The sender writes a JSON-encoded [wireEvidence]
+// and the receiver responds with a JSON-encoded [wireResult]
+// indicating whether it accepted the evidence.
+const ProtocolID = libp2pprotocol.ID("/gcosmos/fork_evidence/v1")
+
+// Arbitrary limit on the size of received evidence,
+// which is two full headers.
+const maxEvidenceSize = 1 << 20
+
+// How long to spend handling or sending a single piece of evidence.
+const streamTimeout = 5 * time.Second
+
+type wireEvidence struct {
+	A, B json.RawMessage
+}
+
+type wireResult struct {
+	Err string `json:",omitempty"`
+}
+
+// ExchangeConfig is the configuration for [NewExchange].
+type ExchangeConfig struct {
+	Host libp2phost.Host
+
+	// Codec for the committed headers in evidence sent to or received from peers.
+	Codec tmcodec.MarshalCodec
+
+	// Verifier and hash scheme for the checks in [Verify].
+	Verifier   *gcverify.Verifier
+	HashScheme tmconsensus.HashScheme
+
+	// Optional callback for every newly accepted piece of evidence.
+	// It is called synchronously, so it must not block.
+	OnEvidence func(Evidence)
+
+	// Optional check of whether evidence at height is too old to accept,
+	// for instance under the app-governed maximum evidence age.
+	// Such evidence is rejected with [ErrTooOld].
+	TooOld func(ctx context.Context, height uint64) bool
+}
+
+// Exchange accepts fork evidence from the local operator and from peers,
+// alerts the operator to every newly verified piece of evidence,
+// and forwards it to all connected peers.
+type Exchange struct {
+	// As with the gp2papi.DataHost, libp2p streams have no associated context,
+	// so stream handlers derive their contexts from the exchange's life context.
+	ctx context.Context
+
+	log *slog.Logger
+
+	host  libp2phost.Host
+	codec tmcodec.MarshalCodec
+
+	v  *gcverify.Verifier
+	hs tmconsensus.HashScheme
+
+	onEvidence func(Evidence)
+	tooOld     func(context.Context, uint64) bool
+
+	mu       sync.Mutex
+	seen     map[string]struct{}
+	evidence []Evidence
+
+	sends sync.WaitGroup
+
+	done chan struct{}
+}
+
+// NewExchange returns a new Exchange based on cfg,
+// which handles evidence streams on cfg.Host until ctx is canceled.
+func NewExchange(ctx context.Context, log *slog.Logger, cfg ExchangeConfig) *Exchange {
+	e := &Exchange{
+		ctx: ctx,
+		log: log,
+
+		host:  cfg.Host,
+		codec: cfg.Codec,
+
+		v:  cfg.Verifier,
+		hs: cfg.HashScheme,
+
+		onEvidence: cfg.OnEvidence,
+		tooOld:     cfg.TooOld,
+
+		seen: make(map[string]struct{}),
+
+		done: make(chan struct{}),
+	}
+
+	e.host.SetStreamHandler(ProtocolID, e.handleStream)
+
+	go e.waitForCancellation()
+
+	return e
+}
+
+// Wait blocks until e has stopped handling streams
+// and has finished sending evidence to peers.
+func (e *Exchange) Wait() {
+	<-e.done
+}
+
+func (e *Exchange) waitForCancellation() {
+	<-e.ctx.Done()
+	e.host.RemoveStreamHandler(ProtocolID)
+
+	// Once the context is canceled, no new sends begin;
+	// acquiring the lock ensures that any send that began concurrently
+	// has been added to the wait group.
+	e.mu.Lock()
+	e.mu.Unlock()
+	e.sends.Wait()
+
+	close(e.done)
+}
+
+// Evidence returns every piece of evidence accepted so far,
+// in the order it was accepted.
+func (e *Exchange) Evidence() []Evidence {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.evidence)
+}
+
+// Submit verifies ev and, if it is valid and not already known,
+// alerts the operator and sends it to all connected peers.
+// Submitting known evidence again is not an error.
+func (e *Exchange) Submit(ctx context.Context, ev Evidence) error {
+	return e.submit(ctx, ev, "")
+}
+
+// submit is the shared implementation of Submit and handleStream.
+// The evidence is not sent back to the peer it came from, if any.
+func (e *Exchange) submit(ctx context.Context, ev Evidence, from libp2ppeer.ID) error {
+	key := ev.key()
+
+	e.mu.Lock()
+	_, seen := e.seen[key]
+	e.mu.Unlock()
+	if seen {
+		return nil
+	}
+
+	if e.tooOld != nil && e.tooOld(ctx, ev.Height()) {
+		return ErrTooOld
+	}
+
+	if err := Verify(ctx, e.v, e.hs, ev); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if _, seen := e.seen[key]; seen {
+		// Accepted concurrently.
+		e.mu.Unlock()
+		return nil
+	}
+	e.seen[key] = struct{}{}
+	e.evidence = append(e.evidence, ev)
+	e.mu.Unlock()
+
+	e.log.Error(
+		"CHAIN FORK: verified conflicting commits at the same height",
+		"event", "fork_evidence",
+		"height", ev.Height(),
+		"hash_a", fmt.Sprintf("%x", ev.A.Header.Hash),
+		"hash_b", fmt.Sprintf("%x", ev.B.Header.Hash),
+		"from_peer", from,
+	)
+
+	if e.onEvidence != nil {
+		e.onEvidence(ev)
+	}
+
+	e.broadcast(ev, from)
+	return nil
+}
+
+// broadcast sends ev to every connected peer except skip,
+// in the background.
+func (e *Exchange) broadcast(ev Evidence, skip libp2ppeer.ID) {
+	a, err := e.codec.MarshalCommittedHeader(ev.A)
+	if err != nil {
+		e.log.Warn("Failed to marshal evidence header", "err", err)
+		return
+	}
+	b, err := e.codec.MarshalCommittedHeader(ev.B)
+	if err != nil {
+		e.log.Warn("Failed to marshal evidence header", "err", err)
+		return
+	}
+	msg, err := json.Marshal(wireEvidence{A: a, B: b})
+	if err != nil {
+		e.log.Warn("Failed to marshal evidence", "err", err)
+		return
+	}
+
+	for _, p := range e.host.Network().Peers() {
+		if p == skip {
+			continue
+		}
+
+		e.mu.Lock()
+		if e.ctx.Err() != nil {
+			e.mu.Unlock()
+			return
+		}
+		e.sends.Add(1)
+		e.mu.Unlock()
+
+		go e.send(p, msg)
+	}
+}
+
+func (e *Exchange) send(p libp2ppeer.ID, msg []byte) {
+	defer e.sends.Done()
+
+	ctx, cancel := context.WithTimeout(e.ctx, streamTimeout)
+	defer cancel()
+
+	s, err := e.host.NewStream(ctx, p, ProtocolID)
+	if err != nil {
+		e.log.Info("Failed to open evidence stream to peer", "peer_id", p, "err", err)
+		return
+	}
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(streamTimeout))
+
+	if _, err := s.Write(msg); err != nil {
+		e.log.Info("Failed to send evidence to peer", "peer_id", p, "err", err)
+		return
+	}
+	_ = s.CloseWrite()
+
+	var res wireResult
+	if err := json.NewDecoder(io.LimitReader(s, 16*1024)).Decode(&res); err != nil {
+		e.log.Info("Failed to read evidence response from peer", "peer_id", p, "err", err)
+		return
+	}
+	if res.Err != "" {
+		e.log.Info("Peer rejected evidence", "peer_id", p, "err", res.Err)
+	}
+}
+
+func (e *Exchange) handleStream(s libp2pnetwork.Stream) {
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(streamTimeout))
+
+	ctx, cancel := context.WithTimeout(e.ctx, streamTimeout)
+	defer cancel()
+
+	from := s.Conn().RemotePeer()
+
+	var we wireEvidence
+	if err := json.NewDecoder(io.LimitReader(s, maxEvidenceSize)).Decode(&we); err != nil {
+		_ = json.NewEncoder(s).Encode(wireResult{Err: "invalid evidence encoding"})
+		return
+	}
+	_ = s.CloseRead()
+
+	var ev Evidence
+	if err := e.codec.UnmarshalCommittedHeader(we.A, &ev.A); err != nil {
+		_ = json.NewEncoder(s).Encode(wireResult{Err: "invalid committed header"})
+		return
+	}
+	if err := e.codec.UnmarshalCommittedHeader(we.B, &ev.B); err != nil {
+		_ = json.NewEncoder(s).Encode(wireResult{Err: "invalid committed header"})
+		return
+	}
+
+	if err := e.submit(ctx, ev, from); err != nil {
+		e.log.Info(
+			"Rejected evidence from peer",
+			"peer_id", from,
+			"height", ev.Height(),
+			"err", err,
+		)
+		_ = json.NewEncoder(s).Encode(wireResult{Err: err.Error()})
+		return
+	}
+
+	_ = json.NewEncoder(s).Encode(wireResult{})
+}
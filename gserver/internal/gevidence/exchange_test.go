@@ -0,0 +1,180 @@
+package gevidence_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gevidence"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p/tmlibp2ptest"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+	"github.com/stretchr/testify/require"
+)
+
+// conflictingEvidence returns evidence of two blocks committed at height 1,
+// with the validators at index 1 and 2 signing both.
+func conflictingEvidence(
+	t *testing.T, ctx context.Context, fx *tmconsensustest.StandardFixture,
+) gevidence.Evidence {
+	t.Helper()
+
+	phA := fx.NextProposedHeader([]byte("a"), 0)
+	phB := fx.NextProposedHeader([]byte("b"), 0)
+	require.NotEqual(t, phA.Header.Hash, phB.Header.Hash)
+
+	proofs := fx.PrecommitProofMap(ctx, 1, 0, map[string][]int{
+		string(phA.Header.Hash): {0, 1, 2},
+		string(phB.Header.Hash): {1, 2, 3},
+	})
+
+	commit := func(h tmconsensus.Header) tmconsensus.CommittedHeader {
+		sp := proofs[string(h.Hash)].AsSparse()
+		return tmconsensus.CommittedHeader{
+			Header: h,
+			Proof: tmconsensus.CommitProof{
+				Round:      0,
+				PubKeyHash: sp.PubKeyHash,
+				Proofs: map[string][]gcrypto.SparseSignature{
+					string(h.Hash): sp.Signatures,
+				},
+			},
+		}
+	}
+
+	return gevidence.Evidence{
+		A: commit(phA.Header),
+		B: commit(phB.Header),
+	}
+}
+
+func newVerifier(
+	t *testing.T, ctx context.Context, fx *tmconsensustest.StandardFixture, stored tmconsensus.CommittedHeader,
+) *gcverify.Verifier {
+	t.Helper()
+
+	s := tmmemstore.NewCommittedHeaderStore()
+	require.NoError(t, s.SaveCommittedHeader(ctx, stored))
+
+	return gcverify.NewVerifier(gcverify.VerifierConfig{
+		Store:                             s,
+		SignatureScheme:                   fx.SignatureScheme,
+		CommonMessageSignatureProofScheme: fx.CommonMessageSignatureProofScheme,
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	ev := conflictingEvidence(t, ctx, fx)
+	v := newVerifier(t, ctx, fx, ev.A)
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, gevidence.Verify(ctx, v, fx.HashScheme, ev))
+	})
+
+	t.Run("same block", func(t *testing.T) {
+		err := gevidence.Verify(ctx, v, fx.HashScheme, gevidence.Evidence{A: ev.A, B: ev.A})
+		require.ErrorIs(t, err, gevidence.ErrSameBlock)
+	})
+
+	t.Run("different heights", func(t *testing.T) {
+		b := ev.B
+		b.Header.Height = 2
+		err := gevidence.Verify(ctx, v, fx.HashScheme, gevidence.Evidence{A: ev.A, B: b})
+		require.ErrorIs(t, err, gevidence.ErrHeightMismatch)
+	})
+
+	t.Run("wrong hash", func(t *testing.T) {
+		b := ev.B
+		b.Header.DataID = []byte("other")
+		err := gevidence.Verify(ctx, v, fx.HashScheme, gevidence.Evidence{A: ev.A, B: b})
+		require.ErrorContains(t, err, "does not match calculated hash")
+	})
+
+	t.Run("proof for other block", func(t *testing.T) {
+		b := ev.B
+		b.Proof = ev.A.Proof
+		err := gevidence.Verify(ctx, v, fx.HashScheme, gevidence.Evidence{A: ev.A, B: b})
+		var ipe gcverify.InsufficientPowerError
+		require.ErrorAs(t, err, &ipe)
+	})
+}
+
+func TestExchange(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	ev := conflictingEvidence(t, ctx, fx)
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+	codec := tmjson.MarshalCodec{CryptoRegistry: reg}
+
+	log := gtest.NewLogger(t)
+	net, err := tmlibp2ptest.NewNetwork(ctx, log.With("sys", "net"), codec)
+	require.NoError(t, err)
+	defer net.Wait()
+	defer cancel()
+
+	c1, err := net.Connect(ctx)
+	require.NoError(t, err)
+	c2, err := net.Connect(ctx)
+	require.NoError(t, err)
+	require.NoError(t, net.Stabilize(ctx))
+
+	var tooOld atomic.Bool
+	e1 := gevidence.NewExchange(ctx, log.With("node", 1), gevidence.ExchangeConfig{
+		Host:       c1.Host().Libp2pHost(),
+		Codec:      codec,
+		Verifier:   newVerifier(t, ctx, fx, ev.A),
+		HashScheme: fx.HashScheme,
+		TooOld:     func(context.Context, uint64) bool { return tooOld.Load() },
+	})
+	defer e1.Wait()
+	defer cancel()
+
+	received := make(chan gevidence.Evidence, 1)
+	e2 := gevidence.NewExchange(ctx, log.With("node", 2), gevidence.ExchangeConfig{
+		Host:       c2.Host().Libp2pHost(),
+		Codec:      codec,
+		Verifier:   newVerifier(t, ctx, fx, ev.B),
+		HashScheme: fx.HashScheme,
+		OnEvidence: func(ev gevidence.Evidence) { received <- ev },
+	})
+	defer e2.Wait()
+	defer cancel()
+
+	// Invalid evidence is neither accepted nor forwarded.
+	require.ErrorIs(t, e1.Submit(ctx, gevidence.Evidence{A: ev.A, B: ev.A}), gevidence.ErrSameBlock)
+	require.Empty(t, e1.Evidence())
+
+	// Nor is evidence beyond the maximum age.
+	tooOld.Store(true)
+	require.ErrorIs(t, e1.Submit(ctx, ev), gevidence.ErrTooOld)
+	require.Empty(t, e1.Evidence())
+	tooOld.Store(false)
+
+	require.NoError(t, e1.Submit(ctx, ev))
+	require.Len(t, e1.Evidence(), 1)
+
+	got := gtest.ReceiveSoon(t, received)
+	require.Equal(t, ev.A.Header.Hash, got.A.Header.Hash)
+	require.Equal(t, ev.B.Header.Hash, got.B.Header.Hash)
+	require.Len(t, e2.Evidence(), 1)
+
+	// Resubmitting the same evidence, in either order, is a no-op.
+	require.NoError(t, e1.Submit(ctx, gevidence.Evidence{A: ev.B, B: ev.A}))
+	require.Len(t, e1.Evidence(), 1)
+}
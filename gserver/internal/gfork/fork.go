@@ -0,0 +1,170 @@
+// Package gfork detects when the node's application state
+// diverges from the state the network committed.
+//
+// Every header records the app state hash that its proposer computed
+// after finalizing the previous height.
+// When the driver finalizes a header, it compares that hash
+// with the hash of its own last commit.
+// A mismatch means this node is on a fork,
+// or the application is not deterministic;
+// either way, the node must stop participating in consensus.
+package gfork
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Report describes a detected app hash mismatch.
+type Report struct {
+	ChainID string
+
+	// The height whose app state hash disagrees.
+	Height uint64
+
+	// Hex-encoded hashes of the app state after Height:
+	// the one recorded in the committed header at Height+1,
+	// and the one this node computed.
+	NetworkAppHash string
+	LocalAppHash   string
+
+	// Hex-encoded hash of the header at Height+1,
+	// and the round in which it was committed.
+	NextBlockHash string
+	NextRound     uint32
+
+	DetectedAt time.Time
+}
+
+// Error is the error for a [Report], for callers that must fail with it.
+type Error struct {
+	Report Report
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf(
+		"app hash mismatch after height %d: network has %s, local state has %s",
+		e.Report.Height, e.Report.NetworkAppHash, e.Report.LocalAppHash,
+	)
+}
+
+// Recorder persists the first fork report so that it survives a restart.
+// It is safe for concurrent use.
+type Recorder struct {
+	log  *slog.Logger
+	path string
+
+	mu     sync.Mutex
+	report *Report
+}
+
+// NewRecorder returns a Recorder persisting its report to path,
+// loading a report already present there.
+func NewRecorder(log *slog.Logger, path string) (*Recorder, error) {
+	r := &Recorder{log: log, path: path}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("failed to read fork report: %w", err)
+	}
+
+	var rep Report
+	if err := json.Unmarshal(b, &rep); err != nil {
+		return nil, fmt.Errorf("failed to parse fork report at %s: %w", path, err)
+	}
+	r.report = &rep
+	return r, nil
+}
+
+// Path returns the path of the persisted report.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Report returns the recorded report, if any.
+func (r *Recorder) Report() (Report, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.report == nil {
+		return Report{}, false
+	}
+	return *r.report, true
+}
+
+// Check compares the app state hash recorded by the network after height
+// with the local one, recording and returning an [*Error] if they differ.
+// Only the first mismatch is recorded.
+func (r *Recorder) Check(
+	chainID string,
+	height uint64,
+	networkAppHash, localAppHash []byte,
+	nextBlockHash []byte, nextRound uint32,
+) error {
+	if string(networkAppHash) == string(localAppHash) {
+		return nil
+	}
+
+	rep := Report{
+		ChainID: chainID,
+		Height:  height,
+
+		NetworkAppHash: hex.EncodeToString(networkAppHash),
+		LocalAppHash:   hex.EncodeToString(localAppHash),
+
+		NextBlockHash: hex.EncodeToString(nextBlockHash),
+		NextRound:     nextRound,
+
+		DetectedAt: time.Now().UTC(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.report != nil {
+		return &Error{Report: *r.report}
+	}
+	r.report = &rep
+
+	r.log.Error(
+		"App hash mismatch; this node is on a fork or the app is nondeterministic",
+		"height", rep.Height,
+		"network_app_hash", rep.NetworkAppHash,
+		"local_app_hash", rep.LocalAppHash,
+		"next_block_hash", rep.NextBlockHash,
+		"report_path", r.path,
+	)
+
+	if err := r.write(rep); err != nil {
+		r.log.Error("Failed to persist fork report", "err", err)
+	}
+
+	return &Error{Report: rep}
+}
+
+func (r *Recorder) write(rep Report) error {
+	b, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
@@ -0,0 +1,44 @@
+package gfork_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gfork"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Check(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "data", "fork_report.json")
+	r, err := gfork.NewRecorder(gtest.NewLogger(t), path)
+	require.NoError(t, err)
+
+	_, ok := r.Report()
+	require.False(t, ok)
+
+	require.NoError(t, r.Check("c", 4, []byte("same"), []byte("same"), []byte("h5"), 0))
+
+	err = r.Check("c", 5, []byte{0xaa}, []byte{0xbb}, []byte{0x06}, 2)
+	var forkErr *gfork.Error
+	require.ErrorAs(t, err, &forkErr)
+	require.Equal(t, uint64(5), forkErr.Report.Height)
+	require.Equal(t, "aa", forkErr.Report.NetworkAppHash)
+	require.Equal(t, "bb", forkErr.Report.LocalAppHash)
+	require.Equal(t, uint32(2), forkErr.Report.NextRound)
+
+	// A later mismatch still returns the first report.
+	err = r.Check("c", 6, []byte{0x01}, []byte{0x02}, nil, 0)
+	require.ErrorAs(t, err, &forkErr)
+	require.Equal(t, uint64(5), forkErr.Report.Height)
+
+	// The report survives a restart.
+	r2, err := gfork.NewRecorder(gtest.NewLogger(t), path)
+	require.NoError(t, err)
+	rep, ok := r2.Report()
+	require.True(t, ok)
+	require.Equal(t, uint64(5), rep.Height)
+	require.Equal(t, "aa", rep.NetworkAppHash)
+}
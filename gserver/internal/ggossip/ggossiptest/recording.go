@@ -0,0 +1,206 @@
+// Package ggossiptest contains test doubles for gossip strategies.
+package ggossiptest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/gordian-engine/gordian/tm/tmgossip"
+)
+
+// Record is a network view update received by a [RecordingStrategy].
+type Record struct {
+	Update   tmelink.NetworkViewUpdate
+	Received time.Time
+}
+
+// RecordingStrategy is a [tmgossip.Strategy] that records every update it receives,
+// until its context is canceled.
+//
+// Its Require methods wait briefly for a matching update,
+// so tests need not receive from channels themselves.
+type RecordingStrategy struct {
+	ctx context.Context
+
+	mu      sync.Mutex
+	records []Record
+	changed chan struct{} // Closed and replaced on every new record.
+	sent    int           // Number of records marked sent.
+
+	start chan (<-chan tmelink.NetworkViewUpdate)
+	done  chan struct{}
+}
+
+var _ tmgossip.Strategy = (*RecordingStrategy)(nil)
+
+// NewRecordingStrategy returns a new RecordingStrategy
+// that runs until ctx is canceled.
+func NewRecordingStrategy(ctx context.Context) *RecordingStrategy {
+	s := &RecordingStrategy{
+		ctx: ctx,
+
+		changed: make(chan struct{}),
+
+		start: make(chan (<-chan tmelink.NetworkViewUpdate), 1),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *RecordingStrategy) Start(updates <-chan tmelink.NetworkViewUpdate) {
+	s.start <- updates
+}
+
+func (s *RecordingStrategy) Wait() {
+	<-s.done
+}
+
+// Done returns a channel that is closed once s has stopped.
+func (s *RecordingStrategy) Done() <-chan struct{} {
+	return s.done
+}
+
+// Records returns a copy of every update received so far, in order.
+func (s *RecordingStrategy) Records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Record, len(s.records))
+	copy(out, s.records)
+	return out
+}
+
+// MarkSent marks every view received so far as sent,
+// for a later call to [*RecordingStrategy.RequireNoDuplicates].
+func (s *RecordingStrategy) MarkSent() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = len(s.records)
+}
+
+// RequireUpdate waits briefly for an update for which match returns true,
+// among every update received so far and any received during the wait,
+// and returns the first such update.
+// If there is none, tb.Fatalf is called.
+func (s *RecordingStrategy) RequireUpdate(
+	tb gtest.TestingFatalHelper, match func(tmelink.NetworkViewUpdate) bool,
+) tmelink.NetworkViewUpdate {
+	tb.Helper()
+
+	timer := time.NewTimer(time.Duration(gtest.ScaleMs(100)))
+	defer timer.Stop()
+
+	checked := 0
+	for {
+		s.mu.Lock()
+		records := s.records[checked:]
+		changed := s.changed
+		checked = len(s.records)
+		s.mu.Unlock()
+
+		for _, r := range records {
+			if match(r.Update) {
+				return r.Update
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			tb.Fatalf("no matching update among %d received", checked)
+			panic("unreachable")
+		}
+	}
+}
+
+// RequireVotingView waits briefly for an update with a voting view
+// at height h and round r, and returns that view.
+// If there is none, tb.Fatalf is called.
+func (s *RecordingStrategy) RequireVotingView(
+	tb gtest.TestingFatalHelper, h uint64, r uint32,
+) *tmconsensus.VersionedRoundView {
+	tb.Helper()
+
+	u := s.RequireUpdate(tb, func(u tmelink.NetworkViewUpdate) bool {
+		return u.Voting != nil && u.Voting.Height == h && u.Voting.Round == r
+	})
+	return u.Voting
+}
+
+// RequireNoDuplicates calls tb.Fatalf if any view received
+// since the latest call to [*RecordingStrategy.MarkSent]
+// has the same height, round, and version as a view marked sent.
+func (s *RecordingStrategy) RequireNoDuplicates(tb gtest.TestingFatalHelper) {
+	tb.Helper()
+
+	type viewKey struct {
+		h       uint64
+		r       uint32
+		version uint32
+	}
+
+	s.mu.Lock()
+	sent := s.records[:s.sent]
+	later := s.records[s.sent:]
+	s.mu.Unlock()
+
+	seen := make(map[viewKey]bool)
+	for _, rec := range sent {
+		for _, v := range views(rec.Update) {
+			seen[viewKey{v.Height, v.Round, v.Version}] = true
+		}
+	}
+
+	for _, rec := range later {
+		for _, v := range views(rec.Update) {
+			if seen[viewKey{v.Height, v.Round, v.Version}] {
+				tb.Fatalf(
+					"view at height %d, round %d, version %d was received again after being marked sent",
+					v.Height, v.Round, v.Version,
+				)
+				return
+			}
+		}
+	}
+}
+
+// views returns the non-nil views in u.
+func views(u tmelink.NetworkViewUpdate) []*tmconsensus.VersionedRoundView {
+	var out []*tmconsensus.VersionedRoundView
+	for _, v := range []*tmconsensus.VersionedRoundView{u.Committing, u.Voting, u.NextRound} {
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (s *RecordingStrategy) run() {
+	defer close(s.done)
+
+	var updates <-chan tmelink.NetworkViewUpdate
+	select {
+	case <-s.ctx.Done():
+		return
+	case updates = <-s.start:
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case u := <-updates:
+			s.mu.Lock()
+			s.records = append(s.records, Record{Update: u, Received: time.Now()})
+			close(s.changed)
+			s.changed = make(chan struct{})
+			s.mu.Unlock()
+		}
+	}
+}
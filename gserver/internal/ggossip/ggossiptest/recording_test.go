@@ -0,0 +1,79 @@
+package ggossiptest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/ggossip/ggossiptest"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/stretchr/testify/require"
+)
+
+// fatalRecorder is a [gtest.TestingFatalHelper] that records failures instead of stopping the test.
+type fatalRecorder struct {
+	failures []string
+}
+
+func (r *fatalRecorder) Helper() {}
+
+func (r *fatalRecorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func voting(h uint64, r, version uint32) tmelink.NetworkViewUpdate {
+	return tmelink.NetworkViewUpdate{
+		Voting: &tmconsensus.VersionedRoundView{
+			RoundView: tmconsensus.RoundView{Height: h, Round: r},
+			Version:   version,
+		},
+	}
+}
+
+func TestRecordingStrategy(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := ggossiptest.NewRecordingStrategy(ctx)
+	defer s.Wait()
+	defer cancel()
+
+	updates := make(chan tmelink.NetworkViewUpdate)
+	s.Start(updates)
+
+	gtest.SendSoon(t, updates, voting(1, 0, 1))
+	gtest.SendSoon(t, updates, voting(1, 1, 1))
+
+	v := s.RequireVotingView(t, 1, 1)
+	require.Equal(t, uint32(1), v.Round)
+	require.Len(t, s.Records(), 2)
+
+	s.MarkSent()
+
+	// A new version of a sent view is not a duplicate.
+	gtest.SendSoon(t, updates, voting(1, 1, 2))
+	_ = s.RequireUpdate(t, func(u tmelink.NetworkViewUpdate) bool {
+		return u.Voting != nil && u.Voting.Version == 2
+	})
+	s.RequireNoDuplicates(t)
+
+	// But the same version is.
+	gtest.SendSoon(t, updates, voting(1, 0, 1))
+	require.Eventually(t, func() bool { return len(s.Records()) == 4 }, time.Second, time.Millisecond)
+
+	fr := new(fatalRecorder)
+	s.RequireNoDuplicates(fr)
+	require.Len(t, fr.failures, 1)
+
+	// A view that never arrives fails after a short wait.
+	fr = new(fatalRecorder)
+	require.Panics(t, func() {
+		s.RequireVotingView(fr, 2, 0)
+	})
+	require.Len(t, fr.failures, 1)
+}
@@ -0,0 +1,210 @@
+// Package ggossip contains gossip strategy helpers for the gcosmos server.
+package ggossip
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/trace"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure"
+	"github.com/gordian-engine/gcosmos/internal/copy/gchan"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/gordian-engine/gordian/tm/tmgossip"
+)
+
+// StrategyFactory returns a new, not yet started gossip strategy.
+// Canceling ctx must stop the strategy, so that its Wait method returns.
+type StrategyFactory func(ctx context.Context) tmgossip.Strategy
+
+// errSwapped is the cancellation cause for a strategy that was replaced.
+var errSwapped = errors.New("gossip strategy swapped")
+
+// SwappableStrategy is a [tmgossip.Strategy] that delegates to an inner strategy
+// which can be replaced while the engine is running.
+//
+// On a swap, the old strategy's context is canceled and its Wait method awaited,
+// and then the new strategy is started.
+// The new strategy's first update contains the latest committing, voting, and next round views
+// seen so far, so it begins with the same view of the network as the old strategy had.
+type SwappableStrategy struct {
+	log *slog.Logger
+
+	startCh chan (<-chan tmelink.NetworkViewUpdate)
+	swapCh  chan swapRequest
+
+	// Optional path measuring forwarding to the inner strategy,
+	// and optional function observing every update,
+	// only read by the kernel after Start.
+	fwdPath  *gbackpressure.Path
+	observer func(tmelink.NetworkViewUpdate)
+
+	done chan struct{}
+}
+
+type swapRequest struct {
+	f    StrategyFactory
+	resp chan struct{}
+}
+
+// NewSwappableStrategy returns a new SwappableStrategy
+// whose initial inner strategy is created with initial.
+func NewSwappableStrategy(ctx context.Context, log *slog.Logger, initial StrategyFactory) *SwappableStrategy {
+	s := &SwappableStrategy{
+		log: log,
+
+		startCh: make(chan (<-chan tmelink.NetworkViewUpdate), 1),
+		swapCh:  make(chan swapRequest),
+
+		done: make(chan struct{}),
+	}
+
+	go s.kernel(ctx, initial)
+
+	return s
+}
+
+// SetForwardPath sets the path measuring how long each update
+// is blocked on the inner strategy.
+// It must be called before Start.
+func (s *SwappableStrategy) SetForwardPath(p *gbackpressure.Path) {
+	s.fwdPath = p
+}
+
+// SetViewObserver sets a function called with every update from the engine,
+// on the strategy's goroutine, before it is forwarded to the inner strategy.
+// It must be called before Start.
+func (s *SwappableStrategy) SetViewObserver(f func(tmelink.NetworkViewUpdate)) {
+	s.observer = f
+}
+
+func (s *SwappableStrategy) Start(updates <-chan tmelink.NetworkViewUpdate) {
+	s.startCh <- updates
+	close(s.startCh)
+}
+
+// Wait blocks until s and its current inner strategy have stopped.
+func (s *SwappableStrategy) Wait() {
+	<-s.done
+}
+
+// Swap replaces the inner strategy with one created by f,
+// blocking until the old strategy has stopped and the new one has started.
+// If s has not yet been started, the new strategy replaces the initial one.
+// Swap returns the cause if ctx or s's context is canceled first.
+func (s *SwappableStrategy) Swap(ctx context.Context, f StrategyFactory) error {
+	req := swapRequest{f: f, resp: make(chan struct{})}
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-s.done:
+		return errors.New("gossip strategy already stopped")
+	case s.swapCh <- req:
+	}
+
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-req.resp:
+		return nil
+	}
+}
+
+// inner is the running inner strategy.
+type inner struct {
+	gs      tmgossip.Strategy
+	cancel  context.CancelCauseFunc
+	updates chan tmelink.NetworkViewUpdate
+}
+
+func (s *SwappableStrategy) newInner(ctx context.Context, f StrategyFactory) inner {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return inner{
+		gs:      f(ctx),
+		cancel:  cancel,
+		updates: make(chan tmelink.NetworkViewUpdate),
+	}
+}
+
+func (in inner) stop(cause error) {
+	in.cancel(cause)
+	in.gs.Wait()
+}
+
+func (s *SwappableStrategy) kernel(ctx context.Context, initial StrategyFactory) {
+	defer close(s.done)
+
+	ctx, task := trace.NewTask(ctx, "SwappableStrategy.kernel")
+	defer task.End()
+
+	cur := s.newInner(ctx, initial)
+	defer func() { cur.stop(context.Cause(ctx)) }()
+
+	// Block for the start signal, accepting swaps in the meantime.
+	var updates <-chan tmelink.NetworkViewUpdate
+	for updates == nil {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Stopping before start due to context cancellation", "cause", context.Cause(ctx))
+			return
+		case u, ok := <-s.startCh:
+			if !ok {
+				// Only possible if Start was called twice.
+				panic(errors.New("BUG: SwappableStrategy started more than once"))
+			}
+			updates = u
+		case req := <-s.swapCh:
+			cur.stop(errSwapped)
+			cur = s.newInner(ctx, req.f)
+			close(req.resp)
+		}
+	}
+	cur.gs.Start(cur.updates)
+
+	// The latest non-nil views, replayed to a new strategy on swap.
+	var latest tmelink.NetworkViewUpdate
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case u := <-updates:
+			if u.Committing != nil {
+				latest.Committing = u.Committing
+			}
+			if u.Voting != nil {
+				latest.Voting = u.Voting
+			}
+			if u.NextRound != nil {
+				latest.NextRound = u.NextRound
+			}
+			if s.observer != nil {
+				s.observer(u)
+			}
+
+			end := s.fwdPath.Begin()
+			ok := gchan.SendC(ctx, s.log, cur.updates, u, "forwarding network view update")
+			end()
+			if !ok {
+				return
+			}
+
+		case req := <-s.swapCh:
+			s.log.Info("Swapping gossip strategy")
+			cur.stop(errSwapped)
+
+			cur = s.newInner(ctx, req.f)
+			cur.gs.Start(cur.updates)
+
+			if latest.Voting != nil {
+				if !gchan.SendC(ctx, s.log, cur.updates, latest, "replaying latest network view") {
+					close(req.resp)
+					return
+				}
+			}
+			close(req.resp)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package ggossip_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/ggossip"
+	"github.com/gordian-engine/gcosmos/gserver/internal/ggossip/ggossiptest"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/gordian-engine/gordian/tm/tmgossip"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwappableStrategy_Swap(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	strategies := make(chan *ggossiptest.RecordingStrategy, 2)
+	factory := func(ctx context.Context) tmgossip.Strategy {
+		s := ggossiptest.NewRecordingStrategy(ctx)
+		strategies <- s
+		return s
+	}
+
+	s := ggossip.NewSwappableStrategy(ctx, gtest.NewLogger(t), factory)
+	defer s.Wait()
+	defer cancel()
+
+	first := gtest.ReceiveSoon(t, strategies)
+
+	updates := make(chan tmelink.NetworkViewUpdate)
+	s.Start(updates)
+
+	voting := &tmconsensus.VersionedRoundView{RoundView: tmconsensus.RoundView{Height: 1}}
+	committing := &tmconsensus.VersionedRoundView{RoundView: tmconsensus.RoundView{Height: 0}}
+	gtest.SendSoon(t, updates, tmelink.NetworkViewUpdate{Voting: voting, Committing: committing})
+	require.Equal(t, voting, first.RequireVotingView(t, 1, 0))
+
+	voting2 := &tmconsensus.VersionedRoundView{RoundView: tmconsensus.RoundView{Height: 1, Round: 1}}
+	gtest.SendSoon(t, updates, tmelink.NetworkViewUpdate{Voting: voting2})
+	_ = first.RequireVotingView(t, 1, 1)
+
+	require.NoError(t, s.Swap(ctx, factory))
+
+	// The old strategy was stopped.
+	_ = gtest.ReceiveSoon(t, first.Done())
+
+	// The new strategy receives the latest views first.
+	second := gtest.ReceiveSoon(t, strategies)
+	_ = second.RequireVotingView(t, 1, 1)
+	got := second.Records()[0].Update
+	require.Equal(t, voting2, got.Voting)
+	require.Equal(t, committing, got.Committing)
+	require.Nil(t, got.NextRound)
+
+	// Then subsequent updates.
+	nextRound := &tmconsensus.VersionedRoundView{RoundView: tmconsensus.RoundView{Height: 1, Round: 2}}
+	gtest.SendSoon(t, updates, tmelink.NetworkViewUpdate{NextRound: nextRound})
+	got = second.RequireUpdate(t, func(u tmelink.NetworkViewUpdate) bool {
+		return u.NextRound != nil
+	})
+	require.Equal(t, nextRound, got.NextRound)
+	require.Len(t, first.Records(), 2)
+}
+
+func TestSwappableStrategy_Swap_beforeStart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	strategies := make(chan *ggossiptest.RecordingStrategy, 2)
+	factory := func(ctx context.Context) tmgossip.Strategy {
+		s := ggossiptest.NewRecordingStrategy(ctx)
+		strategies <- s
+		return s
+	}
+
+	s := ggossip.NewSwappableStrategy(ctx, gtest.NewLogger(t), factory)
+	defer s.Wait()
+	defer cancel()
+
+	first := gtest.ReceiveSoon(t, strategies)
+	require.NoError(t, s.Swap(ctx, factory))
+	second := gtest.ReceiveSoon(t, strategies)
+	_ = gtest.ReceiveSoon(t, first.Done())
+
+	// Only the replacement is started.
+	updates := make(chan tmelink.NetworkViewUpdate)
+	s.Start(updates)
+
+	voting := &tmconsensus.VersionedRoundView{RoundView: tmconsensus.RoundView{Height: 1}}
+	gtest.SendSoon(t, updates, tmelink.NetworkViewUpdate{Voting: voting})
+	require.Equal(t, voting, second.RequireVotingView(t, 1, 0))
+}
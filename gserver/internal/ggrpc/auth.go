@@ -0,0 +1,60 @@
+package ggrpc
+
+import (
+	"context"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtenant"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ChainIDMetadataKey is the gRPC metadata key a client may set
+// to the chain ID it expects to reach.
+// A request naming a different chain is rejected,
+// so that a client configured for one chain cannot act on another
+// through a misdirected endpoint.
+const ChainIDMetadataKey = "gordian-chain-id"
+
+// scope checks the chain ID and bearer token of an incoming request.
+type scope struct {
+	chainID string
+	tokens  *gtenant.Tokens
+}
+
+func (s scope) check(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if ids := md.Get(ChainIDMetadataKey); len(ids) > 0 && s.chainID != "" && ids[0] != s.chainID {
+		return status.Errorf(codes.PermissionDenied, "request for chain %q sent to chain %q", ids[0], s.chainID)
+	}
+
+	var auth string
+	if vs := md.Get("authorization"); len(vs) > 0 {
+		auth = vs[0]
+	}
+	if !s.tokens.Allow(auth) {
+		return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+
+	return nil
+}
+
+func (s scope) unary(
+	ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	if err := s.check(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s scope) stream(
+	srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	if err := s.check(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -10,6 +10,7 @@ import (
 	"cosmossdk.io/server/v2/appmanager"
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtenant"
 	"github.com/gordian-engine/gordian/gcrypto"
 	"github.com/gordian-engine/gordian/tm/tmstore"
 	grpc "google.golang.org/grpc"
@@ -28,10 +29,11 @@ type GordianGRPC struct {
 	reg *gcrypto.Registry
 
 	// debug handler
-	txc   transaction.Codec[transaction.Tx]
-	am    appmanager.AppManager[transaction.Tx]
-	txBuf *gsi.SDKTxBuf
-	cdc   codec.Codec
+	txc    transaction.Codec[transaction.Tx]
+	am     appmanager.AppManager[transaction.Tx]
+	txBuf  *gsi.SDKTxBuf
+	txPool *gsi.TxPool
+	cdc    codec.Codec
 
 	done chan struct{}
 }
@@ -39,6 +41,13 @@ type GordianGRPC struct {
 type GRPCServerConfig struct {
 	Listener net.Listener
 
+	// Requests naming another chain in their metadata are rejected.
+	ChainID string
+
+	// If enabled, every request must carry one of the tokens
+	// in its authorization metadata.
+	AuthTokens *gtenant.Tokens
+
 	FinalizationStore tmstore.FinalizationStore
 	MirrorStore       tmstore.MirrorStore
 
@@ -49,6 +58,7 @@ type GRPCServerConfig struct {
 	Codec      codec.Codec
 
 	TxBuffer *gsi.SDKTxBuf
+	TxPool   *gsi.TxPool
 }
 
 func NewGordianGRPCServer(ctx context.Context, log *slog.Logger, cfg GRPCServerConfig) *GordianGRPC {
@@ -59,18 +69,23 @@ func NewGordianGRPCServer(ctx context.Context, log *slog.Logger, cfg GRPCServerC
 	srv := &GordianGRPC{
 		log: log,
 
-		fs:    cfg.FinalizationStore,
-		ms:    cfg.MirrorStore,
-		reg:   cfg.CryptoRegistry,
-		txc:   cfg.TxCodec,
-		am:    cfg.AppManager,
-		txBuf: cfg.TxBuffer,
-		cdc:   cfg.Codec,
+		fs:     cfg.FinalizationStore,
+		ms:     cfg.MirrorStore,
+		reg:    cfg.CryptoRegistry,
+		txc:    cfg.TxCodec,
+		am:     cfg.AppManager,
+		txBuf:  cfg.TxBuffer,
+		txPool: cfg.TxPool,
+		cdc:    cfg.Codec,
 
 		done: make(chan struct{}),
 	}
 
-	var opts []grpc.ServerOption
+	sc := scope{chainID: cfg.ChainID, tokens: cfg.AuthTokens}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(sc.unary),
+		grpc.ChainStreamInterceptor(sc.stream),
+	}
 	// TODO: configure grpc options (like TLS)
 	gs := grpc.NewServer(opts...)
 
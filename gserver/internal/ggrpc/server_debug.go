@@ -36,7 +36,7 @@ func (g *GordianGRPC) SubmitTransaction(ctx context.Context, req *SubmitTransact
 	}
 
 	// If it passed basic validation, then we can attempt to add it to the buffer.
-	if err := g.txBuf.AddTx(ctx, tx); err != nil {
+	if err := g.txPool.AddTx(ctx, tx); err != nil {
 		// We could potentially check if it is a TxInvalidError here
 		// and adjust the status code,
 		// but since this is a debug endpoint, we'll ignore the type.
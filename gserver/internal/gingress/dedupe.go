@@ -0,0 +1,240 @@
+package gingress
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/gexchange"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Defaults for [DedupeConfig].
+const (
+	DefaultDedupeWindow     = 30 * time.Second
+	DefaultDedupeMaxEntries = 4096
+)
+
+// DedupeConfig is the configuration for [NewDeduper].
+type DedupeConfig struct {
+	// How long an accepted message is remembered.
+	// Defaults to DefaultDedupeWindow if zero.
+	Window time.Duration
+
+	// Maximum number of remembered messages;
+	// the oldest are forgotten first.
+	// Defaults to DefaultDedupeMaxEntries if zero.
+	MaxEntries int
+
+	// Optional clock; time.Now if nil.
+	Now func() time.Time
+}
+
+// DedupeStats is a snapshot of a [Deduper]'s state.
+type DedupeStats struct {
+	// Number of remembered messages, and the configured maximum.
+	Entries, MaxEntries int
+
+	// Number of messages dropped as duplicates.
+	Dropped uint64
+}
+
+// Deduper is a [tmconsensus.ConsensusHandler] that drops messages
+// identical to one its inner handler recently accepted.
+//
+// Gossip delivers the same proposed header or vote proof
+// from every peer who relays it,
+// and each copy would otherwise pass through the mirror and its kernel
+// only to be found redundant there.
+// Duplicates are ignored rather than rejected,
+// since relaying a valid message is not the peer's fault.
+//
+// A message is only remembered once the inner handler accepts it,
+// so a message that arrived too early to be accepted
+// may be delivered again by another peer.
+type Deduper struct {
+	inner tmconsensus.ConsensusHandler
+
+	window     time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	seen    map[[sha256.Size]byte]time.Time
+	order   []seenEntry // In order of acceptance, oldest first.
+	dropped uint64
+}
+
+type seenEntry struct {
+	key [sha256.Size]byte
+	at  time.Time
+}
+
+var _ tmconsensus.ConsensusHandler = (*Deduper)(nil)
+
+// NewDeduper returns a new Deduper wrapping inner.
+func NewDeduper(inner tmconsensus.ConsensusHandler, cfg DedupeConfig) *Deduper {
+	if cfg.Window == 0 {
+		cfg.Window = DefaultDedupeWindow
+	}
+	if cfg.MaxEntries == 0 {
+		cfg.MaxEntries = DefaultDedupeMaxEntries
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &Deduper{
+		inner: inner,
+
+		window:     cfg.Window,
+		maxEntries: cfg.MaxEntries,
+		now:        cfg.Now,
+
+		seen: make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+func (d *Deduper) HandleProposedHeader(ctx context.Context, ph tmconsensus.ProposedHeader) gexchange.Feedback {
+	h := sha256.New()
+	writeBytes(h, []byte("ph"))
+	writeBytes(h, ph.Header.Hash)
+	writeUint(h, uint64(ph.Round))
+	if ph.ProposerPubKey != nil {
+		writeBytes(h, ph.ProposerPubKey.PubKeyBytes())
+	}
+	writeBytes(h, ph.Annotations.User)
+	writeBytes(h, ph.Annotations.Driver)
+	writeBytes(h, ph.Signature)
+
+	return d.handle(sum(h), func() gexchange.Feedback {
+		return d.inner.HandleProposedHeader(ctx, ph)
+	})
+}
+
+func (d *Deduper) HandlePrevoteProofs(ctx context.Context, p tmconsensus.PrevoteSparseProof) gexchange.Feedback {
+	key := proofKey("prevote", p.Height, p.Round, p.PubKeyHash, p.Proofs)
+	return d.handle(key, func() gexchange.Feedback {
+		return d.inner.HandlePrevoteProofs(ctx, p)
+	})
+}
+
+func (d *Deduper) HandlePrecommitProofs(ctx context.Context, p tmconsensus.PrecommitSparseProof) gexchange.Feedback {
+	key := proofKey("precommit", p.Height, p.Round, p.PubKeyHash, p.Proofs)
+	return d.handle(key, func() gexchange.Feedback {
+		return d.inner.HandlePrecommitProofs(ctx, p)
+	})
+}
+
+// Stats returns a snapshot of d's state.
+func (d *Deduper) Stats() DedupeStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return DedupeStats{
+		Entries:    len(d.seen),
+		MaxEntries: d.maxEntries,
+
+		Dropped: d.dropped,
+	}
+}
+
+// handle drops the message identified by key if it was recently accepted,
+// and otherwise calls fn, remembering key if fn accepts the message.
+func (d *Deduper) handle(key [sha256.Size]byte, fn func() gexchange.Feedback) gexchange.Feedback {
+	d.mu.Lock()
+	d.expire(d.now())
+	if _, ok := d.seen[key]; ok {
+		d.dropped++
+		d.mu.Unlock()
+		return gexchange.FeedbackIgnored
+	}
+	d.mu.Unlock()
+
+	f := fn()
+	if f != gexchange.FeedbackAccepted {
+		return f
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.seen[key]; ok {
+		// A concurrent copy was accepted first.
+		return f
+	}
+
+	now := d.now()
+	d.seen[key] = now
+	d.order = append(d.order, seenEntry{key: key, at: now})
+	if len(d.order) > d.maxEntries {
+		delete(d.seen, d.order[0].key)
+		d.order = slices.Delete(d.order, 0, 1)
+	}
+
+	return f
+}
+
+// expire forgets messages accepted longer than the window before now.
+// The caller must hold d.mu.
+func (d *Deduper) expire(now time.Time) {
+	n := 0
+	for n < len(d.order) && now.Sub(d.order[n].at) >= d.window {
+		delete(d.seen, d.order[n].key)
+		n++
+	}
+	if n > 0 {
+		d.order = slices.Delete(d.order, 0, n)
+	}
+}
+
+// proofKey returns the dedupe key of a sparse vote proof.
+func proofKey(
+	kind string, height uint64, round uint32, pubKeyHash string, proofs map[string][]gcrypto.SparseSignature,
+) [sha256.Size]byte {
+	h := sha256.New()
+	writeBytes(h, []byte(kind))
+	writeUint(h, height)
+	writeUint(h, uint64(round))
+	writeBytes(h, []byte(pubKeyHash))
+
+	// Map iteration order is random, so sort the block hashes.
+	blockHashes := make([]string, 0, len(proofs))
+	for bh := range proofs {
+		blockHashes = append(blockHashes, bh)
+	}
+	slices.Sort(blockHashes)
+
+	for _, bh := range blockHashes {
+		writeBytes(h, []byte(bh))
+		sigs := proofs[bh]
+		writeUint(h, uint64(len(sigs)))
+		for _, s := range sigs {
+			writeBytes(h, s.KeyID)
+			writeBytes(h, s.Sig)
+		}
+	}
+
+	return sum(h)
+}
+
+// writeBytes writes b to h with a length prefix,
+// so that adjacent fields cannot run into each other.
+func writeBytes(h hash.Hash, b []byte) {
+	writeUint(h, uint64(len(b)))
+	_, _ = h.Write(b)
+}
+
+func writeUint(h hash.Hash, u uint64) {
+	_, _ = h.Write(binary.BigEndian.AppendUint64(nil, u))
+}
+
+func sum(h hash.Hash) (out [sha256.Size]byte) {
+	_ = h.Sum(out[:0])
+	return out
+}
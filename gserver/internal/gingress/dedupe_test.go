@@ -0,0 +1,98 @@
+package gingress_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gingress"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/gexchange"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduper_proposedHeaders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	inner := acceptingHandler{phFeedback: gexchange.FeedbackIgnored}
+	now := time.Unix(1_000, 0)
+	d := gingress.NewDeduper(&inner, gingress.DedupeConfig{
+		Window: time.Second,
+		Now:    func() time.Time { return now },
+	})
+
+	pubKey, err := gcrypto.NewEd25519PubKey(make([]byte, 32))
+	require.NoError(t, err)
+	ph := tmconsensus.ProposedHeader{
+		Header:         tmconsensus.Header{Height: 1, Hash: []byte("a")},
+		ProposerPubKey: pubKey,
+		Signature:      []byte("sig"),
+	}
+
+	// Messages the inner handler did not accept are not remembered.
+	require.Equal(t, gexchange.FeedbackIgnored, d.HandleProposedHeader(ctx, ph))
+	inner.phFeedback = gexchange.FeedbackAccepted
+	require.Equal(t, gexchange.FeedbackAccepted, d.HandleProposedHeader(ctx, ph))
+	require.Equal(t, 2, inner.phs)
+
+	// An identical copy is dropped.
+	require.Equal(t, gexchange.FeedbackIgnored, d.HandleProposedHeader(ctx, ph))
+	require.Equal(t, 2, inner.phs)
+
+	// Any difference, such as the round, is a distinct message.
+	ph2 := ph
+	ph2.Round = 1
+	require.Equal(t, gexchange.FeedbackAccepted, d.HandleProposedHeader(ctx, ph2))
+	require.Equal(t, 3, inner.phs)
+
+	s := d.Stats()
+	require.Equal(t, 2, s.Entries)
+	require.Equal(t, uint64(1), s.Dropped)
+
+	// After the window, the message is forgotten.
+	now = now.Add(time.Second)
+	require.Equal(t, gexchange.FeedbackAccepted, d.HandleProposedHeader(ctx, ph))
+	require.Equal(t, 4, inner.phs)
+	require.Equal(t, 1, d.Stats().Entries)
+}
+
+func TestDeduper_votes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var inner acceptingHandler
+	d := gingress.NewDeduper(&inner, gingress.DedupeConfig{
+		MaxEntries: 2,
+	})
+
+	proofs := func(sig string) map[string][]gcrypto.SparseSignature {
+		return map[string][]gcrypto.SparseSignature{
+			"a": {{KeyID: []byte{0}, Sig: []byte(sig)}},
+			"":  {{KeyID: []byte{1}, Sig: []byte("nil")}},
+		}
+	}
+
+	pv := tmconsensus.PrevoteSparseProof{Height: 1, PubKeyHash: "h", Proofs: proofs("x")}
+	require.Equal(t, gexchange.FeedbackAccepted, d.HandlePrevoteProofs(ctx, pv))
+
+	// Equal content in a different map is still a duplicate.
+	pv2 := tmconsensus.PrevoteSparseProof{Height: 1, PubKeyHash: "h", Proofs: proofs("x")}
+	require.Equal(t, gexchange.FeedbackIgnored, d.HandlePrevoteProofs(ctx, pv2))
+	require.Equal(t, 1, inner.votes)
+
+	// A precommit with the same fields is not a duplicate of the prevote.
+	pc := tmconsensus.PrecommitSparseProof{Height: 1, PubKeyHash: "h", Proofs: proofs("x")}
+	require.Equal(t, gexchange.FeedbackAccepted, d.HandlePrecommitProofs(ctx, pc))
+	require.Equal(t, 2, inner.votes)
+
+	// Exceeding the entry limit forgets the oldest message.
+	pv3 := tmconsensus.PrevoteSparseProof{Height: 1, PubKeyHash: "h", Proofs: proofs("y")}
+	require.Equal(t, gexchange.FeedbackAccepted, d.HandlePrevoteProofs(ctx, pv3))
+	require.Equal(t, gexchange.FeedbackAccepted, d.HandlePrevoteProofs(ctx, pv))
+	require.Equal(t, 4, inner.votes)
+	require.Equal(t, 2, d.Stats().Entries)
+}
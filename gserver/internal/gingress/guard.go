@@ -0,0 +1,311 @@
+// Package gingress contains checks applied to incoming consensus messages
+// before they are handed to the engine.
+package gingress
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gordian/gexchange"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// GuardConfig is the configuration for [NewGuard].
+type GuardConfig struct {
+	// The maximum number of distinct proposed headers
+	// to accept in a single height and round.
+	// Each proposer may only contribute one distinct header per round,
+	// so that honest proposers are preferred over one proposer flooding the round.
+	// Headers arriving concurrently may briefly exceed the limit.
+	// Zero means no limit.
+	MaxProposedHeadersPerRound int
+
+	// Votes for heights more than this many heights
+	// beyond the local committing height are rejected.
+	// Zero means no limit.
+	MaxFutureVoteHeights uint64
+
+	// Reports the local committing height.
+	// Required if MaxFutureVoteHeights is nonzero.
+	// Only called when a vote appears to be too far in the future,
+	// so it is acceptable for it to do store work.
+	CommittingHeight func(context.Context) (uint64, error)
+
+	// Optional log to record proposers who sign two different headers
+	// in the same height and round.
+	Audit *gaudit.Log
+
+	// The scheme used to check the signature of the second header
+	// before recording an equivocation.
+	// Required if Audit is set.
+	SignatureScheme tmconsensus.SignatureScheme
+}
+
+// Guard is a [tmconsensus.ConsensusHandler] that enforces limits
+// on incoming messages before passing them to another handler.
+// Messages violating the limits are rejected,
+// which penalizes the peer who sent them.
+type Guard struct {
+	log *slog.Logger
+
+	inner tmconsensus.ConsensusHandler
+
+	maxPHs int
+
+	maxFutureHeights uint64
+	committingHeight func(context.Context) (uint64, error)
+
+	audit     *gaudit.Log
+	sigScheme tmconsensus.SignatureScheme
+
+	mu     sync.Mutex
+	rounds map[heightRound]*roundProposals
+
+	// Last known committing height, refreshed on demand.
+	knownCommitting uint64
+}
+
+type heightRound struct {
+	H uint64
+	R uint32
+}
+
+type roundProposals struct {
+	// Proposer public key bytes to the first header it proposed.
+	byProposer map[string]*proposal
+}
+
+type proposal struct {
+	ph tmconsensus.ProposedHeader
+
+	// Whether an equivocation was already recorded for the proposer,
+	// so that repeated conflicting headers do not flood the audit log.
+	reported bool
+}
+
+var _ tmconsensus.ConsensusHandler = (*Guard)(nil)
+
+// NewGuard returns a new Guard wrapping inner.
+func NewGuard(log *slog.Logger, inner tmconsensus.ConsensusHandler, cfg GuardConfig) *Guard {
+	if cfg.MaxFutureVoteHeights > 0 && cfg.CommittingHeight == nil {
+		panic(errors.New("BUG: GuardConfig.CommittingHeight must be set when MaxFutureVoteHeights is nonzero"))
+	}
+	if cfg.Audit != nil && cfg.SignatureScheme == nil {
+		panic(errors.New("BUG: GuardConfig.SignatureScheme must be set when Audit is set"))
+	}
+
+	return &Guard{
+		log: log,
+
+		inner: inner,
+
+		maxPHs: cfg.MaxProposedHeadersPerRound,
+
+		maxFutureHeights: cfg.MaxFutureVoteHeights,
+		committingHeight: cfg.CommittingHeight,
+
+		audit:     cfg.Audit,
+		sigScheme: cfg.SignatureScheme,
+
+		rounds: make(map[heightRound]*roundProposals),
+	}
+}
+
+func (g *Guard) HandleProposedHeader(ctx context.Context, ph tmconsensus.ProposedHeader) gexchange.Feedback {
+	if g.maxPHs <= 0 && g.audit == nil {
+		return g.inner.HandleProposedHeader(ctx, ph)
+	}
+
+	hr := heightRound{H: ph.Header.Height, R: ph.Round}
+	proposer := string(ph.ProposerPubKey.PubKeyBytes())
+	hash := string(ph.Header.Hash)
+
+	g.mu.Lock()
+	rp := g.rounds[hr]
+	if rp != nil {
+		if prev, ok := rp.byProposer[proposer]; ok {
+			if string(prev.ph.Header.Hash) == hash {
+				g.mu.Unlock()
+
+				// Plain duplicate; let the inner handler decide what to do.
+				return g.inner.HandleProposedHeader(ctx, ph)
+			}
+
+			// Claim the report before unlocking,
+			// so that concurrent copies do not both record it.
+			report := g.audit != nil && !prev.reported
+			if report {
+				prev.reported = true
+			}
+			prevPH := prev.ph
+			g.mu.Unlock()
+
+			if report && !g.recordEquivocation(prevPH, ph) {
+				g.mu.Lock()
+				prev.reported = false
+				g.mu.Unlock()
+			}
+
+			if g.maxPHs <= 0 {
+				return g.inner.HandleProposedHeader(ctx, ph)
+			}
+
+			g.log.Info(
+				"Rejecting additional proposed header from proposer who already proposed in this round",
+				"height", hr.H, "round", hr.R,
+			)
+			return gexchange.FeedbackRejected
+		}
+
+		if g.maxPHs > 0 && len(rp.byProposer) >= g.maxPHs {
+			g.mu.Unlock()
+
+			g.log.Info(
+				"Rejecting proposed header beyond per-round limit",
+				"height", hr.H, "round", hr.R, "limit", g.maxPHs,
+			)
+			return gexchange.FeedbackRejected
+		}
+	}
+	g.mu.Unlock()
+
+	// Only count the header once the inner handler has validated it,
+	// so that invalid headers cannot consume the round's capacity.
+	f := g.inner.HandleProposedHeader(ctx, ph)
+	if f != gexchange.FeedbackAccepted {
+		return f
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	rp = g.rounds[hr]
+	if rp == nil {
+		rp = &roundProposals{byProposer: make(map[string]*proposal)}
+		g.rounds[hr] = rp
+
+		// A new round is a reasonable time to discard rounds from old heights.
+		for k := range g.rounds {
+			if k.H+1 < hr.H {
+				delete(g.rounds, k)
+			}
+		}
+	}
+	if _, ok := rp.byProposer[proposer]; !ok {
+		rp.byProposer[proposer] = &proposal{ph: ph}
+	}
+
+	return f
+}
+
+// recordEquivocation records prev and ph in the audit log,
+// if ph is validly signed by its proposer,
+// and reports whether it did.
+// The caller must not hold g.mu.
+//
+// The inner handler already verified prev before it was tracked,
+// but ph has not been verified,
+// and an unsigned conflicting header is not evidence against the proposer.
+func (g *Guard) recordEquivocation(prev, ph tmconsensus.ProposedHeader) bool {
+	signBytes, err := tmconsensus.ProposalSignBytes(ph.Header, ph.Round, ph.Annotations, g.sigScheme)
+	if err != nil {
+		g.log.Debug("Failed to get sign bytes of conflicting proposed header", "err", err)
+		return false
+	}
+	if !ph.ProposerPubKey.Verify(signBytes, ph.Signature) {
+		return false
+	}
+
+	g.audit.Equivocation(prev, ph)
+	return true
+}
+
+// GuardStats is a snapshot of the state retained by a [Guard],
+// alongside its configured limits.
+type GuardStats struct {
+	// Number of height-round pairs whose proposed headers are tracked,
+	// and the total number of proposed headers tracked across them.
+	TrackedRounds, TrackedProposedHeaders int
+
+	MaxProposedHeadersPerRound int
+	MaxFutureVoteHeights       uint64
+
+	// Last known committing height, used to bound future votes.
+	KnownCommittingHeight uint64
+}
+
+// Stats returns a snapshot of the state retained by g.
+func (g *Guard) Stats() GuardStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s := GuardStats{
+		TrackedRounds: len(g.rounds),
+
+		MaxProposedHeadersPerRound: g.maxPHs,
+		MaxFutureVoteHeights:       g.maxFutureHeights,
+
+		KnownCommittingHeight: g.knownCommitting,
+	}
+	for _, rp := range g.rounds {
+		s.TrackedProposedHeaders += len(rp.byProposer)
+	}
+	return s
+}
+
+func (g *Guard) HandlePrevoteProofs(ctx context.Context, p tmconsensus.PrevoteSparseProof) gexchange.Feedback {
+	if !g.voteHeightAllowed(ctx, p.Height) {
+		return gexchange.FeedbackRejected
+	}
+	return g.inner.HandlePrevoteProofs(ctx, p)
+}
+
+func (g *Guard) HandlePrecommitProofs(ctx context.Context, p tmconsensus.PrecommitSparseProof) gexchange.Feedback {
+	if !g.voteHeightAllowed(ctx, p.Height) {
+		return gexchange.FeedbackRejected
+	}
+	return g.inner.HandlePrecommitProofs(ctx, p)
+}
+
+// voteHeightAllowed reports whether a vote at height h
+// is within the configured distance of the committing height.
+func (g *Guard) voteHeightAllowed(ctx context.Context, h uint64) bool {
+	if g.maxFutureHeights == 0 {
+		return true
+	}
+
+	g.mu.Lock()
+	known := g.knownCommitting
+	g.mu.Unlock()
+
+	if h <= known+g.maxFutureHeights {
+		return true
+	}
+
+	// Our cached height may just be stale, so check the current value
+	// before penalizing the peer.
+	ch, err := g.committingHeight(ctx)
+	if err != nil {
+		// Not the peer's fault; let the engine decide.
+		g.log.Warn("Failed to get committing height for vote bounds check", "err", err)
+		return true
+	}
+
+	g.mu.Lock()
+	g.knownCommitting = max(g.knownCommitting, ch)
+	known = g.knownCommitting
+	g.mu.Unlock()
+
+	if h <= known+g.maxFutureHeights {
+		return true
+	}
+
+	g.log.Info(
+		"Rejecting vote too far beyond committing height",
+		"vote_height", h, "committing_height", known, "limit", g.maxFutureHeights,
+	)
+	return false
+}
@@ -0,0 +1,205 @@
+package gingress_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gingress"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/gexchange"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard_maxProposedHeadersPerRound(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var inner acceptingHandler
+	g := gingress.NewGuard(gtest.NewLogger(t), &inner, gingress.GuardConfig{
+		MaxProposedHeadersPerRound: 2,
+	})
+
+	ph := func(h uint64, r uint32, proposer byte, hash string) tmconsensus.ProposedHeader {
+		b := make([]byte, 32)
+		b[0] = proposer
+		pubKey, err := gcrypto.NewEd25519PubKey(b)
+		require.NoError(t, err)
+
+		return tmconsensus.ProposedHeader{
+			Header: tmconsensus.Header{
+				Height: h,
+				Hash:   []byte(hash),
+			},
+			Round:          r,
+			ProposerPubKey: pubKey,
+		}
+	}
+
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, ph(1, 0, 1, "a")))
+
+	// Exact duplicate is passed through.
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, ph(1, 0, 1, "a")))
+	require.Equal(t, 2, inner.phs)
+
+	// Same proposer with a different header is rejected without reaching the inner handler.
+	require.Equal(t, gexchange.FeedbackRejected, g.HandleProposedHeader(ctx, ph(1, 0, 1, "b")))
+	require.Equal(t, 2, inner.phs)
+
+	// Second proposer fills the round.
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, ph(1, 0, 2, "c")))
+
+	// Third proposer is over the limit.
+	require.Equal(t, gexchange.FeedbackRejected, g.HandleProposedHeader(ctx, ph(1, 0, 3, "d")))
+
+	// But the limit is per round.
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, ph(1, 1, 3, "d")))
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, ph(2, 0, 3, "e")))
+
+	s := g.Stats()
+	require.Equal(t, 3, s.TrackedRounds)
+	require.Equal(t, 4, s.TrackedProposedHeaders)
+	require.Equal(t, 2, s.MaxProposedHeadersPerRound)
+
+	// Rounds more than one height old are dropped once a new round is tracked.
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, ph(3, 0, 1, "f")))
+	s = g.Stats()
+	require.Equal(t, 2, s.TrackedRounds)
+	require.Equal(t, 2, s.TrackedProposedHeaders)
+}
+
+func TestGuard_invalidHeadersDoNotConsumeCapacity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	inner := acceptingHandler{phFeedback: gexchange.FeedbackRejected}
+	g := gingress.NewGuard(gtest.NewLogger(t), &inner, gingress.GuardConfig{
+		MaxProposedHeadersPerRound: 1,
+	})
+
+	pubKey, err := gcrypto.NewEd25519PubKey(make([]byte, 32))
+	require.NoError(t, err)
+	ph := tmconsensus.ProposedHeader{
+		Header:         tmconsensus.Header{Height: 1, Hash: []byte("a")},
+		ProposerPubKey: pubKey,
+	}
+
+	require.Equal(t, gexchange.FeedbackRejected, g.HandleProposedHeader(ctx, ph))
+
+	inner.phFeedback = gexchange.FeedbackAccepted
+	ph.Header.Hash = []byte("b")
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, ph))
+}
+
+func TestGuard_auditEquivocation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+	audit, err := gaudit.OpenLog(gtest.NewLogger(t), gaudit.LogConfig{
+		Path:  filepath.Join(t.TempDir(), "audit.jsonl"),
+		Codec: tmjson.MarshalCodec{CryptoRegistry: &reg},
+	})
+	require.NoError(t, err)
+	defer audit.Close()
+
+	fx := tmconsensustest.NewStandardFixture(2)
+
+	var inner acceptingHandler
+	g := gingress.NewGuard(gtest.NewLogger(t), &inner, gingress.GuardConfig{
+		Audit:           audit,
+		SignatureScheme: fx.SignatureScheme,
+	})
+
+	a := fx.NextProposedHeader([]byte("a"), 0)
+	fx.SignProposal(ctx, &a, 0)
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, a))
+
+	// A conflicting header with a bad signature is not evidence.
+	b := fx.NextProposedHeader([]byte("b"), 0)
+	fx.SignProposal(ctx, &b, 0)
+	forged := b
+	forged.Signature = []byte("forged")
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, forged))
+
+	rs, err := audit.Records()
+	require.NoError(t, err)
+	require.Empty(t, rs)
+
+	// Without a per-round limit, the properly signed conflicting header
+	// is recorded once and still passed through.
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, b))
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandleProposedHeader(ctx, b))
+	require.Equal(t, 4, inner.phs)
+
+	rs, err = audit.Records()
+	require.NoError(t, err)
+	require.Len(t, rs, 1)
+	require.Equal(t, gaudit.KindEquivocation, rs[0].Kind)
+	require.Equal(t, fx.ValidatorPubKey(0).PubKeyBytes(), rs[0].PubKey)
+}
+
+func TestGuard_maxFutureVoteHeights(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	committing := uint64(5)
+	nCalls := 0
+
+	var inner acceptingHandler
+	g := gingress.NewGuard(gtest.NewLogger(t), &inner, gingress.GuardConfig{
+		MaxFutureVoteHeights: 3,
+		CommittingHeight: func(context.Context) (uint64, error) {
+			nCalls++
+			return committing, nil
+		},
+	})
+
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandlePrevoteProofs(ctx, tmconsensus.PrevoteSparseProof{Height: 8}))
+	require.Equal(t, gexchange.FeedbackRejected, g.HandlePrevoteProofs(ctx, tmconsensus.PrevoteSparseProof{Height: 9}))
+	require.Equal(t, gexchange.FeedbackRejected, g.HandlePrecommitProofs(ctx, tmconsensus.PrecommitSparseProof{Height: 100}))
+
+	// Once the committing height advances, the previously rejected height is acceptable.
+	committing = 6
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandlePrecommitProofs(ctx, tmconsensus.PrecommitSparseProof{Height: 9}))
+
+	// And with the cached height, nearby votes do not need another lookup.
+	n := nCalls
+	require.Equal(t, gexchange.FeedbackAccepted, g.HandlePrevoteProofs(ctx, tmconsensus.PrevoteSparseProof{Height: 7}))
+	require.Equal(t, n, nCalls)
+}
+
+type acceptingHandler struct {
+	phs, votes int
+
+	// Zero value is treated as FeedbackAccepted.
+	phFeedback gexchange.Feedback
+}
+
+func (h *acceptingHandler) HandleProposedHeader(context.Context, tmconsensus.ProposedHeader) gexchange.Feedback {
+	h.phs++
+	if h.phFeedback == gexchange.FeedbackUnspecified {
+		return gexchange.FeedbackAccepted
+	}
+	return h.phFeedback
+}
+
+func (h *acceptingHandler) HandlePrevoteProofs(context.Context, tmconsensus.PrevoteSparseProof) gexchange.Feedback {
+	h.votes++
+	return gexchange.FeedbackAccepted
+}
+
+func (h *acceptingHandler) HandlePrecommitProofs(context.Context, tmconsensus.PrecommitSparseProof) gexchange.Feedback {
+	h.votes++
+	return gexchange.FeedbackAccepted
+}
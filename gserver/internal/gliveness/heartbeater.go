@@ -0,0 +1,289 @@
+// Package gliveness exchanges periodic heartbeats between peers.
+//
+// While the app finalizes a slow block, or while there are no transactions to propose,
+// a node may send no consensus messages for a long time,
+// and its peers cannot tell whether it is quiet or dead.
+// A [Heartbeater] sends every connected peer a small [Status] on a fixed interval,
+// and records the latest status received from each peer,
+// so that other subsystems can prefer peers that are alive
+// and that have reached a particular height.
+package gliveness
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"runtime/trace"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gready"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+	libp2pprotocol "github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// ProtocolID is the libp2p protocol for heartbeats.
+// The sender writes a JSON-encoded [Status] and closes the stream;
+// there is no response.
+const ProtocolID = libp2pprotocol.ID("/gcosmos/heartbeat/v1")
+
+// DefaultInterval is the heartbeat interval used when [HeartbeaterConfig.Interval] is zero.
+const DefaultInterval = 5 * time.Second
+
+// A peer is considered live if its latest heartbeat
+// arrived within this many intervals.
+const liveIntervals = 3
+
+// How long to spend sending a single heartbeat.
+const sendTimeout = 2 * time.Second
+
+// Maximum size of an encoded [Status],
+// leaving room for a readiness signal with the most features.
+const maxStatusSize = 4096
+
+// Steps reported in [Status].
+const (
+	StepVoting     = "voting"
+	StepFinalizing = "finalizing"
+	StepFollowing  = "following"
+)
+
+// Status is the content of a heartbeat.
+type Status struct {
+	// The voting height and round.
+	Height uint64
+	Round  uint32
+
+	// What the node is currently doing; one of the Step constants.
+	Step string
+
+	// The latest height the app has finalized, if known.
+	FinalizedHeight uint64 `json:",omitempty"`
+
+	// The features the node's validator is ready to run, if any.
+	// Receivers must verify the signal before trusting it.
+	Readiness *gready.Signal `json:",omitempty"`
+}
+
+// PeerStatus is the latest heartbeat received from a peer.
+type PeerStatus struct {
+	Peer string
+
+	Status
+
+	LastSeen time.Time
+
+	// Whether the heartbeat is recent enough that the peer is considered live.
+	Live bool
+}
+
+// HeartbeaterConfig is the configuration for [NewHeartbeater].
+type HeartbeaterConfig struct {
+	Host libp2phost.Host
+
+	// How often to send heartbeats to every connected peer.
+	// Defaults to DefaultInterval if zero.
+	Interval time.Duration
+
+	// The local node's status, called once per interval.
+	Status func() Status
+
+	// Optional callback for every heartbeat received,
+	// called on the goroutine handling the peer's stream.
+	OnStatus func(p libp2ppeer.ID, st Status)
+}
+
+// Heartbeater sends heartbeats to, and receives heartbeats from, connected peers.
+type Heartbeater struct {
+	log *slog.Logger
+
+	host     libp2phost.Host
+	interval time.Duration
+	status   func() Status
+	onStatus func(libp2ppeer.ID, Status)
+
+	mu    sync.Mutex
+	peers map[libp2ppeer.ID]PeerStatus
+
+	done chan struct{}
+}
+
+// NewHeartbeater returns a new Heartbeater based on cfg,
+// which runs until ctx is canceled.
+func NewHeartbeater(ctx context.Context, log *slog.Logger, cfg HeartbeaterConfig) *Heartbeater {
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	h := &Heartbeater{
+		log: log,
+
+		host:     cfg.Host,
+		interval: interval,
+		status:   cfg.Status,
+		onStatus: cfg.OnStatus,
+
+		peers: make(map[libp2ppeer.ID]PeerStatus),
+
+		done: make(chan struct{}),
+	}
+
+	h.host.SetStreamHandler(ProtocolID, h.handleStream)
+
+	go h.kernel(ctx)
+
+	return h
+}
+
+// Wait blocks until h has stopped.
+func (h *Heartbeater) Wait() {
+	<-h.done
+}
+
+// Peers returns the latest heartbeat from each connected peer, sorted by peer ID.
+func (h *Heartbeater) Peers() []PeerStatus {
+	h.mu.Lock()
+	out := make([]PeerStatus, 0, len(h.peers))
+	for _, ps := range h.peers {
+		out = append(out, h.withLive(ps))
+	}
+	h.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b PeerStatus) int {
+		return strings.Compare(a.Peer, b.Peer)
+	})
+	return out
+}
+
+// Peer returns the latest heartbeat from p,
+// and false if no heartbeat has been received from p.
+func (h *Heartbeater) Peer(p libp2ppeer.ID) (PeerStatus, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ps, ok := h.peers[p]
+	if !ok {
+		return PeerStatus{}, false
+	}
+	return h.withLive(ps), true
+}
+
+// HasCommittedHeader reports whether p is live
+// and its latest heartbeat shows it voting beyond height,
+// so that it can serve the committed header at height.
+// HasCommittedHeader may be called on a nil Heartbeater, always returning false.
+func (h *Heartbeater) HasCommittedHeader(p libp2ppeer.ID, height uint64) bool {
+	if h == nil {
+		return false
+	}
+
+	ps, ok := h.Peer(p)
+	return ok && ps.Live && ps.Height > height
+}
+
+// withLive sets ps.Live according to the current time.
+func (h *Heartbeater) withLive(ps PeerStatus) PeerStatus {
+	ps.Live = time.Since(ps.LastSeen) <= liveIntervals*h.interval
+	return ps
+}
+
+func (h *Heartbeater) kernel(ctx context.Context) {
+	defer close(h.done)
+	defer h.host.RemoveStreamHandler(ProtocolID)
+
+	ctx, task := trace.NewTask(ctx, "Heartbeater.kernel")
+	defer task.End()
+
+	t := time.NewTicker(h.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case <-t.C:
+			h.sendAll(ctx)
+		}
+	}
+}
+
+// sendAll sends the current status to every connected peer concurrently,
+// returning once every send has completed.
+func (h *Heartbeater) sendAll(ctx context.Context) {
+	b, err := json.Marshal(h.status())
+	if err != nil {
+		h.log.Warn("Failed to marshal heartbeat", "err", err)
+		return
+	}
+
+	peers := h.host.Network().Peers()
+
+	var wg sync.WaitGroup
+	wg.Add(len(peers))
+	for _, p := range peers {
+		go func() {
+			defer wg.Done()
+			h.send(ctx, p, b)
+		}()
+	}
+	wg.Wait()
+
+	// Forget peers that are no longer connected.
+	h.mu.Lock()
+	for p := range h.peers {
+		if !slices.Contains(peers, p) {
+			delete(h.peers, p)
+		}
+	}
+	h.mu.Unlock()
+}
+
+func (h *Heartbeater) send(ctx context.Context, p libp2ppeer.ID, b []byte) {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	s, err := h.host.NewStream(ctx, p, ProtocolID)
+	if err != nil {
+		// Most likely a peer that does not support heartbeats.
+		h.log.Debug("Failed to open heartbeat stream to peer", "peer_id", p, "err", err)
+		return
+	}
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(sendTimeout))
+
+	if _, err := s.Write(b); err != nil {
+		h.log.Debug("Failed to send heartbeat to peer", "peer_id", p, "err", err)
+	}
+}
+
+func (h *Heartbeater) handleStream(s libp2pnetwork.Stream) {
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(sendTimeout))
+
+	var st Status
+	if err := json.NewDecoder(io.LimitReader(s, maxStatusSize)).Decode(&st); err != nil {
+		return
+	}
+
+	p := s.Conn().RemotePeer()
+
+	h.mu.Lock()
+	h.peers[p] = PeerStatus{
+		Peer:     p.String(),
+		Status:   st,
+		LastSeen: time.Now(),
+	}
+	h.mu.Unlock()
+
+	if h.onStatus != nil {
+		h.onStatus(p, st)
+	}
+}
@@ -0,0 +1,92 @@
+package gliveness_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gliveness"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gready"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p/tmlibp2ptest"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeater(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	log := gtest.NewLogger(t)
+	net, err := tmlibp2ptest.NewNetwork(ctx, log.With("sys", "net"), tmjson.MarshalCodec{CryptoRegistry: reg})
+	require.NoError(t, err)
+	defer net.Wait()
+	defer cancel()
+
+	c1, err := net.Connect(ctx)
+	require.NoError(t, err)
+	c2, err := net.Connect(ctx)
+	require.NoError(t, err)
+	require.NoError(t, net.Stabilize(ctx))
+
+	st1 := gliveness.Status{
+		Height: 5, Round: 1, Step: gliveness.StepFinalizing, FinalizedHeight: 3,
+		Readiness: &gready.Signal{
+			PubKey: []byte("pub"), Height: 5, Features: []string{"v2"}, Signature: []byte("sig"),
+		},
+	}
+	h1 := gliveness.NewHeartbeater(ctx, log.With("node", 1), gliveness.HeartbeaterConfig{
+		Host:     c1.Host().Libp2pHost(),
+		Interval: 10 * time.Millisecond,
+		Status: func() gliveness.Status {
+			return st1
+		},
+	})
+	defer h1.Wait()
+	defer cancel()
+
+	var received atomic.Pointer[gliveness.Status]
+	h2 := gliveness.NewHeartbeater(ctx, log.With("node", 2), gliveness.HeartbeaterConfig{
+		Host:     c2.Host().Libp2pHost(),
+		Interval: 10 * time.Millisecond,
+		Status: func() gliveness.Status {
+			return gliveness.Status{Height: 4, Step: gliveness.StepVoting}
+		},
+		OnStatus: func(_ libp2ppeer.ID, st gliveness.Status) {
+			received.Store(&st)
+		},
+	})
+	defer h2.Wait()
+	defer cancel()
+
+	require.Eventually(t, func() bool {
+		return len(h1.Peers()) == 1 && len(h2.Peers()) == 1 && received.Load() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	p1 := c1.Host().Libp2pHost().ID()
+	ps, ok := h2.Peer(p1)
+	require.True(t, ok)
+	require.True(t, ps.Live)
+	require.Equal(t, p1.String(), ps.Peer)
+	require.Equal(t, st1, ps.Status)
+	require.Equal(t, st1, *received.Load())
+
+	// Voting at height 5 means the peer has committed headers through height 4.
+	require.True(t, h2.HasCommittedHeader(p1, 4))
+	require.False(t, h2.HasCommittedHeader(p1, 5))
+
+	p2 := c2.Host().Libp2pHost().ID()
+	require.Equal(t, p2.String(), h1.Peers()[0].Peer)
+	require.Equal(t, uint64(4), h1.Peers()[0].Height)
+
+	var nilH *gliveness.Heartbeater
+	require.False(t, nilH.HasCommittedHeader(p1, 1))
+}
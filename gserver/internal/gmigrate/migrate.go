@@ -0,0 +1,163 @@
+// Package gmigrate versions the layout of a node's data directory
+// and upgrades old layouts at startup.
+//
+// The version is recorded in a marker file in the data directory.
+// A data directory without a marker predates versioning and is treated as version 0.
+// Each [Migration] upgrades the directory by exactly one version,
+// and the marker is rewritten after every successful migration,
+// so an interrupted upgrade resumes from the last completed step.
+package gmigrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MarkerFile is the name of the version marker within the data directory.
+const MarkerFile = "gcosmos_data_version.json"
+
+// Migration upgrades a data directory from version To-1 to version To.
+type Migration struct {
+	To int
+
+	// Short description, included in logs and error messages.
+	Description string
+
+	// Apply performs the migration on the given data directory.
+	// It must be safe to re-run after a partial failure.
+	Apply func(ctx context.Context, log *slog.Logger, dataDir string) error
+}
+
+// Mode controls whether [Run] applies pending migrations.
+type Mode string
+
+const (
+	// ModeAuto applies pending migrations.
+	ModeAuto Mode = "auto"
+
+	// ModeRefuse fails if any migration is pending,
+	// so that an operator can back up the data directory first.
+	ModeRefuse Mode = "refuse"
+)
+
+// ParseMode parses the string form of a [Mode].
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case ModeAuto, ModeRefuse:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("unknown migration mode %q (expected %q or %q)", s, ModeAuto, ModeRefuse)
+	}
+}
+
+// marker is the JSON content of the marker file.
+type marker struct {
+	Version int
+	Updated time.Time
+}
+
+// ReadVersion returns the version recorded in dataDir,
+// or zero if there is no marker file.
+func ReadVersion(dataDir string) (int, error) {
+	b, err := os.ReadFile(filepath.Join(dataDir, MarkerFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read data version marker: %w", err)
+	}
+
+	var m marker
+	if err := json.Unmarshal(b, &m); err != nil {
+		return 0, fmt.Errorf("failed to parse data version marker: %w", err)
+	}
+	if m.Version < 0 {
+		return 0, fmt.Errorf("invalid data version %d in marker", m.Version)
+	}
+	return m.Version, nil
+}
+
+// writeVersion atomically replaces the marker in dataDir.
+func writeVersion(dataDir string, v int) error {
+	b, err := json.Marshal(marker{Version: v, Updated: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dataDir, MarkerFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write data version marker: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dataDir, MarkerFile)); err != nil {
+		return fmt.Errorf("failed to replace data version marker: %w", err)
+	}
+	return nil
+}
+
+// Run brings dataDir up to the version of the last migration.
+// migrations must be ordered, with To values counting up from 1.
+//
+// Run fails without changes if dataDir has a newer version than any migration,
+// as that layout was written by a newer release.
+func Run(ctx context.Context, log *slog.Logger, dataDir string, mode Mode, migrations []Migration) error {
+	for i, m := range migrations {
+		if m.To != i+1 {
+			return fmt.Errorf("BUG: migration %d has To=%d", i, m.To)
+		}
+	}
+	latest := len(migrations)
+
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	cur, err := ReadVersion(dataDir)
+	if err != nil {
+		return err
+	}
+
+	if cur > latest {
+		return fmt.Errorf(
+			"data directory %s has layout version %d, but this release only supports up to version %d; "+
+				"run a newer release, or restore a backup made before the upgrade",
+			dataDir, cur, latest,
+		)
+	}
+	if cur == latest {
+		log.Debug("Data directory layout is current", "version", cur)
+		return nil
+	}
+
+	pending := migrations[cur:]
+	if mode == ModeRefuse {
+		descs := make([]string, len(pending))
+		for i, m := range pending {
+			descs[i] = fmt.Sprintf("%d: %s", m.To, m.Description)
+		}
+		return fmt.Errorf(
+			"data directory %s has layout version %d and needs migrations (%s); "+
+				"back up the directory and restart with migrations enabled",
+			dataDir, cur, strings.Join(descs, "; "),
+		)
+	}
+
+	for _, m := range pending {
+		log.Info("Migrating data directory", "from", m.To-1, "to", m.To, "desc", m.Description)
+		start := time.Now()
+		if err := m.Apply(ctx, log.With("migration", m.To), dataDir); err != nil {
+			return fmt.Errorf("data migration to version %d (%s) failed: %w", m.To, m.Description, err)
+		}
+		if err := writeVersion(dataDir, m.To); err != nil {
+			return err
+		}
+		log.Info("Migrated data directory", "version", m.To, "dur", time.Since(start))
+	}
+	return nil
+}
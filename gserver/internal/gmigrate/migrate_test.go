@@ -0,0 +1,93 @@
+package gmigrate_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gmigrate"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func recording(applied *[]int) []gmigrate.Migration {
+	ms := make([]gmigrate.Migration, 3)
+	for i := range ms {
+		ms[i] = gmigrate.Migration{
+			To:          i + 1,
+			Description: "test",
+			Apply: func(context.Context, *slog.Logger, string) error {
+				*applied = append(*applied, i+1)
+				return nil
+			},
+		}
+	}
+	return ms
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var applied []int
+	ms := recording(&applied)
+
+	// Only the first two migrations exist in the older release.
+	require.NoError(t, gmigrate.Run(context.Background(), gtest.NewLogger(t), dir, gmigrate.ModeAuto, ms[:2]))
+	require.Equal(t, []int{1, 2}, applied)
+
+	v, err := gmigrate.ReadVersion(dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+
+	// Upgrading only applies the new migration.
+	require.NoError(t, gmigrate.Run(context.Background(), gtest.NewLogger(t), dir, gmigrate.ModeAuto, ms))
+	require.Equal(t, []int{1, 2, 3}, applied)
+
+	// Already current.
+	require.NoError(t, gmigrate.Run(context.Background(), gtest.NewLogger(t), dir, gmigrate.ModeAuto, ms))
+	require.Equal(t, []int{1, 2, 3}, applied)
+
+	// A downgrade is refused.
+	err = gmigrate.Run(context.Background(), gtest.NewLogger(t), dir, gmigrate.ModeAuto, ms[:1])
+	require.ErrorContains(t, err, "only supports up to version 1")
+}
+
+func TestRun_refuse(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var applied []int
+	err := gmigrate.Run(context.Background(), gtest.NewLogger(t), dir, gmigrate.ModeRefuse, recording(&applied))
+	require.ErrorContains(t, err, "needs migrations")
+	require.Empty(t, applied)
+
+	v, err := gmigrate.ReadVersion(dir)
+	require.NoError(t, err)
+	require.Zero(t, v)
+}
+
+func TestRun_failureResumes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var applied []int
+	ms := recording(&applied)
+
+	fail := errors.New("disk full")
+	orig := ms[1].Apply
+	ms[1].Apply = func(context.Context, *slog.Logger, string) error { return fail }
+
+	err := gmigrate.Run(context.Background(), gtest.NewLogger(t), dir, gmigrate.ModeAuto, ms)
+	require.ErrorIs(t, err, fail)
+	require.Equal(t, []int{1}, applied)
+
+	v, err := gmigrate.ReadVersion(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	ms[1].Apply = orig
+	require.NoError(t, gmigrate.Run(context.Background(), gtest.NewLogger(t), dir, gmigrate.ModeAuto, ms))
+	require.Equal(t, []int{1, 2, 3}, applied)
+}
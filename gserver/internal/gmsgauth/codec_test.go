@@ -0,0 +1,110 @@
+package gmsgauth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gmsgauth"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func newJSONCodec() tmjson.MarshalCodec {
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+	return tmjson.MarshalCodec{CryptoRegistry: &reg}
+}
+
+func newMessage(t *testing.T) tmcodec.ConsensusMessage {
+	t.Helper()
+
+	fx := tmconsensustest.NewStandardFixture(2)
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	fx.SignProposal(context.Background(), &ph, 0)
+	return tmcodec.ConsensusMessage{ProposedHeader: &ph}
+}
+
+func TestCodec_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	priv, _, err := libp2pcrypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	wantOrigin, err := libp2ppeer.IDFromPrivateKey(priv)
+	require.NoError(t, err)
+
+	sender, err := gmsgauth.NewCodec(gmsgauth.CodecConfig{
+		Inner:    newJSONCodec(),
+		Identity: priv,
+	})
+	require.NoError(t, err)
+
+	var gotOrigin libp2ppeer.ID
+	receiver, err := gmsgauth.NewCodec(gmsgauth.CodecConfig{
+		Inner:             newJSONCodec(),
+		RequireSignatures: true,
+		OnVerified: func(origin libp2ppeer.ID, _ tmcodec.ConsensusMessage) {
+			gotOrigin = origin
+		},
+	})
+	require.NoError(t, err)
+
+	cm := newMessage(t)
+	b, err := sender.MarshalConsensusMessage(cm)
+	require.NoError(t, err)
+
+	var got tmcodec.ConsensusMessage
+	require.NoError(t, receiver.UnmarshalConsensusMessage(b, &got))
+	require.Equal(t, cm.ProposedHeader.Header.Hash, got.ProposedHeader.Header.Hash)
+	require.Equal(t, wantOrigin, gotOrigin)
+
+	// Tampering with the payload invalidates the signature.
+	b[len(b)-2] ^= 1
+	var invalidErr error
+	receiver, err = gmsgauth.NewCodec(gmsgauth.CodecConfig{
+		Inner:     newJSONCodec(),
+		OnInvalid: func(err error, _ []byte) { invalidErr = err },
+	})
+	require.NoError(t, err)
+	err = receiver.UnmarshalConsensusMessage(b, &got)
+	require.ErrorAs(t, err, new(gmsgauth.InvalidSignatureError))
+	require.Equal(t, err, invalidErr)
+	require.Equal(t, wantOrigin, err.(gmsgauth.InvalidSignatureError).Origin)
+}
+
+func TestCodec_unsigned(t *testing.T) {
+	t.Parallel()
+
+	cm := newMessage(t)
+	b, err := newJSONCodec().MarshalConsensusMessage(cm)
+	require.NoError(t, err)
+
+	optional, err := gmsgauth.NewCodec(gmsgauth.CodecConfig{Inner: newJSONCodec()})
+	require.NoError(t, err)
+	var got tmcodec.ConsensusMessage
+	require.NoError(t, optional.UnmarshalConsensusMessage(b, &got))
+	require.Equal(t, cm.ProposedHeader.Header.Hash, got.ProposedHeader.Header.Hash)
+
+	required, err := gmsgauth.NewCodec(gmsgauth.CodecConfig{
+		Inner:             newJSONCodec(),
+		RequireSignatures: true,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, required.UnmarshalConsensusMessage(b, &got), gmsgauth.ErrUnsigned)
+}
+
+func TestCodec_malformed(t *testing.T) {
+	t.Parallel()
+
+	c, err := gmsgauth.NewCodec(gmsgauth.CodecConfig{Inner: newJSONCodec()})
+	require.NoError(t, err)
+
+	var got tmcodec.ConsensusMessage
+	require.Error(t, c.UnmarshalConsensusMessage([]byte("\x00gcauth1\xff"), &got))
+	require.Error(t, c.UnmarshalConsensusMessage([]byte("\x00gcauth1\x05ab"), &got))
+}
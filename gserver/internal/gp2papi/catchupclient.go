@@ -13,11 +13,13 @@ import (
 	"time"
 
 	"cosmossdk.io/core/transaction"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
 	"github.com/gordian-engine/gcosmos/internal/copy/gchan"
 	"github.com/gordian-engine/gordian/tm/tmcodec"
 	"github.com/gordian-engine/gordian/tm/tmconsensus"
 	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/gordian-engine/gordian/tm/tmstore"
 	libp2phost "github.com/libp2p/go-libp2p/core/host"
 	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
 	libp2pprotocol "github.com/libp2p/go-libp2p/core/protocol"
@@ -30,6 +32,9 @@ type resumeFetchRequest struct {
 type pauseFetchRequest struct{}
 
 type nextPeerRequest struct {
+	// The height to be fetched from the peer.
+	Height uint64
+
 	Resp chan<- libp2ppeer.ID
 }
 
@@ -67,6 +72,10 @@ type CatchupClient struct {
 
 	rCache *gsbd.RequestCache
 
+	vs tmstore.ValidatorStore // May be nil.
+
+	peerHasHeader func(libp2ppeer.ID, uint64) bool // May be nil.
+
 	// Requests that originate externally (should be from the Driver specifically),
 	// via calling an exported method on CatchupClient.
 	resumeRequests      chan resumeFetchRequest
@@ -77,6 +86,7 @@ type CatchupClient struct {
 
 	// Where we send the committed headers that have fetched.
 	replayedHeaders chan<- tmelink.ReplayedHeaderRequest
+	replayedPath    *gbackpressure.Path
 
 	// Communication between the main loop and the fetch worker.
 	newFetchStateRequests chan newFetchStateRequest
@@ -105,6 +115,25 @@ type CatchupClientConfig struct {
 	// This same channel should be passed to the
 	// [tmengine.WithReplayedHeaderRequestChannel] option.
 	ReplayedHeadersOut chan<- tmelink.ReplayedHeaderRequest
+
+	// Buffer size of the peer add, remove, and exclude channels.
+	// Zero means 8.
+	PeerRequestBuffer int
+
+	// Optional path measuring how long the engine takes
+	// to accept and respond to each replayed header.
+	ReplayedHeadersPath *gbackpressure.Path
+
+	// The engine's validator store.
+	// If set, the client prefers the compact version 2 protocol,
+	// resolving the omitted validator sets from this store.
+	ValidatorStore tmstore.ValidatorStore
+
+	// Optional report of whether a peer is known to have
+	// the committed header at a height, such as from its heartbeats.
+	// If set, fetches prefer such peers,
+	// falling back to any peer when none are known to have the header.
+	PeerHasCommittedHeader func(p libp2ppeer.ID, height uint64) bool
 }
 
 func NewCatchupClient(
@@ -112,6 +141,11 @@ func NewCatchupClient(
 	log *slog.Logger,
 	cfg CatchupClientConfig,
 ) *CatchupClient {
+	peerBuf := cfg.PeerRequestBuffer
+	if peerBuf <= 0 {
+		peerBuf = 8
+	}
+
 	c := &CatchupClient{
 		log: log,
 
@@ -122,15 +156,19 @@ func NewCatchupClient(
 
 		rCache: cfg.RequestCache,
 
+		vs: cfg.ValidatorStore,
+
+		peerHasHeader: cfg.PeerHasCommittedHeader,
+
 		resumeRequests: make(chan resumeFetchRequest),
 		pauseRequests:  make(chan pauseFetchRequest),
 
-		// Arbitrarily sized.
-		addPeerRequests:     make(chan addPeerRequest, 8),
-		removePeerRequests:  make(chan removePeerRequest, 8),
-		excludePeerRequests: make(chan excludePeerRequest, 8),
+		addPeerRequests:     make(chan addPeerRequest, peerBuf),
+		removePeerRequests:  make(chan removePeerRequest, peerBuf),
+		excludePeerRequests: make(chan excludePeerRequest, peerBuf),
 
 		replayedHeaders: cfg.ReplayedHeadersOut,
+		replayedPath:    cfg.ReplayedHeadersPath,
 
 		// 1-buffered so we don't block the main loop on the first send.
 		newFetchStateRequests: make(chan newFetchStateRequest, 1),
@@ -207,12 +245,8 @@ func (c *CatchupClient) mainLoop(ctx context.Context) {
 				continue
 			}
 
-			// Rely on map iteration to send a peer at random.
-			for p := range peers {
-				// Response must be 1-buffered, having originated from the fetch worker goroutine.
-				req.Resp <- p
-				break
-			}
+			// Response must be 1-buffered, having originated from the fetch worker goroutine.
+			req.Resp <- c.choosePeer(peers, req.Height)
 
 		case req := <-c.addPeerRequests:
 			if _, ok := excludedPeers[req.P]; ok {
@@ -249,6 +283,23 @@ func (c *CatchupClient) mainLoop(ctx context.Context) {
 	}
 }
 
+// choosePeer returns a peer from the non-empty peers map
+// to fetch the given height from.
+// It prefers a peer known to have the header, if any;
+// otherwise it relies on map iteration to choose a peer at random.
+func (c *CatchupClient) choosePeer(peers map[libp2ppeer.ID]struct{}, height uint64) libp2ppeer.ID {
+	var fallback libp2ppeer.ID
+	for p := range peers {
+		if c.peerHasHeader == nil || c.peerHasHeader(p, height) {
+			return p
+		}
+		if fallback == "" {
+			fallback = p
+		}
+	}
+	return fallback
+}
+
 var errFetchPause = errors.New("fetches paused")
 
 // fetchWorker handles committed header and block data fetching on a dedicated goroutine.
@@ -279,7 +330,7 @@ func (c *CatchupClient) doFetches(ctx context.Context, start, stop uint64) {
 		respCh := make(chan libp2ppeer.ID, 1)
 		p, ok := gchan.ReqResp(
 			ctx, c.log,
-			c.nextPeerRequests, nextPeerRequest{Resp: respCh},
+			c.nextPeerRequests, nextPeerRequest{Height: height, Resp: respCh},
 			respCh,
 			"requesting next peer for fetch",
 		)
@@ -292,7 +343,7 @@ func (c *CatchupClient) doFetches(ctx context.Context, start, stop uint64) {
 			return
 		}
 
-		res := c.doFetch(ctx, height, p)
+		res := c.doFetch(ctx, height, p, c.vs != nil)
 		if res.ExcludePeer {
 			// This should be an exceptional case,
 			// so let's go ahead and do a blocking here.
@@ -338,7 +389,10 @@ var errFetchHeaderDeadlineExceeded = errors.New("deadline for retrieving header
 
 // doFetch executes a single committed header and block data fetch,
 // at the given height, from the given peer.
-func (c *CatchupClient) doFetch(ctx context.Context, height uint64, p libp2ppeer.ID) fetchResult {
+// doFetch fetches the full block at height from p.
+// If allowCompact is set, it prefers the compact version 2 protocol
+// when the peer supports it.
+func (c *CatchupClient) doFetch(ctx context.Context, height uint64, p libp2ppeer.ID, allowCompact bool) fetchResult {
 	defer trace.StartRegion(ctx, "doFetch").End()
 
 	const timeout = 2 * time.Second // Arbitrarily chosen.
@@ -349,9 +403,16 @@ func (c *CatchupClient) doFetch(ctx context.Context, height uint64, p libp2ppeer
 
 	// If we want to support a header-only CatchupClient,
 	// we would need to use headerV1HeightPrefix here.
-	s, err := c.host.NewStream(streamCtx, p, libp2pprotocol.ID(
-		fmt.Sprintf("%s%d", fullBlockV1HeightPrefix, height),
-	))
+	// Protocols are negotiated in order of preference.
+	v1ID := libp2pprotocol.ID(fmt.Sprintf("%s%d", fullBlockV1HeightPrefix, height))
+	pids := []libp2pprotocol.ID{v1ID}
+	if allowCompact {
+		pids = []libp2pprotocol.ID{
+			libp2pprotocol.ID(fmt.Sprintf("%s%d", fullBlockV2HeightPrefix, height)),
+			v1ID,
+		}
+	}
+	s, err := c.host.NewStream(streamCtx, p, pids...)
 	if err != nil {
 		c.log.Info("Failed to open stream to peer", "peer_id", p, "err", err)
 		return fetchResult{
@@ -359,6 +420,7 @@ func (c *CatchupClient) doFetch(ctx context.Context, height uint64, p libp2ppeer
 		}
 	}
 	defer s.Close()
+	compact := s.Protocol() != v1ID
 
 	// We have a stream to the right protocol, let's parse the result.
 	// Arbitrary limit on header size.
@@ -433,6 +495,20 @@ func (c *CatchupClient) doFetch(ctx context.Context, height uint64, p libp2ppeer
 		}
 	}
 
+	if compact {
+		if err := resolveValidators(ctx, c.vs, &ch.Header); err != nil {
+			// Expected when the validator set changes,
+			// as we will not have seen the new set yet.
+			c.log.Debug(
+				"Failed to resolve validators for compact header; retrying with full header",
+				"peer_id", p,
+				"height", height,
+				"err", err,
+			)
+			return c.doFetch(ctx, height, p, false)
+		}
+	}
+
 	// Confirm that the block data is appropriate for the header.
 	if gsbd.IsZeroTxDataID(string(ch.Header.DataID)) {
 		if len(fbr.BlockData) > 0 {
@@ -490,7 +566,8 @@ func (c *CatchupClient) doFetch(ctx context.Context, height uint64, p libp2ppeer
 
 		// Since we have the block data and it matches the header's data ID,
 		// we can set it in the request cache as completed.
-		c.rCache.SetImmediatelyAvailable(string(ch.Header.DataID), txs, fbr.BlockData)
+		// The block is committed, so nothing would fetch the data again if it were evicted.
+		c.rCache.SetCommitted(string(ch.Header.DataID), txs, fbr.BlockData)
 	}
 
 	// Now we have a committed header, so we have to send it to the engine.
@@ -500,12 +577,14 @@ func (c *CatchupClient) doFetch(ctx context.Context, height uint64, p libp2ppeer
 		Proof:  ch.Proof,
 		Resp:   respCh,
 	}
+	end := c.replayedPath.Begin()
 	resp, ok := gchan.ReqResp(
 		ctx, c.log,
 		c.replayedHeaders, req,
 		respCh,
 		"sending replayed header to engine",
 	)
+	end()
 	if !ok {
 		// Context was cancelled, so result is meaningless here.
 		return fetchResult{}
@@ -580,3 +659,20 @@ func (c *CatchupClient) RemovePeer(ctx context.Context, p libp2ppeer.ID) (ok boo
 		"making remove peer request",
 	)
 }
+
+// resolveValidators fills in the validator slices omitted from h
+// by the version 2 protocols, loading them from vs by their hashes.
+func resolveValidators(ctx context.Context, vs tmstore.ValidatorStore, h *tmconsensus.Header) error {
+	for _, set := range []*tmconsensus.ValidatorSet{&h.ValidatorSet, &h.NextValidatorSet} {
+		if len(set.Validators) > 0 {
+			continue
+		}
+
+		vals, err := vs.LoadValidators(ctx, string(set.PubKeyHash), string(set.VotePowerHash))
+		if err != nil {
+			return fmt.Errorf("failed to load validators for hashes %x/%x: %w", set.PubKeyHash, set.VotePowerHash, err)
+		}
+		set.Validators = vals
+	}
+	return nil
+}
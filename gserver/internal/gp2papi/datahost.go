@@ -49,6 +49,15 @@ const (
 
 	// The committed header with the block data.
 	fullBlockV1HeightPrefix = "/gcosmos/full_blocks/v1/height/"
+
+	// Version 2 of the above protocols omits the validators
+	// from the header's ValidatorSet and NextValidatorSet,
+	// leaving only the set hashes.
+	// The validator slices are usually the bulk of an encoded header,
+	// and the client usually has the sets in its validator store already;
+	// if not, it can fall back to version 1 for that height.
+	headerV2HeightPrefix    = "/gcosmos/committed_headers/v2/height/"
+	fullBlockV2HeightPrefix = "/gcosmos/full_blocks/v2/height/"
 )
 
 func NewDataHost(
@@ -72,51 +81,20 @@ func NewDataHost(
 
 	go h.waitForCancellation()
 
-	h.host.SetStreamHandlerMatch(
-		libp2pprotocol.ID(headerV1HeightPrefix),
-		func(id libp2pprotocol.ID) bool {
-			heightS, ok := strings.CutPrefix(string(id), headerV1HeightPrefix)
-			if !ok {
-				return false
-			}
-
-			if len(heightS) == 0 {
-				return false
-			}
-
-			for _, rn := range heightS {
-				if rn < '0' || rn > '9' {
-					return false
-				}
-			}
-
-			return true
-		},
-		h.handleCommittedHeaderStream,
-	)
-
-	h.host.SetStreamHandlerMatch(
-		libp2pprotocol.ID(fullBlockV1HeightPrefix),
-		func(id libp2pprotocol.ID) bool {
-			heightS, ok := strings.CutPrefix(string(id), fullBlockV1HeightPrefix)
-			if !ok {
-				return false
-			}
-
-			if len(heightS) == 0 {
-				return false
-			}
-
-			for _, rn := range heightS {
-				if rn < '0' || rn > '9' {
-					return false
-				}
-			}
-
-			return true
-		},
-		h.handleFullBlockStream,
-	)
+	for _, prefix := range []string{headerV1HeightPrefix, headerV2HeightPrefix} {
+		h.host.SetStreamHandlerMatch(
+			libp2pprotocol.ID(prefix),
+			heightProtocolMatcher(prefix),
+			h.handleCommittedHeaderStream,
+		)
+	}
+	for _, prefix := range []string{fullBlockV1HeightPrefix, fullBlockV2HeightPrefix} {
+		h.host.SetStreamHandlerMatch(
+			libp2pprotocol.ID(prefix),
+			heightProtocolMatcher(prefix),
+			h.handleFullBlockStream,
+		)
+	}
 
 	return h
 }
@@ -130,10 +108,57 @@ func (h *DataHost) waitForCancellation() {
 	// but this would allow us to dynamically enable the service
 	// without shutting down the whole process.
 	<-h.ctx.Done()
-	h.host.RemoveStreamHandler(libp2pprotocol.ID(headerV1HeightPrefix))
+	for _, prefix := range []string{
+		headerV1HeightPrefix, headerV2HeightPrefix,
+		fullBlockV1HeightPrefix, fullBlockV2HeightPrefix,
+	} {
+		h.host.RemoveStreamHandler(libp2pprotocol.ID(prefix))
+	}
 	close(h.done)
 }
 
+// heightProtocolMatcher returns a protocol matcher
+// for prefix followed by a decimal height.
+func heightProtocolMatcher(prefix string) func(libp2pprotocol.ID) bool {
+	return func(id libp2pprotocol.ID) bool {
+		heightS, ok := strings.CutPrefix(string(id), prefix)
+		if !ok {
+			return false
+		}
+
+		if len(heightS) == 0 {
+			return false
+		}
+
+		for _, rn := range heightS {
+			if rn < '0' || rn > '9' {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// cutHeightProtocol returns the height string following
+// the version 1 or version 2 prefix in id,
+// and whether it was the compact version 2.
+func cutHeightProtocol(id libp2pprotocol.ID, v1Prefix, v2Prefix string) (heightS string, compact, ok bool) {
+	if heightS, ok := strings.CutPrefix(string(id), v2Prefix); ok {
+		return heightS, true, true
+	}
+	heightS, ok = strings.CutPrefix(string(id), v1Prefix)
+	return heightS, false, ok
+}
+
+// stripValidators returns ch without the validator slices in its header,
+// for the version 2 protocols.
+func stripValidators(ch tmconsensus.CommittedHeader) tmconsensus.CommittedHeader {
+	ch.Header.ValidatorSet.Validators = nil
+	ch.Header.NextValidatorSet.Validators = nil
+	return ch
+}
+
 func (h *DataHost) handleCommittedHeaderStream(s libp2pnetwork.Stream) {
 	// TODO: this is unfortunate that we need to distinguish errors from actual results
 	// and we are stuck with JSON for now.
@@ -154,7 +179,7 @@ func (h *DataHost) handleCommittedHeaderStream(s libp2pnetwork.Stream) {
 	ctx, cancel := context.WithTimeout(h.ctx, time.Second)
 	defer cancel()
 
-	heightS, ok := strings.CutPrefix(string(s.Protocol()), headerV1HeightPrefix)
+	heightS, compact, ok := cutHeightProtocol(s.Protocol(), headerV1HeightPrefix, headerV2HeightPrefix)
 	if !ok {
 		_ = json.NewEncoder(s).Encode(JSONResult{
 			Err: "invalid protocol",
@@ -185,6 +210,10 @@ func (h *DataHost) handleCommittedHeaderStream(s libp2pnetwork.Stream) {
 		return
 	}
 
+	if compact {
+		ch = stripValidators(ch)
+	}
+
 	b, err := h.codec.MarshalCommittedHeader(ch)
 	if err != nil {
 		h.log.Info(
@@ -223,7 +252,7 @@ func (h *DataHost) handleFullBlockStream(s libp2pnetwork.Stream) {
 	ctx, cancel := context.WithTimeout(h.ctx, time.Second)
 	defer cancel()
 
-	heightS, ok := strings.CutPrefix(string(s.Protocol()), fullBlockV1HeightPrefix)
+	heightS, compact, ok := cutHeightProtocol(s.Protocol(), fullBlockV1HeightPrefix, fullBlockV2HeightPrefix)
 	if !ok {
 		_ = json.NewEncoder(s).Encode(JSONResult{
 			Err: "invalid protocol",
@@ -254,6 +283,10 @@ func (h *DataHost) handleFullBlockStream(s libp2pnetwork.Stream) {
 		return
 	}
 
+	if compact {
+		ch = stripValidators(ch)
+	}
+
 	b, err := h.codec.MarshalCommittedHeader(ch)
 	if err != nil {
 		h.log.Info(
@@ -214,3 +214,65 @@ func TestDataHost_fullBlock(t *testing.T) {
 		require.Contains(t, jr.Err, "height unknown")
 	})
 }
+
+func TestDataHost_serveCommittedHeader_compact(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dhfx := NewFixture(t, ctx)
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	ph1 := fx.NextProposedHeader([]byte("app_data_1"), 0)
+	fx.SignProposal(ctx, &ph1, 0)
+
+	precommitProofs := fx.PrecommitProofMap(ctx, 1, 0, map[string][]int{
+		string(ph1.Header.Hash): {0, 1, 2},
+		"":                      {3},
+	})
+	fx.CommitBlock(ph1.Header, []byte("app_state_1"), 0, precommitProofs)
+	nextPH := fx.NextProposedHeader([]byte("whatever"), 0)
+
+	require.NoError(t, dhfx.CommittedHeaderStore.SaveCommittedHeader(ctx, tmconsensus.CommittedHeader{
+		Header: ph1.Header,
+		Proof:  nextPH.Header.PrevCommitProof,
+	}))
+
+	hostInfo := libp2phost.InfoFromHost(dhfx.P2PHostConn.Host().Libp2pHost())
+	require.NoError(t, dhfx.P2PClientConn.Host().Libp2pHost().Connect(ctx, *hostInfo))
+
+	fetch := func(protocol string) []byte {
+		s, err := dhfx.P2PClientConn.Host().Libp2pHost().NewStream(ctx, hostInfo.ID, libp2pprotocol.ID(protocol))
+		require.NoError(t, err)
+		defer s.Close()
+
+		b, err := io.ReadAll(s)
+		require.NoError(t, err)
+		return b
+	}
+
+	full := fetch("/gcosmos/committed_headers/v1/height/1")
+	compact := fetch("/gcosmos/committed_headers/v2/height/1")
+	require.Less(t, len(compact), len(full))
+
+	var jr gp2papi.JSONResult
+	require.NoError(t, json.Unmarshal(compact, &jr))
+	require.Empty(t, jr.Err)
+
+	var ch tmconsensus.CommittedHeader
+	require.NoError(t, dhfx.Codec.UnmarshalCommittedHeader(jr.Result, &ch))
+
+	// Only the validator slices are omitted.
+	require.Empty(t, ch.Header.ValidatorSet.Validators)
+	require.Empty(t, ch.Header.NextValidatorSet.Validators)
+	require.Equal(t, ph1.Header.ValidatorSet.PubKeyHash, ch.Header.ValidatorSet.PubKeyHash)
+	require.Equal(t, ph1.Header.ValidatorSet.VotePowerHash, ch.Header.ValidatorSet.VotePowerHash)
+
+	ch.Header.ValidatorSet.Validators = ph1.Header.ValidatorSet.Validators
+	ch.Header.NextValidatorSet.Validators = ph1.Header.NextValidatorSet.Validators
+	require.Equal(t, tmconsensus.CommittedHeader{
+		Header: ph1.Header,
+		Proof:  nextPH.Header.PrevCommitProof,
+	}, ch)
+}
@@ -70,7 +70,7 @@ func NewFixture(t *testing.T, ctx context.Context) *Fixture {
 		CommittedHeaderStore: chs,
 		BlockDataStore:       bds,
 
-		Cache: gsbd.NewRequestCache(),
+		Cache: gsbd.NewRequestCache(gsbd.RequestCacheConfig{}),
 
 		DataHost: dh,
 
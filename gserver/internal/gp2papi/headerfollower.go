@@ -0,0 +1,396 @@
+package gp2papi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gcosmos/internal/copy/glog"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+	libp2pprotocol "github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// HeaderFollower fetches committed headers from peers,
+// without their block data,
+// and saves each header to a store once its commit proof is verified.
+//
+// It is intended for nodes that only need to track the chain,
+// such as bridges and monitoring agents,
+// and that never execute blocks.
+type HeaderFollower struct {
+	log *slog.Logger
+
+	host        libp2phost.Host
+	unmarshaler tmcodec.Unmarshaler
+
+	hs   tmconsensus.HashScheme
+	ss   tmconsensus.SignatureScheme
+	cmsp gcrypto.CommonMessageSignatureProofScheme
+
+	store tmstore.CommittedHeaderStore
+
+	initialHeight uint64
+	trustedHash   []byte
+
+	pollInterval time.Duration
+
+	mu     sync.RWMutex
+	latest tmconsensus.Header
+
+	done chan struct{}
+}
+
+// HeaderFollowerConfig is the configuration for a [HeaderFollower].
+type HeaderFollowerConfig struct {
+	// The host from which we will open libp2p streams to other hosts.
+	Host libp2phost.Host
+
+	// How to unmarshal the committed headers.
+	Unmarshaler tmcodec.Unmarshaler
+
+	// Schemes matching those of the chain being followed.
+	HashScheme                        tmconsensus.HashScheme
+	SignatureScheme                   tmconsensus.SignatureScheme
+	CommonMessageSignatureProofScheme gcrypto.CommonMessageSignatureProofScheme
+
+	// Where to save verified headers.
+	// On startup, the follower resumes after the highest header in the store.
+	Store tmstore.CommittedHeaderStore
+
+	// The first height of the chain.
+	InitialHeight uint64
+
+	// The expected hash of the header at InitialHeight.
+	// Every later header is verified through its predecessor's next validator set,
+	// so this hash is the root of trust for the follower.
+	// If nil, the first header received from any peer is trusted.
+	TrustedInitialHash []byte
+
+	// How long to wait before asking again
+	// when peers do not yet have the next height.
+	// If zero, one second is used.
+	PollInterval time.Duration
+}
+
+func NewHeaderFollower(
+	ctx context.Context,
+	log *slog.Logger,
+	cfg HeaderFollowerConfig,
+) *HeaderFollower {
+	f := &HeaderFollower{
+		log: log,
+
+		host:        cfg.Host,
+		unmarshaler: cfg.Unmarshaler,
+
+		hs:   cfg.HashScheme,
+		ss:   cfg.SignatureScheme,
+		cmsp: cfg.CommonMessageSignatureProofScheme,
+
+		store: cfg.Store,
+
+		initialHeight: cfg.InitialHeight,
+		trustedHash:   cfg.TrustedInitialHash,
+
+		pollInterval: cfg.PollInterval,
+
+		done: make(chan struct{}),
+	}
+
+	if f.pollInterval <= 0 {
+		f.pollInterval = time.Second
+	}
+
+	if f.trustedHash == nil {
+		log.Warn(
+			"No trusted initial header hash configured; trusting the first header received from any peer",
+			"initial_height", f.initialHeight,
+		)
+	}
+
+	go f.mainLoop(ctx)
+
+	return f
+}
+
+// Wait blocks until f's background work has finished,
+// after the context passed to [NewHeaderFollower] is canceled.
+func (f *HeaderFollower) Wait() {
+	<-f.done
+}
+
+// LatestHeader returns the highest verified header,
+// or the zero header if no header has been verified yet.
+func (f *HeaderFollower) LatestHeader() tmconsensus.Header {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.latest
+}
+
+func (f *HeaderFollower) mainLoop(ctx context.Context) {
+	defer close(f.done)
+
+	prev, err := f.resume(ctx)
+	if err != nil {
+		f.log.Warn("Failed to determine resume height for header follower", "err", err)
+		return
+	}
+
+	height := f.initialHeight
+	if prev != nil {
+		height = prev.Height + 1
+
+		f.mu.Lock()
+		f.latest = *prev
+		f.mu.Unlock()
+	}
+
+	var peerIdx int
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		peers := f.host.Network().Peers()
+		if len(peers) == 0 {
+			if !f.sleep(ctx) {
+				return
+			}
+			continue
+		}
+		p := peers[peerIdx%len(peers)]
+
+		ch, ok := f.fetch(ctx, height, p)
+		if !ok {
+			// Either the peer misbehaved or it does not have the height yet.
+			// Try the next peer, pausing once we have cycled through all of them.
+			peerIdx++
+			if peerIdx%len(peers) == 0 && !f.sleep(ctx) {
+				return
+			}
+			continue
+		}
+
+		if err := f.verify(ch, prev); err != nil {
+			f.log.Info(
+				"Rejecting unverifiable committed header from peer",
+				"peer_id", p,
+				"height", height,
+				"err", err,
+			)
+			peerIdx++
+			continue
+		}
+
+		if err := f.store.SaveCommittedHeader(ctx, ch); err != nil {
+			f.log.Warn("Failed to save verified committed header", "height", height, "err", err)
+			if !f.sleep(ctx) {
+				return
+			}
+			continue
+		}
+
+		f.log.Debug(
+			"Verified committed header",
+			"height", height,
+			"hash", glog.Hex(ch.Header.Hash),
+		)
+
+		f.mu.Lock()
+		f.latest = ch.Header
+		f.mu.Unlock()
+
+		prev = &ch.Header
+		height++
+	}
+}
+
+// resume returns the highest contiguous header already in the store,
+// or nil if the store does not have the initial height.
+func (f *HeaderFollower) resume(ctx context.Context) (*tmconsensus.Header, error) {
+	has := func(h uint64) (bool, error) {
+		_, err := f.store.LoadCommittedHeader(ctx, h)
+		if err == nil {
+			return true, nil
+		}
+		if errors.As(err, new(tmconsensus.HeightUnknownError)) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	ok, err := has(f.initialHeight)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// Headers are saved contiguously,
+	// so gallop to find an unknown height and then binary search back.
+	lo, step := f.initialHeight, uint64(1)
+	var hi uint64
+	for {
+		ok, err := has(lo + step)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			hi = lo + step
+			break
+		}
+		lo += step
+		step *= 2
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ok, err := has(mid)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	ch, err := f.store.LoadCommittedHeader(ctx, lo)
+	if err != nil {
+		return nil, err
+	}
+	return &ch.Header, nil
+}
+
+func (f *HeaderFollower) sleep(ctx context.Context) bool {
+	t := time.NewTimer(f.pollInterval)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// fetch requests the committed header at height from p,
+// reporting false if the header could not be retrieved.
+func (f *HeaderFollower) fetch(
+	ctx context.Context, height uint64, p libp2ppeer.ID,
+) (tmconsensus.CommittedHeader, bool) {
+	const timeout = 2 * time.Second // Arbitrarily chosen.
+	streamCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	s, err := f.host.NewStream(streamCtx, p, libp2pprotocol.ID(
+		fmt.Sprintf("%s%d", headerV1HeightPrefix, height),
+	))
+	if err != nil {
+		f.log.Debug("Failed to open header stream to peer", "peer_id", p, "err", err)
+		return tmconsensus.CommittedHeader{}, false
+	}
+	defer s.Close()
+
+	// Arbitrary limit on header size, matching the CatchupClient.
+	var res JSONResult
+	err = json.NewDecoder(io.LimitReader(s, 16*1024)).Decode(&res)
+	_ = s.Close()
+	if err != nil {
+		f.log.Debug("Failed to parse header stream response from peer", "peer_id", p, "err", err)
+		return tmconsensus.CommittedHeader{}, false
+	}
+
+	if res.Err != "" {
+		if res.Err != "height unknown" {
+			f.log.Debug(
+				"Got error response from peer",
+				"peer_id", p,
+				"height", height,
+				"err", res.Err,
+			)
+		}
+		return tmconsensus.CommittedHeader{}, false
+	}
+
+	var ch tmconsensus.CommittedHeader
+	if err := f.unmarshaler.UnmarshalCommittedHeader(res.Result, &ch); err != nil {
+		f.log.Debug("Failed to unmarshal committed header from peer", "peer_id", p, "err", err)
+		return tmconsensus.CommittedHeader{}, false
+	}
+
+	if ch.Header.Height != height {
+		f.log.Debug(
+			"Peer returned header for wrong height",
+			"peer_id", p,
+			"want", height, "got", ch.Header.Height,
+		)
+		return tmconsensus.CommittedHeader{}, false
+	}
+
+	return ch, true
+}
+
+// verify reports an error if ch is not a valid successor to prev,
+// or if its commit proof does not have a majority of its validators' power.
+// If prev is nil, ch must be the initial header.
+func (f *HeaderFollower) verify(ch tmconsensus.CommittedHeader, prev *tmconsensus.Header) error {
+	h := ch.Header
+
+	wantHash, err := f.hs.Block(h)
+	if err != nil {
+		return fmt.Errorf("failed to calculate block hash: %w", err)
+	}
+	if !bytes.Equal(wantHash, h.Hash) {
+		return fmt.Errorf("block hash mismatch: calculated %x, header claims %x", wantHash, h.Hash)
+	}
+
+	if err := f.verifyValidatorSet(h.ValidatorSet); err != nil {
+		return fmt.Errorf("invalid validator set: %w", err)
+	}
+	if err := f.verifyValidatorSet(h.NextValidatorSet); err != nil {
+		return fmt.Errorf("invalid next validator set: %w", err)
+	}
+
+	if prev == nil {
+		if f.trustedHash != nil && !bytes.Equal(f.trustedHash, h.Hash) {
+			return fmt.Errorf("initial header hash %x does not match trusted hash %x", h.Hash, f.trustedHash)
+		}
+	} else {
+		if !bytes.Equal(prev.Hash, h.PrevBlockHash) {
+			return fmt.Errorf("previous block hash mismatch: have %x, header claims %x", prev.Hash, h.PrevBlockHash)
+		}
+		if !prev.NextValidatorSet.Equal(h.ValidatorSet) {
+			return errors.New("validator set does not match previous header's next validator set")
+		}
+	}
+
+	return f.verifyCommitProof(h, ch.Proof)
+}
+
+// verifyValidatorSet reports an error if the hashes in vs
+// do not match its validators.
+func (f *HeaderFollower) verifyValidatorSet(vs tmconsensus.ValidatorSet) error {
+	want, err := tmconsensus.NewValidatorSet(vs.Validators, f.hs)
+	if err != nil {
+		return err
+	}
+	if !want.Equal(vs) {
+		return errors.New("validator hashes do not match validators")
+	}
+	return nil
+}
+
+func (f *HeaderFollower) verifyCommitProof(h tmconsensus.Header, p tmconsensus.CommitProof) error {
+	return gcverify.CommitProof(h.ValidatorSet, h.Height, h.Hash, p, f.ss, f.cmsp)
+}
@@ -0,0 +1,130 @@
+package gp2papi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gp2papi"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderFollower(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dhfx := NewFixture(t, ctx)
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	hs := commitHeaders(t, ctx, dhfx, fx, 3)
+
+	store := tmmemstore.NewCommittedHeaderStore()
+	f := gp2papi.NewHeaderFollower(ctx, gtest.NewLogger(t), gp2papi.HeaderFollowerConfig{
+		Host:        dhfx.P2PClientConn.Host().Libp2pHost(),
+		Unmarshaler: dhfx.Codec,
+
+		HashScheme:                        fx.HashScheme,
+		SignatureScheme:                   fx.SignatureScheme,
+		CommonMessageSignatureProofScheme: fx.CommonMessageSignatureProofScheme,
+
+		Store: store,
+
+		InitialHeight:      1,
+		TrustedInitialHash: hs[0].Hash,
+
+		PollInterval: 10 * time.Millisecond,
+	})
+	defer f.Wait()
+	defer cancel()
+
+	require.Eventually(t, func() bool {
+		return f.LatestHeader().Height == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, h := range hs {
+		ch, err := store.LoadCommittedHeader(ctx, h.Height)
+		require.NoError(t, err)
+		require.Equal(t, h.Hash, ch.Header.Hash)
+	}
+
+	// A later height is picked up once it is available.
+	hs = commitHeaders(t, ctx, dhfx, fx, 1)
+	require.Eventually(t, func() bool {
+		return f.LatestHeader().Height == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, hs[0].Hash, f.LatestHeader().Hash)
+}
+
+func TestHeaderFollower_untrustedInitialHeader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dhfx := NewFixture(t, ctx)
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	_ = commitHeaders(t, ctx, dhfx, fx, 1)
+
+	store := tmmemstore.NewCommittedHeaderStore()
+	f := gp2papi.NewHeaderFollower(ctx, gtest.NewLogger(t), gp2papi.HeaderFollowerConfig{
+		Host:        dhfx.P2PClientConn.Host().Libp2pHost(),
+		Unmarshaler: dhfx.Codec,
+
+		HashScheme:                        fx.HashScheme,
+		SignatureScheme:                   fx.SignatureScheme,
+		CommonMessageSignatureProofScheme: fx.CommonMessageSignatureProofScheme,
+
+		Store: store,
+
+		InitialHeight:      1,
+		TrustedInitialHash: []byte("some other hash"),
+
+		PollInterval: 10 * time.Millisecond,
+	})
+	defer f.Wait()
+	defer cancel()
+
+	// Give the follower a few attempts at the header.
+	time.Sleep(100 * time.Millisecond)
+
+	require.Zero(t, f.LatestHeader().Height)
+	_, err := store.LoadCommittedHeader(ctx, 1)
+	require.ErrorAs(t, err, new(tmconsensus.HeightUnknownError))
+}
+
+// commitHeaders commits the next n headers in fx,
+// saves them to dhfx's committed header store,
+// and returns the saved headers.
+func commitHeaders(
+	t *testing.T, ctx context.Context, dhfx *Fixture, fx *tmconsensustest.StandardFixture, n int,
+) []tmconsensus.Header {
+	t.Helper()
+
+	var hs []tmconsensus.Header
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	for range n {
+		precommitProofs := fx.PrecommitProofMap(ctx, ph.Header.Height, 0, map[string][]int{
+			string(ph.Header.Hash): {0, 1, 2},
+			"":                     {3},
+		})
+		fx.CommitBlock(ph.Header, []byte("app_state"), 0, precommitProofs)
+		nextPH := fx.NextProposedHeader([]byte("data"), 0)
+
+		require.NoError(t, dhfx.CommittedHeaderStore.SaveCommittedHeader(ctx, tmconsensus.CommittedHeader{
+			Header: ph.Header,
+			Proof:  nextPH.Header.PrevCommitProof,
+		}))
+
+		hs = append(hs, ph.Header)
+		ph = nextPH
+	}
+
+	return hs
+}
@@ -0,0 +1,119 @@
+package gp2papi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	libp2pevent "github.com/libp2p/go-libp2p/core/event"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+)
+
+// PeerBarrier reports when a libp2p host has connected to enough peers
+// that it is reasonable to begin consensus.
+//
+// Without a barrier, a freshly started network tends to burn through
+// the timeouts of its first rounds while the p2p mesh is still forming.
+type PeerBarrier struct {
+	log *slog.Logger
+
+	host     libp2phost.Host
+	minPeers int
+	timeout  time.Duration
+
+	ready chan struct{}
+	done  chan struct{}
+}
+
+// PeerBarrierConfig is the configuration for [NewPeerBarrier].
+type PeerBarrierConfig struct {
+	Host libp2phost.Host
+
+	// How many connected peers are required before the barrier is ready.
+	MinPeers int
+
+	// How long to wait for MinPeers before giving up
+	// and reporting ready anyway.
+	// If zero, the barrier waits indefinitely.
+	Timeout time.Duration
+}
+
+// NewPeerBarrier returns a new PeerBarrier
+// that begins watching cfg.Host's connections immediately.
+func NewPeerBarrier(ctx context.Context, log *slog.Logger, cfg PeerBarrierConfig) *PeerBarrier {
+	b := &PeerBarrier{
+		log: log,
+
+		host:     cfg.Host,
+		minPeers: cfg.MinPeers,
+		timeout:  cfg.Timeout,
+
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	go b.run(ctx)
+
+	return b
+}
+
+// Ready returns a channel that is closed once the host
+// has connected to the configured minimum number of peers,
+// or once the configured timeout has elapsed.
+// The channel is never closed if the barrier's context is canceled first.
+func (b *PeerBarrier) Ready() <-chan struct{} {
+	return b.ready
+}
+
+// Wait blocks until b's background goroutine has finished.
+func (b *PeerBarrier) Wait() {
+	<-b.done
+}
+
+func (b *PeerBarrier) run(ctx context.Context) {
+	defer close(b.done)
+
+	// Subscribe before checking the current peer count,
+	// so that we cannot miss a connection between the check and the subscription.
+	sub, err := b.host.EventBus().Subscribe(new(libp2pevent.EvtPeerConnectednessChanged))
+	if err != nil {
+		b.log.Warn(
+			"Failed to subscribe to peer connectedness events; not waiting for peers",
+			"err", err,
+		)
+		close(b.ready)
+		return
+	}
+	defer sub.Close()
+
+	var timeoutC <-chan time.Time
+	if b.timeout > 0 {
+		t := time.NewTimer(b.timeout)
+		defer t.Stop()
+		timeoutC = t.C
+	}
+
+	for {
+		n := len(b.host.Network().Peers())
+		if n >= b.minPeers {
+			b.log.Info("Connected to enough peers to start consensus", "n_peers", n)
+			close(b.ready)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timeoutC:
+			b.log.Warn(
+				"Timed out waiting for peers; starting consensus anyway",
+				"n_peers", len(b.host.Network().Peers()),
+				"min_peers", b.minPeers,
+			)
+			close(b.ready)
+			return
+		case <-sub.Out():
+			// Just recheck the count at the top of the loop.
+		}
+	}
+}
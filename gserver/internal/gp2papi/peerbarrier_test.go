@@ -0,0 +1,63 @@
+package gp2papi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gp2papi"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerBarrier(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The fixture's host and client are already connected to each other.
+	fx := NewFixture(t, ctx)
+
+	b := gp2papi.NewPeerBarrier(ctx, gtest.NewLogger(t), gp2papi.PeerBarrierConfig{
+		Host:     fx.P2PClientConn.Host().Libp2pHost(),
+		MinPeers: 1,
+	})
+	defer b.Wait()
+	defer cancel()
+
+	select {
+	case <-b.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("barrier did not become ready with enough connected peers")
+	}
+}
+
+func TestPeerBarrier_timeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fx := NewFixture(t, ctx)
+
+	b := gp2papi.NewPeerBarrier(ctx, gtest.NewLogger(t), gp2papi.PeerBarrierConfig{
+		Host:     fx.P2PClientConn.Host().Libp2pHost(),
+		MinPeers: 100,
+		Timeout:  50 * time.Millisecond,
+	})
+	defer b.Wait()
+	defer cancel()
+
+	select {
+	case <-b.Ready():
+		t.Fatal("barrier became ready before the timeout")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	select {
+	case <-b.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("barrier did not become ready after the timeout")
+	}
+}
@@ -0,0 +1,199 @@
+// Package gpanic converts panics in a node's long-running goroutines
+// into a clean shutdown of the node.
+//
+// An unrecovered panic in any goroutine terminates the process immediately,
+// without closing stores or recording what the goroutine was doing.
+// A [Shield] instead recovers the panic, writes a dump file
+// with the panic value, the stack, and a redacted snapshot of the goroutine's state,
+// and cancels the node's root context with a [*PanicError] as the cause,
+// so that every other subsystem stops through its normal path.
+package gpanic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"time"
+)
+
+// PanicError is the cancellation cause recorded by a [Shield]
+// after recovering a panic.
+type PanicError struct {
+	Subsystem string
+	Value     any
+	Stack     []byte
+
+	// Path of the dump file, or empty if the dump could not be written.
+	DumpPath string
+}
+
+func (e *PanicError) Error() string {
+	if e.DumpPath == "" {
+		return fmt.Sprintf("panic in %s: %v", e.Subsystem, e.Value)
+	}
+	return fmt.Sprintf("panic in %s: %v (state dumped to %s)", e.Subsystem, e.Value, e.DumpPath)
+}
+
+// ShieldConfig is the configuration for [NewShield].
+type ShieldConfig struct {
+	// Directory for dump files, created on first use.
+	// If empty, no dump files are written.
+	DumpDir string
+
+	// Called with the *PanicError after a panic is recovered,
+	// typically the cancel function of the node's root context.
+	Fail context.CancelCauseFunc
+}
+
+// Shield recovers panics in the goroutines it protects.
+// It is safe for concurrent use.
+type Shield struct {
+	log *slog.Logger
+
+	dir  string
+	fail context.CancelCauseFunc
+}
+
+// NewShield returns a new Shield based on cfg.
+func NewShield(log *slog.Logger, cfg ShieldConfig) *Shield {
+	return &Shield{
+		log: log,
+
+		dir:  cfg.DumpDir,
+		fail: cfg.Fail,
+	}
+}
+
+// Recover must be deferred directly at the top of the protected goroutine,
+// as in:
+//
+//	defer shield.Recover("driver", d.panicSnapshot)
+//
+// If the goroutine panics, Recover dumps the state and fails the node
+// as described in the package documentation, and the goroutine returns normally.
+// The snapshot function is optional; its result is encoded as JSON,
+// with the values of any keys that look secret replaced.
+// A panic within snapshot is recovered and noted in the dump.
+func (s *Shield) Recover(subsystem string, snapshot func() any) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	pe := &PanicError{
+		Subsystem: subsystem,
+		Value:     v,
+		Stack:     debug.Stack(),
+	}
+
+	if s.dir != "" {
+		path, err := s.writeDump(pe, snapshot)
+		if err != nil {
+			s.log.Error("Failed to write panic dump", "subsystem", subsystem, "err", err)
+		} else {
+			pe.DumpPath = path
+		}
+	}
+
+	s.log.Error(
+		"Recovered panic; shutting down",
+		"subsystem", subsystem, "panic", fmt.Sprint(v), "dump", pe.DumpPath,
+		"stack", string(pe.Stack),
+	)
+
+	if s.fail != nil {
+		s.fail(pe)
+	}
+}
+
+type dump struct {
+	Subsystem string    `json:"subsystem"`
+	Time      time.Time `json:"time"`
+	Panic     string    `json:"panic"`
+	Stack     string    `json:"stack"`
+
+	Snapshot any `json:"snapshot,omitempty"`
+
+	SnapshotError string `json:"snapshot_error,omitempty"`
+}
+
+func (s *Shield) writeDump(pe *PanicError, snapshot func() any) (string, error) {
+	d := dump{
+		Subsystem: pe.Subsystem,
+		Time:      time.Now().UTC(),
+		Panic:     fmt.Sprint(pe.Value),
+		Stack:     string(pe.Stack),
+	}
+	if snapshot != nil {
+		d.Snapshot, d.SnapshotError = takeSnapshot(snapshot)
+	}
+
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode dump: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	path := filepath.Join(
+		s.dir,
+		fmt.Sprintf("panic-%s-%s.json", pe.Subsystem, d.Time.Format("20060102T150405.000000000Z")),
+	)
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write dump: %w", err)
+	}
+	return path, nil
+}
+
+// takeSnapshot calls snapshot and redacts its JSON form.
+func takeSnapshot(snapshot func() any) (out any, errMsg string) {
+	defer func() {
+		if v := recover(); v != nil {
+			out, errMsg = nil, fmt.Sprintf("snapshot panicked: %v", v)
+		}
+	}()
+
+	b, err := json.Marshal(snapshot())
+	if err != nil {
+		return nil, fmt.Sprintf("failed to encode snapshot: %v", err)
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Sprintf("failed to decode snapshot: %v", err)
+	}
+	return redact(v), ""
+}
+
+// secretKey matches object keys whose values are withheld from dumps.
+var secretKey = regexp.MustCompile(`(?i)(secret|priv|password|passphrase|pin|token|mnemonic|seed|key)`)
+
+const redacted = "[redacted]"
+
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, x := range v {
+			if secretKey.MatchString(k) {
+				v[k] = redacted
+			} else {
+				v[k] = redact(x)
+			}
+		}
+		return v
+	case []any:
+		for i, x := range v {
+			v[i] = redact(x)
+		}
+		return v
+	default:
+		return v
+	}
+}
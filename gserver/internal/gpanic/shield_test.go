@@ -0,0 +1,108 @@
+package gpanic_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpanic"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShield_Recover(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	s := gpanic.NewShield(gtest.NewLogger(t), gpanic.ShieldConfig{
+		DumpDir: t.TempDir(),
+		Fail:    cancel,
+	})
+
+	type state struct {
+		Height  uint64
+		PrivKey []byte
+		Nested  map[string]string
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer s.Recover("worker", func() any {
+			return state{
+				Height:  12,
+				PrivKey: []byte("do not leak"),
+				Nested:  map[string]string{"api_token": "nor this", "peer": "p1"},
+			}
+		})
+		panic("boom")
+	}()
+	_ = gtest.ReceiveSoon(t, done)
+
+	require.Error(t, ctx.Err())
+	var pe *gpanic.PanicError
+	require.ErrorAs(t, context.Cause(ctx), &pe)
+	require.Equal(t, "worker", pe.Subsystem)
+	require.Equal(t, "boom", pe.Value)
+	require.NotEmpty(t, pe.Stack)
+	require.NotEmpty(t, pe.DumpPath)
+
+	b, err := os.ReadFile(pe.DumpPath)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "do not leak")
+	require.NotContains(t, string(b), "nor this")
+
+	var d struct {
+		Subsystem string
+		Panic     string
+		Snapshot  map[string]any
+	}
+	require.NoError(t, json.Unmarshal(b, &d))
+	require.Equal(t, "worker", d.Subsystem)
+	require.Equal(t, "boom", d.Panic)
+	require.Equal(t, float64(12), d.Snapshot["Height"])
+	require.Equal(t, "[redacted]", d.Snapshot["PrivKey"])
+	require.Equal(t, "p1", d.Snapshot["Nested"].(map[string]any)["peer"])
+}
+
+func TestShield_Recover_noPanic(t *testing.T) {
+	t.Parallel()
+
+	failed := false
+	s := gpanic.NewShield(gtest.NewLogger(t), gpanic.ShieldConfig{
+		DumpDir: t.TempDir(),
+		Fail:    func(error) { failed = true },
+	})
+
+	func() {
+		defer s.Recover("worker", nil)
+	}()
+
+	require.False(t, failed)
+}
+
+func TestShield_Recover_snapshotPanics(t *testing.T) {
+	t.Parallel()
+
+	var cause error
+	s := gpanic.NewShield(gtest.NewLogger(t), gpanic.ShieldConfig{
+		DumpDir: t.TempDir(),
+		Fail:    func(err error) { cause = err },
+	})
+
+	func() {
+		defer s.Recover("worker", func() any { panic("snapshot failed") })
+		panic(errors.New("original"))
+	}()
+
+	var pe *gpanic.PanicError
+	require.ErrorAs(t, cause, &pe)
+
+	b, err := os.ReadFile(pe.DumpPath)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "snapshot panicked")
+}
@@ -0,0 +1,262 @@
+// Package gparams schedules changes to consensus parameters
+// requested by the app, such as through on-chain governance.
+//
+// The app requests a change by emitting an end block event of type [EventType]
+// while finalizing a block, with these attributes:
+//
+//   - height: required; the first height at which the new values apply.
+//     It must be at least [MinActivationDelay] beyond the height being finalized,
+//     since the engine may already be voting on the next height.
+//   - proposal_timeout, prevote_delay_timeout, precommit_delay_timeout, commit_wait_timeout:
+//     round 0 timeouts, as Go durations such as "3s".
+//   - max_block_bytes: the maximum size of a proposed block's raw data.
+//   - evidence_max_age_blocks: how many heights behind the committing height
+//     fork evidence may be before it is rejected.
+//
+// Absent attributes keep the value in effect at the activation height,
+// and a zero value restores the node's local configuration or removes the limit.
+//
+// Because every validator finalizes the same blocks,
+// every validator schedules the same changes.
+// The [Schedule] is persisted in the data directory,
+// as the blocks requesting past changes are not finalized again after a restart.
+package gparams
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EventType is the type of the end block event requesting a parameter change.
+const EventType = "gordian_consensus_params"
+
+// MinActivationDelay is how far beyond the height being finalized
+// a requested change must apply.
+const MinActivationDelay = 2
+
+// Params are the app-governed consensus parameters.
+type Params struct {
+	// Round 0 timeouts, replacing the node's configured base timeouts.
+	// Zero keeps the configured value.
+	ProposalTimeout       time.Duration `json:",omitempty"`
+	PrevoteDelayTimeout   time.Duration `json:",omitempty"`
+	PrecommitDelayTimeout time.Duration `json:",omitempty"`
+	CommitWaitTimeout     time.Duration `json:",omitempty"`
+
+	// Maximum size of a proposed block's raw (uncompressed) data.
+	// Zero means no limit.
+	MaxBlockBytes int64 `json:",omitempty"`
+
+	// Fork evidence more than this many heights
+	// behind the committing height is rejected.
+	// Zero means no limit.
+	EvidenceMaxAgeBlocks uint64 `json:",omitempty"`
+}
+
+// Update declares that Params apply from Height onward.
+type Update struct {
+	Height uint64 `json:",string"`
+	Params Params
+}
+
+// Attribute is a key-value attribute of a parameter change event.
+type Attribute struct {
+	Key, Value string
+}
+
+// Schedule maps heights to the [Params] in effect.
+// It is safe for concurrent use.
+type Schedule struct {
+	log  *slog.Logger
+	path string
+
+	mu      sync.RWMutex
+	updates []Update // In strictly increasing height order.
+}
+
+// NewSchedule returns a Schedule persisting its updates to path,
+// loading any updates already present there.
+func NewSchedule(log *slog.Logger, path string) (*Schedule, error) {
+	s := &Schedule{log: log, path: path}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read consensus parameter schedule: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &s.updates); err != nil {
+		return nil, fmt.Errorf("failed to parse consensus parameter schedule at %s: %w", path, err)
+	}
+	for i := 1; i < len(s.updates); i++ {
+		if s.updates[i].Height <= s.updates[i-1].Height {
+			return nil, fmt.Errorf(
+				"consensus parameter schedule at %s is not in increasing height order", path,
+			)
+		}
+	}
+
+	return s, nil
+}
+
+// Path returns the path of the persisted schedule.
+func (s *Schedule) Path() string {
+	return s.path
+}
+
+// Updates returns a copy of the scheduled updates, in increasing height order.
+func (s *Schedule) Updates() []Update {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.updates)
+}
+
+// At returns the parameters in effect at height.
+// A nil Schedule has zero parameters at every height.
+func (s *Schedule) At(height uint64) Params {
+	if s == nil {
+		return Params{}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var p Params
+	for _, u := range s.updates {
+		if u.Height > height {
+			break
+		}
+		p = u.Params
+	}
+	return p
+}
+
+// Request schedules the change described by attrs,
+// from an event emitted while finalizing the given height.
+//
+// The activation height must be at least [MinActivationDelay] beyond finalized,
+// and beyond every height already scheduled.
+// Requesting an update identical to the latest one is a no-op,
+// so that finalizing a block again after a crash is harmless.
+func (s *Schedule) Request(finalized uint64, attrs []Attribute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		height    uint64
+		hasHeight bool
+	)
+	for _, a := range attrs {
+		if a.Key != "height" {
+			continue
+		}
+		h, err := strconv.ParseUint(a.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid height %q: %w", a.Value, err)
+		}
+		height, hasHeight = h, true
+	}
+	if !hasHeight {
+		return errors.New("missing height attribute")
+	}
+	if height < finalized+MinActivationDelay {
+		return fmt.Errorf(
+			"activation height %d is less than %d heights beyond finalized height %d",
+			height, MinActivationDelay, finalized,
+		)
+	}
+
+	var p Params
+	var last *Update
+	if n := len(s.updates); n > 0 {
+		last = &s.updates[n-1]
+		p = last.Params
+	}
+
+	for _, a := range attrs {
+		var err error
+		switch a.Key {
+		case "height":
+			// Already handled.
+		case "proposal_timeout":
+			p.ProposalTimeout, err = parseTimeout(a.Value)
+		case "prevote_delay_timeout":
+			p.PrevoteDelayTimeout, err = parseTimeout(a.Value)
+		case "precommit_delay_timeout":
+			p.PrecommitDelayTimeout, err = parseTimeout(a.Value)
+		case "commit_wait_timeout":
+			p.CommitWaitTimeout, err = parseTimeout(a.Value)
+		case "max_block_bytes":
+			p.MaxBlockBytes, err = strconv.ParseInt(a.Value, 10, 64)
+			if err == nil && p.MaxBlockBytes < 0 {
+				err = errors.New("must not be negative")
+			}
+		case "evidence_max_age_blocks":
+			p.EvidenceMaxAgeBlocks, err = strconv.ParseUint(a.Value, 10, 64)
+		default:
+			err = errors.New("unknown attribute")
+		}
+		if err != nil {
+			return fmt.Errorf("invalid attribute %s=%q: %w", a.Key, a.Value, err)
+		}
+	}
+
+	if last != nil {
+		if last.Height == height && last.Params == p {
+			return nil
+		}
+		if height <= last.Height {
+			return fmt.Errorf(
+				"activation height %d is not beyond already scheduled height %d",
+				height, last.Height,
+			)
+		}
+	}
+
+	updates := append(slices.Clone(s.updates), Update{Height: height, Params: p})
+	if err := s.write(updates); err != nil {
+		return fmt.Errorf("failed to persist consensus parameter schedule: %w", err)
+	}
+	s.updates = updates
+
+	s.log.Info(
+		"Scheduled consensus parameter change",
+		"height", height, "requested_at", finalized, "params", p,
+	)
+	return nil
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = errors.New("must not be negative")
+	}
+	return d, err
+}
+
+func (s *Schedule) write(updates []Update) error {
+	b, err := json.MarshalIndent(updates, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,83 @@
+package gparams_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Request(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "data", "consensus_params.json")
+	s, err := gparams.NewSchedule(gtest.NewLogger(t), path)
+	require.NoError(t, err)
+
+	require.Equal(t, gparams.Params{}, s.At(100))
+
+	require.NoError(t, s.Request(10, []gparams.Attribute{
+		{Key: "height", Value: "20"},
+		{Key: "proposal_timeout", Value: "3s"},
+		{Key: "max_block_bytes", Value: "1000"},
+	}))
+
+	require.Equal(t, gparams.Params{}, s.At(19))
+	require.Equal(t, gparams.Params{
+		ProposalTimeout: 3 * time.Second,
+		MaxBlockBytes:   1000,
+	}, s.At(20))
+
+	// The same request again, as after a crash, is a no-op.
+	require.NoError(t, s.Request(10, []gparams.Attribute{
+		{Key: "height", Value: "20"},
+		{Key: "proposal_timeout", Value: "3s"},
+		{Key: "max_block_bytes", Value: "1000"},
+	}))
+	require.Len(t, s.Updates(), 1)
+
+	// Later updates keep absent values, and zero removes a value.
+	require.NoError(t, s.Request(15, []gparams.Attribute{
+		{Key: "height", Value: "30"},
+		{Key: "max_block_bytes", Value: "0"},
+		{Key: "evidence_max_age_blocks", Value: "50"},
+	}))
+	want := gparams.Params{
+		ProposalTimeout:      3 * time.Second,
+		EvidenceMaxAgeBlocks: 50,
+	}
+	require.Equal(t, want, s.At(30))
+
+	// The schedule survives a restart.
+	s2, err := gparams.NewSchedule(gtest.NewLogger(t), path)
+	require.NoError(t, err)
+	require.Equal(t, s.Updates(), s2.Updates())
+	require.Equal(t, want, s2.At(1000))
+}
+
+func TestSchedule_Request_invalid(t *testing.T) {
+	t.Parallel()
+
+	s, err := gparams.NewSchedule(gtest.NewLogger(t), filepath.Join(t.TempDir(), "consensus_params.json"))
+	require.NoError(t, err)
+	require.NoError(t, s.Request(10, []gparams.Attribute{{Key: "height", Value: "20"}}))
+
+	for name, attrs := range map[string][]gparams.Attribute{
+		"missing height":   {{Key: "proposal_timeout", Value: "1s"}},
+		"too soon":         {{Key: "height", Value: "11"}},
+		"not increasing":   {{Key: "height", Value: "20"}, {Key: "proposal_timeout", Value: "1s"}},
+		"bad duration":     {{Key: "height", Value: "40"}, {Key: "commit_wait_timeout", Value: "soon"}},
+		"negative size":    {{Key: "height", Value: "40"}, {Key: "max_block_bytes", Value: "-1"}},
+		"unknown key":      {{Key: "height", Value: "40"}, {Key: "max_gas", Value: "1"}},
+		"negative timeout": {{Key: "height", Value: "40"}, {Key: "proposal_timeout", Value: "-1s"}},
+	} {
+		require.Errorf(t, s.Request(10, attrs), "expected error for %s", name)
+	}
+	require.Len(t, s.Updates(), 1)
+
+	var nilSchedule *gparams.Schedule
+	require.Equal(t, gparams.Params{}, nilSchedule.At(1))
+}
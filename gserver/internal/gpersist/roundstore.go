@@ -0,0 +1,374 @@
+// Package gpersist retries failed writes to the engine's round store.
+//
+// The engine's mirror kernel only logs a failed round store write
+// and carries on with the change in memory,
+// so a transient database failure leaves the on-disk round state behind,
+// and a restart would resurrect the stale state.
+// A [RoundStore] wraps the real store and queues a failed write
+// to be retried with exponential backoff,
+// reporting success to the kernel so that it keeps its in-memory progress.
+// Queued writes are applied in order,
+// and every later write waits behind them,
+// so the store never reflects a newer write without the older ones.
+//
+// While writes keep failing, the store reports itself as degraded
+// through [RoundStore.Health].
+package gpersist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/trace"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+)
+
+// Defaults for [RoundStoreConfig].
+const (
+	DefaultMinBackoff    = 100 * time.Millisecond
+	DefaultMaxBackoff    = 10 * time.Second
+	DefaultDegradedAfter = 5
+	DefaultMaxQueued     = 1024
+)
+
+// How long to keep retrying queued writes after the context is canceled.
+const finalFlushTimeout = 5 * time.Second
+
+// RoundStoreConfig is the configuration for [NewRoundStore].
+type RoundStoreConfig struct {
+	// Bounds of the exponential backoff between retries.
+	// Default to DefaultMinBackoff and DefaultMaxBackoff if zero.
+	MinBackoff, MaxBackoff time.Duration
+
+	// Number of consecutive failed attempts after which the store is degraded.
+	// Defaults to DefaultDegradedAfter if zero.
+	DegradedAfter int
+
+	// Maximum number of queued writes.
+	// Once the queue is full, a failed write returns its error,
+	// as if the store were not wrapped.
+	// Defaults to DefaultMaxQueued if zero.
+	MaxQueued int
+}
+
+// Health is a snapshot of a [RoundStore]'s retry state.
+type Health struct {
+	// Set once DegradedAfter consecutive attempts have failed,
+	// and cleared when the queue drains.
+	Degraded bool
+
+	// Writes waiting to be retried.
+	Pending int
+
+	ConsecutiveFailures int
+
+	LastError   string `json:",omitempty"`
+	LastFailure time.Time
+}
+
+// write is a queued write.
+type write struct {
+	// Writes with the same non-empty key replace each other in the queue,
+	// as each overwrites the previous one in the store.
+	key string
+
+	desc string
+	run  func(context.Context) error
+}
+
+// RoundStore is a [tmstore.RoundStore] that retries failed writes.
+type RoundStore struct {
+	log   *slog.Logger
+	inner tmstore.RoundStore
+
+	minBackoff, maxBackoff time.Duration
+	degradedAfter          int
+	maxQueued              int
+
+	// Held while writing to inner, so that writes are applied in order.
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	queue  []write
+	health Health
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+// NewRoundStore returns a new RoundStore wrapping inner,
+// which retries queued writes until ctx is canceled.
+func NewRoundStore(
+	ctx context.Context, log *slog.Logger, inner tmstore.RoundStore, cfg RoundStoreConfig,
+) *RoundStore {
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.DegradedAfter == 0 {
+		cfg.DegradedAfter = DefaultDegradedAfter
+	}
+	if cfg.MaxQueued == 0 {
+		cfg.MaxQueued = DefaultMaxQueued
+	}
+
+	s := &RoundStore{
+		log:   log,
+		inner: inner,
+
+		minBackoff:    cfg.MinBackoff,
+		maxBackoff:    cfg.MaxBackoff,
+		degradedAfter: cfg.DegradedAfter,
+		maxQueued:     cfg.MaxQueued,
+
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	go s.kernel(ctx)
+
+	return s
+}
+
+// Wait blocks until s has stopped retrying.
+func (s *RoundStore) Wait() {
+	<-s.done
+}
+
+// Health returns the current retry state.
+func (s *RoundStore) Health() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.health
+	h.Pending = len(s.queue)
+	return h
+}
+
+func (s *RoundStore) SaveRoundProposedHeader(ctx context.Context, ph tmconsensus.ProposedHeader) error {
+	return s.do(ctx, write{
+		desc: fmt.Sprintf("proposed header %x at %d/%d", ph.Header.Hash, ph.Header.Height, ph.Round),
+		run: func(ctx context.Context) error {
+			return s.inner.SaveRoundProposedHeader(ctx, ph)
+		},
+	})
+}
+
+func (s *RoundStore) SaveRoundReplayedHeader(ctx context.Context, h tmconsensus.Header) error {
+	return s.do(ctx, write{
+		desc: fmt.Sprintf("replayed header %x at height %d", h.Hash, h.Height),
+		run: func(ctx context.Context) error {
+			return s.inner.SaveRoundReplayedHeader(ctx, h)
+		},
+	})
+}
+
+func (s *RoundStore) OverwriteRoundPrevoteProofs(
+	ctx context.Context, height uint64, round uint32, proofs tmconsensus.SparseSignatureCollection,
+) error {
+	return s.do(ctx, write{
+		key:  fmt.Sprintf("prevotes/%d/%d", height, round),
+		desc: fmt.Sprintf("prevote proofs at %d/%d", height, round),
+		run: func(ctx context.Context) error {
+			return s.inner.OverwriteRoundPrevoteProofs(ctx, height, round, proofs)
+		},
+	})
+}
+
+func (s *RoundStore) OverwriteRoundPrecommitProofs(
+	ctx context.Context, height uint64, round uint32, proofs tmconsensus.SparseSignatureCollection,
+) error {
+	return s.do(ctx, write{
+		key:  fmt.Sprintf("precommits/%d/%d", height, round),
+		desc: fmt.Sprintf("precommit proofs at %d/%d", height, round),
+		run: func(ctx context.Context) error {
+			return s.inner.OverwriteRoundPrecommitProofs(ctx, height, round, proofs)
+		},
+	})
+}
+
+// LoadRoundState first tries once to apply any queued writes,
+// so that the loaded state includes them.
+// If writes remain queued, the result may be stale, and a warning is logged.
+func (s *RoundStore) LoadRoundState(ctx context.Context, height uint64, round uint32) (
+	phs []tmconsensus.ProposedHeader,
+	prevotes, precommits tmconsensus.SparseSignatureCollection,
+	err error,
+) {
+	if n := s.flush(ctx); n > 0 {
+		s.log.Warn(
+			"Loading round state with writes still queued; the result may be stale",
+			"height", height, "round", round, "pending", n,
+		)
+	}
+	return s.inner.LoadRoundState(ctx, height, round)
+}
+
+// do applies w directly if nothing is queued,
+// and otherwise queues w behind the earlier writes.
+func (s *RoundStore) do(ctx context.Context, w write) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	queued := len(s.queue) > 0
+	s.mu.Unlock()
+
+	if !queued {
+		err := w.run(ctx)
+		if err == nil || !retryable(ctx, err) {
+			return err
+		}
+		s.recordFailure(w, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w.key != "" {
+		for i := range s.queue {
+			if s.queue[i].key == w.key {
+				s.queue[i] = w
+				return nil
+			}
+		}
+	}
+	if len(s.queue) >= s.maxQueued {
+		return fmt.Errorf("round store retry queue is full (%d writes)", len(s.queue))
+	}
+	s.queue = append(s.queue, w)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// retryable reports whether err from a write may succeed when retried.
+// Errors classifying the write itself, such as an attempted overwrite, are not retried.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch gcerr.CodeOf(err) {
+	case gcerr.CodeUnknown, gcerr.CodeUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *RoundStore) recordFailure(w write, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health.ConsecutiveFailures++
+	s.health.LastError = err.Error()
+	s.health.LastFailure = time.Now()
+
+	if !s.health.Degraded && s.health.ConsecutiveFailures >= s.degradedAfter {
+		s.health.Degraded = true
+		s.log.Error(
+			"Round store writes keep failing; on-disk round state is behind",
+			"failures", s.health.ConsecutiveFailures, "write", w.desc, "err", err,
+		)
+		return
+	}
+	s.log.Warn("Failed to write to round store; will retry", "write", w.desc, "err", err)
+}
+
+// flush applies queued writes in order until one fails,
+// returning the number of writes still queued.
+func (s *RoundStore) flush(ctx context.Context) int {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			recovered := s.health.Degraded
+			s.health.Degraded = false
+			s.health.ConsecutiveFailures = 0
+			s.mu.Unlock()
+
+			if recovered {
+				s.log.Info("Round store writes succeeded again; no longer degraded")
+			}
+			return 0
+		}
+		w := s.queue[0]
+		s.mu.Unlock()
+
+		if err := w.run(ctx); err != nil && retryable(ctx, err) {
+			s.recordFailure(w, err)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.queue)
+		} else if err != nil {
+			s.log.Warn("Dropping queued round store write", "write", w.desc, "err", err)
+		}
+
+		s.mu.Lock()
+		s.queue = s.queue[1:]
+		s.health.ConsecutiveFailures = 0
+		s.mu.Unlock()
+	}
+}
+
+func (s *RoundStore) kernel(ctx context.Context) {
+	defer close(s.done)
+
+	ctx, task := trace.NewTask(ctx, "RoundStore.kernel")
+	defer task.End()
+
+	backoff := s.minBackoff
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			s.finalFlush()
+			return
+
+		case <-s.wake:
+			if retry == nil {
+				retry = time.After(backoff)
+			}
+
+		case <-retry:
+			retry = nil
+			if n := s.flush(ctx); n > 0 {
+				backoff = min(2*backoff, s.maxBackoff)
+				retry = time.After(backoff)
+			} else {
+				backoff = s.minBackoff
+			}
+		}
+	}
+}
+
+// finalFlush makes a last attempt at queued writes during shutdown,
+// so that a restart does not load stale round state.
+func (s *RoundStore) finalFlush() {
+	ctx, cancel := context.WithTimeoutCause(
+		context.Background(), finalFlushTimeout,
+		errors.New("round store final flush timed out"),
+	)
+	defer cancel()
+
+	if n := s.flush(ctx); n > 0 {
+		s.log.Error(
+			"Round store writes still failing at shutdown; round state on disk is stale",
+			"dropped", n,
+		)
+	}
+}
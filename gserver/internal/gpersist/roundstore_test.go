@@ -0,0 +1,106 @@
+package gpersist_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpersist"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRoundStore fails every write while failing is set.
+type flakyRoundStore struct {
+	tmstore.RoundStore
+	failing atomic.Bool
+}
+
+var errFlaky = errors.New("database is locked")
+
+func (s *flakyRoundStore) SaveRoundProposedHeader(ctx context.Context, ph tmconsensus.ProposedHeader) error {
+	if s.failing.Load() {
+		return errFlaky
+	}
+	return s.RoundStore.SaveRoundProposedHeader(ctx, ph)
+}
+
+func (s *flakyRoundStore) OverwriteRoundPrecommitProofs(
+	ctx context.Context, height uint64, round uint32, proofs tmconsensus.SparseSignatureCollection,
+) error {
+	if s.failing.Load() {
+		return errFlaky
+	}
+	return s.RoundStore.OverwriteRoundPrecommitProofs(ctx, height, round, proofs)
+}
+
+func TestRoundStore_retry(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inner := &flakyRoundStore{RoundStore: tmmemstore.NewRoundStore()}
+	s := gpersist.NewRoundStore(ctx, gtest.NewLogger(t), inner, gpersist.RoundStoreConfig{
+		MinBackoff:    time.Millisecond,
+		MaxBackoff:    5 * time.Millisecond,
+		DegradedAfter: 2,
+	})
+	defer s.Wait()
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(2)
+	ph := fx.NextProposedHeader([]byte("app_data"), 0)
+	fx.SignProposal(ctx, &ph, 0)
+
+	// Failed writes are reported as successful and queued.
+	inner.failing.Store(true)
+	require.NoError(t, s.SaveRoundProposedHeader(ctx, ph))
+	require.NoError(t, s.OverwriteRoundPrecommitProofs(ctx, 1, 0, tmconsensus.SparseSignatureCollection{}))
+	require.Eventually(t, func() bool {
+		return s.Health().Degraded
+	}, 5*time.Second, time.Millisecond)
+	h := s.Health()
+	require.Equal(t, 2, h.Pending)
+	require.Equal(t, errFlaky.Error(), h.LastError)
+
+	// Once the store recovers, the queue drains in order.
+	inner.failing.Store(false)
+	require.Eventually(t, func() bool {
+		h := s.Health()
+		return !h.Degraded && h.Pending == 0
+	}, 5*time.Second, time.Millisecond)
+
+	phs, _, _, err := s.LoadRoundState(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, phs, 1)
+	require.Equal(t, ph.Header.Hash, phs[0].Header.Hash)
+}
+
+func TestRoundStore_notRetryable(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := gpersist.NewRoundStore(ctx, gtest.NewLogger(t), tmmemstore.NewRoundStore(), gpersist.RoundStoreConfig{})
+	defer s.Wait()
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(2)
+	ph := fx.NextProposedHeader([]byte("app_data"), 0)
+	fx.SignProposal(ctx, &ph, 0)
+	require.NoError(t, s.SaveRoundProposedHeader(ctx, ph))
+
+	// Saving the same header again is an overwrite error,
+	// which the caller must see rather than having it retried.
+	err := s.SaveRoundProposedHeader(ctx, ph)
+	require.ErrorAs(t, err, new(tmstore.OverwriteError))
+	require.Zero(t, s.Health().Pending)
+}
@@ -0,0 +1,92 @@
+// Package gpreflight collects the results of the consistency checks
+// that a node runs when started in validate-only mode,
+// so that misconfiguration is caught before a validator goes live.
+package gpreflight
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Result is the outcome of a single [Check].
+type Result string
+
+const (
+	// The check passed.
+	ResultOK Result = "ok"
+
+	// The check passed, but the node may not behave as the operator expects.
+	ResultWarn Result = "warn"
+
+	// The node would fail or misbehave if started.
+	ResultFail Result = "FAIL"
+)
+
+// Check is the outcome of one consistency check.
+type Check struct {
+	Name   string
+	Result Result
+	Detail string
+}
+
+// Report is an ordered list of checks.
+// The zero value is ready to use.
+type Report struct {
+	Checks []Check
+}
+
+// OK records a passing check.
+func (r *Report) OK(name, format string, args ...any) {
+	r.add(name, ResultOK, format, args)
+}
+
+// Warn records a check that passed with a warning.
+func (r *Report) Warn(name, format string, args ...any) {
+	r.add(name, ResultWarn, format, args)
+}
+
+// Fail records a failing check.
+func (r *Report) Fail(name, format string, args ...any) {
+	r.add(name, ResultFail, format, args)
+}
+
+func (r *Report) add(name string, res Result, format string, args []any) {
+	r.Checks = append(r.Checks, Check{
+		Name:   name,
+		Result: res,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// Failures returns the number of failing checks.
+func (r *Report) Failures() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Result == ResultFail {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes r as an aligned table, one check per line,
+// followed by a summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range r.Checks {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Result, c.Name, c.Detail); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if n := r.Failures(); n > 0 {
+		_, err := fmt.Fprintf(w, "%d of %d checks failed\n", n, len(r.Checks))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "All %d checks passed\n", len(r.Checks))
+	return err
+}
@@ -0,0 +1,37 @@
+package gpreflight_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpreflight"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	var r gpreflight.Report
+	r.OK("genesis", "chain ID %q", "gcosmos")
+	r.Warn("seeds", "no seed addresses")
+	require.Zero(t, r.Failures())
+
+	var sb strings.Builder
+	require.NoError(t, r.WriteText(&sb))
+	require.Equal(t, `ok    genesis  chain ID "gcosmos"
+warn  seeds    no seed addresses
+All 2 checks passed
+`, sb.String())
+
+	r.Fail("stores", "committing height %d has no finalization", 5)
+	require.Equal(t, 1, r.Failures())
+	require.Equal(t, gpreflight.Check{
+		Name:   "stores",
+		Result: gpreflight.ResultFail,
+		Detail: "committing height 5 has no finalization",
+	}, r.Checks[2])
+
+	sb.Reset()
+	require.NoError(t, r.WriteText(&sb))
+	require.True(t, strings.HasSuffix(sb.String(), "1 of 3 checks failed\n"))
+}
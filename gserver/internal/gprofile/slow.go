@@ -0,0 +1,203 @@
+// Package gprofile captures pprof snapshots of slow block finalizations,
+// so that an operator can diagnose a slow application after the fact.
+//
+// A [SlowCapture] watches each finalization through [SlowCapture.Begin].
+// Once a finalization has been running longer than the configured threshold,
+// a CPU profile starts, and it runs until the finalization completes
+// or the maximum CPU profile duration elapses.
+// When the finalization completes, a heap profile is written alongside the CPU profile.
+// Both files are named after the height, for example:
+//
+//	height-1234-cpu.pprof
+//	height-1234-heap.pprof
+package gprofile
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// DefaultMaxCPUDuration is used when [SlowCaptureConfig.MaxCPUDuration] is zero.
+const DefaultMaxCPUDuration = 30 * time.Second
+
+// SlowCaptureConfig is the configuration for [NewSlowCapture].
+type SlowCaptureConfig struct {
+	// Directory for profile files, created on first use.
+	Dir string
+
+	// How long a finalization may run before profiling starts.
+	// Zero or negative disables capturing.
+	Threshold time.Duration
+
+	// Upper bound on the length of a single CPU profile,
+	// in case a finalization never completes.
+	// Zero means [DefaultMaxCPUDuration].
+	MaxCPUDuration time.Duration
+}
+
+// SlowCapture captures profiles of slow finalizations.
+// It is safe for concurrent use,
+// but only one finalization is profiled at a time,
+// as the Go runtime only supports one CPU profile at a time.
+type SlowCapture struct {
+	log *slog.Logger
+
+	dir       string
+	threshold time.Duration
+	maxCPU    time.Duration
+
+	mu sync.Mutex
+
+	// Set while a CPU profile is running.
+	cpuFile   *os.File
+	cpuHeight uint64
+	cpuTimer  *time.Timer
+}
+
+// NewSlowCapture returns a new SlowCapture based on cfg.
+func NewSlowCapture(log *slog.Logger, cfg SlowCaptureConfig) *SlowCapture {
+	maxCPU := cfg.MaxCPUDuration
+	if maxCPU <= 0 {
+		maxCPU = DefaultMaxCPUDuration
+	}
+
+	return &SlowCapture{
+		log: log,
+
+		dir:       cfg.Dir,
+		threshold: cfg.Threshold,
+		maxCPU:    maxCPU,
+	}
+}
+
+// Begin marks the start of the finalization of the given height.
+// The returned function must be called when the finalization completes.
+//
+// Begin may be called on a nil SlowCapture,
+// in which case nothing is captured.
+func (c *SlowCapture) Begin(height uint64) (end func()) {
+	if c == nil || c.threshold <= 0 {
+		return func() {}
+	}
+
+	start := time.Now()
+	t := time.AfterFunc(c.threshold, func() {
+		c.startCPU(height)
+	})
+
+	return func() {
+		if t.Stop() {
+			// Finished under the threshold; nothing was captured.
+			return
+		}
+
+		// The timer fired, so profiling at least started for this height,
+		// unless another capture was already running.
+		if !c.stopCPU(height) {
+			return
+		}
+
+		heapPath := c.path(height, "heap")
+		if err := writeHeap(heapPath); err != nil {
+			c.log.Warn("Failed to write heap profile", "height", height, "err", err)
+			heapPath = ""
+		}
+
+		c.log.Warn(
+			"Captured profile of slow finalization",
+			"height", height,
+			"dur", time.Since(start),
+			"threshold", c.threshold,
+			"cpu_profile", c.path(height, "cpu"),
+			"heap_profile", heapPath,
+		)
+	}
+}
+
+func (c *SlowCapture) startCPU(height uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cpuFile != nil {
+		c.log.Info(
+			"Skipping profile of slow finalization while another capture is running",
+			"height", height, "running_height", c.cpuHeight,
+		)
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		c.log.Warn("Failed to create profile directory", "dir", c.dir, "err", err)
+		return
+	}
+
+	f, err := os.Create(c.path(height, "cpu"))
+	if err != nil {
+		c.log.Warn("Failed to create CPU profile file", "height", height, "err", err)
+		return
+	}
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		// Most likely another CPU profile is running, outside this package.
+		c.log.Warn("Failed to start CPU profile", "height", height, "err", err)
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return
+	}
+
+	c.log.Info(
+		"Finalization exceeded threshold; started CPU profile",
+		"height", height, "threshold", c.threshold,
+	)
+
+	c.cpuFile = f
+	c.cpuHeight = height
+	c.cpuTimer = time.AfterFunc(c.maxCPU, func() {
+		c.stopCPU(height)
+	})
+}
+
+// stopCPU stops the CPU profile if it is running for height,
+// reporting whether there was a profile for height.
+// A profile already stopped by the maximum duration still counts.
+func (c *SlowCapture) stopCPU(height uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cpuFile == nil || c.cpuHeight != height {
+		// Either this height's profile already hit the maximum duration,
+		// or it never started.
+		_, err := os.Stat(c.path(height, "cpu"))
+		return err == nil
+	}
+
+	c.cpuTimer.Stop()
+	pprof.StopCPUProfile()
+	if err := c.cpuFile.Close(); err != nil {
+		c.log.Warn("Failed to close CPU profile file", "height", height, "err", err)
+	}
+	c.cpuFile = nil
+	c.cpuTimer = nil
+	return true
+}
+
+func (c *SlowCapture) path(height uint64, kind string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("height-%d-%s.pprof", height, kind))
+}
+
+func writeHeap(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup("heap").WriteTo(f, 0); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,78 @@
+package gprofile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gprofile"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowCapture_underThreshold(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c := gprofile.NewSlowCapture(gtest.NewLogger(t), gprofile.SlowCaptureConfig{
+		Dir:       dir,
+		Threshold: time.Hour,
+	})
+
+	c.Begin(3)()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestSlowCapture_overThreshold(t *testing.T) {
+	// Not parallel: the runtime only supports one CPU profile at a time.
+	dir := filepath.Join(t.TempDir(), "profiles")
+	c := gprofile.NewSlowCapture(gtest.NewLogger(t), gprofile.SlowCaptureConfig{
+		Dir:       dir,
+		Threshold: time.Millisecond,
+	})
+
+	end := c.Begin(5)
+	time.Sleep(50 * time.Millisecond)
+	end()
+
+	require.FileExists(t, filepath.Join(dir, "height-5-cpu.pprof"))
+	require.FileExists(t, filepath.Join(dir, "height-5-heap.pprof"))
+}
+
+func TestSlowCapture_maxCPUDuration(t *testing.T) {
+	// Not parallel: the runtime only supports one CPU profile at a time.
+	dir := t.TempDir()
+	c := gprofile.NewSlowCapture(gtest.NewLogger(t), gprofile.SlowCaptureConfig{
+		Dir:            dir,
+		Threshold:      time.Millisecond,
+		MaxCPUDuration: 10 * time.Millisecond,
+	})
+
+	end := c.Begin(7)
+	time.Sleep(100 * time.Millisecond)
+
+	// The CPU profile was stopped by the maximum duration,
+	// so a new capture can start before the first finalization completes.
+	end2 := c.Begin(8)
+	time.Sleep(50 * time.Millisecond)
+	end2()
+	end()
+
+	for _, name := range []string{
+		"height-7-cpu.pprof", "height-7-heap.pprof",
+		"height-8-cpu.pprof", "height-8-heap.pprof",
+	} {
+		require.FileExists(t, filepath.Join(dir, name))
+	}
+}
+
+func TestSlowCapture_nil(t *testing.T) {
+	t.Parallel()
+
+	var c *gprofile.SlowCapture
+	c.Begin(1)()
+}
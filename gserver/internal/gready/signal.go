@@ -0,0 +1,248 @@
+// Package gready lets validators advertise which upgrade features they are ready to run,
+// so that operators can see when enough voting power is ready to activate an upgrade.
+//
+// A validator signs a [Signal] listing its ready features
+// with its consensus key, and sends it to its peers in heartbeats.
+// Each node keeps the latest valid signal from every validator in a [Tracker],
+// and tallies the voting power ready for each feature
+// against the validator set at the committing height.
+//
+// Signals are advisory: they do not activate anything by themselves.
+package gready
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Limits on the features in a [Signal],
+// keeping signals small enough for heartbeats.
+const (
+	MaxFeatures      = 16
+	MaxFeatureLength = 64
+)
+
+// DefaultMaxAge is the MaxAge used when [TrackerConfig.MaxAge] is zero.
+const DefaultMaxAge = 100
+
+// signPrefix separates readiness signatures from any other use of the consensus key.
+const signPrefix = "gcosmos/readiness/v1\x00"
+
+// Signal is a validator's signed statement of the features it is ready to run.
+type Signal struct {
+	// The validator's public key, as encoded by the crypto registry.
+	PubKey []byte
+
+	// The validator's voting height when it signed the signal.
+	// A newer signal from the same validator replaces an older one,
+	// so that a validator can withdraw readiness.
+	Height uint64
+
+	// Sorted and without duplicates.
+	Features []string `json:",omitempty"`
+
+	Signature []byte
+}
+
+// SignBytes returns the bytes signed for the given height and features.
+func SignBytes(height uint64, features []string) []byte {
+	b := []byte(signPrefix)
+	b = binary.AppendUvarint(b, height)
+	b = binary.AppendUvarint(b, uint64(len(features)))
+	for _, f := range features {
+		b = binary.AppendUvarint(b, uint64(len(f)))
+		b = append(b, f...)
+	}
+	return b
+}
+
+// NewSignal returns a Signal for features at height, signed by signer.
+func NewSignal(
+	ctx context.Context, reg *gcrypto.Registry, signer gcrypto.Signer, height uint64, features []string,
+) (Signal, error) {
+	features = slices.Clone(features)
+	slices.Sort(features)
+	features = slices.Compact(features)
+	if err := validateFeatures(features); err != nil {
+		return Signal{}, err
+	}
+
+	sig, err := signer.Sign(ctx, SignBytes(height, features))
+	if err != nil {
+		return Signal{}, fmt.Errorf("failed to sign readiness signal: %w", err)
+	}
+
+	return Signal{
+		PubKey:    reg.Marshal(signer.PubKey()),
+		Height:    height,
+		Features:  features,
+		Signature: sig,
+	}, nil
+}
+
+// ValidateFeatures reports an error if features would not fit in a [Signal].
+func ValidateFeatures(features []string) error {
+	if len(features) > MaxFeatures {
+		return fmt.Errorf("too many features: %d > %d", len(features), MaxFeatures)
+	}
+	for _, f := range features {
+		if f == "" || len(f) > MaxFeatureLength {
+			return fmt.Errorf("feature names must be 1 to %d bytes: %q", MaxFeatureLength, f)
+		}
+	}
+	return nil
+}
+
+// validateFeatures additionally requires features to be sorted without duplicates.
+func validateFeatures(features []string) error {
+	if err := ValidateFeatures(features); err != nil {
+		return err
+	}
+	for i := 1; i < len(features); i++ {
+		if features[i] <= features[i-1] {
+			return errors.New("features must be sorted without duplicates")
+		}
+	}
+	return nil
+}
+
+// TrackerConfig is the configuration for [NewTracker].
+type TrackerConfig struct {
+	// Decodes the public keys in signals.
+	Registry *gcrypto.Registry
+
+	// Signals signed more than this many heights
+	// before the committing height are left out of tallies,
+	// as the validator is no longer advertising them.
+	// Defaults to DefaultMaxAge if zero.
+	MaxAge uint64
+}
+
+// Tracker keeps the latest valid signal from each validator.
+// It is safe for concurrent use.
+type Tracker struct {
+	reg    *gcrypto.Registry
+	maxAge uint64
+
+	mu      sync.Mutex
+	signals map[string]Signal // By public key bytes.
+}
+
+// NewTracker returns a new Tracker based on cfg.
+func NewTracker(cfg TrackerConfig) *Tracker {
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = DefaultMaxAge
+	}
+
+	return &Tracker{
+		reg:    cfg.Registry,
+		maxAge: cfg.MaxAge,
+
+		signals: make(map[string]Signal),
+	}
+}
+
+// Record verifies s and keeps it if it is newer than
+// the signal already held for the same validator.
+// Signals from keys outside vals are rejected,
+// so that arbitrary keys cannot grow the tracker.
+func (t *Tracker) Record(s Signal, vals []tmconsensus.Validator) error {
+	if err := validateFeatures(s.Features); err != nil {
+		return err
+	}
+
+	pubKey, err := t.reg.Unmarshal(s.PubKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	if !slices.ContainsFunc(vals, func(v tmconsensus.Validator) bool {
+		return pubKey.Equal(v.PubKey)
+	}) {
+		return errors.New("signal is not from a current validator")
+	}
+	if !pubKey.Verify(SignBytes(s.Height, s.Features), s.Signature) {
+		return errors.New("invalid signature")
+	}
+
+	key := string(pubKey.PubKeyBytes())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if prev, ok := t.signals[key]; ok && prev.Height >= s.Height {
+		return nil
+	}
+	t.signals[key] = s
+	return nil
+}
+
+// Tally is the voting power ready for each advertised feature.
+type Tally struct {
+	// The height whose validator set the tally is against.
+	CommittingHeight uint64
+
+	TotalPower uint64
+
+	// Sorted by feature name.
+	Features []FeatureTally
+}
+
+// FeatureTally is the voting power ready for a single feature.
+type FeatureTally struct {
+	Feature string
+
+	ReadyPower uint64
+
+	// The ready validators' public keys, as encoded by the crypto registry,
+	// in validator set order.
+	Validators [][]byte
+}
+
+// Tally tallies the current signals against vals,
+// the validator set at committingHeight.
+// Signals from validators no longer in vals,
+// or signed more than MaxAge heights before committingHeight, are left out.
+func (t *Tracker) Tally(committingHeight uint64, vals []tmconsensus.Validator) Tally {
+	out := Tally{CommittingHeight: committingHeight}
+	byFeature := make(map[string]*FeatureTally)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, v := range vals {
+		out.TotalPower += v.Power
+
+		s, ok := t.signals[string(v.PubKey.PubKeyBytes())]
+		if !ok || (s.Height < committingHeight && committingHeight-s.Height > t.maxAge) {
+			continue
+		}
+
+		for _, f := range s.Features {
+			ft, ok := byFeature[f]
+			if !ok {
+				ft = &FeatureTally{Feature: f}
+				byFeature[f] = ft
+			}
+			ft.ReadyPower += v.Power
+			ft.Validators = append(ft.Validators, t.reg.Marshal(v.PubKey))
+		}
+	}
+
+	out.Features = make([]FeatureTally, 0, len(byFeature))
+	for _, ft := range byFeature {
+		out.Features = append(out.Features, *ft)
+	}
+	slices.SortFunc(out.Features, func(a, b FeatureTally) int {
+		return strings.Compare(a.Feature, b.Feature)
+	})
+
+	return out
+}
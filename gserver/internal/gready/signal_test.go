@@ -0,0 +1,93 @@
+package gready_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gready"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	vals := fx.Vals()
+	var total uint64
+	for _, v := range vals {
+		total += v.Power
+	}
+
+	tr := gready.NewTracker(gready.TrackerConfig{Registry: &reg, MaxAge: 10})
+
+	s0, err := gready.NewSignal(ctx, &reg, fx.PrivVals[0].Signer, 5, []string{"v2", "v1", "v2"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1", "v2"}, s0.Features)
+	require.NoError(t, tr.Record(s0, vals))
+
+	s1, err := gready.NewSignal(ctx, &reg, fx.PrivVals[1].Signer, 5, []string{"v2"})
+	require.NoError(t, err)
+	require.NoError(t, tr.Record(s1, vals))
+
+	tally := tr.Tally(6, vals)
+	require.Equal(t, total, tally.TotalPower)
+	require.Len(t, tally.Features, 2)
+	require.Equal(t, "v1", tally.Features[0].Feature)
+	require.Equal(t, vals[0].Power, tally.Features[0].ReadyPower)
+	require.Equal(t, "v2", tally.Features[1].Feature)
+	require.Equal(t, vals[0].Power+vals[1].Power, tally.Features[1].ReadyPower)
+	require.Equal(t, [][]byte{reg.Marshal(vals[0].PubKey), reg.Marshal(vals[1].PubKey)}, tally.Features[1].Validators)
+
+	// A newer signal withdraws readiness; an older one is ignored.
+	withdrawn, err := gready.NewSignal(ctx, &reg, fx.PrivVals[0].Signer, 7, []string{"v2"})
+	require.NoError(t, err)
+	require.NoError(t, tr.Record(withdrawn, vals))
+	require.NoError(t, tr.Record(s0, vals))
+	tally = tr.Tally(8, vals)
+	require.Len(t, tally.Features, 1)
+	require.Equal(t, "v2", tally.Features[0].Feature)
+
+	// Stale signals are left out.
+	tally = tr.Tally(16, vals)
+	require.Len(t, tally.Features, 1)
+	require.Equal(t, vals[0].Power, tally.Features[0].ReadyPower)
+	require.Empty(t, tr.Tally(18, vals).Features)
+}
+
+func TestTracker_Record_invalid(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	vals := fx.Vals()
+	tr := gready.NewTracker(gready.TrackerConfig{Registry: &reg})
+
+	s, err := gready.NewSignal(ctx, &reg, fx.PrivVals[0].Signer, 1, []string{"v1"})
+	require.NoError(t, err)
+
+	forged := s
+	forged.Features = []string{"v1", "v2"}
+	require.Error(t, tr.Record(forged, vals))
+
+	unsorted := s
+	unsorted.Features = []string{"v2", "v1"}
+	require.Error(t, tr.Record(unsorted, vals))
+
+	require.Error(t, tr.Record(s, vals[1:]), "signal from non-validator")
+
+	require.Empty(t, tr.Tally(1, vals).Features)
+
+	_, err = gready.NewSignal(ctx, &reg, fx.PrivVals[0].Signer, 1, []string{""})
+	require.Error(t, err)
+}
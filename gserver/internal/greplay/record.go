@@ -0,0 +1,188 @@
+// Package greplay records inbound consensus messages to a file
+// and replays them into a consensus handler,
+// so that a problem seen on a production node
+// can be reproduced against a fresh engine.
+//
+// A replay file holds one JSON [Record] per line.
+package greplay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/gexchange"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// Record is a single recorded consensus message.
+type Record struct {
+	// When the message was received.
+	Time time.Time `json:"time"`
+
+	// The message, encoded with the recorder's codec.
+	Message json.RawMessage `json:"message"`
+
+	// The handler's feedback on the message when it was recorded,
+	// for comparison with the feedback during replay.
+	Feedback string `json:"feedback"`
+}
+
+// Recorder is a [tmconsensus.ConsensusHandler] that writes every message
+// it handles to a replay file, before passing it to another handler.
+// Messages are recorded regardless of the inner handler's feedback.
+type Recorder struct {
+	log *slog.Logger
+
+	inner tmconsensus.ConsensusHandler
+	codec tmcodec.Marshaler
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+var _ tmconsensus.ConsensusHandler = (*Recorder)(nil)
+
+// NewRecorder returns a new Recorder writing to w.
+// Each record is written with a single call to w.Write,
+// so records are not lost to buffering if the process crashes.
+func NewRecorder(log *slog.Logger, w io.Writer, codec tmcodec.Marshaler, inner tmconsensus.ConsensusHandler) *Recorder {
+	return &Recorder{
+		log: log,
+
+		inner: inner,
+		codec: codec,
+
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (r *Recorder) HandleProposedHeader(ctx context.Context, ph tmconsensus.ProposedHeader) gexchange.Feedback {
+	now := time.Now()
+	f := r.inner.HandleProposedHeader(ctx, ph)
+	r.record(now, tmcodec.ConsensusMessage{ProposedHeader: &ph}, f)
+	return f
+}
+
+func (r *Recorder) HandlePrevoteProofs(ctx context.Context, p tmconsensus.PrevoteSparseProof) gexchange.Feedback {
+	now := time.Now()
+	f := r.inner.HandlePrevoteProofs(ctx, p)
+	r.record(now, tmcodec.ConsensusMessage{PrevoteProof: &p}, f)
+	return f
+}
+
+func (r *Recorder) HandlePrecommitProofs(ctx context.Context, p tmconsensus.PrecommitSparseProof) gexchange.Feedback {
+	now := time.Now()
+	f := r.inner.HandlePrecommitProofs(ctx, p)
+	r.record(now, tmcodec.ConsensusMessage{PrecommitProof: &p}, f)
+	return f
+}
+
+func (r *Recorder) record(t time.Time, cm tmcodec.ConsensusMessage, f gexchange.Feedback) {
+	b, err := r.codec.MarshalConsensusMessage(cm)
+	if err != nil {
+		r.log.Warn("Failed to marshal consensus message for recording", "err", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(Record{Time: t, Message: b, Feedback: f.String()}); err != nil {
+		r.log.Warn("Failed to write consensus message record", "err", err)
+	}
+}
+
+// ReplayConfig is the configuration for [Replay].
+type ReplayConfig struct {
+	// Source of the replay file.
+	Source io.Reader
+
+	// Decodes the recorded messages.
+	Codec tmcodec.Unmarshaler
+
+	// Receives the replayed messages.
+	Handler tmconsensus.ConsensusHandler
+
+	// Speed relative to the recorded timing;
+	// 2 replays twice as fast as the messages were recorded.
+	// Zero replays every message immediately after the previous one.
+	Speed float64
+}
+
+// ReplayStats summarizes a call to [Replay].
+type ReplayStats struct {
+	Messages int
+
+	// How many messages received different feedback
+	// than they had when they were recorded.
+	FeedbackChanged int
+}
+
+// Replay reads every record from cfg.Source and passes its message to cfg.Handler,
+// preserving the recorded gaps between messages as adjusted by cfg.Speed.
+// It stops at the end of the source, on a malformed record,
+// or when ctx is canceled.
+func Replay(ctx context.Context, log *slog.Logger, cfg ReplayConfig) (ReplayStats, error) {
+	var stats ReplayStats
+
+	dec := json.NewDecoder(cfg.Source)
+	var prev time.Time
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return stats, nil
+			}
+			return stats, fmt.Errorf("failed to decode record %d: %w", stats.Messages+1, err)
+		}
+
+		if cfg.Speed > 0 && !prev.IsZero() {
+			if gap := rec.Time.Sub(prev); gap > 0 {
+				t := time.NewTimer(time.Duration(float64(gap) / cfg.Speed))
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return stats, context.Cause(ctx)
+				case <-t.C:
+				}
+			}
+		}
+		prev = rec.Time
+
+		if err := ctx.Err(); err != nil {
+			return stats, context.Cause(ctx)
+		}
+
+		var cm tmcodec.ConsensusMessage
+		if err := cfg.Codec.UnmarshalConsensusMessage(rec.Message, &cm); err != nil {
+			return stats, fmt.Errorf("failed to decode message in record %d: %w", stats.Messages+1, err)
+		}
+
+		var f gexchange.Feedback
+		switch {
+		case cm.ProposedHeader != nil:
+			f = cfg.Handler.HandleProposedHeader(ctx, *cm.ProposedHeader)
+		case cm.PrevoteProof != nil:
+			f = cfg.Handler.HandlePrevoteProofs(ctx, *cm.PrevoteProof)
+		case cm.PrecommitProof != nil:
+			f = cfg.Handler.HandlePrecommitProofs(ctx, *cm.PrecommitProof)
+		default:
+			return stats, fmt.Errorf("record %d has an empty message", stats.Messages+1)
+		}
+
+		stats.Messages++
+		if rec.Feedback != "" && f.String() != rec.Feedback {
+			stats.FeedbackChanged++
+			log.Info(
+				"Replayed message feedback differs from recording",
+				"record", stats.Messages, "recorded", rec.Feedback, "replayed", f.String(),
+			)
+		}
+	}
+}
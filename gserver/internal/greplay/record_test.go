@@ -0,0 +1,108 @@
+package greplay_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/greplay"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/gexchange"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+// handler records the messages it receives and returns fixed feedback.
+type handler struct {
+	feedback gexchange.Feedback
+
+	phs        []tmconsensus.ProposedHeader
+	prevotes   []tmconsensus.PrevoteSparseProof
+	precommits []tmconsensus.PrecommitSparseProof
+}
+
+func (h *handler) HandleProposedHeader(_ context.Context, ph tmconsensus.ProposedHeader) gexchange.Feedback {
+	h.phs = append(h.phs, ph)
+	return h.feedback
+}
+
+func (h *handler) HandlePrevoteProofs(_ context.Context, p tmconsensus.PrevoteSparseProof) gexchange.Feedback {
+	h.prevotes = append(h.prevotes, p)
+	return h.feedback
+}
+
+func (h *handler) HandlePrecommitProofs(_ context.Context, p tmconsensus.PrecommitSparseProof) gexchange.Feedback {
+	h.precommits = append(h.precommits, p)
+	return h.feedback
+}
+
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+	codec := tmjson.MarshalCodec{CryptoRegistry: &reg}
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	fx.SignProposal(ctx, &ph, 0)
+
+	prevotes := fx.SparsePrevoteProofMap(ctx, 1, 0, map[string][]int{
+		string(ph.Header.Hash): {0, 1, 2},
+	})
+	precommits := fx.SparsePrecommitProofMap(ctx, 1, 0, map[string][]int{
+		string(ph.Header.Hash): {0, 1, 2},
+	})
+	pubKeyHash, _ := fx.ValidatorHashes()
+
+	var buf bytes.Buffer
+	live := &handler{feedback: gexchange.FeedbackAccepted}
+	rec := greplay.NewRecorder(gtest.NewLogger(t), &buf, codec, live)
+
+	require.Equal(t, gexchange.FeedbackAccepted, rec.HandleProposedHeader(ctx, ph))
+	require.Equal(t, gexchange.FeedbackAccepted, rec.HandlePrevoteProofs(ctx, tmconsensus.PrevoteSparseProof{
+		Height: 1, Round: 0, PubKeyHash: pubKeyHash, Proofs: prevotes,
+	}))
+	require.Equal(t, gexchange.FeedbackAccepted, rec.HandlePrecommitProofs(ctx, tmconsensus.PrecommitSparseProof{
+		Height: 1, Round: 0, PubKeyHash: pubKeyHash, Proofs: precommits,
+	}))
+	require.Len(t, live.phs, 1)
+
+	// One record per line.
+	require.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	// The replay gives different feedback for every message.
+	replayed := &handler{feedback: gexchange.FeedbackIgnored}
+	stats, err := greplay.Replay(ctx, gtest.NewLogger(t), greplay.ReplayConfig{
+		Source:  &buf,
+		Codec:   codec,
+		Handler: replayed,
+	})
+	require.NoError(t, err)
+	require.Equal(t, greplay.ReplayStats{Messages: 3, FeedbackChanged: 3}, stats)
+
+	require.Len(t, replayed.phs, 1)
+	require.Equal(t, ph.Header.Hash, replayed.phs[0].Header.Hash)
+	require.Equal(t, live.prevotes, replayed.prevotes)
+	require.Equal(t, live.precommits, replayed.precommits)
+}
+
+func TestReplay_malformed(t *testing.T) {
+	t.Parallel()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+
+	_, err := greplay.Replay(context.Background(), gtest.NewLogger(t), greplay.ReplayConfig{
+		Source:  strings.NewReader("{\"time\":\"2024-01-01T00:00:00Z\",\"message\":{}}\n"),
+		Codec:   tmjson.MarshalCodec{CryptoRegistry: &reg},
+		Handler: &handler{},
+	})
+	require.ErrorContains(t, err, "record 1")
+}
@@ -22,12 +22,21 @@ import (
 //  3. The mirror is performing catchup,
 //     and the block data may or may not be immediately available.
 //
-// The first downside of the RequestCache is that entries must be manually purged.
-// If multiple proposed blocks are received, the driver is designed to only purge
-// the block data for the block it finalizes.
-// This means the consensus strategy should purge the other blocks;
-// otherwise the other block data will never be garbage collected.
-// (Perhaps this implies this cache is a good candidate for a weak map in Go.)
+// Once the driver finalizes a height, it calls [*RequestCache.PruneThrough]
+// to drop every entry for that height and earlier,
+// including the block data of proposed blocks that were not committed.
+//
+// If [RequestCacheConfig.MaxBytes] is set, the cache also bounds
+// the encoded size of the ready block data it retains,
+// by evicting entries for the heights furthest in the future.
+// Only speculative entries, for proposed blocks, are evicted:
+// an evicted proposed block is requested again
+// if the consensus strategy considers it at its height.
+// Entries for committed blocks, added with [*RequestCache.SetCommitted],
+// are never requested again, so they are kept until pruned.
+// Entries for the next height to finalize, and entries still in flight,
+// are never evicted either, so the budget may be exceeded
+// until the driver catches up.
 //
 // There are some possible race conditions if a block data request
 // may be created from multiple originators, or if the same block data is present in different requests.
@@ -39,6 +48,39 @@ type RequestCache struct {
 	// the mutex is held for a predictably short time.
 	mu sync.Mutex
 	rs map[string]*BlockDataRequest
+
+	maxBytes int64
+
+	// Height through which entries have been pruned.
+	pruned uint64
+
+	evicted uint64
+}
+
+// RequestCacheConfig is the configuration for [NewRequestCache].
+type RequestCacheConfig struct {
+	// Maximum total size in bytes of the encoded transactions
+	// of ready entries in the cache.
+	// The limit is enforced whenever an entry is added.
+	// Zero means no limit.
+	MaxBytes int64
+}
+
+// RequestCacheStats is a snapshot of a [RequestCache]'s contents.
+type RequestCacheStats struct {
+	// Number of entries in the cache,
+	// and how many of them are still in flight.
+	Entries, InFlight int
+
+	// Total size of the encoded transactions of ready entries,
+	// and the configured limit, which is zero if unlimited.
+	Bytes, MaxBytes int64
+
+	// Height through which entries have been pruned.
+	PrunedHeight uint64
+
+	// Number of entries evicted to stay within MaxBytes.
+	Evicted uint64
 }
 
 // BlockDataRequest is the information associated with a request for block data,
@@ -55,11 +97,17 @@ type BlockDataRequest struct {
 	// This value is used to write into [gcstore.BlockDataStore],
 	// so identical data may be hosted to other peers.
 	EncodedTransactions []byte
+
+	// Whether the data belongs to a committed block,
+	// so that it must not be evicted.
+	committed bool
 }
 
-func NewRequestCache() *RequestCache {
+func NewRequestCache(cfg RequestCacheConfig) *RequestCache {
 	return &RequestCache{
 		rs: make(map[string]*BlockDataRequest),
+
+		maxBytes: cfg.MaxBytes,
 	}
 }
 
@@ -80,6 +128,8 @@ func (c *RequestCache) SetInFlight(dataID string, req *BlockDataRequest) {
 	}
 
 	c.rs[dataID] = req
+
+	c.evictOverBudget()
 }
 
 // SetImmediatelyAvailable marks the given dataID as ready to read,
@@ -100,17 +150,160 @@ func (c *RequestCache) SetImmediatelyAvailable(
 	c.SetInFlight(dataID, &req)
 }
 
+// SetCommitted is like [*RequestCache.SetImmediatelyAvailable],
+// for the block data of a block already known to be committed,
+// such as one fetched during catchup.
+// The entry is never evicted to stay within the configured MaxBytes,
+// as nothing would request it again before the driver finalizes it.
+func (c *RequestCache) SetCommitted(
+	dataID string, txs []transaction.Tx, encodedTxs []byte,
+) {
+	ch := make(chan struct{})
+	close(ch)
+	req := BlockDataRequest{
+		Ready:               ch,
+		Transactions:        txs,
+		EncodedTransactions: encodedTxs,
+
+		committed: true,
+	}
+
+	c.SetInFlight(dataID, &req)
+}
+
 // Purge removes the entry keyed by dataID from the cache.
-// If no such entry exists, Purge panics.
+// The entry may have already been pruned or evicted,
+// so it is not an error for the entry to be missing.
 func (c *RequestCache) Purge(dataID string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if _, ok := c.rs[dataID]; !ok {
-		panic(fmt.Errorf("BUG: attempted to purge nonexistent data ID %q", dataID))
+	delete(c.rs, dataID)
+}
+
+// PruneThrough removes every entry whose data ID refers to height h or earlier.
+// Entries whose keys are not well-formed data IDs are left in place.
+func (c *RequestCache) PruneThrough(h uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id := range c.rs {
+		if eh, _, ok := idHeightRound(id); ok && eh <= h {
+			delete(c.rs, id)
+		}
 	}
 
-	delete(c.rs, dataID)
+	c.pruned = max(c.pruned, h)
+}
+
+// Stats returns a snapshot of the cache's contents.
+func (c *RequestCache) Stats() RequestCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := RequestCacheStats{
+		Entries: len(c.rs),
+
+		MaxBytes: c.maxBytes,
+
+		PrunedHeight: c.pruned,
+
+		Evicted: c.evicted,
+	}
+	for _, r := range c.rs {
+		if n, ok := readyBytes(r); ok {
+			s.Bytes += n
+		} else {
+			s.InFlight++
+		}
+	}
+	return s
+}
+
+// evictOverBudget removes ready, uncommitted entries for the furthest heights and rounds
+// until the ready entries fit in c.maxBytes,
+// or until no more entries are eligible for eviction.
+// The caller must hold c.mu.
+func (c *RequestCache) evictOverBudget() {
+	if c.maxBytes <= 0 {
+		return
+	}
+
+	var total int64
+	for _, r := range c.rs {
+		if n, ok := readyBytes(r); ok {
+			total += n
+		}
+	}
+
+	for total > c.maxBytes {
+		var (
+			victim     string
+			victimSize int64
+			vh         uint64
+			vr         uint32
+		)
+		for id, r := range c.rs {
+			if r.committed {
+				continue
+			}
+			h, rd, ok := idHeightRound(id)
+			if !ok || h <= c.pruned+1 {
+				// Malformed, or needed for the next finalization.
+				continue
+			}
+			n, ok := readyBytes(r)
+			if !ok {
+				continue
+			}
+			if victim == "" || h > vh || (h == vh && rd > vr) {
+				victim, victimSize, vh, vr = id, n, h, rd
+			}
+		}
+		if victim == "" {
+			return
+		}
+
+		delete(c.rs, victim)
+		total -= victimSize
+		c.evicted++
+	}
+}
+
+// readyBytes reports the size of r's encoded transactions,
+// and whether r is ready.
+func readyBytes(r *BlockDataRequest) (int64, bool) {
+	select {
+	case <-r.Ready:
+		return int64(len(r.EncodedTransactions)), true
+	default:
+		return 0, false
+	}
+}
+
+// idHeightRound parses the height and round from a data ID.
+func idHeightRound(id string) (uint64, uint32, bool) {
+	h, r, _, _, _, err := ParseDataID(id)
+	if err != nil {
+		return 0, 0, false
+	}
+	return h, r, true
+}
+
+// InFlight returns the number of entries whose block data is not yet ready.
+func (c *RequestCache) InFlight() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, r := range c.rs {
+		select {
+		case <-r.Ready:
+		default:
+			n++
+		}
+	}
+	return n
 }
 
 // Get returns the BlockDataRequest corresponding to dataID.
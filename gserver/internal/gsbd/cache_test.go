@@ -3,6 +3,7 @@ package gsbd_test
 import (
 	"testing"
 
+	"cosmossdk.io/core/transaction"
 	"github.com/gordian-engine/gcosmos/gserver/gservertest"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
 	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
@@ -12,7 +13,7 @@ import (
 func TestRequestCache_SetInFlight(t *testing.T) {
 	t.Parallel()
 
-	c := gsbd.NewRequestCache()
+	c := gsbd.NewRequestCache(gsbd.RequestCacheConfig{})
 
 	ready := make(chan struct{})
 	bdr := gsbd.BlockDataRequest{
@@ -35,3 +36,132 @@ func TestRequestCache_SetInFlight(t *testing.T) {
 	require.Equal(t, bdr.Transactions, got.Transactions)
 	require.Equal(t, bdr.EncodedTransactions, got.EncodedTransactions)
 }
+
+func TestRequestCache_PruneThrough(t *testing.T) {
+	t.Parallel()
+
+	c := gsbd.NewRequestCache(gsbd.RequestCacheConfig{})
+
+	txs := []transaction.Tx{gservertest.NewHashOnlyTransaction(1)}
+	id := func(h uint64, r uint32) string {
+		return gsbd.DataID(h, r, 8, txs)
+	}
+
+	c.SetImmediatelyAvailable(id(1, 0), txs, make([]byte, 8))
+	c.SetImmediatelyAvailable(id(1, 1), txs, make([]byte, 8))
+	c.SetImmediatelyAvailable(id(2, 0), txs, make([]byte, 8))
+	c.SetInFlight(id(3, 0), &gsbd.BlockDataRequest{Ready: make(chan struct{})})
+
+	s := c.Stats()
+	require.Equal(t, 4, s.Entries)
+	require.Equal(t, 1, s.InFlight)
+	require.Equal(t, int64(24), s.Bytes)
+
+	c.PruneThrough(1)
+
+	_, ok := c.Get(id(1, 0))
+	require.False(t, ok)
+	_, ok = c.Get(id(1, 1))
+	require.False(t, ok)
+	_, ok = c.Get(id(2, 0))
+	require.True(t, ok)
+
+	s = c.Stats()
+	require.Equal(t, 2, s.Entries)
+	require.Equal(t, int64(8), s.Bytes)
+	require.Equal(t, uint64(1), s.PrunedHeight)
+
+	// Purging a pruned entry is not an error.
+	c.Purge(id(1, 0))
+}
+
+func TestRequestCache_MaxBytes(t *testing.T) {
+	t.Parallel()
+
+	c := gsbd.NewRequestCache(gsbd.RequestCacheConfig{MaxBytes: 20})
+	c.PruneThrough(1)
+
+	txs := []transaction.Tx{gservertest.NewHashOnlyTransaction(1)}
+	id := func(h uint64, r uint32) string {
+		return gsbd.DataID(h, r, 10, txs)
+	}
+
+	// The next height to finalize is never evicted, even beyond the budget.
+	c.SetImmediatelyAvailable(id(2, 0), txs, make([]byte, 10))
+	c.SetImmediatelyAvailable(id(2, 1), txs, make([]byte, 10))
+	c.SetImmediatelyAvailable(id(2, 2), txs, make([]byte, 10))
+	require.Equal(t, int64(30), c.Stats().Bytes)
+	require.Zero(t, c.Stats().Evicted)
+
+	// A later height over the budget is evicted immediately.
+	c.SetImmediatelyAvailable(id(4, 0), txs, make([]byte, 10))
+	_, ok := c.Get(id(4, 0))
+	require.False(t, ok)
+	require.Equal(t, uint64(1), c.Stats().Evicted)
+
+	// Once the earlier height is pruned, later heights fit.
+	c.PruneThrough(2)
+	c.SetImmediatelyAvailable(id(4, 0), txs, make([]byte, 10))
+	c.SetImmediatelyAvailable(id(3, 0), txs, make([]byte, 10))
+
+	// In-flight entries are not counted or evicted.
+	ready := make(chan struct{})
+	c.SetInFlight(id(5, 0), &gsbd.BlockDataRequest{Ready: ready})
+
+	// Over the budget, the furthest height goes first.
+	c.SetImmediatelyAvailable(id(3, 1), txs, make([]byte, 10))
+	_, ok = c.Get(id(4, 0))
+	require.False(t, ok)
+	_, ok = c.Get(id(3, 0))
+	require.True(t, ok)
+	_, ok = c.Get(id(3, 1))
+	require.True(t, ok)
+	_, ok = c.Get(id(5, 0))
+	require.True(t, ok)
+
+	s := c.Stats()
+	require.Equal(t, int64(20), s.Bytes)
+	require.Equal(t, int64(20), s.MaxBytes)
+	require.Equal(t, 1, s.InFlight)
+	require.Equal(t, uint64(2), s.Evicted)
+}
+
+func TestRequestCache_MaxBytes_committed(t *testing.T) {
+	t.Parallel()
+
+	c := gsbd.NewRequestCache(gsbd.RequestCacheConfig{MaxBytes: 20})
+	c.PruneThrough(1)
+
+	txs := []transaction.Tx{gservertest.NewHashOnlyTransaction(1)}
+	id := func(h uint64, r uint32) string {
+		return gsbd.DataID(h, r, 10, txs)
+	}
+
+	// Catchup runs ahead of the driver, filling the budget with committed blocks.
+	for h := uint64(2); h <= 6; h++ {
+		c.SetCommitted(id(h, 0), txs, make([]byte, 10))
+	}
+	require.Equal(t, int64(50), c.Stats().Bytes)
+	require.Zero(t, c.Stats().Evicted)
+
+	// A speculative proposal beyond them is the one evicted.
+	c.SetImmediatelyAvailable(id(7, 0), txs, make([]byte, 10))
+	_, ok := c.Get(id(7, 0))
+	require.False(t, ok)
+	require.Equal(t, uint64(1), c.Stats().Evicted)
+
+	// The driver then finalizes each committed height in turn,
+	// finding its block data in the cache before pruning it.
+	for h := uint64(2); h <= 6; h++ {
+		r, ok := c.Get(id(h, 0))
+		require.True(t, ok, "height %d", h)
+		require.Len(t, r.EncodedTransactions, 10)
+		_ = gtest.IsSending(t, r.Ready)
+
+		c.PruneThrough(h)
+	}
+
+	s := c.Stats()
+	require.Zero(t, s.Entries)
+	require.Equal(t, uint64(1), s.Evicted)
+}
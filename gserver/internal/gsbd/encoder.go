@@ -2,6 +2,7 @@ package gsbd
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -47,6 +48,25 @@ func EncodeBlockData(w io.Writer, txs []transaction.Tx) (
 	return compressEncodedBlockData(w, j)
 }
 
+// MaxTxsForDataSize returns the number of leading transactions in txs
+// whose decompressed data size, as returned by [EncodeBlockData],
+// is at most maxSize.
+func MaxTxsForDataSize(txs []transaction.Tx, maxSize int64) int {
+	// Mirrors the JSON encoding of a [][]byte:
+	// brackets around comma-separated, quoted base64 strings.
+	size := int64(2)
+	for i, tx := range txs {
+		size += int64(base64.StdEncoding.EncodedLen(len(tx.Bytes()))) + 2
+		if i > 0 {
+			size++
+		}
+		if size > maxSize {
+			return i
+		}
+	}
+	return len(txs)
+}
+
 func compressEncodedBlockData(w io.Writer, j []byte) (int, error) {
 	var header byte
 	var szBuf []byte
@@ -66,3 +66,22 @@ func TestEncodeBlockData_panicsOnEmptyTxs(t *testing.T) {
 		_, _ = gsbd.EncodeBlockData(new(bytes.Buffer), nil)
 	})
 }
+
+func TestMaxTxsForDataSize(t *testing.T) {
+	t.Parallel()
+
+	txs := make([]transaction.Tx, 5)
+	for i := range txs {
+		txs[i] = gservertest.NewHashOnlyTransaction(uint64(i))
+	}
+
+	for n := 1; n <= len(txs); n++ {
+		sz, err := gsbd.EncodeBlockData(new(bytes.Buffer), txs[:n])
+		require.NoError(t, err)
+
+		require.Equal(t, n, gsbd.MaxTxsForDataSize(txs, int64(sz)))
+		require.Equal(t, n-1, gsbd.MaxTxsForDataSize(txs, int64(sz-1)))
+	}
+
+	require.Zero(t, gsbd.MaxTxsForDataSize(txs, 0))
+}
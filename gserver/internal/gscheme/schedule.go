@@ -0,0 +1,190 @@
+// Package gscheme switches the consensus signature and hash schemes
+// at coordinated heights, to allow future cryptographic migrations.
+//
+// Each pair of schemes is identified by a version number in [Versions].
+// The chain starts at version 1, and the genesis file may declare upgrades,
+// each naming a version and the height from which it applies:
+//
+//	"gordian": {
+//	  "scheme_upgrades": [{"height": "1000", "version": 2}]
+//	}
+//
+// Because every validator reads the same genesis file,
+// every validator switches at the same height.
+//
+// The [Schedule]'s schemes dispatch on the height of the header or vote target,
+// so proposals and votes for a height are always signed and verified
+// under the version in effect at that height, regardless of the local node's progress.
+//
+// Validator set hashing ([tmconsensus.HashScheme.PubKeys] and [tmconsensus.HashScheme.VotePowers])
+// is not given a height, so it always uses version 1.
+// A new version must therefore hash validator sets identically to version 1.
+package gscheme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+)
+
+// Schemes is a versioned pair of signature and hash schemes.
+type Schemes struct {
+	Signature tmconsensus.SignatureScheme
+	Hash      tmconsensus.HashScheme
+}
+
+// Versions are the scheme versions known to this binary.
+// Existing entries must never change,
+// or nodes would disagree about past heights.
+var Versions = map[uint32]Schemes{
+	1: {
+		Signature: tmconsensustest.SimpleSignatureScheme{},
+		Hash:      tmconsensustest.SimpleHashScheme{},
+	},
+}
+
+// Upgrade declares that Version applies from Height onward.
+type Upgrade struct {
+	// Encoded as a string, like other heights in the genesis file.
+	Height uint64 `json:"height,string"`
+
+	Version uint32 `json:"version"`
+}
+
+// Schedule maps heights to [Schemes].
+type Schedule struct {
+	base     Schemes
+	upgrades []Upgrade
+	versions map[uint32]Schemes
+}
+
+// NewSchedule returns a Schedule starting at version 1 of versions
+// and applying upgrades, which must be in strictly increasing height order.
+func NewSchedule(versions map[uint32]Schemes, upgrades []Upgrade) (*Schedule, error) {
+	base, ok := versions[1]
+	if !ok {
+		return nil, fmt.Errorf("scheme version 1 is not defined")
+	}
+
+	for i, u := range upgrades {
+		if _, ok := versions[u.Version]; !ok {
+			return nil, fmt.Errorf(
+				"scheme upgrade at height %d names unknown version %d; this binary may be too old",
+				u.Height, u.Version,
+			)
+		}
+		if i > 0 && u.Height <= upgrades[i-1].Height {
+			return nil, fmt.Errorf(
+				"scheme upgrade heights must be strictly increasing; got %d after %d",
+				u.Height, upgrades[i-1].Height,
+			)
+		}
+	}
+
+	return &Schedule{
+		base:     base,
+		upgrades: slices.Clone(upgrades),
+		versions: versions,
+	}, nil
+}
+
+// LoadGenesisSchedule returns the Schedule declared in the genesis file at path,
+// using the known [Versions].
+// A genesis file without a gordian section yields version 1 at every height.
+func LoadGenesisSchedule(path string) (*Schedule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open genesis file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadGenesisSchedule(f)
+}
+
+// ReadGenesisSchedule is like [LoadGenesisSchedule] but reads the genesis JSON from r.
+func ReadGenesisSchedule(r io.Reader) (*Schedule, error) {
+	var g struct {
+		Gordian struct {
+			SchemeUpgrades []Upgrade `json:"scheme_upgrades"`
+		} `json:"gordian"`
+	}
+	if err := json.NewDecoder(r).Decode(&g); err != nil {
+		return nil, fmt.Errorf("failed to parse scheme upgrades from genesis: %w", err)
+	}
+
+	return NewSchedule(Versions, g.Gordian.SchemeUpgrades)
+}
+
+// Upgrades returns a copy of the upgrades in s, in increasing height order.
+func (s *Schedule) Upgrades() []Upgrade {
+	return slices.Clone(s.upgrades)
+}
+
+// VersionAt returns the scheme version in effect at height.
+func (s *Schedule) VersionAt(height uint64) uint32 {
+	v := uint32(1)
+	for _, u := range s.upgrades {
+		if u.Height > height {
+			break
+		}
+		v = u.Version
+	}
+	return v
+}
+
+// At returns the schemes in effect at height.
+func (s *Schedule) At(height uint64) Schemes {
+	return s.versions[s.VersionAt(height)]
+}
+
+// SignatureScheme returns a [tmconsensus.SignatureScheme]
+// delegating to the scheme in effect at each message's height.
+func (s *Schedule) SignatureScheme() tmconsensus.SignatureScheme {
+	return signatureScheme{s: s}
+}
+
+// HashScheme returns a [tmconsensus.HashScheme]
+// delegating block hashes to the scheme in effect at the header's height.
+func (s *Schedule) HashScheme() tmconsensus.HashScheme {
+	return hashScheme{s: s}
+}
+
+type signatureScheme struct {
+	s *Schedule
+}
+
+func (ss signatureScheme) WriteProposalSigningContent(
+	w io.Writer, h tmconsensus.Header, round uint32, pbAnnotations tmconsensus.Annotations,
+) (int, error) {
+	return ss.s.At(h.Height).Signature.WriteProposalSigningContent(w, h, round, pbAnnotations)
+}
+
+func (ss signatureScheme) WritePrevoteSigningContent(w io.Writer, vt tmconsensus.VoteTarget) (int, error) {
+	return ss.s.At(vt.Height).Signature.WritePrevoteSigningContent(w, vt)
+}
+
+func (ss signatureScheme) WritePrecommitSigningContent(w io.Writer, vt tmconsensus.VoteTarget) (int, error) {
+	return ss.s.At(vt.Height).Signature.WritePrecommitSigningContent(w, vt)
+}
+
+type hashScheme struct {
+	s *Schedule
+}
+
+func (hs hashScheme) Block(h tmconsensus.Header) ([]byte, error) {
+	return hs.s.At(h.Height).Hash.Block(h)
+}
+
+func (hs hashScheme) PubKeys(keys []gcrypto.PubKey) ([]byte, error) {
+	return hs.s.base.Hash.PubKeys(keys)
+}
+
+func (hs hashScheme) VotePowers(powers []uint64) ([]byte, error) {
+	return hs.s.base.Hash.VotePowers(powers)
+}
@@ -0,0 +1,100 @@
+package gscheme_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gscheme"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+// prefixSignatureScheme is a SimpleSignatureScheme with a distinguishing prefix.
+type prefixSignatureScheme struct {
+	tmconsensustest.SimpleSignatureScheme
+}
+
+func (s prefixSignatureScheme) WritePrevoteSigningContent(w io.Writer, vt tmconsensus.VoteTarget) (int, error) {
+	n, err := io.WriteString(w, "v2:")
+	if err != nil {
+		return n, err
+	}
+	m, err := s.SimpleSignatureScheme.WritePrevoteSigningContent(w, vt)
+	return n + m, err
+}
+
+func testVersions() map[uint32]gscheme.Schemes {
+	return map[uint32]gscheme.Schemes{
+		1: gscheme.Versions[1],
+		2: {
+			Signature: prefixSignatureScheme{},
+			Hash:      tmconsensustest.SimpleHashScheme{},
+		},
+	}
+}
+
+func TestSchedule_VersionAt(t *testing.T) {
+	t.Parallel()
+
+	s, err := gscheme.NewSchedule(testVersions(), []gscheme.Upgrade{
+		{Height: 10, Version: 2},
+		{Height: 20, Version: 1},
+	})
+	require.NoError(t, err)
+
+	for h, want := range map[uint64]uint32{1: 1, 9: 1, 10: 2, 19: 2, 20: 1, 100: 1} {
+		require.Equal(t, want, s.VersionAt(h), "height %d", h)
+	}
+}
+
+func TestSchedule_SignatureScheme(t *testing.T) {
+	t.Parallel()
+
+	s, err := gscheme.NewSchedule(testVersions(), []gscheme.Upgrade{{Height: 10, Version: 2}})
+	require.NoError(t, err)
+	ss := s.SignatureScheme()
+
+	var before, after bytes.Buffer
+	_, err = ss.WritePrevoteSigningContent(&before, tmconsensus.VoteTarget{Height: 9, BlockHash: "x"})
+	require.NoError(t, err)
+	_, err = ss.WritePrevoteSigningContent(&after, tmconsensus.VoteTarget{Height: 10, BlockHash: "x"})
+	require.NoError(t, err)
+
+	require.False(t, strings.HasPrefix(before.String(), "v2:"))
+	require.True(t, strings.HasPrefix(after.String(), "v2:"))
+}
+
+func TestNewSchedule_invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := gscheme.NewSchedule(testVersions(), []gscheme.Upgrade{{Height: 10, Version: 3}})
+	require.ErrorContains(t, err, "unknown version 3")
+
+	_, err = gscheme.NewSchedule(testVersions(), []gscheme.Upgrade{
+		{Height: 10, Version: 2},
+		{Height: 10, Version: 1},
+	})
+	require.ErrorContains(t, err, "strictly increasing")
+}
+
+func TestReadGenesisSchedule(t *testing.T) {
+	t.Parallel()
+
+	s, err := gscheme.ReadGenesisSchedule(strings.NewReader(`{"chain_id": "test"}`))
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), s.VersionAt(1_000_000))
+
+	_, err = gscheme.ReadGenesisSchedule(strings.NewReader(
+		`{"chain_id": "test", "gordian": {"scheme_upgrades": [{"height": "10", "version": 2}]}}`,
+	))
+	require.ErrorContains(t, err, "unknown version 2")
+
+	s, err = gscheme.ReadGenesisSchedule(strings.NewReader(
+		`{"chain_id": "test", "gordian": {"scheme_upgrades": [{"height": "10", "version": 1}]}}`,
+	))
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), s.VersionAt(10))
+}
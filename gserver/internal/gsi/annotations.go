@@ -8,4 +8,8 @@ type ProposalDriverAnnotation struct {
 	// Locations is a slice of addresses on where the proposer
 	// expects the blodk data to be retrievable.
 	Locations []gsbd.Location
+
+	// The name of the external [BlockBuilder] that supplied the block's transactions,
+	// or empty if the proposer used its own transaction buffer.
+	Builder string `json:",omitempty"`
 }
@@ -0,0 +1,134 @@
+package gsi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"cosmossdk.io/core/transaction"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
+)
+
+// BlockBuilder supplies the transactions for a block we are about to propose,
+// as an alternative to reaping our own transaction buffer.
+//
+// The [ConsensusStrategy] simulates the returned transactions
+// before proposing them,
+// and falls back to the local transaction buffer
+// if the builder fails, returns invalid transactions,
+// or does not respond in time.
+type BlockBuilder interface {
+	// Name identifies the builder in logs and in the proposal annotations.
+	Name() string
+
+	// BuildBlock returns the transactions to propose at height h and round r.
+	// The builder must respect ctx's deadline.
+	BuildBlock(ctx context.Context, h uint64, r uint32) ([]transaction.Tx, error)
+}
+
+// defaultMaxBuilderResponseBytes bounds the response read from an [HTTPBlockBuilder]
+// at heights where the consensus parameters do not limit the block size.
+const defaultMaxBuilderResponseBytes = 64 << 20
+
+// HTTPBlockBuilder is a [BlockBuilder] that requests block contents
+// from an external service over HTTP.
+//
+// The service receives a POST of a JSON object with Height and Round fields,
+// and must respond with a JSON object whose Txs field
+// is an array of base64-encoded transaction bytes.
+type HTTPBlockBuilder struct {
+	url    string
+	name   string
+	client *http.Client
+	txc    transaction.Codec[transaction.Tx]
+	params *gparams.Schedule
+}
+
+// NewHTTPBlockBuilder returns an HTTPBlockBuilder that posts requests to rawURL
+// and decodes the returned transactions with txc.
+// Responses are limited according to the maximum block size in params,
+// which may be nil.
+func NewHTTPBlockBuilder(
+	rawURL string, txc transaction.Codec[transaction.Tx], params *gparams.Schedule,
+) (*HTTPBlockBuilder, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse block builder URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("block builder URL must use http or https (got %q)", u.Scheme)
+	}
+
+	return &HTTPBlockBuilder{
+		url:    rawURL,
+		name:   u.Host,
+		client: new(http.Client),
+		txc:    txc,
+		params: params,
+	}, nil
+}
+
+// Name returns the host of the builder's URL.
+func (b *HTTPBlockBuilder) Name() string {
+	return b.name
+}
+
+func (b *HTTPBlockBuilder) BuildBlock(ctx context.Context, h uint64, r uint32) ([]transaction.Tx, error) {
+	reqBody, err := json.Marshal(struct {
+		Height uint64
+		Round  uint32
+	}{Height: h, Round: r})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal build request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request block from builder: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("builder responded with status %d: %s", resp.StatusCode, msg)
+	}
+
+	var out struct {
+		Txs [][]byte
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, b.maxResponseBytes(h))).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode builder response: %w", err)
+	}
+
+	txs := make([]transaction.Tx, len(out.Txs))
+	for i, txBytes := range out.Txs {
+		tx, err := b.txc.Decode(txBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode builder transaction at index %d: %w", i, err)
+		}
+		txs[i] = tx
+	}
+
+	return txs, nil
+}
+
+// maxResponseBytes returns how much of a response to read for a block at height h.
+// Base64 encoding grows the transactions by a third,
+// so twice the maximum block size, plus a little,
+// leaves room for the encoding and the surrounding JSON.
+func (b *HTTPBlockBuilder) maxResponseBytes(h uint64) int64 {
+	if limit := b.params.At(h).MaxBlockBytes; limit > 0 {
+		return 2*limit + 1024
+	}
+	return defaultMaxBuilderResponseBytes
+}
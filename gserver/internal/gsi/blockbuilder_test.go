@@ -0,0 +1,146 @@
+package gsi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cosmossdk.io/core/transaction"
+	"github.com/gordian-engine/gcosmos/gserver/gservertest"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPBlockBuilder(t *testing.T) {
+	t.Parallel()
+
+	tx1 := gservertest.NewHashOnlyTransaction(1)
+	tx2 := gservertest.NewHashOnlyTransaction(2)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var body struct {
+				Height uint64
+				Round  uint32
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Height != 3 || body.Round != 1 {
+				http.Error(w, "unexpected request", http.StatusBadRequest)
+				return
+			}
+			writeBuilderTxs(w, tx1.Bytes(), tx2.Bytes())
+		}))
+		defer srv.Close()
+
+		b, err := gsi.NewHTTPBlockBuilder(srv.URL, gservertest.HashOnlyTransactionDecoder{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, srv.Listener.Addr().String(), b.Name())
+
+		txs, err := b.BuildBlock(context.Background(), 3, 1)
+		require.NoError(t, err)
+		require.Equal(t, []transaction.Tx{tx1, tx2}, txs)
+	})
+
+	t.Run("non-200 status", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "no block for you", http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		b, err := gsi.NewHTTPBlockBuilder(srv.URL, gservertest.HashOnlyTransactionDecoder{}, nil)
+		require.NoError(t, err)
+
+		_, err = b.BuildBlock(context.Background(), 1, 0)
+		require.ErrorContains(t, err, "status 503")
+		require.ErrorContains(t, err, "no block for you")
+	})
+
+	t.Run("undecodable transaction", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			writeBuilderTxs(w, tx1.Bytes(), []byte("too short"))
+		}))
+		defer srv.Close()
+
+		b, err := gsi.NewHTTPBlockBuilder(srv.URL, gservertest.HashOnlyTransactionDecoder{}, nil)
+		require.NoError(t, err)
+
+		_, err = b.BuildBlock(context.Background(), 1, 0)
+		require.ErrorContains(t, err, "index 1")
+	})
+
+	t.Run("response beyond maximum block size", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "consensus_params.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"Height":"1","Params":{"MaxBlockBytes":64}}]`), 0o600))
+		params, err := gparams.NewSchedule(gtest.NewLogger(t), path)
+		require.NoError(t, err)
+
+		many := make([][]byte, 100)
+		for i := range many {
+			many[i] = tx1.Bytes()
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			writeBuilderTxs(w, many...)
+		}))
+		defer srv.Close()
+
+		b, err := gsi.NewHTTPBlockBuilder(srv.URL, gservertest.HashOnlyTransactionDecoder{}, params)
+		require.NoError(t, err)
+
+		_, err = b.BuildBlock(context.Background(), 1, 0)
+		require.ErrorContains(t, err, "failed to decode builder response")
+
+		// Before the limit takes effect, the same response is accepted.
+		txs, err := b.BuildBlock(context.Background(), 0, 0)
+		require.NoError(t, err)
+		require.Len(t, txs, len(many))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			select {
+			case <-release:
+			case <-req.Context().Done():
+			}
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		b, err := gsi.NewHTTPBlockBuilder(srv.URL, gservertest.HashOnlyTransactionDecoder{}, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = b.BuildBlock(ctx, 1, 0)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestNewHTTPBlockBuilder_scheme(t *testing.T) {
+	t.Parallel()
+
+	_, err := gsi.NewHTTPBlockBuilder("ftp://builder.example", gservertest.HashOnlyTransactionDecoder{}, nil)
+	require.ErrorContains(t, err, "http or https")
+}
+
+// writeBuilderTxs writes a block builder response containing txs.
+func writeBuilderTxs(w http.ResponseWriter, txs ...[]byte) {
+	_ = json.NewEncoder(w).Encode(struct{ Txs [][]byte }{Txs: txs})
+}
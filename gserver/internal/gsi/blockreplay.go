@@ -0,0 +1,103 @@
+package gsi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"cosmossdk.io/core/transaction"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+)
+
+// replayLogInterval is the minimum time between progress logs
+// while replaying committed blocks to the app.
+const replayLogInterval = 5 * time.Second
+
+// blockReplay tracks the replay of committed blocks to the app,
+// when the state machine restarts below the mirror's committing height
+// and finalizes the already-committed blocks in order.
+//
+// It is only accessed from the driver's goroutine.
+type blockReplay struct {
+	// The latest committing height reported by the engine's lag state.
+	committingHeight uint64
+
+	// Zero unless a replay is in progress.
+	start       time.Time
+	firstHeight uint64
+
+	lastLog time.Time
+}
+
+// observeReplay records the finalization of height,
+// logging the replay progress when height is below the committing height.
+func (d *Driver) observeReplay(height uint64) {
+	r := &d.replay
+	if height >= r.committingHeight {
+		if !r.start.IsZero() {
+			d.log.Info(
+				"Finished replaying committed blocks",
+				"from_height", r.firstHeight, "to_height", height-1,
+				"dur", time.Since(r.start),
+			)
+			r.start = time.Time{}
+		}
+		return
+	}
+
+	now := time.Now()
+	if r.start.IsZero() {
+		r.start = now
+		r.firstHeight = height
+		r.lastLog = now
+		d.log.Info(
+			"Replaying committed blocks to app",
+			"from_height", height, "committing_height", r.committingHeight,
+		)
+		return
+	}
+
+	if now.Sub(r.lastLog) < replayLogInterval {
+		return
+	}
+	r.lastLog = now
+
+	done := height - r.firstHeight
+	d.log.Info(
+		"Replaying committed blocks to app",
+		"height", height,
+		"committing_height", r.committingHeight,
+		"remaining", r.committingHeight-height,
+		"blocks_per_sec", float64(done)/now.Sub(r.start).Seconds(),
+	)
+}
+
+// loadStoredBlockData returns the transactions and encoded block data
+// saved to the block data store for height,
+// for a block whose data is no longer in the request cache,
+// such as a block committed before the node restarted.
+func (d *Driver) loadStoredBlockData(
+	ctx context.Context, height uint64, dataID string,
+) ([]transaction.Tx, []byte, error) {
+	storedID, data, err := d.bdStore.LoadBlockDataByHeight(ctx, height, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load block data: %w", err)
+	}
+	if storedID != dataID {
+		return nil, nil, fmt.Errorf(
+			"stored block data has ID %q, but header has data ID %q",
+			storedID, dataID,
+		)
+	}
+
+	dec, err := gsbd.NewBlockDataDecoder(dataID, d.txDecoder)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create block data decoder: %w", err)
+	}
+	txs, err := dec.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode stored block data: %w", err)
+	}
+	return txs, data, nil
+}
@@ -0,0 +1,79 @@
+package gsi
+
+// Internal tests, because replaying blocks is part of the driver's main loop,
+// and a full driver is far more than the replay needs.
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"cosmossdk.io/core/transaction"
+	"github.com/gordian-engine/gcosmos/gcstore/gcmemstore"
+	"github.com/gordian-engine/gcosmos/gserver/gservertest"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_replayAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// Before the restart, the driver saved the data of each block it finalized.
+	store := gcmemstore.NewBlockDataStore()
+	blocks := map[uint64][]transaction.Tx{
+		2: {gservertest.NewHashOnlyTransaction(1), gservertest.NewHashOnlyTransaction(2)},
+		3: {gservertest.NewHashOnlyTransaction(3)},
+	}
+	dataIDs := make(map[uint64]string, len(blocks))
+	encoded := make(map[uint64][]byte, len(blocks))
+	for h, txs := range blocks {
+		var buf bytes.Buffer
+		n, err := gsbd.EncodeBlockData(&buf, txs)
+		require.NoError(t, err)
+
+		dataIDs[h] = gsbd.DataID(h, 0, uint32(n), txs)
+		encoded[h] = buf.Bytes()
+		require.NoError(t, store.SaveBlockData(ctx, h, dataIDs[h], encoded[h]))
+	}
+
+	// After the restart, the request cache is empty,
+	// and the app is behind the committing height reported by the engine.
+	d := &Driver{
+		log:       gtest.NewLogger(t),
+		bdStore:   store,
+		txDecoder: gservertest.HashOnlyTransactionDecoder{},
+	}
+	d.replay.committingHeight = 4
+
+	for h := uint64(2); h <= 3; h++ {
+		d.observeReplay(h)
+		require.False(t, d.replay.start.IsZero())
+		require.Equal(t, uint64(2), d.replay.firstHeight)
+
+		txs, data, err := d.loadStoredBlockData(ctx, h, dataIDs[h])
+		require.NoError(t, err)
+		require.Equal(t, blocks[h], txs)
+		require.Equal(t, encoded[h], data)
+	}
+
+	// Reaching the committing height finishes the replay.
+	d.observeReplay(4)
+	require.True(t, d.replay.start.IsZero())
+
+	t.Run("data ID mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := d.loadStoredBlockData(ctx, 2, dataIDs[3])
+		require.ErrorContains(t, err, "header has data ID")
+	})
+
+	t.Run("missing block data", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := d.loadStoredBlockData(ctx, 9, dataIDs[2])
+		require.ErrorContains(t, err, "failed to load block data")
+	})
+}
@@ -6,15 +6,22 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"cosmossdk.io/core/transaction"
 	"cosmossdk.io/server/v2/appmanager"
+	"github.com/gordian-engine/gcosmos/gcconsensus"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
 	"github.com/gordian-engine/gcosmos/internal/copy/gchan"
 	"github.com/gordian-engine/gcosmos/internal/copy/glog"
 	"github.com/gordian-engine/gordian/gcrypto"
 	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine"
 )
 
 type ConsensusStrategy struct {
@@ -31,11 +38,47 @@ type ConsensusStrategy struct {
 	curH uint64
 	curR uint32
 
+	// The expected proposer in the current round,
+	// and the hashes of headers from other validators already audited in it.
+	curProposer     gcrypto.PubKey
+	auditedProposed map[string]struct{}
+
+	audit *gaudit.Log
+
 	pbdr *PBDRetriever
 
 	bdrCache *gsbd.RequestCache
 
 	proposerSelection ProposerSelectionFunc
+
+	stepObservers []StepObserver
+
+	timeoutStrategy tmengine.TimeoutStrategy
+	broadcastMargin time.Duration
+
+	builder        BlockBuilder
+	builderTimeout time.Duration
+
+	emptyBlocks EmptyBlockPolicy
+
+	params *gparams.Schedule
+
+	// Cancels our proposal still waiting for transactions
+	// from a previous round, if any.
+	cancelWait context.CancelFunc
+	waits      sync.WaitGroup
+
+	// Our most recent non-empty proposal,
+	// reused if building a later round's proposal at the same height
+	// exceeds the block building budget.
+	lastProposed atomic.Pointer[proposedTxs]
+}
+
+// proposedTxs is the set of transactions in one of our proposals.
+type proposedTxs struct {
+	Height  uint64
+	Txs     []transaction.Tx
+	Builder string
 }
 
 // ProposerSelectionFunc decides which validator
@@ -87,6 +130,43 @@ type ConsensusStrategyConfig struct {
 	// and which ones have already been completed.
 	// Not yet entirely used.
 	BlockDataRequestCache *gsbd.RequestCache
+
+	// Optional observers to be notified of state machine steps.
+	// Each observer is called in order for every step.
+	StepObservers []StepObserver
+
+	// The timeout strategy in use by the engine,
+	// used to calculate the deadline by which our proposal must be broadcast.
+	// If nil, building a proposed block has no deadline.
+	TimeoutStrategy tmengine.TimeoutStrategy
+
+	// How much of the proposal timeout to reserve
+	// for the proposal to propagate through the network.
+	// If zero, one fifth of the proposal timeout is reserved.
+	ProposalBroadcastMargin time.Duration
+
+	// Optional external source of the transactions in our proposed blocks.
+	// If nil, proposed blocks always use the transactions in TxBuf.
+	BlockBuilder BlockBuilder
+
+	// How long to wait for the BlockBuilder before falling back to TxBuf.
+	// If zero, the builder may use half of the block building budget,
+	// leaving the remainder for the fallback.
+	BlockBuilderTimeout time.Duration
+
+	// Whether and how long to wait for transactions
+	// before proposing a block without any.
+	// The zero value proposes immediately, even without transactions.
+	EmptyBlocks EmptyBlockPolicy
+
+	// Optional log to record proposed headers
+	// from validators who were not the round's proposer.
+	// Those headers are never voted for, whether or not this is set.
+	Audit *gaudit.Log
+
+	// Optional app-governed parameters,
+	// limiting the size of proposed blocks at each height.
+	Params *gparams.Schedule
 }
 
 func NewConsensusStrategy(
@@ -109,6 +189,20 @@ func NewConsensusStrategy(
 		bdrCache: cfg.BlockDataRequestCache,
 
 		proposerSelection: cfg.ProposerSelection,
+
+		stepObservers: slices.Clone(cfg.StepObservers),
+
+		timeoutStrategy: cfg.TimeoutStrategy,
+		broadcastMargin: cfg.ProposalBroadcastMargin,
+
+		builder:        cfg.BlockBuilder,
+		builderTimeout: cfg.BlockBuilderTimeout,
+
+		emptyBlocks: cfg.EmptyBlocks,
+
+		audit: cfg.Audit,
+
+		params: cfg.Params,
 	}
 
 	if cs.proposerSelection == nil {
@@ -121,8 +215,13 @@ func NewConsensusStrategy(
 func (s *ConsensusStrategy) Wait() {
 	// The pbdr is an implementation detail of the consensus strategy,
 	// so we don't expose it directly.
-	// The pbdr is the only background work created here.
 	s.pbdr.Wait()
+
+	// The only other background work is a proposal waiting for transactions,
+	// which stops with the context passed to EnterRound.
+	// Transaction gathering abandoned by gatherTxs is not waited for,
+	// as it may not respect its context.
+	s.waits.Wait()
 }
 
 // BlockAnnotation is the data encoded as a block annotation.
@@ -143,16 +242,38 @@ func (c *ConsensusStrategy) EnterRound(
 	ctx context.Context,
 	rv tmconsensus.RoundView,
 	proposalOut chan<- tmconsensus.Proposal,
-) error {
+) (err error) {
+	// The round timer starts approximately when we enter the round,
+	// so capture the time before doing any other work.
+	enteredAt := time.Now()
+
 	// Track the current height and round for later when we get to voting.
 	c.curH = rv.Height
 	c.curR = rv.Round
 
+	// A proposal from an earlier round that is still waiting for transactions
+	// is no longer useful.
+	if c.cancelWait != nil {
+		c.cancelWait()
+		c.cancelWait = nil
+	}
+
+	exitStep := c.observeStep(ctx, StepEvent{
+		Kind:             StepNewRound,
+		Height:           rv.Height,
+		Round:            rv.Round,
+		NProposedHeaders: len(rv.ProposedHeaders),
+	})
+	defer func() { exitStep("", err) }()
+
 	if c.signerPubKey == nil {
 		// Not participating, stop early.
 	}
 
 	proposingVal := c.proposerSelection(ctx, rv.Height, rv.Round, rv.ValidatorSet)
+	c.curProposer = proposingVal.PubKey
+	clear(c.auditedProposed)
+
 	weShouldPropose := proposingVal.PubKey.Equal(c.signerPubKey)
 	if !weShouldPropose {
 		return nil
@@ -174,6 +295,26 @@ func (c *ConsensusStrategy) EnterRound(
 		))
 	}
 
+	if c.waitForTxs(ctx, rv.Round) {
+		wCtx, cancel := context.WithCancel(ctx)
+		c.cancelWait = cancel
+
+		c.waits.Add(1)
+		go c.proposeWhenTxs(wCtx, rv.Height, rv.Round, enteredAt, proposalOut)
+		return nil
+	}
+
+	return c.propose(ctx, rv.Height, rv.Round, enteredAt, proposalOut)
+}
+
+// propose gathers transactions and sends our proposal
+// for height h and round r to proposalOut.
+func (c *ConsensusStrategy) propose(
+	ctx context.Context,
+	h uint64, r uint32,
+	enteredAt time.Time,
+	proposalOut chan<- tmconsensus.Proposal,
+) error {
 	ba, err := json.Marshal(BlockAnnotation{
 		// TODO: this needs something much more sophisticated than just time.Now.
 		TimeS: time.Now().UTC().Format(time.RFC3339),
@@ -182,20 +323,43 @@ func (c *ConsensusStrategy) EnterRound(
 		return fmt.Errorf("failed to marshal block driver annotations: %w", err)
 	}
 
-	pendingTxs := c.txBuf.Buffered(ctx, nil)
+	// Gathering transactions is bounded by the block building budget,
+	// so that the app can shrink its work rather than missing the proposal timeout.
+	buildCtx, cancel := c.blockBuildingContext(ctx, h, r, enteredAt)
+	defer cancel()
+
+	pendingTxs, builderName, ok := c.gatherTxs(buildCtx, h, r)
+	if !ok && ctx.Err() == nil {
+		// We ran out of time gathering transactions,
+		// but a stale or empty proposal is better than no proposal.
+		pendingTxs, builderName = c.budgetFallback(h, r)
+	}
+
+	if limit := c.params.At(h).MaxBlockBytes; limit > 0 {
+		if n := gsbd.MaxTxsForDataSize(pendingTxs, limit); n < len(pendingTxs) {
+			c.log.Info(
+				"Omitting transactions beyond the maximum block size",
+				"height", h, "round", r,
+				"n_txs", len(pendingTxs), "n_omitted", len(pendingTxs)-n,
+				"max_block_bytes", limit,
+			)
+			pendingTxs = pendingTxs[:n]
+		}
+	}
 
 	var blockDataID string
 	var pda []byte
 	if len(pendingTxs) == 0 {
-		blockDataID = gsbd.DataID(c.curH, c.curR, 0, nil)
+		blockDataID = gsbd.DataID(h, r, 0, nil)
 	} else {
-		res, err := c.provider.Provide(ctx, c.curH, c.curR, pendingTxs)
+		res, err := c.provider.Provide(ctx, h, r, pendingTxs)
 		if err != nil {
 			return fmt.Errorf("failed to provide block data: %w", err)
 		}
 
 		pda, err = json.Marshal(ProposalDriverAnnotation{
 			Locations: res.Addrs,
+			Builder:   builderName,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to marshal proposal driver annotations: %w", err)
@@ -205,6 +369,12 @@ func (c *ConsensusStrategy) EnterRound(
 
 		// We are proposing this data, so mark it as locally available.
 		c.bdrCache.SetImmediatelyAvailable(blockDataID, pendingTxs, res.Encoded)
+
+		c.lastProposed.Store(&proposedTxs{
+			Height:  h,
+			Txs:     pendingTxs,
+			Builder: builderName,
+		})
 	}
 
 	if !gchan.SendC(
@@ -226,11 +396,198 @@ func (c *ConsensusStrategy) EnterRound(
 	return nil
 }
 
+// gatherTxs returns the transactions for our proposed block
+// at height h and round r, from the BlockBuilder or from the transaction buffer,
+// along with the builder's name if the builder supplied them.
+//
+// The builder and the app are expected to respect the deadline on ctx,
+// but gatherTxs enforces it regardless, so that one slow module cannot stall consensus:
+// if ctx is done before the transactions are gathered,
+// gatherTxs returns false without waiting any longer.
+func (c *ConsensusStrategy) gatherTxs(
+	ctx context.Context, h uint64, r uint32,
+) ([]transaction.Tx, string, bool) {
+	type result struct {
+		txs     []transaction.Tx
+		builder string
+	}
+
+	// Buffered so the goroutine can finish after we have stopped waiting for it.
+	ch := make(chan result, 1)
+	go func() {
+		txs, name := c.externalBlock(ctx, h, r)
+		if name == "" {
+			txs = c.txBuf.Buffered(ctx, nil)
+		}
+		ch <- result{txs: txs, builder: name}
+	}()
+
+	select {
+	case res := <-ch:
+		if ctx.Err() != nil {
+			// Finished, but too late to use.
+			return nil, "", false
+		}
+		return res.txs, res.builder, true
+	case <-ctx.Done():
+		return nil, "", false
+	}
+}
+
+// budgetFallback returns the transactions to propose at height h and round r
+// when gathering transactions exceeded the block building budget:
+// the transactions from our earlier proposal at height h, if any,
+// which are still valid because the height's state has not changed,
+// or else no transactions.
+func (c *ConsensusStrategy) budgetFallback(h uint64, r uint32) ([]transaction.Tx, string) {
+	if prev := c.lastProposed.Load(); prev != nil && prev.Height == h {
+		c.log.Warn(
+			"Block building budget exhausted while gathering transactions; re-proposing earlier transactions",
+			"height", h, "round", r, "n_txs", len(prev.Txs),
+		)
+		return prev.Txs, prev.Builder
+	}
+
+	c.log.Warn(
+		"Block building budget exhausted while gathering transactions; proposing empty block",
+		"height", h, "round", r,
+	)
+	return nil, ""
+}
+
+// externalBlock requests the transactions for our proposed block
+// from the configured BlockBuilder,
+// returning the builder's name alongside the transactions on success.
+//
+// If there is no builder, or if the builder fails or returns
+// transactions that do not apply cleanly,
+// externalBlock returns an empty name
+// and the caller must gather transactions itself.
+func (c *ConsensusStrategy) externalBlock(
+	ctx context.Context, h uint64, r uint32,
+) ([]transaction.Tx, string) {
+	if c.builder == nil {
+		return nil, ""
+	}
+
+	name := c.builder.Name()
+
+	timeout := c.builderTimeout
+	if timeout <= 0 {
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline) / 2
+		}
+	}
+	bCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		bCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	txs, err := c.builder.BuildBlock(bCtx, h, r)
+	if err != nil {
+		c.log.Warn(
+			"Block builder failed; falling back to local transactions",
+			"height", h, "round", r, "builder", name, "err", err,
+		)
+		return nil, ""
+	}
+
+	if err := c.simulateTxs(bCtx, txs); err != nil {
+		c.log.Warn(
+			"Block builder supplied unusable transactions; falling back to local transactions",
+			"height", h, "round", r, "builder", name, "err", err,
+		)
+		return nil, ""
+	}
+
+	c.log.Info(
+		"Using transactions from block builder",
+		"height", h, "round", r, "builder", name, "n_txs", len(txs),
+	)
+	return txs, name
+}
+
+// simulateTxs reports an error if txs do not all apply cleanly,
+// in order, on top of the current state.
+func (c *ConsensusStrategy) simulateTxs(ctx context.Context, txs []transaction.Tx) error {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	txRes, state, err := c.am.Simulate(ctx, txs[0])
+	if err != nil {
+		return fmt.Errorf("failed to simulate transaction 0: %w", err)
+	}
+	if txRes.Error != nil {
+		return fmt.Errorf("transaction 0 failed: %w", txRes.Error)
+	}
+
+	for i, tx := range txs[1:] {
+		txRes, state, err = c.am.SimulateWithState(ctx, state, tx)
+		if err != nil {
+			return fmt.Errorf("failed to simulate transaction %d: %w", i+1, err)
+		}
+		if txRes.Error != nil {
+			return fmt.Errorf("transaction %d failed: %w", i+1, txRes.Error)
+		}
+	}
+
+	return nil
+}
+
+// blockBuildingContext returns a child of ctx whose deadline
+// is the time by which our proposal must be broadcast,
+// for a round at height h and round r entered at enteredAt.
+//
+// Block building code can inspect the deadline on the returned context
+// to adapt how much work it does.
+func (c *ConsensusStrategy) blockBuildingContext(
+	ctx context.Context, h uint64, r uint32, enteredAt time.Time,
+) (context.Context, context.CancelFunc) {
+	if c.timeoutStrategy == nil {
+		return context.WithCancel(ctx)
+	}
+
+	timeout := c.timeoutStrategy.ProposalTimeout(h, r)
+	margin := c.broadcastMargin
+	if margin <= 0 {
+		margin = timeout / 5
+	}
+	if margin > timeout {
+		margin = timeout
+	}
+
+	return context.WithDeadline(ctx, enteredAt.Add(timeout-margin))
+}
+
 // ConsiderProposedBlocks effectively chooses the first valid block in phs.
+// A block is only valid if it was proposed by the round's proposer,
+// so a block from any other validator is never prevoted,
+// even if it would otherwise be acceptable.
 func (c *ConsensusStrategy) ConsiderProposedBlocks(
 	ctx context.Context,
 	phs []tmconsensus.ProposedHeader,
 	_ tmconsensus.ConsiderProposedBlocksReason,
+) (choice string, err error) {
+	exitStep := c.observeStep(ctx, StepEvent{
+		Kind:             StepProposalReceived,
+		Height:           c.curH,
+		Round:            c.curR,
+		NProposedHeaders: len(phs),
+	})
+	defer func() { exitStep(choice, err) }()
+
+	return c.considerProposedBlocks(ctx, phs)
+}
+
+// considerProposedBlocks is the implementation of ConsiderProposedBlocks,
+// shared with ChooseProposedBlock so that step observers
+// only see the step that the state machine actually entered.
+func (c *ConsensusStrategy) considerProposedBlocks(
+	ctx context.Context,
+	phs []tmconsensus.ProposedHeader,
 ) (string, error) {
 PH_LOOP:
 	for _, ph := range phs {
@@ -256,8 +613,19 @@ PH_LOOP:
 			)
 			continue
 		}
+		if c.curProposer != nil && !c.curProposer.Equal(ph.ProposerPubKey) {
+			// The engine accepts a signed proposed header from any validator,
+			// so it is up to the strategy to reject those from the wrong proposer.
+			c.log.Debug(
+				"Ignoring proposed block from validator who is not the round's proposer",
+				"h", c.curH, "r", c.curR,
+				"block_hash", glog.Hex(ph.Header.Hash),
+			)
+			c.auditInvalidProposer(ph)
+			continue
+		}
 
-		h, r, nTxs, _, _, err := gsbd.ParseDataID(string(ph.Header.DataID))
+		h, r, nTxs, dataLen, _, err := gsbd.ParseDataID(string(ph.Header.DataID))
 		if err != nil {
 			c.log.Debug(
 				"Ignoring proposed block due to unparseable app data ID",
@@ -283,6 +651,14 @@ PH_LOOP:
 			)
 			continue
 		}
+		if limit := c.params.At(h).MaxBlockBytes; limit > 0 && int64(dataLen) > limit {
+			c.log.Debug(
+				"Ignoring proposed block exceeding the maximum block size",
+				"h", c.curH, "r", c.curR,
+				"data_len", dataLen, "max_block_bytes", limit,
+			)
+			continue
+		}
 
 		if nTxs != 0 {
 			bdr, ok := c.bdrCache.Get(string(ph.Header.DataID))
@@ -387,11 +763,38 @@ PH_LOOP:
 	return "", tmconsensus.ErrProposedBlockChoiceNotReady
 }
 
+// auditInvalidProposer records ph, from a validator other than the current proposer,
+// in the audit log, unless it was already recorded this round.
+// The mirror only delivers headers whose signatures it verified,
+// so ph is evidence against its proposer.
+func (c *ConsensusStrategy) auditInvalidProposer(ph tmconsensus.ProposedHeader) {
+	if c.audit == nil {
+		return
+	}
+	if _, ok := c.auditedProposed[string(ph.Header.Hash)]; ok {
+		return
+	}
+	if c.auditedProposed == nil {
+		c.auditedProposed = make(map[string]struct{})
+	}
+	c.auditedProposed[string(ph.Header.Hash)] = struct{}{}
+
+	c.audit.InvalidProposer(ph, c.curProposer)
+}
+
 func (c *ConsensusStrategy) ChooseProposedBlock(
 	ctx context.Context,
 	phs []tmconsensus.ProposedHeader,
-) (string, error) {
-	h, err := c.ConsiderProposedBlocks(ctx, phs, tmconsensus.ConsiderProposedBlocksReason{})
+) (choice string, err error) {
+	exitStep := c.observeStep(ctx, StepEvent{
+		Kind:             StepProposalTimeout,
+		Height:           c.curH,
+		Round:            c.curR,
+		NProposedHeaders: len(phs),
+	})
+	defer func() { exitStep(choice, err) }()
+
+	h, err := c.considerProposedBlocks(ctx, phs)
 	if err == tmconsensus.ErrProposedBlockChoiceNotReady {
 		return "", nil
 	}
@@ -405,10 +808,16 @@ func (c *ConsensusStrategy) ChooseProposedBlock(
 func (c *ConsensusStrategy) DecidePrecommit(
 	ctx context.Context,
 	vs tmconsensus.VoteSummary,
-) (string, error) {
-	maj := tmconsensus.ByzantineMajority(vs.AvailablePower)
-	if pow := vs.PrevoteBlockPower[vs.MostVotedPrevoteHash]; pow >= maj {
-		return vs.MostVotedPrevoteHash, nil
+) (choice string, err error) {
+	exitStep := c.observeStep(ctx, StepEvent{
+		Kind:   StepPrecommitDecision,
+		Height: c.curH,
+		Round:  c.curR,
+	})
+	defer func() { exitStep(choice, err) }()
+
+	if h, ok := (gcconsensus.VoteSummary{VoteSummary: vs}).PrevoteQuorum(); ok {
+		return h, nil
 	}
 
 	// Didn't reach consensus on one block; automatically precommit nil.
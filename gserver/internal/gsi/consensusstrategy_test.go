@@ -0,0 +1,458 @@
+package gsi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	coreserver "cosmossdk.io/core/server"
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/core/transaction"
+	"cosmossdk.io/server/v2/appmanager"
+	"github.com/gordian-engine/gcosmos/gserver/gservertest"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/gdriver/gtxbuf"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensusStrategy_stepObserver(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+
+	// Every observer sees every step.
+	var rec, other stepRecorder
+	cs := gsi.NewConsensusStrategy(ctx, gtest.NewLogger(t), gsi.ConsensusStrategyConfig{
+		StepObservers: []gsi.StepObserver{&rec, &other},
+	})
+
+	proposer := gsi.DefaultProposerSelection(ctx, 1, 0, fx.ValSet())
+
+	good := fx.NextProposedHeader([]byte(gsbd.DataID(1, 0, 0, nil)), 0)
+	good.ProposerPubKey = proposer.PubKey
+	ba, err := json.Marshal(gsi.BlockAnnotation{
+		TimeS: time.Now().Add(-time.Second).UTC().Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	good.Header.Annotations.Driver = ba
+	fx.RecalculateHash(&good.Header)
+
+	bad := fx.NextProposedHeader([]byte("not a data ID"), 0)
+	bad.ProposerPubKey = proposer.PubKey
+
+	// Not a validator, so entering the round does not propose.
+	require.NoError(t, cs.EnterRound(ctx, tmconsensus.RoundView{
+		Height: 1, Round: 0,
+		ValidatorSet:    fx.ValSet(),
+		ProposedHeaders: []tmconsensus.ProposedHeader{bad},
+	}, nil))
+	rec.RequireStep(t, gsi.StepEvent{
+		Kind: gsi.StepNewRound, Height: 1, Round: 0, NProposedHeaders: 1,
+	}, gsi.StepResult{})
+
+	choice, err := cs.ConsiderProposedBlocks(
+		ctx, []tmconsensus.ProposedHeader{bad}, tmconsensus.ConsiderProposedBlocksReason{},
+	)
+	require.ErrorIs(t, err, tmconsensus.ErrProposedBlockChoiceNotReady)
+	require.Empty(t, choice)
+	rec.RequireStep(t, gsi.StepEvent{
+		Kind: gsi.StepProposalReceived, Height: 1, Round: 0, NProposedHeaders: 1,
+	}, gsi.StepResult{Err: tmconsensus.ErrProposedBlockChoiceNotReady})
+
+	choice, err = cs.ChooseProposedBlock(ctx, []tmconsensus.ProposedHeader{bad, good})
+	require.NoError(t, err)
+	require.Equal(t, string(good.Header.Hash), choice)
+	rec.RequireStep(t, gsi.StepEvent{
+		Kind: gsi.StepProposalTimeout, Height: 1, Round: 0, NProposedHeaders: 2,
+	}, gsi.StepResult{Choice: string(good.Header.Hash)})
+
+	choice, err = cs.DecidePrecommit(ctx, tmconsensus.VoteSummary{
+		AvailablePower:       4,
+		PrevoteBlockPower:    map[string]uint64{string(good.Header.Hash): 3},
+		MostVotedPrevoteHash: string(good.Header.Hash),
+	})
+	require.NoError(t, err)
+	require.Equal(t, string(good.Header.Hash), choice)
+	rec.RequireStep(t, gsi.StepEvent{
+		Kind: gsi.StepPrecommitDecision, Height: 1, Round: 0,
+	}, gsi.StepResult{Choice: string(good.Header.Hash)})
+
+	// The next round is reported with its own round number,
+	// and a precommit without a prevote quorum is nil.
+	require.NoError(t, cs.EnterRound(ctx, tmconsensus.RoundView{
+		Height: 1, Round: 1,
+		ValidatorSet: fx.ValSet(),
+	}, nil))
+	rec.RequireStep(t, gsi.StepEvent{
+		Kind: gsi.StepNewRound, Height: 1, Round: 1,
+	}, gsi.StepResult{})
+
+	choice, err = cs.DecidePrecommit(ctx, tmconsensus.VoteSummary{AvailablePower: 4})
+	require.NoError(t, err)
+	require.Empty(t, choice)
+	rec.RequireStep(t, gsi.StepEvent{
+		Kind: gsi.StepPrecommitDecision, Height: 1, Round: 1,
+	}, gsi.StepResult{})
+
+	// The other observer saw the same steps, in the same order.
+	wantKinds := []gsi.StepKind{
+		gsi.StepNewRound, gsi.StepProposalReceived, gsi.StepProposalTimeout, gsi.StepPrecommitDecision,
+		gsi.StepNewRound, gsi.StepPrecommitDecision,
+	}
+	require.Len(t, other.enters, len(wantKinds))
+	require.Len(t, other.exits, len(wantKinds))
+	for i, k := range wantKinds {
+		require.Equal(t, k, other.enters[i].Kind)
+		require.Equal(t, other.enters[i], other.exits[i].Event)
+	}
+}
+
+func TestConsensusStrategy_blockBuildingDeadline(t *testing.T) {
+	t.Parallel()
+
+	const proposalTimeout = 10 * time.Second
+
+	for _, tc := range []struct {
+		name string
+
+		noTimeoutStrategy bool
+		margin            time.Duration
+
+		// How long after entering the round the deadline is expected.
+		wantBudget time.Duration
+	}{
+		{name: "default margin", wantBudget: proposalTimeout - proposalTimeout/5},
+		{name: "configured margin", margin: 3 * time.Second, wantBudget: 7 * time.Second},
+		{name: "margin capped at timeout", margin: time.Minute, wantBudget: 0},
+		{name: "no timeout strategy", noTimeoutStrategy: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fx := tmconsensustest.NewStandardFixture(4)
+			proposer := gsi.DefaultProposerSelection(ctx, 1, 0, fx.ValSet())
+
+			b := deadlineBuilder{deadlines: make(chan builderDeadline, 1)}
+			cfg := gsi.ConsensusStrategyConfig{
+				SignerPubKey: proposer.PubKey,
+
+				ProposalBroadcastMargin: tc.margin,
+
+				BlockBuilder: b,
+			}
+			if !tc.noTimeoutStrategy {
+				cfg.TimeoutStrategy = gsi.TimeoutStrategy{
+					Linear: tmengine.LinearTimeoutStrategy{ProposalBase: proposalTimeout},
+				}
+
+				// Long enough that the builder sees the block building deadline,
+				// rather than half of the remaining budget.
+				cfg.BlockBuilderTimeout = time.Hour
+			}
+			cs := gsi.NewConsensusStrategy(ctx, gtest.NewLogger(t), cfg)
+
+			proposalOut := make(chan tmconsensus.Proposal, 1)
+			before := time.Now()
+			require.NoError(t, cs.EnterRound(ctx, tmconsensus.RoundView{
+				Height: 1, Round: 0,
+				ValidatorSet: fx.ValSet(),
+			}, proposalOut))
+			after := time.Now()
+
+			d := gtest.ReceiveSoon(t, b.deadlines)
+			if tc.noTimeoutStrategy {
+				require.False(t, d.OK)
+			} else {
+				require.True(t, d.OK)
+				require.WithinRange(t, d.At, before.Add(tc.wantBudget), after.Add(tc.wantBudget))
+			}
+
+			// Whether or not the budget was exhausted, an empty block is proposed.
+			p := gtest.ReceiveSoon(t, proposalOut)
+			require.Equal(t, gsbd.DataID(1, 0, 0, nil), p.DataID)
+		})
+	}
+}
+
+func TestConsensusStrategy_blockBuilderFallback(t *testing.T) {
+	t.Parallel()
+
+	local := gservertest.NewHashOnlyTransaction(1)
+	built := gservertest.NewHashOnlyTransaction(2)
+	invalid := gservertest.NewHashOnlyTransaction(3)
+
+	for _, tc := range []struct {
+		name    string
+		builder staticBuilder
+
+		wantTxs     []transaction.Tx
+		wantBuilder string
+	}{
+		{
+			name:    "builder error",
+			builder: staticBuilder{err: errors.New("unavailable")},
+			wantTxs: []transaction.Tx{local},
+		},
+		{
+			name:    "builder transactions fail simulation",
+			builder: staticBuilder{txs: []transaction.Tx{built, invalid}},
+			wantTxs: []transaction.Tx{local},
+		},
+		{
+			name:        "builder transactions used",
+			builder:     staticBuilder{txs: []transaction.Tx{built}},
+			wantTxs:     []transaction.Tx{built},
+			wantBuilder: "static",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			log := gtest.NewLogger(t)
+
+			txBuf := gtxbuf.New(
+				ctx, log.With("sys", "txbuf"),
+				func(_ context.Context, s corestore.ReaderMap, _ transaction.Tx) (corestore.ReaderMap, error) {
+					return s, nil
+				},
+				func(context.Context, []transaction.Tx) func(transaction.Tx) bool {
+					return func(transaction.Tx) bool { return false }
+				},
+			)
+			defer txBuf.Wait()
+			defer cancel()
+			require.True(t, txBuf.Initialize(ctx, nil))
+			require.NoError(t, txBuf.AddTx(ctx, local))
+
+			fx := tmconsensustest.NewStandardFixture(4)
+			proposer := gsi.DefaultProposerSelection(ctx, 1, 0, fx.ValSet())
+
+			provider := recordingProvider{txs: make(chan []transaction.Tx, 1)}
+			cs := gsi.NewConsensusStrategy(ctx, log, gsi.ConsensusStrategyConfig{
+				AppManager: simulatingAppManager{
+					reject: map[[32]byte]bool{invalid.Hash(): true},
+				},
+				TxBuf:        txBuf,
+				SignerPubKey: proposer.PubKey,
+
+				BlockDataProvider:     provider,
+				BlockDataRequestCache: gsbd.NewRequestCache(gsbd.RequestCacheConfig{}),
+
+				BlockBuilder: tc.builder,
+			})
+
+			proposalOut := make(chan tmconsensus.Proposal, 1)
+			require.NoError(t, cs.EnterRound(ctx, tmconsensus.RoundView{
+				Height: 1, Round: 0,
+				ValidatorSet: fx.ValSet(),
+			}, proposalOut))
+
+			require.Equal(t, tc.wantTxs, gtest.ReceiveSoon(t, provider.txs))
+
+			p := gtest.ReceiveSoon(t, proposalOut)
+			var pda gsi.ProposalDriverAnnotation
+			require.NoError(t, json.Unmarshal(p.ProposalAnnotations.Driver, &pda))
+			require.Equal(t, tc.wantBuilder, pda.Builder)
+		})
+	}
+}
+
+func TestConsensusStrategy_wrongProposer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reg gcrypto.Registry
+	gcrypto.RegisterEd25519(&reg)
+	audit, err := gaudit.OpenLog(gtest.NewLogger(t), gaudit.LogConfig{
+		Path:  filepath.Join(t.TempDir(), "audit.jsonl"),
+		Codec: tmjson.MarshalCodec{CryptoRegistry: &reg},
+	})
+	require.NoError(t, err)
+	defer audit.Close()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	cs := gsi.NewConsensusStrategy(ctx, gtest.NewLogger(t), gsi.ConsensusStrategyConfig{
+		Audit: audit,
+	})
+
+	proposer := gsi.DefaultProposerSelection(ctx, 1, 0, fx.ValSet())
+	var other gcrypto.PubKey
+	for _, v := range fx.ValSet().Validators {
+		if !v.PubKey.Equal(proposer.PubKey) {
+			other = v.PubKey
+			break
+		}
+	}
+
+	// A block that would be acceptable from the round's proposer.
+	ph := fx.NextProposedHeader([]byte(gsbd.DataID(1, 0, 0, nil)), 0)
+	ba, err := json.Marshal(gsi.BlockAnnotation{
+		TimeS: time.Now().Add(-time.Second).UTC().Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	ph.Header.Annotations.Driver = ba
+	fx.RecalculateHash(&ph.Header)
+
+	wrong := ph
+	wrong.ProposerPubKey = other
+
+	require.NoError(t, cs.EnterRound(ctx, tmconsensus.RoundView{
+		Height: 1, Round: 0,
+		ValidatorSet: fx.ValSet(),
+	}, nil))
+
+	// The block from the wrong proposer is neither considered nor prevoted.
+	choice, err := cs.ConsiderProposedBlocks(
+		ctx, []tmconsensus.ProposedHeader{wrong}, tmconsensus.ConsiderProposedBlocksReason{},
+	)
+	require.ErrorIs(t, err, tmconsensus.ErrProposedBlockChoiceNotReady)
+	require.Empty(t, choice)
+
+	choice, err = cs.ChooseProposedBlock(ctx, []tmconsensus.ProposedHeader{wrong})
+	require.NoError(t, err)
+	require.Empty(t, choice)
+
+	// It was audited once, despite being seen twice.
+	rs, err := audit.Records()
+	require.NoError(t, err)
+	require.Len(t, rs, 1)
+	require.Equal(t, gaudit.KindInvalidProposer, rs[0].Kind)
+
+	// The same block from the round's proposer is prevoted.
+	right := ph
+	right.ProposerPubKey = proposer.PubKey
+	choice, err = cs.ChooseProposedBlock(ctx, []tmconsensus.ProposedHeader{wrong, right})
+	require.NoError(t, err)
+	require.Equal(t, string(ph.Header.Hash), choice)
+}
+
+// deadlineBuilder is a [gsi.BlockBuilder] that supplies no transactions,
+// reporting the deadline of each request.
+type deadlineBuilder struct {
+	deadlines chan builderDeadline
+}
+
+type builderDeadline struct {
+	At time.Time
+	OK bool
+}
+
+func (deadlineBuilder) Name() string { return "deadline" }
+
+func (b deadlineBuilder) BuildBlock(ctx context.Context, _ uint64, _ uint32) ([]transaction.Tx, error) {
+	at, ok := ctx.Deadline()
+	b.deadlines <- builderDeadline{At: at, OK: ok}
+	return nil, nil
+}
+
+// staticBuilder is a [gsi.BlockBuilder] returning fixed transactions or a fixed error.
+type staticBuilder struct {
+	txs []transaction.Tx
+	err error
+}
+
+func (staticBuilder) Name() string { return "static" }
+
+func (b staticBuilder) BuildBlock(context.Context, uint64, uint32) ([]transaction.Tx, error) {
+	return b.txs, b.err
+}
+
+// simulatingAppManager is an app manager whose simulations
+// succeed except for the transactions in reject.
+type simulatingAppManager struct {
+	// Embedded so that the methods not needed by tests panic.
+	appmanager.AppManager[transaction.Tx]
+
+	reject map[[32]byte]bool
+}
+
+func (am simulatingAppManager) Simulate(
+	_ context.Context, tx transaction.Tx,
+) (coreserver.TxResult, corestore.WriterMap, error) {
+	return am.result(tx), nil, nil
+}
+
+func (am simulatingAppManager) SimulateWithState(
+	_ context.Context, _ corestore.ReaderMap, tx transaction.Tx,
+) (coreserver.TxResult, corestore.WriterMap, error) {
+	return am.result(tx), nil, nil
+}
+
+func (am simulatingAppManager) result(tx transaction.Tx) coreserver.TxResult {
+	if am.reject[tx.Hash()] {
+		return coreserver.TxResult{Error: errors.New("rejected")}
+	}
+	return coreserver.TxResult{}
+}
+
+// recordingProvider is a [gsbd.Provider] reporting the transactions it provides.
+type recordingProvider struct {
+	txs chan []transaction.Tx
+}
+
+func (p recordingProvider) Provide(
+	_ context.Context, h uint64, r uint32, txs []transaction.Tx,
+) (gsbd.ProvideResult, error) {
+	p.txs <- txs
+	return gsbd.ProvideResult{DataID: gsbd.DataID(h, r, 1, txs)}, nil
+}
+
+// stepRecorder is a [gsi.StepObserver] recording every callback.
+type stepRecorder struct {
+	enters []gsi.StepEvent
+	exits  []stepExit
+}
+
+type stepExit struct {
+	Event  gsi.StepEvent
+	Result gsi.StepResult
+}
+
+func (r *stepRecorder) EnterStep(_ context.Context, ev gsi.StepEvent) {
+	r.enters = append(r.enters, ev)
+}
+
+func (r *stepRecorder) ExitStep(_ context.Context, ev gsi.StepEvent, res gsi.StepResult) {
+	r.exits = append(r.exits, stepExit{Event: ev, Result: res})
+}
+
+// RequireStep requires that the only callbacks since the last call
+// were entering and exiting the step in ev, with result res.
+func (r *stepRecorder) RequireStep(t *testing.T, ev gsi.StepEvent, res gsi.StepResult) {
+	t.Helper()
+
+	require.Equal(t, []gsi.StepEvent{ev}, r.enters)
+	require.Len(t, r.exits, 1)
+	require.Equal(t, ev, r.exits[0].Event)
+	require.Equal(t, res.Choice, r.exits[0].Result.Choice)
+	if res.Err == nil {
+		require.NoError(t, r.exits[0].Result.Err)
+	} else {
+		require.ErrorIs(t, r.exits[0].Result.Err, res.Err)
+	}
+
+	r.enters = nil
+	r.exits = nil
+}
@@ -3,16 +3,20 @@ package gsi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
 	"path/filepath"
 	"runtime/trace"
 	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	corecomet "cosmossdk.io/core/comet"
 	corecontext "cosmossdk.io/core/context"
+	"cosmossdk.io/core/event"
 	coreserver "cosmossdk.io/core/server"
 	"cosmossdk.io/core/store"
 	"cosmossdk.io/core/transaction"
@@ -24,17 +28,30 @@ import (
 	"github.com/cosmos/cosmos-sdk/server"
 	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 	"github.com/gordian-engine/gcosmos/gccodec"
+	"github.com/gordian-engine/gcosmos/gcrand"
 	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gda"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gfork"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gp2papi"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpanic"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gprofile"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gvalbook"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatch"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatermark"
 	"github.com/gordian-engine/gcosmos/internal/copy/gchan"
 	"github.com/gordian-engine/gcosmos/internal/copy/glog"
+	"github.com/gordian-engine/gordian/gassert"
 	"github.com/gordian-engine/gordian/gcrypto"
 	"github.com/gordian-engine/gordian/tm/tmconsensus"
 	"github.com/gordian-engine/gordian/tm/tmdriver"
 	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/gordian-engine/gordian/tm/tmstore"
 )
 
+//go:generate go run github.com/gordian-engine/gordian/gassert/cmd/generate-nodebug driver_debug.go
+
 type DriverConfig struct {
 	ChainID string
 
@@ -53,6 +70,55 @@ type DriverConfig struct {
 
 	BlockDataRequestCache *gsbd.RequestCache
 	BlockDataStore        gcstore.BlockDataStore
+
+	// Optional queue to publish finalized blocks
+	// to an external data availability layer.
+	DAQueue *gda.Queue
+
+	// Optional index recording the hashes of finalized transactions.
+	TxIndex gcstore.TxIndex
+
+	// Optional pool whose transaction replacements the driver processes,
+	// and which the driver informs of transactions leaving the buffer.
+	TxPool *TxPool
+
+	// Optional channel that must be closed
+	// before the engine may begin the initial height,
+	// such as a [*gp2papi.PeerBarrier]'s Ready channel.
+	StartBarrier <-chan struct{}
+
+	// Assertion environment for the driver's invariant checks,
+	// which are only compiled into debug builds.
+	AssertEnv gassert.Env
+
+	// Optional mirror store, only read by invariant checks.
+	MirrorStore tmstore.MirrorStore
+
+	// Optional shield recovering a panic in the driver's goroutine.
+	PanicShield *gpanic.Shield
+
+	// Optional capture of profiles when delivering a block to the app is slow.
+	SlowFinalizeCapture *gprofile.SlowCapture
+
+	// Optional writer notified of each finalized height.
+	Watermarks *gwatermark.Writer
+
+	// Optional recorder of app hash mismatches.
+	// On a mismatch, the driver stops finalizing blocks,
+	// which halts the engine's participation in consensus.
+	ForkRecorder *gfork.Recorder
+
+	// Optional book of validator monikers,
+	// refreshed from the staking module whenever the validator set changes.
+	ValidatorBook *gvalbook.Book
+
+	// Optional schedule of consensus parameter changes,
+	// to which the driver adds the changes the app requests in finalized blocks.
+	Params *gparams.Schedule
+
+	// Optional index of watched addresses,
+	// to which the driver passes the events of each finalized block.
+	WatchIndex *gwatch.Index
 }
 
 type Driver struct {
@@ -66,8 +132,28 @@ type Driver struct {
 
 	bdrCache *gsbd.RequestCache
 
+	// Decodes block data loaded from bdStore,
+	// set once the driver goroutine starts.
+	txDecoder transaction.Codec[transaction.Tx]
+
 	cuClient *gp2papi.CatchupClient
 
+	daQueue *gda.Queue
+
+	txIndex gcstore.TxIndex
+
+	startBarrier <-chan struct{}
+
+	assertEnv gassert.Env
+	ms        tmstore.MirrorStore
+
+	// The state the transaction buffer was last initialized or rebased on,
+	// only accessed from the driver's goroutine.
+	bufState store.ReaderMap
+
+	txPool            *TxPool
+	txReplaceRequests <-chan txReplaceRequest
+
 	am       appmanager.AppManager[transaction.Tx]
 	sdkStore storev2.RootStore
 
@@ -75,9 +161,46 @@ type Driver struct {
 
 	lagStateUpdates <-chan tmelink.LagState
 
+	shield *gpanic.Shield
+
+	slowCapture *gprofile.SlowCapture
+
+	watermarks *gwatermark.Writer
+
+	forks *gfork.Recorder
+
+	valBook *gvalbook.Book
+
+	params *gparams.Schedule
+
+	watch *gwatch.Index
+
+	// Progress of replaying committed blocks after a restart.
+	replay blockReplay
+
+	// Height of the block the app is currently executing, or zero.
+	finalizing atomic.Uint64
+
+	// What the driver goroutine is currently handling,
+	// included in the state dump if the goroutine panics.
+	work driverWork
+
+	// Closed by Drain, to stop the main loop between requests.
+	drain     chan struct{}
+	drainOnce sync.Once
+
 	done chan struct{}
 }
 
+// driverWork describes the request the driver goroutine is handling.
+type driverWork struct {
+	ChainID string
+
+	Stage  string
+	Height uint64 `json:",omitempty"`
+	Round  uint32 `json:",omitempty"`
+}
+
 func NewDriver(
 	lifeCtx, valCtx context.Context,
 	log *slog.Logger,
@@ -106,14 +229,43 @@ func NewDriver(
 
 		cuClient: cfg.CatchupClient,
 
+		daQueue: cfg.DAQueue,
+
+		txIndex: cfg.TxIndex,
+
+		startBarrier: cfg.StartBarrier,
+
+		assertEnv: cfg.AssertEnv,
+		ms:        cfg.MirrorStore,
+
 		finalizeBlockRequests: cfg.FinalizeBlockRequests,
 		lagStateUpdates:       cfg.LagStateUpdates,
 
 		am:       cfg.AppManager,
 		sdkStore: cfg.Store,
 
+		shield: cfg.PanicShield,
+
+		slowCapture: cfg.SlowFinalizeCapture,
+
+		watermarks: cfg.Watermarks,
+
+		forks: cfg.ForkRecorder,
+
+		valBook: cfg.ValidatorBook,
+
+		params: cfg.Params,
+
+		watch: cfg.WatchIndex,
+
+		drain: make(chan struct{}),
+
 		done: make(chan struct{}),
 	}
+	if cfg.TxPool != nil {
+		d.txPool = cfg.TxPool
+		d.txReplaceRequests = cfg.TxPool.replaceRequests
+	}
 
 	go d.run(lifeCtx, ag, cc.TxConfig, cfg)
 
@@ -131,16 +283,25 @@ func (d *Driver) run(
 
 	defer close(d.done)
 
+	// Deferred after closing d.done, so the root context is canceled
+	// before Wait returns.
+	if d.shield != nil {
+		defer d.shield.Recover("driver", func() any { return d.work })
+	}
+
 	d.log.Info("Driver starting...")
 	defer d.log.Info("Driver goroutine finished")
 
+	d.work = driverWork{ChainID: d.chainID, Stage: "init_chain"}
+
+	d.txDecoder = gccodec.NewTxDecoder(txConfig)
+
 	// We are currently assuming we always need to handle init chain,
 	// but we should handle non-initial height.
 	if !d.handleInitialization(
 		ctx,
 		ag,
 		cfg.Store,
-		txConfig,
 		cfg.InitChainRequests,
 	) {
 		return
@@ -153,7 +314,6 @@ func (d *Driver) handleInitialization(
 	ctx context.Context,
 	ag *genutiltypes.AppGenesis,
 	s storev2.RootStore,
-	txConfig client.TxConfig,
 	initChainCh <-chan tmdriver.InitChainRequest,
 ) bool {
 	defer trace.StartRegion(ctx, "handleInitialization").End()
@@ -177,6 +337,7 @@ func (d *Driver) handleInitialization(
 				d.log.Error("Failed to get latest state", "err", err)
 				return false
 			}
+			d.bufState = state
 			return d.txBuf.Initialize(ctx, state)
 		}
 
@@ -202,8 +363,6 @@ func (d *Driver) handleInitialization(
 	appState := []byte(ag.AppState)
 
 	// Now, init genesis in the SDK-layer application.
-	var codec transaction.Codec[transaction.Tx] = gccodec.NewTxDecoder(txConfig)
-
 	// We need a special context for the InitGenesis call,
 	// as the consensus parameters are expected to be a value on the context there.
 	deliverCtx := context.WithValue(ctx, corecontext.CometParamsInitInfoKey, &consensustypes.MsgUpdateParams{
@@ -223,7 +382,7 @@ func (d *Driver) handleInitialization(
 		},
 	})
 	blockResp, genesisState, err := d.am.InitGenesis(
-		deliverCtx, blockReq, appState, codec,
+		deliverCtx, blockReq, appState, d.txDecoder,
 	)
 	if err != nil {
 		d.log.Warn("Failed to run appManager.InitGenesis", "appState", fmt.Sprintf("%q", appState), "err", err)
@@ -234,6 +393,7 @@ func (d *Driver) handleInitialization(
 	if !d.txBuf.Initialize(ctx, genesisState) {
 		return false
 	}
+	d.bufState = genesisState
 
 	// SetInitialVersion followed by WorkingHash,
 	// and passing that hash as the initial app state hash,
@@ -290,6 +450,18 @@ func (d *Driver) handleInitialization(
 
 		Validators: gVals,
 	}
+
+	// The engine does not enter the initial height until it has our response,
+	// so holding the response until enough peers are connected
+	// and until the genesis time
+	// gives every validator the same starting point,
+	// and gives the p2p layer time to settle before the first proposal.
+	if !d.waitForStartBarrier(ctx) {
+		return false
+	}
+	if !d.waitForGenesisTime(ctx, ag.GenesisTime) {
+		return false
+	}
 	if !gchan.SendC(
 		ctx, d.log,
 		req.Resp, resp,
@@ -308,26 +480,107 @@ func (d *Driver) handleInitialization(
 	return true
 }
 
+// waitForStartBarrier blocks until the configured start barrier is closed,
+// returning false if ctx is canceled first.
+func (d *Driver) waitForStartBarrier(ctx context.Context) bool {
+	if d.startBarrier == nil {
+		return true
+	}
+
+	d.log.Info("Waiting for start barrier before starting initial height")
+
+	select {
+	case <-ctx.Done():
+		d.log.Info(
+			"Context canceled while waiting for start barrier",
+			"cause", context.Cause(ctx),
+		)
+		return false
+	case <-d.startBarrier:
+		return true
+	}
+}
+
+// waitForGenesisTime blocks until genesisTime,
+// returning false if ctx is canceled first.
+// A zero or past genesis time returns immediately.
+func (d *Driver) waitForGenesisTime(ctx context.Context, genesisTime time.Time) bool {
+	wait := time.Until(genesisTime)
+	if genesisTime.IsZero() || wait <= 0 {
+		return true
+	}
+
+	d.log.Info(
+		"Waiting for genesis time before starting initial height",
+		"genesis_time", genesisTime,
+		"wait", wait,
+	)
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		d.log.Info(
+			"Context canceled while waiting for genesis time",
+			"cause", context.Cause(ctx),
+		)
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
 func (d *Driver) mainLoop(
 	ctx context.Context,
 ) {
 	defer trace.StartRegion(ctx, "mainLoop").End()
 
+	// Pick up validators created since genesis.
+	d.refreshValidatorBook(ctx)
+
 	for {
+		// Check for a drain before accepting another request,
+		// as the select below would choose randomly between them.
+		select {
+		case <-d.drain:
+			d.log.Info("Stopping after draining in-flight work")
+			return
+		default:
+		}
+
 		select {
 		case <-ctx.Done():
 			d.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
 			return
 
+		case <-d.drain:
+			d.log.Info("Stopping after draining in-flight work")
+			return
+
 		case req := <-d.finalizeBlockRequests:
+			d.work = driverWork{
+				ChainID: d.chainID,
+				Stage:   "finalize_block",
+				Height:  req.Header.Height,
+				Round:   req.Round,
+			}
+			d.observeReplay(req.Header.Height)
 			if !d.handleFinalization(ctx, req) {
 				return
 			}
 
 		case ls := <-d.lagStateUpdates:
+			d.work = driverWork{ChainID: d.chainID, Stage: "lag_state", Height: ls.CommittingHeight}
 			if !d.handleLagStateUpdate(ctx, ls) {
 				return
 			}
+
+		case req := <-d.txReplaceRequests:
+			d.work = driverWork{ChainID: d.chainID, Stage: "tx_replacement"}
+			if !d.handleTxReplacement(ctx, req) {
+				return
+			}
 		}
 	}
 }
@@ -358,6 +611,9 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 			Validators:   req.Header.NextValidatorSet.Validators,
 			AppStateHash: appHash,
 		}
+		if !d.publishToDA(ctx, req, nil, appHash) {
+			return false
+		}
 		if !gchan.SendC(
 			ctx, d.log,
 			req.Resp, resp,
@@ -367,6 +623,7 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 			return false
 		}
 
+		d.watermarks.SetFinalized(req.Header.Height)
 		return true
 	}
 
@@ -379,6 +636,21 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 		return false
 	}
 
+	invariantFinalization(ctx, d.assertEnv, d.ms, cID.Version, req)
+
+	// The header records the app state hash the network committed
+	// after the previous height, which must match our last commit.
+	if d.forks != nil {
+		if err := d.forks.Check(
+			d.chainID, req.Header.Height-1,
+			req.Header.PrevAppStateHash, cID.Hash,
+			req.Header.Hash, req.Round,
+		); err != nil {
+			d.log.Error("Halting finalization due to app hash mismatch", "err", err)
+			return false
+		}
+	}
+
 	var ba BlockAnnotation
 	if err := json.Unmarshal(req.Header.Annotations.Driver, &ba); err != nil {
 		d.log.Warn(
@@ -398,68 +670,85 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 	}
 
 	var txs []transaction.Tx
+	var encodedTxs []byte
 
 	if !gsbd.IsZeroTxDataID(string(req.Header.DataID)) {
-		// The entry must already exist in the cache.
-		// If it doesn't, then something went wrong in the consensus strategy.
 		bdr, ok := d.bdrCache.Get(string(req.Header.DataID))
 		if !ok {
-			panic(fmt.Errorf(
-				"TODO: handle missing entry (%q) in block data request cache",
-				req.Header.DataID,
-			))
-		}
+			// The entry only exists in the cache for blocks proposed or fetched
+			// since the node started, so a block being replayed after a restart
+			// must have its data in the block data store instead.
+			var err error
+			txs, encodedTxs, err = d.loadStoredBlockData(ctx, req.Header.Height, string(req.Header.DataID))
+			if err != nil {
+				d.log.Error(
+					"Block data missing from request cache and block data store",
+					"height", req.Header.Height,
+					"data_id", req.Header.DataID,
+					"err", err,
+				)
+				return false
+			}
+		} else {
+			// We have the bdr, so now we have to block until it's ready.
+			// Optimistic check first.
+			needToBlock := false
+			select {
+			case <-bdr.Ready:
+				// Good.
+			default:
+				needToBlock = true
+			}
 
-		// We have the bdr, so now we have to block until it's ready.
-		// Optimistic check first.
-		needToBlock := false
-		select {
-		case <-bdr.Ready:
-			// Good.
-		default:
-			needToBlock = true
-		}
+			if needToBlock {
+				// TODO: this should have some slightly sophisticated logging,
+				// so that an observer could see why the state machine is stuck here.
+				if _, ok := gchan.RecvC(
+					ctx, d.log,
+					bdr.Ready,
+					"waiting for block data in order to finalize",
+				); !ok {
+					return false
+				}
+			}
+
+			txs = bdr.Transactions
+			encodedTxs = bdr.EncodedTransactions
 
-		if needToBlock {
-			// TODO: this should have some slightly sophisticated logging,
-			// so that an observer could see why the state machine is stuck here.
-			if _, ok := gchan.RecvC(
-				ctx, d.log,
-				bdr.Ready,
-				"waiting for block data in order to finalize",
-			); !ok {
+			// Save the block data to its store,
+			// so that we can serve it to peers who need it later.
+			//
+			// TODO: is it okay to do this here?
+			// Or should it happen on another goroutine,
+			// to avoid blocking the upcoming SDK block delivery?
+			// It probably should not be in the finalization path at all,
+			// but rather part of the mirror kernel.
+			// But it works enough here for the moment.
+			//
+			// The data may already be saved, if a previous run
+			// stopped before the app committed this height.
+			if err := d.bdStore.SaveBlockData(
+				ctx,
+				req.Header.Height,
+				string(req.Header.DataID),
+				bdr.EncodedTransactions,
+			); err != nil && !errors.As(err, new(gcstore.AlreadyHaveBlockDataForHeightError)) {
+				// Fatal error.
+				d.log.Error(
+					"Failed to write block data",
+					"height", req.Header.Height,
+					"data_id", req.Header.DataID,
+					"err", err,
+				)
 				return false
 			}
 		}
-
-		txs = bdr.Transactions
-
-		// Save the block data to its store,
-		// so that we can serve it to peers who need it later.
-		//
-		// TODO: is it okay to do this here?
-		// Or should it happen on another goroutine,
-		// to avoid blocking the upcoming SDK block delivery?
-		// It probably should not be in the finalization path at all,
-		// but rather part of the mirror kernel.
-		// But it works enough here for the moment.
-		if err := d.bdStore.SaveBlockData(
-			ctx,
-			req.Header.Height,
-			string(req.Header.DataID),
-			bdr.EncodedTransactions,
-		); err != nil {
-			// Fatal error.
-			d.log.Error(
-				"Failed to write block data",
-				"height", req.Header.Height,
-				"data_id", req.Header.DataID,
-				"err", err,
-			)
-			return false
-		}
 	}
 
+	// The block data for this height, and for any other proposed blocks
+	// at this height or earlier, is no longer needed in memory.
+	d.bdrCache.PruneThrough(req.Header.Height)
+
 	blockReq := &coreserver.BlockRequest[transaction.Tx]{
 		Height: req.Header.Height,
 
@@ -478,7 +767,21 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 		// without any values populated, is enough to progress past the panic.
 	})
 
+	// Expose the per-height randomness to the app.
+	// The context is the only channel into the modules,
+	// as the block request has no field for it;
+	// see the gcrand package documentation.
+	// There is no beacon at the initial height,
+	// but we have already returned early in that case.
+	if b, ok := gcrand.BeaconFromHeader(req.Header); ok {
+		ctx = gcrand.ContextWithBeacon(ctx, b)
+	}
+
+	endCapture := d.slowCapture.Begin(blockReq.Height)
+	d.finalizing.Store(blockReq.Height)
 	blockResp, newState, err := d.am.DeliverBlock(ctx, blockReq)
+	d.finalizing.Store(0)
+	endCapture()
 	if err != nil {
 		d.log.Warn(
 			"Failed to deliver block",
@@ -568,16 +871,25 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 				})
 			}
 		}
+
+		d.logPowerDistribution(req.Header.Height, updatedVals)
 	}
 
 	// Rebase the transactions on the new state.
 	// For now, we just discard the invalidated transactions.
 	// We could log them but it isn't clear what exactly should be logged.
 	// Maybe the transaction hash would suffice?
-	if _, err := d.txBuf.Rebase(ctx, newState, txs); err != nil {
+	invalidated, err := d.txBuf.Rebase(ctx, newState, txs)
+	if err != nil {
 		d.log.Warn("Failed to rebase transaction buffer", "err", err)
 		return false
 	}
+	d.bufState = newState
+
+	if d.txPool != nil {
+		d.txPool.forget(txs)
+		d.txPool.forget(invalidated)
+	}
 
 	stateChanges, err := newState.GetStateChanges()
 	if err != nil {
@@ -591,7 +903,24 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 	}
 	d.log.Info("Committed change to root store", "height", req.Header.Height, "apphash", glog.Hex(appHash))
 
-	// TODO: There could be updated consensus params that we care about here.
+	if d.txIndex != nil {
+		hashes := make([][]byte, len(txs))
+		for i, tx := range txs {
+			h := tx.Hash()
+			hashes[i] = h[:]
+		}
+		if err := d.txIndex.SaveBlockTxs(ctx, req.Header.Height, hashes); err != nil {
+			// The index is only for lookups outside consensus,
+			// so a failure here must not halt the chain.
+			d.log.Warn(
+				"Failed to index finalized transactions",
+				"height", req.Header.Height,
+				"err", err,
+			)
+		}
+	}
+
+	d.scheduleParamChanges(req.Header.Height, blockResp.EndBlockEvents)
 
 	fbResp := tmdriver.FinalizeBlockResponse{
 		Height:    req.Header.Height,
@@ -602,6 +931,9 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 
 		AppStateHash: appHash,
 	}
+	if !d.publishToDA(ctx, req, encodedTxs, appHash) {
+		return false
+	}
 	if !gchan.SendC(
 		ctx, d.log,
 		req.Resp, fbResp,
@@ -611,12 +943,136 @@ func (d *Driver) handleFinalization(ctx context.Context, req tmdriver.FinalizeBl
 		return false
 	}
 
+	d.watermarks.SetFinalized(req.Header.Height)
+	d.publishWatchEvents(req.Header.Height, blockResp)
+
+	if len(blockResp.ValidatorUpdates) > 0 {
+		d.refreshValidatorBook(ctx)
+	}
+
 	return true
 }
 
+// scheduleParamChanges adds the consensus parameter changes
+// requested by the app's end block events at height to d.params.
+//
+// An invalid request is logged and otherwise ignored,
+// as every validator rejects it alike.
+func (d *Driver) scheduleParamChanges(height uint64, events []event.Event) {
+	if d.params == nil {
+		return
+	}
+
+	for _, ev := range events {
+		if ev.Type != gparams.EventType {
+			continue
+		}
+
+		evAttrs, err := ev.Attributes()
+		if err != nil {
+			d.log.Warn(
+				"Failed to read consensus parameter change event",
+				"height", height, "err", err,
+			)
+			continue
+		}
+		attrs := make([]gparams.Attribute, len(evAttrs))
+		for i, a := range evAttrs {
+			attrs[i] = gparams.Attribute{Key: a.Key, Value: a.Value}
+		}
+
+		if err := d.params.Request(height, attrs); err != nil {
+			d.log.Warn(
+				"Ignoring invalid consensus parameter change requested by app",
+				"height", height, "err", err,
+			)
+		}
+	}
+}
+
+// publishWatchEvents passes every event of the block finalized at height
+// to the watch index.
+func (d *Driver) publishWatchEvents(height uint64, resp *coreserver.BlockResponse) {
+	if d.watch == nil {
+		return
+	}
+
+	var events []gwatch.Event
+	add := func(evs []event.Event) {
+		for _, ev := range evs {
+			evAttrs, err := ev.Attributes()
+			if err != nil {
+				d.log.Warn("Failed to read event for watched addresses", "height", height, "err", err)
+				continue
+			}
+			attrs := make([]gwatch.Attribute, len(evAttrs))
+			for i, a := range evAttrs {
+				attrs[i] = gwatch.Attribute{Key: a.Key, Value: a.Value}
+			}
+			events = append(events, gwatch.Event{Type: ev.Type, Attributes: attrs})
+		}
+	}
+
+	add(resp.PreBlockEvents)
+	add(resp.BeginBlockEvents)
+	for _, tr := range resp.TxResults {
+		// A failed transaction still reports the events of its fee payment.
+		add(tr.Events)
+	}
+	add(resp.EndBlockEvents)
+
+	d.watch.Observe(height, events)
+}
+
+// logPowerDistribution logs the power distribution of a changed validator set,
+// warning when a single validator holds enough power to halt the chain.
+func (d *Driver) logPowerDistribution(height uint64, vals []tmconsensus.Validator) {
+	pd := NewPowerDistribution(vals)
+
+	if len(vals) > 1 && pd.NakamotoCoefficient == 1 {
+		d.log.Warn(
+			"A single validator holds more than one third of voting power",
+			"height", height,
+			"top_share", pd.CumulativeShares[0],
+			"n_vals", len(vals),
+		)
+		return
+	}
+
+	d.log.Info(
+		"Validator set changed",
+		"height", height,
+		"n_vals", len(vals),
+		"nakamoto_coefficient", pd.NakamotoCoefficient,
+	)
+}
+
+// publishToDA submits the finalized block to the DA queue, if one is configured.
+// Depending on the queue's gating, this may block until the block is included.
+// It reports false if the context was canceled first.
+func (d *Driver) publishToDA(
+	ctx context.Context, req tmdriver.FinalizeBlockRequest, data, appHash []byte,
+) bool {
+	if d.daQueue == nil {
+		return true
+	}
+
+	return d.daQueue.Submit(ctx, gda.Block{
+		Height: req.Header.Height,
+		Hash:   req.Header.Hash,
+
+		DataID: string(req.Header.DataID),
+		Data:   data,
+
+		AppStateHash: appHash,
+	})
+}
+
 func (d *Driver) handleLagStateUpdate(ctx context.Context, ls tmelink.LagState) bool {
 	defer trace.StartRegion(ctx, "handleLagStateUpdate").End()
 
+	d.replay.committingHeight = ls.CommittingHeight
+
 	switch ls.Status {
 	case tmelink.LagStatusInitializing,
 		tmelink.LagStatusAssumedBehind,
@@ -640,3 +1096,24 @@ func (d *Driver) handleLagStateUpdate(ctx context.Context, ls tmelink.LagState)
 func (d *Driver) Wait() {
 	<-d.done
 }
+
+// FinalizingHeight returns the height of the block the app is currently executing,
+// or zero if the app is not executing a block.
+func (d *Driver) FinalizingHeight() uint64 {
+	return d.finalizing.Load()
+}
+
+// Drain asks the driver to stop once it completes the request in progress,
+// such as a block finalization, without accepting any further requests.
+// It blocks until the driver has stopped,
+// or returns the cause of ctx if ctx is canceled first.
+func (d *Driver) Drain(ctx context.Context) error {
+	d.drainOnce.Do(func() { close(d.drain) })
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
@@ -0,0 +1,119 @@
+//go:build debug
+
+package gsi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gordian/gassert"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmdriver"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+)
+
+// invariantFinalization asserts invariants of the engine and SDK state
+// as observed when the driver receives a finalization request,
+// prior to the SDK store committing the finalized block.
+//
+// lastVersion is the version of the SDK store's last commit.
+// ms may be nil, in which case the mirror heights are not checked.
+func invariantFinalization(
+	ctx context.Context,
+	env gassert.Env,
+	ms tmstore.MirrorStore,
+	lastVersion uint64,
+	req tmdriver.FinalizeBlockRequest,
+) {
+	h := req.Header.Height
+
+	if env.Enabled("gcosmos.driver.finalization.version") {
+		if lastVersion >= h {
+			env.HandleAssertionFailure(fmt.Errorf(
+				"finalizing height %d but SDK store is already at version %d",
+				h, lastVersion,
+			))
+		}
+	}
+
+	if ms != nil && env.Enabled("gcosmos.driver.finalization.mirror_heights") {
+		vh, _, ch, _, err := ms.NetworkHeightRound(ctx)
+		if err != nil {
+			env.HandleAssertionFailure(fmt.Errorf(
+				"failed to load network height-round: %w", err,
+			))
+		} else {
+			if vh <= ch {
+				env.HandleAssertionFailure(fmt.Errorf(
+					"mirror voting height %d must be greater than committing height %d",
+					vh, ch,
+				))
+			}
+			if h > ch {
+				env.HandleAssertionFailure(fmt.Errorf(
+					"finalizing height %d beyond mirror committing height %d",
+					h, ch,
+				))
+			}
+		}
+	}
+
+	if env.Enabled("gcosmos.driver.finalization.prev_commit_power") {
+		invariantPrevCommitPower(env, req.Header)
+	}
+}
+
+// invariantPrevCommitPower asserts that the signatures in hdr's previous commit proof
+// for the previous block hash represent a majority of voting power.
+//
+// The proof was signed by the previous height's validators,
+// which are only available through the header
+// if the validator set was unchanged across the heights;
+// otherwise the check is skipped.
+func invariantPrevCommitPower(env gassert.Env, hdr tmconsensus.Header) {
+	proof := hdr.PrevCommitProof
+	if hdr.Height <= 1 || proof.PubKeyHash != string(hdr.ValidatorSet.PubKeyHash) {
+		return
+	}
+
+	vals := hdr.ValidatorSet.Validators
+	var total, signed uint64
+	for _, v := range vals {
+		total += v.Power
+	}
+	if total == 0 {
+		env.HandleAssertionFailure(fmt.Errorf(
+			"validator set at height %d has no voting power", hdr.Height,
+		))
+		return
+	}
+
+	seen := make([]bool, len(vals))
+	for _, sig := range proof.Proofs[string(hdr.PrevBlockHash)] {
+		idx, ok := gcstore.SimpleKeyIndex(sig.KeyID)
+		if !ok || idx >= len(vals) {
+			env.HandleAssertionFailure(fmt.Errorf(
+				"previous commit proof at height %d has invalid key ID %x",
+				hdr.Height, sig.KeyID,
+			))
+			return
+		}
+		if seen[idx] {
+			env.HandleAssertionFailure(fmt.Errorf(
+				"previous commit proof at height %d counts validator %d twice",
+				hdr.Height, idx,
+			))
+			return
+		}
+		seen[idx] = true
+		signed += vals[idx].Power
+	}
+
+	if signed < tmconsensus.ByzantineMajority(total) {
+		env.HandleAssertionFailure(fmt.Errorf(
+			"previous commit proof at height %d has power %d, need at least %d of %d",
+			hdr.Height, signed, tmconsensus.ByzantineMajority(total), total,
+		))
+	}
+}
@@ -0,0 +1,32 @@
+package gsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSONDuration is a [time.Duration] encoded in JSON as a string, e.g. "5.5s",
+// to be more readable than nanosecond integers.
+type JSONDuration time.Duration
+
+func (d JSONDuration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d JSONDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *JSONDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = JSONDuration(v)
+	return nil
+}
@@ -0,0 +1,109 @@
+package gsi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// EmptyBlockPolicy controls whether the [ConsensusStrategy]
+// waits for transactions before proposing a block,
+// similar to CometBFT's create_empty_blocks settings.
+//
+// The zero value proposes immediately,
+// producing blocks continuously whether or not there are transactions.
+type EmptyBlockPolicy struct {
+	// If positive, the proposer waits up to Interval for a transaction
+	// before proposing in the first round of a height,
+	// and then proposes an empty block if there are still none.
+	//
+	// Transactions are not gossiped between validators,
+	// so a proposer waiting for its own transactions
+	// never learns of transactions submitted to other validators.
+	// The empty block after Interval moves the chain on to another proposer.
+	Interval time.Duration
+}
+
+// Validate reports an error if p cannot be used.
+func (p EmptyBlockPolicy) Validate() error {
+	if p.Interval < 0 {
+		return errors.New("empty block interval must not be negative")
+	}
+	return nil
+}
+
+// Wait returns how long the proposer may wait for transactions
+// in the first round of a height, or zero if it does not wait.
+//
+// Every validator must extend its round 0 proposal timeout by this amount,
+// as [TimeoutStrategy] does with its EmptyBlockWait field,
+// so that validators do not prevote nil while the proposer is waiting.
+func (p EmptyBlockPolicy) Wait() time.Duration {
+	return p.Interval
+}
+
+// How often a proposer waiting for transactions checks the transaction buffer.
+const emptyBlockPollInterval = 100 * time.Millisecond
+
+// waitForTxs reports whether our proposal for round r
+// must wait for transactions to arrive in the buffer.
+//
+// Blocks from a BlockBuilder are not waited for,
+// as the builder has its own source of transactions.
+func (c *ConsensusStrategy) waitForTxs(ctx context.Context, r uint32) bool {
+	if r != 0 || c.emptyBlocks.Wait() == 0 || c.builder != nil {
+		return false
+	}
+	return len(c.txBuf.Buffered(ctx, nil)) == 0
+}
+
+// proposeWhenTxs waits until the transaction buffer is not empty,
+// or until the empty block interval since enteredAt elapses,
+// and then proposes for height h and round r.
+// It runs in its own goroutine, and it stops early if ctx is canceled
+// because the engine has entered another round.
+func (c *ConsensusStrategy) proposeWhenTxs(
+	ctx context.Context,
+	h uint64, r uint32,
+	enteredAt time.Time,
+	proposalOut chan<- tmconsensus.Proposal,
+) {
+	defer c.waits.Done()
+
+	wait := c.emptyBlocks.Wait()
+	c.log.Debug(
+		"Waiting for transactions before proposing",
+		"height", h, "round", r, "max_wait", wait,
+	)
+
+	deadline := time.NewTimer(time.Until(enteredAt.Add(wait)))
+	defer deadline.Stop()
+
+	poll := time.NewTicker(emptyBlockPollInterval)
+	defer poll.Stop()
+
+WAIT:
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-deadline.C:
+			break WAIT
+
+		case <-poll.C:
+			if len(c.txBuf.Buffered(ctx, nil)) > 0 {
+				break WAIT
+			}
+		}
+	}
+
+	if err := c.propose(ctx, h, r, enteredAt, proposalOut); err != nil && ctx.Err() == nil {
+		c.log.Warn(
+			"Failed to propose after waiting for transactions",
+			"height", h, "round", r, "err", err,
+		)
+	}
+}
@@ -0,0 +1,164 @@
+package gsi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/core/transaction"
+	"github.com/gordian-engine/gcosmos/gserver/gservertest"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gdriver/gtxbuf"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmptyBlockPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, gsi.EmptyBlockPolicy{}.Validate())
+	require.NoError(t, gsi.EmptyBlockPolicy{Interval: time.Second}.Validate())
+	require.Error(t, gsi.EmptyBlockPolicy{Interval: -time.Second}.Validate())
+}
+
+func TestConsensusStrategy_emptyBlockInterval(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty block after the interval", func(t *testing.T) {
+		t.Parallel()
+
+		interval := time.Duration(gtest.ScaleMs(300))
+		fx := newEmptyBlockFixture(t, interval, 0)
+
+		enteredAt := time.Now()
+		fx.EnterRound(t)
+
+		gtest.NotSendingSoon(t, fx.ProposalOut)
+
+		p := gtest.ReceiveOrTimeout(t, fx.ProposalOut, gtest.ScaleMs(500))
+		require.GreaterOrEqual(t, time.Since(enteredAt), interval)
+		require.Equal(t, gsbd.DataID(1, 0, 0, nil), p.DataID)
+	})
+
+	t.Run("proposal once a transaction arrives", func(t *testing.T) {
+		t.Parallel()
+
+		fx := newEmptyBlockFixture(t, time.Hour, 0)
+		fx.EnterRound(t)
+
+		gtest.NotSendingSoon(t, fx.ProposalOut)
+
+		tx := gservertest.NewHashOnlyTransaction(1)
+		require.NoError(t, fx.TxBuf.AddTx(fx.Ctx, tx))
+
+		// The buffer is polled, so allow more than the default timeout.
+		require.Equal(t, []transaction.Tx{tx}, gtest.ReceiveOrTimeout(t, fx.Provider.txs, gtest.ScaleMs(300)))
+		_ = gtest.ReceiveSoon(t, fx.ProposalOut)
+	})
+
+	t.Run("buffered transactions are proposed immediately", func(t *testing.T) {
+		t.Parallel()
+
+		fx := newEmptyBlockFixture(t, time.Hour, 0)
+
+		tx := gservertest.NewHashOnlyTransaction(1)
+		require.NoError(t, fx.TxBuf.AddTx(fx.Ctx, tx))
+
+		fx.EnterRound(t)
+
+		require.Equal(t, []transaction.Tx{tx}, gtest.ReceiveSoon(t, fx.Provider.txs))
+		_ = gtest.ReceiveSoon(t, fx.ProposalOut)
+	})
+
+	t.Run("later rounds do not wait", func(t *testing.T) {
+		t.Parallel()
+
+		fx := newEmptyBlockFixture(t, time.Hour, 1)
+		fx.EnterRound(t)
+
+		p := gtest.ReceiveSoon(t, fx.ProposalOut)
+		require.Equal(t, gsbd.DataID(1, 1, 0, nil), p.DataID)
+	})
+}
+
+type emptyBlockFixture struct {
+	Ctx context.Context
+
+	TxBuf    *gsi.SDKTxBuf
+	Provider recordingProvider
+
+	CS *gsi.ConsensusStrategy
+
+	ProposalOut chan tmconsensus.Proposal
+
+	fx    *tmconsensustest.StandardFixture
+	round uint32
+}
+
+// newEmptyBlockFixture returns a fixture whose consensus strategy
+// is the proposer for height 1 and round r,
+// waiting up to interval for transactions.
+func newEmptyBlockFixture(t *testing.T, interval time.Duration, r uint32) *emptyBlockFixture {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := gtest.NewLogger(t)
+
+	txBuf := gtxbuf.New(
+		ctx, log.With("sys", "txbuf"),
+		func(_ context.Context, s corestore.ReaderMap, _ transaction.Tx) (corestore.ReaderMap, error) {
+			return s, nil
+		},
+		func(context.Context, []transaction.Tx) func(transaction.Tx) bool {
+			return func(transaction.Tx) bool { return false }
+		},
+	)
+	t.Cleanup(txBuf.Wait)
+	t.Cleanup(cancel)
+	require.True(t, txBuf.Initialize(ctx, nil))
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	proposer := gsi.DefaultProposerSelection(ctx, 1, r, fx.ValSet())
+
+	provider := recordingProvider{txs: make(chan []transaction.Tx, 1)}
+	cs := gsi.NewConsensusStrategy(ctx, log, gsi.ConsensusStrategyConfig{
+		AppManager:   simulatingAppManager{},
+		TxBuf:        txBuf,
+		SignerPubKey: proposer.PubKey,
+
+		BlockDataProvider:     provider,
+		BlockDataRequestCache: gsbd.NewRequestCache(gsbd.RequestCacheConfig{}),
+
+		EmptyBlocks: gsi.EmptyBlockPolicy{Interval: interval},
+	})
+
+	return &emptyBlockFixture{
+		Ctx: ctx,
+
+		TxBuf:    txBuf,
+		Provider: provider,
+
+		CS: cs,
+
+		ProposalOut: make(chan tmconsensus.Proposal, 1),
+
+		fx:    fx,
+		round: r,
+	}
+}
+
+// EnterRound enters the fixture's round at height 1.
+func (f *emptyBlockFixture) EnterRound(t *testing.T) {
+	t.Helper()
+
+	require.NoError(t, f.CS.EnterRound(f.Ctx, tmconsensus.RoundView{
+		Height: 1, Round: f.round,
+		ValidatorSet: f.fx.ValSet(),
+	}, f.ProposalOut))
+}
@@ -8,11 +8,35 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
 
 	"cosmossdk.io/core/transaction"
 	"cosmossdk.io/server/v2/appmanager"
 	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gcosmos/gcstore"
+	"github.com/gordian-engine/gcosmos/gcverify"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gclock"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gevidence"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gfork"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gingress"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gliveness"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpersist"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gready"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gstall"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtenant"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gvalbook"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatch"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatermark"
+	"github.com/gordian-engine/gcosmos/internal/copy/glog"
 	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine"
 	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p"
 	"github.com/gordian-engine/gordian/tm/tmstore"
 	"github.com/gorilla/mux"
@@ -23,11 +47,23 @@ type HTTPServer struct {
 }
 
 type HTTPServerConfig struct {
+	// The listener to serve on, for [NewHTTPServer].
 	Listener net.Listener
 
+	// The shared host and the chain ID to serve under, for [AttachHTTPServer].
+	SharedHost *gtenant.Host
+	ChainID    string
+
+	// If enabled, every request must carry one of the tokens.
+	AuthTokens *gtenant.Tokens
+
 	FinalizationStore tmstore.FinalizationStore
 	MirrorStore       tmstore.MirrorStore
 
+	// Source for the committed headers endpoint.
+	// May be nil, in which case the endpoint reports an error.
+	CommittedHeaderStore tmstore.CommittedHeaderStore
+
 	CryptoRegistry *gcrypto.Registry
 
 	Libp2pHost *tmlibp2p.Host
@@ -38,11 +74,95 @@ type HTTPServerConfig struct {
 	Codec      codec.Codec
 
 	TxBuffer *SDKTxBuf
+
+	// Submitted transactions are added to the buffer through the pool,
+	// which checks for signer sequence conflicts.
+	TxPool *TxPool
+
+	// Reported through the debug timeouts endpoint.
+	TimeoutStrategy tmengine.TimeoutStrategy
+
+	// Used to submit proposed headers built outside of the consensus strategy,
+	// typically the engine, so that they are validated
+	// exactly like proposed headers received from the network.
+	// If nil, the submit_proposed_header endpoint reports an error.
+	ProposedHeaderHandler tmconsensus.FineGrainedConsensusHandler
+
+	// Encodes and decodes consensus values such as headers.
+	// Endpoints that need it report an error if it is nil.
+	ConsensusCodec tmcodec.MarshalCodec
+
+	// Reported through the debug stall endpoint.
+	// If nil, the endpoint reports an error.
+	StallDetector *gstall.Detector
+
+	// Reported through the debug clock skew endpoint.
+	// If nil, the endpoint reports an error.
+	ClockSkew *gclock.Monitor
+
+	// Reported through the debug heartbeats endpoint.
+	// If nil, the endpoint reports an error.
+	Heartbeats *gliveness.Heartbeater
+
+	// Source for the fork report endpoint.
+	// If nil, the endpoint reports an error.
+	ForkRecorder *gfork.Recorder
+
+	// Source for the fork evidence endpoints.
+	// If nil, the endpoints report an error.
+	ForkEvidence *gevidence.Exchange
+
+	// Source for the audit endpoint.
+	// If nil, the endpoint reports an error.
+	AuditLog *gaudit.Log
+
+	// Source for the upgrade readiness endpoint.
+	// If nil, the endpoint reports an error.
+	Readiness *gready.Tracker
+
+	// Optional source of validator monikers
+	// included in the validator, validator diff, and commit signer endpoints.
+	ValidatorBook *gvalbook.Book
+
+	// Optional source for the watermark endpoint,
+	// which otherwise reads the mirror store.
+	Watermarks *gwatermark.Writer
+
+	// Source for the watch endpoint.
+	// If nil, the endpoint reports an error.
+	WatchIndex *gwatch.Index
+
+	// Source for the round history endpoint.
+	// If nil, the endpoint reports an error.
+	RoundHistory *RoundHistory
+
+	// Reported through the debug round store endpoint.
+	// If nil, the endpoint reports an error.
+	RoundStore *gpersist.RoundStore
+
+	// Reported through the debug backpressure endpoint.
+	// If nil, the endpoint reports an error.
+	Backpressure *gbackpressure.Registry
+
+	// Reported through the debug memory endpoint.
+	// Any may be nil, in which case its section is omitted.
+	BlockDataCache *gsbd.RequestCache
+	IngressGuard   *gingress.Guard
+	IngressDedupe  *gingress.Deduper
+
+	// Reported through the node info endpoint,
+	// along with the protocols supported by Libp2pHost.
+	NodeInfo NodeInfo
+
+	// Maximum number of items returned by paginated list endpoints,
+	// and their default page size.
+	// Zero means no maximum.
+	MaxPageSize int
 }
 
 func NewHTTPServer(ctx context.Context, log *slog.Logger, cfg HTTPServerConfig) *HTTPServer {
 	srv := &http.Server{
-		Handler: newMux(log, cfg),
+		Handler: cfg.AuthTokens.Wrap(newMux(log, cfg)),
 
 		BaseContext: func(net.Listener) context.Context {
 			return ctx
@@ -58,6 +178,28 @@ func NewHTTPServer(ctx context.Context, log *slog.Logger, cfg HTTPServerConfig)
 	return h
 }
 
+// AttachHTTPServer serves the same API as [NewHTTPServer],
+// under cfg.ChainID on cfg.SharedHost instead of on a listener of its own,
+// until ctx is canceled.
+func AttachHTTPServer(ctx context.Context, log *slog.Logger, cfg HTTPServerConfig) (*HTTPServer, error) {
+	detach, err := cfg.SharedHost.Attach(cfg.ChainID, cfg.AuthTokens.Wrap(newMux(log, cfg)))
+	if err != nil {
+		return nil, err
+	}
+
+	h := &HTTPServer{
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(h.done)
+		<-ctx.Done()
+		detach()
+		log.Info("HTTP server detached from shared host")
+	}()
+
+	return h, nil
+}
+
 func (h *HTTPServer) Wait() {
 	<-h.done
 }
@@ -89,75 +231,180 @@ func newMux(log *slog.Logger, cfg HTTPServerConfig) http.Handler {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/blocks/watermark", handleBlocksWatermark(log, cfg)).Methods("GET")
+	r.HandleFunc("/node_info", handleNodeInfo(log, cfg)).Methods("GET")
 	r.HandleFunc("/validators", handleValidators(log, cfg)).Methods("GET")
+	r.HandleFunc("/validators/stream", handleValidatorStream(log, cfg)).Methods("GET")
+	r.HandleFunc("/validators/power", handleValidatorPower(log, cfg)).Methods("GET")
+	r.HandleFunc("/validators/diff", handleValidatorDiff(log, cfg)).Methods("GET")
+	r.HandleFunc("/headers/{height:[0-9]+}", handleCommittedHeader(log, cfg)).Methods("GET")
+	r.HandleFunc("/finality_proof/{height:[0-9]+}", handleFinalityProof(log, cfg)).Methods("GET")
+	r.HandleFunc("/commit_signers", handleCommitSigners(log, cfg)).Methods("GET")
+	r.HandleFunc("/fork_report", handleForkReport(log, cfg)).Methods("GET")
+	r.HandleFunc("/fork_evidence", handleForkEvidence(log, cfg)).Methods("GET")
+	r.HandleFunc("/audit", handleAudit(log, cfg)).Methods("GET")
+	r.HandleFunc("/upgrade_readiness", handleUpgradeReadiness(log, cfg)).Methods("GET")
+	r.HandleFunc("/simulate_tx", handleSimulateTx(log, cfg)).Methods("POST")
+	r.HandleFunc("/watch", handleWatch(log, cfg)).Methods("GET")
+	r.HandleFunc("/round_history", handleRoundHistory(log, cfg)).Methods("GET")
 
 	setDebugRoutes(log, cfg, r)
 
 	setCompatRoutes(log, cfg, r)
 
+	// Must be last, so that the document includes every other route.
+	setOpenAPIRoute(log, r)
+
 	return r
 }
 
 func handleBlocksWatermark(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
 	ms := cfg.MirrorStore
+	wms := cfg.Watermarks
 	return func(w http.ResponseWriter, req *http.Request) {
-		vh, vr, ch, cr, err := ms.NetworkHeightRound(req.Context())
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		var currentBlock gwatermark.Watermark
+		if wms != nil {
+			currentBlock = wms.Latest()
+		}
+
+		// Until the engine publishes its first view, read the mirror store directly.
+		if currentBlock.VotingHeight == 0 {
+			vh, vr, ch, cr, err := ms.NetworkHeightRound(req.Context())
+			if err != nil {
+				httpError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			currentBlock.VotingHeight = vh
+			currentBlock.VotingRound = vr
+			currentBlock.CommittingHeight = ch
+			currentBlock.CommittingRound = cr
+		}
+
+		if err := json.NewEncoder(w).Encode(currentBlock); err != nil {
+			log.Warn("Failed to marshal current block", "err", err)
 			return
 		}
+	}
+}
 
-		// TODO: this should probably be an exported type somewhere.
-		var currentBlock struct {
-			VotingHeight uint64
-			VotingRound  uint32
+func handleForkReport(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	fr := cfg.ForkRecorder
+	return func(w http.ResponseWriter, req *http.Request) {
+		if fr == nil {
+			httpError(w, "fork detection not enabled", http.StatusServiceUnavailable)
+			return
+		}
 
-			CommittingHeight uint64
-			CommittingRound  uint32
+		rep, ok := fr.Report()
+		if !ok {
+			httpError(w, "no fork detected", http.StatusNotFound)
+			return
 		}
 
-		currentBlock.VotingHeight = vh
-		currentBlock.VotingRound = vr
-		currentBlock.CommittingHeight = ch
-		currentBlock.CommittingRound = cr
+		if err := json.NewEncoder(w).Encode(rep); err != nil {
+			log.Warn("Failed to encode fork report", "err", err)
+		}
+	}
+}
 
-		if err := json.NewEncoder(w).Encode(currentBlock); err != nil {
-			log.Warn("Failed to marshal current block", "err", err)
+// jsonForkEvidence is the HTTP representation of a [gevidence.Evidence],
+// with each committed header in the consensus codec's encoding.
+type jsonForkEvidence struct {
+	Height uint64 `json:",omitempty"`
+	A, B   json.RawMessage
+}
+
+func handleForkEvidence(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	ex := cfg.ForkEvidence
+	tmCodec := cfg.ConsensusCodec
+	return func(w http.ResponseWriter, req *http.Request) {
+		if ex == nil || tmCodec == nil {
+			httpError(w, "fork evidence exchange not enabled", http.StatusServiceUnavailable)
 			return
 		}
+
+		evs := ex.Evidence()
+		out := make([]jsonForkEvidence, len(evs))
+		for i, ev := range evs {
+			a, err := tmCodec.MarshalCommittedHeader(ev.A)
+			if err != nil {
+				httpError(w, "failed to encode committed header: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			b, err := tmCodec.MarshalCommittedHeader(ev.B)
+			if err != nil {
+				httpError(w, "failed to encode committed header: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			out[i] = jsonForkEvidence{Height: ev.Height(), A: a, B: b}
+		}
+
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			log.Warn("Failed to encode fork evidence", "err", err)
+		}
 	}
 }
 
-func handleValidators(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
-	ms := cfg.MirrorStore
-	fs := cfg.FinalizationStore
-	reg := cfg.CryptoRegistry
+func handleAudit(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	al := cfg.AuditLog
 	return func(w http.ResponseWriter, req *http.Request) {
-		_, _, committingHeight, _, err := ms.NetworkHeightRound(req.Context())
-		if err != nil {
-			http.Error(
-				w,
-				fmt.Sprintf("failed to get committing height: %v", err),
-				http.StatusInternalServerError,
-			)
+		if al == nil {
+			httpError(w, "audit log not enabled", http.StatusServiceUnavailable)
 			return
 		}
 
-		_, _, valSet, _, err := fs.LoadFinalizationByHeight(req.Context(), committingHeight)
+		rs, err := al.Records()
 		if err != nil {
-			http.Error(
-				w,
-				fmt.Sprintf("failed to load finalization: %v", err),
-				http.StatusInternalServerError,
-			)
+			httpError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rs, ok := paginate(w, req, rs, cfg.MaxPageSize)
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(rs); err != nil {
+			log.Warn("Failed to encode audit log records", "err", err)
+		}
+	}
+}
+
+func handleUpgradeReadiness(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	tr := cfg.Readiness
+	return func(w http.ResponseWriter, req *http.Request) {
+		if tr == nil {
+			httpError(w, "upgrade readiness not enabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		committingHeight, vals, ok := loadCommittingValidators(w, req, cfg)
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(tr.Tally(committingHeight, vals)); err != nil {
+			log.Warn("Failed to encode upgrade readiness", "err", err)
+		}
+	}
+}
+
+func handleValidators(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	reg := cfg.CryptoRegistry
+	return func(w http.ResponseWriter, req *http.Request) {
+		committingHeight, vals, ok := loadCommittingValidators(w, req, cfg)
+		if !ok {
+			return
+		}
+		vals, ok = paginate(w, req, vals, cfg.MaxPageSize)
+		if !ok {
 			return
 		}
-		vals := valSet.Validators
 
 		// Now we have the validators at the committing height.
 		type jsonValidator struct {
-			PubKey []byte
-			Power  uint64
+			PubKey  []byte
+			Power   uint64
+			Moniker string `json:",omitempty"`
 		}
 		var resp struct {
 			FinalizationHeight uint64
@@ -169,6 +416,7 @@ func handleValidators(log *slog.Logger, cfg HTTPServerConfig) func(w http.Respon
 		for i, v := range vals {
 			resp.Validators[i].Power = v.Power
 			resp.Validators[i].PubKey = reg.Marshal(v.PubKey)
+			resp.Validators[i].Moniker = cfg.ValidatorBook.Moniker(v.PubKey.PubKeyBytes())
 		}
 
 		if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -177,3 +425,523 @@ func handleValidators(log *slog.Logger, cfg HTTPServerConfig) func(w http.Respon
 		}
 	}
 }
+
+// validatorStreamFlushInterval is the number of validators
+// written to a validator stream between flushes.
+const validatorStreamFlushInterval = 256
+
+// handleValidatorStream writes the validators at the committing height
+// as newline-delimited JSON, one validator per line,
+// without applying the page size limit.
+// This keeps memory use flat for clients of networks with many validators.
+func handleValidatorStream(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	reg := cfg.CryptoRegistry
+	return func(w http.ResponseWriter, req *http.Request) {
+		committingHeight, vals, ok := loadCommittingValidators(w, req, cfg)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("X-Finalization-Height", strconv.FormatUint(committingHeight, 10))
+		w.Header().Set(totalCountHeader, strconv.Itoa(len(vals)))
+
+		rc := http.NewResponseController(w)
+		enc := json.NewEncoder(w)
+		for i, v := range vals {
+			if err := enc.Encode(struct {
+				PubKey  []byte
+				Power   uint64
+				Moniker string `json:",omitempty"`
+			}{
+				PubKey:  reg.Marshal(v.PubKey),
+				Power:   v.Power,
+				Moniker: cfg.ValidatorBook.Moniker(v.PubKey.PubKeyBytes()),
+			}); err != nil {
+				log.Debug("Failed to write validator stream", "err", err)
+				return
+			}
+
+			if (i+1)%validatorStreamFlushInterval == 0 {
+				if err := rc.Flush(); err != nil {
+					log.Debug("Failed to flush validator stream", "err", err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// maxWatchAddresses is the most addresses one watch request may name.
+const maxWatchAddresses = 100
+
+// handleWatch streams the changes to the balances and delegations
+// of the addresses in the address query parameter,
+// as newline-delimited JSON, one change per line,
+// until the client disconnects.
+// The response ends early if the client falls too far behind.
+func handleWatch(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	x := cfg.WatchIndex
+	return func(w http.ResponseWriter, req *http.Request) {
+		if x == nil {
+			httpError(w, "address watching not enabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		addrs := req.URL.Query()["address"]
+		if len(addrs) == 0 {
+			httpError(w, "at least one address query parameter is required", http.StatusBadRequest)
+			return
+		}
+		if len(addrs) > maxWatchAddresses {
+			httpError(
+				w,
+				fmt.Sprintf("at most %d addresses may be watched per request", maxWatchAddresses),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		ch, err := x.Subscribe(req.Context(), addrs)
+		if err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+
+		// Send the headers now, so the client knows the subscription is in place
+		// before the first change arrives.
+		rc := http.NewResponseController(w)
+		if err := rc.Flush(); err != nil {
+			log.Debug("Failed to flush watch stream", "err", err)
+			return
+		}
+
+		enc := json.NewEncoder(w)
+		for c := range ch {
+			if err := enc.Encode(c); err != nil {
+				log.Debug("Failed to write watch stream", "err", err)
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				log.Debug("Failed to flush watch stream", "err", err)
+				return
+			}
+		}
+	}
+}
+
+func handleValidatorPower(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		committingHeight, vals, ok := loadCommittingValidators(w, req, cfg)
+		if !ok {
+			return
+		}
+
+		resp := struct {
+			FinalizationHeight uint64
+			PowerDistribution
+		}{
+			FinalizationHeight: committingHeight,
+			PowerDistribution:  NewPowerDistribution(vals),
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Warn("Failed to marshal validator power response", "err", err)
+			return
+		}
+	}
+}
+
+func handleValidatorDiff(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	fs := cfg.FinalizationStore
+	reg := cfg.CryptoRegistry
+	return func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		from, err := strconv.ParseUint(q.Get("from"), 10, 64)
+		if err != nil {
+			httpError(w, "invalid from height: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := strconv.ParseUint(q.Get("to"), 10, 64)
+		if err != nil {
+			httpError(w, "invalid to height: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var sets [2][]tmconsensus.Validator
+		for i, h := range []uint64{from, to} {
+			_, _, valSet, _, err := fs.LoadFinalizationByHeight(req.Context(), h)
+			if err != nil {
+				if gcerr.CodeOf(err) == gcerr.CodeNotFound {
+					httpError(w, err.Error(), http.StatusNotFound)
+					return
+				}
+				httpError(
+					w,
+					fmt.Sprintf("failed to load finalization at height %d: %v", h, err),
+					http.StatusInternalServerError,
+				)
+				return
+			}
+			sets[i] = valSet.Validators
+		}
+
+		d := DiffValidators(sets[0], sets[1])
+
+		type jsonValidator struct {
+			PubKey  []byte
+			Power   uint64
+			Moniker string `json:",omitempty"`
+		}
+		type jsonPowerChange struct {
+			PubKey   []byte
+			Moniker  string `json:",omitempty"`
+			OldPower uint64
+			NewPower uint64
+		}
+		resp := struct {
+			FromHeight, ToHeight uint64
+
+			Joined       []jsonValidator
+			Left         []jsonValidator
+			PowerChanged []jsonPowerChange
+		}{
+			FromHeight: from,
+			ToHeight:   to,
+
+			// Non-nil so that empty lists encode as [] rather than null.
+			Joined:       make([]jsonValidator, len(d.Joined)),
+			Left:         make([]jsonValidator, len(d.Left)),
+			PowerChanged: make([]jsonPowerChange, len(d.PowerChanged)),
+		}
+		for i, v := range d.Joined {
+			resp.Joined[i] = jsonValidator{
+				PubKey:  reg.Marshal(v.PubKey),
+				Power:   v.Power,
+				Moniker: cfg.ValidatorBook.Moniker(v.PubKey.PubKeyBytes()),
+			}
+		}
+		for i, v := range d.Left {
+			resp.Left[i] = jsonValidator{
+				PubKey:  reg.Marshal(v.PubKey),
+				Power:   v.Power,
+				Moniker: cfg.ValidatorBook.Moniker(v.PubKey.PubKeyBytes()),
+			}
+		}
+		for i, pc := range d.PowerChanged {
+			resp.PowerChanged[i] = jsonPowerChange{
+				PubKey:   reg.Marshal(pc.Validator.PubKey),
+				Moniker:  cfg.ValidatorBook.Moniker(pc.Validator.PubKey.PubKeyBytes()),
+				OldPower: pc.OldPower,
+				NewPower: pc.Validator.Power,
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Warn("Failed to marshal validator diff response", "err", err)
+			return
+		}
+	}
+}
+
+// Classes of a validator's participation in a commit,
+// reported by the commit signers endpoint.
+const (
+	// The validator's precommit for the committed block is in the commit proof.
+	commitClassInCommit = "in-commit"
+
+	// The commit proof holds the validator's precommit for nil or for another block.
+	commitClassOther = "other"
+
+	// The commit proof has no precommit from the validator.
+	// The validator may still have precommitted after the proof was collected,
+	// or its signature may have been dropped by commit proof compaction.
+	commitClassAbsent = "absent"
+)
+
+func handleCommitSigners(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	chs := cfg.CommittedHeaderStore
+	reg := cfg.CryptoRegistry
+	return func(w http.ResponseWriter, req *http.Request) {
+		if chs == nil {
+			httpError(w, "committed headers not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		height, err := strconv.ParseUint(req.URL.Query().Get("height"), 10, 64)
+		if err != nil {
+			httpError(w, "invalid height: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ch, err := chs.LoadCommittedHeader(req.Context(), height)
+		if err != nil {
+			if gcerr.CodeOf(err) == gcerr.CodeNotFound {
+				httpError(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			httpError(
+				w,
+				fmt.Sprintf("failed to load committed header: %v", err),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		vals := ch.Header.ValidatorSet.Validators
+		classes := make([]string, len(vals))
+		for i := range classes {
+			classes[i] = commitClassAbsent
+		}
+		for hash, sigs := range ch.Proof.Proofs {
+			class := commitClassOther
+			if hash == string(ch.Header.Hash) {
+				class = commitClassInCommit
+			}
+			for _, sig := range sigs {
+				// The chain always uses the simple signature proof scheme.
+				idx, ok := gcstore.SimpleKeyIndex(sig.KeyID)
+				if !ok || idx >= len(vals) {
+					log.Warn(
+						"Ignoring unrecognized key ID in commit proof",
+						"height", height,
+						"key_id", glog.Hex(sig.KeyID),
+					)
+					continue
+				}
+				classes[idx] = class
+			}
+		}
+
+		type jsonSigner struct {
+			PubKey  []byte
+			Moniker string `json:",omitempty"`
+			Power   uint64
+			Class   string
+		}
+		resp := struct {
+			Height    uint64
+			Round     uint32
+			BlockHash string
+
+			TotalPower     uint64
+			CommittedPower uint64
+
+			Validators []jsonSigner
+		}{
+			Height:    height,
+			Round:     ch.Proof.Round,
+			BlockHash: fmt.Sprintf("%X", ch.Header.Hash),
+
+			Validators: make([]jsonSigner, len(vals)),
+		}
+		for i, v := range vals {
+			resp.TotalPower += v.Power
+			if classes[i] == commitClassInCommit {
+				resp.CommittedPower += v.Power
+			}
+			resp.Validators[i] = jsonSigner{
+				PubKey:  reg.Marshal(v.PubKey),
+				Moniker: cfg.ValidatorBook.Moniker(v.PubKey.PubKeyBytes()),
+				Power:   v.Power,
+				Class:   classes[i],
+			}
+		}
+
+		// The power totals always cover the whole validator set.
+		var ok bool
+		resp.Validators, ok = paginate(w, req, resp.Validators, cfg.MaxPageSize)
+		if !ok {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Warn("Failed to marshal commit signers response", "err", err)
+			return
+		}
+	}
+}
+
+// handleNodeInfo reports the configured [NodeInfo]
+// and the libp2p protocols the host currently handles.
+func handleNodeInfo(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		resp := struct {
+			NodeInfo
+
+			// Non-nil so that an empty list encodes as [] rather than null.
+			Protocols []string
+		}{
+			NodeInfo:  cfg.NodeInfo,
+			Protocols: []string{},
+		}
+
+		if cfg.Libp2pHost != nil {
+			for _, p := range cfg.Libp2pHost.Libp2pHost().Mux().Protocols() {
+				resp.Protocols = append(resp.Protocols, string(p))
+			}
+			slices.Sort(resp.Protocols)
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Warn("Failed to marshal node info response", "err", err)
+			return
+		}
+	}
+}
+
+// loadCommittingValidators loads the validators at the committing height.
+// If loading fails, it writes an error to w and reports false.
+func loadCommittingValidators(
+	w http.ResponseWriter, req *http.Request, cfg HTTPServerConfig,
+) (uint64, []tmconsensus.Validator, bool) {
+	_, _, committingHeight, _, err := cfg.MirrorStore.NetworkHeightRound(req.Context())
+	if err != nil {
+		httpError(
+			w,
+			fmt.Sprintf("failed to get committing height: %v", err),
+			http.StatusInternalServerError,
+		)
+		return 0, nil, false
+	}
+
+	_, _, valSet, _, err := cfg.FinalizationStore.LoadFinalizationByHeight(req.Context(), committingHeight)
+	if err != nil {
+		httpError(
+			w,
+			fmt.Sprintf("failed to load finalization: %v", err),
+			http.StatusInternalServerError,
+		)
+		return 0, nil, false
+	}
+
+	return committingHeight, valSet.Validators, true
+}
+
+func handleCommittedHeader(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	chs := cfg.CommittedHeaderStore
+	codec := cfg.ConsensusCodec
+	return func(w http.ResponseWriter, req *http.Request) {
+		if chs == nil || codec == nil {
+			httpError(w, "committed headers not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		height, err := strconv.ParseUint(mux.Vars(req)["height"], 10, 64)
+		if err != nil {
+			httpError(w, "invalid height: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ch, err := chs.LoadCommittedHeader(req.Context(), height)
+		if err != nil {
+			if gcerr.CodeOf(err) == gcerr.CodeNotFound {
+				httpError(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			httpError(
+				w,
+				fmt.Sprintf("failed to load committed header: %v", err),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		b, err := codec.MarshalCommittedHeader(ch)
+		if err != nil {
+			httpError(
+				w,
+				fmt.Sprintf("failed to marshal committed header: %v", err),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(b); err != nil {
+			log.Warn("Failed to write committed header response", "err", err)
+			return
+		}
+	}
+}
+
+// handleFinalityProof serves the [gcverify.FinalityProof] for a height,
+// which is only available once the following height is committed too.
+func handleFinalityProof(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	chs := cfg.CommittedHeaderStore
+	codec := cfg.ConsensusCodec
+	return func(w http.ResponseWriter, req *http.Request) {
+		if chs == nil || codec == nil {
+			httpError(w, "committed headers not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		height, err := strconv.ParseUint(mux.Vars(req)["height"], 10, 64)
+		if err != nil {
+			httpError(w, "invalid height: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var p gcverify.FinalityProof
+		for i, ch := range []*tmconsensus.CommittedHeader{&p.Block, &p.Next} {
+			*ch, err = chs.LoadCommittedHeader(req.Context(), height+uint64(i))
+			if err != nil {
+				if gcerr.CodeOf(err) == gcerr.CodeNotFound {
+					httpError(w, err.Error(), http.StatusNotFound)
+					return
+				}
+				httpError(
+					w,
+					fmt.Sprintf("failed to load committed header: %v", err),
+					http.StatusInternalServerError,
+				)
+				return
+			}
+		}
+
+		b, err := gcverify.MarshalFinalityProof(p, codec)
+		if err != nil {
+			httpError(
+				w,
+				fmt.Sprintf("failed to marshal finality proof: %v", err),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(b); err != nil {
+			log.Warn("Failed to write finality proof response", "err", err)
+			return
+		}
+	}
+}
+
+// handleRoundHistory reports the round summaries of the most recent heights,
+// newest first and paginated, along with the height in progress.
+func handleRoundHistory(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	rh := cfg.RoundHistory
+	return func(w http.ResponseWriter, req *http.Request) {
+		if rh == nil {
+			httpError(w, "round history not enabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		completed, current := rh.Heights()
+		heights, ok := paginate(w, req, completed, cfg.MaxPageSize)
+		if !ok {
+			return
+		}
+
+		resp := struct {
+			Current *HeightHistory `json:",omitempty"`
+			Heights []HeightHistory
+		}{Current: current, Heights: heights}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Warn("Failed to encode round history response", "err", err)
+		}
+	}
+}
@@ -66,14 +66,14 @@ func (h compatHandler) HandleABCIInfo(w http.ResponseWriter, req *http.Request)
 			LastBlockAppHash: []byte{},
 		},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleABCIQuery(w http.ResponseWriter, req *http.Request) {
 	_ = &ctypes.ResultABCIQuery{
 		Response: abcitypes.QueryResponse{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBlock(w http.ResponseWriter, req *http.Request) {
@@ -83,7 +83,7 @@ func (h compatHandler) HandleBlock(w http.ResponseWriter, req *http.Request) {
 		BlockID: tmtypes.BlockID{},
 		Block:   &tmtypes.Block{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBlockByHash(w http.ResponseWriter, req *http.Request) {
@@ -93,7 +93,7 @@ func (h compatHandler) HandleBlockByHash(w http.ResponseWriter, req *http.Reques
 		BlockID: tmtypes.BlockID{},
 		Block:   &tmtypes.Block{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBlockResults(w http.ResponseWriter, req *http.Request) {
@@ -106,7 +106,7 @@ func (h compatHandler) HandleBlockResults(w http.ResponseWriter, req *http.Reque
 		ValidatorUpdates:      []abcitypes.ValidatorUpdate{},
 		ConsensusParamUpdates: &v1types.ConsensusParams{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBlockSearch(w http.ResponseWriter, req *http.Request) {
@@ -118,7 +118,7 @@ func (h compatHandler) HandleBlockSearch(w http.ResponseWriter, req *http.Reques
 		Blocks:     []*ctypes.ResultBlock{},
 		TotalCount: 0,
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBlockchainInfo(w http.ResponseWriter, req *http.Request) {
@@ -129,7 +129,7 @@ func (h compatHandler) HandleBlockchainInfo(w http.ResponseWriter, req *http.Req
 		LastHeight: 0,
 		BlockMetas: []*tmtypes.BlockMeta{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBroadcastEvidence(w http.ResponseWriter, req *http.Request) {
@@ -138,11 +138,11 @@ func (h compatHandler) HandleBroadcastEvidence(w http.ResponseWriter, req *http.
 	_ = &ctypes.ResultBroadcastEvidence{
 		Hash: []byte{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBroadcastTxAsync(w http.ResponseWriter, req *http.Request) {
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBroadcastTxCommit(w http.ResponseWriter, req *http.Request) {
@@ -151,24 +151,24 @@ func (h compatHandler) HandleBroadcastTxCommit(w http.ResponseWriter, req *http.
 		TxResult: abcitypes.ExecTxResult{},
 		Hash:     []byte{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleBroadcastTxSync(w http.ResponseWriter, req *http.Request) {
 	_ = &ctypes.ResultBroadcastTx{}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleCheckTx(w http.ResponseWriter, req *http.Request) {
 	_ = &ctypes.ResultCheckTx{}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleCommit(w http.ResponseWriter, req *http.Request) {
 	// Request
 	// height *int64
 	_ = ctypes.NewResultCommit(&tmtypes.Header{}, &tmtypes.Commit{}, true)
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleConsensusParams(w http.ResponseWriter, req *http.Request) {
@@ -176,14 +176,14 @@ func (h compatHandler) HandleConsensusParams(w http.ResponseWriter, req *http.Re
 		BlockHeight:     0,
 		ConsensusParams: tmtypes.ConsensusParams{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleConsensusState(w http.ResponseWriter, req *http.Request) {
 	_ = &ctypes.ResultConsensusState{
 		RoundState: json.RawMessage{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleDumpConsensusState(w http.ResponseWriter, req *http.Request) {
@@ -191,16 +191,16 @@ func (h compatHandler) HandleDumpConsensusState(w http.ResponseWriter, req *http
 		RoundState: json.RawMessage{},
 		Peers:      []ctypes.PeerStateInfo{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleGenesis(w http.ResponseWriter, req *http.Request) {
 	_ = ctypes.ResultGenesis{}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleGenesisChunked(w http.ResponseWriter, req *http.Request) {
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleHeader(w http.ResponseWriter, req *http.Request) {
@@ -209,7 +209,7 @@ func (h compatHandler) HandleHeader(w http.ResponseWriter, req *http.Request) {
 	_ = &ctypes.ResultHeader{
 		Header: &tmtypes.Header{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleHeaderByHash(w http.ResponseWriter, req *http.Request) {
@@ -218,12 +218,12 @@ func (h compatHandler) HandleHeaderByHash(w http.ResponseWriter, req *http.Reque
 	_ = &ctypes.ResultHeader{
 		Header: &tmtypes.Header{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleHealth(w http.ResponseWriter, req *http.Request) {
 	_ = &ctypes.ResultHealth{}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleNetInfo(w http.ResponseWriter, req *http.Request) {
@@ -233,7 +233,7 @@ func (h compatHandler) HandleNetInfo(w http.ResponseWriter, req *http.Request) {
 		NPeers:    0,
 		Peers:     []ctypes.Peer{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleNumUnconfirmedTxs(w http.ResponseWriter, req *http.Request) {
@@ -242,7 +242,7 @@ func (h compatHandler) HandleNumUnconfirmedTxs(w http.ResponseWriter, req *http.
 		Total:      0,
 		TotalBytes: 0,
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleStatus(w http.ResponseWriter, req *http.Request) {
@@ -279,12 +279,12 @@ func (h compatHandler) HandleStatus(w http.ResponseWriter, req *http.Request) {
 			VotingPower: 0,
 		},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleSubscribe(w http.ResponseWriter, req *http.Request) {
 	// TODO: subscribe to events via a websocket. Would be nice to add this feature as some interfaces rely on it
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleTx(w http.ResponseWriter, req *http.Request) {
@@ -298,7 +298,7 @@ func (h compatHandler) HandleTx(w http.ResponseWriter, req *http.Request) {
 		Tx:       tmtypes.Tx{},
 		Proof:    tmtypes.TxProof{},
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleTxSearch(w http.ResponseWriter, req *http.Request) {
@@ -312,7 +312,7 @@ func (h compatHandler) HandleTxSearch(w http.ResponseWriter, req *http.Request)
 		Txs:        []*ctypes.ResultTx{},
 		TotalCount: 0,
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleUnconfirmedTxs(w http.ResponseWriter, req *http.Request) {
@@ -322,17 +322,17 @@ func (h compatHandler) HandleUnconfirmedTxs(w http.ResponseWriter, req *http.Req
 		Txs:        []tmtypes.Tx{},
 		TotalBytes: 0,
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleUnsubscribe(w http.ResponseWriter, req *http.Request) {
 	// TODO: subscribe to events via a websocket. Would be nice to add this feature as some interfaces rely on it
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleUnsubscribeAll(w http.ResponseWriter, req *http.Request) {
 	// TODO: subscribe to events via a websocket. Would be nice to add this feature as some interfaces rely on it
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
 
 func (h compatHandler) HandleValidators(w http.ResponseWriter, req *http.Request) {
@@ -346,5 +346,5 @@ func (h compatHandler) HandleValidators(w http.ResponseWriter, req *http.Request
 		Count:       0,
 		Total:       0,
 	}
-	http.Error(w, "not yet implemented", http.StatusNotImplemented)
+	httpError(w, "not yet implemented", http.StatusNotImplemented)
 }
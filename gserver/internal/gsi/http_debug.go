@@ -2,15 +2,30 @@ package gsi
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"cosmossdk.io/core/transaction"
 	"cosmossdk.io/server/v2/appmanager"
 	banktypes "cosmossdk.io/x/bank/types"
+	stakingtypes "cosmossdk.io/x/staking/types"
 	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gbackpressure"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gclock"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gevidence"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gingress"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gliveness"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpersist"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsbd"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gstall"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine"
+	"github.com/gordian-engine/gordian/tm/tmstore"
 	"github.com/gorilla/mux"
 )
 
@@ -22,7 +37,27 @@ type debugHandler struct {
 
 	am appmanager.AppManager[transaction.Tx]
 
-	txBuf *SDKTxBuf
+	txBuf  *SDKTxBuf
+	txPool *TxPool
+
+	maxPageSize int
+
+	ms tmstore.MirrorStore
+	ts tmengine.TimeoutStrategy
+
+	stall *gstall.Detector
+	clock *gclock.Monitor
+	hb    *gliveness.Heartbeater
+	bp    *gbackpressure.Registry
+	forks *gevidence.Exchange
+	rs    *gpersist.RoundStore
+
+	bdrCache *gsbd.RequestCache
+	guard    *gingress.Guard
+	dedupe   *gingress.Deduper
+
+	phHandler tmconsensus.FineGrainedConsensusHandler
+	tmCodec   tmcodec.Unmarshaler
 }
 
 func setDebugRoutes(log *slog.Logger, cfg HTTPServerConfig, r *mux.Router) {
@@ -32,15 +67,48 @@ func setDebugRoutes(log *slog.Logger, cfg HTTPServerConfig, r *mux.Router) {
 		codec:   cfg.Codec,
 		am:      cfg.AppManager,
 
-		txBuf: cfg.TxBuffer,
+		txBuf:  cfg.TxBuffer,
+		txPool: cfg.TxPool,
+
+		maxPageSize: cfg.MaxPageSize,
+
+		ms: cfg.MirrorStore,
+		ts: cfg.TimeoutStrategy,
+
+		stall: cfg.StallDetector,
+		clock: cfg.ClockSkew,
+		hb:    cfg.Heartbeats,
+		bp:    cfg.Backpressure,
+		forks: cfg.ForkEvidence,
+		rs:    cfg.RoundStore,
+
+		bdrCache: cfg.BlockDataCache,
+		guard:    cfg.IngressGuard,
+		dedupe:   cfg.IngressDedupe,
+
+		phHandler: cfg.ProposedHeaderHandler,
+		tmCodec:   cfg.ConsensusCodec,
 	}
 
 	r.HandleFunc("/debug/submit_tx", h.HandleSubmitTx).Methods("POST")
 	r.HandleFunc("/debug/simulate_tx", h.HandleSimulateTx).Methods("POST")
 
+	r.HandleFunc("/debug/submit_proposed_header", h.HandleSubmitProposedHeader).Methods("POST")
+	r.HandleFunc("/debug/submit_fork_evidence", h.HandleSubmitForkEvidence).Methods("POST")
+
 	r.HandleFunc("/debug/pending_txs", h.HandlePendingTxs).Methods("GET")
+	r.HandleFunc("/debug/pending_txs/{hash:[0-9a-fA-F]{64}}", h.HandlePendingTx).Methods("GET")
 
 	r.HandleFunc("/debug/accounts/{id}/balance", h.HandleAccountBalance).Methods("GET")
+	r.HandleFunc("/debug/staking/validators", h.HandleStakingValidators).Methods("GET")
+
+	r.HandleFunc("/debug/timeouts", h.HandleTimeouts).Methods("GET")
+	r.HandleFunc("/debug/stall", h.HandleStall).Methods("GET")
+	r.HandleFunc("/debug/clock_skew", h.HandleClockSkew).Methods("GET")
+	r.HandleFunc("/debug/heartbeats", h.HandleHeartbeats).Methods("GET")
+	r.HandleFunc("/debug/backpressure", h.HandleBackpressure).Methods("GET")
+	r.HandleFunc("/debug/round_store", h.HandleRoundStore).Methods("GET")
+	r.HandleFunc("/debug/memory", h.HandleMemory).Methods("GET")
 }
 
 func (h debugHandler) HandleSubmitTx(w http.ResponseWriter, req *http.Request) {
@@ -49,14 +117,14 @@ func (h debugHandler) HandleSubmitTx(w http.ResponseWriter, req *http.Request) {
 	b, err := io.ReadAll(req.Body)
 	if err != nil {
 		h.log.Warn("Failed to read request body", "route", "submit_tx", "err", err)
-		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		httpError(w, "failed to read request body", http.StatusBadRequest)
 		return
 	}
 
 	tx, err := h.txCodec.DecodeJSON(b)
 	if err != nil {
 		h.log.Warn("Failed to decode transaction", "route", "submit_tx", "err", err)
-		http.Error(w, "failed to decode transaction", http.StatusBadRequest)
+		httpError(w, "failed to decode transaction", http.StatusBadRequest)
 		return
 	}
 
@@ -71,22 +139,23 @@ func (h debugHandler) HandleSubmitTx(w http.ResponseWriter, req *http.Request) {
 		// ValidateTx should only return an error at this level,
 		// if it failed to get state from the store.
 		h.log.Warn("Error attempting to validate transaction", "route", "submit_tx", "err", err)
-		http.Error(w, "internal error while attempting to validate transaction", http.StatusInternalServerError)
+		httpError(w, "internal error while attempting to validate transaction", http.StatusInternalServerError)
 		return
 	}
 
 	if res.Error != nil {
 		// This is fine from the server's perspective, no need to log.
-		http.Error(w, "transaction validation failed: "+res.Error.Error(), http.StatusBadRequest)
+		appError(w, "transaction validation failed: ", res.Error, http.StatusBadRequest)
 		return
 	}
 
 	// If it passed basic validation, then we can attempt to add it to the buffer.
-	if err := h.txBuf.AddTx(ctx, tx); err != nil {
+	if err := h.txPool.AddTx(ctx, tx); err != nil {
 		// We could potentially check if it is a TxInvalidError here
 		// and adjust the status code,
 		// but since this is a debug endpoint, we'll ignore the type.
-		http.Error(w, "failed to add transaction to buffer: "+err.Error(), http.StatusBadRequest)
+		// An error from the app keeps its codespace and code.
+		appError(w, "failed to add transaction to buffer: ", err, http.StatusBadRequest)
 		return
 	}
 
@@ -101,14 +170,14 @@ func (h debugHandler) HandleSimulateTx(w http.ResponseWriter, req *http.Request)
 	b, err := io.ReadAll(req.Body)
 	if err != nil {
 		h.log.Warn("Failed to read request body", "route", "simulate_tx", "err", err)
-		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		httpError(w, "failed to read request body", http.StatusBadRequest)
 		return
 	}
 
 	tx, err := h.txCodec.DecodeJSON(b)
 	if err != nil {
 		h.log.Warn("Failed to decode transaction", "route", "simulate_tx", "err", err)
-		http.Error(w, "failed to decode transaction", http.StatusBadRequest)
+		httpError(w, "failed to decode transaction", http.StatusBadRequest)
 		return
 	}
 
@@ -123,13 +192,13 @@ func (h debugHandler) HandleSimulateTx(w http.ResponseWriter, req *http.Request)
 		// Simulate should only return an error at this level,
 		// if it failed to get state from the store.
 		h.log.Warn("Error attempting to simulate transaction", "route", "simulate_tx", "err", err)
-		http.Error(w, "internal error while attempting to simulate transaction", http.StatusInternalServerError)
+		httpError(w, "internal error while attempting to simulate transaction", http.StatusInternalServerError)
 		return
 	}
 
 	if res.Error != nil {
 		// This is fine from the server's perspective, no need to log.
-		http.Error(w, "transaction simulation failed: "+res.Error.Error(), http.StatusBadRequest)
+		appError(w, "transaction simulation failed: ", res.Error, http.StatusBadRequest)
 		return
 	}
 
@@ -138,16 +207,98 @@ func (h debugHandler) HandleSimulateTx(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// HandleSubmitProposedHeader accepts a proposed header built out-of-band,
+// such as by an external block builder,
+// and passes it to the engine as though it arrived from the network.
+func (h debugHandler) HandleSubmitProposedHeader(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.phHandler == nil || h.tmCodec == nil {
+		httpError(w, "proposed header submission not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		httpError(w, "failed to read request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var ph tmconsensus.ProposedHeader
+	if err := h.tmCodec.UnmarshalProposedHeader(b, &ph); err != nil {
+		httpError(w, "failed to decode proposed header: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := h.phHandler.HandleProposedHeader(req.Context(), ph)
+
+	var resp struct {
+		Result string
+	}
+	resp.Result = res.String()
+
+	switch res {
+	case tmconsensus.HandleProposedHeaderAccepted, tmconsensus.HandleProposedHeaderAlreadyStored:
+		// Default status is fine.
+	case tmconsensus.HandleProposedHeaderInternalError:
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Warn("Failed to encode submit_proposed_header result", "err", err)
+	}
+}
+
+// HandleSubmitForkEvidence accepts a pair of conflicting committed headers
+// and, if they are valid evidence of a fork, alerts the operator
+// and forwards the evidence to connected peers.
+func (h debugHandler) HandleSubmitForkEvidence(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.forks == nil || h.tmCodec == nil {
+		httpError(w, "fork evidence exchange not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var in jsonForkEvidence
+	if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+		httpError(w, "failed to decode request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var ev gevidence.Evidence
+	if err := h.tmCodec.UnmarshalCommittedHeader(in.A, &ev.A); err != nil {
+		httpError(w, "failed to decode committed header A: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.tmCodec.UnmarshalCommittedHeader(in.B, &ev.B); err != nil {
+		httpError(w, "failed to decode committed header B: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.forks.Submit(req.Context(), ev); err != nil {
+		httpError(w, "invalid fork evidence: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h debugHandler) HandlePendingTxs(w http.ResponseWriter, req *http.Request) {
 	defer req.Body.Close()
 
-	txs := h.txBuf.Buffered(req.Context(), nil)
+	txs, ok := paginate(w, req, h.txBuf.Buffered(req.Context(), nil), h.maxPageSize)
+	if !ok {
+		return
+	}
 
 	encodedTxs := make([]json.RawMessage, len(txs))
 	for i, tx := range txs {
 		b, err := json.Marshal(tx)
 		if err != nil {
-			http.Error(w, "failed to encode transaction: "+err.Error(), http.StatusInternalServerError)
+			httpError(w, "failed to encode transaction: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 		encodedTxs[i] = json.RawMessage(b)
@@ -158,25 +309,269 @@ func (h debugHandler) HandlePendingTxs(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// HandlePendingTx reports whether the transaction with the given hex-encoded hash
+// is still in the transaction buffer.
+func (h debugHandler) HandlePendingTx(w http.ResponseWriter, req *http.Request) {
+	want, err := hex.DecodeString(mux.Vars(req)["hash"])
+	if err != nil {
+		httpError(w, "invalid hash: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resp struct {
+		Pending bool
+	}
+	for _, tx := range h.txBuf.Buffered(req.Context(), nil) {
+		if hash := tx.Hash(); bytes.Equal(hash[:], want) {
+			resp.Pending = true
+			break
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Warn("Failed to encode pending transaction result", "err", err)
+	}
+}
+
 func (h debugHandler) HandleAccountBalance(w http.ResponseWriter, r *http.Request) {
 	accountID := mux.Vars(r)["id"]
 
+	denom := r.URL.Query().Get("denom")
+	if denom == "" {
+		denom = "stake"
+	}
+
 	msg, err := h.am.Query(r.Context(), 0, &banktypes.QueryBalanceRequest{
 		Address: accountID,
-		Denom:   "stake",
+		Denom:   denom,
 	})
 	if err != nil {
 		h.log.Warn("Failed to query account balance", "id", accountID, "err", err)
-		http.Error(w, "query failed", http.StatusBadRequest)
+		httpError(w, "query failed", http.StatusBadRequest)
 		return
 	}
 
 	b, err := h.codec.MarshalJSON(msg)
 	if err != nil {
-		http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		httpError(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 	if _, err := io.Copy(w, bytes.NewReader(b)); err != nil {
 		h.log.Warn("Failed to encode account balance response", "err", err)
 	}
 }
+
+// HandleStakingValidators reports the staking module's validators,
+// as opposed to the consensus validator set reported by the /validators route.
+func (h debugHandler) HandleStakingValidators(w http.ResponseWriter, r *http.Request) {
+	msg, err := h.am.Query(r.Context(), 0, &stakingtypes.QueryValidatorsRequest{})
+	if err != nil {
+		h.log.Warn("Failed to query staking validators", "err", err)
+		httpError(w, "query failed", http.StatusBadRequest)
+		return
+	}
+
+	b, err := h.codec.MarshalJSON(msg)
+	if err != nil {
+		httpError(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := io.Copy(w, bytes.NewReader(b)); err != nil {
+		h.log.Warn("Failed to encode staking validators response", "err", err)
+	}
+}
+
+// HandleTimeouts reports the effective timeouts for the current voting round,
+// so that an operator can reason about how long a stalled round may take to advance.
+func (h debugHandler) HandleTimeouts(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.ts == nil {
+		httpError(w, "no timeout strategy configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	vh, vr, _, _, err := h.ms.NetworkHeightRound(req.Context())
+	if err != nil {
+		httpError(w, "failed to get network height and round: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var resp struct {
+		VotingHeight uint64
+		VotingRound  uint32
+
+		Escalation TimeoutEscalation `json:",omitempty"`
+
+		ProposalTimeout       JSONDuration
+		PrevoteDelayTimeout   JSONDuration
+		PrecommitDelayTimeout JSONDuration
+		CommitWaitTimeout     JSONDuration
+	}
+
+	resp.VotingHeight = vh
+	resp.VotingRound = vr
+
+	if ts, ok := h.ts.(TimeoutStrategy); ok {
+		resp.Escalation = ts.Escalation
+		if resp.Escalation == "" {
+			resp.Escalation = TimeoutEscalationLinear
+		}
+	}
+
+	resp.ProposalTimeout = JSONDuration(h.ts.ProposalTimeout(vh, vr))
+	resp.PrevoteDelayTimeout = JSONDuration(h.ts.PrevoteDelayTimeout(vh, vr))
+	resp.PrecommitDelayTimeout = JSONDuration(h.ts.PrecommitDelayTimeout(vh, vr))
+	resp.CommitWaitTimeout = JSONDuration(h.ts.CommitWaitTimeout(vh, vr))
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Warn("Failed to encode timeouts response", "err", err)
+	}
+}
+
+func (h debugHandler) HandleStall(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.stall == nil {
+		httpError(w, "stall detection not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(h.stall.Status()); err != nil {
+		h.log.Warn("Failed to encode stall status response", "err", err)
+	}
+}
+
+func (h debugHandler) HandleClockSkew(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.clock == nil {
+		httpError(w, "clock skew monitoring not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	type peerSkew struct {
+		Peer string
+
+		Skew, RTT JSONDuration
+
+		MeasuredAt time.Time
+
+		Exceeds bool
+	}
+
+	skews := h.clock.Skews()
+	resp := make([]peerSkew, len(skews))
+	for i, s := range skews {
+		resp[i] = peerSkew{
+			Peer: s.Peer,
+
+			Skew: JSONDuration(s.Skew),
+			RTT:  JSONDuration(s.RTT),
+
+			MeasuredAt: s.MeasuredAt,
+
+			Exceeds: s.Exceeds,
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Warn("Failed to encode clock skew response", "err", err)
+	}
+}
+
+func (h debugHandler) HandleHeartbeats(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.hb == nil {
+		httpError(w, "heartbeats not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(h.hb.Peers()); err != nil {
+		h.log.Warn("Failed to encode heartbeats response", "err", err)
+	}
+}
+
+func (h debugHandler) HandleRoundStore(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.rs == nil {
+		httpError(w, "round store retries not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(h.rs.Health()); err != nil {
+		h.log.Warn("Failed to encode round store health response", "err", err)
+	}
+}
+
+func (h debugHandler) HandleBackpressure(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.bp == nil {
+		httpError(w, "no backpressure measurements configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	type pathStats struct {
+		Name string
+
+		InFlight, MaxInFlight int64
+
+		Count uint64
+
+		TotalBlocked, MaxBlocked JSONDuration
+	}
+
+	stats := h.bp.Stats()
+	resp := make([]pathStats, len(stats))
+	for i, s := range stats {
+		resp[i] = pathStats{
+			Name: s.Name,
+
+			InFlight:    s.InFlight,
+			MaxInFlight: s.MaxInFlight,
+
+			Count: s.Count,
+
+			TotalBlocked: JSONDuration(s.TotalBlocked),
+			MaxBlocked:   JSONDuration(s.MaxBlocked),
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Warn("Failed to encode backpressure response", "err", err)
+	}
+}
+
+func (h debugHandler) HandleMemory(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if h.bdrCache == nil && h.guard == nil && h.dedupe == nil {
+		httpError(w, "no memory accounting configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var resp struct {
+		BlockDataCache *gsbd.RequestCacheStats `json:",omitempty"`
+		IngressGuard   *gingress.GuardStats    `json:",omitempty"`
+		IngressDedupe  *gingress.DedupeStats   `json:",omitempty"`
+	}
+	if h.bdrCache != nil {
+		s := h.bdrCache.Stats()
+		resp.BlockDataCache = &s
+	}
+	if h.guard != nil {
+		s := h.guard.Stats()
+		resp.IngressGuard = &s
+	}
+	if h.dedupe != nil {
+		s := h.dedupe.Stats()
+		resp.IngressDedupe = &s
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Warn("Failed to encode memory response", "err", err)
+	}
+}
@@ -0,0 +1,89 @@
+package gsi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	errorsmod "cosmossdk.io/errors"
+)
+
+// Codespace is the codespace of errors raised by the HTTP server itself.
+// Errors returned by the app keep the codespace and code the app assigned them,
+// so that a client can tell, for instance, insufficient funds from a malformed request.
+const Codespace = "gserver"
+
+// Server errors, one per HTTP status the server responds with.
+// Code 1 is reserved for internal errors by SDK convention,
+// so the codes start at 2.
+var (
+	errInternal       = errorsmod.Register(Codespace, 2, "internal error")
+	errInvalidRequest = errorsmod.Register(Codespace, 3, "invalid request")
+	errNotFound       = errorsmod.Register(Codespace, 4, "not found")
+	errNotEnabled     = errorsmod.Register(Codespace, 5, "not enabled")
+	errNotImplemented = errorsmod.Register(Codespace, 6, "not implemented")
+)
+
+// errorResponse is the JSON body of every error response,
+// matching the codespace, code and log fields of an SDK ABCI result.
+type errorResponse struct {
+	Codespace string `json:"codespace"`
+	Code      uint32 `json:"code"`
+	Log       string `json:"log"`
+}
+
+// statusError returns the server error reported with status.
+func statusError(status int) *errorsmod.Error {
+	switch status {
+	case http.StatusBadRequest:
+		return errInvalidRequest
+	case http.StatusNotFound:
+		return errNotFound
+	case http.StatusNotImplemented:
+		return errNotImplemented
+	case http.StatusServiceUnavailable:
+		return errNotEnabled
+	default:
+		return errInternal
+	}
+}
+
+// httpError replies to the request with msg as an error response,
+// in place of [http.Error].
+// The codespace and code are those of the server error for status.
+func httpError(w http.ResponseWriter, msg string, status int) {
+	e := statusError(status)
+	writeErrorResponse(w, status, errorResponse{
+		Codespace: e.Codespace(),
+		Code:      e.ABCICode(),
+		Log:       msg,
+	})
+}
+
+// appError replies to the request with an error response for err,
+// an error returned by the app, with its log prefixed by msg.
+// The response keeps err's codespace and code if the app registered them,
+// and otherwise uses those of the server error for status.
+func appError(w http.ResponseWriter, msg string, err error, status int) {
+	codespace, code, log := errorsmod.ABCIInfo(err, false)
+	if codespace == errorsmod.UndefinedCodespace {
+		e := statusError(status)
+		codespace, code = e.Codespace(), e.ABCICode()
+	}
+	writeErrorResponse(w, status, errorResponse{
+		Codespace: codespace,
+		Code:      code,
+		Log:       msg + log,
+	})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, resp errorResponse) {
+	// Like http.Error, drop any headers set for a successful response.
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	// The status is already written, so there is nothing more to do on failure.
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,240 @@
+package gsi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"cosmossdk.io/core/transaction"
+	"cosmossdk.io/server/v2/appmanager"
+	"github.com/cosmos/cosmos-sdk/codec"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+// simulateTxTimeout bounds a single simulation,
+// so that an expensive transaction cannot hold a request open indefinitely.
+const simulateTxTimeout = 10 * time.Second
+
+// simulateTxRequest is the body of a simulate_tx request.
+// It accepts the same tx_bytes field as the SDK's simulate request,
+// or a JSON-encoded transaction in the tx field.
+type simulateTxRequest struct {
+	TxBytes []byte          `json:"tx_bytes"`
+	Tx      json.RawMessage `json:"tx"`
+}
+
+// simulateTxResponse mirrors the JSON encoding of the SDK's simulate response,
+// so that clients estimating gas can decode it unchanged.
+type simulateTxResponse struct {
+	GasInfo struct {
+		GasWanted uint64 `json:"gas_wanted,string"`
+		GasUsed   uint64 `json:"gas_used,string"`
+	} `json:"gas_info"`
+
+	Result struct {
+		Log          string            `json:"log"`
+		Events       []simulateEvent   `json:"events"`
+		MsgResponses []json.RawMessage `json:"msg_responses"`
+	} `json:"result"`
+}
+
+type simulateEvent struct {
+	Type       string              `json:"type"`
+	Attributes []simulateAttribute `json:"attributes"`
+}
+
+type simulateAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Index bool   `json:"index"`
+}
+
+// handleSimulateTx runs a transaction through the app's simulation path
+// and reports its estimated gas and events, without submitting it.
+//
+// A transaction without signer infos is simulated as if signed
+// by the senders of its messages, at their current account sequences,
+// so that clients can estimate gas before signing.
+func handleSimulateTx(log *slog.Logger, cfg HTTPServerConfig) func(w http.ResponseWriter, req *http.Request) {
+	am := cfg.AppManager
+	txc := cfg.TxCodec
+	cdc := cfg.Codec
+	return func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		if am == nil || txc == nil || cdc == nil {
+			httpError(w, "transaction simulation not enabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			httpError(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		var in simulateTxRequest
+		if err := json.Unmarshal(b, &in); err != nil {
+			httpError(w, "failed to decode request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var tx transaction.Tx
+		switch {
+		case len(in.TxBytes) > 0 && len(in.Tx) > 0:
+			httpError(w, "only one of tx_bytes and tx may be set", http.StatusBadRequest)
+			return
+		case len(in.TxBytes) > 0:
+			tx, err = txc.Decode(in.TxBytes)
+		case len(in.Tx) > 0:
+			tx, err = txc.DecodeJSON(in.Tx)
+		default:
+			httpError(w, "one of tx_bytes and tx is required", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			httpError(w, "failed to decode transaction: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), simulateTxTimeout)
+		defer cancel()
+
+		tx, err = inferSigners(ctx, am, txc, cdc, tx)
+		if err != nil {
+			httpError(w, "failed to infer transaction signers: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, _, err := am.Simulate(ctx, tx)
+		if err != nil {
+			// Simulate should only return an error at this level,
+			// if it failed to get state from the store.
+			log.Warn("Error attempting to simulate transaction", "route", "simulate_tx", "err", err)
+			httpError(w, "internal error while attempting to simulate transaction", http.StatusInternalServerError)
+			return
+		}
+
+		if res.Error != nil {
+			// This is fine from the server's perspective, no need to log.
+			appError(w, "transaction simulation failed: ", res.Error, http.StatusBadRequest)
+			return
+		}
+
+		var out simulateTxResponse
+		out.GasInfo.GasWanted = res.GasWanted
+		out.GasInfo.GasUsed = res.GasUsed
+
+		out.Result.Events = make([]simulateEvent, len(res.Events))
+		for i, ev := range res.Events {
+			attrs, err := ev.Attributes()
+			if err != nil {
+				httpError(w, "failed to extract result events: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			se := simulateEvent{
+				Type:       ev.Type,
+				Attributes: make([]simulateAttribute, len(attrs)),
+			}
+			for j, a := range attrs {
+				se.Attributes[j] = simulateAttribute{Key: a.Key, Value: a.Value, Index: true}
+			}
+			out.Result.Events[i] = se
+		}
+
+		out.Result.MsgResponses = make([]json.RawMessage, len(res.Resp))
+		for i, msg := range res.Resp {
+			mb, err := cdc.MarshalInterfaceJSON(msg)
+			if err != nil {
+				httpError(w, "failed to encode message response: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			out.Result.MsgResponses[i] = mb
+		}
+
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			log.Warn("Failed to encode simulate_tx result", "err", err)
+		}
+	}
+}
+
+// inferSigners returns tx unchanged if it has signer infos.
+// Otherwise it returns a copy of tx with a direct-mode signer info
+// and an empty signature for each sender,
+// using the sender's public key and sequence from the auth module.
+// Signature verification is skipped in simulation,
+// and an account without a public key is given a placeholder by the ante handler.
+func inferSigners(
+	ctx context.Context,
+	am appmanager.AppManager[transaction.Tx],
+	txc transaction.Codec[transaction.Tx],
+	cdc codec.Codec,
+	tx transaction.Tx,
+) (transaction.Tx, error) {
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(tx.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to decode raw transaction: %w", err)
+	}
+	var authInfo txtypes.AuthInfo
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		return nil, fmt.Errorf("failed to decode auth info: %w", err)
+	}
+	if len(authInfo.SignerInfos) > 0 {
+		return tx, nil
+	}
+
+	senders, err := tx.GetSenders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message senders: %w", err)
+	}
+	if len(senders) == 0 {
+		return nil, errors.New("transaction has no senders")
+	}
+
+	ac := cdc.InterfaceRegistry().SigningContext().AddressCodec()
+	raw.Signatures = make([][]byte, len(senders))
+	for i, sender := range senders {
+		addr, err := ac.BytesToString(sender)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode sender %d address: %w", i, err)
+		}
+
+		resp, err := am.Query(ctx, 0, &authtypes.QueryAccountInfoRequest{Address: addr})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up account %s: %w", addr, err)
+		}
+		info, ok := resp.(*authtypes.QueryAccountInfoResponse)
+		if !ok || info.Info == nil {
+			return nil, fmt.Errorf("no account info for %s", addr)
+		}
+
+		authInfo.SignerInfos = append(authInfo.SignerInfos, &txtypes.SignerInfo{
+			PublicKey: info.Info.PubKey,
+			ModeInfo: &txtypes.ModeInfo{
+				Sum: &txtypes.ModeInfo_Single_{
+					Single: &txtypes.ModeInfo_Single{Mode: signing.SignMode_SIGN_MODE_DIRECT},
+				},
+			},
+			Sequence: info.Info.Sequence,
+		})
+		raw.Signatures[i] = []byte{}
+	}
+
+	raw.AuthInfoBytes, err = authInfo.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode auth info: %w", err)
+	}
+	b, err := raw.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode raw transaction: %w", err)
+	}
+	return txc.Decode(b)
+}
@@ -1,15 +1,22 @@
 package gsi_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net"
 	"net/http"
+	"path/filepath"
+	"sync"
 	"testing"
 
+	"github.com/gordian-engine/gcosmos/gserver/internal/gaudit"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gready"
 	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
 	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
 	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
 	"github.com/gordian-engine/gordian/tm/tmconsensus"
 	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
 	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
@@ -168,3 +175,627 @@ func TestHTTPServer_Validators(t *testing.T) {
 
 	require.True(t, tmconsensus.ValidatorSlicesEqual(valSet.Validators, outVals))
 }
+
+func TestHTTPServer_SubmitProposedHeader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := "http://" + ln.Addr().String() + "/debug/submit_proposed_header"
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+	codec := tmjson.MarshalCodec{CryptoRegistry: reg}
+
+	phHandler := &recordingPHHandler{res: tmconsensus.HandleProposedHeaderAccepted}
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener:    ln,
+		MirrorStore: tmmemstore.NewMirrorStore(),
+
+		CryptoRegistry: reg,
+
+		ProposedHeaderHandler: phHandler,
+		ConsensusCodec:        codec,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(2)
+	ph := fx.NextProposedHeader([]byte("app_data"), 0)
+	fx.SignProposal(ctx, &ph, 0)
+
+	b, err := codec.MarshalProposedHeader(ph)
+	require.NoError(t, err)
+
+	submit := func(body []byte) (int, string) {
+		resp, err := http.Post(addr, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var output struct {
+			Result string
+		}
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusBadRequest {
+			// A malformed body is reported as plain text, so ignore decode errors.
+			_ = json.NewDecoder(resp.Body).Decode(&output)
+		}
+		return resp.StatusCode, output.Result
+	}
+
+	t.Run("accepted header", func(t *testing.T) {
+		code, res := submit(b)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "Accepted", res)
+
+		got := phHandler.Last()
+		require.Equal(t, ph.Header.Hash, got.Header.Hash)
+		require.Equal(t, ph.Signature, got.Signature)
+	})
+
+	t.Run("rejected header", func(t *testing.T) {
+		phHandler.SetResult(tmconsensus.HandleProposedHeaderBadSignature)
+
+		code, res := submit(b)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Equal(t, "BadSignature", res)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		code, _ := submit([]byte("not a header"))
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+type recordingPHHandler struct {
+	mu   sync.Mutex
+	res  tmconsensus.HandleProposedHeaderResult
+	last tmconsensus.ProposedHeader
+}
+
+func (h *recordingPHHandler) SetResult(res tmconsensus.HandleProposedHeaderResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.res = res
+}
+
+func (h *recordingPHHandler) Last() tmconsensus.ProposedHeader {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+func (h *recordingPHHandler) HandleProposedHeader(
+	_ context.Context, ph tmconsensus.ProposedHeader,
+) tmconsensus.HandleProposedHeaderResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = ph
+	return h.res
+}
+
+func (h *recordingPHHandler) HandlePrevoteProofs(
+	context.Context, tmconsensus.PrevoteSparseProof,
+) tmconsensus.HandleVoteProofsResult {
+	panic("not called in test")
+}
+
+func (h *recordingPHHandler) HandlePrecommitProofs(
+	context.Context, tmconsensus.PrecommitSparseProof,
+) tmconsensus.HandleVoteProofsResult {
+	panic("not called in test")
+}
+
+func TestHTTPServer_CommittedHeader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := "http://" + ln.Addr().String() + "/headers/"
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+	codec := tmjson.MarshalCodec{CryptoRegistry: reg}
+
+	chs := tmmemstore.NewCommittedHeaderStore()
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener:    ln,
+		MirrorStore: tmmemstore.NewMirrorStore(),
+
+		CommittedHeaderStore: chs,
+
+		CryptoRegistry: reg,
+
+		ConsensusCodec: codec,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(2)
+	ph := fx.NextProposedHeader([]byte("app_data"), 0)
+	precommitProofs := fx.PrecommitProofMap(ctx, 1, 0, map[string][]int{
+		string(ph.Header.Hash): {0, 1},
+	})
+	fx.CommitBlock(ph.Header, []byte("app_state"), 0, precommitProofs)
+	nextPH := fx.NextProposedHeader([]byte("app_data"), 0)
+	require.NoError(t, chs.SaveCommittedHeader(ctx, tmconsensus.CommittedHeader{
+		Header: ph.Header,
+		Proof:  nextPH.Header.PrevCommitProof,
+	}))
+
+	t.Run("known height", func(t *testing.T) {
+		resp, err := http.Get(addr + "1")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var ch tmconsensus.CommittedHeader
+		require.NoError(t, codec.UnmarshalCommittedHeader(b, &ch))
+		require.Equal(t, ph.Header.Hash, ch.Header.Hash)
+	})
+
+	t.Run("unknown height", func(t *testing.T) {
+		resp, err := http.Get(addr + "2")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestHTTPServer_OpenAPI(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/openapi.json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Summary    string
+			Parameters []struct {
+				Name   string
+				Schema struct {
+					Pattern string
+				}
+			}
+		}
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	// Every registered route must be documented.
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			require.NotEmptyf(t, op.Summary, "missing summary for %s %s", method, path)
+		}
+	}
+
+	// Path templates are converted to OpenAPI form,
+	// keeping the route's pattern on the parameter.
+	op := doc.Paths["/debug/pending_txs/{hash}"]["get"]
+	require.Len(t, op.Parameters, 1)
+	require.Equal(t, "hash", op.Parameters[0].Name)
+	require.Equal(t, "[0-9a-fA-F]{64}", op.Parameters[0].Schema.Pattern)
+
+	require.Contains(t, doc.Paths, "/headers/{height}")
+}
+
+func TestHTTPServer_NodeInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+
+		NodeInfo: gsi.NodeInfo{
+			Build:          gsi.ReadBuildInfo(),
+			SchemeVersions: gsi.KnownSchemeVersions(),
+			Features:       map[string]bool{"header_only": true},
+		},
+	})
+	defer h.Wait()
+	defer cancel()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/node_info")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var info struct {
+		gsi.NodeInfo
+		Protocols []string
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+
+	require.NotEmpty(t, info.Build.GoVersion)
+	require.Equal(t, uint32(1), info.SchemeVersions[0].Version)
+	require.NotEmpty(t, info.SchemeVersions[0].Signature)
+	require.True(t, info.Features["header_only"])
+
+	// Without a libp2p host, there are no protocols to report.
+	require.Empty(t, info.Protocols)
+}
+
+func TestHTTPServer_Audit(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	al, err := gaudit.OpenLog(gtest.NewLogger(t), gaudit.LogConfig{
+		Path: filepath.Join(t.TempDir(), "audit.jsonl"),
+	})
+	require.NoError(t, err)
+	defer al.Close()
+	for _, peer := range []string{"a", "b", "c"} {
+		al.InvalidSignature(peer, []byte("msg"))
+	}
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+		AuditLog: al,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/audit?limit=2&order=desc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var rs []gaudit.Record
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rs))
+	require.Len(t, rs, 2)
+	require.Equal(t, uint64(3), rs[0].Seq)
+	require.Equal(t, "c", rs[0].Peer)
+	require.Equal(t, gaudit.KindInvalidSignature, rs[1].Kind)
+}
+
+func TestHTTPServer_UpgradeReadiness(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ms := tmmemstore.NewMirrorStore()
+	fs := tmmemstore.NewFinalizationStore()
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	privVals := tmconsensustest.DeterministicValidatorsEd25519(3)
+	valSet, err := tmconsensus.NewValidatorSet(privVals.Vals(), tmconsensustest.SimpleHashScheme{})
+	require.NoError(t, err)
+	require.NoError(t, ms.SetNetworkHeightRound(ctx, 3, 0, 2, 0))
+	require.NoError(t, fs.SaveFinalization(ctx, 2, 0, "block_hash", valSet, "app_state_hash"))
+
+	tr := gready.NewTracker(gready.TrackerConfig{Registry: reg})
+	sig, err := gready.NewSignal(ctx, reg, privVals[0].Signer, 2, []string{"v2"})
+	require.NoError(t, err)
+	require.NoError(t, tr.Record(sig, valSet.Validators))
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+
+		FinalizationStore: fs,
+		MirrorStore:       ms,
+
+		CryptoRegistry: reg,
+
+		Readiness: tr,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/upgrade_readiness")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var tally gready.Tally
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tally))
+	require.Equal(t, uint64(2), tally.CommittingHeight)
+	require.Len(t, tally.Features, 1)
+	require.Equal(t, "v2", tally.Features[0].Feature)
+	require.Equal(t, privVals[0].CVal.Power, tally.Features[0].ReadyPower)
+	require.Equal(t, [][]byte{reg.Marshal(privVals[0].CVal.PubKey)}, tally.Features[0].Validators)
+}
+
+func TestHTTPServer_Validators_pagination(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := "http://" + ln.Addr().String() + "/validators"
+
+	ms := tmmemstore.NewMirrorStore()
+	fs := tmmemstore.NewFinalizationStore()
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+
+		FinalizationStore: fs,
+		MirrorStore:       ms,
+
+		CryptoRegistry: reg,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	require.NoError(t, ms.SetNetworkHeightRound(ctx, 3, 0, 2, 0))
+
+	valSet, err := tmconsensus.NewValidatorSet(
+		tmconsensustest.DeterministicValidatorsEd25519(5).Vals(),
+		tmconsensustest.SimpleHashScheme{},
+	)
+	require.NoError(t, err)
+	require.NoError(t, fs.SaveFinalization(
+		ctx,
+		2, 0,
+		"block_hash",
+		valSet,
+		"app_state_hash",
+	))
+
+	resp, err := http.Get(addr + "?limit=2&offset=1&order=desc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "5", resp.Header.Get("X-Total-Count"))
+
+	var output struct {
+		Validators []struct {
+			PubKey []byte
+			Power  uint64
+		}
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&output))
+
+	// Reversed order, skipping the last validator, then taking two.
+	require.Len(t, output.Validators, 2)
+	for i, want := range []tmconsensus.Validator{valSet.Validators[3], valSet.Validators[2]} {
+		require.Equal(t, reg.Marshal(want.PubKey), output.Validators[i].PubKey)
+	}
+
+	badResp, err := http.Get(addr + "?order=sideways")
+	require.NoError(t, err)
+	defer badResp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, badResp.StatusCode)
+}
+
+func TestHTTPServer_Validators_maxPageSize(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := "http://" + ln.Addr().String()
+
+	ms := tmmemstore.NewMirrorStore()
+	fs := tmmemstore.NewFinalizationStore()
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+
+		FinalizationStore: fs,
+		MirrorStore:       ms,
+
+		CryptoRegistry: reg,
+
+		MaxPageSize: 2,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	require.NoError(t, ms.SetNetworkHeightRound(ctx, 3, 0, 2, 0))
+
+	valSet, err := tmconsensus.NewValidatorSet(
+		tmconsensustest.DeterministicValidatorsEd25519(5).Vals(),
+		tmconsensustest.SimpleHashScheme{},
+	)
+	require.NoError(t, err)
+	require.NoError(t, fs.SaveFinalization(
+		ctx,
+		2, 0,
+		"block_hash",
+		valSet,
+		"app_state_hash",
+	))
+
+	type jsonValidator struct {
+		PubKey []byte
+		Power  uint64
+	}
+
+	t.Run("default and excessive limits are capped", func(t *testing.T) {
+		for _, q := range []string{"", "?limit=100"} {
+			resp, err := http.Get(addr + "/validators" + q)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "5", resp.Header.Get("X-Total-Count"))
+
+			var output struct {
+				Validators []jsonValidator
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&output))
+			require.Len(t, output.Validators, 2)
+		}
+	})
+
+	t.Run("stream is not capped", func(t *testing.T) {
+		resp, err := http.Get(addr + "/validators/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "2", resp.Header.Get("X-Finalization-Height"))
+
+		dec := json.NewDecoder(resp.Body)
+		for _, want := range valSet.Validators {
+			var v jsonValidator
+			require.NoError(t, dec.Decode(&v))
+			require.Equal(t, reg.Marshal(want.PubKey), v.PubKey)
+			require.Equal(t, want.Power, v.Power)
+		}
+		require.False(t, dec.More())
+	})
+}
+
+func TestHTTPServer_CommitSigners(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := "http://" + ln.Addr().String() + "/commit_signers"
+
+	chs := tmmemstore.NewCommittedHeaderStore()
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+
+		CommittedHeaderStore: chs,
+
+		CryptoRegistry: reg,
+	})
+	defer h.Wait()
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	ph := fx.NextProposedHeader([]byte("data"), 0)
+	precommitProofs := fx.PrecommitProofMap(ctx, 1, 0, map[string][]int{
+		string(ph.Header.Hash): {0, 1},
+		"":                     {3},
+	})
+	fx.CommitBlock(ph.Header, []byte("app_state"), 0, precommitProofs)
+	nextPH := fx.NextProposedHeader([]byte("data"), 0)
+	require.NoError(t, chs.SaveCommittedHeader(ctx, tmconsensus.CommittedHeader{
+		Header: ph.Header,
+		Proof:  nextPH.Header.PrevCommitProof,
+	}))
+
+	resp, err := http.Get(addr + "?height=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var output struct {
+		Height     uint64
+		Validators []struct {
+			PubKey []byte
+			Power  uint64
+			Class  string
+		}
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&output))
+
+	require.Equal(t, uint64(1), output.Height)
+	require.Len(t, output.Validators, 4)
+
+	var classes []string
+	for i, v := range output.Validators {
+		require.Equal(t, reg.Marshal(fx.Vals()[i].PubKey), v.PubKey)
+		classes = append(classes, v.Class)
+	}
+	require.Equal(t, []string{"in-commit", "in-commit", "absent", "other"}, classes)
+
+	// Unknown heights are reported as not found.
+	missingResp, err := http.Get(addr + "?height=2")
+	require.NoError(t, err)
+	defer missingResp.Body.Close()
+	require.Equal(t, http.StatusNotFound, missingResp.StatusCode)
+}
+
+func TestHTTPServer_errorEnvelope(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := (new(net.ListenConfig)).Listen(ctx, "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	h := gsi.NewHTTPServer(ctx, gtest.NewLogger(t), gsi.HTTPServerConfig{
+		Listener: ln,
+
+		CommittedHeaderStore: tmmemstore.NewCommittedHeaderStore(),
+	})
+	defer h.Wait()
+	defer cancel()
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		code         uint32
+	}{
+		// Without an app, simulation is not enabled.
+		{method: "POST", path: "/simulate_tx", status: http.StatusServiceUnavailable, code: 5},
+		{method: "GET", path: "/commit_signers?height=x", status: http.StatusBadRequest, code: 3},
+		{method: "GET", path: "/commit_signers?height=5", status: http.StatusNotFound, code: 4},
+	} {
+		req, err := http.NewRequest(tc.method, "http://"+ln.Addr().String()+tc.path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		require.Equal(t, tc.status, resp.StatusCode, tc.path)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		var env struct {
+			Codespace string `json:"codespace"`
+			Code      uint32 `json:"code"`
+			Log       string `json:"log"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+		resp.Body.Close()
+
+		require.Equal(t, gsi.Codespace, env.Codespace, tc.path)
+		require.Equal(t, tc.code, env.Code, tc.path)
+		require.NotEmpty(t, env.Log, tc.path)
+	}
+}
@@ -0,0 +1,114 @@
+package gsi
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gscheme"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwire"
+)
+
+// NodeInfo describes how this node was built and configured,
+// so that operators can audit differences between validators.
+// It is reported by the /node_info endpoint,
+// along with the libp2p protocols the node currently supports.
+type NodeInfo struct {
+	Build BuildInfo
+
+	// The scheme versions known to this binary,
+	// and the upgrades declared in the genesis file.
+	SchemeVersions []SchemeVersion
+	SchemeUpgrades []gscheme.Upgrade
+
+	// The consensus message encoding upgrades declared in the genesis file.
+	WireUpgrades []gwire.Upgrade
+
+	// Optional behaviors by name, and whether each is enabled.
+	Features map[string]bool
+}
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	// The main module's path and version.
+	Module  string
+	Version string
+
+	// The VCS revision the binary was built from, if known,
+	// and whether the working tree had uncommitted changes.
+	Revision string `json:",omitempty"`
+	Modified bool   `json:",omitempty"`
+
+	GoVersion string
+
+	// The version of the gordian module the binary was built with.
+	GordianVersion string `json:",omitempty"`
+
+	// Build tags, such as debug.
+	Tags []string
+}
+
+// SchemeVersion names the signature and hash schemes of a scheme version.
+type SchemeVersion struct {
+	Version uint32
+
+	Signature string
+	Hash      string
+}
+
+// ReadBuildInfo returns the BuildInfo of the running binary.
+func ReadBuildInfo() BuildInfo {
+	// Non-nil so that no tags encode as [] rather than null.
+	out := BuildInfo{Tags: []string{}}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return out
+	}
+
+	out.Module = bi.Main.Path
+	out.Version = bi.Main.Version
+	out.GoVersion = bi.GoVersion
+
+	for _, dep := range bi.Deps {
+		if dep.Path == "github.com/gordian-engine/gordian" {
+			out.GordianVersion = dep.Version
+			if dep.Replace != nil {
+				out.GordianVersion = dep.Replace.Path + "@" + dep.Replace.Version
+			}
+			break
+		}
+	}
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			out.Revision = s.Value
+		case "vcs.modified":
+			out.Modified = s.Value == "true"
+		case "-tags":
+			out.Tags = strings.Split(s.Value, ",")
+		}
+	}
+
+	return out
+}
+
+// KnownSchemeVersions returns the scheme versions in [gscheme.Versions],
+// in increasing order, naming each scheme by its Go type.
+func KnownSchemeVersions() []SchemeVersion {
+	out := make([]SchemeVersion, 0, len(gscheme.Versions))
+	for v, s := range gscheme.Versions {
+		out = append(out, SchemeVersion{
+			Version: v,
+
+			Signature: fmt.Sprintf("%T", s.Signature),
+			Hash:      fmt.Sprintf("%T", s.Hash),
+		})
+	}
+	slices.SortFunc(out, func(a, b SchemeVersion) int {
+		return int(a.Version) - int(b.Version)
+	})
+	return out
+}
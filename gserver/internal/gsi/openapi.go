@@ -0,0 +1,219 @@
+package gsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// routeSummaries describes every route served by the HTTP server,
+// keyed by method and path template as registered on the router.
+//
+// The OpenAPI document is generated by walking the router,
+// so a route missing from this map is still listed,
+// but without a summary; the tests require a summary for every route.
+var routeSummaries = map[string]string{
+	"GET /openapi.json": "This OpenAPI document.",
+
+	"GET /commit_signers":                 "Which validators' precommits are in the commit proof for the height query parameter; paginated with limit, offset and order.",
+	"GET /blocks/watermark":               "Current voting and committing heights and rounds, and the finalized height.",
+	"GET /fork_report":                    "The app hash mismatch that halted the node, if any; 404 when no fork has been detected.",
+	"GET /node_info":                      "Build version, VCS revision, build tags, supported libp2p protocols, scheme versions and enabled features, for auditing differences between nodes.",
+	"GET /fork_evidence":                  "Verified pairs of conflicting committed headers, received from peers or submitted locally.",
+	"GET /upgrade_readiness":              "Voting power at the committing height whose validators have signaled readiness for each upgrade feature, from their heartbeats.",
+	"GET /audit":                          "Protocol violations detected by this node, with the evidence to verify each; paginated with limit, offset and order.",
+	"GET /validators":                     "Consensus validator set at the committing height; paginated with limit, offset and order.",
+	"GET /validators/stream":              "Consensus validator set at the committing height as newline-delimited JSON, without a page size limit.",
+	"GET /validators/diff":                "Validators that joined, left, or changed power between the from and to heights.",
+	"GET /validators/power":               "Voting power distribution of the validator set at the committing height.",
+	"GET /headers/{height:[0-9]+}":        "Committed header at the given height, in the consensus codec's encoding.",
+	"GET /finality_proof/{height:[0-9]+}": "Finality proof for the block at the given height: its committed header and that of the next height, whose previous app state hash is the state after the block; verify offline with gcverify.VerifyFinalityProof.",
+	"GET /round_history":                  "Summaries of the rounds of the most recent heights, newest first, with the rounds taken, time per step, proposer and power at commit, along with the height in progress; paginated with limit, offset and order.",
+	"GET /watch":                          "Stream changes to the balances and delegations of the addresses in the address query parameter, as newline-delimited JSON, for blocks finalized after the request.",
+	"POST /simulate_tx":                   "Estimate the gas and events of a transaction, given as base64 tx_bytes or JSON tx, without submitting it; signers are inferred from the messages if the transaction has no signer infos.",
+	"POST /debug/submit_tx":               "Validate a JSON-encoded signed transaction and add it to the transaction buffer.",
+	"POST /debug/simulate_tx":             "Simulate a JSON-encoded signed transaction without adding it to the buffer.",
+	"GET /debug/pending_txs":              "Transactions in the transaction buffer; paginated with limit, offset and order.",
+	"GET /debug/staking/validators":       "Validators known to the staking module.",
+	"GET /debug/timeouts":                 "Effective consensus timeouts for the current voting round.",
+	"GET /debug/stall":                    "Whether consensus is stalled, with the number of stall reports and the latest report.",
+	"GET /debug/clock_skew":               "Latest measured clock skew and round trip time for each connected peer.",
+	"GET /debug/round_store":              "Whether round store writes are failing and queued for retry, with the number pending and the latest error.",
+	"GET /debug/heartbeats":               "Latest heartbeat from each connected peer, with its height, round and step, and whether it is live.",
+	"GET /debug/backpressure":             "How long each hand-off to the engine has been blocked, with the number currently blocked.",
+	"GET /debug/memory":                   "Size and limits of the block data request cache and the ingress guard and dedupe state.",
+
+	"POST /debug/submit_proposed_header":            "Submit a proposed header as if it had been received from the network.",
+	"POST /debug/submit_fork_evidence":              "Submit a pair of conflicting committed headers; valid evidence is forwarded to every connected peer.",
+	"GET /debug/pending_txs/{hash:[0-9a-fA-F]{64}}": "Whether the transaction with the given hex-encoded hash is in the transaction buffer.",
+	"GET /debug/accounts/{id}/balance":              "Balance of the given account, in the denomination set by the denom query parameter (default stake).",
+}
+
+// setOpenAPIRoute registers a route serving an OpenAPI document
+// generated from every route already registered on r.
+// It must be called after all other routes are registered.
+func setOpenAPIRoute(log *slog.Logger, r *mux.Router) {
+	route := r.NewRoute().Path("/openapi.json").Methods("GET")
+
+	doc, err := buildOpenAPIDoc(r)
+	if err != nil {
+		log.Warn("Failed to build OpenAPI document", "err", err)
+		route.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			httpError(w, "failed to build OpenAPI document: "+err.Error(), http.StatusInternalServerError)
+		})
+		return
+	}
+
+	route.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	})
+}
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	OperationID string                     `json:"operationId"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string `json:"description"`
+}
+
+func buildOpenAPIDoc(r *mux.Router) ([]byte, error) {
+	paths := map[string]map[string]openAPIOperation{}
+
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			// Routes without a path, such as subrouters, have nothing to document.
+			return nil
+		}
+		if !strings.HasPrefix(tmpl, "/") {
+			// The Comet compatibility routes are registered without a leading slash,
+			// so they cannot match any request; leave them out.
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		oaPath, params, err := openAPIPath(tmpl)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range methods {
+			ops := paths[oaPath]
+			if ops == nil {
+				ops = map[string]openAPIOperation{}
+				paths[oaPath] = ops
+			}
+			ops[strings.ToLower(m)] = openAPIOperation{
+				Summary:     routeSummaries[m+" "+tmpl],
+				OperationID: openAPIOperationID(m, oaPath),
+				Parameters:  params,
+				Responses: map[string]openAPIResponse{
+					"200":     {Description: "Success."},
+					"default": {Description: "Failure, as a JSON object with the codespace, code and log of the error."},
+				},
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]string{
+			"title":   "gcosmos HTTP API",
+			"version": "0",
+		},
+		"paths": paths,
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// openAPIPath converts a gorilla/mux path template to an OpenAPI path,
+// returning the path parameters found in the template.
+func openAPIPath(tmpl string) (string, []openAPIParameter, error) {
+	var b strings.Builder
+	var params []openAPIParameter
+
+	for i := 0; i < len(tmpl); i++ {
+		if tmpl[i] != '{' {
+			b.WriteByte(tmpl[i])
+			continue
+		}
+
+		// Patterns may contain braces themselves, such as {64},
+		// so find the matching close brace by depth.
+		depth := 0
+		end := -1
+		for j := i; j < len(tmpl); j++ {
+			switch tmpl[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			if depth == 0 {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			return "", nil, fmt.Errorf("unbalanced braces in path template %q", tmpl)
+		}
+
+		name, pattern, _ := strings.Cut(tmpl[i+1:end], ":")
+		params = append(params, openAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema: openAPISchema{
+				Type:    "string",
+				Pattern: pattern,
+			},
+		})
+		b.WriteString("{" + name + "}")
+		i = end
+	}
+
+	return b.String(), params, nil
+}
+
+// openAPIOperationID derives a stable operation ID from the method and path,
+// e.g. "get_debug_pending_txs_hash".
+func openAPIOperationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, p := range strings.Split(path, "/") {
+		p = strings.Trim(p, "{}")
+		if p == "" {
+			continue
+		}
+		parts = append(parts, strings.NewReplacer(".", "_", "-", "_").Replace(p))
+	}
+	return strings.Join(parts, "_")
+}
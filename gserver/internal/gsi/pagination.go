@@ -0,0 +1,95 @@
+package gsi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// totalCountHeader is set by list endpoints to the number of items
+// before pagination was applied.
+const totalCountHeader = "X-Total-Count"
+
+// pageParams are the pagination and ordering query parameters
+// shared by every list endpoint:
+//
+//   - limit: maximum number of items to return; zero or absent means no limit
+//   - offset: number of items to skip, after ordering
+//   - order: "asc" (the endpoint's natural order, the default) or "desc"
+type pageParams struct {
+	Limit  int
+	Offset int
+	Desc   bool
+}
+
+func parsePageParams(q url.Values) (pageParams, error) {
+	var p pageParams
+
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid limit %q", s)
+		}
+		p.Limit = n
+	}
+
+	if s := q.Get("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid offset %q", s)
+		}
+		p.Offset = n
+	}
+
+	switch s := q.Get("order"); s {
+	case "", "asc":
+		// Default.
+	case "desc":
+		p.Desc = true
+	default:
+		return p, fmt.Errorf("invalid order %q (must be asc or desc)", s)
+	}
+
+	return p, nil
+}
+
+// paginate parses the page parameters from req and applies them to items,
+// setting the total count header on w.
+// The items slice is not modified.
+//
+// If maxLimit is positive, it is the default limit when none is given,
+// and larger limits are reduced to it;
+// clients can compare the number of items returned against the total count header.
+//
+// If the parameters are invalid, paginate writes an error to w and reports false.
+func paginate[T any](w http.ResponseWriter, req *http.Request, items []T, maxLimit int) ([]T, bool) {
+	p, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	if maxLimit > 0 && (p.Limit == 0 || p.Limit > maxLimit) {
+		p.Limit = maxLimit
+	}
+
+	w.Header().Set(totalCountHeader, strconv.Itoa(len(items)))
+
+	if p.Desc {
+		items = slices.Clone(items)
+		slices.Reverse(items)
+	}
+
+	if p.Offset >= len(items) {
+		return items[:0], true
+	}
+	items = items[p.Offset:]
+
+	if p.Limit > 0 && p.Limit < len(items) {
+		items = items[:p.Limit]
+	}
+
+	return items, true
+}
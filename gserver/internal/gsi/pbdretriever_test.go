@@ -92,7 +92,7 @@ func NewPBDFixture(t *testing.T, ctx context.Context) *PBDFixture {
 	return &PBDFixture{
 		Log: log,
 
-		Cache: gsbd.NewRequestCache(),
+		Cache: gsbd.NewRequestCache(gsbd.RequestCacheConfig{}),
 
 		P2PHostConn:   host,
 		P2PClientConn: client,
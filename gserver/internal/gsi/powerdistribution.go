@@ -0,0 +1,60 @@
+package gsi
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// PowerDistribution summarizes how voting power is spread across a validator set,
+// in order to highlight concentration risk.
+type PowerDistribution struct {
+	TotalPower uint64
+
+	// Every validator's power, from largest to smallest.
+	Powers []uint64
+
+	// CumulativeShares[i] is the fraction of the total power
+	// held by the i+1 largest validators.
+	CumulativeShares []float64
+
+	// The fewest validators whose combined power exceeds one third of the total,
+	// which is enough to halt the chain.
+	// Zero for an empty or powerless validator set.
+	NakamotoCoefficient int
+}
+
+// NewPowerDistribution returns the PowerDistribution of vals.
+func NewPowerDistribution(vals []tmconsensus.Validator) PowerDistribution {
+	d := PowerDistribution{
+		Powers:           make([]uint64, len(vals)),
+		CumulativeShares: make([]float64, len(vals)),
+	}
+
+	for i, v := range vals {
+		d.Powers[i] = v.Power
+		d.TotalPower += v.Power
+	}
+	slices.SortFunc(d.Powers, func(a, b uint64) int {
+		return cmp.Compare(b, a)
+	})
+
+	if d.TotalPower == 0 {
+		return d
+	}
+
+	var acc uint64
+	for i, p := range d.Powers {
+		acc += p
+		d.CumulativeShares[i] = float64(acc) / float64(d.TotalPower)
+
+		// Comparing against the truncated third is equivalent to acc*3 > total,
+		// without risking overflow.
+		if d.NakamotoCoefficient == 0 && acc > d.TotalPower/3 {
+			d.NakamotoCoefficient = i + 1
+		}
+	}
+
+	return d
+}
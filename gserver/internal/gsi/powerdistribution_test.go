@@ -0,0 +1,57 @@
+package gsi_test
+
+import (
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPowerDistribution(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concentrated", func(t *testing.T) {
+		t.Parallel()
+
+		d := gsi.NewPowerDistribution([]tmconsensus.Validator{
+			{Power: 10}, {Power: 40}, {Power: 20}, {Power: 30},
+		})
+
+		require.Equal(t, uint64(100), d.TotalPower)
+		require.Equal(t, []uint64{40, 30, 20, 10}, d.Powers)
+		require.InDeltaSlice(t, []float64{0.4, 0.7, 0.9, 1}, d.CumulativeShares, 1e-9)
+
+		// The largest validator alone holds more than a third.
+		require.Equal(t, 1, d.NakamotoCoefficient)
+	})
+
+	t.Run("even", func(t *testing.T) {
+		t.Parallel()
+
+		d := gsi.NewPowerDistribution([]tmconsensus.Validator{
+			{Power: 25}, {Power: 25}, {Power: 25}, {Power: 25},
+		})
+
+		require.Equal(t, 2, d.NakamotoCoefficient)
+	})
+
+	t.Run("exactly one third is not enough", func(t *testing.T) {
+		t.Parallel()
+
+		d := gsi.NewPowerDistribution([]tmconsensus.Validator{
+			{Power: 3}, {Power: 3}, {Power: 3},
+		})
+
+		require.Equal(t, 2, d.NakamotoCoefficient)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		d := gsi.NewPowerDistribution(nil)
+
+		require.Zero(t, d.TotalPower)
+		require.Zero(t, d.NakamotoCoefficient)
+	})
+}
@@ -0,0 +1,55 @@
+package gsi
+
+import (
+	"sync"
+
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+)
+
+// PrecommitTracker records, from the engine's network view updates,
+// the latest round in which every validator has precommitted for the same block.
+//
+// Its FullPrecommits method is suitable for [TimeoutStrategy.FullPrecommits].
+// The zero value is ready to use.
+type PrecommitTracker struct {
+	mu sync.Mutex
+
+	h    uint64
+	r    uint32
+	full bool
+}
+
+// UpdateView records whether the committing or voting view in u
+// has precommits from every validator for a single block.
+// It is intended to be used as a gossip strategy view observer.
+func (t *PrecommitTracker) UpdateView(u tmelink.NetworkViewUpdate) {
+	for _, v := range []*tmconsensus.VersionedRoundView{u.Committing, u.Voting} {
+		if v == nil {
+			continue
+		}
+
+		vs := v.VoteSummary
+		if vs.AvailablePower == 0 || vs.MostVotedPrecommitHash == "" {
+			continue
+		}
+		if vs.PrecommitBlockPower[vs.MostVotedPrecommitHash] != vs.AvailablePower {
+			continue
+		}
+
+		t.mu.Lock()
+		if !t.full || v.Height > t.h || (v.Height == t.h && v.Round > t.r) {
+			t.h, t.r, t.full = v.Height, v.Round, true
+		}
+		t.mu.Unlock()
+	}
+}
+
+// FullPrecommits reports whether every validator has been seen
+// precommitting for the same block at height h and round r.
+func (t *PrecommitTracker) FullPrecommits(h uint64, r uint32) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.full && t.h == h && t.r == r
+}
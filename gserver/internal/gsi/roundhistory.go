@@ -0,0 +1,276 @@
+package gsi
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+)
+
+// DefaultRoundHistoryHeights is the default number of heights kept by a [RoundHistory].
+const DefaultRoundHistoryHeights = 100
+
+// RoundHistory keeps a summary of the rounds of each of the last few heights,
+// so that why a height was slow can be answered without the logs.
+//
+// Its UpdateView method is intended to be used as a gossip strategy view observer,
+// and it is a [StepObserver] for the [ConsensusStrategy],
+// from which it records the time spent in each step.
+type RoundHistory struct {
+	mu sync.Mutex
+
+	// Ring of completed heights; next is the index of the oldest,
+	// once the ring is full.
+	heights []HeightHistory
+	next    int
+
+	// The height currently being voted on, if any.
+	cur *HeightHistory
+
+	// When each step of the current height was entered.
+	stepStart map[StepKind]time.Time
+}
+
+// HeightHistory summarizes the rounds of a single height, as seen by this node.
+type HeightHistory struct {
+	Height uint64
+
+	// When the node started voting on the height,
+	// and how long it took until the height was committed.
+	Start    time.Time
+	Duration JSONDuration `json:",omitempty"`
+
+	// The rounds entered at this height, in order.
+	// More than one round indicates a nil-committed or skipped round.
+	Rounds []RoundSummary
+
+	// Whether the node observed the commit,
+	// as opposed to jumping ahead, such as during catch-up.
+	Committed bool
+
+	// The round in which the block was committed,
+	// the hex-encoded public key of its proposer,
+	// and the precommit power for the block out of the available power.
+	CommitRound    uint32
+	Proposer       string `json:",omitempty"`
+	CommitPower    uint64
+	AvailablePower uint64
+}
+
+// RoundSummary summarizes a single round within a [HeightHistory].
+type RoundSummary struct {
+	Round uint32
+
+	// Time from entering the round until the next round or the commit.
+	// Zero for the round in progress.
+	Duration JSONDuration `json:",omitempty"`
+
+	// Cumulative time spent in each consensus strategy step during the round,
+	// keyed by the step's [StepKind] name.
+	Steps map[string]JSONDuration `json:",omitempty"`
+
+	start time.Time
+	steps map[StepKind]time.Duration
+}
+
+// NewRoundHistory returns a RoundHistory keeping the last n heights.
+// It panics if n is not positive.
+func NewRoundHistory(n int) *RoundHistory {
+	if n <= 0 {
+		panic(fmt.Errorf("BUG: NewRoundHistory: n must be positive (got %d)", n))
+	}
+	return &RoundHistory{
+		heights:   make([]HeightHistory, 0, n),
+		stepStart: make(map[StepKind]time.Time),
+	}
+}
+
+// UpdateView completes the current height when the update commits it,
+// and starts a new height or round when the voting view moves past it.
+func (h *RoundHistory) UpdateView(u tmelink.NetworkViewUpdate) {
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if c := u.Committing; c != nil && h.cur != nil && c.Height == h.cur.Height {
+		h.commit(&c.RoundView, now)
+	}
+
+	v := u.Voting
+	if v == nil {
+		return
+	}
+
+	if h.cur != nil && v.Height > h.cur.Height {
+		// Moved ahead without seeing the commit.
+		h.push(now)
+	}
+	if h.cur == nil {
+		if len(h.heights) > 0 && v.Height <= h.latest().Height {
+			return
+		}
+		h.cur = &HeightHistory{Height: v.Height, Start: now}
+		clear(h.stepStart)
+	}
+	if v.Height == h.cur.Height {
+		h.enterRound(v.Round, now)
+	}
+}
+
+// commit records the commit details from rv, the committing view,
+// and moves the current height into the ring.
+func (h *RoundHistory) commit(rv *tmconsensus.RoundView, now time.Time) {
+	hash := rv.VoteSummary.MostVotedPrecommitHash
+
+	cur := h.cur
+	cur.Committed = true
+	cur.CommitRound = rv.Round
+	cur.CommitPower = rv.VoteSummary.PrecommitBlockPower[hash]
+	cur.AvailablePower = rv.VoteSummary.AvailablePower
+	for _, ph := range rv.ProposedHeaders {
+		if string(ph.Header.Hash) == hash && ph.ProposerPubKey != nil {
+			cur.Proposer = hex.EncodeToString(ph.ProposerPubKey.PubKeyBytes())
+			break
+		}
+	}
+
+	h.push(now)
+}
+
+// push completes the current height at now and adds it to the ring.
+func (h *RoundHistory) push(now time.Time) {
+	cur := h.cur
+	h.cur = nil
+
+	cur.Duration = JSONDuration(now.Sub(cur.Start))
+	if n := len(cur.Rounds); n > 0 {
+		finishRound(&cur.Rounds[n-1], now)
+	}
+
+	if len(h.heights) < cap(h.heights) {
+		h.heights = append(h.heights, *cur)
+		return
+	}
+	h.heights[h.next] = *cur
+	h.next = (h.next + 1) % len(h.heights)
+}
+
+// enterRound starts round r of the current height, if it is newer than the last round.
+func (h *RoundHistory) enterRound(r uint32, now time.Time) {
+	rounds := h.cur.Rounds
+	if n := len(rounds); n > 0 {
+		if r <= rounds[n-1].Round {
+			return
+		}
+		finishRound(&rounds[n-1], now)
+	}
+	h.cur.Rounds = append(rounds, RoundSummary{Round: r, start: now})
+}
+
+func finishRound(rs *RoundSummary, now time.Time) {
+	if rs.Duration == 0 {
+		rs.Duration = JSONDuration(now.Sub(rs.start))
+	}
+	rs.Steps = stepDurations(rs.steps)
+}
+
+// latest returns the most recently completed height.
+// It must only be called with a non-empty ring.
+func (h *RoundHistory) latest() HeightHistory {
+	n := len(h.heights)
+	if n < cap(h.heights) {
+		return h.heights[n-1]
+	}
+	return h.heights[(h.next-1+n)%n]
+}
+
+// EnterStep records when the step in ev began.
+func (h *RoundHistory) EnterStep(_ context.Context, ev StepEvent) {
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cur == nil || ev.Height != h.cur.Height {
+		return
+	}
+	h.stepStart[ev.Kind] = now
+}
+
+// ExitStep adds the time spent in the step in ev to its round.
+func (h *RoundHistory) ExitStep(_ context.Context, ev StepEvent, _ StepResult) {
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start, ok := h.stepStart[ev.Kind]
+	if !ok || h.cur == nil || ev.Height != h.cur.Height {
+		return
+	}
+	delete(h.stepStart, ev.Kind)
+
+	// The state machine may enter a round
+	// before the view update for that round arrives.
+	h.enterRound(ev.Round, start)
+	for i := range h.cur.Rounds {
+		rs := &h.cur.Rounds[i]
+		if rs.Round != ev.Round {
+			continue
+		}
+		if rs.steps == nil {
+			rs.steps = make(map[StepKind]time.Duration)
+		}
+		rs.steps[ev.Kind] += now.Sub(start)
+		return
+	}
+}
+
+// Heights returns the summaries of the retained completed heights, newest first,
+// and of the height in progress, which is nil if there is none.
+func (h *RoundHistory) Heights() (completed []HeightHistory, current *HeightHistory) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := len(h.heights)
+	completed = make([]HeightHistory, 0, n)
+	for i := range n {
+		// Walk backward from the newest entry,
+		// which is only at the end of the slice until the ring wraps.
+		idx := n - 1 - i
+		if n == cap(h.heights) {
+			idx = (h.next - 1 - i + n) % n
+		}
+		completed = append(completed, h.heights[idx])
+	}
+
+	if h.cur != nil {
+		c := *h.cur
+		c.Rounds = make([]RoundSummary, len(h.cur.Rounds))
+		for i, rs := range h.cur.Rounds {
+			if rs.Duration == 0 {
+				// The round in progress only has the steps so far.
+				rs.Steps = stepDurations(rs.steps)
+			}
+			c.Rounds[i] = rs
+		}
+		current = &c
+	}
+	return completed, current
+}
+
+func stepDurations(steps map[StepKind]time.Duration) map[string]JSONDuration {
+	if len(steps) == 0 {
+		return nil
+	}
+	out := make(map[string]JSONDuration, len(steps))
+	for k, d := range steps {
+		out[k.String()] = JSONDuration(d)
+	}
+	return out
+}
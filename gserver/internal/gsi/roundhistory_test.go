@@ -0,0 +1,97 @@
+package gsi_test
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fx := tmconsensustest.NewStandardFixture(4)
+	ph := fx.NextProposedHeader([]byte("data"), 2)
+	ph.ProposerPubKey = fx.ValidatorPubKey(2)
+
+	view := func(h uint64, r uint32) *tmconsensus.VersionedRoundView {
+		return &tmconsensus.VersionedRoundView{
+			RoundView: tmconsensus.RoundView{Height: h, Round: r},
+		}
+	}
+
+	rh := gsi.NewRoundHistory(2)
+
+	completed, cur := rh.Heights()
+	require.Empty(t, completed)
+	require.Nil(t, cur)
+
+	// Height 1 takes two rounds, with a step recorded in the second.
+	rh.UpdateView(tmelink.NetworkViewUpdate{Voting: view(1, 0)})
+	rh.UpdateView(tmelink.NetworkViewUpdate{Voting: view(1, 1)})
+	ev := gsi.StepEvent{Kind: gsi.StepPrecommitDecision, Height: 1, Round: 1}
+	rh.EnterStep(ctx, ev)
+	rh.ExitStep(ctx, ev, gsi.StepResult{})
+
+	_, cur = rh.Heights()
+	require.NotNil(t, cur)
+	require.Equal(t, uint64(1), cur.Height)
+	require.Len(t, cur.Rounds, 2)
+	require.NotEmpty(t, cur.Rounds[0].Duration)
+	require.Empty(t, cur.Rounds[1].Duration)
+	require.Contains(t, cur.Rounds[1].Steps, "PrecommitDecision")
+
+	committing := view(1, 1)
+	committing.ProposedHeaders = []tmconsensus.ProposedHeader{ph}
+	committing.VoteSummary = tmconsensus.VoteSummary{
+		AvailablePower:         40,
+		PrecommitBlockPower:    map[string]uint64{string(ph.Header.Hash): 30},
+		MostVotedPrecommitHash: string(ph.Header.Hash),
+	}
+	rh.UpdateView(tmelink.NetworkViewUpdate{Committing: committing, Voting: view(2, 0)})
+
+	completed, cur = rh.Heights()
+	require.Len(t, completed, 1)
+	h1 := completed[0]
+	require.Equal(t, uint64(1), h1.Height)
+	require.True(t, h1.Committed)
+	require.Equal(t, uint32(1), h1.CommitRound)
+	require.Equal(t, uint64(30), h1.CommitPower)
+	require.Equal(t, uint64(40), h1.AvailablePower)
+	require.Equal(t, hex.EncodeToString(fx.ValidatorPubKey(2).PubKeyBytes()), h1.Proposer)
+	require.NotEmpty(t, h1.Duration)
+	require.NotEmpty(t, h1.Rounds[1].Duration)
+	require.Equal(t, uint64(2), cur.Height)
+
+	// Height 2 is skipped over without seeing its commit,
+	// and height 3 commits; the ring only keeps the last two heights.
+	rh.UpdateView(tmelink.NetworkViewUpdate{Voting: view(3, 0)})
+	rh.UpdateView(tmelink.NetworkViewUpdate{Committing: view(3, 0), Voting: view(4, 0)})
+
+	completed, cur = rh.Heights()
+	require.Len(t, completed, 2)
+	require.Equal(t, uint64(3), completed[0].Height)
+	require.True(t, completed[0].Committed)
+	require.Equal(t, uint64(2), completed[1].Height)
+	require.False(t, completed[1].Committed)
+	require.Equal(t, uint64(4), cur.Height)
+
+	// A stale update for a completed height is ignored.
+	rh.UpdateView(tmelink.NetworkViewUpdate{Committing: view(3, 0), Voting: view(3, 0)})
+	_, cur = rh.Heights()
+	require.Equal(t, uint64(4), cur.Height)
+}
+
+func TestNewRoundHistory_nonPositive(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() { gsi.NewRoundHistory(0) })
+	require.Panics(t, func() { gsi.NewRoundHistory(-1) })
+}
@@ -0,0 +1,106 @@
+package gsi
+
+import (
+	"context"
+)
+
+// StepKind identifies which state machine step
+// caused a [ConsensusStrategy] method call.
+type StepKind uint8
+
+const (
+	// The state machine entered a new round,
+	// through [*ConsensusStrategy.EnterRound].
+	StepNewRound StepKind = iota + 1
+
+	// One or more proposed headers were received and are being considered,
+	// through [*ConsensusStrategy.ConsiderProposedBlocks].
+	StepProposalReceived
+
+	// The proposal timeout elapsed and the strategy must choose a block to prevote,
+	// through [*ConsensusStrategy.ChooseProposedBlock].
+	StepProposalTimeout
+
+	// Either the prevote delay elapsed, or sufficient prevotes arrived,
+	// and the strategy must decide its precommit,
+	// through [*ConsensusStrategy.DecidePrecommit].
+	StepPrecommitDecision
+)
+
+func (k StepKind) String() string {
+	switch k {
+	case StepNewRound:
+		return "NewRound"
+	case StepProposalReceived:
+		return "ProposalReceived"
+	case StepProposalTimeout:
+		return "ProposalTimeout"
+	case StepPrecommitDecision:
+		return "PrecommitDecision"
+	default:
+		return "Unknown"
+	}
+}
+
+// StepEvent describes a single step as observed by the [ConsensusStrategy].
+type StepEvent struct {
+	Kind StepKind
+
+	Height uint64
+	Round  uint32
+
+	// The number of proposed headers available to the strategy at this step.
+	// Always zero for StepPrecommitDecision.
+	NProposedHeaders int
+}
+
+// StepResult is the outcome of a step, passed to [StepObserver.ExitStep].
+type StepResult struct {
+	// The block hash chosen during the step, if any.
+	// Empty when the step does not choose a block,
+	// or when the strategy chose nil.
+	Choice string
+
+	// The error returned from the strategy method, if any.
+	Err error
+}
+
+// StepObserver receives callbacks when the [ConsensusStrategy]
+// enters and exits each step of the state machine.
+// A strategy may have any number of observers,
+// set through [ConsensusStrategyConfig.StepObservers].
+//
+// The callbacks are called synchronously on the state machine's goroutine,
+// so implementations must return quickly.
+// An observer is the intended place to accumulate information,
+// such as which validators have been absent in recent rounds,
+// that a strategy needs to make better decisions.
+type StepObserver interface {
+	EnterStep(context.Context, StepEvent)
+	ExitStep(context.Context, StepEvent, StepResult)
+}
+
+// observeStep calls EnterStep on each of c's observers,
+// and returns the function to call with the step's result,
+// which calls ExitStep on each observer in the same order.
+// The returned function is never nil.
+func (c *ConsensusStrategy) observeStep(
+	ctx context.Context, ev StepEvent,
+) func(choice string, err error) {
+	if len(c.stepObservers) == 0 {
+		return func(string, error) {}
+	}
+
+	for _, o := range c.stepObservers {
+		o.EnterStep(ctx, ev)
+	}
+	return func(choice string, err error) {
+		res := StepResult{
+			Choice: choice,
+			Err:    err,
+		}
+		for _, o := range c.stepObservers {
+			o.ExitStep(ctx, ev, res)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package gsi
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
+	"github.com/gordian-engine/gordian/tm/tmengine"
+)
+
+// TimeoutEscalation describes how a [TimeoutStrategy]
+// grows its timeouts as the round number increases.
+type TimeoutEscalation string
+
+const (
+	// TimeoutEscalationLinear adds the increment once per round,
+	// matching the behavior of [tmengine.LinearTimeoutStrategy].
+	TimeoutEscalationLinear TimeoutEscalation = "linear"
+
+	// TimeoutEscalationExponential multiplies the base timeout
+	// by the configured factor once per round.
+	TimeoutEscalationExponential TimeoutEscalation = "exponential"
+)
+
+// ParseTimeoutEscalation parses s into a TimeoutEscalation,
+// returning an error if s is not a recognized value.
+// The empty string is treated as [TimeoutEscalationLinear].
+func ParseTimeoutEscalation(s string) (TimeoutEscalation, error) {
+	switch e := TimeoutEscalation(s); e {
+	case "":
+		return TimeoutEscalationLinear, nil
+	case TimeoutEscalationLinear, TimeoutEscalationExponential:
+		return e, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown timeout escalation %q (must be %q or %q)",
+			s, TimeoutEscalationLinear, TimeoutEscalationExponential,
+		)
+	}
+}
+
+// defaultExponentialFactor is used when
+// a TimeoutStrategy's Factor is not greater than 1.
+const defaultExponentialFactor = 1.5
+
+// TimeoutStrategy is a [tmengine.TimeoutStrategy]
+// with configurable escalation and an optional upper bound.
+//
+// The base timeouts and linear increments come from the embedded Linear strategy,
+// so zero values there use the same defaults as the engine.
+type TimeoutStrategy struct {
+	Linear tmengine.LinearTimeoutStrategy
+
+	Escalation TimeoutEscalation
+
+	// Per-round multiplier when Escalation is exponential.
+	// Values not greater than 1 use a default of 1.5.
+	Factor float64
+
+	// If positive, no timeout will exceed Cap.
+	Cap time.Duration
+
+	// Added to the round 0 proposal timeout, after applying Cap,
+	// so that validators wait for a proposer
+	// that is waiting for transactions under an [EmptyBlockPolicy].
+	EmptyBlockWait time.Duration
+
+	// If set, CommitWaitTimeout returns zero for a height and round
+	// in which FullPrecommits reports precommits from every validator,
+	// since waiting for more precommits cannot add anything.
+	// See [PrecommitTracker].
+	FullPrecommits func(h uint64, r uint32) bool
+
+	// If set, nonzero timeouts in the parameters in effect at a height
+	// replace the corresponding base timeouts in Linear at that height.
+	Params *gparams.Schedule
+}
+
+var _ tmengine.TimeoutStrategy = TimeoutStrategy{}
+
+func (s TimeoutStrategy) ProposalTimeout(h uint64, r uint32) time.Duration {
+	d := s.escalate(s.linear(h).ProposalTimeout, h, r)
+	if r == 0 {
+		d += s.EmptyBlockWait
+	}
+	return d
+}
+
+func (s TimeoutStrategy) PrevoteDelayTimeout(h uint64, r uint32) time.Duration {
+	return s.escalate(s.linear(h).PrevoteDelayTimeout, h, r)
+}
+
+func (s TimeoutStrategy) PrecommitDelayTimeout(h uint64, r uint32) time.Duration {
+	return s.escalate(s.linear(h).PrecommitDelayTimeout, h, r)
+}
+
+func (s TimeoutStrategy) CommitWaitTimeout(h uint64, r uint32) time.Duration {
+	if s.FullPrecommits != nil && s.FullPrecommits(h, r) {
+		return 0
+	}
+	return s.escalate(s.linear(h).CommitWaitTimeout, h, r)
+}
+
+// linear returns s.Linear with any base timeouts
+// from the parameters in effect at height h.
+func (s TimeoutStrategy) linear(h uint64) tmengine.LinearTimeoutStrategy {
+	l := s.Linear
+	if s.Params == nil {
+		return l
+	}
+
+	p := s.Params.At(h)
+	if p.ProposalTimeout > 0 {
+		l.ProposalBase = p.ProposalTimeout
+	}
+	if p.PrevoteDelayTimeout > 0 {
+		l.PrevoteDelayBase = p.PrevoteDelayTimeout
+	}
+	if p.PrecommitDelayTimeout > 0 {
+		l.PrecommitDelayBase = p.PrecommitDelayTimeout
+	}
+	if p.CommitWaitTimeout > 0 {
+		l.CommitWaitBase = p.CommitWaitTimeout
+	}
+	return l
+}
+
+func (s TimeoutStrategy) escalate(
+	linearFn func(uint64, uint32) time.Duration,
+	h uint64, r uint32,
+) time.Duration {
+	var d time.Duration
+	if s.Escalation == TimeoutEscalationExponential {
+		f := s.Factor
+		if f <= 1 {
+			f = defaultExponentialFactor
+		}
+
+		// The round 0 value from the linear strategy is just its base.
+		fd := float64(linearFn(h, 0)) * math.Pow(f, float64(r))
+		if fd >= math.MaxInt64 {
+			// Avoid overflow in very high rounds.
+			d = math.MaxInt64
+		} else {
+			d = time.Duration(fd)
+		}
+	} else {
+		d = linearFn(h, r)
+	}
+
+	if s.Cap > 0 && d > s.Cap {
+		d = s.Cap
+	}
+	return d
+}
@@ -0,0 +1,158 @@
+package gsi_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gparams"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutStrategy_Linear(t *testing.T) {
+	t.Parallel()
+
+	lin := tmengine.LinearTimeoutStrategy{
+		ProposalBase:      time.Second,
+		ProposalIncrement: 250 * time.Millisecond,
+	}
+	s := gsi.TimeoutStrategy{Linear: lin}
+
+	for r := uint32(0); r < 5; r++ {
+		require.Equal(t, lin.ProposalTimeout(1, r), s.ProposalTimeout(1, r))
+		require.Equal(t, lin.CommitWaitTimeout(1, r), s.CommitWaitTimeout(1, r))
+	}
+
+	s.Cap = 1500 * time.Millisecond
+	require.Equal(t, 1250*time.Millisecond, s.ProposalTimeout(1, 1))
+	require.Equal(t, 1500*time.Millisecond, s.ProposalTimeout(1, 2))
+	require.Equal(t, 1500*time.Millisecond, s.ProposalTimeout(1, 10))
+}
+
+func TestTimeoutStrategy_Exponential(t *testing.T) {
+	t.Parallel()
+
+	s := gsi.TimeoutStrategy{
+		Linear: tmengine.LinearTimeoutStrategy{
+			ProposalBase: time.Second,
+		},
+		Escalation: gsi.TimeoutEscalationExponential,
+		Factor:     2,
+	}
+
+	require.Equal(t, time.Second, s.ProposalTimeout(1, 0))
+	require.Equal(t, 2*time.Second, s.ProposalTimeout(1, 1))
+	require.Equal(t, 8*time.Second, s.ProposalTimeout(1, 3))
+
+	// Very high rounds must not overflow into negative durations.
+	require.Positive(t, s.ProposalTimeout(1, 1000))
+
+	s.Cap = 5 * time.Second
+	require.Equal(t, 4*time.Second, s.ProposalTimeout(1, 2))
+	require.Equal(t, 5*time.Second, s.ProposalTimeout(1, 3))
+	require.Equal(t, 5*time.Second, s.ProposalTimeout(1, 1000))
+}
+
+func TestTimeoutStrategy_EmptyBlockWait(t *testing.T) {
+	t.Parallel()
+
+	s := gsi.TimeoutStrategy{
+		Linear: tmengine.LinearTimeoutStrategy{
+			ProposalBase:      time.Second,
+			ProposalIncrement: time.Second,
+		},
+		Cap:            1500 * time.Millisecond,
+		EmptyBlockWait: 10 * time.Second,
+	}
+
+	// Only the first round waits for the proposer.
+	require.Equal(t, 11*time.Second, s.ProposalTimeout(1, 0))
+	require.Equal(t, 1500*time.Millisecond, s.ProposalTimeout(1, 1))
+}
+
+func TestTimeoutStrategy_FullPrecommits(t *testing.T) {
+	t.Parallel()
+
+	var pt gsi.PrecommitTracker
+	s := gsi.TimeoutStrategy{
+		Linear: tmengine.LinearTimeoutStrategy{
+			CommitWaitBase: time.Second,
+		},
+		FullPrecommits: pt.FullPrecommits,
+	}
+	require.Equal(t, time.Second, s.CommitWaitTimeout(1, 0))
+
+	vrv := &tmconsensus.VersionedRoundView{
+		RoundView: tmconsensus.RoundView{
+			Height: 1,
+			Round:  0,
+			VoteSummary: tmconsensus.VoteSummary{
+				AvailablePower:         10,
+				TotalPrecommitPower:    9,
+				PrecommitBlockPower:    map[string]uint64{"a": 9},
+				MostVotedPrecommitHash: "a",
+			},
+		},
+	}
+
+	// Most, but not all, of the power is not enough.
+	pt.UpdateView(tmelink.NetworkViewUpdate{Voting: vrv})
+	require.Equal(t, time.Second, s.CommitWaitTimeout(1, 0))
+
+	vrv.VoteSummary.TotalPrecommitPower = 10
+	vrv.VoteSummary.PrecommitBlockPower["a"] = 10
+	pt.UpdateView(tmelink.NetworkViewUpdate{Committing: vrv})
+	require.Zero(t, s.CommitWaitTimeout(1, 0))
+
+	// Other rounds are unaffected.
+	require.Equal(t, time.Second+500*time.Millisecond, s.CommitWaitTimeout(1, 1))
+}
+
+func TestTimeoutStrategy_Params(t *testing.T) {
+	t.Parallel()
+
+	params, err := gparams.NewSchedule(gtest.NewLogger(t), filepath.Join(t.TempDir(), "consensus_params.json"))
+	require.NoError(t, err)
+	require.NoError(t, params.Request(1, []gparams.Attribute{
+		{Key: "height", Value: "10"},
+		{Key: "proposal_timeout", Value: "2s"},
+	}))
+
+	s := gsi.TimeoutStrategy{
+		Linear: tmengine.LinearTimeoutStrategy{
+			ProposalBase:      time.Second,
+			ProposalIncrement: 250 * time.Millisecond,
+			CommitWaitBase:    time.Second,
+		},
+		Params: params,
+	}
+
+	require.Equal(t, time.Second, s.ProposalTimeout(9, 0))
+	require.Equal(t, 2*time.Second, s.ProposalTimeout(10, 0))
+
+	// Escalation still applies to the scheduled base.
+	require.Equal(t, 2250*time.Millisecond, s.ProposalTimeout(10, 1))
+
+	// Timeouts absent from the parameters are unchanged.
+	require.Equal(t, time.Second, s.CommitWaitTimeout(10, 0))
+}
+
+func TestParseTimeoutEscalation(t *testing.T) {
+	t.Parallel()
+
+	e, err := gsi.ParseTimeoutEscalation("")
+	require.NoError(t, err)
+	require.Equal(t, gsi.TimeoutEscalationLinear, e)
+
+	e, err = gsi.ParseTimeoutEscalation("exponential")
+	require.NoError(t, err)
+	require.Equal(t, gsi.TimeoutEscalationExponential, e)
+
+	_, err = gsi.ParseTimeoutEscalation("quadratic")
+	require.Error(t, err)
+}
@@ -0,0 +1,349 @@
+package gsi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"cosmossdk.io/core/transaction"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/gordian-engine/gcosmos/gcerr"
+	"github.com/gordian-engine/gcosmos/internal/copy/gchan"
+	"github.com/gordian-engine/gcosmos/internal/copy/glog"
+)
+
+// SequencePolicy controls how a [TxPool] handles a new transaction
+// that reuses a signer sequence already pending in the buffer.
+type SequencePolicy uint8
+
+const (
+	// SequencePolicyReject refuses the new transaction.
+	SequencePolicyReject SequencePolicy = iota
+
+	// SequencePolicyReplaceByFee replaces the pending transaction
+	// if the new transaction pays a strictly higher fee in every denomination,
+	// and otherwise refuses the new transaction.
+	SequencePolicyReplaceByFee
+)
+
+// ParseSequencePolicy parses the string form of a SequencePolicy,
+// either "reject" or "replace-by-fee".
+// The empty string is treated as reject.
+func ParseSequencePolicy(s string) (SequencePolicy, error) {
+	switch s {
+	case "", "reject":
+		return SequencePolicyReject, nil
+	case "replace-by-fee":
+		return SequencePolicyReplaceByFee, nil
+	default:
+		return 0, fmt.Errorf("unknown sequence policy %q (must be reject or replace-by-fee)", s)
+	}
+}
+
+// SequenceConflictError is returned from [*TxPool.AddTx]
+// when the transaction reuses a signer sequence of a pending transaction,
+// and the pool's policy did not allow a replacement.
+type SequenceConflictError struct {
+	Signer   []byte
+	Sequence uint64
+
+	// Hash of the pending transaction that holds the sequence.
+	PendingHash [32]byte
+}
+
+func (e SequenceConflictError) Error() string {
+	return fmt.Sprintf(
+		"signer %X already has a pending transaction (%X) with sequence %d",
+		e.Signer, e.PendingHash, e.Sequence,
+	)
+}
+
+func (SequenceConflictError) ErrorCode() gcerr.Code { return gcerr.CodeAlreadyExists }
+
+// TxPool is the entry point for adding client transactions to the transaction buffer.
+//
+// The pool indexes the signer sequences of the transactions it has added,
+// and it checks each new transaction against that index.
+// Additions are serialized, so that two transactions added through the pool
+// with the same (signer, sequence) pair are never pending at once.
+//
+// Replacing a pending transaction requires rebasing the buffer,
+// which only the [Driver] may do safely,
+// so replacements are sent to the driver configured with this pool.
+// The driver also reports the transactions that leave the buffer,
+// so that their sequences are removed from the index.
+type TxPool struct {
+	log *slog.Logger
+
+	buf    *SDKTxBuf
+	policy SequencePolicy
+
+	// Held for the whole of AddTx,
+	// so that a conflict check and the addition it allows
+	// cannot interleave with another submission.
+	addMu sync.Mutex
+
+	// Guards the index of pending transactions.
+	// Separate from addMu, because the driver updates the index
+	// while AddTx may be waiting on the driver for a replacement.
+	mu       sync.Mutex
+	bySeq    map[signerSequence]pendingTx
+	seqsByTx map[[32]byte][]signerSequence
+
+	replaceRequests chan txReplaceRequest
+}
+
+// pendingTx is a transaction in the [TxPool] index, with its hash.
+type pendingTx struct {
+	Tx   transaction.Tx
+	Hash [32]byte
+}
+
+type txReplaceRequest struct {
+	Old, New transaction.Tx
+
+	Resp chan error
+}
+
+// signerSequence identifies a single signature on a transaction.
+type signerSequence struct {
+	Signer   string
+	Sequence uint64
+}
+
+// NewTxPool returns a new TxPool that adds transactions to buf.
+// The pool must be set as the TxPool field of a [DriverConfig],
+// in order to process replacements
+// and to remove committed transactions from its index.
+func NewTxPool(log *slog.Logger, buf *SDKTxBuf, policy SequencePolicy) *TxPool {
+	return &TxPool{
+		log: log,
+
+		buf:    buf,
+		policy: policy,
+
+		bySeq:    make(map[signerSequence]pendingTx),
+		seqsByTx: make(map[[32]byte][]signerSequence),
+
+		replaceRequests: make(chan txReplaceRequest),
+	}
+}
+
+// AddTx adds tx to the buffer,
+// unless it conflicts with a pending transaction's signer sequence.
+// Depending on the pool's policy,
+// a conflicting transaction is either refused with a [SequenceConflictError]
+// or replaces the pending transaction.
+func (p *TxPool) AddTx(ctx context.Context, tx transaction.Tx) error {
+	seqs, ok := txSignerSequences(tx)
+	if !ok {
+		// Nothing to check; let the buffer decide.
+		return p.buf.AddTx(ctx, tx)
+	}
+
+	p.addMu.Lock()
+	defer p.addMu.Unlock()
+
+	hash := tx.Hash()
+	if pending, ss, ok := p.conflict(hash, seqs); ok {
+		if p.policy != SequencePolicyReplaceByFee || !paysHigherFee(tx, pending.Tx) {
+			return SequenceConflictError{
+				Signer:      []byte(ss.Signer),
+				Sequence:    ss.Sequence,
+				PendingHash: pending.Hash,
+			}
+		}
+
+		// The driver updates the index when the replacement succeeds.
+		return p.replace(ctx, pending.Tx, tx)
+	}
+
+	// Track the transaction before adding it,
+	// so that the driver cannot report it committed before it is indexed.
+	if !p.track(tx, seqs) {
+		// Already pending; let the buffer refuse the duplicate.
+		return p.buf.AddTx(ctx, tx)
+	}
+	if err := p.buf.AddTx(ctx, tx); err != nil {
+		p.forget([]transaction.Tx{tx})
+		return err
+	}
+	return nil
+}
+
+// conflict returns the first pending transaction, other than the one with hash,
+// holding any of seqs, along with the conflicting sequence.
+// Resubmitting the same transaction is not a sequence conflict;
+// the buffer refuses it on its own.
+func (p *TxPool) conflict(
+	hash [32]byte, seqs []signerSequence,
+) (pendingTx, signerSequence, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ss := range seqs {
+		if pending, ok := p.bySeq[ss]; ok && pending.Hash != hash {
+			return pending, ss, true
+		}
+	}
+	return pendingTx{}, signerSequence{}, false
+}
+
+// track adds tx, with its signer sequences seqs, to the index.
+// It reports false if tx was already indexed.
+func (p *TxPool) track(tx transaction.Tx, seqs []signerSequence) bool {
+	hash := tx.Hash()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.seqsByTx[hash]; ok {
+		return false
+	}
+	for _, ss := range seqs {
+		p.bySeq[ss] = pendingTx{Tx: tx, Hash: hash}
+	}
+	p.seqsByTx[hash] = seqs
+	return true
+}
+
+// forget removes txs, which have left the buffer, from the index.
+// Transactions that were not added through the pool are ignored.
+func (p *TxPool) forget(txs []transaction.Tx) {
+	if len(txs) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, tx := range txs {
+		hash := tx.Hash()
+		for _, ss := range p.seqsByTx[hash] {
+			if p.bySeq[ss].Hash == hash {
+				delete(p.bySeq, ss)
+			}
+		}
+		delete(p.seqsByTx, hash)
+	}
+}
+
+func (p *TxPool) replace(ctx context.Context, old, new transaction.Tx) error {
+	req := txReplaceRequest{
+		Old: old,
+		New: new,
+
+		Resp: make(chan error, 1),
+	}
+	err, ok := gchan.ReqResp(
+		ctx, p.log,
+		p.replaceRequests, req,
+		req.Resp,
+		"requesting transaction replacement",
+	)
+	if !ok {
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+// txSignerSequences returns the (signer, sequence) pairs
+// for every signature on tx, in signature order.
+// It reports false if tx does not expose its signatures.
+func txSignerSequences(tx transaction.Tx) ([]signerSequence, bool) {
+	svTx, ok := tx.(authsigning.SigVerifiableTx)
+	if !ok {
+		return nil, false
+	}
+
+	sigs, err := svTx.GetSignaturesV2()
+	if err != nil {
+		return nil, false
+	}
+
+	out := make([]signerSequence, 0, len(sigs))
+	for _, sig := range sigs {
+		if sig.PubKey == nil {
+			continue
+		}
+		out = append(out, signerSequence{
+			Signer:   string(sig.PubKey.Address()),
+			Sequence: sig.Sequence,
+		})
+	}
+	return out, true
+}
+
+// paysHigherFee reports whether tx pays a strictly higher fee than pending,
+// in every denomination that pending pays.
+func paysHigherFee(tx, pending transaction.Tx) bool {
+	txFee, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return false
+	}
+	pendingFee, ok := pending.(sdk.FeeTx)
+	if !ok {
+		return false
+	}
+
+	return txFee.GetFee().IsAllGT(pendingFee.GetFee())
+}
+
+// handleTxReplacement removes req.Old from the buffer and adds req.New in its place.
+// If req.New cannot be added, req.Old is restored.
+// It must only be called from the driver's main loop,
+// because it rebases the buffer on d's current buffer state.
+func (d *Driver) handleTxReplacement(ctx context.Context, req txReplaceRequest) bool {
+	// Rebasing on the same state drops the old transaction,
+	// along with any later transactions that depended on it.
+	invalidated, err := d.txBuf.Rebase(ctx, d.bufState, []transaction.Tx{req.Old})
+	if err != nil {
+		d.log.Warn("Failed to rebase transaction buffer for replacement", "err", err)
+		return false
+	}
+
+	addErr := d.txBuf.AddTx(ctx, req.New)
+	if addErr != nil {
+		// Put the original transaction back, so a failed replacement is not a removal.
+		invalidated = append([]transaction.Tx{req.Old}, invalidated...)
+	} else {
+		d.txPool.forget([]transaction.Tx{req.Old})
+		if seqs, ok := txSignerSequences(req.New); ok {
+			d.txPool.track(req.New, seqs)
+		}
+		d.log.Debug(
+			"Replaced pending transaction",
+			"old_hash", glog.Hex(hashBytes(req.Old)),
+			"new_hash", glog.Hex(hashBytes(req.New)),
+		)
+	}
+
+	// Anything invalidated by removing the old transaction
+	// is likely valid again on top of its replacement.
+	var dropped []transaction.Tx
+	for _, tx := range invalidated {
+		if err := d.txBuf.AddTx(ctx, tx); err != nil {
+			dropped = append(dropped, tx)
+			if ctx.Err() == nil {
+				d.log.Debug(
+					"Dropped pending transaction after replacement",
+					"hash", glog.Hex(hashBytes(tx)),
+					"err", err,
+				)
+			}
+		}
+	}
+	d.txPool.forget(dropped)
+
+	// Buffered, so this does not block.
+	req.Resp <- addErr
+
+	return ctx.Err() == nil
+}
+
+func hashBytes(tx transaction.Tx) []byte {
+	h := tx.Hash()
+	return h[:]
+}
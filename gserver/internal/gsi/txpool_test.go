@@ -0,0 +1,230 @@
+package gsi
+
+// Unlike the rest of the package's tests, these are internal tests,
+// because replacements are only processed by the driver's main loop,
+// and a full driver is far more than the pool needs.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/core/transaction"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gdriver/gtxbuf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxPool_reject(t *testing.T) {
+	t.Parallel()
+
+	fx := newTxPoolFixture(t, SequencePolicyReject)
+	signer := ed25519.GenPrivKey().PubKey()
+
+	pending := fx.NewTx(signer, 0, 10)
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, pending))
+
+	// The same sequence is refused, regardless of the fee.
+	higher := fx.NewTx(signer, 0, 20)
+	err := fx.Pool.AddTx(fx.Ctx, higher)
+	var conflict SequenceConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, []byte(signer.Address()), conflict.Signer)
+	require.Zero(t, conflict.Sequence)
+	require.Equal(t, pending.Hash(), conflict.PendingHash)
+
+	// The next sequence, or another signer, is not a conflict.
+	next := fx.NewTx(signer, 1, 10)
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, next))
+	other := fx.NewTx(ed25519.GenPrivKey().PubKey(), 0, 10)
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, other))
+
+	require.Equal(t, []transaction.Tx{pending, next, other}, fx.Buf.Buffered(fx.Ctx, nil))
+
+	// Once the pending transaction leaves the buffer, its sequence is free.
+	fx.Pool.forget([]transaction.Tx{pending})
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, higher))
+}
+
+func TestTxPool_replaceByFee(t *testing.T) {
+	t.Parallel()
+
+	fx := newTxPoolFixture(t, SequencePolicyReplaceByFee)
+	signer := ed25519.GenPrivKey().PubKey()
+
+	pending := fx.NewTx(signer, 0, 10)
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, pending))
+
+	// A fee that is not strictly higher is still refused.
+	same := fx.NewTx(signer, 0, 10)
+	err := fx.Pool.AddTx(fx.Ctx, same)
+	var conflict SequenceConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, pending.Hash(), conflict.PendingHash)
+
+	higher := fx.NewTx(signer, 0, 20)
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, higher))
+	require.Equal(t, []transaction.Tx{higher}, fx.Buf.Buffered(fx.Ctx, nil))
+
+	// The replacement is now the pending transaction for the sequence.
+	err = fx.Pool.AddTx(fx.Ctx, fx.NewTx(signer, 0, 15))
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, higher.Hash(), conflict.PendingHash)
+}
+
+func TestTxPool_replaceByFee_restoresOnFailure(t *testing.T) {
+	t.Parallel()
+
+	fx := newTxPoolFixture(t, SequencePolicyReplaceByFee)
+	signer := ed25519.GenPrivKey().PubKey()
+
+	pending := fx.NewTx(signer, 0, 10)
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, pending))
+	next := fx.NewTx(signer, 1, 10)
+	require.NoError(t, fx.Pool.AddTx(fx.Ctx, next))
+
+	// The replacement pays more but fails to apply.
+	invalid := fx.NewTx(signer, 0, 30)
+	fx.Invalid[invalid.Hash()] = true
+	err := fx.Pool.AddTx(fx.Ctx, invalid)
+	require.ErrorAs(t, err, new(gtxbuf.TxInvalidError))
+
+	// The original transaction was restored,
+	// and the one after it was added back.
+	require.ElementsMatch(t, []transaction.Tx{pending, next}, fx.Buf.Buffered(fx.Ctx, nil))
+
+	// The original transaction still holds its sequence.
+	var conflict SequenceConflictError
+	err = fx.Pool.AddTx(fx.Ctx, fx.NewTx(signer, 0, 5))
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, pending.Hash(), conflict.PendingHash)
+}
+
+type txPoolFixture struct {
+	Ctx context.Context
+
+	Buf  *SDKTxBuf
+	Pool *TxPool
+
+	// Hashes of transactions that fail to apply to the buffer.
+	// Only modified before calling into the pool.
+	Invalid map[[32]byte]bool
+
+	n uint64
+}
+
+// newTxPoolFixture returns a fixture whose pool adds to a buffer
+// that accepts every transaction not marked invalid,
+// with a driver serving the pool's replacements.
+func newTxPoolFixture(t *testing.T, policy SequencePolicy) *txPoolFixture {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := gtest.NewLogger(t)
+
+	fx := &txPoolFixture{
+		Ctx:     ctx,
+		Invalid: make(map[[32]byte]bool),
+	}
+
+	fx.Buf = gtxbuf.New(
+		ctx, log.With("sys", "txbuf"),
+		func(_ context.Context, s corestore.ReaderMap, tx transaction.Tx) (corestore.ReaderMap, error) {
+			if fx.Invalid[tx.Hash()] {
+				return nil, gtxbuf.TxInvalidError{Err: context.Canceled}
+			}
+			return s, nil
+		},
+		func(_ context.Context, reject []transaction.Tx) func(transaction.Tx) bool {
+			hashes := make(map[[32]byte]bool, len(reject))
+			for _, tx := range reject {
+				hashes[tx.Hash()] = true
+			}
+			return func(tx transaction.Tx) bool { return hashes[tx.Hash()] }
+		},
+	)
+	t.Cleanup(fx.Buf.Wait)
+	t.Cleanup(cancel)
+	require.True(t, fx.Buf.Initialize(ctx, nil))
+
+	fx.Pool = NewTxPool(log.With("sys", "txpool"), fx.Buf, policy)
+
+	d := &Driver{
+		log:    log.With("sys", "driver"),
+		txBuf:  fx.Buf,
+		txPool: fx.Pool,
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case req := <-fx.Pool.replaceRequests:
+				if !d.handleTxReplacement(ctx, req) {
+					return
+				}
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return fx
+}
+
+// NewTx returns a transaction signed by signer at sequence seq,
+// paying fee in a single denomination.
+// Every returned transaction has a distinct hash.
+func (fx *txPoolFixture) NewTx(signer cryptotypes.PubKey, seq uint64, fee int64) *poolTx {
+	fx.n++
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], fx.n)
+
+	return &poolTx{
+		hash:   sha256.Sum256(id[:]),
+		signer: signer,
+		seq:    seq,
+		fee:    sdk.NewCoins(sdk.NewInt64Coin("stake", fee)),
+	}
+}
+
+// sdkTx is the set of SDK transaction interfaces the pool inspects.
+type sdkTx interface {
+	transaction.Tx
+	authsigning.SigVerifiableTx
+	sdk.FeeTx
+}
+
+// poolTx implements only the parts of [sdkTx] that the pool and buffer use;
+// calling any other method panics.
+type poolTx struct {
+	sdkTx
+
+	hash   [32]byte
+	signer cryptotypes.PubKey
+	seq    uint64
+	fee    sdk.Coins
+}
+
+func (tx *poolTx) Hash() [32]byte { return tx.hash }
+
+func (tx *poolTx) Bytes() []byte { return tx.hash[:] }
+
+func (tx *poolTx) GetSignaturesV2() ([]signing.SignatureV2, error) {
+	return []signing.SignatureV2{{PubKey: tx.signer, Sequence: tx.seq}}, nil
+}
+
+func (tx *poolTx) GetFee() sdk.Coins { return tx.fee }
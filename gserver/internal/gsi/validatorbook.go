@@ -0,0 +1,69 @@
+package gsi
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/core/transaction"
+	"cosmossdk.io/server/v2/appmanager"
+	stakingtypes "cosmossdk.io/x/staking/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gvalbook"
+)
+
+// refreshValidatorBook records the monikers of the staking module's validators
+// in the driver's validator book, if it has one.
+// Failures are only logged, as monikers are purely informational.
+func (d *Driver) refreshValidatorBook(ctx context.Context) {
+	if d.valBook == nil {
+		return
+	}
+
+	n, err := loadStakingMonikers(ctx, d.am, d.valBook)
+	if err != nil {
+		d.log.Info("Failed to refresh validator monikers from staking state", "err", err)
+		return
+	}
+	d.log.Debug("Refreshed validator monikers from staking state", "n_validators", n)
+}
+
+// loadStakingMonikers records in book the moniker of every staking validator
+// with an ed25519 consensus key, at the latest committed state,
+// returning the number of validators recorded.
+func loadStakingMonikers(
+	ctx context.Context, am appmanager.AppManager[transaction.Tx], book *gvalbook.Book,
+) (int, error) {
+	n := 0
+	var nextKey []byte
+	for {
+		msg, err := am.Query(ctx, 0, &stakingtypes.QueryValidatorsRequest{
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return n, fmt.Errorf("failed to query staking validators: %w", err)
+		}
+		res, ok := msg.(*stakingtypes.QueryValidatorsResponse)
+		if !ok {
+			return n, fmt.Errorf("unexpected staking validators response type %T", msg)
+		}
+
+		for _, v := range res.Validators {
+			pk := v.ConsensusPubkey
+			if pk == nil || pk.TypeUrl != "/cosmos.crypto.ed25519.PubKey" {
+				continue
+			}
+			var key ed25519.PubKey
+			if err := key.Unmarshal(pk.Value); err != nil {
+				continue
+			}
+			book.Set(key.Key, v.Description.Moniker)
+			n++
+		}
+
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return n, nil
+		}
+		nextKey = res.Pagination.NextKey
+	}
+}
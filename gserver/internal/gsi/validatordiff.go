@@ -0,0 +1,64 @@
+package gsi
+
+import (
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+)
+
+// ValidatorDiff is the difference between two validator sets.
+type ValidatorDiff struct {
+	// Validators present only in the newer set, in the newer set's order.
+	Joined []tmconsensus.Validator
+
+	// Validators present only in the older set, in the older set's order.
+	Left []tmconsensus.Validator
+
+	// Validators present in both sets with a different power,
+	// in the newer set's order.
+	PowerChanged []ValidatorPowerChange
+}
+
+// ValidatorPowerChange is a single validator's power in two validator sets.
+type ValidatorPowerChange struct {
+	Validator tmconsensus.Validator // Has the new power.
+	OldPower  uint64
+}
+
+// DiffValidators returns the changes required to go from the older validator set
+// to the newer validator set.
+// Validators are matched by public key.
+func DiffValidators(older, newer []tmconsensus.Validator) ValidatorDiff {
+	oldPowers := make(map[string]uint64, len(older))
+	for _, v := range older {
+		oldPowers[string(v.PubKey.PubKeyBytes())] = v.Power
+	}
+
+	var d ValidatorDiff
+	for _, v := range newer {
+		k := string(v.PubKey.PubKeyBytes())
+		oldPow, ok := oldPowers[k]
+		if !ok {
+			d.Joined = append(d.Joined, v)
+			continue
+		}
+
+		// Deleting leaves only the validators that left.
+		delete(oldPowers, k)
+
+		if oldPow != v.Power {
+			d.PowerChanged = append(d.PowerChanged, ValidatorPowerChange{
+				Validator: v,
+				OldPower:  oldPow,
+			})
+		}
+	}
+
+	if len(oldPowers) > 0 {
+		for _, v := range older {
+			if _, ok := oldPowers[string(v.PubKey.PubKeyBytes())]; ok {
+				d.Left = append(d.Left, v)
+			}
+		}
+	}
+
+	return d
+}
@@ -0,0 +1,34 @@
+package gsi_test
+
+import (
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffValidators(t *testing.T) {
+	t.Parallel()
+
+	vals := tmconsensustest.DeterministicValidatorsEd25519(4).Vals()
+
+	older := []tmconsensus.Validator{vals[0], vals[1], vals[2]}
+
+	newer := []tmconsensus.Validator{vals[0], vals[2], vals[3]}
+	newer[1].Power += 5
+
+	d := gsi.DiffValidators(older, newer)
+
+	require.Equal(t, []tmconsensus.Validator{vals[3]}, d.Joined)
+	require.Equal(t, []tmconsensus.Validator{vals[1]}, d.Left)
+
+	require.Len(t, d.PowerChanged, 1)
+	require.True(t, vals[2].PubKey.Equal(d.PowerChanged[0].Validator.PubKey))
+	require.Equal(t, vals[2].Power, d.PowerChanged[0].OldPower)
+	require.Equal(t, vals[2].Power+5, d.PowerChanged[0].Validator.Power)
+
+	// Identical sets have no differences.
+	require.Zero(t, gsi.DiffValidators(older, older))
+}
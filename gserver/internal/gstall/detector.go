@@ -0,0 +1,330 @@
+// Package gstall detects when consensus stops making progress.
+//
+// A [Detector] wraps the engine's gossip strategy,
+// through which the engine publishes every change to its network view.
+// If the voting height and round do not advance within the configured threshold,
+// the detector logs a structured stall report
+// and repeats the report every threshold until consensus resumes.
+package gstall
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"runtime/trace"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/gchan"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/gordian-engine/gordian/tm/tmgossip"
+)
+
+// Report describes a stalled voting round.
+type Report struct {
+	Height uint64
+	Round  uint32
+
+	// When the voting height or round last advanced,
+	// and how long ago that was when the report was made.
+	Since      time.Time
+	StalledFor time.Duration
+
+	// Vote power summary for the voting round.
+	AvailablePower uint64
+	PrevotePower   uint64
+	PrecommitPower uint64
+
+	// Hex-encoded hashes of the blocks with the most prevote and precommit power,
+	// or empty if nil has the most power.
+	MostVotedPrevoteHash   string `json:",omitempty"`
+	MostVotedPrecommitHash string `json:",omitempty"`
+
+	// Number of proposed headers seen for the round;
+	// zero indicates a missing proposal.
+	ProposedHeaders int
+
+	// Number of validators whose prevotes or precommits have not been seen.
+	MissingPrevotes   int
+	MissingPrecommits int
+
+	// Names of the validators counted in MissingPrevotes and MissingPrecommits,
+	// only set if the detector was configured with a ValidatorName function.
+	MissingPrevoters     []string `json:",omitempty"`
+	MissingPrecommitters []string `json:",omitempty"`
+
+	// Number of block data fetches not yet complete,
+	// or -1 if not reported.
+	InFlightFetches int
+
+	// Number of connected peers, or -1 if not reported.
+	Peers int
+}
+
+// Status is the detector's current state, returned by [*Detector.Status].
+type Status struct {
+	// Whether consensus is currently stalled.
+	Stalled bool
+
+	// Number of stall reports made since the detector started.
+	Reports uint64
+
+	// The most recent report, or nil if there has not been one.
+	Latest *Report `json:",omitempty"`
+}
+
+// DetectorConfig is the configuration for [NewDetector].
+type DetectorConfig struct {
+	// The gossip strategy receiving the engine's network view updates.
+	Inner tmgossip.Strategy
+
+	// How long the voting height and round may stay unchanged
+	// before a stall is reported.
+	Threshold time.Duration
+
+	// Optional sources for the in-flight fetch and peer counts in reports.
+	InFlightFetches func() int
+	PeerCount       func() int
+
+	// Optional function naming a validator by its public key bytes in reports,
+	// such as [*gvalbook.Book.Name].
+	ValidatorName func(pubKey []byte) string
+}
+
+// Detector is a [tmgossip.Strategy] that forwards updates to an inner strategy,
+// reporting stalls as described in the package documentation.
+type Detector struct {
+	log *slog.Logger
+
+	inner     tmgossip.Strategy
+	threshold time.Duration
+
+	inFlightFetches func() int
+	peerCount       func() int
+	validatorName   func(pubKey []byte) string
+
+	startCh chan (<-chan tmelink.NetworkViewUpdate)
+
+	mu     sync.Mutex
+	status Status
+
+	done chan struct{}
+}
+
+// NewDetector returns a new Detector based on cfg.
+// The detector stops when ctx is canceled;
+// the inner strategy must stop with the same context.
+func NewDetector(ctx context.Context, log *slog.Logger, cfg DetectorConfig) (*Detector, error) {
+	if cfg.Inner == nil {
+		return nil, errors.New("inner gossip strategy is required")
+	}
+	if cfg.Threshold <= 0 {
+		return nil, errors.New("stall threshold must be positive")
+	}
+
+	d := &Detector{
+		log: log,
+
+		inner:     cfg.Inner,
+		threshold: cfg.Threshold,
+
+		inFlightFetches: cfg.InFlightFetches,
+		peerCount:       cfg.PeerCount,
+		validatorName:   cfg.ValidatorName,
+
+		startCh: make(chan (<-chan tmelink.NetworkViewUpdate), 1),
+
+		done: make(chan struct{}),
+	}
+
+	go d.kernel(ctx)
+
+	return d, nil
+}
+
+func (d *Detector) Start(updates <-chan tmelink.NetworkViewUpdate) {
+	d.startCh <- updates
+	close(d.startCh)
+}
+
+// Wait blocks until d and its inner strategy have stopped.
+func (d *Detector) Wait() {
+	<-d.done
+	d.inner.Wait()
+}
+
+// Status returns the detector's current state.
+func (d *Detector) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.status
+	if s.Latest != nil {
+		r := *s.Latest
+		s.Latest = &r
+	}
+	return s
+}
+
+func (d *Detector) kernel(ctx context.Context) {
+	defer close(d.done)
+
+	ctx, task := trace.NewTask(ctx, "Detector.kernel")
+	defer task.End()
+
+	updates, ok := gchan.RecvC(ctx, d.log, d.startCh, "waiting for start signal")
+	if !ok {
+		return
+	}
+
+	fwd := make(chan tmelink.NetworkViewUpdate)
+	d.inner.Start(fwd)
+
+	// The timer only runs once there is a voting view,
+	// so that time spent before the engine starts voting is not a stall.
+	timer := time.NewTimer(d.threshold)
+	timer.Stop()
+	defer timer.Stop()
+
+	var voting *tmconsensus.VersionedRoundView
+	var since time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case u := <-updates:
+			if u.Voting != nil {
+				advanced := voting == nil ||
+					u.Voting.Height != voting.Height || u.Voting.Round != voting.Round
+				voting = u.Voting
+				if advanced {
+					since = time.Now()
+					d.resume(voting.Height, voting.Round)
+					timer.Reset(d.threshold)
+				}
+			}
+
+			if !gchan.SendC(ctx, d.log, fwd, u, "forwarding network view update") {
+				return
+			}
+
+		case <-timer.C:
+			d.report(voting, since)
+			timer.Reset(d.threshold)
+		}
+	}
+}
+
+// resume clears the stalled state, logging if consensus had been stalled.
+func (d *Detector) resume(h uint64, r uint32) {
+	d.mu.Lock()
+	stalled := d.status.Stalled
+	d.status.Stalled = false
+	d.mu.Unlock()
+
+	if stalled {
+		d.log.Info("Consensus resumed after stall", "height", h, "round", r)
+	}
+}
+
+func (d *Detector) report(v *tmconsensus.VersionedRoundView, since time.Time) {
+	vals := v.ValidatorSet.Validators
+	missingPrevotes := missingVoters(len(vals), v.PrevoteProofs)
+	missingPrecommits := missingVoters(len(vals), v.PrecommitProofs)
+
+	r := Report{
+		Height: v.Height,
+		Round:  v.Round,
+
+		Since:      since,
+		StalledFor: time.Since(since),
+
+		AvailablePower: v.VoteSummary.AvailablePower,
+		PrevotePower:   v.VoteSummary.TotalPrevotePower,
+		PrecommitPower: v.VoteSummary.TotalPrecommitPower,
+
+		MostVotedPrevoteHash:   hex.EncodeToString([]byte(v.VoteSummary.MostVotedPrevoteHash)),
+		MostVotedPrecommitHash: hex.EncodeToString([]byte(v.VoteSummary.MostVotedPrecommitHash)),
+
+		ProposedHeaders: len(v.ProposedHeaders),
+
+		MissingPrevotes:   len(missingPrevotes),
+		MissingPrecommits: len(missingPrecommits),
+
+		InFlightFetches: -1,
+		Peers:           -1,
+	}
+	if d.inFlightFetches != nil {
+		r.InFlightFetches = d.inFlightFetches()
+	}
+	if d.peerCount != nil {
+		r.Peers = d.peerCount()
+	}
+	if d.validatorName != nil {
+		r.MissingPrevoters = d.names(vals, missingPrevotes)
+		r.MissingPrecommitters = d.names(vals, missingPrecommits)
+	}
+
+	d.mu.Lock()
+	d.status.Stalled = true
+	d.status.Reports++
+	d.status.Latest = &r
+	d.mu.Unlock()
+
+	d.log.Warn(
+		"Consensus stalled",
+		"event", "consensus_stall",
+		"height", r.Height,
+		"round", r.Round,
+		"stalled_for", r.StalledFor,
+		"available_power", r.AvailablePower,
+		"prevote_power", r.PrevotePower,
+		"precommit_power", r.PrecommitPower,
+		"most_voted_prevote", r.MostVotedPrevoteHash,
+		"most_voted_precommit", r.MostVotedPrecommitHash,
+		"proposed_headers", r.ProposedHeaders,
+		"missing_prevotes", r.MissingPrevotes,
+		"missing_precommits", r.MissingPrecommits,
+		"missing_prevoters", r.MissingPrevoters,
+		"missing_precommitters", r.MissingPrecommitters,
+		"in_flight_fetches", r.InFlightFetches,
+		"peers", r.Peers,
+	)
+}
+
+// names returns the names of the validators in vals at the given indices.
+func (d *Detector) names(vals []tmconsensus.Validator, idxs []int) []string {
+	out := make([]string, len(idxs))
+	for i, idx := range idxs {
+		out[i] = d.validatorName(vals[idx].PubKey.PubKeyBytes())
+	}
+	return out
+}
+
+// missingVoters returns the indices of the nVals validators
+// without a signature in any of the proofs.
+func missingVoters(nVals int, proofs map[string]gcrypto.CommonMessageSignatureProof) []int {
+	voted := make([]bool, nVals)
+	for _, p := range proofs {
+		bs := p.SignatureBitSet()
+		for i := range voted {
+			if bs.Test(uint(i)) {
+				voted[i] = true
+			}
+		}
+	}
+
+	var missing []int
+	for i, v := range voted {
+		if !v {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,138 @@
+package gstall_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/ggossip/ggossiptest"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gstall"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/stretchr/testify/require"
+)
+
+func votingUpdate(h uint64, r uint32) tmelink.NetworkViewUpdate {
+	return tmelink.NetworkViewUpdate{
+		Voting: &tmconsensus.VersionedRoundView{
+			RoundView: tmconsensus.RoundView{
+				Height: h,
+				Round:  r,
+
+				VoteSummary: tmconsensus.VoteSummary{
+					AvailablePower:    10,
+					TotalPrevotePower: 4,
+				},
+			},
+		},
+	}
+}
+
+func TestDetector_stall(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d, err := gstall.NewDetector(ctx, gtest.NewLogger(t), gstall.DetectorConfig{
+		Inner:     ggossiptest.NewRecordingStrategy(ctx),
+		Threshold: 20 * time.Millisecond,
+
+		PeerCount: func() int { return 3 },
+	})
+	require.NoError(t, err)
+	defer d.Wait()
+	defer cancel()
+
+	updates := make(chan tmelink.NetworkViewUpdate)
+	d.Start(updates)
+
+	// No reports before the first voting view.
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, d.Status().Reports)
+
+	gtest.SendSoon(t, updates, votingUpdate(2, 0))
+	require.Eventually(t, func() bool {
+		return d.Status().Stalled
+	}, time.Second, 5*time.Millisecond)
+
+	s := d.Status()
+	require.NotNil(t, s.Latest)
+	require.Equal(t, uint64(2), s.Latest.Height)
+	require.Equal(t, uint64(10), s.Latest.AvailablePower)
+	require.Equal(t, uint64(4), s.Latest.PrevotePower)
+	require.Zero(t, s.Latest.ProposedHeaders)
+	require.Equal(t, 3, s.Latest.Peers)
+	require.Equal(t, -1, s.Latest.InFlightFetches)
+
+	// Advancing the round clears the stall, but keeps the count and latest report.
+	gtest.SendSoon(t, updates, votingUpdate(2, 1))
+	require.Eventually(t, func() bool {
+		return !d.Status().Stalled
+	}, time.Second, time.Millisecond)
+
+	s = d.Status()
+	require.NotZero(t, s.Reports)
+	require.Equal(t, uint32(0), s.Latest.Round)
+}
+
+func TestDetector_validatorNames(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d, err := gstall.NewDetector(ctx, gtest.NewLogger(t), gstall.DetectorConfig{
+		Inner:     ggossiptest.NewRecordingStrategy(ctx),
+		Threshold: 20 * time.Millisecond,
+
+		ValidatorName: func(pubKey []byte) string {
+			return fmt.Sprintf("val-%d", pubKey[0])
+		},
+	})
+	require.NoError(t, err)
+	defer d.Wait()
+	defer cancel()
+
+	updates := make(chan tmelink.NetworkViewUpdate)
+	d.Start(updates)
+
+	fx := tmconsensustest.NewStandardFixture(2)
+	u := votingUpdate(1, 0)
+	u.Voting.ValidatorSet = fx.ValSet()
+	gtest.SendSoon(t, updates, u)
+
+	require.Eventually(t, func() bool {
+		return d.Status().Stalled
+	}, time.Second, 5*time.Millisecond)
+
+	// Without any proofs, every validator is missing.
+	var want []string
+	for _, v := range fx.ValSet().Validators {
+		want = append(want, fmt.Sprintf("val-%d", v.PubKey.PubKeyBytes()[0]))
+	}
+	s := d.Status()
+	require.Equal(t, 2, s.Latest.MissingPrevotes)
+	require.Equal(t, want, s.Latest.MissingPrevoters)
+	require.Equal(t, want, s.Latest.MissingPrecommitters)
+}
+
+func TestNewDetector_validation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := gstall.NewDetector(ctx, gtest.NewLogger(t), gstall.DetectorConfig{
+		Threshold: time.Second,
+	})
+	require.Error(t, err)
+
+	_, err = gstall.NewDetector(ctx, gtest.NewLogger(t), gstall.DetectorConfig{
+		Inner: ggossiptest.NewRecordingStrategy(ctx),
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,130 @@
+// Package gstop records why each of a node's long-running subsystems stopped.
+//
+// Subsystems normally stop because their context was canceled during shutdown.
+// A subsystem that stops on its own, such as after the engine watchdog terminates,
+// otherwise leaves the node running but no longer making progress;
+// the [Report] logs that case as an error and retains it for later inspection.
+package gstop
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrNoCause is the cause recorded for a subsystem
+// that stopped without an error and before its context was canceled.
+var ErrNoCause = errors.New("stopped without error or context cancellation")
+
+// Entry describes the stop of a single subsystem.
+type Entry struct {
+	Subsystem string
+	StoppedAt time.Time
+
+	// The error the subsystem stopped with, if it reported one;
+	// otherwise the cause of its context's cancellation,
+	// or ErrNoCause if the context was not canceled.
+	Cause error
+
+	// Whether the subsystem stopped while its context was still active,
+	// before [*Report.ShuttingDown] was called.
+	Unexpected bool
+}
+
+// Report collects an [Entry] for each subsystem as it stops.
+// It is safe for concurrent use.
+type Report struct {
+	log *slog.Logger
+
+	mu       sync.Mutex
+	running  []string
+	entries  []Entry
+	shutdown error
+}
+
+// NewReport returns a new, empty Report.
+func NewReport(log *slog.Logger) *Report {
+	return &Report{log: log}
+}
+
+// Watch starts a goroutine that calls wait,
+// and records the subsystem as stopped once wait returns.
+// The ctx argument must be the context the subsystem was started with,
+// so that its cancellation cause can be recorded.
+func (r *Report) Watch(ctx context.Context, subsystem string, wait func()) {
+	r.Started(subsystem)
+	go func() {
+		wait()
+		r.Stopped(ctx, subsystem, nil)
+	}()
+}
+
+// Started records subsystem as running.
+// Watch calls Started, so it is only needed for subsystems
+// that call [*Report.Stopped] themselves.
+func (r *Report) Started(subsystem string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running = append(r.running, subsystem)
+}
+
+// ShuttingDown records that the node is stopping for the given cause.
+// Subsystems stopping afterwards are not reported as unexpected,
+// even if their own context has not been canceled,
+// and cause is recorded for those that did not report a cause of their own.
+func (r *Report) ShuttingDown(cause error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shutdown = cause
+}
+
+// Stopped records that subsystem stopped, with err if it failed.
+// The ctx argument must be the context the subsystem was started with.
+func (r *Report) Stopped(ctx context.Context, subsystem string, err error) {
+	e := Entry{
+		Subsystem: subsystem,
+		StoppedAt: time.Now(),
+		Cause:     err,
+	}
+	if e.Cause == nil {
+		e.Cause = context.Cause(ctx)
+	}
+
+	r.mu.Lock()
+	e.Unexpected = ctx.Err() == nil && r.shutdown == nil
+	if e.Cause == nil {
+		e.Cause = r.shutdown
+	}
+	if e.Cause == nil {
+		e.Cause = ErrNoCause
+	}
+	if i := slices.Index(r.running, subsystem); i >= 0 {
+		r.running = slices.Delete(r.running, i, i+1)
+	}
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+
+	if e.Unexpected {
+		r.log.Error("Subsystem stopped unexpectedly", "subsystem", subsystem, "cause", e.Cause)
+	} else {
+		r.log.Debug("Subsystem stopped", "subsystem", subsystem, "cause", e.Cause)
+	}
+}
+
+// Entries returns the entries recorded so far, in the order the subsystems stopped.
+func (r *Report) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.entries)
+}
+
+// Running returns the names of the subsystems that have not yet stopped,
+// in the order they started.
+func (r *Report) Running() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.running)
+}
@@ -0,0 +1,81 @@
+package gstop_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gstop"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Watch(t *testing.T) {
+	t.Parallel()
+
+	r := gstop.NewReport(gtest.NewLogger(t))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	aDone := make(chan struct{})
+	bDone := make(chan struct{})
+	r.Watch(ctx, "a", func() { <-aDone })
+	r.Watch(ctx, "b", func() { <-bDone })
+	require.Equal(t, []string{"a", "b"}, r.Running())
+
+	// b stops on its own while the context is still active.
+	close(bDone)
+	require.Eventually(t, func() bool { return len(r.Entries()) == 1 }, time.Second, 5*time.Millisecond)
+
+	e := r.Entries()[0]
+	require.Equal(t, "b", e.Subsystem)
+	require.True(t, e.Unexpected)
+	require.ErrorIs(t, e.Cause, gstop.ErrNoCause)
+	require.Equal(t, []string{"a"}, r.Running())
+
+	// a stops due to the context being canceled.
+	stopErr := errors.New("shutting down")
+	cancel(stopErr)
+	close(aDone)
+	require.Eventually(t, func() bool { return len(r.Entries()) == 2 }, time.Second, 5*time.Millisecond)
+
+	e = r.Entries()[1]
+	require.Equal(t, "a", e.Subsystem)
+	require.False(t, e.Unexpected)
+	require.ErrorIs(t, e.Cause, stopErr)
+	require.Empty(t, r.Running())
+}
+
+func TestReport_Stopped_error(t *testing.T) {
+	t.Parallel()
+
+	r := gstop.NewReport(gtest.NewLogger(t))
+
+	r.Started("x")
+	failErr := errors.New("token gone")
+	r.Stopped(context.Background(), "x", failErr)
+
+	entries := r.Entries()
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Unexpected)
+	require.ErrorIs(t, entries[0].Cause, failErr)
+}
+
+func TestReport_ShuttingDown(t *testing.T) {
+	t.Parallel()
+
+	r := gstop.NewReport(gtest.NewLogger(t))
+
+	stopErr := errors.New("stopping")
+	r.ShuttingDown(stopErr)
+
+	r.Started("x")
+	r.Stopped(context.Background(), "x", nil)
+
+	entries := r.Entries()
+	require.Len(t, entries, 1)
+	require.False(t, entries[0].Unexpected)
+	require.ErrorIs(t, entries[0].Cause, stopErr)
+}
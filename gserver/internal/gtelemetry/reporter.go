@@ -0,0 +1,311 @@
+// Package gtelemetry periodically posts anonymized network statistics
+// to an operator-configured HTTP endpoint,
+// for chains that want fleet-wide dashboards without scraping every node.
+//
+// Reporting is opt-in: no [Reporter] runs unless an endpoint is configured.
+// A [Report] contains no node identity, addresses, or keys;
+// it only identifies the reporting process by a random ID chosen at startup,
+// so that a dashboard can count nodes without knowing which node is which.
+package gtelemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"runtime/trace"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatermark"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+)
+
+// DefaultInterval is the reporting interval used when [ReporterConfig.Interval] is zero.
+const DefaultInterval = time.Minute
+
+// The most round durations kept between reports;
+// later rounds in the same interval are not sampled.
+const maxRoundSamples = 4096
+
+// How long to spend posting a single report.
+const postTimeout = 10 * time.Second
+
+// Report is the JSON body posted to the endpoint.
+type Report struct {
+	// Random, chosen when the reporter starts.
+	InstanceID string
+
+	ChainID string
+	Time    time.Time
+
+	Version Version
+
+	VotingHeight    uint64
+	VotingRound     uint32
+	FinalizedHeight uint64
+
+	// Durations of the rounds completed since the previous report.
+	Rounds RoundStats
+
+	Peers int
+}
+
+// Version describes the reporting binary.
+type Version struct {
+	// The main module's path and version, e.g. "github.com/example/chaind@v1.2.3".
+	Module string
+
+	// The VCS revision the binary was built from, if known.
+	Revision string `json:",omitempty"`
+
+	Go string
+}
+
+// RoundStats summarizes round durations.
+// Durations are encoded in JSON as integer nanoseconds.
+type RoundStats struct {
+	Count int
+
+	P50, P90, P99 time.Duration
+}
+
+// ReporterConfig is the configuration for [NewReporter].
+type ReporterConfig struct {
+	// The http or https URL to post reports to.
+	Endpoint string
+
+	// How often to post a report.
+	// Defaults to DefaultInterval if zero.
+	Interval time.Duration
+
+	ChainID string
+
+	// Source of the heights in reports.
+	Watermarks *gwatermark.Writer
+
+	// Optional source of the peer count in reports.
+	PeerCount func() int
+}
+
+// Reporter measures round durations from the engine's network view updates
+// and periodically posts a [Report] to the configured endpoint.
+type Reporter struct {
+	log *slog.Logger
+
+	endpoint string
+	interval time.Duration
+	client   *http.Client
+
+	instanceID string
+	chainID    string
+	version    Version
+
+	watermarks *gwatermark.Writer
+	peerCount  func() int
+
+	mu         sync.Mutex
+	curH       uint64
+	curR       uint32
+	roundStart time.Time
+	durations  []time.Duration
+
+	done chan struct{}
+}
+
+// NewReporter returns a new Reporter based on cfg,
+// which runs until ctx is canceled.
+func NewReporter(ctx context.Context, log *slog.Logger, cfg ReporterConfig) (*Reporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse telemetry endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("telemetry endpoint must use http or https (got %q)", u.Scheme)
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	r := &Reporter{
+		log: log,
+
+		endpoint: cfg.Endpoint,
+		interval: interval,
+		client:   new(http.Client),
+
+		instanceID: hex.EncodeToString(id),
+		chainID:    cfg.ChainID,
+		version:    buildVersion(),
+
+		watermarks: cfg.Watermarks,
+		peerCount:  cfg.PeerCount,
+
+		done: make(chan struct{}),
+	}
+
+	go r.kernel(ctx)
+
+	return r, nil
+}
+
+// Wait blocks until r has stopped.
+func (r *Reporter) Wait() {
+	<-r.done
+}
+
+// UpdateView records the duration of each voting round that the update moves past.
+// It is intended to be used as a gossip strategy view observer.
+func (r *Reporter) UpdateView(u tmelink.NetworkViewUpdate) {
+	if u.Voting == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, round := u.Voting.Height, u.Voting.Round
+	if h == r.curH && round == r.curR {
+		return
+	}
+
+	now := time.Now()
+	if !r.roundStart.IsZero() && len(r.durations) < maxRoundSamples {
+		r.durations = append(r.durations, now.Sub(r.roundStart))
+	}
+	r.curH, r.curR, r.roundStart = h, round, now
+}
+
+func (r *Reporter) kernel(ctx context.Context) {
+	defer close(r.done)
+
+	ctx, task := trace.NewTask(ctx, "Reporter.kernel")
+	defer task.End()
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case <-t.C:
+			if err := r.post(ctx, r.report()); err != nil && ctx.Err() == nil {
+				// The endpoint is an optional external service,
+				// so failures only warrant a debug log.
+				r.log.Debug("Failed to post telemetry report", "err", err)
+			}
+		}
+	}
+}
+
+// report returns the current report,
+// resetting the round durations for the next interval.
+func (r *Reporter) report() Report {
+	wm := r.watermarks.Latest()
+
+	rep := Report{
+		InstanceID: r.instanceID,
+
+		ChainID: r.chainID,
+		Time:    time.Now().UTC(),
+
+		Version: r.version,
+
+		VotingHeight:    wm.VotingHeight,
+		VotingRound:     wm.VotingRound,
+		FinalizedHeight: wm.FinalizedHeight,
+
+		Peers: -1,
+	}
+	if r.peerCount != nil {
+		rep.Peers = r.peerCount()
+	}
+
+	r.mu.Lock()
+	ds := r.durations
+	r.durations = nil
+	r.mu.Unlock()
+
+	rep.Rounds = roundStats(ds)
+	return rep
+}
+
+func (r *Reporter) post(ctx context.Context, rep Report) error {
+	b, err := json.Marshal(rep)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create report request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post report: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4*1024))
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// roundStats returns the nearest-rank percentiles of ds, which it sorts.
+func roundStats(ds []time.Duration) RoundStats {
+	s := RoundStats{Count: len(ds)}
+	if len(ds) == 0 {
+		return s
+	}
+
+	slices.Sort(ds)
+	pct := func(p int) time.Duration {
+		// Nearest rank: the smallest value with at least p% of values at or below it.
+		idx := (p*len(ds)+99)/100 - 1
+		return ds[idx]
+	}
+	s.P50, s.P90, s.P99 = pct(50), pct(90), pct(99)
+	return s
+}
+
+// buildVersion returns the version of the running binary from its build info.
+func buildVersion() Version {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Version{Module: "unknown"}
+	}
+
+	v := Version{
+		Module: bi.Main.Path + "@" + bi.Main.Version,
+		Go:     bi.GoVersion,
+	}
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.revision" {
+			v.Revision = s.Value
+		}
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+package gtelemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtelemetry"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatermark"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/stretchr/testify/require"
+)
+
+func votingUpdate(h uint64, r uint32) tmelink.NetworkViewUpdate {
+	return tmelink.NetworkViewUpdate{
+		Voting: &tmconsensus.VersionedRoundView{
+			RoundView: tmconsensus.RoundView{Height: h, Round: r},
+		},
+	}
+}
+
+func TestReporter(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan gtelemetry.Report, 8)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var rep gtelemetry.Report
+		if err := json.NewDecoder(req.Body).Decode(&rep); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reports <- rep
+	}))
+	defer srv.Close()
+
+	wm := gwatermark.NewWriter()
+	wm.UpdateView(votingUpdate(3, 1))
+	wm.SetFinalized(2)
+
+	r, err := gtelemetry.NewReporter(ctx, gtest.NewLogger(t), gtelemetry.ReporterConfig{
+		Endpoint: srv.URL,
+		Interval: 50 * time.Millisecond,
+		ChainID:  "test-chain",
+
+		Watermarks: wm,
+		PeerCount:  func() int { return 4 },
+	})
+	require.NoError(t, err)
+	defer r.Wait()
+	defer cancel()
+
+	// Two completed rounds; the third is still in progress.
+	r.UpdateView(votingUpdate(3, 0))
+	time.Sleep(5 * time.Millisecond)
+	r.UpdateView(votingUpdate(3, 1))
+	time.Sleep(5 * time.Millisecond)
+	r.UpdateView(votingUpdate(4, 0))
+
+	rep := gtest.ReceiveSoon(t, reports)
+	require.Equal(t, "test-chain", rep.ChainID)
+	require.NotEmpty(t, rep.InstanceID)
+	require.NotEmpty(t, rep.Version.Go)
+	require.Equal(t, uint64(3), rep.VotingHeight)
+	require.Equal(t, uint32(1), rep.VotingRound)
+	require.Equal(t, uint64(2), rep.FinalizedHeight)
+	require.Equal(t, 4, rep.Peers)
+
+	require.Equal(t, 2, rep.Rounds.Count)
+	require.GreaterOrEqual(t, rep.Rounds.P50, 5*time.Millisecond)
+	require.GreaterOrEqual(t, rep.Rounds.P99, rep.Rounds.P50)
+
+	// Round durations reset after each report.
+	rep = gtest.ReceiveSoon(t, reports)
+	require.Zero(t, rep.Rounds.Count)
+}
+
+func TestNewReporter_validation(t *testing.T) {
+	t.Parallel()
+
+	_, err := gtelemetry.NewReporter(context.Background(), gtest.NewLogger(t), gtelemetry.ReporterConfig{
+		Endpoint: "ftp://example.com/stats",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+// Package gtenant lets several chains hosted in one process
+// share one HTTP endpoint, each under its own URL prefix,
+// and scopes access to each chain with its own bearer tokens.
+//
+// The chains in one process must be instances of one chain type,
+// such as several networks built from the same app,
+// or several nodes of one network as in the integration tests.
+// The Cosmos SDK keeps some configuration, such as the bech32 prefixes,
+// global to the process, so different chain types cannot be hosted together.
+//
+// A [Host] listens on one address and serves the handler of each attached chain
+// under /chains/<chain ID>/, with the prefix stripped,
+// so that a chain's API looks the same whether it is hosted alone or shared.
+// Requests for one chain never reach another chain's handler,
+// and each handler may require its own [Tokens].
+//
+// [Shared] returns the one Host for an address in the process,
+// so that every chain configured with the same address attaches to it.
+package gtenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// PathPrefix is the URL prefix of every chain on a [Host];
+// the chain ID follows it.
+const PathPrefix = "/chains/"
+
+// Host serves attached chains' handlers on one listener.
+type Host struct {
+	log *slog.Logger
+	ln  net.Listener
+	srv *http.Server
+
+	mu     sync.RWMutex
+	chains map[string]http.Handler
+
+	done chan struct{}
+}
+
+// NewHost returns a Host serving on ln until ln is closed
+// or [Host.Close] is called.
+func NewHost(log *slog.Logger, ln net.Listener) *Host {
+	h := &Host{
+		log: log,
+		ln:  ln,
+
+		chains: make(map[string]http.Handler),
+
+		done: make(chan struct{}),
+	}
+	h.srv = &http.Server{Handler: h}
+
+	go h.serve()
+
+	return h
+}
+
+func (h *Host) serve() {
+	defer close(h.done)
+
+	if err := h.srv.Serve(h.ln); err != nil {
+		if errors.Is(err, net.ErrClosed) || errors.Is(err, http.ErrServerClosed) {
+			h.log.Info("Shared HTTP server shutting down")
+		} else {
+			h.log.Info("Shared HTTP server shutting down due to error", "err", err)
+		}
+	}
+}
+
+// Addr returns the address the host listens on.
+func (h *Host) Addr() net.Addr {
+	return h.ln.Addr()
+}
+
+// Close stops the host and waits for it to finish.
+func (h *Host) Close() error {
+	err := h.srv.Close()
+	<-h.done
+	return err
+}
+
+// Attach serves handler under the prefix for chainID,
+// until the returned detach function is called.
+// It is an error to attach a chain ID that is already attached.
+func (h *Host) Attach(chainID string, handler http.Handler) (detach func(), err error) {
+	if chainID == "" || strings.Contains(chainID, "/") {
+		return nil, fmt.Errorf("invalid chain ID %q for shared HTTP server", chainID)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.chains[chainID]; ok {
+		return nil, fmt.Errorf("chain %q is already served at %s", chainID, h.ln.Addr())
+	}
+	h.chains[chainID] = http.StripPrefix(PathPrefix+chainID, handler)
+
+	h.log.Info("Serving chain on shared HTTP server", "chain_id", chainID, "prefix", PathPrefix+chainID)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.chains, chainID)
+		})
+	}, nil
+}
+
+// ChainIDs returns the attached chain IDs in sorted order.
+func (h *Host) ChainIDs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]string, 0, len(h.chains))
+	for id := range h.chains {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// ServeHTTP dispatches req to the chain named in its path.
+// The bare prefix lists the attached chain IDs.
+func (h *Host) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == PathPrefix || req.URL.Path == strings.TrimSuffix(PathPrefix, "/") {
+		if err := json.NewEncoder(w).Encode(h.ChainIDs()); err != nil {
+			h.log.Warn("Failed to encode chain IDs", "err", err)
+		}
+		return
+	}
+
+	rest, ok := strings.CutPrefix(req.URL.Path, PathPrefix)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	chainID, _, _ := strings.Cut(rest, "/")
+
+	h.mu.RLock()
+	handler, ok := h.chains[chainID]
+	h.mu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("chain %q not served here", chainID), http.StatusNotFound)
+		return
+	}
+
+	handler.ServeHTTP(w, req)
+}
+
+var (
+	sharedMu sync.Mutex
+	shared   = make(map[string]*sharedHost)
+)
+
+type sharedHost struct {
+	host *Host
+	refs int
+}
+
+// Shared returns the process-wide Host listening on addr,
+// starting it if this is the first use of addr.
+// The caller must call release once it has detached its chain;
+// the host stops when its last user releases it.
+//
+// Every caller must use the same spelling of addr
+// to share one host.
+func Shared(log *slog.Logger, addr string) (h *Host, release func(), err error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	sh, ok := shared[addr]
+	if !ok {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to listen for shared HTTP on %q: %w", addr, err)
+		}
+		sh = &sharedHost{host: NewHost(log, ln)}
+		shared[addr] = sh
+	}
+	sh.refs++
+
+	var once sync.Once
+	return sh.host, func() {
+		once.Do(func() {
+			sharedMu.Lock()
+			defer sharedMu.Unlock()
+
+			sh.refs--
+			if sh.refs > 0 {
+				return
+			}
+			delete(shared, addr)
+			if err := sh.host.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				log.Warn("Error closing shared HTTP server", "err", err)
+			}
+		})
+	}, nil
+}
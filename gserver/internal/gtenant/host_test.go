@@ -0,0 +1,156 @@
+package gtenant_test
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gtenant"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, url, token string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(b)
+}
+
+func echoPath(chainID string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, chainID+" "+req.URL.Path)
+	})
+}
+
+func TestHost(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	h := gtenant.NewHost(gtest.NewLogger(t), ln)
+	defer h.Close()
+
+	detachA, err := h.Attach("chain-a", echoPath("a"))
+	require.NoError(t, err)
+	defer detachA()
+
+	detachB, err := h.Attach("chain-b", gtenant.NewTokens("secret").Wrap(echoPath("b")))
+	require.NoError(t, err)
+
+	_, err = h.Attach("chain-a", echoPath("again"))
+	require.Error(t, err)
+	_, err = h.Attach("bad/id", echoPath("bad"))
+	require.Error(t, err)
+
+	base := "http://" + h.Addr().String()
+
+	// The prefix is stripped, so each chain sees its usual paths.
+	code, body := get(t, base+"/chains/chain-a/blocks/watermark", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "a /blocks/watermark", body)
+
+	// Only the second chain requires a token.
+	code, _ = get(t, base+"/chains/chain-b/blocks/watermark", "")
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, _ = get(t, base+"/chains/chain-b/blocks/watermark", "wrong")
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, body = get(t, base+"/chains/chain-b/blocks/watermark", "secret")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "b /blocks/watermark", body)
+
+	code, body = get(t, base+"/chains/", "")
+	require.Equal(t, http.StatusOK, code)
+	var ids []string
+	require.NoError(t, json.Unmarshal([]byte(body), &ids))
+	require.Equal(t, []string{"chain-a", "chain-b"}, ids)
+
+	code, _ = get(t, base+"/chains/chain-c/", "")
+	require.Equal(t, http.StatusNotFound, code)
+	code, _ = get(t, base+"/blocks/watermark", "")
+	require.Equal(t, http.StatusNotFound, code)
+
+	// A detached chain is no longer served, and its ID may be attached again.
+	detachB()
+	code, _ = get(t, base+"/chains/chain-b/blocks/watermark", "secret")
+	require.Equal(t, http.StatusNotFound, code)
+	detachB, err = h.Attach("chain-b", echoPath("b2"))
+	require.NoError(t, err)
+	defer detachB()
+}
+
+func TestShared(t *testing.T) {
+	t.Parallel()
+
+	// Reserve a free port to share.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	log := gtest.NewLogger(t)
+	h1, release1, err := gtenant.Shared(log, addr)
+	require.NoError(t, err)
+	h2, release2, err := gtenant.Shared(log, addr)
+	require.NoError(t, err)
+	require.Same(t, h1, h2)
+
+	_, err = h1.Attach("chain-a", echoPath("a"))
+	require.NoError(t, err)
+
+	// The host keeps serving until its last user releases it.
+	release1()
+	release1()
+	code, _ := get(t, "http://"+addr+"/chains/chain-a/", "")
+	require.Equal(t, http.StatusOK, code)
+
+	release2()
+	_, err = http.Get("http://" + addr + "/chains/")
+	require.Error(t, err)
+
+	// The address can be used again afterwards.
+	h3, release3, err := gtenant.Shared(log, addr)
+	require.NoError(t, err)
+	defer release3()
+	require.NotSame(t, h1, h3)
+}
+
+func TestLoadTokens(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tokens")
+	require.NoError(t, os.WriteFile(path, []byte("# operators\none\n\n  two  \n"), 0o600))
+
+	tokens, err := gtenant.LoadTokens(path)
+	require.NoError(t, err)
+	require.True(t, tokens.Enabled())
+	require.True(t, tokens.Allow("Bearer one"))
+	require.True(t, tokens.Allow("Bearer two"))
+	require.False(t, tokens.Allow("Bearer three"))
+	require.False(t, tokens.Allow("one"))
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("# nothing\n"), 0o600))
+	_, err = gtenant.LoadTokens(empty)
+	require.Error(t, err)
+
+	var none *gtenant.Tokens
+	require.False(t, none.Enabled())
+	require.True(t, none.Allow(""))
+}
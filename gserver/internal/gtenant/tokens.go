@@ -0,0 +1,100 @@
+package gtenant
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Tokens are the bearer tokens granting access to one chain's API.
+// A nil or empty Tokens grants access to every request.
+type Tokens struct {
+	// SHA-256 hashes of the tokens, so that comparisons take constant time
+	// regardless of token length.
+	hashes [][sha256.Size]byte
+}
+
+// NewTokens returns Tokens accepting each of tokens.
+func NewTokens(tokens ...string) *Tokens {
+	t := &Tokens{hashes: make([][sha256.Size]byte, len(tokens))}
+	for i, tok := range tokens {
+		t.hashes[i] = sha256.Sum256([]byte(tok))
+	}
+	return t
+}
+
+// LoadTokens reads tokens from the file at path, one per line.
+// Blank lines and lines starting with # are ignored.
+func LoadTokens(path string) (*Tokens, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var tokens []string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token file %s contains no tokens", path)
+	}
+
+	return NewTokens(tokens...), nil
+}
+
+// Enabled reports whether t restricts access.
+func (t *Tokens) Enabled() bool {
+	return t != nil && len(t.hashes) > 0
+}
+
+// Allow reports whether the value of an Authorization header,
+// of the form "Bearer <token>", carries one of the tokens.
+// It always reports true if t is not enabled.
+func (t *Tokens) Allow(authorization string) bool {
+	if !t.Enabled() {
+		return true
+	}
+
+	tok, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return false
+	}
+	h := sha256.Sum256([]byte(tok))
+
+	found := 0
+	for _, want := range t.hashes {
+		found |= subtle.ConstantTimeCompare(h[:], want[:])
+	}
+	return found == 1
+}
+
+// Wrap returns a handler that rejects requests without one of the tokens
+// with 401 Unauthorized, and passes the others to h.
+// It returns h itself if t is not enabled.
+func (t *Tokens) Wrap(h http.Handler) http.Handler {
+	if !t.Enabled() {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !t.Allow(req.Header.Get("Authorization")) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,160 @@
+// Package gvalbook maps validators' consensus public keys to their monikers,
+// so that logs and RPC output can name validators
+// instead of showing raw hex-encoded keys.
+//
+// A [Book] is populated from the genesis file's gentxs and staking validators,
+// and later from the staking module's state as the validator set changes.
+package gvalbook
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ed25519PubKeyType is the type URL of the only consensus key type in use.
+const ed25519PubKeyType = "/cosmos.crypto.ed25519.PubKey"
+
+// Book is a concurrency-safe map of consensus public keys to monikers.
+//
+// Keys are the raw public key bytes, as returned by PubKeyBytes on a gcrypto.PubKey.
+// A nil *Book knows no monikers.
+type Book struct {
+	mu       sync.RWMutex
+	monikers map[string]string
+}
+
+// New returns a new, empty Book.
+func New() *Book {
+	return &Book{
+		monikers: make(map[string]string),
+	}
+}
+
+// Set records moniker for the validator with the given public key,
+// replacing any earlier moniker.
+// An empty moniker is ignored.
+func (b *Book) Set(pubKey []byte, moniker string) {
+	if moniker == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.monikers[string(pubKey)] = moniker
+}
+
+// Moniker returns the moniker of the validator with the given public key,
+// or the empty string if it is not known.
+func (b *Book) Moniker(pubKey []byte) string {
+	if b == nil {
+		return ""
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.monikers[string(pubKey)]
+}
+
+// Name returns the moniker of the validator with the given public key,
+// or the hex-encoded key if the moniker is not known.
+func (b *Book) Name(pubKey []byte) string {
+	if m := b.Moniker(pubKey); m != "" {
+		return m
+	}
+	return hex.EncodeToString(pubKey)
+}
+
+// Len returns the number of validators with a known moniker.
+func (b *Book) Len() int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.monikers)
+}
+
+// jsonPubKey is the JSON encoding of a public key packed in an Any.
+type jsonPubKey struct {
+	Type string `json:"@type"`
+	Key  []byte `json:"key"`
+}
+
+type jsonDescription struct {
+	Moniker string `json:"moniker"`
+}
+
+// genesis is the subset of the genesis file that names validators.
+type genesis struct {
+	AppState struct {
+		Genutil struct {
+			GenTxs []struct {
+				Body struct {
+					Messages []struct {
+						Type        string          `json:"@type"`
+						Description jsonDescription `json:"description"`
+						PubKey      jsonPubKey      `json:"pubkey"`
+					} `json:"messages"`
+				} `json:"body"`
+			} `json:"gen_txs"`
+		} `json:"genutil"`
+
+		Staking struct {
+			Validators []struct {
+				Description     jsonDescription `json:"description"`
+				ConsensusPubKey jsonPubKey      `json:"consensus_pubkey"`
+			} `json:"validators"`
+		} `json:"staking"`
+	} `json:"app_state"`
+}
+
+// LoadGenesisFile is like [*Book.ReadGenesis] but reads the genesis file at path.
+func (b *Book) LoadGenesisFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open genesis file: %w", err)
+	}
+	defer f.Close()
+
+	return b.ReadGenesis(f)
+}
+
+// ReadGenesis records the monikers of the validators
+// created by gentxs or listed in the staking state of the genesis JSON in r,
+// returning the number of monikers recorded.
+// Validators whose consensus key is not ed25519 are skipped.
+func (b *Book) ReadGenesis(r io.Reader) (int, error) {
+	var g genesis
+	if err := json.NewDecoder(r).Decode(&g); err != nil {
+		return 0, fmt.Errorf("failed to parse validators from genesis: %w", err)
+	}
+
+	n := 0
+	set := func(pk jsonPubKey, d jsonDescription) {
+		if pk.Type != ed25519PubKeyType || len(pk.Key) == 0 || d.Moniker == "" {
+			return
+		}
+		b.Set(pk.Key, d.Moniker)
+		n++
+	}
+
+	for _, tx := range g.AppState.Genutil.GenTxs {
+		for _, msg := range tx.Body.Messages {
+			if msg.Type != "/cosmos.staking.v1beta1.MsgCreateValidator" {
+				continue
+			}
+			set(msg.PubKey, msg.Description)
+		}
+	}
+
+	for _, v := range g.AppState.Staking.Validators {
+		set(v.ConsensusPubKey, v.Description)
+	}
+
+	return n, nil
+}
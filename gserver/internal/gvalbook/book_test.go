@@ -0,0 +1,80 @@
+package gvalbook_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gvalbook"
+	"github.com/stretchr/testify/require"
+)
+
+const testGenesis = `{
+  "app_state": {
+    "genutil": {
+      "gen_txs": [
+        {
+          "body": {
+            "messages": [
+              {
+                "@type": "/cosmos.staking.v1beta1.MsgCreateValidator",
+                "description": {"moniker": "alpha"},
+                "pubkey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "AQID"}
+              }
+            ]
+          }
+        }
+      ]
+    },
+    "staking": {
+      "validators": [
+        {
+          "description": {"moniker": "beta"},
+          "consensus_pubkey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "BAUG"}
+        },
+        {
+          "description": {"moniker": "gamma"},
+          "consensus_pubkey": {"@type": "/cosmos.crypto.secp256k1.PubKey", "key": "BwgJ"}
+        }
+      ]
+    }
+  }
+}`
+
+func TestBook_ReadGenesis(t *testing.T) {
+	t.Parallel()
+
+	b := gvalbook.New()
+	n, err := b.ReadGenesis(strings.NewReader(testGenesis))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, 2, b.Len())
+
+	require.Equal(t, "alpha", b.Moniker([]byte{1, 2, 3}))
+	require.Equal(t, "beta", b.Moniker([]byte{4, 5, 6}))
+
+	// Only ed25519 consensus keys are recorded.
+	require.Empty(t, b.Moniker([]byte{7, 8, 9}))
+	require.Equal(t, hex.EncodeToString([]byte{7, 8, 9}), b.Name([]byte{7, 8, 9}))
+}
+
+func TestBook_Set(t *testing.T) {
+	t.Parallel()
+
+	b := gvalbook.New()
+	b.Set([]byte{1}, "alpha")
+	b.Set([]byte{1}, "")
+	require.Equal(t, "alpha", b.Name([]byte{1}))
+
+	b.Set([]byte{1}, "renamed")
+	require.Equal(t, "renamed", b.Name([]byte{1}))
+}
+
+func TestBook_nil(t *testing.T) {
+	t.Parallel()
+
+	var b *gvalbook.Book
+	require.Empty(t, b.Moniker([]byte{1}))
+	require.Equal(t, "01", b.Name([]byte{1}))
+	require.Zero(t, b.Len())
+}
@@ -0,0 +1,451 @@
+// Package gversion advertises and negotiates protocol versions with peers at connect time.
+//
+// Each node supports a range of versions of the block, gossip, and sync protocols.
+// When a peer connects, a [Negotiator] exchanges its [Versions] with the peer
+// and records the highest version of each protocol that both sides support,
+// so that a network can be upgraded one node at a time:
+// a new binary keeps supporting the previous version of a protocol
+// until every peer advertises the new one.
+//
+// A peer that shares no version of some protocol is disconnected,
+// unless the negotiator is configured to keep incompatible peers,
+// in which case that protocol is marked unusable for the peer.
+// Peers that predate version negotiation are assumed to support only version 1
+// of each protocol.
+//
+// Peers also exchange their chain ID and genesis hash in a [Hello],
+// and a peer on a different chain is always disconnected,
+// so that networks sharing infrastructure do not pollute each other's gossip.
+// Peers that only speak [ProtocolID] cannot report their chain,
+// and are assumed to be on the same chain.
+package gversion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"runtime/trace"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/glog"
+	libp2pevent "github.com/libp2p/go-libp2p/core/event"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+	libp2pprotocol "github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// ProtocolID is the libp2p protocol for version negotiation.
+// The initiator writes its JSON-encoded [Versions],
+// and the responder replies with its own.
+const ProtocolID = libp2pprotocol.ID("/gcosmos/versions/v1")
+
+// HelloProtocolID is the libp2p protocol for version negotiation
+// including the chain identity.
+// The initiator writes its JSON-encoded [Hello],
+// and the responder replies with its own.
+// Negotiation falls back to [ProtocolID] for peers that do not support it.
+const HelloProtocolID = libp2pprotocol.ID("/gcosmos/versions/v2")
+
+// Names of the negotiated protocols.
+const (
+	// Proposed block data.
+	ProtocolBlock = "block"
+
+	// Consensus message gossip.
+	ProtocolGossip = "gossip"
+
+	// Committed headers and full blocks for catching up.
+	ProtocolSync = "sync"
+)
+
+// How long to spend on a single negotiation.
+const negotiateTimeout = 5 * time.Second
+
+// Range is an inclusive range of supported versions.
+type Range struct {
+	Min, Max uint32
+}
+
+// Versions maps protocol names to the versions supported for each.
+type Versions map[string]Range
+
+// Hello is the message exchanged over [HelloProtocolID].
+type Hello struct {
+	Versions Versions
+
+	ChainID     string
+	GenesisHash []byte
+}
+
+// LocalVersions returns the protocol versions supported by this binary.
+func LocalVersions() Versions {
+	return Versions{
+		ProtocolBlock:  {Min: 1, Max: 1},
+		ProtocolGossip: {Min: 1, Max: 1},
+
+		// Version 2 added compact full blocks.
+		ProtocolSync: {Min: 1, Max: 2},
+	}
+}
+
+// legacyVersions are the versions assumed for a peer
+// that does not support version negotiation.
+func legacyVersions() Versions {
+	return Versions{
+		ProtocolBlock:  {Min: 1, Max: 1},
+		ProtocolGossip: {Min: 1, Max: 1},
+		ProtocolSync:   {Min: 1, Max: 1},
+	}
+}
+
+// Negotiate returns the highest version of each local protocol
+// also supported by remote, or zero if there is no such version.
+// The second result lists the protocols with no common version, sorted by name.
+func Negotiate(local, remote Versions) (map[string]uint32, []string) {
+	out := make(map[string]uint32, len(local))
+	var incompatible []string
+	for name, l := range local {
+		r, ok := remote[name]
+		if !ok {
+			out[name] = 0
+			incompatible = append(incompatible, name)
+			continue
+		}
+
+		lo, hi := max(l.Min, r.Min), min(l.Max, r.Max)
+		if lo > hi {
+			out[name] = 0
+			incompatible = append(incompatible, name)
+			continue
+		}
+		out[name] = hi
+	}
+	slices.Sort(incompatible)
+	return out, incompatible
+}
+
+// PeerVersions is the outcome of negotiating with a peer.
+type PeerVersions struct {
+	Peer string
+
+	// The highest common version of each protocol;
+	// zero for a protocol the peer cannot use.
+	Negotiated map[string]uint32
+
+	// Protocols with no common version.
+	Incompatible []string `json:",omitempty"`
+
+	// Set if the peer does not support version negotiation.
+	Legacy bool `json:",omitempty"`
+
+	// The chain ID the peer reported;
+	// empty if it does not support [HelloProtocolID].
+	ChainID string `json:",omitempty"`
+}
+
+// NegotiatorConfig is the configuration for [NewNegotiator].
+type NegotiatorConfig struct {
+	Host libp2phost.Host
+
+	// The versions to advertise.
+	// Defaults to LocalVersions() if nil.
+	Local Versions
+
+	// Whether to stay connected to a peer
+	// that shares no version of some protocol.
+	KeepIncompatible bool
+
+	// The local chain identity.
+	// A peer reporting a different chain ID or genesis hash is disconnected.
+	ChainID     string
+	GenesisHash []byte
+}
+
+// Negotiator negotiates protocol versions with every connected peer.
+type Negotiator struct {
+	log *slog.Logger
+
+	host             libp2phost.Host
+	local            Versions
+	keepIncompatible bool
+
+	chainID     string
+	genesisHash []byte
+
+	mu    sync.Mutex
+	peers map[libp2ppeer.ID]PeerVersions
+
+	done chan struct{}
+}
+
+// NewNegotiator returns a new Negotiator based on cfg,
+// which negotiates with the host's current peers and with every new peer
+// until ctx is canceled.
+func NewNegotiator(ctx context.Context, log *slog.Logger, cfg NegotiatorConfig) *Negotiator {
+	local := cfg.Local
+	if local == nil {
+		local = LocalVersions()
+	}
+
+	n := &Negotiator{
+		log: log,
+
+		host:             cfg.Host,
+		local:            local,
+		keepIncompatible: cfg.KeepIncompatible,
+
+		chainID:     cfg.ChainID,
+		genesisHash: cfg.GenesisHash,
+
+		peers: make(map[libp2ppeer.ID]PeerVersions),
+
+		done: make(chan struct{}),
+	}
+
+	n.host.SetStreamHandler(ProtocolID, n.handleStream)
+	n.host.SetStreamHandler(HelloProtocolID, n.handleHelloStream)
+
+	go n.kernel(ctx)
+
+	return n
+}
+
+// Wait blocks until n has stopped.
+func (n *Negotiator) Wait() {
+	<-n.done
+}
+
+// Peers returns the negotiated versions for each connected peer, sorted by peer ID.
+func (n *Negotiator) Peers() []PeerVersions {
+	n.mu.Lock()
+	out := make([]PeerVersions, 0, len(n.peers))
+	for _, pv := range n.peers {
+		out = append(out, pv)
+	}
+	n.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b PeerVersions) int {
+		return strings.Compare(a.Peer, b.Peer)
+	})
+	return out
+}
+
+// Version returns the negotiated version of the named protocol for p,
+// and false if negotiation with p has not completed.
+// Version may be called on a nil Negotiator, always returning false.
+func (n *Negotiator) Version(p libp2ppeer.ID, protocol string) (uint32, bool) {
+	if n == nil {
+		return 0, false
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	pv, ok := n.peers[p]
+	if !ok {
+		return 0, false
+	}
+	return pv.Negotiated[protocol], true
+}
+
+func (n *Negotiator) kernel(ctx context.Context) {
+	defer close(n.done)
+	defer n.host.RemoveStreamHandler(ProtocolID)
+	defer n.host.RemoveStreamHandler(HelloProtocolID)
+
+	ctx, task := trace.NewTask(ctx, "Negotiator.kernel")
+	defer task.End()
+
+	// Subscribe before listing the current peers,
+	// so that we cannot miss a connection between the two.
+	sub, err := n.host.EventBus().Subscribe(new(libp2pevent.EvtPeerConnectednessChanged))
+	if err != nil {
+		n.log.Warn(
+			"Failed to subscribe to peer connectedness events; not negotiating versions",
+			"err", err,
+		)
+		return
+	}
+	defer sub.Close()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for _, p := range n.host.Network().Peers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.negotiate(ctx, p)
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case e := <-sub.Out():
+			ev, ok := e.(libp2pevent.EvtPeerConnectednessChanged)
+			if !ok {
+				continue
+			}
+			switch ev.Connectedness {
+			case libp2pnetwork.Connected:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					n.negotiate(ctx, ev.Peer)
+				}()
+			case libp2pnetwork.NotConnected:
+				n.mu.Lock()
+				delete(n.peers, ev.Peer)
+				n.mu.Unlock()
+			}
+		}
+	}
+}
+
+// negotiate sends the local versions to p and records the outcome from p's reply.
+func (n *Negotiator) negotiate(ctx context.Context, p libp2ppeer.ID) {
+	ctx, cancel := context.WithTimeout(ctx, negotiateTimeout)
+	defer cancel()
+
+	s, err := n.host.NewStream(ctx, p, HelloProtocolID, ProtocolID)
+	if err != nil {
+		if ctx.Err() != nil || n.host.Network().Connectedness(p) != libp2pnetwork.Connected {
+			// Canceled, or the peer was disconnected, possibly for being on another chain.
+			return
+		}
+		// Most likely a peer from before version negotiation.
+		n.log.Debug("Failed to open version stream to peer; assuming legacy versions", "peer_id", p, "err", err)
+		n.record(p, Hello{Versions: legacyVersions()}, true)
+		return
+	}
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(negotiateTimeout))
+
+	hello := s.Protocol() == HelloProtocolID
+	var out any = n.local
+	if hello {
+		out = n.hello()
+	}
+	if err := json.NewEncoder(s).Encode(out); err != nil {
+		n.log.Debug("Failed to send versions to peer", "peer_id", p, "err", err)
+		return
+	}
+	_ = s.CloseWrite()
+
+	var remote Hello
+	dec := json.NewDecoder(io.LimitReader(s, 4*1024))
+	if hello {
+		err = dec.Decode(&remote)
+	} else {
+		err = dec.Decode(&remote.Versions)
+	}
+	if err != nil {
+		n.log.Debug("Failed to read versions from peer", "peer_id", p, "err", err)
+		return
+	}
+
+	n.record(p, remote, false)
+}
+
+func (n *Negotiator) hello() Hello {
+	return Hello{
+		Versions: n.local,
+
+		ChainID:     n.chainID,
+		GenesisHash: n.genesisHash,
+	}
+}
+
+func (n *Negotiator) handleHelloStream(s libp2pnetwork.Stream) {
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(negotiateTimeout))
+
+	var remote Hello
+	if err := json.NewDecoder(io.LimitReader(s, 4*1024)).Decode(&remote); err != nil {
+		return
+	}
+	if err := json.NewEncoder(s).Encode(n.hello()); err != nil {
+		return
+	}
+
+	n.record(s.Conn().RemotePeer(), remote, false)
+}
+
+func (n *Negotiator) handleStream(s libp2pnetwork.Stream) {
+	defer s.Close()
+	_ = s.SetDeadline(time.Now().Add(negotiateTimeout))
+
+	var remote Versions
+	if err := json.NewDecoder(io.LimitReader(s, 4*1024)).Decode(&remote); err != nil {
+		return
+	}
+	if err := json.NewEncoder(s).Encode(n.local); err != nil {
+		return
+	}
+
+	n.record(s.Conn().RemotePeer(), Hello{Versions: remote}, false)
+}
+
+// record stores the outcome of negotiating with p,
+// disconnecting p if it is on a different chain,
+// or if it is incompatible and n does not keep incompatible peers.
+func (n *Negotiator) record(p libp2ppeer.ID, remote Hello, legacy bool) {
+	if n.otherChain(remote) {
+		n.log.Warn(
+			"Disconnecting peer on a different chain",
+			"peer_id", p,
+			"peer_chain_id", remote.ChainID, "chain_id", n.chainID,
+			"peer_genesis_hash", glog.Hex(remote.GenesisHash), "genesis_hash", glog.Hex(n.genesisHash),
+		)
+		_ = n.host.Network().ClosePeer(p)
+		return
+	}
+
+	negotiated, incompatible := Negotiate(n.local, remote.Versions)
+
+	if len(incompatible) > 0 {
+		if !n.keepIncompatible {
+			n.log.Warn(
+				"Disconnecting peer with incompatible protocol versions",
+				"peer_id", p, "incompatible", incompatible, "legacy", legacy,
+			)
+			_ = n.host.Network().ClosePeer(p)
+			return
+		}
+
+		n.log.Warn(
+			"Peer has incompatible protocol versions; those protocols are disabled for the peer",
+			"peer_id", p, "incompatible", incompatible, "legacy", legacy,
+		)
+	}
+
+	n.mu.Lock()
+	n.peers[p] = PeerVersions{
+		Peer:         p.String(),
+		Negotiated:   negotiated,
+		Incompatible: incompatible,
+		Legacy:       legacy,
+		ChainID:      remote.ChainID,
+	}
+	n.mu.Unlock()
+}
+
+// otherChain reports whether remote identifies a chain other than n's.
+// A peer that did not report its chain, such as over [ProtocolID], is not on another chain.
+func (n *Negotiator) otherChain(remote Hello) bool {
+	if remote.ChainID != "" && n.chainID != "" && remote.ChainID != n.chainID {
+		return true
+	}
+	return len(remote.GenesisHash) > 0 && len(n.genesisHash) > 0 &&
+		!bytes.Equal(remote.GenesisHash, n.genesisHash)
+}
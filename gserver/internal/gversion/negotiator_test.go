@@ -0,0 +1,224 @@
+package gversion_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gversion"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p/tmlibp2ptest"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	local := gversion.Versions{
+		"a": {Min: 1, Max: 3},
+		"b": {Min: 2, Max: 2},
+		"c": {Min: 1, Max: 1},
+	}
+	remote := gversion.Versions{
+		"a": {Min: 2, Max: 5},
+		"b": {Min: 3, Max: 4},
+	}
+
+	got, incompatible := gversion.Negotiate(local, remote)
+	require.Equal(t, map[string]uint32{"a": 3, "b": 0, "c": 0}, got)
+	require.Equal(t, []string{"b", "c"}, incompatible)
+}
+
+func TestNegotiator(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	log := gtest.NewLogger(t)
+	net, err := tmlibp2ptest.NewNetwork(ctx, log.With("sys", "net"), tmjson.MarshalCodec{CryptoRegistry: reg})
+	require.NoError(t, err)
+	defer net.Wait()
+	defer cancel()
+
+	c1, err := net.Connect(ctx)
+	require.NoError(t, err)
+	c2, err := net.Connect(ctx)
+	require.NoError(t, err)
+	require.NoError(t, net.Stabilize(ctx))
+
+	// Node 2 is mid-upgrade: it prefers sync v3 but still supports v2,
+	// and its gossip protocol has moved past node 1's.
+	n1 := gversion.NewNegotiator(ctx, log.With("node", 1), gversion.NegotiatorConfig{
+		Host: c1.Host().Libp2pHost(),
+
+		KeepIncompatible: true,
+
+		ChainID:     "chain",
+		GenesisHash: []byte("genesis"),
+	})
+	defer n1.Wait()
+	defer cancel()
+
+	n2 := gversion.NewNegotiator(ctx, log.With("node", 2), gversion.NegotiatorConfig{
+		Host: c2.Host().Libp2pHost(),
+		Local: gversion.Versions{
+			gversion.ProtocolBlock:  {Min: 1, Max: 1},
+			gversion.ProtocolGossip: {Min: 2, Max: 2},
+			gversion.ProtocolSync:   {Min: 2, Max: 3},
+		},
+
+		KeepIncompatible: true,
+
+		ChainID:     "chain",
+		GenesisHash: []byte("genesis"),
+	})
+	defer n2.Wait()
+	defer cancel()
+
+	p1 := c1.Host().Libp2pHost().ID()
+	p2 := c2.Host().Libp2pHost().ID()
+	require.Eventually(t, func() bool {
+		_, ok1 := n1.Version(p2, gversion.ProtocolSync)
+		_, ok2 := n2.Version(p1, gversion.ProtocolSync)
+		return ok1 && ok2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	v, ok := n1.Version(p2, gversion.ProtocolSync)
+	require.True(t, ok)
+	require.Equal(t, uint32(2), v)
+
+	v, ok = n1.Version(p2, gversion.ProtocolGossip)
+	require.True(t, ok)
+	require.Zero(t, v)
+
+	v, ok = n2.Version(p1, gversion.ProtocolSync)
+	require.True(t, ok)
+	require.Equal(t, uint32(2), v)
+
+	var pv gversion.PeerVersions
+	for _, x := range n1.Peers() {
+		if x.Peer == p2.String() {
+			pv = x
+		}
+	}
+	require.Equal(t, []string{gversion.ProtocolGossip}, pv.Incompatible)
+	require.False(t, pv.Legacy)
+	require.Equal(t, "chain", pv.ChainID)
+
+	var nilN *gversion.Negotiator
+	_, ok = nilN.Version(p2, gversion.ProtocolSync)
+	require.False(t, ok)
+}
+
+func TestNegotiator_rejectIncompatible(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+
+	log := gtest.NewLogger(t)
+	net, err := tmlibp2ptest.NewNetwork(ctx, log.With("sys", "net"), tmjson.MarshalCodec{CryptoRegistry: reg})
+	require.NoError(t, err)
+	defer net.Wait()
+	defer cancel()
+
+	c1, err := net.Connect(ctx)
+	require.NoError(t, err)
+	c2, err := net.Connect(ctx)
+	require.NoError(t, err)
+	require.NoError(t, net.Stabilize(ctx))
+
+	n1 := gversion.NewNegotiator(ctx, log.With("node", 1), gversion.NegotiatorConfig{
+		Host: c1.Host().Libp2pHost(),
+	})
+	defer n1.Wait()
+	defer cancel()
+
+	n2 := gversion.NewNegotiator(ctx, log.With("node", 2), gversion.NegotiatorConfig{
+		Host: c2.Host().Libp2pHost(),
+		Local: gversion.Versions{
+			gversion.ProtocolBlock:  {Min: 2, Max: 2},
+			gversion.ProtocolGossip: {Min: 1, Max: 1},
+			gversion.ProtocolSync:   {Min: 1, Max: 2},
+		},
+	})
+	defer n2.Wait()
+	defer cancel()
+
+	h1 := c1.Host().Libp2pHost()
+	p2 := c2.Host().Libp2pHost().ID()
+	require.Eventually(t, func() bool {
+		return h1.Network().Connectedness(p2) != libp2pnetwork.Connected
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, ok := n1.Version(p2, gversion.ProtocolBlock)
+	require.False(t, ok)
+}
+
+func TestNegotiator_otherChain(t *testing.T) {
+	t.Parallel()
+
+	for name, cfg2 := range map[string]gversion.NegotiatorConfig{
+		"chain ID":     {ChainID: "other", GenesisHash: []byte("genesis")},
+		"genesis hash": {ChainID: "chain", GenesisHash: []byte("other")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			reg := new(gcrypto.Registry)
+			gcrypto.RegisterEd25519(reg)
+
+			log := gtest.NewLogger(t)
+			net, err := tmlibp2ptest.NewNetwork(ctx, log.With("sys", "net"), tmjson.MarshalCodec{CryptoRegistry: reg})
+			require.NoError(t, err)
+			defer net.Wait()
+			defer cancel()
+
+			c1, err := net.Connect(ctx)
+			require.NoError(t, err)
+			c2, err := net.Connect(ctx)
+			require.NoError(t, err)
+			require.NoError(t, net.Stabilize(ctx))
+
+			// Keeping incompatible peers does not keep peers on another chain.
+			n1 := gversion.NewNegotiator(ctx, log.With("node", 1), gversion.NegotiatorConfig{
+				Host: c1.Host().Libp2pHost(),
+
+				KeepIncompatible: true,
+
+				ChainID:     "chain",
+				GenesisHash: []byte("genesis"),
+			})
+			defer n1.Wait()
+			defer cancel()
+
+			cfg2.Host = c2.Host().Libp2pHost()
+			cfg2.KeepIncompatible = true
+			n2 := gversion.NewNegotiator(ctx, log.With("node", 2), cfg2)
+			defer n2.Wait()
+			defer cancel()
+
+			h1 := c1.Host().Libp2pHost()
+			p2 := c2.Host().Libp2pHost().ID()
+			require.Eventually(t, func() bool {
+				return h1.Network().Connectedness(p2) != libp2pnetwork.Connected
+			}, 5*time.Second, 10*time.Millisecond)
+
+			_, ok := n1.Version(p2, gversion.ProtocolBlock)
+			require.False(t, ok)
+		})
+	}
+}
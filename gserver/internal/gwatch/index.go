@@ -0,0 +1,230 @@
+// Package gwatch pushes changes to the balances and delegations
+// of watched addresses, as the driver finalizes blocks.
+//
+// The driver passes the events of every finalized block to an [Index],
+// which matches the address attributes of the bank and staking events
+// against the addresses of the current subscriptions.
+// The index holds no history: a subscriber only sees changes
+// in blocks finalized after it subscribed,
+// so it should read the current balances once subscribed.
+package gwatch
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// Kind is the kind of change to a watched address.
+type Kind string
+
+const (
+	// The address's balance changed,
+	// by a transfer, fee payment, mint or burn.
+	KindBalance Kind = "balance"
+
+	// A delegation from the address was created, moved, or removed.
+	KindDelegation Kind = "delegation"
+)
+
+// addressKeys maps the types of the events that change balances or delegations
+// to the attribute keys naming the affected addresses.
+var addressKeys = map[string]struct {
+	kind Kind
+	keys []string
+}{
+	"coin_spent":    {KindBalance, []string{"spender"}},
+	"coin_received": {KindBalance, []string{"receiver"}},
+	"transfer":      {KindBalance, []string{"sender", "recipient"}},
+	"burn":          {KindBalance, []string{"burner"}},
+	"coinbase":      {KindBalance, []string{"minter"}},
+
+	"delegate":                    {KindDelegation, []string{"delegator"}},
+	"unbond":                      {KindDelegation, []string{"delegator"}},
+	"redelegate":                  {KindDelegation, []string{"delegator"}},
+	"complete_unbonding":          {KindDelegation, []string{"delegator"}},
+	"complete_redelegation":       {KindDelegation, []string{"delegator"}},
+	"cancel_unbonding_delegation": {KindDelegation, []string{"delegator"}},
+}
+
+// SubscriptionBuffer is the number of changes buffered for each subscription.
+// A subscriber that falls further behind is unsubscribed,
+// rather than silently missing changes.
+const SubscriptionBuffer = 256
+
+// Attribute is a key-value attribute of an [Event].
+type Attribute struct {
+	Key, Value string
+}
+
+// Event is an event emitted by the app while finalizing a block.
+type Event struct {
+	Type       string
+	Attributes []Attribute
+}
+
+// Change is a change to a watched address in a finalized block.
+type Change struct {
+	Height  uint64
+	Address string
+	Kind    Kind
+
+	// The event reporting the change.
+	Event Event
+}
+
+// Index matches finalized block events against subscribed addresses.
+// It is safe for concurrent use,
+// and [Index.Observe] may be called on a nil Index.
+type Index struct {
+	log *slog.Logger
+
+	mu     sync.Mutex
+	byAddr map[string]map[*subscription]struct{}
+}
+
+type subscription struct {
+	ch     chan Change
+	addrs  []string
+	closed bool
+}
+
+// NewIndex returns a new Index with no subscriptions.
+func NewIndex(log *slog.Logger) *Index {
+	return &Index{
+		log:    log,
+		byAddr: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving every change to addrs
+// in blocks finalized from now on, until ctx is canceled,
+// at which point the channel is closed.
+// The channel is also closed if the subscriber falls more than
+// [SubscriptionBuffer] changes behind.
+func (x *Index) Subscribe(ctx context.Context, addrs []string) (<-chan Change, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("no addresses to watch")
+	}
+
+	s := &subscription{
+		ch:    make(chan Change, SubscriptionBuffer),
+		addrs: addrs,
+	}
+
+	x.mu.Lock()
+	for _, a := range addrs {
+		subs := x.byAddr[a]
+		if subs == nil {
+			subs = make(map[*subscription]struct{})
+			x.byAddr[a] = subs
+		}
+		subs[s] = struct{}{}
+	}
+	x.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		x.mu.Lock()
+		defer x.mu.Unlock()
+		x.unsubscribe(s)
+	})
+
+	return s.ch, nil
+}
+
+// unsubscribe removes s and closes its channel, if not done already.
+// It must be called with x.mu held.
+func (x *Index) unsubscribe(s *subscription) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+
+	for _, a := range s.addrs {
+		subs := x.byAddr[a]
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(x.byAddr, a)
+		}
+	}
+	close(s.ch)
+}
+
+// WatchedAddresses returns the number of distinct addresses
+// with at least one subscription.
+func (x *Index) WatchedAddresses() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return len(x.byAddr)
+}
+
+// Observe publishes the changes reported by the events of the block
+// finalized at height to the subscribers of the affected addresses.
+// It never blocks on a subscriber.
+func (x *Index) Observe(height uint64, events []Event) {
+	if x == nil {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if len(x.byAddr) == 0 {
+		return
+	}
+
+	for _, ev := range events {
+		ak, ok := addressKeys[ev.Type]
+		if !ok {
+			continue
+		}
+
+		// An event may name the same address twice,
+		// such as a transfer to oneself, but is only published once per address.
+		var seen map[string]struct{}
+		for _, attr := range ev.Attributes {
+			if !containsKey(ak.keys, attr.Key) {
+				continue
+			}
+			subs, ok := x.byAddr[attr.Value]
+			if !ok {
+				continue
+			}
+			if _, ok := seen[attr.Value]; ok {
+				continue
+			}
+			if seen == nil {
+				seen = make(map[string]struct{}, 1)
+			}
+			seen[attr.Value] = struct{}{}
+
+			c := Change{
+				Height:  height,
+				Address: attr.Value,
+				Kind:    ak.kind,
+				Event:   ev,
+			}
+			for s := range subs {
+				select {
+				case s.ch <- c:
+				default:
+					x.log.Info(
+						"Dropping watch subscription that fell behind",
+						"height", height, "addresses", len(s.addrs),
+					)
+					x.unsubscribe(s)
+				}
+			}
+		}
+	}
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
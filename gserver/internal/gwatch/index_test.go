@@ -0,0 +1,120 @@
+package gwatch_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatch"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func transfer(from, to string) gwatch.Event {
+	return gwatch.Event{
+		Type: "transfer",
+		Attributes: []gwatch.Attribute{
+			{Key: "recipient", Value: to},
+			{Key: "sender", Value: from},
+			{Key: "amount", Value: "100stake"},
+		},
+	}
+}
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	x := gwatch.NewIndex(gtest.NewLogger(t))
+
+	_, err := x.Subscribe(ctx, nil)
+	require.Error(t, err)
+
+	aliceCh, err := x.Subscribe(ctx, []string{"alice"})
+	require.NoError(t, err)
+	bothCtx, cancelBoth := context.WithCancel(ctx)
+	bothCh, err := x.Subscribe(bothCtx, []string{"alice", "bob"})
+	require.NoError(t, err)
+	require.Equal(t, 2, x.WatchedAddresses())
+
+	delegate := gwatch.Event{
+		Type: "delegate",
+		Attributes: []gwatch.Attribute{
+			{Key: "validator", Value: "alice"},
+			{Key: "delegator", Value: "bob"},
+		},
+	}
+	x.Observe(5, []gwatch.Event{
+		transfer("alice", "carol"),
+		{Type: "message", Attributes: []gwatch.Attribute{{Key: "sender", Value: "alice"}}},
+		delegate,
+	})
+
+	c := gtest.ReceiveSoon(t, aliceCh)
+	require.Equal(t, gwatch.Change{
+		Height: 5, Address: "alice", Kind: gwatch.KindBalance, Event: transfer("alice", "carol"),
+	}, c)
+	// The message event is not a change,
+	// and alice is only the validator of the delegation.
+	gtest.NotSending(t, aliceCh)
+
+	require.Equal(t, "alice", gtest.ReceiveSoon(t, bothCh).Address)
+	c = gtest.ReceiveSoon(t, bothCh)
+	require.Equal(t, "bob", c.Address)
+	require.Equal(t, gwatch.KindDelegation, c.Kind)
+	require.Equal(t, uint64(5), c.Height)
+
+	// A transfer to oneself is published once.
+	x.Observe(6, []gwatch.Event{transfer("alice", "alice")})
+	require.Equal(t, uint64(6), gtest.ReceiveSoon(t, aliceCh).Height)
+	gtest.NotSending(t, aliceCh)
+	gtest.ReceiveSoon(t, bothCh)
+
+	cancelBoth()
+	select {
+	case _, ok := <-bothCh:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription channel not closed after cancel")
+	}
+	require.Equal(t, 1, x.WatchedAddresses())
+}
+
+func TestIndex_slowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	x := gwatch.NewIndex(gtest.NewLogger(t))
+	ch, err := x.Subscribe(ctx, []string{"alice"})
+	require.NoError(t, err)
+
+	for h := range uint64(gwatch.SubscriptionBuffer + 1) {
+		x.Observe(h+1, []gwatch.Event{transfer("alice", "bob")})
+	}
+
+	// The buffered changes are delivered, and then the channel is closed.
+	for h := range uint64(gwatch.SubscriptionBuffer) {
+		require.Equal(t, h+1, gtest.ReceiveSoon(t, ch).Height)
+	}
+	select {
+	case _, ok := <-ch:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription channel not closed after overflow")
+	}
+	require.Zero(t, x.WatchedAddresses())
+
+	// Canceling afterwards is harmless.
+	cancel()
+}
+
+func TestIndex_nil(t *testing.T) {
+	t.Parallel()
+
+	var x *gwatch.Index
+	x.Observe(1, []gwatch.Event{transfer("alice", "bob")})
+}
@@ -0,0 +1,126 @@
+// Package gwatermark publishes the node's consensus watermarks:
+// the voting and committing height and round, and the finalized height.
+//
+// A [Writer] is fed by the subsystems that observe each value,
+// so that consumers such as the watermark HTTP endpoint
+// do not need to read the engine's internal stores.
+package gwatermark
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+)
+
+// Watermark is a snapshot of the node's heights.
+type Watermark struct {
+	VotingHeight uint64
+	VotingRound  uint32
+
+	CommittingHeight uint64
+	CommittingRound  uint32
+
+	// The highest height the driver has finalized.
+	FinalizedHeight uint64 `json:",omitempty"`
+}
+
+// Writer holds the latest [Watermark] and publishes changes to subscribers.
+// It is safe for concurrent use,
+// and its update methods may be called on a nil Writer.
+type Writer struct {
+	mu   sync.Mutex
+	cur  Watermark
+	subs map[chan Watermark]struct{}
+}
+
+// NewWriter returns a new Writer with a zero watermark.
+func NewWriter() *Writer {
+	return &Writer{
+		subs: make(map[chan Watermark]struct{}),
+	}
+}
+
+// UpdateView updates the voting and committing values
+// from the views present in u.
+func (w *Writer) UpdateView(u tmelink.NetworkViewUpdate) {
+	if w == nil || (u.Voting == nil && u.Committing == nil) {
+		return
+	}
+
+	w.update(func(wm *Watermark) {
+		if u.Voting != nil {
+			wm.VotingHeight = u.Voting.Height
+			wm.VotingRound = u.Voting.Round
+		}
+		if u.Committing != nil {
+			wm.CommittingHeight = u.Committing.Height
+			wm.CommittingRound = u.Committing.Round
+		}
+	})
+}
+
+// SetFinalized records that the given height has been finalized.
+// Heights lower than the current finalized height are ignored.
+func (w *Writer) SetFinalized(h uint64) {
+	if w == nil {
+		return
+	}
+
+	w.update(func(wm *Watermark) {
+		if h > wm.FinalizedHeight {
+			wm.FinalizedHeight = h
+		}
+	})
+}
+
+// Latest returns the current watermark.
+func (w *Writer) Latest() Watermark {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cur
+}
+
+// Subscribe returns a channel that receives the current watermark
+// and then every changed watermark, until ctx is canceled,
+// at which point the channel is closed.
+// A slow subscriber only misses intermediate values;
+// the channel always holds the latest watermark not yet received.
+func (w *Writer) Subscribe(ctx context.Context) <-chan Watermark {
+	ch := make(chan Watermark, 1)
+
+	w.mu.Lock()
+	ch <- w.cur
+	w.subs[ch] = struct{}{}
+	w.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subs, ch)
+		close(ch)
+	})
+
+	return ch
+}
+
+func (w *Writer) update(f func(*Watermark)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prev := w.cur
+	f(&w.cur)
+	if w.cur == prev {
+		return
+	}
+
+	for ch := range w.subs {
+		// The writer only sends while holding the lock,
+		// so after discarding an unread value, the send cannot block.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- w.cur
+	}
+}
@@ -0,0 +1,65 @@
+package gwatermark_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwatermark"
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmengine/tmelink"
+	"github.com/stretchr/testify/require"
+)
+
+func view(h uint64, r uint32) *tmconsensus.VersionedRoundView {
+	return &tmconsensus.VersionedRoundView{
+		RoundView: tmconsensus.RoundView{Height: h, Round: r},
+	}
+}
+
+func TestWriter_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := gwatermark.NewWriter()
+	ch := w.Subscribe(ctx)
+
+	require.Equal(t, gwatermark.Watermark{}, gtest.ReceiveSoon(t, ch))
+
+	w.UpdateView(tmelink.NetworkViewUpdate{Voting: view(3, 1), Committing: view(2, 0)})
+	w.SetFinalized(1)
+
+	// Only the latest value is retained for a slow subscriber.
+	want := gwatermark.Watermark{
+		VotingHeight: 3, VotingRound: 1,
+		CommittingHeight: 2,
+		FinalizedHeight:  1,
+	}
+	require.Equal(t, want, gtest.ReceiveSoon(t, ch))
+	require.Equal(t, want, w.Latest())
+
+	// Unchanged and stale values are not published.
+	w.SetFinalized(1)
+	w.SetFinalized(0)
+	w.UpdateView(tmelink.NetworkViewUpdate{})
+	gtest.NotSending(t, ch)
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed after context cancellation")
+	}
+}
+
+func TestWriter_nil(t *testing.T) {
+	t.Parallel()
+
+	var w *gwatermark.Writer
+	w.UpdateView(tmelink.NetworkViewUpdate{Voting: view(1, 0)})
+	w.SetFinalized(1)
+}
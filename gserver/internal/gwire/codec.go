@@ -0,0 +1,274 @@
+// Package gwire switches the encoding of gossiped consensus messages
+// at coordinated heights, without a flag day.
+//
+// Each encoding is identified by a version number.
+// The chain starts at version 1, the plain encoding of the inner codec,
+// and the genesis file may declare upgrades, each naming a version,
+// the height from which it applies, and a window of heights around that height:
+//
+//	"gordian": {
+//	  "wire_upgrades": [{"height": "1000", "version": 2, "window": "100"}]
+//	}
+//
+// A [Codec] encodes each message in the version in effect at the message's height.
+// It decodes messages in any known version,
+// but only accepts a message whose version is in effect at the message's height,
+// or, for heights within an upgrade's window,
+// the version that the upgrade replaces.
+// So nodes may disagree about how to encode messages near the upgrade height,
+// for instance because one node is still finishing an older height,
+// without dropping each other's messages.
+//
+// Only consensus messages are versioned.
+// Headers and proofs outside consensus messages,
+// such as those served to catching-up peers,
+// always use version 1.
+package gwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+)
+
+// frameMagic prefixes every message encoded in a version other than 1,
+// followed by the uvarint-encoded version.
+// The JSON consensus codec never produces a leading zero byte,
+// so version 1 messages need no frame,
+// and remain readable by nodes that predate this package.
+var frameMagic = []byte("\x00gcwire")
+
+// Versions are the encoding versions known to this binary.
+var Versions = []uint32{1}
+
+// Encodings returns the codec for each of [Versions],
+// given the codec for version 1.
+// Existing versions must never change their encoding,
+// or nodes would be unable to decode each other's messages.
+func Encodings(base tmcodec.MarshalCodec) map[uint32]tmcodec.MarshalCodec {
+	return map[uint32]tmcodec.MarshalCodec{
+		1: base,
+	}
+}
+
+// Upgrade declares that Version applies from Height onward.
+type Upgrade struct {
+	// Encoded as strings, like other heights in the genesis file.
+	Height uint64 `json:"height,string"`
+
+	Version uint32 `json:"version"`
+
+	// Messages for heights within Window of Height
+	// are also accepted in the previous version.
+	Window uint64 `json:"window,string"`
+}
+
+// Schedule maps heights to encoding versions.
+type Schedule struct {
+	upgrades []Upgrade
+}
+
+// NewSchedule returns a Schedule starting at version 1 and applying upgrades,
+// which must be in strictly increasing height order,
+// and which must only name versions in known.
+func NewSchedule(known []uint32, upgrades []Upgrade) (*Schedule, error) {
+	for i, u := range upgrades {
+		if !slices.Contains(known, u.Version) {
+			return nil, fmt.Errorf(
+				"wire upgrade at height %d names unknown version %d; this binary may be too old",
+				u.Height, u.Version,
+			)
+		}
+		if i > 0 && u.Height <= upgrades[i-1].Height {
+			return nil, fmt.Errorf(
+				"wire upgrade heights must be strictly increasing; got %d after %d",
+				u.Height, upgrades[i-1].Height,
+			)
+		}
+	}
+
+	return &Schedule{upgrades: slices.Clone(upgrades)}, nil
+}
+
+// LoadGenesisSchedule returns the Schedule declared in the genesis file at path,
+// using the known [Versions].
+// A genesis file without a gordian section yields version 1 at every height.
+func LoadGenesisSchedule(path string) (*Schedule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open genesis file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadGenesisSchedule(f)
+}
+
+// ReadGenesisSchedule is like [LoadGenesisSchedule] but reads the genesis JSON from r.
+func ReadGenesisSchedule(r io.Reader) (*Schedule, error) {
+	var g struct {
+		Gordian struct {
+			WireUpgrades []Upgrade `json:"wire_upgrades"`
+		} `json:"gordian"`
+	}
+	if err := json.NewDecoder(r).Decode(&g); err != nil {
+		return nil, fmt.Errorf("failed to parse wire upgrades from genesis: %w", err)
+	}
+
+	return NewSchedule(Versions, g.Gordian.WireUpgrades)
+}
+
+// Upgrades returns a copy of the upgrades in s, in increasing height order.
+func (s *Schedule) Upgrades() []Upgrade {
+	return slices.Clone(s.upgrades)
+}
+
+// VersionAt returns the encoding version in effect at height.
+func (s *Schedule) VersionAt(height uint64) uint32 {
+	v := uint32(1)
+	for _, u := range s.upgrades {
+		if u.Height > height {
+			break
+		}
+		v = u.Version
+	}
+	return v
+}
+
+// Accepts reports whether a message for height may be encoded in version v.
+func (s *Schedule) Accepts(height uint64, v uint32) bool {
+	if v == s.VersionAt(height) {
+		return true
+	}
+
+	prev := uint32(1)
+	for _, u := range s.upgrades {
+		inWindow := height+u.Window >= u.Height && height < u.Height+u.Window
+		if inWindow && (v == prev || v == u.Version) {
+			return true
+		}
+		prev = u.Version
+	}
+	return false
+}
+
+// WrongVersionError is returned when unmarshaling a consensus message
+// whose encoding version is not accepted at the message's height.
+type WrongVersionError struct {
+	Height  uint64
+	Version uint32
+
+	// The version in effect at Height.
+	Want uint32
+}
+
+func (e WrongVersionError) Error() string {
+	return fmt.Sprintf(
+		"consensus message for height %d encoded in wire version %d; want version %d",
+		e.Height, e.Version, e.Want,
+	)
+}
+
+// CodecConfig is the configuration for [NewCodec].
+type CodecConfig struct {
+	// The codec for each encoding version.
+	// Version 1 is required, and handles every value other than consensus messages.
+	Encodings map[uint32]tmcodec.MarshalCodec
+
+	Schedule *Schedule
+}
+
+// Codec is a [tmcodec.MarshalCodec] that encodes consensus messages
+// per the package documentation.
+type Codec struct {
+	tmcodec.MarshalCodec
+
+	encodings map[uint32]tmcodec.MarshalCodec
+	schedule  *Schedule
+}
+
+var _ tmcodec.MarshalCodec = (*Codec)(nil)
+
+// NewCodec returns a new Codec based on cfg.
+func NewCodec(cfg CodecConfig) (*Codec, error) {
+	base, ok := cfg.Encodings[1]
+	if !ok {
+		return nil, errors.New("wire version 1 is not defined")
+	}
+	for _, u := range cfg.Schedule.upgrades {
+		if _, ok := cfg.Encodings[u.Version]; !ok {
+			return nil, fmt.Errorf("no encoding for wire version %d", u.Version)
+		}
+	}
+
+	return &Codec{
+		MarshalCodec: base,
+
+		encodings: cfg.Encodings,
+		schedule:  cfg.Schedule,
+	}, nil
+}
+
+// MarshalConsensusMessage marshals cm in the version in effect at its height,
+// framing the result if the version is not 1.
+func (c *Codec) MarshalConsensusMessage(cm tmcodec.ConsensusMessage) ([]byte, error) {
+	v := c.schedule.VersionAt(messageHeight(cm))
+	b, err := c.encodings[v].MarshalConsensusMessage(cm)
+	if err != nil || v == 1 {
+		return b, err
+	}
+
+	out := make([]byte, 0, len(frameMagic)+binary.MaxVarintLen32+len(b))
+	out = append(out, frameMagic...)
+	out = binary.AppendUvarint(out, uint64(v))
+	return append(out, b...), nil
+}
+
+// UnmarshalConsensusMessage unmarshals b in the version named by its frame,
+// or version 1 if it is not framed,
+// and then returns a [WrongVersionError]
+// if that version is not accepted at the message's height.
+func (c *Codec) UnmarshalConsensusMessage(b []byte, cm *tmcodec.ConsensusMessage) error {
+	v := uint32(1)
+	if rest, ok := bytes.CutPrefix(b, frameMagic); ok {
+		n, sz := binary.Uvarint(rest)
+		if sz <= 0 || n == 0 || n > uint64(^uint32(0)) {
+			return errors.New("malformed wire version in frame")
+		}
+		v = uint32(n)
+		b = rest[sz:]
+	}
+
+	enc, ok := c.encodings[v]
+	if !ok {
+		return fmt.Errorf("unknown wire version %d", v)
+	}
+	if err := enc.UnmarshalConsensusMessage(b, cm); err != nil {
+		return err
+	}
+
+	h := messageHeight(*cm)
+	if !c.schedule.Accepts(h, v) {
+		return WrongVersionError{Height: h, Version: v, Want: c.schedule.VersionAt(h)}
+	}
+	return nil
+}
+
+func messageHeight(cm tmcodec.ConsensusMessage) uint64 {
+	switch {
+	case cm.ProposedHeader != nil:
+		return cm.ProposedHeader.Header.Height
+	case cm.PrevoteProof != nil:
+		return cm.PrevoteProof.Height
+	case cm.PrecommitProof != nil:
+		return cm.PrecommitProof.Height
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,117 @@
+package gwire_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gwire"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmcodec"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/stretchr/testify/require"
+)
+
+func prevote(h uint64) tmcodec.ConsensusMessage {
+	return tmcodec.ConsensusMessage{
+		PrevoteProof: &tmconsensus.PrevoteSparseProof{
+			Height:     h,
+			PubKeyHash: "keys",
+			Proofs:     map[string][]gcrypto.SparseSignature{},
+		},
+	}
+}
+
+func newCodecs(t *testing.T, upgrades []gwire.Upgrade) (v1Only, upgraded *gwire.Codec) {
+	t.Helper()
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+	base := tmjson.MarshalCodec{CryptoRegistry: reg}
+
+	// Version 2 reuses the JSON encoding;
+	// only the frame distinguishes it.
+	encodings := map[uint32]tmcodec.MarshalCodec{1: base, 2: base}
+
+	plain, err := gwire.NewSchedule([]uint32{1, 2}, nil)
+	require.NoError(t, err)
+	v1Only, err = gwire.NewCodec(gwire.CodecConfig{Encodings: encodings, Schedule: plain})
+	require.NoError(t, err)
+
+	sched, err := gwire.NewSchedule([]uint32{1, 2}, upgrades)
+	require.NoError(t, err)
+	upgraded, err = gwire.NewCodec(gwire.CodecConfig{Encodings: encodings, Schedule: sched})
+	require.NoError(t, err)
+
+	return v1Only, upgraded
+}
+
+func TestCodec_window(t *testing.T) {
+	t.Parallel()
+
+	old, c := newCodecs(t, []gwire.Upgrade{{Height: 100, Version: 2, Window: 10}})
+
+	// Before the upgrade height, messages are unframed and readable by old codecs.
+	b, err := c.MarshalConsensusMessage(prevote(99))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(b), "{"))
+
+	var cm tmcodec.ConsensusMessage
+	require.NoError(t, old.UnmarshalConsensusMessage(b, &cm))
+	require.Equal(t, uint64(99), cm.PrevoteProof.Height)
+
+	// From the upgrade height, messages use version 2.
+	b, err = c.MarshalConsensusMessage(prevote(100))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(b), "\x00gcwire\x02"))
+	require.NoError(t, c.UnmarshalConsensusMessage(b, &cm))
+	require.Equal(t, uint64(100), cm.PrevoteProof.Height)
+
+	// A version 2 message is not accepted for a height well before the upgrade.
+	b, err = old.MarshalConsensusMessage(prevote(50))
+	require.NoError(t, err)
+	err = c.UnmarshalConsensusMessage(append([]byte("\x00gcwire\x02"), b...), &cm)
+	require.ErrorAs(t, err, new(gwire.WrongVersionError))
+
+	// Version 1 messages are accepted within the window on either side of the upgrade height.
+	for _, h := range []uint64{90, 99, 100, 109} {
+		b, err := old.MarshalConsensusMessage(prevote(h))
+		require.NoError(t, err)
+		require.NoError(t, c.UnmarshalConsensusMessage(b, &cm), "height %d", h)
+	}
+
+	// But not after the window.
+	b, err = old.MarshalConsensusMessage(prevote(110))
+	require.NoError(t, err)
+	err = c.UnmarshalConsensusMessage(b, &cm)
+	var wrong gwire.WrongVersionError
+	require.ErrorAs(t, err, &wrong)
+	require.Equal(t, gwire.WrongVersionError{Height: 110, Version: 1, Want: 2}, wrong)
+}
+
+func TestNewSchedule_validation(t *testing.T) {
+	t.Parallel()
+
+	_, err := gwire.NewSchedule([]uint32{1}, []gwire.Upgrade{{Height: 10, Version: 2}})
+	require.ErrorContains(t, err, "unknown version 2")
+
+	_, err = gwire.NewSchedule([]uint32{1, 2, 3}, []gwire.Upgrade{
+		{Height: 10, Version: 2},
+		{Height: 10, Version: 3},
+	})
+	require.ErrorContains(t, err, "strictly increasing")
+}
+
+func TestReadGenesisSchedule(t *testing.T) {
+	t.Parallel()
+
+	s, err := gwire.ReadGenesisSchedule(strings.NewReader(`{"chain_id": "x"}`))
+	require.NoError(t, err)
+	require.Empty(t, s.Upgrades())
+	require.Equal(t, uint32(1), s.VersionAt(1000))
+
+	_, err = gwire.ReadGenesisSchedule(strings.NewReader(
+		`{"gordian": {"wire_upgrades": [{"height": "1000", "version": 9, "window": "10"}]}}`,
+	))
+	require.ErrorContains(t, err, "unknown version 9")
+}
@@ -0,0 +1,217 @@
+package gserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/gserver/internal/gpreflight"
+	"github.com/gordian-engine/gcosmos/gserver/internal/gsi"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/tm/tmstore"
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+)
+
+// How long validate-only mode may spend on any check reaching a store or the HSM.
+const validateTimeout = 10 * time.Second
+
+// runValidateOnly runs the validate-only checks against the state loaded by Init,
+// prints the report to standard output,
+// and releases everything Init opened.
+// If every check passes, it exits the process;
+// otherwise it returns an error so that the start command fails.
+func (c *Component) runValidateOnly(homeDir string, fileKey gcrypto.PubKey) error {
+	ctx, cancel := context.WithTimeout(c.rootCtx, validateTimeout)
+	defer cancel()
+
+	r := c.validate(ctx, homeDir, fileKey)
+	if err := r.WriteText(os.Stdout); err != nil {
+		c.log.Warn("Failed to write validation report", "err", err)
+	}
+
+	if err := c.Stop(ctx); err != nil {
+		c.log.Warn("Failed to release resources after validation", "err", err)
+	}
+
+	if n := r.Failures(); n > 0 {
+		return fmt.Errorf("%d validation checks failed", n)
+	}
+
+	// The start command otherwise keeps running until interrupted,
+	// even though nothing was started.
+	os.Exit(0)
+	return nil
+}
+
+// validate checks the consistency of the configuration, keys and stores loaded by Init.
+// Init has already rejected unparseable flags and unreadable files,
+// so these checks look for combinations that load but would misbehave.
+func (c *Component) validate(ctx context.Context, homeDir string, fileKey gcrypto.PubKey) *gpreflight.Report {
+	r := new(gpreflight.Report)
+
+	r.OK("config", "start flags parsed")
+	if c.httpLn != nil {
+		r.OK("http", "listening address %s available", c.httpLn.Addr())
+	}
+	if c.grpcLn != nil {
+		r.OK("grpc", "listening address %s available", c.grpcLn.Addr())
+	}
+
+	if c.chainID == "" {
+		r.Fail("genesis", "genesis file has no chain_id")
+	} else {
+		r.OK("genesis", "chain ID %q, genesis hash %X", c.chainID, c.genesisHash)
+	}
+
+	c.validateKeys(ctx, r, fileKey)
+	c.validateStores(ctx, r)
+
+	dataDir := filepath.Join(homeDir, "data")
+	if f, err := os.CreateTemp(dataDir, ".validate-*"); err != nil {
+		r.Fail("data_dir", "%s is not writable: %v", dataDir, err)
+	} else {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		r.OK("data_dir", "%s is writable", dataDir)
+	}
+
+	var nSeeds int
+	var badSeeds []string
+	for _, s := range strings.Split(c.seedAddrs, "\n") {
+		if s == "" {
+			continue
+		}
+		if _, err := libp2ppeer.AddrInfoFromString(s); err != nil {
+			badSeeds = append(badSeeds, s)
+			continue
+		}
+		nSeeds++
+	}
+	switch {
+	case len(badSeeds) > 0:
+		r.Fail("seeds", "invalid seed addresses: %s", strings.Join(badSeeds, ", "))
+	case nSeeds == 0:
+		r.Warn("seeds", "no seed addresses; the node relies on incoming connections to find peers")
+	default:
+		r.OK("seeds", "%d seed addresses", nSeeds)
+	}
+
+	r.OK("p2p_identity", "a new libp2p identity is generated at each start")
+
+	if c.roundHistory == nil {
+		r.OK("round_history", "disabled")
+	} else {
+		r.OK("round_history", "keeping the last %d heights", c.roundHistoryHeights)
+	}
+
+	return r
+}
+
+// validateRoundHistoryHeights parses the value of the round history heights flag.
+// Zero disables the history, so only negative and non-integer values are rejected,
+// before they can reach [gsi.NewRoundHistory].
+func validateRoundHistoryHeights(s string) (int, error) {
+	if s == "" {
+		return gsi.DefaultRoundHistoryHeights, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid value for %s: %q", roundHistoryHeightsFlag, s)
+	}
+	return n, nil
+}
+
+// validateKeys checks that the consensus key can sign.
+func (c *Component) validateKeys(ctx context.Context, r *gpreflight.Report, fileKey gcrypto.PubKey) {
+	if c.hsmSigner == nil {
+		r.OK("consensus_key", "priv_validator_key.json public key %X", fileKey.PubKeyBytes())
+		return
+	}
+
+	if err := c.hsmSigner.CheckHealth(); err != nil {
+		r.Fail("consensus_key", "HSM health check failed: %v", err)
+		return
+	}
+	if !c.hsmSigner.PubKey().Equal(fileKey) {
+		r.Warn(
+			"consensus_key",
+			"HSM public key %X differs from priv_validator_key.json public key %X; the HSM key is used",
+			c.hsmSigner.PubKey().PubKeyBytes(), fileKey.PubKeyBytes(),
+		)
+		return
+	}
+	r.OK("consensus_key", "HSM public key %X", c.hsmSigner.PubKey().PubKeyBytes())
+}
+
+// validateStores checks that the consensus stores agree with each other
+// and with the app state.
+func (c *Component) validateStores(ctx context.Context, r *gpreflight.Report) {
+	appVersion, _, err := c.app.Store().StateLatest()
+	if err != nil {
+		r.Fail("app_state", "failed to read latest app state: %v", err)
+		return
+	}
+
+	vh, vr, ch, cr, err := c.ms.NetworkHeightRound(ctx)
+	if errors.Is(err, tmstore.ErrStoreUninitialized) {
+		if appVersion > 0 {
+			r.Fail(
+				"app_state",
+				"app state is at version %d but the consensus stores are empty; were they removed?",
+				appVersion,
+			)
+			return
+		}
+		r.OK("stores", "empty; the chain will be initialized from genesis")
+		return
+	}
+	if err != nil {
+		r.Fail("stores", "failed to read network height and round: %v", err)
+		return
+	}
+	r.OK("stores", "voting %d/%d, committing %d/%d", vh, vr, ch, cr)
+
+	// The app finalizes a height only after it is committed,
+	// so app state beyond the committing height means
+	// the consensus stores were replaced or rolled back.
+	if appVersion > ch {
+		r.Fail(
+			"app_state",
+			"app state version %d is ahead of committing height %d; were the consensus stores reset?",
+			appVersion, ch,
+		)
+	} else {
+		r.OK("app_state", "version %d", appVersion)
+	}
+
+	if ch == 0 {
+		return
+	}
+
+	_, _, valSet, _, err := c.fs.LoadFinalizationByHeight(ctx, ch)
+	if err != nil {
+		r.Fail("validator_set", "failed to load finalization at committing height %d: %v", ch, err)
+		return
+	}
+	if c.signer == nil {
+		r.OK("validator_set", "%d validators; no consensus key configured", len(valSet.Validators))
+		return
+	}
+	pubKey := c.signer.PubKey()
+	for _, v := range valSet.Validators {
+		if v.PubKey.Equal(pubKey) {
+			r.OK("validator_set", "consensus key is a validator with power %d", v.Power)
+			return
+		}
+	}
+	r.Warn(
+		"validator_set",
+		"consensus key is not among the %d validators at committing height %d; the node will not vote",
+		len(valSet.Validators), ch,
+	)
+}
@@ -7,11 +7,11 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -235,8 +235,41 @@ type ChainAddresses struct {
 	// File containing the address of the seed node.
 	// Only populated for chains with multiple validators.
 	P2PSeedPath string
+
+	addrDir   string
+	seedAddrs string
+
+	// The running validators, so that individual validators
+	// may be stopped and restarted.
+	nodes []*validatorNode
 }
 
+// validatorNode is a single running validator started through [Chain.Start].
+type validatorNode struct {
+	httpAddrFile string
+
+	cancel context.CancelFunc
+
+	// Only set when runValidatorsAsProcesses is true.
+	proc *ProcessNode
+
+	done chan struct{}
+}
+
+// StopMode indicates how [ChainAddresses.StopValidator] stops a validator.
+type StopMode uint8
+
+const (
+	// StopGraceful allows the validator to shut down cleanly.
+	StopGraceful StopMode = iota
+
+	// StopKill kills the validator's process without a chance to shut down,
+	// simulating a crash.
+	// This is only possible when running validators as separate processes;
+	// otherwise it behaves the same as StopGraceful.
+	StopKill
+)
+
 func (c Chain) Start(t *testing.T, ctx context.Context, nVals int) ChainAddresses {
 	t.Helper()
 
@@ -289,39 +322,14 @@ func (c Chain) Start(t *testing.T, ctx context.Context, nVals int) ChainAddresse
 	}
 
 	// Now we start the validators.
-	httpAddrFiles := make([]string, nVals)
-
-	var wg sync.WaitGroup
-	wg.Add(nVals)
+	ca.addrDir = addrDir
+	ca.seedAddrs = seedAddrs
+	ca.nodes = make([]*validatorNode, nVals)
 	for i := range nVals {
-		httpAddrFiles[i] = filepath.Join(addrDir, fmt.Sprintf("http_addr_%d.txt", i))
-
-		go func(i int) {
-			defer wg.Done()
-
-			startCmd := []string{"start"}
-			if !gci.RunCometInsteadOfGordian {
-				// Then include the HTTP server flags.
-				startCmd = append(
-					startCmd,
-					"--g-http-addr", "127.0.0.1:0",
-					"--g-http-addr-file", httpAddrFiles[i],
-				)
-
-				if nVals > 1 {
-					// Only include the seed addresses when there are multiple validators.
-					startCmd = append(startCmd, "--g-seed-addrs", seedAddrs)
-				}
-
-				startCmd = append(startCmd, c.RootCmds[i].sqlitePathArgs()...)
-			}
-
-			_ = c.RootCmds[i].RunC(ctx, startCmd...)
-		}(i)
+		ca.nodes[i] = c.startValidator(t, ctx, ca, i)
 	}
-	t.Cleanup(wg.Wait)
 
-	// Now all the goroutines to start the servers should be running.
+	// Now all the validators should be starting.
 	// Gather their reported HTTP addresses.
 	for i := range nVals {
 		if gci.RunCometInsteadOfGordian {
@@ -329,33 +337,170 @@ func (c Chain) Start(t *testing.T, ctx context.Context, nVals int) ChainAddresse
 			break
 		}
 
-		var httpAddr string
-		deadline := time.Now().Add(5 * time.Second)
-		for time.Now().Before(deadline) {
-			a, err := os.ReadFile(httpAddrFiles[i])
-			if err != nil {
-				// Swallow the error and delay.
-				time.Sleep(25 * time.Millisecond)
-				continue
-			}
-			if !bytes.HasSuffix(a, []byte("\n")) {
-				// Very unlikely incomplete write/read.
-				time.Sleep(25 * time.Millisecond)
-				continue
+		ca.HTTP[i] = readHTTPAddrFile(t, ca.nodes[i].httpAddrFile)
+	}
+
+	return ca
+}
+
+// startValidator runs the start command for the validator at index idx,
+// using the addresses previously configured on ca.
+func (c Chain) startValidator(
+	t *testing.T, ctx context.Context, ca ChainAddresses, idx int,
+) *validatorNode {
+	t.Helper()
+
+	n := &validatorNode{
+		httpAddrFile: filepath.Join(ca.addrDir, fmt.Sprintf("http_addr_%d.txt", idx)),
+		done:         make(chan struct{}),
+	}
+
+	// In case this is a restart, ensure we don't read the stale address.
+	if err := os.Remove(n.httpAddrFile); err != nil && !os.IsNotExist(err) {
+		t.Fatalf("failed to remove old http address file: %v", err)
+	}
+
+	startCmd := []string{"start"}
+	if !gci.RunCometInsteadOfGordian {
+		// Then include the HTTP server flags.
+		startCmd = append(
+			startCmd,
+			"--g-http-addr", "127.0.0.1:0",
+			"--g-http-addr-file", n.httpAddrFile,
+		)
+
+		if ca.seedAddrs != "" {
+			// Only include the seed addresses when there are multiple validators.
+			startCmd = append(startCmd, "--g-seed-addrs", ca.seedAddrs)
+		}
+
+		startCmd = append(startCmd, c.RootCmds[idx].sqlitePathArgs()...)
+	}
+
+	ctx, n.cancel = context.WithCancel(ctx)
+
+	e := c.RootCmds[idx]
+	if runValidatorsAsProcesses {
+		n.proc = e.StartProcess(t, startCmd...)
+		go func() {
+			defer close(n.done)
+			select {
+			case <-ctx.Done():
+				_ = n.proc.Stop(10 * time.Second)
+			case <-n.proc.Done():
 			}
+		}()
+	} else {
+		go func() {
+			defer close(n.done)
+			_ = e.RunC(ctx, startCmd...)
+		}()
+	}
+	t.Cleanup(func() {
+		<-n.done
+	})
 
-			httpAddr = strings.TrimSuffix(string(a), "\n")
-			break
+	return n
+}
+
+// readHTTPAddrFile waits for the HTTP address to be written to path,
+// failing the test if it is not written in time.
+func readHTTPAddrFile(t *testing.T, path string) string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		a, err := os.ReadFile(path)
+		if err != nil {
+			// Swallow the error and delay.
+			time.Sleep(25 * time.Millisecond)
+			continue
+		}
+		if !bytes.HasSuffix(a, []byte("\n")) {
+			// Very unlikely incomplete write/read.
+			time.Sleep(25 * time.Millisecond)
+			continue
 		}
 
-		if httpAddr == "" {
-			t.Fatalf("did not read http address from %s in time", httpAddrFiles[i])
+		return strings.TrimSuffix(string(a), "\n")
+	}
+
+	t.Fatalf("did not read http address from %s in time", path)
+	return "" // Unreachable.
+}
+
+// StopValidator stops the validator at index idx,
+// blocking until it has finished running.
+func (ca ChainAddresses) StopValidator(t *testing.T, idx int, mode StopMode) {
+	t.Helper()
+
+	n := ca.nodes[idx]
+	if mode == StopKill {
+		if n.proc == nil {
+			t.Logf("Cannot kill in-process validator %d; stopping gracefully instead", idx)
+		} else {
+			n.proc.Kill()
 		}
+	}
+
+	n.cancel()
 
-		ca.HTTP[i] = httpAddr
+	select {
+	case <-n.done:
+	case <-time.After(15 * time.Second):
+		t.Fatalf("validator %d did not stop in time", idx)
 	}
+}
 
-	return ca
+// RestartValidator starts the previously stopped validator at index idx,
+// reusing its home directory and therefore its stored state.
+// The new HTTP address is set on ca and returned.
+func (c Chain) RestartValidator(
+	t *testing.T, ctx context.Context, ca ChainAddresses, idx int,
+) string {
+	t.Helper()
+
+	select {
+	case <-ca.nodes[idx].done:
+	default:
+		t.Fatalf("validator %d must be stopped before restarting", idx)
+	}
+
+	ca.nodes[idx] = c.startValidator(t, ctx, ca, idx)
+	if gci.RunCometInsteadOfGordian {
+		return ""
+	}
+
+	ca.HTTP[idx] = readHTTPAddrFile(t, ca.nodes[idx].httpAddrFile)
+	return ca.HTTP[idx]
+}
+
+// RequireCaughtUp fails the test unless the validator at index idx
+// reaches the highest voting height reported by the other running validators,
+// before the timeout.
+func (ca ChainAddresses) RequireCaughtUp(t *testing.T, timeout time.Duration, idx int) {
+	t.Helper()
+
+	var target uint64
+	for i, a := range ca.HTTP {
+		if i == idx {
+			continue
+		}
+		select {
+		case <-ca.nodes[i].done:
+			// Not running.
+			continue
+		default:
+		}
+
+		w, err := GetWatermark(a)
+		if err != nil {
+			t.Fatalf("failed to get watermark of validator %d: %v", i, err)
+		}
+		target = max(target, w.VotingHeight)
+	}
+
+	RequireVotingHeight(t, timeout, target, ca.HTTP[idx])
 }
 
 var lateNodeCounter int32
@@ -420,7 +565,7 @@ func AddLateNode(
 			startCmd = append(startCmd, e.sqlitePathArgs()...)
 		}
 
-		_ = e.RunC(ctx, startCmd...)
+		e.runStart(t, ctx, startCmd...)
 	}()
 	t.Cleanup(func() {
 		<-done
@@ -524,8 +669,170 @@ func (r RunResult) NoError(t *testing.T) {
 	require.NoErrorf(t, r.Err, "OUT: %s\n\nERR: %s", r.Stdout.String(), r.Stderr.String())
 }
 
+// SignedTx runs genArgs, which must generate an unsigned transaction with --generate-only,
+// and then signs the transaction offline as the from address,
+// returning the signed transaction's JSON encoding.
+func (e CmdEnv) SignedTx(
+	t *testing.T, chainID, from string, accountNumber, sequence int, genArgs ...string,
+) []byte {
+	t.Helper()
+
+	res := e.Run(genArgs...)
+	res.NoError(t)
+
+	msgPath := filepath.Join(t.TempDir(), "unsigned.msg")
+	require.NoError(t, os.WriteFile(msgPath, res.Stdout.Bytes(), 0o600))
+
+	res = e.Run(
+		"tx", "sign", msgPath,
+		"--offline",
+		"--chain-id", chainID,
+		fmt.Sprintf("--account-number=%d", accountNumber),
+		"--from", from,
+		fmt.Sprintf("--sequence=%d", sequence),
+	)
+	res.NoError(t)
+
+	return res.Stdout.Bytes()
+}
+
 // keyAddOutput is used for unmarshaling the output of the keys add command,
 // so that we can collect the bech32 address for the key that was added.
 type keyAddOutput struct {
 	Address string
 }
+
+// Watermark is the decoded response from a validator's /blocks/watermark endpoint.
+type Watermark struct {
+	VotingHeight uint64
+	VotingRound  uint32
+
+	CommittingHeight uint64
+	CommittingRound  uint32
+}
+
+// GetWatermark fetches the current watermark from the HTTP server at httpAddr.
+func GetWatermark(httpAddr string) (Watermark, error) {
+	resp, err := http.Get("http://" + httpAddr + "/blocks/watermark")
+	if err != nil {
+		return Watermark{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return Watermark{}, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, b)
+	}
+
+	var w Watermark
+	if err := json.NewDecoder(resp.Body).Decode(&w); err != nil {
+		return Watermark{}, fmt.Errorf("failed to decode watermark: %w", err)
+	}
+	return w, nil
+}
+
+// RequireNetworkCondition polls the watermark of every validator in httpAddrs
+// until pred reports true for the full set of watermarks,
+// and then returns that set.
+//
+// Errors fetching a watermark are tolerated until the timeout,
+// as a validator's HTTP server may not be serving yet.
+// If the timeout elapses first, the test fails
+// with the most recent watermark or error for every validator.
+func RequireNetworkCondition(
+	t *testing.T,
+	timeout time.Duration,
+	httpAddrs []string,
+	desc string,
+	pred func([]Watermark) bool,
+) []Watermark {
+	t.Helper()
+
+	ws := make([]Watermark, len(httpAddrs))
+	errs := make([]error, len(httpAddrs))
+
+	deadline := time.Now().Add(timeout)
+	for {
+		allOK := true
+		for i, a := range httpAddrs {
+			ws[i], errs[i] = GetWatermark(a)
+			if errs[i] != nil {
+				allOK = false
+			}
+		}
+
+		if allOK && pred(ws) {
+			return ws
+		}
+
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var dump strings.Builder
+	for i, a := range httpAddrs {
+		if errs[i] != nil {
+			fmt.Fprintf(&dump, "\n\tvalidator %d (%s): error: %v", i, a, errs[i])
+			continue
+		}
+		fmt.Fprintf(
+			&dump, "\n\tvalidator %d (%s): voting=%d/%d committing=%d/%d",
+			i, a,
+			ws[i].VotingHeight, ws[i].VotingRound,
+			ws[i].CommittingHeight, ws[i].CommittingRound,
+		)
+	}
+	t.Fatalf("network did not satisfy %q within %s; last observed:%s", desc, timeout, dump.String())
+	return nil // Unreachable.
+}
+
+// RequireVotingHeight fails the test unless every validator in httpAddrs
+// reports a voting height of at least minHeight before the timeout.
+func RequireVotingHeight(
+	t *testing.T,
+	timeout time.Duration,
+	minHeight uint64,
+	httpAddrs ...string,
+) []Watermark {
+	t.Helper()
+
+	return RequireNetworkCondition(
+		t, timeout, httpAddrs,
+		fmt.Sprintf("voting height >= %d", minHeight),
+		func(ws []Watermark) bool {
+			for _, w := range ws {
+				if w.VotingHeight < minHeight {
+					return false
+				}
+			}
+			return true
+		},
+	)
+}
+
+// RequireSameCommittingHeight fails the test unless every validator in httpAddrs
+// simultaneously reports the same committing height, of at least minHeight,
+// before the timeout.
+func RequireSameCommittingHeight(
+	t *testing.T,
+	timeout time.Duration,
+	minHeight uint64,
+	httpAddrs ...string,
+) []Watermark {
+	t.Helper()
+
+	return RequireNetworkCondition(
+		t, timeout, httpAddrs,
+		fmt.Sprintf("same committing height >= %d", minHeight),
+		func(ws []Watermark) bool {
+			for _, w := range ws {
+				if w.CommittingHeight < minHeight || w.CommittingHeight != ws[0].CommittingHeight {
+					return false
+				}
+			}
+			return true
+		},
+	)
+}
@@ -0,0 +1,254 @@
+// Package gapptest contains a scripted fake app for tests
+// that run the engine or driver without the Cosmos SDK.
+//
+// An [App] answers the engine's init chain and finalize block requests
+// with deterministic app state hashes,
+// and can be scripted to change validators, delay responses,
+// or fail at particular heights.
+// Tests then assert on the finalizations it recorded
+// instead of handling the request channels themselves.
+package gapptest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmdriver"
+)
+
+// InitialAppHash is the app state hash an [App] reports when initializing the chain.
+var InitialAppHash = AppHash(nil, 0, nil)
+
+// AppHash returns the app state hash an [App] reports
+// after finalizing the block with blockHash at height,
+// given the previous app state hash.
+func AppHash(prev []byte, height uint64, blockHash []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write(prev)
+	_, _ = h.Write(binary.BigEndian.AppendUint64(nil, height))
+	_, _ = h.Write(blockHash)
+	return h.Sum(nil)
+}
+
+// AppConfig is the configuration for [NewApp].
+type AppConfig struct {
+	InitChainRequests     <-chan tmdriver.InitChainRequest
+	FinalizeBlockRequests <-chan tmdriver.FinalizeBlockRequest
+
+	// The validators the app reports after finalizing each height;
+	// each entry applies until the next one.
+	// Before the first entry, the genesis validators are reported.
+	ValidatorUpdates map[uint64][]tmconsensus.Validator
+
+	// Optional delay before responding to the finalization of each height.
+	Latency func(height uint64) time.Duration
+
+	// Errors to fail with when finalizing each height.
+	// On failure, the app does not respond to the request and stops,
+	// like a driver whose app failed to finalize a block.
+	Errors map[uint64]error
+}
+
+// App is a fake app per the package documentation.
+type App struct {
+	log *slog.Logger
+
+	valUpdates map[uint64][]tmconsensus.Validator
+	latency    func(uint64) time.Duration
+	errs       map[uint64]error
+
+	mu        sync.Mutex
+	finalized []tmdriver.FinalizeBlockResponse
+	err       error
+	changed   chan struct{} // Closed and replaced on every finalization.
+
+	done chan struct{}
+}
+
+// NewApp returns a new App based on cfg,
+// which handles requests until ctx is canceled or a scripted error occurs.
+func NewApp(ctx context.Context, log *slog.Logger, cfg AppConfig) *App {
+	a := &App{
+		log: log,
+
+		valUpdates: cfg.ValidatorUpdates,
+		latency:    cfg.Latency,
+		errs:       cfg.Errors,
+
+		changed: make(chan struct{}),
+
+		done: make(chan struct{}),
+	}
+
+	go a.kernel(ctx, cfg.InitChainRequests, cfg.FinalizeBlockRequests)
+
+	return a
+}
+
+// Wait blocks until a has stopped.
+func (a *App) Wait() {
+	<-a.done
+}
+
+// Done returns a channel that is closed once a has stopped.
+func (a *App) Done() <-chan struct{} {
+	return a.done
+}
+
+// Err returns the scripted error the app stopped with, if any.
+func (a *App) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Finalized returns a copy of every finalization response sent so far, in order.
+func (a *App) Finalized() []tmdriver.FinalizeBlockResponse {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.finalized)
+}
+
+// RequireFinalized waits briefly for the app to finalize height,
+// and returns the corresponding response.
+// If the height is not finalized in time, tb.Fatalf is called.
+func (a *App) RequireFinalized(tb gtest.TestingFatalHelper, height uint64) tmdriver.FinalizeBlockResponse {
+	tb.Helper()
+
+	timer := time.NewTimer(time.Duration(gtest.ScaleMs(100)))
+	defer timer.Stop()
+
+	for {
+		a.mu.Lock()
+		changed := a.changed
+		for _, resp := range a.finalized {
+			if resp.Height == height {
+				a.mu.Unlock()
+				return resp
+			}
+		}
+		n := len(a.finalized)
+		a.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			tb.Fatalf("height %d not finalized; %d heights finalized", height, n)
+			panic("unreachable")
+		}
+	}
+}
+
+// WaitFinalized blocks until the app has finalized height,
+// returning the cause of ctx if ctx is done first.
+func (a *App) WaitFinalized(ctx context.Context, height uint64) error {
+	for {
+		a.mu.Lock()
+		changed := a.changed
+		for _, resp := range a.finalized {
+			if resp.Height == height {
+				a.mu.Unlock()
+				return nil
+			}
+		}
+		a.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+func (a *App) kernel(
+	ctx context.Context,
+	initChainRequests <-chan tmdriver.InitChainRequest,
+	finalizeBlockRequests <-chan tmdriver.FinalizeBlockRequest,
+) {
+	defer close(a.done)
+
+	var vals []tmconsensus.Validator
+
+	// Assume we always need to initialize the chain at startup.
+	select {
+	case <-ctx.Done():
+		a.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+		return
+
+	case req := <-initChainRequests:
+		vals = req.Genesis.GenesisValidatorSet.Validators
+
+		select {
+		case req.Resp <- tmdriver.InitChainResponse{AppStateHash: InitialAppHash}:
+			// Okay.
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	prevHash := InitialAppHash
+	for {
+		select {
+		case <-ctx.Done():
+			a.log.Info("Stopping due to context cancellation", "cause", context.Cause(ctx))
+			return
+
+		case req := <-finalizeBlockRequests:
+			h := req.Header.Height
+
+			if a.latency != nil {
+				if d := a.latency(h); d > 0 {
+					t := time.NewTimer(d)
+					select {
+					case <-ctx.Done():
+						t.Stop()
+						return
+					case <-t.C:
+					}
+				}
+			}
+
+			if err := a.errs[h]; err != nil {
+				a.log.Info("Failing finalization as scripted", "height", h, "err", err)
+				a.mu.Lock()
+				a.err = fmt.Errorf("failed to finalize height %d: %w", h, err)
+				a.mu.Unlock()
+				return
+			}
+
+			if u, ok := a.valUpdates[h]; ok {
+				vals = u
+			}
+
+			appHash := AppHash(prevHash, h, req.Header.Hash)
+			resp := tmdriver.FinalizeBlockResponse{
+				Height:    h,
+				Round:     req.Round,
+				BlockHash: req.Header.Hash,
+
+				Validators: slices.Clone(vals),
+
+				AppStateHash: appHash,
+			}
+			prevHash = appHash
+
+			// The response channel is guaranteed to be 1-buffered.
+			req.Resp <- resp
+
+			a.mu.Lock()
+			a.finalized = append(a.finalized, resp)
+			close(a.changed)
+			a.changed = make(chan struct{})
+			a.mu.Unlock()
+		}
+	}
+}
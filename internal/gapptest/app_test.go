@@ -0,0 +1,94 @@
+package gapptest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gcosmos/internal/gapptest"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmdriver"
+	"github.com/stretchr/testify/require"
+)
+
+func finalize(t *testing.T, ch chan<- tmdriver.FinalizeBlockRequest, h uint64) chan tmdriver.FinalizeBlockResponse {
+	t.Helper()
+
+	resp := make(chan tmdriver.FinalizeBlockResponse, 1)
+	gtest.SendSoon(t, ch, tmdriver.FinalizeBlockRequest{
+		Header: tmconsensus.Header{Height: h, Hash: []byte{byte(h)}},
+		Resp:   resp,
+	})
+	return resp
+}
+
+func TestApp(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fx := tmconsensustest.NewStandardFixture(3)
+	vals := fx.Vals()
+
+	initCh := make(chan tmdriver.InitChainRequest)
+	finCh := make(chan tmdriver.FinalizeBlockRequest)
+
+	boom := errors.New("boom")
+	a := gapptest.NewApp(ctx, gtest.NewLogger(t), gapptest.AppConfig{
+		InitChainRequests:     initCh,
+		FinalizeBlockRequests: finCh,
+
+		ValidatorUpdates: map[uint64][]tmconsensus.Validator{
+			2: vals[:2],
+		},
+		Latency: func(h uint64) time.Duration {
+			if h == 3 {
+				return 20 * time.Millisecond
+			}
+			return 0
+		},
+		Errors: map[uint64]error{4: boom},
+	})
+	defer a.Wait()
+	defer cancel()
+
+	initResp := make(chan tmdriver.InitChainResponse, 1)
+	g := fx.DefaultGenesis()
+	gtest.SendSoon(t, initCh, tmdriver.InitChainRequest{
+		Genesis: tmconsensus.ExternalGenesis{GenesisValidatorSet: g.ValidatorSet},
+		Resp:    initResp,
+	})
+	require.Equal(t, gapptest.InitialAppHash, gtest.ReceiveSoon(t, initResp).AppStateHash)
+
+	// The genesis validators apply until the first update.
+	resp := gtest.ReceiveSoon(t, finalize(t, finCh, 1))
+	require.Equal(t, uint64(1), resp.Height)
+	require.Equal(t, vals, resp.Validators)
+	h1 := gapptest.AppHash(gapptest.InitialAppHash, 1, []byte{1})
+	require.Equal(t, h1, resp.AppStateHash)
+
+	resp = gtest.ReceiveSoon(t, finalize(t, finCh, 2))
+	require.Equal(t, vals[:2], resp.Validators)
+	require.Equal(t, gapptest.AppHash(h1, 2, []byte{2}), resp.AppStateHash)
+
+	// The delayed height is only finalized after its latency.
+	start := time.Now()
+	respCh := finalize(t, finCh, 3)
+	resp = a.RequireFinalized(t, 3)
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	require.Equal(t, vals[:2], resp.Validators)
+	_ = gtest.ReceiveSoon(t, respCh)
+
+	require.Len(t, a.Finalized(), 3)
+	require.NoError(t, a.WaitFinalized(ctx, 2))
+
+	// The scripted error stops the app without a response.
+	respCh = finalize(t, finCh, 4)
+	_ = gtest.ReceiveSoon(t, a.Done())
+	gtest.NotSending(t, respCh)
+	require.ErrorIs(t, a.Err(), boom)
+}
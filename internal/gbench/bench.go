@@ -0,0 +1,324 @@
+// Package gbench measures how quickly the consensus engine commits blocks,
+// with in-process validators running a no-op app over a loopback libp2p network.
+//
+// Because the app does no work and the network never leaves the machine,
+// the results mostly track the engine's own overhead,
+// so they are comparable across releases on the same machine.
+// The package's benchmark reports them through the testing package:
+//
+//	go test -run '^$' -bench . ./internal/gbench
+package gbench
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gcosmos/internal/gapptest"
+	"github.com/gordian-engine/gordian/gassert/gasserttest"
+	"github.com/gordian-engine/gordian/gcrypto"
+	"github.com/gordian-engine/gordian/gwatchdog"
+	"github.com/gordian-engine/gordian/tm/tmcodec/tmjson"
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmconsensus/tmconsensustest"
+	"github.com/gordian-engine/gordian/tm/tmdriver"
+	"github.com/gordian-engine/gordian/tm/tmengine"
+	"github.com/gordian-engine/gordian/tm/tmgossip"
+	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p"
+	"github.com/gordian-engine/gordian/tm/tmp2p/tmlibp2p/tmlibp2ptest"
+	"github.com/gordian-engine/gordian/tm/tmstore/tmmemstore"
+)
+
+// DefaultTimeouts are the engine timeouts used when [Config.Timeouts] is zero.
+// The proposal timeout only elapses if a round fails,
+// and the commit wait is as short as the strategy allows,
+// so that heights advance as soon as they are committed.
+var DefaultTimeouts = tmengine.LinearTimeoutStrategy{
+	ProposalBase: 500 * time.Millisecond,
+
+	PrevoteDelayBase:   100 * time.Millisecond,
+	PrecommitDelayBase: 100 * time.Millisecond,
+
+	// Zero would mean the strategy's 2s default.
+	CommitWaitBase: time.Nanosecond,
+}
+
+// Config is the configuration for [Run].
+type Config struct {
+	// Number of validators, each with equal power.
+	// Defaults to 4 if zero.
+	Validators int
+
+	// Number of heights to measure.
+	// The first height is committed before measurement begins,
+	// so that engine startup is excluded.
+	Heights uint64
+
+	// Engine timeouts.
+	// Defaults to DefaultTimeouts if zero.
+	Timeouts tmengine.LinearTimeoutStrategy
+}
+
+// Result is the outcome of [Run].
+type Result struct {
+	// Number of heights measured, and how long they took to commit.
+	Heights uint64
+	Elapsed time.Duration
+
+	// Heights committed per second.
+	BlocksPerSecond float64
+
+	// Mean time, as observed by the first validator, spent
+	// from entering the committed round until choosing the proposed block (Propose),
+	// from then until reaching a prevote majority (Prevote),
+	// and from then until the block was sent to the app for finalization (Commit).
+	Propose, Prevote, Commit time.Duration
+
+	// Number of measured heights committed in a round later than zero.
+	// A nonzero value means timeouts were involved,
+	// so the other results do not reflect only the engine's speed.
+	LateRounds int
+}
+
+// Run starts an in-memory network per cfg and lets consensus run
+// until cfg.Heights heights are committed after the first,
+// then stops the network and reports how it performed.
+func Run(ctx context.Context, log *slog.Logger, cfg Config) (Result, error) {
+	if cfg.Heights == 0 {
+		return Result{}, errors.New("gbench: no heights to measure")
+	}
+	if cfg.Validators == 0 {
+		cfg.Validators = 4
+	}
+	if cfg.Timeouts == (tmengine.LinearTimeoutStrategy{}) {
+		cfg.Timeouts = DefaultTimeouts
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Every background component registers its Wait here,
+	// so that nothing outlives Run.
+	var waits []func()
+	defer func() {
+		cancel()
+		for i := len(waits) - 1; i >= 0; i-- {
+			waits[i]()
+		}
+	}()
+
+	reg := new(gcrypto.Registry)
+	gcrypto.RegisterEd25519(reg)
+	n, err := tmlibp2ptest.NewNetwork(ctx, log.With("sys", "network"), tmjson.MarshalCodec{
+		CryptoRegistry: reg,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create network: %w", err)
+	}
+	waits = append(waits, n.Wait)
+
+	fx := tmconsensustest.NewStandardFixture(cfg.Validators)
+	genesis := fx.DefaultGenesis()
+
+	conns := make([]*tmlibp2p.Connection, cfg.Validators)
+	for i := range conns {
+		conn, err := n.Connect(ctx)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to connect validator %d: %w", i, err)
+		}
+		conns[i] = conn
+	}
+	if err := n.Stabilize(ctx); err != nil {
+		return Result{}, fmt.Errorf("failed to stabilize network: %w", err)
+	}
+
+	// Only the first validator is timed.
+	timer := newPhaseTimer(cfg.Heights + 1)
+
+	apps := make([]*gapptest.App, cfg.Validators)
+
+	for i, v := range fx.PrivVals {
+		vlog := log.With("idx", i)
+
+		var cs tmconsensus.ConsensusStrategy = &noopConsensusStrategy{
+			pubKey:     v.CVal.PubKey,
+			lastHeight: timer.lastHeight,
+		}
+
+		initChainCh := make(chan tmdriver.InitChainRequest)
+		engineFinCh := make(chan tmdriver.FinalizeBlockRequest)
+		appFinCh := engineFinCh
+		if i == 0 {
+			cs = timedConsensusStrategy{inner: cs, t: timer}
+
+			appFinCh = make(chan tmdriver.FinalizeBlockRequest)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				timer.relayFinalizations(ctx, engineFinCh, appFinCh)
+			}()
+			waits = append(waits, func() { <-done })
+		}
+
+		app := gapptest.NewApp(ctx, vlog.With("sys", "app"), gapptest.AppConfig{
+			InitChainRequests:     initChainCh,
+			FinalizeBlockRequests: appFinCh,
+		})
+		waits = append(waits, app.Wait)
+		apps[i] = app
+
+		wd, wCtx := gwatchdog.NewWatchdog(ctx, vlog.With("sys", "watchdog"))
+		waits = append(waits, wd.Wait)
+
+		sigScheme := tmconsensustest.SimpleSignatureScheme{}
+		e, err := tmengine.New(
+			wCtx,
+			vlog.With("sys", "engine"),
+			tmengine.WithActionStore(tmmemstore.NewActionStore()),
+			tmengine.WithCommittedHeaderStore(tmmemstore.NewCommittedHeaderStore()),
+			tmengine.WithFinalizationStore(tmmemstore.NewFinalizationStore()),
+			tmengine.WithMirrorStore(tmmemstore.NewMirrorStore()),
+			tmengine.WithRoundStore(tmmemstore.NewRoundStore()),
+			tmengine.WithStateMachineStore(tmmemstore.NewStateMachineStore()),
+			tmengine.WithValidatorStore(tmmemstore.NewValidatorStore(fx.HashScheme)),
+
+			tmengine.WithHashScheme(fx.HashScheme),
+			tmengine.WithSignatureScheme(sigScheme),
+			tmengine.WithCommonMessageSignatureProofScheme(gcrypto.SimpleCommonMessageSignatureProofScheme),
+
+			tmengine.WithGossipStrategy(tmgossip.NewChattyStrategy(
+				ctx, vlog.With("sys", "gossip"), conns[i].ConsensusBroadcaster(),
+			)),
+			tmengine.WithConsensusStrategy(cs),
+
+			tmengine.WithGenesis(&tmconsensus.ExternalGenesis{
+				ChainID:             genesis.ChainID,
+				InitialHeight:       genesis.InitialHeight,
+				InitialAppState:     strings.NewReader(""), // The no-op app has no state.
+				GenesisValidatorSet: fx.ValSet(),
+			}),
+
+			tmengine.WithTimeoutStrategy(ctx, cfg.Timeouts),
+
+			tmengine.WithBlockFinalizationChannel(engineFinCh),
+			tmengine.WithInitChainChannel(initChainCh),
+
+			tmengine.WithSigner(tmconsensus.PassthroughSigner{
+				Signer:          v.Signer,
+				SignatureScheme: sigScheme,
+			}),
+
+			tmengine.WithWatchdog(wd),
+
+			tmengine.WithAssertEnv(gasserttest.DefaultEnv()),
+		)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to create engine for validator %d: %w", i, err)
+		}
+		waits = append(waits, e.Wait)
+
+		conns[i].SetConsensusHandler(ctx, tmconsensus.AcceptAllValidFeedbackMapper{
+			Handler: e,
+		})
+	}
+
+	select {
+	case <-ctx.Done():
+		return Result{}, context.Cause(ctx)
+	case <-timer.done:
+	}
+
+	// The engine may panic if it is stopped while handling a new proposed block,
+	// so wait for every validator to finish the last proposed height before stopping.
+	for i, app := range apps {
+		if err := app.WaitFinalized(ctx, timer.lastHeight); err != nil {
+			return Result{}, fmt.Errorf("validator %d did not finalize the last height: %w", i, err)
+		}
+	}
+
+	return timer.result(), nil
+}
+
+// noopConsensusStrategy proposes empty blocks in round-robin order,
+// up to and including lastHeight,
+// and votes for any block from the expected proposer.
+type noopConsensusStrategy struct {
+	pubKey     gcrypto.PubKey
+	lastHeight uint64
+
+	mu          sync.Mutex
+	curH        uint64
+	curR        uint32
+	expProposer gcrypto.PubKey
+}
+
+// dataID is the data ID of the empty block at the given height and round.
+func dataID(h uint64, r uint32) string {
+	id := sha256.Sum256(fmt.Appendf(nil, "%d/%d", h, r))
+	return string(id[:])
+}
+
+func (s *noopConsensusStrategy) EnterRound(
+	ctx context.Context, rv tmconsensus.RoundView, proposalOut chan<- tmconsensus.Proposal,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.curH, s.curR = rv.Height, rv.Round
+	vals := rv.ValidatorSet.Validators
+	s.expProposer = vals[(int(rv.Height)+int(rv.Round))%len(vals)].PubKey
+
+	if rv.Height <= s.lastHeight && s.expProposer.Equal(s.pubKey) {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case proposalOut <- tmconsensus.Proposal{DataID: dataID(rv.Height, rv.Round)}:
+		}
+	}
+
+	return nil
+}
+
+func (s *noopConsensusStrategy) ConsiderProposedBlocks(
+	ctx context.Context, phs []tmconsensus.ProposedHeader, _ tmconsensus.ConsiderProposedBlocksReason,
+) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ph := range phs {
+		if ph.Header.Height != s.curH || ph.Round != s.curR {
+			continue
+		}
+		if s.expProposer.Equal(ph.ProposerPubKey) {
+			return string(ph.Header.Hash), nil
+		}
+	}
+
+	return "", tmconsensus.ErrProposedBlockChoiceNotReady
+}
+
+func (s *noopConsensusStrategy) ChooseProposedBlock(
+	ctx context.Context, phs []tmconsensus.ProposedHeader,
+) (string, error) {
+	hash, err := s.ConsiderProposedBlocks(ctx, phs, tmconsensus.ConsiderProposedBlocksReason{})
+	if err == tmconsensus.ErrProposedBlockChoiceNotReady {
+		// Prevote nil if the proposal never arrived.
+		return "", nil
+	}
+	return hash, err
+}
+
+func (s *noopConsensusStrategy) DecidePrecommit(
+	ctx context.Context, vs tmconsensus.VoteSummary,
+) (string, error) {
+	maj := tmconsensus.ByzantineMajority(vs.AvailablePower)
+	if pow := vs.PrevoteBlockPower[vs.MostVotedPrevoteHash]; pow >= maj {
+		return vs.MostVotedPrevoteHash, nil
+	}
+	return "", nil
+}
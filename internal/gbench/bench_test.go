@@ -0,0 +1,54 @@
+package gbench_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
+	"github.com/gordian-engine/gcosmos/internal/gbench"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	res, err := gbench.Run(context.Background(), gtest.NewLogger(t), gbench.Config{
+		Validators: 4,
+		Heights:    5,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(5), res.Heights)
+	require.Positive(t, res.Elapsed)
+	require.Positive(t, res.BlocksPerSecond)
+
+	require.Positive(t, res.Propose)
+	require.Positive(t, res.Prevote)
+	require.Positive(t, res.Commit)
+}
+
+func BenchmarkRun(b *testing.B) {
+	for _, n := range []int{4, 8} {
+		b.Run(fmt.Sprintf("validators=%d", n), func(b *testing.B) {
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			b.ResetTimer()
+			res, err := gbench.Run(context.Background(), log, gbench.Config{
+				Validators: n,
+				Heights:    uint64(b.N),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportMetric(res.BlocksPerSecond, "blocks/s")
+			b.ReportMetric(float64(res.Propose.Microseconds()), "propose-µs")
+			b.ReportMetric(float64(res.Prevote.Microseconds()), "prevote-µs")
+			b.ReportMetric(float64(res.Commit.Microseconds()), "commit-µs")
+			b.ReportMetric(float64(res.LateRounds), "late-rounds")
+		})
+	}
+}
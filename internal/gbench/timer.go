@@ -0,0 +1,190 @@
+package gbench
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gordian-engine/gordian/tm/tmconsensus"
+	"github.com/gordian-engine/gordian/tm/tmdriver"
+)
+
+// roundTimes are the phase boundaries of the latest round entered at one height.
+type roundTimes struct {
+	round uint32
+
+	entered, chosen, majority time.Time
+}
+
+// phaseTimer collects phase boundaries from a timedConsensusStrategy
+// and finalization times from relayFinalizations,
+// until lastHeight is finalized.
+type phaseTimer struct {
+	lastHeight uint64
+
+	mu     sync.Mutex
+	rounds map[uint64]roundTimes
+	voting uint64 // Height of the latest round entered.
+
+	start, end time.Time
+
+	measured                 int
+	propose, prevote, commit time.Duration
+	lateRounds               int
+
+	done chan struct{}
+}
+
+func newPhaseTimer(lastHeight uint64) *phaseTimer {
+	return &phaseTimer{
+		lastHeight: lastHeight,
+		rounds:     make(map[uint64]roundTimes),
+		done:       make(chan struct{}),
+	}
+}
+
+func (t *phaseTimer) enterRound(h uint64, r uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rounds[h] = roundTimes{round: r, entered: time.Now()}
+	t.voting = h
+}
+
+// The strategy is only asked about proposed blocks and precommits
+// in the voting round, so the following methods apply to the latest round entered.
+
+func (t *phaseTimer) chose() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.voting
+	if rt, ok := t.rounds[h]; ok && rt.chosen.IsZero() {
+		rt.chosen = time.Now()
+		t.rounds[h] = rt
+	}
+}
+
+func (t *phaseTimer) reachedMajority() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.voting
+	if rt, ok := t.rounds[h]; ok && rt.majority.IsZero() {
+		rt.majority = time.Now()
+		t.rounds[h] = rt
+	}
+}
+
+func (t *phaseTimer) finalized(h uint64, r uint32) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rt, ok := t.rounds[h]
+	delete(t.rounds, h)
+
+	if h == 1 {
+		t.start = now
+		return
+	}
+	if h > t.lastHeight {
+		return
+	}
+
+	if r > 0 {
+		t.lateRounds++
+	}
+	if ok && rt.round == r && !rt.chosen.IsZero() && !rt.majority.IsZero() {
+		t.measured++
+		t.propose += rt.chosen.Sub(rt.entered)
+		t.prevote += rt.majority.Sub(rt.chosen)
+		t.commit += now.Sub(rt.majority)
+	}
+
+	if h == t.lastHeight {
+		t.end = now
+		close(t.done)
+	}
+}
+
+// relayFinalizations forwards finalization requests from the engine to the app,
+// recording when each arrives.
+func (t *phaseTimer) relayFinalizations(
+	ctx context.Context,
+	in <-chan tmdriver.FinalizeBlockRequest,
+	out chan<- tmdriver.FinalizeBlockRequest,
+) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-in:
+			t.finalized(req.Header.Height, req.Round)
+			select {
+			case <-ctx.Done():
+				return
+			case out <- req:
+			}
+		}
+	}
+}
+
+func (t *phaseTimer) result() Result {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := Result{
+		Heights:    t.lastHeight - 1,
+		Elapsed:    t.end.Sub(t.start),
+		LateRounds: t.lateRounds,
+	}
+	res.BlocksPerSecond = float64(res.Heights) / res.Elapsed.Seconds()
+
+	if t.measured > 0 {
+		n := time.Duration(t.measured)
+		res.Propose = t.propose / n
+		res.Prevote = t.prevote / n
+		res.Commit = t.commit / n
+	}
+
+	return res
+}
+
+// timedConsensusStrategy reports the phase boundaries of inner's decisions to t.
+type timedConsensusStrategy struct {
+	inner tmconsensus.ConsensusStrategy
+	t     *phaseTimer
+}
+
+func (s timedConsensusStrategy) EnterRound(
+	ctx context.Context, rv tmconsensus.RoundView, proposalOut chan<- tmconsensus.Proposal,
+) error {
+	s.t.enterRound(rv.Height, rv.Round)
+	return s.inner.EnterRound(ctx, rv, proposalOut)
+}
+
+func (s timedConsensusStrategy) ConsiderProposedBlocks(
+	ctx context.Context, phs []tmconsensus.ProposedHeader, reason tmconsensus.ConsiderProposedBlocksReason,
+) (string, error) {
+	hash, err := s.inner.ConsiderProposedBlocks(ctx, phs, reason)
+	if err == nil && hash != "" {
+		s.t.chose()
+	}
+	return hash, err
+}
+
+func (s timedConsensusStrategy) ChooseProposedBlock(
+	ctx context.Context, phs []tmconsensus.ProposedHeader,
+) (string, error) {
+	hash, err := s.inner.ChooseProposedBlock(ctx, phs)
+	if err == nil && hash != "" {
+		s.t.chose()
+	}
+	return hash, err
+}
+
+func (s timedConsensusStrategy) DecidePrecommit(
+	ctx context.Context, vs tmconsensus.VoteSummary,
+) (string, error) {
+	s.t.reachedMajority()
+	return s.inner.DecidePrecommit(ctx, vs)
+}
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	coreserver "cosmossdk.io/core/server"
 	"cosmossdk.io/core/transaction"
@@ -74,7 +75,7 @@ func StartGordianCommand() *cobra.Command {
 			// For now a panic on failure to convert is fine.
 			log := serverCtx.Logger.Impl().(*slog.Logger)
 
-			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 			defer cancel()
 
 			// Right now, only start a tmlibp2p host, just to prove that gordian and the SDK
@@ -15,6 +15,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gordian-engine/gcosmos/gcclient"
+	"github.com/gordian-engine/gcosmos/gcconformance"
 	"github.com/gordian-engine/gcosmos/internal/copy/gtest"
 	"github.com/gordian-engine/gcosmos/internal/gci"
 	"github.com/stretchr/testify/require"
@@ -32,6 +34,12 @@ const (
 	useSQLiteInMem = false
 )
 
+// When set, each validator started through [Chain.Start] or [AddLateNode]
+// runs as a separate OS process with its own captured log file,
+// instead of running in the test process.
+// This is slower, but it isolates any global state between validators.
+const runValidatorsAsProcesses = false
+
 func init() {
 	if useMemStore && useSQLiteInMem {
 		panic(fmt.Errorf(
@@ -49,6 +57,39 @@ func TestRootCmd(t *testing.T) {
 	e.Run("init", "defaultmoniker").NoError(t)
 }
 
+func TestRootCmd_consensusKeyRecover(t *testing.T) {
+	t.Parallel()
+
+	e := NewRootCmd(t, gtest.NewLogger(t))
+	e.RunWithInput(
+		strings.NewReader(FixedMnemonics[0]),
+		"init", "recovermoniker", "--recover",
+	).NoError(t)
+
+	keyPath := filepath.Join(e.homeDir, "config", "priv_validator_key.json")
+	want, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+
+	// Refuses to replace the existing key by default.
+	res := e.RunWithInput(
+		strings.NewReader(FixedMnemonics[0]),
+		"gordian", "keys", "consensus", "recover",
+	)
+	require.Error(t, res.Err)
+
+	require.NoError(t, os.Remove(keyPath))
+
+	// The default derivation restores the same key that init --recover wrote.
+	e.RunWithInput(
+		strings.NewReader(FixedMnemonics[0]),
+		"gordian", "keys", "consensus", "recover",
+	).NoError(t)
+
+	got, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	require.JSONEq(t, string(want), string(got))
+}
+
 func TestRootCmd_startWithGordian_singleValidator(t *testing.T) {
 	t.Parallel()
 
@@ -64,32 +105,42 @@ func TestRootCmd_startWithGordian_singleValidator(t *testing.T) {
 	httpAddr := c.Start(t, ctx, 1).HTTP[0]
 
 	if !gci.RunCometInsteadOfGordian {
-		u := "http://" + httpAddr + "/blocks/watermark"
-		// TODO: we might need to delay until we get a non-error HTTP response.
+		RequireVotingHeight(t, 10*time.Second, 3, httpAddr)
+	}
+}
 
-		deadline := time.Now().Add(10 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(u)
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
+func TestRootCmd_startValidateOnly(t *testing.T) {
+	t.Parallel()
 
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
+	if gci.RunCometInsteadOfGordian {
+		t.Skip("validate-only mode is specific to Gordian")
+	}
 
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 3 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-			// We got at least to height 3, so quit the loop.
-			break
-		}
+	c := ConfigureChain(t, ctx, ChainConfig{
+		ID:            t.Name(),
+		NVals:         1,
+		StakeStrategy: ConstantStakeStrategy(1_000_000_000),
+	})
+	e := c.RootCmds[0]
 
-		require.GreaterOrEqual(t, maxHeight, uint(3))
-	}
+	// Validate-only mode exits the process, so it must run in a child process.
+	p := e.StartProcess(t, append([]string{"start", "--g-validate-only"}, e.sqlitePathArgs()...)...)
+	require.NoError(t, p.Wait())
+	out, err := os.ReadFile(p.LogPath)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "checks passed")
+
+	p = e.StartProcess(t, append(
+		[]string{"start", "--g-validate-only", "--g-seed-addrs", "not-a-multiaddr"},
+		e.sqlitePathArgs()...,
+	)...)
+	require.Error(t, p.Wait())
+	out, err = os.ReadFile(p.LogPath)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "FAIL  seeds")
 }
 
 func TestRootCmd_startWithGordian_multipleValidators(t *testing.T) {
@@ -133,38 +184,11 @@ func TestRootCmd_startWithGordian_multipleValidators(t *testing.T) {
 	httpAddrs := ca.HTTP
 
 	// Each of the interesting validators must report a height beyond the first few blocks.
-	for i := range interestingVals {
-		if gci.RunCometInsteadOfGordian {
-			// Nothing to check in this mode.
-			break
-		}
-
+	if !gci.RunCometInsteadOfGordian {
 		// Gratuitous deadline to get the voting height,
 		// because the first proposed block is likely to time out
 		// due to libp2p settle time.
-		u := "http://" + httpAddrs[i] + "/blocks/watermark"
-		deadline := time.Now().Add(30 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(u)
-			require.NoErrorf(t, err, "failed to get the watermark for validator %d/%d", i, interestingVals)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 4 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// We got at least to height 4, so quit the loop.
-			break
-		}
-
-		require.GreaterOrEqualf(t, maxHeight, uint(4), "checking max block height on validator at index %d", i)
+		RequireVotingHeight(t, 30*time.Second, 4, httpAddrs...)
 	}
 
 	t.Run("adding a new validator catches up", func(t *testing.T) {
@@ -200,31 +224,39 @@ func TestRootCmd_startWithGordian_multipleValidators(t *testing.T) {
 			t.Fatal("did not read http address from file before deadline")
 		}
 
-		u := "http://" + httpAddr + "/blocks/watermark"
-		// TODO: we might need to delay until we get a non-error HTTP response.
+		// The late-started server must reach a minimum height.
+		RequireVotingHeight(t, 10*time.Second, 4, httpAddr)
+	})
 
-		deadline = time.Now().Add(10 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(u)
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
+	t.Run("restarted validator catches up", func(t *testing.T) {
+		if gci.RunCometInsteadOfGordian {
+			t.Skip("skipping due to not testing Gordian")
+		}
 
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
+		if useMemStore || useSQLiteInMem {
+			t.Skipf(
+				"can only test restart with on-disk storage (have useMemStore=%t, useSQLiteInMem=%t)",
+				useMemStore, useSQLiteInMem,
+			)
+		}
 
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 4 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
+		// The last interesting validator holds less than a third of the voting power,
+		// so the rest of the network continues without it.
+		const restartIdx = interestingVals - 1
 
-			// We got at least to height 4, so quit the loop.
-			break
+		mode := StopGraceful
+		if runValidatorsAsProcesses {
+			mode = StopKill
 		}
+		ca.StopValidator(t, restartIdx, mode)
+
+		// Let the network make progress while the validator is offline.
+		w, err := GetWatermark(httpAddrs[0])
+		require.NoError(t, err)
+		RequireVotingHeight(t, 15*time.Second, w.VotingHeight+2, httpAddrs[:restartIdx]...)
 
-		require.GreaterOrEqual(t, maxHeight, uint(4), "late-started server did not reach minimum height")
+		c.RestartValidator(t, ctx, ca, restartIdx)
+		ca.RequireCaughtUp(t, 30*time.Second, restartIdx)
 	})
 }
 
@@ -265,35 +297,14 @@ func Test_single_restart(t *testing.T) {
 		baseURL := "http://" + httpAddr
 
 		// Make sure we are beyond the initial height.
-		deadline := time.Now().Add(10 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(baseURL + "/blocks/watermark")
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 3 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// We are past initial height, so break out of the loop.
-			break
-		}
-
-		require.GreaterOrEqual(t, maxHeight, uint(3))
+		ws := RequireVotingHeight(t, 10*time.Second, 3, httpAddr)
 
-		expHeight := maxHeight
+		expHeight := ws[0].VotingHeight
 
 		// Now cancel the context.
 		// We expect the HTTP server to quit within a moment.
 		cancel()
-		deadline = time.Now().Add(3 * time.Second)
+		deadline := time.Now().Add(3 * time.Second)
 		gotError := false
 		for time.Now().Before(deadline) {
 			_, err := http.Get(baseURL + "/blocks/watermark")
@@ -313,41 +324,16 @@ func Test_single_restart(t *testing.T) {
 		ctx2, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		baseURL = "http://" + c.Start(t, ctx2, 1).HTTP[0]
+		httpAddr = c.Start(t, ctx2, 1).HTTP[0]
 
 		// c.Start blocks until the HTTP server is available,
 		// so we should be able to access it immediately.
-		maxHeight = 0
-		resp, err := http.Get(baseURL + "/blocks/watermark")
+		w, err := GetWatermark(httpAddr)
 		require.NoError(t, err)
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-
-		var m map[string]uint
-		require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-		resp.Body.Close()
-
-		maxHeight = m["VotingHeight"]
-		require.GreaterOrEqual(t, maxHeight, expHeight)
+		require.GreaterOrEqual(t, w.VotingHeight, expHeight)
 
 		// Now wait for the height to increase by two more.
-		expHeight += 2
-
-		deadline = time.Now().Add(15 * time.Second)
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(baseURL + "/blocks/watermark")
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight >= expHeight {
-				break
-			}
-		}
-		require.GreaterOrEqual(t, maxHeight, expHeight)
+		RequireVotingHeight(t, 15*time.Second, expHeight+2, httpAddr)
 	}
 }
 
@@ -357,8 +343,9 @@ func TestTx_single_basicSend(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	chainID := t.Name()
 	c := ConfigureChain(t, ctx, ChainConfig{
-		ID:            t.Name(),
+		ID:            chainID,
 		NVals:         1,
 		StakeStrategy: ConstantStakeStrategy(1_000_000_000),
 
@@ -369,102 +356,47 @@ func TestTx_single_basicSend(t *testing.T) {
 	httpAddr := c.Start(t, ctx, 1).HTTP[0]
 
 	if !gci.RunCometInsteadOfGordian {
-		baseURL := "http://" + httpAddr
+		client := gcclient.New(gcclient.Config{Addr: httpAddr})
 
 		// Make sure we are beyond the initial height.
-		deadline := time.Now().Add(10 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(baseURL + "/blocks/watermark")
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 3 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// We are past initial height, so break out of the loop.
-			break
-		}
-
-		require.GreaterOrEqual(t, maxHeight, uint(3))
+		RequireVotingHeight(t, 10*time.Second, 3, httpAddr)
 
 		// Ensure we still match the fixed account initial balance.
-		resp, err := http.Get(baseURL + "/debug/accounts/" + c.FixedAddresses[0] + "/balance")
+		initBalance, err := client.Balance(ctx, c.FixedAddresses[0], "")
 		require.NoError(t, err)
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-		var initBalance balance
-		require.NoError(t, json.NewDecoder(resp.Body).Decode(&initBalance))
-		resp.Body.Close()
-		require.Equal(t, "10000", initBalance.Balance.Amount)
-
-		// Now that we are past the initial height,
-		// make a transaction that we can submit.
+		require.Equal(t, "10000", initBalance)
 
-		const sendAmount = "100stake"
-
-		// First generate the transaction.
+		// The unsigned transaction can be estimated before signing,
+		// with its signer inferred from the send message.
 		res := c.RootCmds[0].Run(
-			"tx", "bank", "send", c.FixedAddresses[0], c.FixedAddresses[1], sendAmount,
-			"--chain-id", t.Name(),
+			"tx", "bank", "send", c.FixedAddresses[0], c.FixedAddresses[1], "100stake",
+			"--chain-id", chainID,
 			"--generate-only",
 		)
 		res.NoError(t)
-
-		dir := t.TempDir()
-		msgPath := filepath.Join(dir, "send.msg")
-		require.NoError(t, os.WriteFile(msgPath, res.Stdout.Bytes(), 0o600))
-
-		// TODO: is there a better way to dynamically get this?
-		const accountNumber = 1
-
-		// Sign the transaction offline so that we can send it.
-		res = c.RootCmds[0].Run(
-			"tx", "sign", msgPath,
-			"--offline",
-			"--chain-id", t.Name(),
-			fmt.Sprintf("--account-number=%d", accountNumber),
-			"--from", c.FixedAddresses[0],
-			"--sequence=0", // We know this is the first transaction for the sender.
-		)
-
-		res.NoError(t)
-		t.Logf("SIGN OUTPUT: %s", res.Stdout.String())
-		t.Logf("SIGN ERROR : %s", res.Stderr.String())
-
-		resp, err = http.Post(baseURL+"/debug/submit_tx", "application/json", &res.Stdout)
-		require.NoError(t, err)
-
-		// Just log out what it responds, for now.
-		// We can't do much with the response until we actually start handling the transaction.
-		b, err := io.ReadAll(resp.Body)
-		require.NoError(t, err)
-		t.Logf("response body: %s", b)
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-
-		// TODO: make some height assertions here instead of just sleeping.
-		time.Sleep(8 * time.Second)
-
-		// Request first account balance again.
-		resp, err = http.Get(baseURL + "/debug/accounts/" + c.FixedAddresses[0] + "/balance")
-		require.NoError(t, err)
-		var newBalance balance
-		require.NoError(t, json.NewDecoder(resp.Body).Decode(&newBalance))
-		resp.Body.Close()
-		require.Equal(t, "9900", newBalance.Balance.Amount) // Was at 10k, subtracted 100.
-
-		// And second account should have increased by 100.
-		resp, err = http.Get(baseURL + "/debug/accounts/" + c.FixedAddresses[1] + "/balance")
+		est, err := client.SimulateTx(ctx, res.Stdout.Bytes())
 		require.NoError(t, err)
-		require.NoError(t, json.NewDecoder(resp.Body).Decode(&newBalance))
-		resp.Body.Close()
-		require.Equal(t, "10100", newBalance.Balance.Amount) // Was at 10k, added 100.
+		require.NotZero(t, est.GasUsed)
+
+		gcconformance.Run(t, gcconformance.Config{
+			Nodes: []*gcclient.Client{client},
+
+			Send: &gcconformance.SendCheck{
+				From:   c.FixedAddresses[0],
+				To:     c.FixedAddresses[1],
+				Amount: 100,
+				Tx: func(t *testing.T) []byte {
+					// The first fixed account has account number 1,
+					// and this is its first transaction.
+					return c.RootCmds[0].SignedTx(
+						t, chainID, c.FixedAddresses[0], 1, 0,
+						"tx", "bank", "send", c.FixedAddresses[0], c.FixedAddresses[1], "100stake",
+						"--chain-id", chainID,
+						"--generate-only",
+					)
+				},
+			},
+		})
 	}
 }
 
@@ -475,8 +407,9 @@ func TestTx_single_delegate(t *testing.T) {
 	defer cancel()
 
 	const fixedAccountInitialBalance = 75_000_000
+	chainID := t.Name()
 	c := ConfigureChain(t, ctx, ChainConfig{
-		ID:            t.Name(),
+		ID:            chainID,
 		NVals:         1,
 		StakeStrategy: ConstantStakeStrategy(1_000_000_000),
 
@@ -488,116 +421,38 @@ func TestTx_single_delegate(t *testing.T) {
 	httpAddr := c.Start(t, ctx, 1).HTTP[0]
 
 	if !gci.RunCometInsteadOfGordian {
-		baseURL := "http://" + httpAddr
+		client := gcclient.New(gcclient.Config{Addr: httpAddr})
 
 		// Make sure we are beyond the initial height.
-		deadline := time.Now().Add(10 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(baseURL + "/blocks/watermark")
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 3 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// We are past initial height, so break out of the loop.
-			break
-		}
-
-		require.GreaterOrEqual(t, maxHeight, uint(3))
-
-		// Get the validator set.
-		resp, err := http.Get(baseURL + "/validators")
-		require.NoError(t, err)
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-
-		var output struct {
-			FinalizationHeight uint64
-			Validators         []struct {
-				// Don't care about the pubkey now,
-				// since there is only one validator.
-				Power uint64
-			}
-		}
-
-		require.NoError(t, json.NewDecoder(resp.Body).Decode(&output))
-		require.Len(t, output.Validators, 1)
-
-		// We need the starting power, because it should increase once we delegate.
-		startingPow := output.Validators[0].Power
+		RequireVotingHeight(t, 10*time.Second, 3, httpAddr)
 
 		delegateAmount := fmt.Sprintf("%dstake", fixedAccountInitialBalance)
 
-		// First generate the transaction.
-		res := c.RootCmds[0].Run(
-			// val0 is the name of the first validator key,
-			// which should be available on the first root command.
-			"tx", "staking", "delegate", "val0", delegateAmount, "--from", c.FixedAddresses[0],
-			"--generate-only",
-		)
-		res.NoError(t)
-
-		dir := t.TempDir()
-		msgPath := filepath.Join(dir, "delegate.msg")
-		require.NoError(t, os.WriteFile(msgPath, res.Stdout.Bytes(), 0o600))
-
-		// TODO: is there a better way to dynamically get this?
-		const accountNumber = 1
-
-		// Sign the transaction offline so that we can send it.
-		res = c.RootCmds[0].Run(
-			"tx", "sign", msgPath,
-			"--offline",
-			"--chain-id", t.Name(),
-			fmt.Sprintf("--account-number=%d", accountNumber),
-			"--from", c.FixedAddresses[0],
-			"--sequence=0", // We know this is the first transaction for the sender.
-		)
-
-		res.NoError(t)
-		t.Logf("SIGN OUTPUT: %s", res.Stdout.String())
-		t.Logf("SIGN ERROR : %s", res.Stderr.String())
-
-		resp, err = http.Post(baseURL+"/debug/submit_tx", "application/json", &res.Stdout)
-		require.NoError(t, err)
-
-		// Just log out what it responds, for now.
-		b, err := io.ReadAll(resp.Body)
-		require.NoError(t, err)
-		t.Logf("response body: %s", b)
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-
-		// TODO: make some height assertions here instead of just sleeping.
-		time.Sleep(8 * time.Second)
-
-		// First account should have a balance of zero,
-		// now that everything has been delegated.
-		resp, err = http.Get(baseURL + "/debug/accounts/" + c.FixedAddresses[0] + "/balance")
-		require.NoError(t, err)
-		var newBalance balance
-		require.NoError(t, json.NewDecoder(resp.Body).Decode(&newBalance))
-		resp.Body.Close()
-		require.Equal(t, "0", newBalance.Balance.Amount) // Entire balance was delegated.
+		gcconformance.Run(t, gcconformance.Config{
+			Nodes: []*gcclient.Client{client},
+
+			Staking: &gcconformance.StakingCheck{
+				Tx: func(t *testing.T) []byte {
+					// The first fixed account has account number 1,
+					// and this is its first transaction.
+					return c.RootCmds[0].SignedTx(
+						t, chainID, c.FixedAddresses[0], 1, 0,
+						// val0 is the name of the first validator key,
+						// which should be available on the first root command.
+						"tx", "staking", "delegate", "val0", delegateAmount, "--from", c.FixedAddresses[0],
+						"--generate-only",
+					)
+				},
+				Changed: func(before, after gcclient.ValidatorSet) bool {
+					return len(after.Validators) == 1 && gcconformance.TotalPowerIncreased(before, after)
+				},
+			},
+		})
 
-		resp, err = http.Get(baseURL + "/validators")
+		// The entire balance was delegated.
+		bal, err := client.Balance(ctx, c.FixedAddresses[0], "")
 		require.NoError(t, err)
-		require.Equal(t, http.StatusOK, resp.StatusCode)
-		clear(output.Validators)
-
-		require.NoError(t, json.NewDecoder(resp.Body).Decode(&output))
-		require.Len(t, output.Validators, 1)
-
-		endingPow := output.Validators[0].Power
-		require.Greater(t, endingPow, startingPow)
-		t.Logf("After delegation, power increased from %d to %d", startingPow, endingPow)
+		require.Equal(t, "0", bal)
 	}
 }
 
@@ -629,28 +484,7 @@ func TestTx_single_addAndRemoveNewValidator(t *testing.T) {
 		baseURL := "http://" + httpAddr
 
 		// Make sure we are beyond the initial height.
-		deadline := time.Now().Add(10 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(baseURL + "/blocks/watermark")
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 3 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// We are past initial height, so break out of the loop.
-			break
-		}
-
-		require.GreaterOrEqual(t, maxHeight, uint(3))
+		RequireVotingHeight(t, 10*time.Second, 3, httpAddr)
 
 		// Now the fixed address wants to become a validator.
 		// Use its mnemonic to create a new environment.
@@ -742,7 +576,7 @@ func TestTx_single_addAndRemoveNewValidator(t *testing.T) {
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 
 		// Wait for create-validator transaction to flush.
-		deadline = time.Now().Add(time.Minute)
+		deadline := time.Now().Add(time.Minute)
 		pendingTxFlushed := false
 		for time.Now().Before(deadline) {
 			resp, err := http.Get(baseURL + "/debug/pending_txs")
@@ -957,40 +791,10 @@ func TestTx_multiple_simpleSend(t *testing.T) {
 	httpAddrs := ca.HTTP
 
 	// Each of the interesting validators must report a height beyond the first few blocks.
-	for i := range interestingVals {
-		if gci.RunCometInsteadOfGordian {
-			// Nothing to check in this mode.
-			break
-		}
-
-		u := "http://" + httpAddrs[i] + "/blocks/watermark"
-		// TODO: we might need to delay until we get a non-error HTTP response.
-
-		// Another deadline to confirm the voting height.
+	if !gci.RunCometInsteadOfGordian {
 		// This is a lot longer than the deadline to check HTTP addresses
 		// because the very first proposed block at 1/0 is expected to time out.
-		deadline := time.Now().Add(30 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(u)
-			require.NoErrorf(t, err, "failed to get the watermark for validator %d/%d", i, interestingVals)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight < 3 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// We got at least to height 3, so quit the loop.
-			break
-		}
-
-		require.GreaterOrEqualf(t, maxHeight, uint(3), "checking max block height on validator at index %d", i)
+		RequireVotingHeight(t, 30*time.Second, 3, httpAddrs...)
 	}
 
 	// Now that the validators are all a couple blocks past initial height,
@@ -1107,15 +911,10 @@ func TestTx_multiple_simpleSend(t *testing.T) {
 		// See what the first validator is on,
 		// and that will be our target height.
 
-		heightResp, err := http.Get("http://" + httpAddrs[0] + "/blocks/watermark")
+		w, err := GetWatermark(httpAddrs[0])
 		require.NoError(t, err)
-		require.Equal(t, http.StatusOK, heightResp.StatusCode)
 
-		var m map[string]uint
-		require.NoError(t, json.NewDecoder(heightResp.Body).Decode(&m))
-		heightResp.Body.Close()
-
-		targetHeight := m["VotingHeight"]
+		targetHeight := w.VotingHeight
 
 		deadline := time.Now().Add(10 * time.Second)
 		for time.Now().Before(deadline) {
@@ -1139,31 +938,8 @@ func TestTx_multiple_simpleSend(t *testing.T) {
 			t.Fatal("did not read http address from file before deadline")
 		}
 
-		u := "http://" + httpAddr + "/blocks/watermark"
-		// TODO: we might need to delay until we get a non-error HTTP response.
-
-		deadline = time.Now().Add(10 * time.Second)
-		var maxHeight uint
-		for time.Now().Before(deadline) {
-			resp, err := http.Get(u)
-			require.NoError(t, err)
-			require.Equal(t, http.StatusOK, resp.StatusCode)
-
-			var m map[string]uint
-			require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
-			resp.Body.Close()
-
-			maxHeight = m["VotingHeight"]
-			if maxHeight < targetHeight {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// We got at least to the target height, so quit the loop.
-			break
-		}
-
-		require.GreaterOrEqual(t, maxHeight, targetHeight, "late-started server did not reach target height of earlier validator")
+		// The late-started server must reach the target height of the earlier validator.
+		RequireVotingHeight(t, 10*time.Second, targetHeight, httpAddr)
 
 		resp, err = http.Get("http://" + httpAddr + "/debug/accounts/" + c.FixedAddresses[0] + "/balance")
 		require.NoError(t, err)
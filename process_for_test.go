@@ -0,0 +1,200 @@
+package main_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"cosmossdk.io/core/transaction"
+	simdcmd "cosmossdk.io/simapp/v2/simdv2/cmd"
+	svrcmd "github.com/cosmos/cosmos-sdk/server/cmd"
+	"github.com/gordian-engine/gcosmos/internal/gci"
+	"github.com/spf13/cobra"
+)
+
+// reexecEnvVar is set in the environment of a child process
+// started by [CmdEnv.StartProcess],
+// indicating that the test binary must behave as the gcosmos command
+// instead of running tests.
+const reexecEnvVar = "GCOSMOS_TEST_REEXEC_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(reexecEnvVar) == "1" {
+		os.Exit(reexecMain(os.Args[1:]))
+	}
+
+	os.Exit(m.Run())
+}
+
+// reexecMain runs the root command with the given args,
+// in the same way as the real main function,
+// and returns the process exit code.
+func reexecMain(args []string) int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var cmd *cobra.Command
+	if gci.RunCometInsteadOfGordian {
+		cmd = simdcmd.NewCometBFTRootCmd[transaction.Tx]()
+	} else {
+		cmd = gci.NewSimdRootCmdWithGordian(ctx, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	}
+	cmd.SetArgs(args)
+
+	if err := cmd.ExecuteContext(svrcmd.CreateExecuteContext(ctx)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// ProcessNode is a command running in a separate OS process,
+// started through [CmdEnv.StartProcess].
+type ProcessNode struct {
+	cmd *exec.Cmd
+
+	// Path to the file containing the process's combined stdout and stderr.
+	LogPath string
+
+	err  error
+	done chan struct{}
+}
+
+var processLogCounter int32
+
+// StartProcess runs the test binary as the gcosmos command with the given args,
+// in a child process using e's home directory.
+//
+// The child's output is written to a log file in the home directory,
+// which is reported on test failure.
+// The child process is killed during test cleanup if it is still running.
+func (e CmdEnv) StartProcess(t *testing.T, args ...string) *ProcessNode {
+	t.Helper()
+
+	p, err := e.startProcess(t, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// startProcess is the implementation of StartProcess,
+// returning an error instead of failing the test,
+// so that it is safe to call from a goroutine other than the test's.
+func (e CmdEnv) startProcess(t *testing.T, args ...string) (*ProcessNode, error) {
+	logIdx := atomic.AddInt32(&processLogCounter, 1)
+	logPath := filepath.Join(e.homeDir, fmt.Sprintf("process-%d.log", logIdx))
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create process log file: %w", err)
+	}
+
+	cmd := exec.Command(
+		os.Args[0],
+		append(slices.Clone(args), "--home", e.homeDir)...,
+	)
+	cmd.Env = append(os.Environ(), reexecEnvVar+"=1")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	if err := cmd.Start(); err != nil {
+		_ = logFile.Close()
+		return nil, fmt.Errorf("failed to start process: %w", err)
+	}
+
+	p := &ProcessNode{
+		cmd:     cmd,
+		LogPath: logPath,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(p.done)
+		p.err = cmd.Wait()
+		_ = logFile.Close()
+	}()
+
+	t.Cleanup(func() {
+		p.Kill()
+		<-p.done
+
+		if t.Failed() {
+			b, err := os.ReadFile(logPath)
+			if err != nil {
+				t.Logf("failed to read process log %s: %v", logPath, err)
+				return
+			}
+			t.Logf("output of process %v:\n%s", args, b)
+		}
+	})
+
+	return p, nil
+}
+
+// Done returns a channel that is closed once the process has exited.
+func (p *ProcessNode) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the process exits,
+// and returns the error from [exec.Cmd.Wait].
+func (p *ProcessNode) Wait() error {
+	<-p.done
+	return p.err
+}
+
+// Stop sends SIGTERM to the process,
+// and if it does not exit within the timeout, kills it.
+// Stop blocks until the process has exited.
+func (p *ProcessNode) Stop(timeout time.Duration) error {
+	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to signal process: %w", err)
+	}
+
+	select {
+	case <-p.done:
+	case <-time.After(timeout):
+		p.Kill()
+		<-p.done
+	}
+	return nil
+}
+
+// Kill immediately kills the process, without any chance for a clean shutdown,
+// simulating a crash.
+// Kill does not wait for the process to exit; use [*ProcessNode.Wait] for that.
+func (p *ProcessNode) Kill() {
+	_ = p.cmd.Process.Kill()
+}
+
+// runStart runs the start command with args,
+// either in-process or in a child process depending on [runValidatorsAsProcesses],
+// blocking until ctx is canceled or the command exits.
+func (e CmdEnv) runStart(t *testing.T, ctx context.Context, args ...string) {
+	if !runValidatorsAsProcesses {
+		_ = e.RunC(ctx, args...)
+		return
+	}
+
+	p, err := e.startProcess(t, args...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+		// Match the in-process behavior of a clean shutdown on context cancellation.
+		_ = p.Stop(10 * time.Second)
+	case <-p.Done():
+	}
+}